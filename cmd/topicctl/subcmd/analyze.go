@@ -0,0 +1,242 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/segmentio/topicctl/pkg/completion"
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var analyzeResourceTypes = []string{
+	"keys",
+}
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [resource type] [topic]",
+	Short: "analyze the data in a topic",
+	Long: strings.Join(
+		[]string{
+			"Analyze the data in a topic.",
+			"Supported types currently include: keys.",
+			"",
+			"See the tool README for a detailed description of each one.",
+		},
+		"\n",
+	),
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: analyzeValidArgs,
+	PreRunE:           analyzePreRun,
+	RunE:              analyzeRun,
+}
+
+type analyzeCmdConfig struct {
+	maxHotKeys      int
+	partitionCounts []int
+	partitioner     string
+	sampleSize      int
+
+	shared sharedOptions
+}
+
+var analyzeConfig analyzeCmdConfig
+
+func init() {
+	analyzeCmd.Flags().IntVar(
+		&analyzeConfig.maxHotKeys,
+		"hot-keys",
+		10,
+		"Number of most common keys to show (keys only)",
+	)
+	analyzeCmd.Flags().IntSliceVar(
+		&analyzeConfig.partitionCounts,
+		"partition-counts",
+		[]int{},
+		"Proposed partition counts to compare against the current one; defaults to double the current count (keys only)",
+	)
+	analyzeCmd.Flags().StringVar(
+		&analyzeConfig.partitioner,
+		"partitioner",
+		string(util.PartitionerMurmur2),
+		fmt.Sprintf(
+			"Partitioner that producers use, one of %+v (keys only)",
+			util.AllPartitioners,
+		),
+	)
+	analyzeCmd.Flags().IntVar(
+		&analyzeConfig.sampleSize,
+		"sample-size",
+		10000,
+		"Number of recent records to sample, spread evenly across partitions (keys only)",
+	)
+	addSharedFlags(analyzeCmd, &analyzeConfig.shared)
+
+	RootCmd.AddCommand(analyzeCmd)
+}
+
+func analyzePreRun(cmd *cobra.Command, args []string) error {
+	if args[0] != "keys" {
+		return fmt.Errorf(
+			"Unrecognized resource type %s; must be one of %+v",
+			args[0],
+			analyzeResourceTypes,
+		)
+	}
+	if analyzeConfig.sampleSize <= 0 {
+		return errors.New("Sample size must be positive")
+	}
+
+	if err := util.Partitioner(analyzeConfig.partitioner).Validate(); err != nil {
+		return err
+	}
+
+	return analyzeConfig.shared.validate()
+}
+
+func analyzeRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	adminClient, err := analyzeConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	topic := args[1]
+
+	// Check that topic exists before dialing partition leaders; otherwise, the topic might
+	// be created as part of the sample.
+	topicInfo, err := adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+	currentPartitions := len(topicInfo.Partitions)
+
+	partitionCounts := analyzeConfig.partitionCounts
+	if len(partitionCounts) == 0 {
+		partitionCounts = []int{2 * currentPartitions}
+	}
+
+	log.Infof(
+		"Sampling up to %d recent record(s) from topic %s",
+		analyzeConfig.sampleSize,
+		topic,
+	)
+	sampled, err := messages.SampleKeys(
+		ctx,
+		adminClient.GetBootstrapAddrs()[0],
+		topic,
+		topicInfo.PartitionIDs(),
+		analyzeConfig.sampleSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := messages.AnalyzeKeys(
+		topic,
+		sampled,
+		util.Partitioner(analyzeConfig.partitioner),
+		currentPartitions,
+		partitionCounts,
+		analyzeConfig.maxHotKeys,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Sampled %d record(s): %d with keys (%d distinct), %d without keys",
+		analysis.SampledRecords,
+		analysis.KeyedRecords(),
+		analysis.DistinctKeys,
+		analysis.KeylessRecords,
+	)
+	if analysis.KeyedRecords() == 0 {
+		log.Infof(
+			"None of the sampled records have keys, so they're spread by the producers' default strategy (e.g., round-robin) instead of by key",
+		)
+		return nil
+	}
+
+	matchingPct := 100.0 * float64(analysis.MatchingRecords) / float64(analysis.KeyedRecords())
+	log.Infof(
+		"%.1f%% of the keyed records are in the partition that the %s partitioner maps their key to",
+		matchingPct,
+		analysis.Partitioner,
+	)
+	if matchingPct < 95.0 {
+		log.Warnf(
+			"The producers for this topic probably use a different partitioner than %s (or the partition count was changed recently); try setting --partitioner",
+			analysis.Partitioner,
+		)
+	}
+
+	log.Infof(
+		"Key distribution by partition count (%s partitioner):\n%s",
+		analysis.Partitioner,
+		messages.FormatKeyDistributions(analysis),
+	)
+	log.Infof("Most common keys:\n%s", messages.FormatHotKeys(analysis))
+
+	for _, distribution := range analysis.Distributions {
+		if distribution.Stats.Mean < 100 {
+			log.Warnf(
+				"There are fewer than 100 sampled records per partition with %d partitions, so the results may be noisy; try increasing --sample-size",
+				distribution.NumPartitions,
+			)
+			break
+		}
+	}
+	if len(analysis.HotKeys) > 0 {
+		// A single key always maps to one partition, so it puts a floor on the busiest one
+		topShare := float64(analysis.HotKeys[0].Records) / float64(analysis.KeyedRecords())
+		minUneven := 0
+		for _, distribution := range analysis.Distributions {
+			if topShare*float64(distribution.NumPartitions) > messages.EvenMaxToMeanRatio &&
+				(minUneven == 0 || distribution.NumPartitions < minUneven) {
+				minUneven = distribution.NumPartitions
+			}
+		}
+		if minUneven > 0 {
+			log.Warnf(
+				"The most common key alone accounts for %.1f%% of the keyed records, so the topic can't be spread evenly over %d or more partitions by key",
+				100.0*topShare,
+				minUneven,
+			)
+		}
+	}
+
+	return nil
+}
+
+func analyzeValidArgs(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completion.FilterPrefix(analyzeResourceTypes, toComplete),
+			cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) == 1 {
+		return completeTopics(analyzeConfig.shared, nil, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
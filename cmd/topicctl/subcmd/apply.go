@@ -2,43 +2,136 @@ package subcmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/artifacts"
 	"github.com/segmentio/topicctl/pkg/cli"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/gitops"
+	"github.com/segmentio/topicctl/pkg/metrics"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var applyCmd = &cobra.Command{
-	Use:   "apply [topic configs]",
-	Short: "apply one or more topic configs",
-	Args:  cobra.MinimumNArgs(1),
+	Use:   "apply [topic or connector configs]",
+	Short: "apply one or more topic or connector configs",
 	RunE:  applyRun,
+	ValidArgsFunction: func(
+		cmd *cobra.Command,
+		args []string,
+		toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		return completeConfigFiles(toComplete)
+	},
 }
 
 type applyCmdConfig struct {
+	allowCleanupPolicyChange   bool
+	allowRepartitioning        bool
+	allowRetentionReduction    bool
+	artifactsDir               string
+	autoApprove                []string
+	backupDir                  string
+	brokerConfigs              string
 	brokersToRemove            []int
 	brokerThrottleMBsOverride  int
+	bundle                     bool
+	cancelOnInterrupt          bool
+	cleanupMonitorWindow       time.Duration
 	clusterConfig              string
+	deleteRetired              bool
 	dryRun                     bool
+	executePlan                string
+	fixRackViolations          bool
+	gitPath                    string
+	gitRef                     string
+	gitRepo                    string
+	ignoreWindow               bool
+	lockWait                   time.Duration
+	outputPlan                 string
 	partitionBatchSizeOverride int
+	partitionMetrics           string
+	partitionStepDelay         time.Duration
+	partitionStepSize          int
 	pathPrefix                 string
+	planOnly                   string
+	raiseAdminQuotas           bool
 	rebalance                  bool
 	skipConfirm                bool
 	sleepLoopTime              time.Duration
+	statusOutput               string
+
+	// autoApproveCategories are parsed from autoApprove when the command is run
+	autoApproveCategories []apply.ApprovalCategory
+
+	// stop is closed on the first interrupt so that no new changes are started
+	stop chan struct{}
 }
 
 var applyConfig applyCmdConfig
 
 func init() {
+	applyCmd.Flags().BoolVar(
+		&applyConfig.allowCleanupPolicyChange,
+		"allow-cleanup-policy-change",
+		false,
+		"Allow applies that change the cleanup.policy of existing topics",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.allowRepartitioning,
+		"allow-repartitioning",
+		false,
+		"Allow partition count increases for keyed topics",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.allowRetentionReduction,
+		"allow-retention-reduction",
+		false,
+		"Allow applies that reduce topic retention, which can delete data",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.artifactsDir,
+		"artifacts-dir",
+		defaultArtifactsDir(),
+		"Directory under which a timestamped artifacts directory is created for each run",
+	)
+	applyCmd.Flags().StringSliceVar(
+		&applyConfig.autoApprove,
+		"auto-approve",
+		[]string{},
+		fmt.Sprintf(
+			"Automatically approve changes in these categories, one or more of %+v; if set without a value, all but delete are approved",
+			apply.AllApprovalCategories,
+		),
+	)
+	applyCmd.Flags().Lookup("auto-approve").NoOptDefVal = strings.Join(
+		approvalCategoryStrs(apply.DefaultAutoApproveCategories),
+		",",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.backupDir,
+		"backup-dir",
+		defaultBackupDir(),
+		"Directory to back up the state of each topic to before changing it; set to empty to disable",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.brokerConfigs,
+		"broker-configs",
+		"",
+		"Path to a brokers config with dynamic broker settings to apply before any topic configs",
+	)
 	applyCmd.Flags().IntSliceVar(
 		&applyConfig.brokersToRemove,
 		"to-remove",
@@ -51,24 +144,120 @@ func init() {
 		0,
 		"Broker throttle override (MB/sec)",
 	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.bundle,
+		"bundle",
+		false,
+		"Create a tarball of the run's artifacts directory when the run finishes",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.cancelOnInterrupt,
+		"cancel-on-interrupt",
+		false,
+		"On interrupt, cancel the in-flight reassignment batch instead of waiting for it to finish",
+	)
+	applyCmd.Flags().DurationVar(
+		&applyConfig.cleanupMonitorWindow,
+		"cleanup-monitor-window",
+		2*time.Minute,
+		"Amount of time to monitor the log cleaner for after a cleanup.policy change; set to 0 to disable",
+	)
 	applyCmd.Flags().StringVar(
 		&applyConfig.clusterConfig,
 		"cluster-config",
 		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
 		"Cluster config path",
 	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.deleteRetired,
+		"delete-retired",
+		false,
+		"Delete retiring topics that are past their deletion dates",
+	)
 	applyCmd.Flags().BoolVar(
 		&applyConfig.dryRun,
 		"dry-run",
 		false,
 		"Do a dry-run",
 	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.executePlan,
+		"execute-plan",
+		"",
+		"Path to a plan from --plan-only; only the changes in it are made, and only if the cluster hasn't changed",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.fixRackViolations,
+		"fix-rack-violations",
+		false,
+		"Move replicas so that each partition is spread across the expected number of racks",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.gitPath,
+		"path",
+		"",
+		"Directory in the git repo that config paths are relative to; only applies if git-repo is set",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.gitRef,
+		"git-ref",
+		"HEAD",
+		"Branch, tag, or commit SHA to apply from; only applies if git-repo is set",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.gitRepo,
+		"git-repo",
+		"",
+		"URL of a git repo to apply configs from instead of the local filesystem",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.ignoreWindow,
+		"ignore-window",
+		false,
+		"Allow moving data between brokers outside of the cluster's maintenance windows",
+	)
+	applyCmd.Flags().DurationVar(
+		&applyConfig.lockWait,
+		"lock-wait",
+		0,
+		"Amount of time to wait in line for the cluster lock if another apply holds it",
+	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.outputPlan,
+		"output-plan",
+		"",
+		"Path to write a JSON report of the topic changes made (or, in dry-run mode, planned) by the apply",
+	)
 	applyCmd.Flags().IntVar(
 		&applyConfig.partitionBatchSizeOverride,
 		"partition-batch-size",
 		0,
 		"Partition batch size override",
 	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.partitionMetrics,
+		"partition-metrics",
+		"",
+		"Path to a JSON or CSV file of per-partition bytes-in rates; if set, rebalances balance leader throughput",
+	)
+	applyCmd.Flags().BoolVar(
+		&applyConfig.raiseAdminQuotas,
+		"raise-admin-quotas",
+		false,
+		"Temporarily raise any client quotas that apply to this tool during the apply",
+	)
+	applyCmd.Flags().IntVar(
+		&applyConfig.partitionStepSize,
+		"partition-step",
+		0,
+		"If set, add new partitions in steps of this size instead of all at once",
+	)
+	applyCmd.Flags().DurationVar(
+		&applyConfig.partitionStepDelay,
+		"partition-step-delay",
+		time.Minute,
+		"Amount of time to wait between partition steps once all replicas are in-sync",
+	)
 	applyCmd.Flags().BoolVar(
 		&applyConfig.rebalance,
 		"rebalance",
@@ -81,6 +270,12 @@ func init() {
 		os.Getenv("TOPICCTL_APPLY_PATH_PREFIX"),
 		"Prefix for topic config paths",
 	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.planOnly,
+		"plan-only",
+		"",
+		"Do a dry-run and write a plan of the changes to this path for a later --execute-plan",
+	)
 	applyCmd.Flags().BoolVar(
 		&applyConfig.skipConfirm,
 		"skip-confirm",
@@ -93,6 +288,12 @@ func init() {
 		10*time.Second,
 		"Amount of time to wait between partition checks",
 	)
+	applyCmd.Flags().StringVar(
+		&applyConfig.statusOutput,
+		"status-output",
+		"",
+		"Path to write a JSON report of the status (changed, unchanged, failed, etc.) of each config",
+	)
 
 	RootCmd.AddCommand(applyCmd)
 }
@@ -101,13 +302,160 @@ func applyRun(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal)
+	// The first interrupt stops the apply after the current step; the second one aborts it
+	// immediately
+	applyConfig.stop = make(chan struct{})
+	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
+		log.Warn("Stopping after the current step; interrupt again to abort immediately")
+		close(applyConfig.stop)
+		<-sigChan
+		log.Warn("Aborting")
 		cancel()
 	}()
 
+	var err error
+	applyConfig.autoApproveCategories, err = apply.ParseApprovalCategories(
+		applyConfig.autoApprove,
+	)
+	if err != nil {
+		return exitcode.Wrap(exitcode.KindConfig, err)
+	}
+
+	var source *gitSource
+
+	if applyConfig.gitRepo != "" {
+		source, err = checkoutGitSource(ctx)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(source.dir)
+
+		if len(args) == 0 && applyConfig.brokerConfigs == "" {
+			args = []string{"*.yaml"}
+		}
+		for i, arg := range args {
+			args[i], err = source.configPath(arg)
+			if err != nil {
+				return err
+			}
+		}
+		if applyConfig.brokerConfigs != "" {
+			applyConfig.brokerConfigs, err = source.configPath(applyConfig.brokerConfigs)
+			if err != nil {
+				return err
+			}
+		}
+	} else if applyConfig.gitPath != "" {
+		return errors.New("Cannot set path without git-repo")
+	}
+
+	plan, err := loadApplyPlan(args)
+	if err != nil {
+		return err
+	}
+	if applyConfig.executePlan != "" {
+		args = plan.Configs
+	}
+
+	if applyConfig.brokerConfigs == "" && len(args) == 0 {
+		return errors.New("Must provide at least one config path or set broker-configs")
+	}
+
+	run, err := artifacts.NewRun(applyConfig.artifactsDir, "apply", args, applyConfig.dryRun)
+	if err != nil {
+		return err
+	}
+	log.Infof("Storing artifacts for run %s in %s", run.ID, run.Dir)
+
+	changeReport := &apply.ChangeReport{
+		DryRun: applyConfig.dryRun,
+		Topics: []*apply.TopicChanges{},
+	}
+
+	progress, err := applyConfigs(ctx, args, run, changeReport, source, plan)
+	if progress != nil {
+		if len(progress.Configs) > 1 {
+			log.Infof("Apply summary:\n%s", apply.FormatApplyProgress(progress))
+			log.Info(progress.Format())
+		}
+		if applyConfig.statusOutput != "" {
+			if statusErr := progress.WriteFile(applyConfig.statusOutput); statusErr != nil {
+				log.Warnf("Error writing status report: %+v", statusErr)
+			} else {
+				log.Infof("Wrote status report to %s", applyConfig.statusOutput)
+			}
+		}
+	}
+	if err != nil && applyStopped(ctx) {
+		notStarted := []string{}
+		if progress != nil {
+			notStarted = progress.Pending()
+		}
+		log.Warn(apply.FormatStopSummary(changeReport, notStarted))
+	}
+
+	for _, topicChanges := range changeReport.Topics {
+		if topicChanges.Skipped != "" {
+			log.Warnf(
+				"Skipped topic %s in cluster %s (%s)",
+				topicChanges.Topic,
+				topicChanges.Cluster,
+				topicChanges.Skipped,
+			)
+		}
+	}
+
+	if applyConfig.planOnly != "" && err == nil {
+		plan.Topics = changeReport.Topics
+		if err = plan.Sign(apply.PlanKey()); err == nil {
+			err = plan.WriteFile(applyConfig.planOnly)
+		}
+		if err == nil {
+			log.Infof(
+				"Wrote plan to %s; after it's reviewed, run apply --execute-plan %s to make these changes",
+				applyConfig.planOnly,
+				applyConfig.planOnly,
+			)
+		}
+	}
+
+	if applyConfig.outputPlan != "" {
+		if err != nil {
+			changeReport.Error = err.Error()
+		}
+		if planErr := changeReport.WriteFile(applyConfig.outputPlan); planErr != nil {
+			log.Warnf("Error writing change report: %+v", planErr)
+		} else {
+			log.Infof("Wrote change report to %s", applyConfig.outputPlan)
+		}
+	}
+
+	if finishErr := run.Finish(err); finishErr != nil {
+		log.Warnf("Error writing run summary: %+v", finishErr)
+	}
+	if applyConfig.bundle {
+		bundlePath, bundleErr := run.Bundle()
+		if bundleErr != nil {
+			log.Warnf("Error bundling run artifacts: %+v", bundleErr)
+		} else {
+			log.Infof("Run artifacts bundled in %s", bundlePath)
+		}
+	}
+
+	return err
+}
+
+func applyConfigs(
+	ctx context.Context,
+	args []string,
+	run *artifacts.Run,
+	changeReport *apply.ChangeReport,
+	source *gitSource,
+	plan *apply.Plan,
+) (*apply.ApplyProgress, error) {
 	// Keep a cache of the admin clients with the cluster config path as the key
 	adminClients := map[string]*admin.Client{}
 
@@ -117,7 +465,21 @@ func applyRun(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	matchCount := 0
+	appliedCount := 0
+
+	if applyConfig.brokerConfigs != "" {
+		err := applyBrokers(ctx, applyConfig.brokerConfigs, adminClients, run, source)
+		addApplyAuditEntry(run, "brokers", applyConfig.brokerConfigs, err)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 0 {
+			return nil, recordAppliedRefs(ctx, adminClients, source)
+		}
+		appliedCount++
+	}
+
+	allMatches := []string{}
 
 	for _, arg := range args {
 		if applyConfig.pathPrefix != "" && !filepath.IsAbs(arg) {
@@ -126,28 +488,121 @@ func applyRun(cmd *cobra.Command, args []string) error {
 
 		matches, err := filepath.Glob(arg)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		allMatches = append(allMatches, matches...)
+	}
 
-		for _, match := range matches {
-			matchCount++
-			if err := applyTopic(ctx, match, adminClients); err != nil {
-				return err
+	// Apply the topics with higher migration priorities first so that, in large rebalances,
+	// the most important topics are moved before the others
+	progress := apply.NewApplyProgress(allMatches, applyConfig.dryRun)
+	progress.OrderByPriority(migrationPriorities(allMatches))
+	multipleTiers := len(progress.Tiers()) > 1
+
+	for m, configProgress := range progress.Configs {
+		match := configProgress.Path
+
+		if applyStopped(ctx) {
+			return progress, partialApplyError(apply.ErrApplyStopped, appliedCount)
+		}
+		if len(allMatches) > 1 {
+			log.Info(progress.Format())
+		}
+		if multipleTiers {
+			log.Infof("Progress by migration priority: %s", progress.FormatTiers())
+		}
+
+		progress.Start(m, time.Now())
+		numTopics := len(changeReport.Topics)
+
+		kind, err := config.LoadKindFile(match)
+		if err == nil {
+			err = run.AddPlanFile(match)
+		}
+		if err != nil {
+			progress.Finish(m, nil, err, time.Now())
+			return progress, err
+		}
+
+		if kind == config.ConnectorKind {
+			if plan != nil {
+				err = fmt.Errorf("Connector config %s can't be included in a plan", match)
+				progress.Finish(m, nil, err, time.Now())
+				return progress, err
 			}
+			err = applyConnector(ctx, match)
+		} else {
+			kind = "topic"
+			err = applyTopic(ctx, match, adminClients, run, changeReport, source, plan)
 		}
+		addApplyAuditEntry(run, kind, match, err)
+
+		var topicChanges *apply.TopicChanges
+		if len(changeReport.Topics) > numTopics {
+			topicChanges = changeReport.Topics[len(changeReport.Topics)-1]
+		}
+		progress.Finish(m, topicChanges, err, time.Now())
+
+		if err != nil {
+			return progress, partialApplyError(err, appliedCount)
+		}
+		appliedCount++
 	}
 
-	if matchCount == 0 {
-		return fmt.Errorf("No topic configs match the provided args (%+v)", args)
+	if len(allMatches) == 0 {
+		return progress, exitcode.Wrap(
+			exitcode.KindConfig,
+			fmt.Errorf("No topic configs match the provided args (%+v)", args),
+		)
 	}
 
-	return nil
+	return progress, recordAppliedRefs(ctx, adminClients, source)
+}
+
+// migrationPriorities returns the migration priority of each of the argument topic configs,
+// keyed by path. Configs that can't be loaded are omitted; their errors are surfaced when
+// they're applied.
+func migrationPriorities(paths []string) map[string]int {
+	priorities := map[string]int{}
+
+	for _, path := range paths {
+		kind, err := config.LoadKindFile(path)
+		if err != nil || kind == config.ConnectorKind {
+			continue
+		}
+		topicConfig, err := config.LoadTopicFile(path)
+		if err != nil {
+			continue
+		}
+		priorities[path] = topicConfig.MigrationPriority()
+	}
+
+	return priorities
+}
+
+// applyStopped returns whether the apply was interrupted, either gracefully or by aborting
+// it.
+func applyStopped(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+
+	select {
+	case <-applyConfig.stop:
+		return true
+	default:
+		return false
+	}
 }
 
 func applyTopic(
 	ctx context.Context,
 	topicConfigPath string,
 	adminClients map[string]*admin.Client,
+	run *artifacts.Run,
+	changeReport *apply.ChangeReport,
+	source *gitSource,
+	plan *apply.Plan,
 ) error {
 	clusterConfigPath, err := clusterConfigForTopicApply(topicConfigPath)
 	if err != nil {
@@ -160,43 +615,381 @@ func applyTopic(
 		clusterConfigPath,
 	)
 
+	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	if err != nil {
+		return err
+	}
+
 	topicConfig, err := config.LoadTopicFile(topicConfigPath)
 	if err != nil {
 		return err
 	}
-	topicConfig.SetDefaults()
+	if err := topicConfig.ResolveClusterDefaults(clusterConfig); err != nil {
+		return err
+	}
 
-	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	adminClient, err := getApplyAdminClient(
+		ctx,
+		clusterConfigPath,
+		clusterConfig,
+		adminClients,
+		source,
+	)
 	if err != nil {
 		return err
 	}
 
-	adminClient, ok := adminClients[clusterConfigPath]
-	if !ok {
-		adminClient, err = clusterConfig.NewAdminClient(ctx, nil, applyConfig.dryRun)
-		if err != nil {
-			return err
-		}
-		adminClients[clusterConfigPath] = adminClient
+	locker, err := clusterConfig.NewLocker(adminClient, nil)
+	if err != nil {
+		return err
 	}
 
 	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, false)
 
 	applierConfig := apply.TopicApplierConfig{
+		AllowCleanupPolicyChange:   applyConfig.allowCleanupPolicyChange,
+		AllowRepartitioning:        applyConfig.allowRepartitioning,
+		AllowRetentionReduction:    applyConfig.allowRetentionReduction,
+		AutoApprove:                applyConfig.autoApproveCategories,
+		BackupDir:                  applyConfig.backupDir,
 		BrokerThrottleMBsOverride:  applyConfig.brokerThrottleMBsOverride,
 		BrokersToRemove:            applyConfig.brokersToRemove,
+		CancelReassignmentsOnStop:  applyConfig.cancelOnInterrupt,
+		ChangeReport:               changeReport,
+		CleanupMonitorWindow:       applyConfig.cleanupMonitorWindow,
 		ClusterConfig:              clusterConfig,
+		DeleteRetired:              applyConfig.deleteRetired,
 		DryRun:                     applyConfig.dryRun,
+		FixRackViolations:          applyConfig.fixRackViolations,
+		IgnoreMaintenanceWindow:    applyConfig.ignoreWindow,
+		Locker:                     locker,
+		LockWait:                   applyConfig.lockWait,
 		PartitionBatchSizeOverride: applyConfig.partitionBatchSizeOverride,
+		PartitionStepDelay:         applyConfig.partitionStepDelay,
+		PartitionStepSize:          applyConfig.partitionStepSize,
+		RaiseAdminQuotas:           applyConfig.raiseAdminQuotas,
 		Rebalance:                  applyConfig.rebalance,
 		SkipConfirm:                applyConfig.skipConfirm,
 		SleepLoopTime:              applyConfig.sleepLoopTime,
+		Stop:                       applyConfig.stop,
 		TopicConfig:                topicConfig,
 	}
 
+	if topicConfig.Spec.PlacementConfig.Strategy == config.PlacementStrategyBalancedTopicSet {
+		// The other members of the topic set are found by looking at the other configs in the
+		// same directory
+		members, err := config.LoadTopicSetMembers(
+			filepath.Dir(topicConfigPath),
+			topicConfig,
+			clusterConfig.Spec.TopicDefaults,
+		)
+		if err != nil {
+			return err
+		}
+		if err := config.CheckTopicSetConsistency(topicConfig, members); err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			applierConfig.TopicSetMembers = append(
+				applierConfig.TopicSetMembers,
+				member.Meta.Name,
+			)
+		}
+	}
+
+	if applyConfig.partitionMetrics != "" {
+		applierConfig.PartitionMetrics, err = metrics.NewFileFetcher(applyConfig.partitionMetrics)
+		if err != nil {
+			return err
+		}
+	}
+
+	if applyConfig.planOnly != "" {
+		if err := addPlanConfigs(plan, topicConfigPath, clusterConfigPath); err != nil {
+			return err
+		}
+	} else if plan != nil {
+		applierConfig.PlannedChanges = plan.TopicChanges(
+			topicConfig.Meta.Name,
+			topicConfig.Meta.Cluster,
+		)
+		if applierConfig.PlannedChanges == nil {
+			return fmt.Errorf(
+				"Topic %s in cluster %s isn't in the plan",
+				topicConfig.Meta.Name,
+				topicConfig.Meta.Cluster,
+			)
+		}
+	}
+
+	snapshotTopic(ctx, run, adminClient, topicConfig.Meta.Name, "before")
+	defer snapshotTopic(ctx, run, adminClient, topicConfig.Meta.Name, "after")
+
 	return cliRunner.ApplyTopic(ctx, applierConfig)
 }
 
+// loadApplyPlan validates the plan-related flags and returns the plan that's being made or
+// executed, if any. When executing a plan, the options that it was made with replace the
+// ones set on the command line.
+func loadApplyPlan(args []string) (*apply.Plan, error) {
+	if applyConfig.planOnly == "" && applyConfig.executePlan == "" {
+		return nil, nil
+	}
+
+	switch {
+	case applyConfig.planOnly != "" && applyConfig.executePlan != "":
+		return nil, errors.New("Cannot set both plan-only and execute-plan")
+	case applyConfig.gitRepo != "" || applyConfig.brokerConfigs != "":
+		return nil, errors.New("Plans can't be used with git-repo or broker-configs")
+	case applyConfig.executePlan != "" && applyConfig.dryRun:
+		return nil, errors.New("Cannot set both execute-plan and dry-run")
+	case applyConfig.executePlan != "" && len(args) > 0:
+		return nil, errors.New(
+			"Cannot pass config paths with execute-plan; the configs in the plan are used",
+		)
+	}
+
+	if applyConfig.planOnly != "" {
+		applyConfig.dryRun = true
+
+		options := apply.PlanOptions{
+			AllowCleanupPolicyChange: applyConfig.allowCleanupPolicyChange,
+			AllowRepartitioning:      applyConfig.allowRepartitioning,
+			AllowRetentionReduction:  applyConfig.allowRetentionReduction,
+			BrokersToRemove:          applyConfig.brokersToRemove,
+			DeleteRetired:            applyConfig.deleteRetired,
+			FixRackViolations:        applyConfig.fixRackViolations,
+			Rebalance:                applyConfig.rebalance,
+		}
+		if applyConfig.partitionMetrics != "" {
+			metricsPath, err := filepath.Abs(applyConfig.partitionMetrics)
+			if err != nil {
+				return nil, err
+			}
+			options.PartitionMetrics = metricsPath
+		}
+
+		plan := apply.NewPlan(options, time.Now())
+		if options.PartitionMetrics != "" {
+			if err := plan.AddFile(options.PartitionMetrics); err != nil {
+				return nil, err
+			}
+		}
+		return plan, nil
+	}
+
+	plan, err := apply.LoadPlanFile(applyConfig.executePlan, apply.PlanKey())
+	if err != nil {
+		return nil, err
+	}
+	if err := plan.CheckFiles(); err != nil {
+		return nil, err
+	}
+	log.Infof(
+		"Executing plan %s from %s, covering %d topic(s)",
+		applyConfig.executePlan,
+		plan.CreatedAt.Format(time.RFC3339),
+		len(plan.Topics),
+	)
+
+	applyConfig.allowCleanupPolicyChange = plan.Options.AllowCleanupPolicyChange
+	applyConfig.allowRepartitioning = plan.Options.AllowRepartitioning
+	applyConfig.allowRetentionReduction = plan.Options.AllowRetentionReduction
+	applyConfig.brokersToRemove = plan.Options.BrokersToRemove
+	applyConfig.deleteRetired = plan.Options.DeleteRetired
+	applyConfig.fixRackViolations = plan.Options.FixRackViolations
+	applyConfig.partitionMetrics = plan.Options.PartitionMetrics
+	applyConfig.pathPrefix = ""
+	applyConfig.rebalance = plan.Options.Rebalance
+
+	return plan, nil
+}
+
+// addPlanConfigs records the argument topic and cluster configs in a plan that's being made.
+func addPlanConfigs(plan *apply.Plan, topicConfigPath string, clusterConfigPath string) error {
+	topicConfigPath, err := filepath.Abs(topicConfigPath)
+	if err != nil {
+		return err
+	}
+	clusterConfigPath, err = filepath.Abs(clusterConfigPath)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{topicConfigPath, clusterConfigPath} {
+		if err := plan.AddFile(path); err != nil {
+			return err
+		}
+	}
+	plan.Configs = append(plan.Configs, topicConfigPath)
+	return nil
+}
+
+func applyBrokers(
+	ctx context.Context,
+	brokersConfigPath string,
+	adminClients map[string]*admin.Client,
+	run *artifacts.Run,
+	source *gitSource,
+) error {
+	// Brokers configs are stored next to the cluster config, not in a subdirectory
+	clusterConfigPath := applyConfig.clusterConfig
+	if clusterConfigPath == "" {
+		var err error
+		clusterConfigPath, err = filepath.Abs(
+			filepath.Join(filepath.Dir(brokersConfigPath), "cluster.yaml"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof(
+		"Processing brokers config %s with cluster config %s",
+		brokersConfigPath,
+		clusterConfigPath,
+	)
+
+	brokersConfig, err := config.LoadBrokersFile(brokersConfigPath)
+	if err != nil {
+		return err
+	}
+
+	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := getApplyAdminClient(
+		ctx,
+		clusterConfigPath,
+		clusterConfig,
+		adminClients,
+		source,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := run.AddPlanFile(brokersConfigPath); err != nil {
+		return err
+	}
+	snapshotBrokers(ctx, run, adminClient, "before")
+	defer snapshotBrokers(ctx, run, adminClient, "after")
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, false)
+
+	return cliRunner.ApplyBrokers(
+		ctx,
+		apply.BrokersApplierConfig{
+			AutoApprove:   applyConfig.autoApproveCategories,
+			BrokersConfig: brokersConfig,
+			ClusterConfig: clusterConfig,
+			DryRun:        applyConfig.dryRun,
+			SkipConfirm:   applyConfig.skipConfirm,
+		},
+	)
+}
+
+func applyConnector(ctx context.Context, connectorConfigPath string) error {
+	clusterConfigPath, err := clusterConfigForTopicApply(connectorConfigPath)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Processing connector config %s with cluster config %s",
+		connectorConfigPath,
+		clusterConfigPath,
+	)
+
+	connectorConfig, err := config.LoadConnectorFile(connectorConfigPath)
+	if err != nil {
+		return err
+	}
+
+	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	if err != nil {
+		return err
+	}
+
+	cliRunner := cli.NewCLIRunner(nil, log.Infof, false)
+
+	return cliRunner.ApplyConnector(
+		ctx,
+		apply.ConnectorApplierConfig{
+			AutoApprove:     applyConfig.autoApproveCategories,
+			ClusterConfig:   clusterConfig,
+			ConnectorConfig: connectorConfig,
+			DryRun:          applyConfig.dryRun,
+			SkipConfirm:     applyConfig.skipConfirm,
+		},
+	)
+}
+
+// getApplyAdminClient returns the admin client for the argument cluster config, creating it
+// if it isn't already in the cache. If configs are being applied from git, the ref that was
+// last applied to the cluster is compared against the current one when the client is created.
+func getApplyAdminClient(
+	ctx context.Context,
+	clusterConfigPath string,
+	clusterConfig config.ClusterConfig,
+	adminClients map[string]*admin.Client,
+	source *gitSource,
+) (*admin.Client, error) {
+	if adminClient, ok := adminClients[clusterConfigPath]; ok {
+		return adminClient, nil
+	}
+
+	adminClient, err := clusterConfig.NewAdminClient(ctx, nil, applyConfig.dryRun)
+	if err != nil {
+		return nil, err
+	}
+	adminClients[clusterConfigPath] = adminClient
+
+	if source != nil {
+		if err := source.checkDrift(ctx, adminClient, clusterConfig.Meta.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return adminClient, nil
+}
+
+// recordAppliedRefs stores the applied git ref in each of the clusters that the apply
+// touched. It's a no-op if configs weren't applied from git or this is a dry run.
+func recordAppliedRefs(
+	ctx context.Context,
+	adminClients map[string]*admin.Client,
+	source *gitSource,
+) error {
+	if source == nil {
+		return nil
+	}
+	if applyConfig.dryRun {
+		log.Infof("Not recording applied git ref because dryRun is set to true")
+		return nil
+	}
+
+	for _, adminClient := range adminClients {
+		if err := adminClient.SetAppliedRef(
+			ctx,
+			admin.NewAppliedRef(
+				applyConfig.gitRepo,
+				applyConfig.gitRef,
+				source.sha,
+				applyConfig.gitPath,
+			),
+		); err != nil {
+			return err
+		}
+	}
+	log.Infof("Recorded %s as the applied git ref", source.sha)
+
+	return nil
+}
+
 func clusterConfigForTopicApply(topicConfigPath string) (string, error) {
 	if applyConfig.clusterConfig != "" {
 		return applyConfig.clusterConfig, nil
@@ -210,3 +1003,181 @@ func clusterConfigForTopicApply(topicConfigPath string) (string, error) {
 		),
 	)
 }
+
+// partialApplyError marks the argument error as a partial apply failure if other configs were
+// already applied before it happened.
+func partialApplyError(err error, appliedCount int) error {
+	if appliedCount == 0 || applyConfig.dryRun {
+		return err
+	}
+
+	return exitcode.Wrap(
+		exitcode.KindPartialApply,
+		fmt.Errorf("Apply failed after %d config(s) were applied: %w", appliedCount, err),
+	)
+}
+
+func defaultArtifactsDir() string {
+	if dir := os.Getenv("TOPICCTL_ARTIFACTS_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "topicctl-runs")
+}
+
+func defaultBackupDir() string {
+	if dir := os.Getenv("TOPICCTL_BACKUP_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "topicctl-backups")
+}
+
+func addApplyAuditEntry(run *artifacts.Run, kind string, configPath string, applyErr error) {
+	entry := artifacts.AuditEntry{
+		Kind:       kind,
+		ConfigPath: configPath,
+		Succeeded:  applyErr == nil,
+	}
+	if applyErr != nil {
+		entry.Error = applyErr.Error()
+	}
+
+	if err := run.AddAuditEntry(entry); err != nil {
+		log.Warnf("Error writing audit entry: %+v", err)
+	}
+}
+
+// snapshotTopic stores the current state of the argument topic in the run artifacts.
+// Errors are logged but otherwise ignored so that they don't interrupt the apply.
+func snapshotTopic(
+	ctx context.Context,
+	run *artifacts.Run,
+	adminClient *admin.Client,
+	topic string,
+	suffix string,
+) {
+	topicInfo, err := adminClient.GetTopic(ctx, topic, false)
+	if err == admin.ErrTopicDoesNotExist {
+		return
+	} else if err != nil {
+		log.Warnf("Error getting topic %s for snapshot: %+v", topic, err)
+		return
+	}
+
+	if err := run.AddSnapshot(fmt.Sprintf("topic-%s-%s", topic, suffix), topicInfo); err != nil {
+		log.Warnf("Error writing snapshot for topic %s: %+v", topic, err)
+	}
+}
+
+// snapshotBrokers stores the current state of the cluster brokers in the run artifacts.
+// Errors are logged but otherwise ignored so that they don't interrupt the apply.
+func snapshotBrokers(
+	ctx context.Context,
+	run *artifacts.Run,
+	adminClient *admin.Client,
+	suffix string,
+) {
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		log.Warnf("Error getting brokers for snapshot: %+v", err)
+		return
+	}
+
+	if err := run.AddSnapshot(fmt.Sprintf("brokers-%s", suffix), brokers); err != nil {
+		log.Warnf("Error writing snapshot for brokers: %+v", err)
+	}
+}
+
+// gitSource stores the details of a git checkout that configs are being applied from.
+type gitSource struct {
+	dir string
+	sha string
+}
+
+// checkoutGitSource checks out the configured git repo and ref into a temporary directory.
+// The caller is responsible for removing the directory when done.
+func checkoutGitSource(ctx context.Context) (*gitSource, error) {
+	tempDir, err := ioutil.TempDir("", "topicctl-git")
+	if err != nil {
+		return nil, err
+	}
+
+	sha, err := gitops.Checkout(
+		ctx,
+		applyConfig.gitRepo,
+		applyConfig.gitRef,
+		filepath.Join(tempDir, "repo"),
+	)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+
+	return &gitSource{dir: tempDir, sha: sha}, nil
+}
+
+// configPath converts the argument config path, which is relative to the configured path in
+// the git repo, into a path in the checkout.
+func (s *gitSource) configPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf(
+			"Config path %s must be relative when applying from a git repo",
+			path,
+		)
+	}
+
+	return filepath.Join(s.dir, "repo", applyConfig.gitPath, path), nil
+}
+
+// checkDrift compares the git ref that was last applied to the argument cluster against the
+// current one. Differences are logged but don't stop the apply.
+func (s *gitSource) checkDrift(
+	ctx context.Context,
+	adminClient *admin.Client,
+	clusterName string,
+) error {
+	prevRef, err := adminClient.GetAppliedRef(ctx)
+	if err != nil {
+		return err
+	}
+
+	if prevRef == nil {
+		log.Infof("No git ref has been applied to cluster %s yet", clusterName)
+		return nil
+	}
+
+	if prevRef.RepoURL != applyConfig.gitRepo || prevRef.Path != applyConfig.gitPath {
+		log.Warnf(
+			"Cluster %s was last applied from repo %s (path '%s'), not %s (path '%s')",
+			clusterName,
+			prevRef.RepoURL,
+			prevRef.Path,
+			applyConfig.gitRepo,
+			applyConfig.gitPath,
+		)
+	}
+
+	if prevRef.SHA == s.sha {
+		log.Infof("Cluster %s was last applied at the same SHA (%s)", clusterName, s.sha)
+	} else {
+		log.Warnf(
+			"Cluster %s was last applied at %s (%s) by %s@%s on %s; now applying %s",
+			clusterName,
+			prevRef.SHA,
+			prevRef.Ref,
+			prevRef.User,
+			prevRef.Host,
+			prevRef.Time.Format(time.RFC3339),
+			s.sha,
+		)
+	}
+
+	return nil
+}
+
+func approvalCategoryStrs(categories []apply.ApprovalCategory) []string {
+	strs := []string{}
+	for _, category := range categories {
+		strs = append(strs, string(category))
+	}
+	return strs
+}
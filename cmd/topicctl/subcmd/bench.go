@@ -0,0 +1,307 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/bench"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [subcommand]",
+	Short: "run benchmarks against a cluster",
+}
+
+var benchProduceCmd = &cobra.Command{
+	Use:     "produce [topic]",
+	Short:   "produce synthetic load to a topic and report throughput and latency",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: benchProducePreRun,
+	RunE:    benchProduceRun,
+}
+
+var benchConsumeCmd = &cobra.Command{
+	Use:     "consume [topic]",
+	Short:   "consume from a topic with synthetic consumer groups and report throughput and lag",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: benchConsumePreRun,
+	RunE:    benchConsumeRun,
+}
+
+type benchProduceCmdConfig struct {
+	batchSize       int
+	duration        time.Duration
+	keyDistribution string
+	messageSize     string
+	numKeys         int
+	numWorkers      int
+	skipConfirm     bool
+	throughput      string
+
+	shared sharedOptions
+}
+
+var benchProduceConfig benchProduceCmdConfig
+
+type benchConsumeCmdConfig struct {
+	duration        time.Duration
+	fromBeginning   bool
+	groupPrefix     string
+	lagInterval     time.Duration
+	membersPerGroup int
+	numGroups       int
+	skipConfirm     bool
+
+	shared sharedOptions
+}
+
+var benchConsumeConfig benchConsumeCmdConfig
+
+func init() {
+	benchProduceCmd.Flags().IntVar(
+		&benchProduceConfig.batchSize,
+		"batch-size",
+		100,
+		"Number of messages per produce request",
+	)
+	benchProduceCmd.Flags().DurationVar(
+		&benchProduceConfig.duration,
+		"duration",
+		time.Minute,
+		"Duration of the benchmark",
+	)
+	benchProduceCmd.Flags().StringVar(
+		&benchProduceConfig.keyDistribution,
+		"key-distribution",
+		string(bench.KeyDistributionNone),
+		"Distribution of message keys (one of 'none', 'uniform', 'zipf')",
+	)
+	benchProduceCmd.Flags().StringVar(
+		&benchProduceConfig.messageSize,
+		"message-size",
+		"1KB",
+		"Size of each message value",
+	)
+	benchProduceCmd.Flags().IntVar(
+		&benchProduceConfig.numKeys,
+		"num-keys",
+		1000,
+		"Number of distinct keys; only applies if key-distribution is not 'none'",
+	)
+	benchProduceCmd.Flags().IntVar(
+		&benchProduceConfig.numWorkers,
+		"workers",
+		4,
+		"Number of concurrent producers",
+	)
+	benchProduceCmd.Flags().BoolVar(
+		&benchProduceConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts during benchmark",
+	)
+	benchProduceCmd.Flags().StringVar(
+		&benchProduceConfig.throughput,
+		"throughput",
+		"",
+		"Target throughput across all producers (e.g., '50MB/s'); if blank, produce as fast as possible",
+	)
+	addSharedFlags(benchProduceCmd, &benchProduceConfig.shared)
+
+	benchConsumeCmd.Flags().DurationVar(
+		&benchConsumeConfig.duration,
+		"duration",
+		time.Minute,
+		"Duration of the benchmark",
+	)
+	benchConsumeCmd.Flags().BoolVar(
+		&benchConsumeConfig.fromBeginning,
+		"from-beginning",
+		false,
+		"Start consuming from the oldest offsets instead of the newest ones",
+	)
+	benchConsumeCmd.Flags().StringVar(
+		&benchConsumeConfig.groupPrefix,
+		"group-prefix",
+		"topicctl-bench",
+		"Prefix for the IDs of the synthetic consumer groups",
+	)
+	benchConsumeCmd.Flags().DurationVar(
+		&benchConsumeConfig.lagInterval,
+		"lag-interval",
+		5*time.Second,
+		"Interval at which group lags are sampled",
+	)
+	benchConsumeCmd.Flags().IntVar(
+		&benchConsumeConfig.membersPerGroup,
+		"members-per-group",
+		1,
+		"Number of members in each consumer group",
+	)
+	benchConsumeCmd.Flags().IntVar(
+		&benchConsumeConfig.numGroups,
+		"groups",
+		1,
+		"Number of consumer groups",
+	)
+	benchConsumeCmd.Flags().BoolVar(
+		&benchConsumeConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts during benchmark",
+	)
+	addSharedFlags(benchConsumeCmd, &benchConsumeConfig.shared)
+
+	benchCmd.AddCommand(benchConsumeCmd)
+	benchCmd.AddCommand(benchProduceCmd)
+	RootCmd.AddCommand(benchCmd)
+}
+
+func benchConsumePreRun(cmd *cobra.Command, args []string) error {
+	return benchConsumeConfig.shared.validate()
+}
+
+func benchConsumeRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	adminClient, err := benchConsumeConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	topic := args[0]
+	if _, err := adminClient.GetTopic(ctx, topic, false); err != nil {
+		return err
+	}
+
+	consumeConfig := bench.ConsumeConfig{
+		BrokerAddrs:     adminClient.GetBootstrapAddrs(),
+		Topic:           topic,
+		Duration:        benchConsumeConfig.duration,
+		NumGroups:       benchConsumeConfig.numGroups,
+		MembersPerGroup: benchConsumeConfig.membersPerGroup,
+		GroupPrefix:     benchConsumeConfig.groupPrefix,
+		FromBeginning:   benchConsumeConfig.fromBeginning,
+		LagInterval:     benchConsumeConfig.lagInterval,
+	}
+	if err := consumeConfig.Validate(); err != nil {
+		return err
+	}
+
+	log.Infof(
+		"This will consume from topic %s for %s using %d new consumer group(s) with %d member(s) each.",
+		topic,
+		benchConsumeConfig.duration,
+		benchConsumeConfig.numGroups,
+		benchConsumeConfig.membersPerGroup,
+	)
+
+	ok, _ := apply.Confirm("OK to continue?", benchConsumeConfig.skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	results, err := bench.RunConsumeBenchmark(ctx, consumeConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Consume benchmark results over %s:\n%s",
+		results.Elapsed.Round(time.Second),
+		bench.FormatConsumeResults(results),
+	)
+	return nil
+}
+
+func benchProducePreRun(cmd *cobra.Command, args []string) error {
+	return benchProduceConfig.shared.validate()
+}
+
+func benchProduceRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	messageSize, err := util.ParseBytes(benchProduceConfig.messageSize)
+	if err != nil {
+		return err
+	}
+
+	var throughput int64
+	if benchProduceConfig.throughput != "" {
+		throughput, err = util.ParseBytes(benchProduceConfig.throughput)
+		if err != nil {
+			return err
+		}
+	}
+
+	adminClient, err := benchProduceConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	topic := args[0]
+	if _, err := adminClient.GetTopic(ctx, topic, false); err != nil {
+		return err
+	}
+
+	produceConfig := bench.ProduceConfig{
+		BrokerAddrs:     adminClient.GetBootstrapAddrs(),
+		Topic:           topic,
+		Duration:        benchProduceConfig.duration,
+		MessageSize:     int(messageSize),
+		BatchSize:       benchProduceConfig.batchSize,
+		NumWorkers:      benchProduceConfig.numWorkers,
+		KeyDistribution: bench.KeyDistribution(benchProduceConfig.keyDistribution),
+		NumKeys:         benchProduceConfig.numKeys,
+		ThroughputBytes: throughput,
+	}
+	if err := produceConfig.Validate(); err != nil {
+		return err
+	}
+
+	log.Infof(
+		"This will write synthetic %s messages to topic %s for %s.",
+		util.PrettyBytes(messageSize),
+		topic,
+		benchProduceConfig.duration,
+	)
+
+	ok, _ := apply.Confirm("OK to continue?", benchProduceConfig.skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	results, err := bench.RunProduceBenchmark(ctx, produceConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Produce benchmark results:\n%s", bench.FormatProduceResults(results))
+	return nil
+}
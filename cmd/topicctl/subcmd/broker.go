@@ -0,0 +1,136 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/spf13/cobra"
+)
+
+var brokerCmd = &cobra.Command{
+	Use:   "broker [subcommand]",
+	Short: "run broker-level operations",
+}
+
+var brokerMaintenanceCmd = &cobra.Command{
+	Use:     "maintenance [broker id]",
+	Short:   "start or end maintenance mode for a broker",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: brokerMaintenancePreRun,
+	RunE:    brokerMaintenanceRun,
+}
+
+type brokerMaintenanceCmdConfig struct {
+	dryRun        bool
+	off           bool
+	on            bool
+	reason        string
+	skipConfirm   bool
+	sleepLoopTime time.Duration
+
+	shared sharedOptions
+}
+
+var brokerMaintenanceConfig brokerMaintenanceCmdConfig
+
+func init() {
+	brokerMaintenanceCmd.Flags().BoolVar(
+		&brokerMaintenanceConfig.dryRun,
+		"dry-run",
+		false,
+		"Do a dry-run",
+	)
+	brokerMaintenanceCmd.Flags().BoolVar(
+		&brokerMaintenanceConfig.off,
+		"off",
+		false,
+		"End maintenance and restore the broker's leaders",
+	)
+	brokerMaintenanceCmd.Flags().BoolVar(
+		&brokerMaintenanceConfig.on,
+		"on",
+		false,
+		"Start maintenance and move leadership away from the broker",
+	)
+	brokerMaintenanceCmd.Flags().StringVar(
+		&brokerMaintenanceConfig.reason,
+		"reason",
+		"",
+		"Reason for the maintenance, recorded in zk",
+	)
+	brokerMaintenanceCmd.Flags().BoolVar(
+		&brokerMaintenanceConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	brokerMaintenanceCmd.Flags().DurationVar(
+		&brokerMaintenanceConfig.sleepLoopTime,
+		"sleep-loop-time",
+		10*time.Second,
+		"Amount of time to wait between reassignment and election checks",
+	)
+	addSharedFlags(brokerMaintenanceCmd, &brokerMaintenanceConfig.shared)
+
+	brokerCmd.AddCommand(brokerMaintenanceCmd)
+	RootCmd.AddCommand(brokerCmd)
+}
+
+func brokerMaintenancePreRun(cmd *cobra.Command, args []string) error {
+	if brokerMaintenanceConfig.on == brokerMaintenanceConfig.off {
+		return errors.New("Must set exactly one of on or off")
+	}
+	return brokerMaintenanceConfig.shared.validate()
+}
+
+func brokerMaintenanceRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	brokerID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("Could not parse broker id %s: %+v", args[0], err)
+	}
+
+	adminClient, err := brokerMaintenanceConfig.shared.getAdminClient(
+		ctx,
+		nil,
+		brokerMaintenanceConfig.dryRun,
+	)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	applier, err := apply.NewMaintenanceApplier(
+		ctx,
+		adminClient,
+		apply.MaintenanceApplierConfig{
+			BrokerID:      brokerID,
+			DryRun:        brokerMaintenanceConfig.dryRun,
+			Enable:        brokerMaintenanceConfig.on,
+			Reason:        brokerMaintenanceConfig.reason,
+			SkipConfirm:   brokerMaintenanceConfig.skipConfirm,
+			SleepLoopTime: brokerMaintenanceConfig.sleepLoopTime,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return applier.Apply(ctx)
+}
@@ -2,29 +2,38 @@ package subcmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/check"
 	"github.com/segmentio/topicctl/pkg/cli"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var checkCmd = &cobra.Command{
-	Use:   "check [topic configs]",
+	Use:   "check [topic or connector configs]",
 	Short: "check that configs are valid and (optionally) match cluster state",
 	RunE:  checkRun,
 }
 
 type checkCmdConfig struct {
-	clusterConfig string
-	checkLeaders  bool
-	pathPrefix    string
-	validateOnly  bool
+	brokerConfigs   string
+	clusterConfig   string
+	checkLeaders    bool
+	checkPlacement  bool
+	match           string
+	parallelism     int
+	pathPrefix      string
+	recreatedWindow time.Duration
+	validateOnly    bool
 }
 
 var checkConfig checkCmdConfig
@@ -36,6 +45,12 @@ func init() {
 		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
 		"Cluster config",
 	)
+	checkCmd.Flags().StringVar(
+		&checkConfig.brokerConfigs,
+		"broker-configs",
+		"",
+		"Path to a brokers config to compare the dynamic broker settings in the cluster against",
+	)
 	checkCmd.Flags().StringVar(
 		&checkConfig.pathPrefix,
 		"path-prefix",
@@ -48,6 +63,30 @@ func init() {
 		false,
 		"Check leaders",
 	)
+	checkCmd.Flags().BoolVar(
+		&checkConfig.checkPlacement,
+		"placement",
+		false,
+		"Check that partition assignments satisfy the placement strategy given the current broker racks",
+	)
+	checkCmd.Flags().StringVar(
+		&checkConfig.match,
+		"match",
+		"",
+		"Only check topic configs whose topic names match this glob, or regex if wrapped in slashes",
+	)
+	checkCmd.Flags().IntVar(
+		&checkConfig.parallelism,
+		"parallelism",
+		8,
+		"Number of configs to check at the same time",
+	)
+	checkCmd.Flags().DurationVar(
+		&checkConfig.recreatedWindow,
+		"recreated-window",
+		admin.DefaultRecreatedWindow,
+		"Flag topics that were re-created since they were last applied within this window; 0 for no limit",
+	)
 	checkCmd.Flags().BoolVar(
 		&checkConfig.validateOnly,
 		"validate-only",
@@ -70,8 +109,28 @@ func checkRun(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
-	matchCount := 0
-	okCount := 0
+	var topicMatcher *util.TopicMatcher
+	if checkConfig.match != "" {
+		var err error
+		topicMatcher, err = util.NewTopicMatcher(checkConfig.match)
+		if err != nil {
+			return err
+		}
+	}
+
+	if checkConfig.parallelism < 1 {
+		return exitcode.Wrap(exitcode.KindConfig, errors.New("parallelism must be at least 1"))
+	}
+
+	items := []*checkItem{}
+
+	if checkConfig.brokerConfigs != "" {
+		item, err := loadBrokersCheck(ctx, checkConfig.brokerConfigs, adminClients)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
 
 	for _, arg := range args {
 		if checkConfig.pathPrefix != "" && !filepath.IsAbs(arg) {
@@ -84,40 +143,158 @@ func checkRun(cmd *cobra.Command, args []string) error {
 		}
 
 		for _, match := range matches {
-			matchCount++
-
-			ok, err := checkTopic(ctx, match, adminClients)
+			kind, err := config.LoadKindFile(match)
 			if err != nil {
 				return err
 			}
 
-			if ok {
-				okCount++
+			if topicMatcher != nil {
+				if kind == config.ConnectorKind {
+					log.Debugf("Skipping connector config %s because match is set", match)
+					continue
+				}
+
+				topicConfig, err := config.LoadTopicFile(match)
+				if err != nil {
+					return err
+				}
+				if !topicMatcher.Matches(topicConfig.Meta.Name) {
+					log.Debugf("Skipping topic config %s that doesn't match %s", match, topicMatcher)
+					continue
+				}
+			}
+
+			var item *checkItem
+			if kind == config.ConnectorKind {
+				item, err = loadConnectorCheck(match)
+			} else {
+				item, err = loadTopicCheck(ctx, match, adminClients)
 			}
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
 		}
 	}
 
+	startTime := time.Now()
+
+	if !checkConfig.validateOnly {
+		if err := setClusterStates(ctx, items); err != nil {
+			return err
+		}
+	}
+
+	// Run the checks in parallel, but print the results in order
+	err := util.RunParallel(
+		ctx,
+		len(items),
+		checkConfig.parallelism,
+		func(ctx context.Context, i int) error {
+			return items[i].run(ctx)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	matchCount := len(items)
+	okCount := 0
+	invalidCount := 0
+
+	cliRunner := cli.NewCLIRunner(nil, log.Infof, false)
+
+	for _, item := range items {
+		switch {
+		case item.topicCheck != nil:
+			cliRunner.PrintTopicCheckResults(*item.topicCheck, item.results)
+		case item.brokersCheck != nil:
+			cliRunner.PrintBrokersCheckResults(*item.brokersCheck, item.results)
+		default:
+			cliRunner.PrintConnectorCheckResults(*item.connectorCheck, item.results)
+		}
+
+		if item.results.AllOK() {
+			okCount++
+		} else if !item.results.ValidationOK() {
+			invalidCount++
+		}
+	}
+
+	log.Debugf(
+		"Checked %d config(s) in %s with parallelism %d",
+		matchCount,
+		time.Since(startTime),
+		checkConfig.parallelism,
+	)
+
 	if matchCount == 0 {
-		return fmt.Errorf("No topic configs match the provided args (%+v)", args)
+		return exitcode.Wrap(
+			exitcode.KindConfig,
+			fmt.Errorf("No configs match the provided args (%+v)", args),
+		)
 	} else if matchCount > okCount {
-		return fmt.Errorf(
-			"Check failed for %d/%d topic configs",
-			matchCount-okCount,
-			matchCount,
+		// Invalid configs take precedence over drift since they need to be fixed first
+		kind := exitcode.KindDrift
+		if invalidCount > 0 {
+			kind = exitcode.KindValidation
+		}
+
+		return exitcode.Wrap(
+			kind,
+			fmt.Errorf(
+				"Check failed for %d/%d configs",
+				matchCount-okCount,
+				matchCount,
+			),
 		)
 	}
 
 	return nil
 }
 
-func checkTopic(
+// checkItem is a single topic, connector, or brokers config to check.
+type checkItem struct {
+	path string
+
+	// Exactly one of these is set
+	topicCheck     *check.CheckConfig
+	connectorCheck *check.ConnectorCheckConfig
+	brokersCheck   *check.BrokersCheckConfig
+
+	results  check.TopicCheckResults
+	duration time.Duration
+}
+
+func (c *checkItem) run(ctx context.Context) error {
+	startTime := time.Now()
+
+	var err error
+	switch {
+	case c.topicCheck != nil:
+		c.results, err = check.CheckTopic(ctx, *c.topicCheck)
+	case c.brokersCheck != nil:
+		c.results, err = check.CheckBrokers(ctx, *c.brokersCheck)
+	default:
+		c.results, err = check.CheckConnector(ctx, *c.connectorCheck)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.duration = time.Since(startTime)
+	log.Debugf("Checked %s in %s", c.path, c.duration)
+	return nil
+}
+
+func loadTopicCheck(
 	ctx context.Context,
 	topicConfigPath string,
 	adminClients map[string]*admin.Client,
-) (bool, error) {
+) (*checkItem, error) {
 	clusterConfigPath, err := clusterConfigForTopicCheck(topicConfigPath)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	log.Debugf(
@@ -126,15 +303,112 @@ func checkTopic(
 		clusterConfigPath,
 	)
 
+	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
 	topicConfig, err := config.LoadTopicFile(topicConfigPath)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	if err := topicConfig.ResolveClusterDefaults(clusterConfig); err != nil {
+		return nil, err
+	}
+
+	var adminClient *admin.Client
+
+	if !checkConfig.validateOnly {
+		var ok bool
+		adminClient, ok = adminClients[clusterConfigPath]
+		if !ok {
+			adminClient, err = clusterConfig.NewAdminClient(ctx, nil, true)
+			if err != nil {
+				return nil, err
+			}
+			adminClients[clusterConfigPath] = adminClient
+		}
+	}
+
+	return &checkItem{
+		path: topicConfigPath,
+		topicCheck: &check.CheckConfig{
+			AdminClient:    adminClient,
+			CheckLeaders:   checkConfig.checkLeaders,
+			CheckPlacement: checkConfig.checkPlacement,
+			ClusterConfig:  clusterConfig,
+			// TODO: Add support for broker rack verification.
+			NumRacks:        -1,
+			RecreatedWindow: checkConfig.recreatedWindow,
+			TopicConfig:     topicConfig,
+			ValidateOnly:    checkConfig.validateOnly,
+		},
+	}, nil
+}
+
+func loadConnectorCheck(connectorConfigPath string) (*checkItem, error) {
+	clusterConfigPath, err := clusterConfigForTopicCheck(connectorConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf(
+		"Processing connector config %s with cluster config %s",
+		connectorConfigPath,
+		clusterConfigPath,
+	)
+
+	connectorConfig, err := config.LoadConnectorFile(connectorConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &checkItem{
+		path: connectorConfigPath,
+		connectorCheck: &check.ConnectorCheckConfig{
+			ClusterConfig:   clusterConfig,
+			ConnectorConfig: connectorConfig,
+			ValidateOnly:    checkConfig.validateOnly,
+		},
+	}, nil
+}
+
+func loadBrokersCheck(
+	ctx context.Context,
+	brokersConfigPath string,
+	adminClients map[string]*admin.Client,
+) (*checkItem, error) {
+	// Brokers configs are stored next to the cluster config, not in a subdirectory
+	clusterConfigPath := checkConfig.clusterConfig
+	if clusterConfigPath == "" {
+		var err error
+		clusterConfigPath, err = filepath.Abs(
+			filepath.Join(filepath.Dir(brokersConfigPath), "cluster.yaml"),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Debugf(
+		"Processing brokers config %s with cluster config %s",
+		brokersConfigPath,
+		clusterConfigPath,
+	)
+
+	brokersConfig, err := config.LoadBrokersFile(brokersConfigPath)
+	if err != nil {
+		return nil, err
 	}
-	topicConfig.SetDefaults()
 
 	clusterConfig, err := config.LoadClusterFile(clusterConfigPath)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	var adminClient *admin.Client
@@ -145,26 +419,59 @@ func checkTopic(
 		if !ok {
 			adminClient, err = clusterConfig.NewAdminClient(ctx, nil, true)
 			if err != nil {
-				return false, err
+				return nil, err
 			}
 			adminClients[clusterConfigPath] = adminClient
 		}
 	}
 
-	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, false)
-	topicCheckConfig := check.CheckConfig{
-		AdminClient:   adminClient,
-		CheckLeaders:  checkConfig.checkLeaders,
-		ClusterConfig: clusterConfig,
-		// TODO: Add support for broker rack verification.
-		NumRacks:     -1,
-		TopicConfig:  topicConfig,
-		ValidateOnly: checkConfig.validateOnly,
+	return &checkItem{
+		path: brokersConfigPath,
+		brokersCheck: &check.BrokersCheckConfig{
+			AdminClient:   adminClient,
+			BrokersConfig: brokersConfig,
+			ClusterConfig: clusterConfig,
+			ValidateOnly:  checkConfig.validateOnly,
+		},
+	}, nil
+}
+
+// setClusterStates fetches the metadata of each cluster once, for all of the topics being
+// checked in it, and shares it across the topic checks.
+func setClusterStates(ctx context.Context, items []*checkItem) error {
+	clusterChecks := map[*admin.Client][]*check.CheckConfig{}
+	for _, item := range items {
+		if item.topicCheck != nil {
+			clusterChecks[item.topicCheck.AdminClient] = append(
+				clusterChecks[item.topicCheck.AdminClient],
+				item.topicCheck,
+			)
+		}
 	}
-	return cliRunner.CheckTopic(
-		ctx,
-		topicCheckConfig,
-	)
+
+	for adminClient, topicChecks := range clusterChecks {
+		topicNames := []string{}
+		for _, topicCheck := range topicChecks {
+			topicNames = append(topicNames, topicCheck.TopicConfig.Meta.Name)
+		}
+
+		startTime := time.Now()
+		clusterState, err := check.GetClusterState(ctx, adminClient, topicNames)
+		if err != nil {
+			return err
+		}
+		log.Debugf(
+			"Fetched metadata for %d topic(s) in %s",
+			len(topicNames),
+			time.Since(startTime),
+		)
+
+		for _, topicCheck := range topicChecks {
+			topicCheck.ClusterState = clusterState
+		}
+	}
+
+	return nil
 }
 
 func clusterConfigForTopicCheck(topicConfigPath string) (string, error) {
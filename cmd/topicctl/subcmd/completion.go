@@ -0,0 +1,171 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/completion"
+	"github.com/segmentio/topicctl/pkg/groups"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish]",
+	Short: "generate a shell completion script",
+	Long: strings.Join(
+		[]string{
+			"Generate a shell completion script for bash, zsh, or fish.",
+			"",
+			"In bash and fish, topic and group names are completed dynamically by looking them up in the target cluster.",
+		},
+		"\n",
+	),
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE:      completionRun,
+}
+
+func init() {
+	RootCmd.AddCommand(completionCmd)
+}
+
+func completionRun(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		return RootCmd.GenBashCompletion(os.Stdout)
+	case "zsh":
+		return RootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return RootCmd.GenFishCompletion(os.Stdout, true)
+	default:
+		return fmt.Errorf("Unsupported shell: %s", args[0])
+	}
+}
+
+// completionMetadata returns the (possibly cached) topic and group names for the cluster
+// targeted by the argument options. Completions run in a separate process that's invoked
+// by the shell, so errors are swallowed and logging is disabled to avoid cluttering the
+// user's terminal.
+func completionMetadata(options sharedOptions) (completion.Metadata, bool) {
+	log.SetOutput(ioutil.Discard)
+
+	if options.validate() != nil {
+		return completion.Metadata{}, false
+	}
+
+	key := fmt.Sprintf("zk:%s%s", options.zkAddr, options.zkPrefix)
+	if options.clusterConfig != "" {
+		absPath, err := filepath.Abs(options.clusterConfig)
+		if err != nil {
+			return completion.Metadata{}, false
+		}
+		key = fmt.Sprintf("cluster-config:%s", absPath)
+	}
+
+	cacheDir, err := completion.DefaultCacheDir()
+	if err != nil {
+		return completion.Metadata{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	metadata, err := completion.NewCache(cacheDir, completion.DefaultCacheTTL).Get(
+		ctx,
+		key,
+		func(ctx context.Context) (completion.Metadata, error) {
+			metadata := completion.Metadata{}
+
+			adminClient, err := options.getAdminClient(ctx, nil, true)
+			if err != nil {
+				return metadata, err
+			}
+			defer adminClient.Close()
+
+			metadata.Topics, err = adminClient.GetTopicNames(ctx)
+			if err != nil {
+				return metadata, err
+			}
+
+			// Group lookups can partially fail; use whatever groups are returned
+			groupCoordinators, _ := groups.NewClient(
+				adminClient.GetBootstrapAddrs()[0],
+			).GetGroups(ctx)
+			for _, groupCoordinator := range groupCoordinators {
+				metadata.Groups = append(metadata.Groups, groupCoordinator.GroupID)
+			}
+
+			return metadata, nil
+		},
+	)
+	if err != nil {
+		return metadata, false
+	}
+	return metadata, true
+}
+
+// completeTopics returns the topic names that complete the argument prefix, excluding any
+// that are already in args.
+func completeTopics(
+	options sharedOptions,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	metadata, ok := completionMetadata(options)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.FilterPrefix(metadata.Topics, toComplete, args...),
+		cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroups returns the consumer group IDs that complete the argument prefix.
+func completeGroups(
+	options sharedOptions,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	metadata, ok := completionMetadata(options)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.FilterPrefix(metadata.Groups, toComplete),
+		cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigFiles returns the YAML files and directories that complete the argument
+// path prefix.
+func completeConfigFiles(toComplete string) ([]string, cobra.ShellCompDirective) {
+	matches, err := filepath.Glob(toComplete + "*")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := []string{}
+	hasDirs := false
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			completions = append(completions, match+string(filepath.Separator))
+			hasDirs = true
+		} else if ext := filepath.Ext(match); ext == ".yaml" || ext == ".yml" {
+			completions = append(completions, match)
+		}
+	}
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	if hasDirs {
+		// Don't add a space after directories so that their contents can be completed next
+		directive |= cobra.ShellCompDirectiveNoSpace
+	}
+	return completions, directive
+}
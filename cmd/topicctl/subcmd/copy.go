@@ -0,0 +1,86 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var copyOffsetsCmd = &cobra.Command{
+	Use:     "copy-offsets [topic name] [source group name] [destination group name]",
+	Short:   "copy committed offsets from one consumer group to another",
+	Args:    cobra.ExactArgs(3),
+	PreRunE: copyOffsetsPreRun,
+	RunE:    copyOffsetsRun,
+}
+
+type copyOffsetsCmdConfig struct {
+	offsetShift int64
+	skipConfirm bool
+	timeShift   time.Duration
+
+	shared sharedOptions
+}
+
+var copyOffsetsConfig copyOffsetsCmdConfig
+
+func init() {
+	copyOffsetsCmd.Flags().Int64Var(
+		&copyOffsetsConfig.offsetShift,
+		"offset-shift",
+		0,
+		"Number of messages to shift each offset by; negative values move offsets back",
+	)
+	copyOffsetsCmd.Flags().BoolVar(
+		&copyOffsetsConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts during copy process",
+	)
+	copyOffsetsCmd.Flags().DurationVar(
+		&copyOffsetsConfig.timeShift,
+		"time-shift",
+		0,
+		"Duration to shift each offset by, based on message times; negative values move offsets back (e.g., -1h)",
+	)
+	addSharedFlags(copyOffsetsCmd, &copyOffsetsConfig.shared)
+
+	RootCmd.AddCommand(copyOffsetsCmd)
+}
+
+func copyOffsetsPreRun(cmd *cobra.Command, args []string) error {
+	if args[1] == args[2] {
+		return errors.New("Source and destination groups must be different")
+	}
+	if copyOffsetsConfig.offsetShift != 0 && copyOffsetsConfig.timeShift != 0 {
+		return errors.New("Cannot set both offset-shift and time-shift")
+	}
+
+	return copyOffsetsConfig.shared.validate()
+}
+
+func copyOffsetsRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	adminClient, err := copyOffsetsConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, true)
+	return cliRunner.CopyOffsets(
+		ctx,
+		args[0],
+		args[1],
+		args[2],
+		copyOffsetsConfig.offsetShift,
+		copyOffsetsConfig.timeShift,
+		copyOffsetsConfig.skipConfirm,
+	)
+}
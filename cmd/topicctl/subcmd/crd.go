@@ -0,0 +1,238 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var crdCmd = &cobra.Command{
+	Use:   "crd [subcommand]",
+	Short: "convert topic configs to and from Kubernetes KafkaTopic resources",
+}
+
+var crdExportCmd = &cobra.Command{
+	Use:   "export [topic configs]",
+	Short: "export topic configs as Strimzi KafkaTopic resources",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  crdExportRun,
+}
+
+var crdImportCmd = &cobra.Command{
+	Use:   "import [resource files]",
+	Short: "convert Strimzi KafkaTopic resources into topic configs",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  crdImportRun,
+}
+
+type crdExportCmdConfig struct {
+	namespace      string
+	outputDir      string
+	overwrite      bool
+	strimziCluster string
+}
+
+var crdExportConfig crdExportCmdConfig
+
+type crdImportCmdConfig struct {
+	clusterConfig string
+	outputDir     string
+	overwrite     bool
+}
+
+var crdImportConfig crdImportCmdConfig
+
+func init() {
+	crdExportCmd.Flags().StringVar(
+		&crdExportConfig.namespace,
+		"namespace",
+		"",
+		"Kubernetes namespace for the resources",
+	)
+	crdExportCmd.Flags().StringVarP(
+		&crdExportConfig.outputDir,
+		"output",
+		"o",
+		"",
+		"Output directory; if not set, resources are printed to stdout",
+	)
+	crdExportCmd.Flags().BoolVar(
+		&crdExportConfig.overwrite,
+		"overwrite",
+		false,
+		"Overwrite existing resources in output directory",
+	)
+	crdExportCmd.Flags().StringVar(
+		&crdExportConfig.strimziCluster,
+		"strimzi-cluster",
+		"",
+		"Value for the strimzi.io/cluster label; defaults to the cluster name in each config",
+	)
+
+	crdImportCmd.Flags().StringVar(
+		&crdImportConfig.clusterConfig,
+		"cluster-config",
+		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
+		"Cluster config, used for resources that weren't exported by topicctl",
+	)
+	crdImportCmd.Flags().StringVarP(
+		&crdImportConfig.outputDir,
+		"output",
+		"o",
+		"",
+		"Output directory; if not set, configs are printed to stdout",
+	)
+	crdImportCmd.Flags().BoolVar(
+		&crdImportConfig.overwrite,
+		"overwrite",
+		false,
+		"Overwrite existing configs in output directory",
+	)
+
+	crdCmd.AddCommand(crdExportCmd)
+	crdCmd.AddCommand(crdImportCmd)
+	RootCmd.AddCommand(crdCmd)
+}
+
+func crdExportRun(cmd *cobra.Command, args []string) error {
+	documents := []string{}
+
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			kind, err := config.LoadKindFile(match)
+			if err != nil {
+				return err
+			}
+			if kind != "" {
+				log.Infof("Skipping non-topic config %s", match)
+				continue
+			}
+
+			topicConfig, err := config.LoadTopicFile(match)
+			if err != nil {
+				return err
+			}
+			resource, err := topicConfig.ToKafkaTopicResource(
+				crdExportConfig.namespace,
+				crdExportConfig.strimziCluster,
+			)
+			if err != nil {
+				return fmt.Errorf("Error converting %s: %+v", match, err)
+			}
+			yamlStr, err := resource.ToYAML()
+			if err != nil {
+				return err
+			}
+
+			if crdExportConfig.outputDir != "" {
+				outputPath := filepath.Join(
+					crdExportConfig.outputDir,
+					fmt.Sprintf("%s.yaml", resource.Metadata.Name),
+				)
+				if err := writeOutputFile(outputPath, yamlStr, crdExportConfig.overwrite); err != nil {
+					return err
+				}
+			} else {
+				documents = append(documents, yamlStr)
+			}
+		}
+	}
+
+	if len(documents) > 0 {
+		fmt.Print("---\n" + strings.Join(documents, "---\n"))
+	}
+
+	return nil
+}
+
+func crdImportRun(cmd *cobra.Command, args []string) error {
+	var clusterConfig config.ClusterConfig
+
+	if crdImportConfig.clusterConfig != "" {
+		var err error
+		clusterConfig, err = config.LoadClusterFile(crdImportConfig.clusterConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	numResources := 0
+
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			resources, err := config.LoadKafkaTopicResourcesFile(match)
+			if err != nil {
+				return fmt.Errorf("Error loading %s: %+v", match, err)
+			}
+
+			for _, resource := range resources {
+				numResources++
+
+				topicConfig, err := config.TopicConfigFromKafkaTopicResource(
+					resource,
+					clusterConfig,
+				)
+				if err != nil {
+					return err
+				}
+				yamlStr, err := topicConfig.ToYAML()
+				if err != nil {
+					return err
+				}
+
+				if crdImportConfig.outputDir != "" {
+					outputPath := filepath.Join(
+						crdImportConfig.outputDir,
+						fmt.Sprintf("%s.yaml", topicConfig.Meta.Name),
+					)
+					err := writeOutputFile(outputPath, yamlStr, crdImportConfig.overwrite)
+					if err != nil {
+						return err
+					}
+				} else {
+					log.Infof("Config for topic %s:\n%s", topicConfig.Meta.Name, yamlStr)
+				}
+			}
+		}
+	}
+
+	if numResources == 0 {
+		return errors.New("No KafkaTopic resources found in the provided files")
+	}
+
+	return nil
+}
+
+// writeOutputFile writes the argument contents to a file, skipping over existing files
+// unless overwrite is set.
+func writeOutputFile(outputPath string, contents string, overwrite bool) error {
+	_, err := os.Stat(outputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if os.IsNotExist(err) || overwrite {
+		log.Infof("Writing %s", outputPath)
+		return ioutil.WriteFile(outputPath, []byte(contents), 0644)
+	}
+
+	log.Infof("Skipping over existing file %s", outputPath)
+	return nil
+}
@@ -0,0 +1,209 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/cli"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/create"
+	"github.com/segmentio/topicctl/pkg/lint"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "interactively generate a topic config and optionally apply it",
+	Args:  cobra.NoArgs,
+	RunE:  createRun,
+}
+
+type createCmdConfig struct {
+	clusterConfig string
+	namePattern   string
+	outputDir     string
+}
+
+var createConfig createCmdConfig
+
+func init() {
+	createCmd.Flags().StringVar(
+		&createConfig.clusterConfig,
+		"cluster-config",
+		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
+		"Cluster config",
+	)
+	createCmd.Flags().StringVar(
+		&createConfig.namePattern,
+		"name-pattern",
+		lint.DefaultNamePattern,
+		"Regular expression that topic names must match; set to empty to disable",
+	)
+	createCmd.Flags().StringVarP(
+		&createConfig.outputDir,
+		"output",
+		"o",
+		"",
+		"Output directory; defaults to the topics directory next to the cluster config",
+	)
+
+	createCmd.MarkFlagRequired("cluster-config")
+
+	RootCmd.AddCommand(createCmd)
+}
+
+func createRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	var namePattern *regexp.Regexp
+	if createConfig.namePattern != "" {
+		var err error
+		namePattern, err = regexp.Compile(createConfig.namePattern)
+		if err != nil {
+			return fmt.Errorf("Invalid name pattern: %+v", err)
+		}
+	}
+
+	outputDir := createConfig.outputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(filepath.Dir(createConfig.clusterConfig), "topics")
+	}
+
+	clusterConfig, err := config.LoadClusterFile(createConfig.clusterConfig)
+	if err != nil {
+		return err
+	}
+	adminClient, err := clusterConfig.NewAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return err
+	}
+	numRacks := len(admin.DistinctRacks(brokers))
+
+	prompter := create.NewPrompter(os.Stdin, os.Stdout)
+
+	answers, err := create.AskTopicAnswers(
+		prompter,
+		clusterConfig,
+		func(name string) error {
+			if namePattern != nil && !namePattern.MatchString(name) {
+				return fmt.Errorf(
+					"Name does not match naming convention %s",
+					namePattern.String(),
+				)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, name+".yaml")); err == nil {
+				return fmt.Errorf("A config for %s already exists in %s", name, outputDir)
+			}
+
+			_, err := adminClient.GetTopic(ctx, name, false)
+			if err == nil {
+				return fmt.Errorf("Topic %s already exists in the cluster", name)
+			} else if err != admin.ErrTopicDoesNotExist {
+				return err
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	topicConfig := create.GenerateTopicConfig(clusterConfig, answers, numRacks)
+	yamlStr, err := topicConfig.ToYAML()
+	if err != nil {
+		return err
+	}
+
+	outputPath := filepath.Join(outputDir, fmt.Sprintf("%s.yaml", topicConfig.Meta.Name))
+
+	// Run the generated config through the same checks as the lint command, along with the
+	// rack-based validation that needs the cluster
+	results := lint.LintTopicBytes(
+		outputPath,
+		[]byte(yamlStr),
+		lint.Options{NamePattern: namePattern},
+	)
+	for _, result := range results {
+		log.Warn(result.String())
+	}
+	if lint.HasErrors(results) {
+		return fmt.Errorf("Generated config for topic %s is invalid", topicConfig.Meta.Name)
+	}
+
+	appliedConfig := topicConfig
+	appliedConfig.InheritDefaults(clusterConfig.Spec.TopicDefaults)
+	appliedConfig.SetDefaults()
+	if err := appliedConfig.Validate(numRacks); err != nil {
+		return err
+	}
+
+	log.Infof("Generated config for topic %s:\n%s", topicConfig.Meta.Name, yamlStr)
+
+	ok, err := prompter.AskBool(fmt.Sprintf("Write config to %s?", outputPath), true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Info("Not writing config")
+		return nil
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outputPath, []byte(yamlStr), 0644); err != nil {
+		return err
+	}
+	log.Infof("Wrote config to %s", outputPath)
+
+	ok, err = prompter.AskBool("Apply it now?", false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Infof("The topic can be created later by running: topicctl apply %s", outputPath)
+		return nil
+	}
+
+	locker, err := clusterConfig.NewLocker(adminClient, nil)
+	if err != nil {
+		return err
+	}
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, false)
+	return cliRunner.ApplyTopic(
+		ctx,
+		apply.TopicApplierConfig{
+			ClusterConfig: clusterConfig,
+			Locker:        locker,
+			// The apply was already confirmed above
+			SkipConfirm:   true,
+			SleepLoopTime: 10 * time.Second,
+			TopicConfig:   appliedConfig,
+		},
+	)
+}
@@ -0,0 +1,83 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/topicctl/pkg/cli"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete [resource type]",
+	Short: "delete instances of a particular type",
+	Long: strings.Join(
+		[]string{
+			"Delete instances of a particular type.",
+			"Supported types currently include: group and group-offsets.",
+			"",
+			"See the tool README for a detailed description of each one.",
+		},
+		"\n",
+	),
+	Args:    cobra.MinimumNArgs(1),
+	PreRunE: deletePreRun,
+	RunE:    deleteRun,
+}
+
+type deleteCmdConfig struct {
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var deleteConfig deleteCmdConfig
+
+func init() {
+	deleteCmd.Flags().BoolVar(
+		&deleteConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts during delete process",
+	)
+	addSharedFlags(deleteCmd, &deleteConfig.shared)
+
+	RootCmd.AddCommand(deleteCmd)
+}
+
+func deletePreRun(cmd *cobra.Command, args []string) error {
+	return deleteConfig.shared.validate()
+}
+
+func deleteRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	adminClient, err := deleteConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, true)
+
+	resource := args[0]
+
+	switch resource {
+	case "group":
+		if len(args) != 2 {
+			return fmt.Errorf("Must provide group ID as second positional argument")
+		}
+
+		return cliRunner.DeleteGroup(ctx, args[1], deleteConfig.skipConfirm)
+	case "group-offsets":
+		if len(args) != 3 {
+			return fmt.Errorf("Must provide group ID and topic as additional positional arguments")
+		}
+
+		return cliRunner.DeleteGroupOffsets(ctx, args[1], args[2], deleteConfig.skipConfirm)
+	default:
+		return fmt.Errorf("Unrecognized resource type: %s", resource)
+	}
+}
@@ -0,0 +1,134 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:     "freeze [topic]",
+	Short:   "freeze or unfreeze a topic so that apply skips it",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: freezePreRun,
+	RunE:    freezeRun,
+}
+
+type freezeCmdConfig struct {
+	duration    time.Duration
+	off         bool
+	on          bool
+	reason      string
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var freezeConfig freezeCmdConfig
+
+func init() {
+	freezeCmd.Flags().DurationVar(
+		&freezeConfig.duration,
+		"duration",
+		0,
+		"How long the freeze lasts; if unset, it lasts until the topic is unfrozen",
+	)
+	freezeCmd.Flags().BoolVar(
+		&freezeConfig.off,
+		"off",
+		false,
+		"Remove the freeze",
+	)
+	freezeCmd.Flags().BoolVar(
+		&freezeConfig.on,
+		"on",
+		false,
+		"Freeze the topic",
+	)
+	freezeCmd.Flags().StringVar(
+		&freezeConfig.reason,
+		"reason",
+		"",
+		"Reason for the freeze, recorded in zk",
+	)
+	freezeCmd.Flags().BoolVar(
+		&freezeConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	addSharedFlags(freezeCmd, &freezeConfig.shared)
+
+	RootCmd.AddCommand(freezeCmd)
+}
+
+func freezePreRun(cmd *cobra.Command, args []string) error {
+	if freezeConfig.on == freezeConfig.off {
+		return errors.New("Must set exactly one of on or off")
+	}
+	if freezeConfig.duration < 0 {
+		return errors.New("Duration cannot be negative")
+	}
+	if freezeConfig.on && freezeConfig.reason == "" {
+		return errors.New("Must set a reason when freezing a topic")
+	}
+	return freezeConfig.shared.validate()
+}
+
+func freezeRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	topic := args[0]
+
+	adminClient, err := freezeConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	if freezeConfig.off {
+		ok, _ := apply.Confirm(
+			fmt.Sprintf("OK to unfreeze topic %s?", topic),
+			freezeConfig.skipConfirm,
+		)
+		if !ok {
+			return errors.New("Stopping because of user response")
+		}
+
+		if err := adminClient.UnfreezeTopic(ctx, topic); err != nil {
+			return err
+		}
+		log.Infof("Unfroze topic %s", topic)
+		return nil
+	}
+
+	topicInfo, err := adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		return err
+	}
+
+	freeze := admin.NewTopicFreeze(topicInfo.Name, freezeConfig.reason, freezeConfig.duration)
+	log.Infof(
+		"Freeze to apply:\n%s",
+		admin.FormatTopicFreezes([]admin.TopicFreeze{freeze}, time.Now()),
+	)
+
+	ok, _ := apply.Confirm(
+		fmt.Sprintf("OK to freeze topic %s?", topic),
+		freezeConfig.skipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if err := adminClient.FreezeTopic(ctx, freeze); err != nil {
+		return err
+	}
+	log.Infof("Froze topic %s; apply will skip it until it's unfrozen or expires", topic)
+	return nil
+}
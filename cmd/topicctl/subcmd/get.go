@@ -5,12 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/cli"
+	"github.com/segmentio/topicctl/pkg/completion"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -21,38 +27,197 @@ var getCmd = &cobra.Command{
 	Long: strings.Join(
 		[]string{
 			"Get instances of a particular type.",
-			"Supported types currently include: balance, brokers, config, groups, lags, members, partitions, offsets, and topics.",
+			"Supported types currently include: applied-ref, balance, brokers, cluster, config, config-diff, config-history, connectors, freezes, groups, health, lags, log-dirs, members, messages-at-offset, owners, partitions, offsets, rack-violations, skew, throughput, topic, topics, and versions.",
 			"",
 			"See the tool README for a detailed description of each one.",
 		},
 		"\n",
 	),
-	Args:    cobra.MinimumNArgs(1),
-	PreRunE: getPreRun,
-	RunE:    getRun,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: getValidArgs,
+	PreRunE:           getPreRun,
+	RunE:              getRun,
+}
+
+var getResourceTypes = []string{
+	"applied-ref",
+	"balance",
+	"brokers",
+	"cluster",
+	"config",
+	"config-diff",
+	"config-history",
+	"connectors",
+	"freezes",
+	"groups",
+	"health",
+	"lags",
+	"log-dirs",
+	"members",
+	"messages-at-offset",
+	"offsets",
+	"owners",
+	"partitions",
+	"rack-violations",
+	"skew",
+	"throughput",
+	"topic",
+	"topics",
+	"versions",
 }
 
 type getCmdConfig struct {
+	artifactsDir  string
+	backupDir     string
 	clusterConfig string
+	expectedRacks int
+	format        string
 	full          bool
+	match         string
+	output        string
+	topicConfigs  string
 	zkAddr        string
 	zkPrefix      string
+
+	// Window for flagging re-created topics
+	recreatedWindow time.Duration
+
+	// Window for sampling throughput and skew
+	window time.Duration
+
+	// Pagination for topics and partitions
+	limit int
+	page  int
+
+	// Filters for partitions
+	leaders         []int
+	maxISR          int
+	racks           []string
+	replicas        []int
+	summary         bool
+	underReplicated bool
 }
 
 var getConfig getCmdConfig
 
 func init() {
+	getCmd.Flags().StringVar(
+		&getConfig.artifactsDir,
+		"artifacts-dir",
+		defaultArtifactsDir(),
+		"Directory of apply run artifacts to read changes from (config-history only)",
+	)
+	getCmd.Flags().StringVar(
+		&getConfig.backupDir,
+		"backup-dir",
+		defaultBackupDir(),
+		"Directory of topic backups to include (config-history only)",
+	)
 	getCmd.Flags().StringVar(
 		&getConfig.clusterConfig,
 		"cluster-config",
 		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
 		"Cluster config",
 	)
+	getCmd.Flags().IntVar(
+		&getConfig.expectedRacks,
+		"expected-racks",
+		0,
+		"Number of racks that each partition should be spread across; if 0, use as many as possible (rack-violations only)",
+	)
+	getCmd.Flags().StringVar(
+		&getConfig.format,
+		"format",
+		string(messages.TailFormatDefault),
+		fmt.Sprintf(
+			"Output format, one of %+v (messages-at-offset only)",
+			messages.AllTailFormats,
+		),
+	)
 	getCmd.Flags().BoolVar(
 		&getConfig.full,
 		"full",
 		false,
-		"Show more full information for resources",
+		"Show more full information for resources; for topic, also sample throughput and find consuming groups",
+	)
+	getCmd.Flags().IntVar(
+		&getConfig.limit,
+		"limit",
+		0,
+		"Maximum number of results per page; 0 shows all results (partitions and topics only)",
+	)
+	getCmd.Flags().IntVar(
+		&getConfig.page,
+		"page",
+		1,
+		"Page of results to show when limit is set (partitions and topics only)",
+	)
+	getCmd.Flags().StringVar(
+		&getConfig.match,
+		"match",
+		"",
+		"Only include topics matching this glob, or regex if wrapped in slashes (config, health, owners, partitions, and topics only)",
+	)
+	getCmd.Flags().StringVarP(
+		&getConfig.output,
+		"output",
+		"o",
+		"text",
+		"Output format, one of [text json] (owners, topic, and topics only)",
+	)
+	getCmd.Flags().DurationVar(
+		&getConfig.recreatedWindow,
+		"recreated-window",
+		admin.DefaultRecreatedWindow,
+		"Flag topics that were re-created since they were last applied within this window; 0 for no limit (topics only)",
+	)
+	getCmd.Flags().DurationVar(
+		&getConfig.window,
+		"window",
+		time.Minute,
+		"How long to sample offsets for (skew, throughput, and topic with --full only)",
+	)
+	getCmd.Flags().StringVar(
+		&getConfig.topicConfigs,
+		"topic-configs",
+		"",
+		"Directory of topic configs to look up owners in; defaults to the topics directory next to the cluster config (owners, topic, and topics only)",
+	)
+	getCmd.Flags().IntSliceVar(
+		&getConfig.leaders,
+		"leader",
+		[]int{},
+		"Only show partitions led by these brokers (partitions only)",
+	)
+	getCmd.Flags().IntVar(
+		&getConfig.maxISR,
+		"max-isr",
+		0,
+		"Only show partitions with at most this many in-sync replicas (partitions only)",
+	)
+	getCmd.Flags().StringSliceVar(
+		&getConfig.racks,
+		"rack",
+		[]string{},
+		"Only show partitions with a replica in these racks (partitions only)",
+	)
+	getCmd.Flags().IntSliceVar(
+		&getConfig.replicas,
+		"replica",
+		[]int{},
+		"Only show partitions with a replica on these brokers (partitions only)",
+	)
+	getCmd.Flags().BoolVar(
+		&getConfig.summary,
+		"summary",
+		false,
+		"Show counts per broker instead of individual partitions (partitions only)",
+	)
+	getCmd.Flags().BoolVar(
+		&getConfig.underReplicated,
+		"under-replicated",
+		false,
+		"Only show under-replicated partitions (partitions only)",
 	)
 	getCmd.Flags().StringVarP(
 		&getConfig.zkAddr,
@@ -79,6 +244,18 @@ func getPreRun(cmd *cobra.Command, args []string) error {
 		(getConfig.zkAddr != "" || getConfig.zkPrefix != "") {
 		log.Warn("zk-addr and zk-prefix flags are ignored when using cluster-config")
 	}
+	if getConfig.limit < 0 {
+		return errors.New("Limit cannot be negative")
+	}
+	if getConfig.page < 1 {
+		return errors.New("Page must be at least 1")
+	}
+	if getConfig.page > 1 && getConfig.limit == 0 {
+		return errors.New("Cannot set page without limit")
+	}
+	if getConfig.output != "text" && getConfig.output != "json" {
+		return fmt.Errorf("Output must be one of [text json]")
+	}
 
 	return nil
 }
@@ -88,10 +265,12 @@ func getRun(cmd *cobra.Command, args []string) error {
 	sess := session.Must(session.NewSession())
 
 	var adminClient *admin.Client
+	var clusterConfig config.ClusterConfig
 	var clientErr error
 
 	if getConfig.clusterConfig != "" {
-		clusterConfig, err := config.LoadClusterFile(getConfig.clusterConfig)
+		var err error
+		clusterConfig, err = config.LoadClusterFile(getConfig.clusterConfig)
 		if err != nil {
 			return err
 		}
@@ -117,9 +296,25 @@ func getRun(cmd *cobra.Command, args []string) error {
 
 	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, true)
 
+	var topicMatcher *util.TopicMatcher
+	if getConfig.match != "" {
+		var err error
+		topicMatcher, err = util.NewTopicMatcher(getConfig.match)
+		if err != nil {
+			return err
+		}
+	}
+
 	resource := args[0]
+	warnTopicLifecycles(ctx, adminClient, clusterConfig, resource, args[1:], topicMatcher)
 
 	switch resource {
+	case "applied-ref":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with applied-ref")
+		}
+
+		return cliRunner.GetAppliedRef(ctx)
 	case "balance":
 		var topicName string
 
@@ -136,37 +331,195 @@ func getRun(cmd *cobra.Command, args []string) error {
 		}
 
 		return cliRunner.GetBrokers(ctx, getConfig.full)
+	case "cluster":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with cluster")
+		}
+
+		return cliRunner.GetClusterInfo(ctx)
 	case "config":
+		if topicMatcher != nil {
+			if len(args) != 1 {
+				return errors.New("Cannot provide a broker ID or topic name with match")
+			}
+
+			topicNames, err := matchingTopicNames(ctx, adminClient, topicMatcher)
+			if err != nil {
+				return err
+			}
+			for _, topicName := range topicNames {
+				if err := cliRunner.GetConfig(ctx, topicName); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
 		if len(args) != 2 {
 			return fmt.Errorf("Must provide broker ID or topic name as second positional argument")
 		}
 
 		return cliRunner.GetConfig(ctx, args[1])
+	case "config-diff":
+		if len(args) != 2 {
+			return fmt.Errorf("Must provide topic name as second positional argument")
+		}
+
+		return cliRunner.GetConfigDiff(ctx, args[1])
+	case "config-history":
+		if len(args) != 2 {
+			return fmt.Errorf("Must provide topic name as second positional argument")
+		}
+
+		return cliRunner.GetConfigHistory(
+			ctx,
+			args[1],
+			getConfig.artifactsDir,
+			getConfig.backupDir,
+		)
+	case "connectors":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with connectors")
+		}
+		if clusterConfig.Spec.ConnectURL == "" {
+			return errors.New("Must use a cluster config with connectURL set to get connectors")
+		}
+
+		return cliRunner.GetConnectors(ctx, clusterConfig.Spec.ConnectURL)
+	case "freezes":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with freezes")
+		}
+
+		return cliRunner.GetTopicFreezes(ctx)
 	case "groups":
 		if len(args) > 1 {
 			return fmt.Errorf("Can only provide one positional argument with groups")
 		}
 
 		return cliRunner.GetGroups(ctx)
+	case "health":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with health")
+		}
+
+		return cliRunner.GetHealth(ctx, topicMatcher)
 	case "lags":
 		if len(args) != 3 {
 			return fmt.Errorf("Must provide topic and groupID as additional positional arguments")
 		}
 
 		return cliRunner.GetMemberLags(ctx, args[1], args[2])
+	case "log-dirs":
+		var brokerIDs []int
+
+		if len(args) == 2 {
+			brokerID, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("Could not parse broker ID %s: %+v", args[1], err)
+			}
+			brokerIDs = []int{brokerID}
+		} else if len(args) > 2 {
+			return fmt.Errorf("Can provide at most one positional argument with log-dirs")
+		}
+
+		return cliRunner.GetLogDirs(ctx, brokerIDs, getConfig.full)
 	case "members":
 		if len(args) != 2 {
 			return fmt.Errorf("Must provide group ID as second positional argument")
 		}
 
 		return cliRunner.GetGroupMembers(ctx, args[1], getConfig.full)
+	case "messages-at-offset":
+		if len(args) != 4 {
+			return fmt.Errorf(
+				"Must provide topic, partition, and offset or offset range as additional positional arguments",
+			)
+		}
+		partition, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("Could not parse partition %s: %+v", args[2], err)
+		}
+		startOffset, endOffset, err := messages.ParseOffsetRange(args[3])
+		if err != nil {
+			return err
+		}
+
+		return cliRunner.GetMessagesAtOffset(
+			ctx,
+			args[1],
+			partition,
+			startOffset,
+			endOffset,
+			messages.TailFormat(getConfig.format),
+		)
+	case "owners":
+		var topicNames []string
+
+		if topicMatcher != nil {
+			if len(args) != 1 {
+				return errors.New("Cannot provide a topic name with match")
+			}
+
+			var err error
+			topicNames, err = matchingTopicNames(ctx, adminClient, topicMatcher)
+			if err != nil {
+				return err
+			}
+		} else {
+			if len(args) < 2 {
+				return fmt.Errorf("Must provide at least one topic as a positional argument")
+			}
+			topicNames = args[1:]
+		}
+
+		owners, err := loadOwnershipIndex(getConfig.clusterConfig, getConfig.topicConfigs, clusterConfig)
+		if err != nil {
+			return err
+		}
+
+		return cliRunner.GetTopicOwners(topicNames, owners, getConfig.output == "json")
 	case "partitions":
-		if len(args) != 2 {
-			return fmt.Errorf("Must provide topic as second positional argument")
+		var topicNames []string
+
+		if topicMatcher != nil {
+			if len(args) != 1 {
+				return errors.New("Cannot provide a topic name with match")
+			}
+
+			var err error
+			topicNames, err = matchingTopicNames(ctx, adminClient, topicMatcher)
+			if err != nil {
+				return err
+			}
+		} else {
+			if len(args) != 2 {
+				return fmt.Errorf("Must provide topic as second positional argument")
+			}
+			topicNames = []string{args[1]}
 		}
-		topicName := args[1]
 
-		return cliRunner.GetPartitions(ctx, topicName)
+		for _, topicName := range topicNames {
+			err := cliRunner.GetPartitions(
+				ctx,
+				topicName,
+				admin.PartitionFilter{
+					LeaderBrokers:   getConfig.leaders,
+					ReplicaBrokers:  getConfig.replicas,
+					Racks:           getConfig.racks,
+					UnderReplicated: getConfig.underReplicated,
+					MaxISR:          getConfig.maxISR,
+				},
+				getConfig.summary,
+				cli.Pagination{
+					Limit: getConfig.limit,
+					Page:  getConfig.page,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	case "offsets":
 		if len(args) != 2 {
 			return fmt.Errorf("Must provide topic as second positional argument")
@@ -174,13 +527,201 @@ func getRun(cmd *cobra.Command, args []string) error {
 		topicName := args[1]
 
 		return cliRunner.GetOffsets(ctx, topicName)
+	case "skew", "throughput":
+		if len(args) != 2 {
+			return fmt.Errorf("Must provide topic as second positional argument")
+		}
+		if getConfig.window <= 0 {
+			return errors.New("Window must be positive")
+		}
+
+		if args[0] == "skew" {
+			return cliRunner.GetSkew(ctx, args[1], getConfig.window)
+		}
+		return cliRunner.GetThroughput(ctx, args[1], getConfig.window)
+	case "rack-violations":
+		var topicName string
+
+		if len(args) == 2 {
+			topicName = args[1]
+		} else if len(args) > 2 {
+			return fmt.Errorf("Can provide at most one positional argument with rack-violations")
+		}
+
+		return cliRunner.GetRackViolations(ctx, topicName, getConfig.expectedRacks)
+	case "topic":
+		if len(args) != 2 {
+			return fmt.Errorf("Must provide topic as second positional argument")
+		}
+		if getConfig.full && getConfig.window <= 0 {
+			return errors.New("Window must be positive")
+		}
+
+		owners, err := loadOwnershipIndex(getConfig.clusterConfig, getConfig.topicConfigs, clusterConfig)
+		if err != nil {
+			return err
+		}
+
+		return cliRunner.GetTopicDescription(
+			ctx,
+			args[1],
+			getConfig.full,
+			getConfig.window,
+			owners,
+			getConfig.output == "json",
+		)
 	case "topics":
 		if len(args) > 1 {
 			return fmt.Errorf("Can only provide one positional argument with args")
 		}
 
-		return cliRunner.GetTopics(ctx, getConfig.full)
+		if getConfig.output == "json" {
+			owners, err := loadOwnershipIndex(getConfig.clusterConfig, getConfig.topicConfigs, clusterConfig)
+			if err != nil {
+				return err
+			}
+
+			return cliRunner.GetTopicsJSON(
+				ctx,
+				topicMatcher,
+				cli.Pagination{
+					Limit: getConfig.limit,
+					Page:  getConfig.page,
+				},
+				owners,
+				getConfig.recreatedWindow,
+			)
+		}
+
+		return cliRunner.GetTopics(
+			ctx,
+			getConfig.full,
+			topicMatcher,
+			cli.Pagination{
+				Limit: getConfig.limit,
+				Page:  getConfig.page,
+			},
+			getConfig.recreatedWindow,
+		)
+	case "versions":
+		if len(args) > 1 {
+			return fmt.Errorf("Can only provide one positional argument with versions")
+		}
+
+		return cliRunner.GetVersions(ctx)
 	default:
 		return fmt.Errorf("Unrecognized resource type: %s", resource)
 	}
 }
+
+// getValidArgs completes the resource type, followed by the topic or group arguments
+// that the resource type takes.
+func getValidArgs(
+	cmd *cobra.Command,
+	args []string,
+	toComplete string,
+) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completion.FilterPrefix(getResourceTypes, toComplete),
+			cobra.ShellCompDirectiveNoFileComp
+	}
+
+	options := sharedOptions{
+		clusterConfig: getConfig.clusterConfig,
+		zkAddr:        getConfig.zkAddr,
+		zkPrefix:      getConfig.zkPrefix,
+	}
+
+	switch args[0] {
+	case "owners", "partitions":
+		return completeTopics(options, args[1:], toComplete)
+	case "balance",
+		"config",
+		"config-diff",
+		"config-history",
+		"messages-at-offset",
+		"offsets",
+		"rack-violations",
+		"skew",
+		"throughput",
+		"topic":
+		if len(args) == 1 {
+			return completeTopics(options, nil, toComplete)
+		}
+	case "lags":
+		if len(args) == 1 {
+			return completeTopics(options, nil, toComplete)
+		} else if len(args) == 2 {
+			return completeGroups(options, toComplete)
+		}
+	case "members":
+		if len(args) == 1 {
+			return completeGroups(options, toComplete)
+		}
+	}
+
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// warnTopicLifecycles logs a warning for each deprecated or retiring topic that the get
+// command covers, according to the topic configs. This is best-effort; any errors are only
+// logged at the debug level.
+func warnTopicLifecycles(
+	ctx context.Context,
+	adminClient *admin.Client,
+	clusterConfig config.ClusterConfig,
+	resource string,
+	resourceArgs []string,
+	topicMatcher *util.TopicMatcher,
+) {
+	if getConfig.clusterConfig == "" && getConfig.topicConfigs == "" {
+		return
+	}
+
+	index, err := loadOwnershipIndex(getConfig.clusterConfig, getConfig.topicConfigs, clusterConfig)
+	if err != nil {
+		log.Debugf("Could not load topic configs to check lifecycles: %+v", err)
+		return
+	}
+
+	// Arguments that aren't topic names (e.g., groups) just won't match any configs
+	topicNames := resourceArgs
+	if resource == "topics" || topicMatcher != nil {
+		topicNames, err = adminClient.GetTopicNames(ctx)
+		if err != nil {
+			log.Debugf("Could not get topic names to check lifecycles: %+v", err)
+			return
+		}
+		sort.Strings(topicNames)
+		topicNames = topicMatcher.Filter(topicNames)
+	}
+
+	for _, topicName := range topicNames {
+		if topicConfig, ok := index.TopicConfig(topicName); ok {
+			if warning := topicConfig.Meta.LifecycleWarning(); warning != "" {
+				log.Warn(warning)
+			}
+		}
+	}
+}
+
+// matchingTopicNames returns the names of the topics in the cluster that match the argument
+// matcher, in alphabetical order.
+func matchingTopicNames(
+	ctx context.Context,
+	adminClient *admin.Client,
+	topicMatcher *util.TopicMatcher,
+) ([]string, error) {
+	topicNames, err := adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(topicNames)
+
+	matches := topicMatcher.Filter(topicNames)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("No topics match %s", topicMatcher)
+	}
+
+	return matches, nil
+}
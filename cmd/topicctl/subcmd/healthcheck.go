@@ -0,0 +1,117 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/healthcheck"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var healthcheckCmd = &cobra.Command{
+	Use:     "healthcheck",
+	Short:   "produce and consume canary messages to check the health of each broker",
+	Args:    cobra.NoArgs,
+	PreRunE: healthcheckPreRun,
+	RunE:    healthcheckRun,
+}
+
+type healthcheckCmdConfig struct {
+	createTopic       bool
+	replicationFactor int
+	timeout           time.Duration
+	topic             string
+
+	shared sharedOptions
+}
+
+var healthcheckConfig healthcheckCmdConfig
+
+func init() {
+	healthcheckCmd.Flags().BoolVar(
+		&healthcheckConfig.createTopic,
+		"create-topic",
+		true,
+		"Create the canary topic, with one partition per broker, if it doesn't exist",
+	)
+	healthcheckCmd.Flags().IntVar(
+		&healthcheckConfig.replicationFactor,
+		"replication-factor",
+		3,
+		"Replication factor for the canary topic if it's created; capped at the number of brokers",
+	)
+	healthcheckCmd.Flags().DurationVar(
+		&healthcheckConfig.timeout,
+		"timeout",
+		10*time.Second,
+		"Maximum time to wait for each canary message to be produced and consumed",
+	)
+	healthcheckCmd.Flags().StringVar(
+		&healthcheckConfig.topic,
+		"topic",
+		healthcheck.DefaultTopic,
+		"Canary topic",
+	)
+	addSharedFlags(healthcheckCmd, &healthcheckConfig.shared)
+
+	RootCmd.AddCommand(healthcheckCmd)
+}
+
+func healthcheckPreRun(cmd *cobra.Command, args []string) error {
+	return healthcheckConfig.shared.validate()
+}
+
+func healthcheckRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	adminClient, err := healthcheckConfig.shared.getAdminClient(
+		ctx,
+		nil,
+		!healthcheckConfig.createTopic,
+	)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	results, err := healthcheck.Run(
+		ctx,
+		adminClient,
+		healthcheck.Config{
+			Topic:             healthcheckConfig.topic,
+			CreateTopic:       healthcheckConfig.createTopic,
+			ReplicationFactor: healthcheckConfig.replicationFactor,
+			Timeout:           healthcheckConfig.timeout,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Health check results for canary topic %s (took %s):\n%s",
+		results.Topic,
+		results.Elapsed.Round(time.Millisecond),
+		healthcheck.FormatResults(results),
+	)
+
+	if !results.Healthy() {
+		return fmt.Errorf("Health check failed for broker(s) %+v", results.UnhealthyBrokers())
+	}
+
+	log.Info("All brokers look healthy")
+	return nil
+}
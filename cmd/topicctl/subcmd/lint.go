@@ -0,0 +1,115 @@
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/lint"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [topic configs]",
+	Short: "statically check topic configs without contacting a cluster",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  lintRun,
+}
+
+type lintCmdConfig struct {
+	namePattern string
+	pathPrefix  string
+	strict      bool
+}
+
+var lintConfig lintCmdConfig
+
+func init() {
+	lintCmd.Flags().StringVar(
+		&lintConfig.namePattern,
+		"name-pattern",
+		lint.DefaultNamePattern,
+		"Regular expression that topic names must match; set to empty to disable",
+	)
+	lintCmd.Flags().StringVar(
+		&lintConfig.pathPrefix,
+		"path-prefix",
+		os.Getenv("TOPICCTL_APPLY_PATH_PREFIX"),
+		"Prefix for topic config paths",
+	)
+	lintCmd.Flags().BoolVar(
+		&lintConfig.strict,
+		"strict",
+		false,
+		"Treat warnings as errors",
+	)
+
+	RootCmd.AddCommand(lintCmd)
+}
+
+func lintRun(cmd *cobra.Command, args []string) error {
+	options := lint.Options{}
+	if lintConfig.namePattern != "" {
+		namePattern, err := regexp.Compile(lintConfig.namePattern)
+		if err != nil {
+			return fmt.Errorf("Invalid name pattern: %+v", err)
+		}
+		options.NamePattern = namePattern
+	}
+
+	matchCount := 0
+	results := []lint.Result{}
+
+	for _, arg := range args {
+		if lintConfig.pathPrefix != "" && !filepath.IsAbs(arg) {
+			arg = filepath.Join(lintConfig.pathPrefix, arg)
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			kind, err := config.LoadKindFile(match)
+			if err != nil {
+				return err
+			}
+			if kind != "" {
+				log.Debugf("Skipping %s config %s", kind, match)
+				continue
+			}
+			matchCount++
+
+			contents, err := ioutil.ReadFile(match)
+			if err != nil {
+				return err
+			}
+			results = append(results, lint.LintTopicBytes(match, contents, options)...)
+		}
+	}
+
+	if matchCount == 0 {
+		return fmt.Errorf("No topic configs match the provided args (%+v)", args)
+	}
+
+	for _, result := range results {
+		if result.Severity == lint.SeverityError {
+			log.Error(result.String())
+		} else {
+			log.Warn(result.String())
+		}
+	}
+
+	if lint.HasErrors(results) || (lintConfig.strict && len(results) > 0) {
+		return errors.New("Lint failed")
+	}
+
+	log.Infof("Linted %d topic config(s), found %d warning(s)", matchCount, len(results))
+	return nil
+}
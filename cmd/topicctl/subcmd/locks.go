@@ -0,0 +1,225 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/zk"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var locksCmd = &cobra.Command{
+	Use:   "locks [subcommand]",
+	Short: "inspect and clean up apply locks",
+}
+
+var locksListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list the apply locks that are currently held",
+	Args:    cobra.NoArgs,
+	PreRunE: locksListPreRun,
+	RunE:    locksListRun,
+}
+
+var locksReleaseCmd = &cobra.Command{
+	Use:     "release [lock name or path]",
+	Short:   "forcibly release an apply lock orphaned by a crashed process",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: locksReleasePreRun,
+	RunE:    locksReleaseRun,
+}
+
+type locksListCmdConfig struct {
+	lockPath string
+
+	shared sharedOptions
+}
+
+var locksListConfig locksListCmdConfig
+
+type locksReleaseCmdConfig struct {
+	force       bool
+	lockPath    string
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var locksReleaseConfig locksReleaseCmdConfig
+
+func init() {
+	locksListCmd.Flags().StringVar(
+		&locksListConfig.lockPath,
+		"lock-path",
+		"",
+		"Root zk path for locks; defaults to zkLockPath in the cluster config",
+	)
+	addSharedFlags(locksListCmd, &locksListConfig.shared)
+
+	locksReleaseCmd.Flags().BoolVar(
+		&locksReleaseConfig.force,
+		"force",
+		false,
+		"Confirm that the lock holder is no longer running",
+	)
+	locksReleaseCmd.Flags().StringVar(
+		&locksReleaseConfig.lockPath,
+		"lock-path",
+		"",
+		"Root zk path for locks; defaults to zkLockPath in the cluster config",
+	)
+	locksReleaseCmd.Flags().BoolVar(
+		&locksReleaseConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	addSharedFlags(locksReleaseCmd, &locksReleaseConfig.shared)
+
+	locksCmd.AddCommand(locksListCmd)
+	locksCmd.AddCommand(locksReleaseCmd)
+	RootCmd.AddCommand(locksCmd)
+}
+
+func locksListPreRun(cmd *cobra.Command, args []string) error {
+	return locksListConfig.shared.validate()
+}
+
+func locksListRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	rootPath, err := locksRootPath(locksListConfig.shared, locksListConfig.lockPath)
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := locksListConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	locks, err := adminClient.GetLocks(ctx, rootPath)
+	if err != nil {
+		return err
+	}
+
+	if len(locks) == 0 {
+		log.Infof("No locks are held under %s", rootPath)
+		return nil
+	}
+
+	log.Infof("Locks held under %s:\n%s", rootPath, admin.FormatLocks(locks, time.Now()))
+	return nil
+}
+
+func locksReleasePreRun(cmd *cobra.Command, args []string) error {
+	if !locksReleaseConfig.force {
+		return errors.New(
+			"Releasing a lock that's still in use can corrupt concurrent applies; set --force to confirm that its holder is no longer running",
+		)
+	}
+	return locksReleaseConfig.shared.validate()
+}
+
+func locksReleaseRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	rootPath, err := locksRootPath(locksReleaseConfig.shared, locksReleaseConfig.lockPath)
+	if err != nil {
+		return err
+	}
+
+	lockPath := args[0]
+	if !filepath.IsAbs(lockPath) {
+		lockPath = filepath.Join(rootPath, lockPath)
+	}
+
+	adminClient, err := locksReleaseConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	lockInfo, held, err := adminClient.GetLock(ctx, lockPath)
+	if err != nil {
+		return err
+	}
+	if !held {
+		return fmt.Errorf("Lock %s is not held", lockPath)
+	}
+
+	log.Infof("Current lock holder:\n%s", admin.FormatLocks([]zk.LockInfo{lockInfo}, time.Now()))
+
+	if lockHolderRunning(lockInfo) {
+		return fmt.Errorf(
+			"Lock %s is held by process %d on this host, which is still running",
+			lockPath,
+			lockInfo.Metadata.PID,
+		)
+	}
+
+	ok, _ := apply.Confirm(
+		fmt.Sprintf("OK to release lock %s?", lockPath),
+		locksReleaseConfig.skipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if err := adminClient.ReleaseLock(ctx, lockInfo); err != nil {
+		return err
+	}
+
+	log.Infof("Released lock %s", lockPath)
+	return nil
+}
+
+// locksRootPath returns the root zk path for the apply locks, using the override if it's set
+// and the cluster config otherwise.
+func locksRootPath(shared sharedOptions, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if shared.clusterConfig == "" {
+		return "", errors.New("Must set lock-path when not using a cluster config")
+	}
+
+	clusterConfig, err := config.LoadClusterFile(shared.clusterConfig)
+	if err != nil {
+		return "", err
+	}
+	if clusterConfig.Spec.ZKLockPath == "" {
+		return "", errors.New("Cluster config does not have a zkLockPath set")
+	}
+
+	return clusterConfig.Spec.ZKLockPath, nil
+}
+
+// lockHolderRunning returns whether the holder of the argument lock is a process on the
+// current host that's still running.
+func lockHolderRunning(lockInfo zk.LockInfo) bool {
+	if lockInfo.Metadata == nil {
+		return false
+	}
+
+	host, err := os.Hostname()
+	if err != nil || host != lockInfo.Metadata.Host {
+		return false
+	}
+
+	process, err := os.FindProcess(lockInfo.Metadata.PID)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,126 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var partitionForCmd = &cobra.Command{
+	Use:     "partition-for",
+	Short:   "show which partition each argument key maps to in a topic",
+	Args:    cobra.NoArgs,
+	PreRunE: partitionForPreRun,
+	RunE:    partitionForRun,
+}
+
+type partitionForCmdConfig struct {
+	keys          []string
+	numPartitions int
+	partitioner   string
+	topic         string
+
+	shared sharedOptions
+}
+
+var partitionForConfig partitionForCmdConfig
+
+func init() {
+	partitionForCmd.Flags().StringArrayVar(
+		&partitionForConfig.keys,
+		"key",
+		[]string{},
+		"Key to look up; can be repeated",
+	)
+	partitionForCmd.Flags().IntVar(
+		&partitionForConfig.numPartitions,
+		"partitions",
+		0,
+		"Number of partitions to use instead of looking up the topic in the cluster",
+	)
+	partitionForCmd.Flags().StringVar(
+		&partitionForConfig.partitioner,
+		"partitioner",
+		string(util.PartitionerMurmur2),
+		fmt.Sprintf("Partitioner that producers use, one of %+v", util.AllPartitioners),
+	)
+	partitionForCmd.Flags().StringVar(
+		&partitionForConfig.topic,
+		"topic",
+		"",
+		"Topic to look up the partition count of",
+	)
+	addSharedFlags(partitionForCmd, &partitionForConfig.shared)
+
+	RootCmd.AddCommand(partitionForCmd)
+}
+
+func partitionForPreRun(cmd *cobra.Command, args []string) error {
+	if len(partitionForConfig.keys) == 0 {
+		return errors.New("Must set at least one key")
+	}
+	if err := util.Partitioner(partitionForConfig.partitioner).Validate(); err != nil {
+		return err
+	}
+	if partitionForConfig.numPartitions < 0 {
+		return errors.New("Partitions cannot be negative")
+	}
+	if partitionForConfig.numPartitions > 0 {
+		if partitionForConfig.topic != "" {
+			return errors.New("Cannot set both topic and partitions")
+		}
+		return nil
+	}
+	if partitionForConfig.topic == "" {
+		return errors.New("Must set either topic or partitions")
+	}
+
+	return partitionForConfig.shared.validate()
+}
+
+func partitionForRun(cmd *cobra.Command, args []string) error {
+	numPartitions := partitionForConfig.numPartitions
+
+	if numPartitions == 0 {
+		ctx := context.Background()
+
+		adminClient, err := partitionForConfig.shared.getAdminClient(ctx, nil, true)
+		if err != nil {
+			return err
+		}
+		defer adminClient.Close()
+
+		topicInfo, err := adminClient.GetTopic(ctx, partitionForConfig.topic, false)
+		if err != nil {
+			return fmt.Errorf("Error fetching topic info: %+v", err)
+		}
+		numPartitions = len(topicInfo.Partitions)
+	}
+
+	partitioner := util.Partitioner(partitionForConfig.partitioner)
+
+	keyPartitions := []messages.KeyPartition{}
+	for _, key := range partitionForConfig.keys {
+		partition, err := util.PartitionForKey(partitioner, []byte(key), numPartitions)
+		if err != nil {
+			return err
+		}
+		keyPartitions = append(
+			keyPartitions,
+			messages.KeyPartition{Key: key, Partition: partition},
+		)
+	}
+
+	log.Infof(
+		"Partitions for key(s) with %d partition(s) and the %s partitioner:\n%s",
+		numPartitions,
+		partitioner,
+		messages.FormatKeyPartitions(keyPartitions),
+	)
+	return nil
+}
@@ -0,0 +1,91 @@
+package subcmd
+
+import (
+	"context"
+
+	"github.com/segmentio/topicctl/pkg/cli"
+	"github.com/segmentio/topicctl/pkg/groups"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var previewAssignmentCmd = &cobra.Command{
+	Use:     "preview-assignment",
+	Short:   "preview consumer group partition assignments for a topic",
+	PreRunE: previewAssignmentPreRun,
+	RunE:    previewAssignmentRun,
+}
+
+type previewAssignmentCmdConfig struct {
+	full       bool
+	group      string
+	numMembers int
+	strategy   string
+	topic      string
+
+	shared sharedOptions
+}
+
+var previewAssignmentConfig previewAssignmentCmdConfig
+
+func init() {
+	previewAssignmentCmd.Flags().BoolVar(
+		&previewAssignmentConfig.full,
+		"full",
+		false,
+		"Show full member IDs",
+	)
+	previewAssignmentCmd.Flags().StringVar(
+		&previewAssignmentConfig.group,
+		"group",
+		"",
+		"Consumer group ID; if set, the current members and assignments are used as a baseline",
+	)
+	previewAssignmentCmd.Flags().IntVar(
+		&previewAssignmentConfig.numMembers,
+		"members",
+		0,
+		"Number of group members (defaults to current number in group)",
+	)
+	previewAssignmentCmd.Flags().StringVar(
+		&previewAssignmentConfig.strategy,
+		"strategy",
+		string(groups.AssignmentStrategyRange),
+		"Assignment strategy (one of 'range', 'roundrobin', 'sticky')",
+	)
+	previewAssignmentCmd.Flags().StringVar(
+		&previewAssignmentConfig.topic,
+		"topic",
+		"",
+		"Topic to preview assignments for",
+	)
+	addSharedFlags(previewAssignmentCmd, &previewAssignmentConfig.shared)
+
+	previewAssignmentCmd.MarkFlagRequired("topic")
+
+	RootCmd.AddCommand(previewAssignmentCmd)
+}
+
+func previewAssignmentPreRun(cmd *cobra.Command, args []string) error {
+	return previewAssignmentConfig.shared.validate()
+}
+
+func previewAssignmentRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	adminClient, err := previewAssignmentConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, true)
+	return cliRunner.PreviewAssignment(
+		ctx,
+		previewAssignmentConfig.topic,
+		previewAssignmentConfig.group,
+		groups.AssignmentStrategy(previewAssignmentConfig.strategy),
+		previewAssignmentConfig.numMembers,
+		previewAssignmentConfig.full,
+	)
+}
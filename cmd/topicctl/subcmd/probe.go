@@ -0,0 +1,122 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/healthcheck"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var probeCmd = &cobra.Command{
+	Use:     "probe",
+	Short:   "probe each broker individually and report connection and request latencies",
+	Args:    cobra.NoArgs,
+	PreRunE: probePreRun,
+	RunE:    probeRun,
+}
+
+type probeCmdConfig struct {
+	interval      time.Duration
+	rounds        int
+	timeout       time.Duration
+	tlsSkipVerify bool
+	topic         string
+
+	shared sharedOptions
+}
+
+var probeConfig probeCmdConfig
+
+func init() {
+	probeCmd.Flags().DurationVar(
+		&probeConfig.interval,
+		"interval",
+		time.Second,
+		"Time to wait between probe rounds",
+	)
+	probeCmd.Flags().IntVar(
+		&probeConfig.rounds,
+		"rounds",
+		5,
+		"Number of times to probe each broker",
+	)
+	probeCmd.Flags().DurationVar(
+		&probeConfig.timeout,
+		"timeout",
+		5*time.Second,
+		"Maximum time for each probe of a broker",
+	)
+	probeCmd.Flags().BoolVar(
+		&probeConfig.tlsSkipVerify,
+		"tls-skip-verify",
+		false,
+		"Skip verification of broker certificates in TLS handshake probes",
+	)
+	probeCmd.Flags().StringVar(
+		&probeConfig.topic,
+		"topic",
+		healthcheck.DefaultTopic,
+		"Topic for produce probes; these are skipped if the topic doesn't exist",
+	)
+	addSharedFlags(probeCmd, &probeConfig.shared)
+
+	RootCmd.AddCommand(probeCmd)
+}
+
+func probePreRun(cmd *cobra.Command, args []string) error {
+	return probeConfig.shared.validate()
+}
+
+func probeRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	adminClient, err := probeConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	log.Infof("Probing each broker %d time(s)", probeConfig.rounds)
+
+	results, err := healthcheck.ProbeBrokers(
+		ctx,
+		adminClient,
+		healthcheck.ProbeConfig{
+			Rounds:        probeConfig.rounds,
+			Interval:      probeConfig.interval,
+			Timeout:       probeConfig.timeout,
+			Topic:         probeConfig.topic,
+			TLSSkipVerify: probeConfig.tlsSkipVerify,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Broker probe results:\n%s", healthcheck.FormatProbeResults(results))
+
+	failingBrokers := []int{}
+	for _, result := range results {
+		if result.Failures > 0 {
+			failingBrokers = append(failingBrokers, result.Broker)
+		}
+	}
+	if len(failingBrokers) > 0 {
+		log.Warnf("Some probes failed for broker(s) %+v", failingBrokers)
+	}
+
+	return nil
+}
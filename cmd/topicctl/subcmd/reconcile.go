@@ -0,0 +1,202 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/reconcile"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:     "reconcile [topic configs]",
+	Short:   "converge topics to their configs, optionally in a continuous loop",
+	PreRunE: reconcilePreRun,
+	RunE:    reconcileRun,
+}
+
+type reconcileCmdConfig struct {
+	backupDir          string
+	clusterConfig      string
+	deleteRetired      bool
+	gitPath            string
+	gitRef             string
+	gitRepo            string
+	interval           time.Duration
+	jitter             float64
+	maxChangesPerCycle int
+	metricsAddr        string
+	sleepLoopTime      time.Duration
+	watch              bool
+}
+
+var reconcileConfig reconcileCmdConfig
+
+func init() {
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.backupDir,
+		"backup-dir",
+		defaultBackupDir(),
+		"Directory to back up the state of each topic to before changing it; set to empty to disable",
+	)
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.clusterConfig,
+		"cluster-config",
+		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
+		"Cluster config path",
+	)
+	reconcileCmd.Flags().BoolVar(
+		&reconcileConfig.deleteRetired,
+		"delete-retired",
+		false,
+		"Delete retiring topics that are past their deletion dates",
+	)
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.gitPath,
+		"path",
+		"",
+		"Directory in the git repo that config paths are relative to; only applies if git-repo is set",
+	)
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.gitRef,
+		"git-ref",
+		"HEAD",
+		"Branch, tag, or commit SHA to reconcile from; only applies if git-repo is set",
+	)
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.gitRepo,
+		"git-repo",
+		"",
+		"URL of a git repo to read configs from, re-fetched each cycle, instead of the local filesystem",
+	)
+	reconcileCmd.Flags().DurationVar(
+		&reconcileConfig.interval,
+		"interval",
+		5*time.Minute,
+		"Base amount of time between cycles in watch mode",
+	)
+	reconcileCmd.Flags().Float64Var(
+		&reconcileConfig.jitter,
+		"jitter",
+		0.1,
+		"Maximum fraction of the interval to randomly shift each wait by",
+	)
+	reconcileCmd.Flags().IntVar(
+		&reconcileConfig.maxChangesPerCycle,
+		"max-changes",
+		10,
+		"Maximum number of changes per cycle; cycles with more changes are skipped (0 for no limit)",
+	)
+	reconcileCmd.Flags().StringVar(
+		&reconcileConfig.metricsAddr,
+		"metrics-addr",
+		"",
+		"Address to serve Prometheus metrics on at /metrics (e.g., :9090); only applies if watch is set",
+	)
+	reconcileCmd.Flags().DurationVar(
+		&reconcileConfig.sleepLoopTime,
+		"sleep-loop-time",
+		10*time.Second,
+		"Amount of time to wait between partition checks",
+	)
+	reconcileCmd.Flags().BoolVar(
+		&reconcileConfig.watch,
+		"watch",
+		false,
+		"Run continuously instead of doing a single cycle",
+	)
+
+	RootCmd.AddCommand(reconcileCmd)
+}
+
+func reconcilePreRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && reconcileConfig.gitRepo == "" {
+		return errors.New("Must provide at least one config path or set git-repo")
+	}
+	if reconcileConfig.gitPath != "" && reconcileConfig.gitRepo == "" {
+		return errors.New("Cannot set path without git-repo")
+	}
+	if reconcileConfig.watch && reconcileConfig.interval <= 0 {
+		return errors.New("Interval must be positive")
+	}
+	if reconcileConfig.jitter < 0 || reconcileConfig.jitter >= 1 {
+		return errors.New("Jitter must be at least 0 and less than 1")
+	}
+	if reconcileConfig.maxChangesPerCycle < 0 {
+		return errors.New("Max changes cannot be negative")
+	}
+	if reconcileConfig.metricsAddr != "" && !reconcileConfig.watch {
+		log.Warn("metrics-addr is ignored unless watch is set")
+	}
+
+	return nil
+}
+
+func reconcileRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	configPaths := args
+	if len(configPaths) == 0 {
+		configPaths = []string{"*.yaml"}
+	}
+
+	reconciler := reconcile.NewReconciler(
+		reconcile.ReconcilerConfig{
+			ConfigPaths:        configPaths,
+			ClusterConfigPath:  reconcileConfig.clusterConfig,
+			BackupDir:          reconcileConfig.backupDir,
+			DeleteRetired:      reconcileConfig.deleteRetired,
+			GitRepo:            reconcileConfig.gitRepo,
+			GitRef:             reconcileConfig.gitRef,
+			GitPath:            reconcileConfig.gitPath,
+			Interval:           reconcileConfig.interval,
+			Jitter:             reconcileConfig.jitter,
+			MaxChangesPerCycle: reconcileConfig.maxChangesPerCycle,
+			SleepLoopTime:      reconcileConfig.sleepLoopTime,
+		},
+	)
+
+	if !reconcileConfig.watch {
+		_, err := reconciler.Reconcile(ctx)
+		return err
+	}
+
+	if reconcileConfig.metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", reconciler.Metrics())
+		server := &http.Server{
+			Addr:    reconcileConfig.metricsAddr,
+			Handler: mux,
+		}
+		defer server.Close()
+
+		go func() {
+			log.Infof("Serving metrics at %s/metrics", reconcileConfig.metricsAddr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Error serving metrics: %+v", err)
+				cancel()
+			}
+		}()
+	}
+
+	err := reconciler.Watch(ctx)
+	if err == context.Canceled {
+		log.Info("Stopping reconciler")
+		return nil
+	}
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/segmentio/topicctl/pkg/admin"
@@ -21,6 +22,7 @@ var replCmd = &cobra.Command{
 }
 
 type replCmdConfig struct {
+	cacheTTL      time.Duration
 	clusterConfig string
 	zkAddr        string
 	zkPrefix      string
@@ -29,6 +31,12 @@ type replCmdConfig struct {
 var replConfig replCmdConfig
 
 func init() {
+	replCmd.Flags().DurationVar(
+		&replConfig.cacheTTL,
+		"cache-ttl",
+		0,
+		"Amount of time to cache cluster metadata for across commands (0 to disable)",
+	)
 	replCmd.Flags().StringVar(
 		&replConfig.clusterConfig,
 		"cluster-config",
@@ -60,6 +68,9 @@ func replPreRun(cmd *cobra.Command, args []string) error {
 		(replConfig.zkAddr != "" || replConfig.zkPrefix != "") {
 		log.Warn("zk-addr and zk-prefix flags are ignored when using cluster-config")
 	}
+	if replConfig.cacheTTL < 0 {
+		return errors.New("Cache TTL cannot be negative")
+	}
 
 	return nil
 }
@@ -96,6 +107,10 @@ func replRun(cmd *cobra.Command, args []string) error {
 	}
 	defer adminClient.Close()
 
+	if replConfig.cacheTTL > 0 {
+		adminClient.EnableCache(replConfig.cacheTTL)
+	}
+
 	repl, err := cli.NewRepl(ctx, adminClient)
 	if err != nil {
 		return err
@@ -0,0 +1,412 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/metrics"
+	"github.com/segmentio/topicctl/pkg/report"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [subcommand]",
+	Short: "generate reports about cluster usage",
+}
+
+var reportCompactionCmd = &cobra.Command{
+	Use:     "compaction",
+	Short:   "report cleaner lag and risky settings for compacted topics",
+	Args:    cobra.NoArgs,
+	PreRunE: reportCompactionPreRun,
+	RunE:    reportCompactionRun,
+}
+
+type reportCompactionCmdConfig struct {
+	all                bool
+	cleanerMetrics     string
+	match              string
+	maxDirtyRatio      float64
+	maxSegmentAge      time.Duration
+	minDeleteRetention time.Duration
+	output             string
+	sampleInterval     time.Duration
+
+	shared sharedOptions
+}
+
+var reportCompactionConfig reportCompactionCmdConfig
+
+var reportUsageCmd = &cobra.Command{
+	Use:     "usage",
+	Short:   "report storage, partitions, and throughput per team or topic",
+	Args:    cobra.NoArgs,
+	PreRunE: reportUsagePreRun,
+	RunE:    reportUsageRun,
+}
+
+type reportUsageCmdConfig struct {
+	by               string
+	match            string
+	output           string
+	partitionMetrics string
+	topicConfigs     string
+
+	shared sharedOptions
+}
+
+var reportUsageConfig reportUsageCmdConfig
+
+func init() {
+	reportUsageCmd.Flags().StringVar(
+		&reportUsageConfig.by,
+		"by",
+		"team",
+		"How to group the results, one of [team topic]",
+	)
+	reportUsageCmd.Flags().StringVar(
+		&reportUsageConfig.match,
+		"match",
+		"",
+		"Only include topics matching this glob, or regex if wrapped in slashes",
+	)
+	reportUsageCmd.Flags().StringVarP(
+		&reportUsageConfig.output,
+		"output",
+		"o",
+		"table",
+		"Output format, one of [table csv json]",
+	)
+	reportUsageCmd.Flags().StringVar(
+		&reportUsageConfig.partitionMetrics,
+		"partition-metrics",
+		"",
+		"Path to a JSON or CSV file with the bytes-in rate of each partition (optional)",
+	)
+	reportUsageCmd.Flags().StringVar(
+		&reportUsageConfig.topicConfigs,
+		"topic-configs",
+		"",
+		"Directory of topic configs to look up owners in; defaults to the topics directory next to the cluster config",
+	)
+	addSharedFlags(reportUsageCmd, &reportUsageConfig.shared)
+
+	reportCompactionCmd.Flags().BoolVar(
+		&reportCompactionConfig.all,
+		"all",
+		false,
+		"Include compacted topics without any problems",
+	)
+	reportCompactionCmd.Flags().StringVar(
+		&reportCompactionConfig.cleanerMetrics,
+		"cleaner-metrics",
+		"",
+		"Path to a JSON or CSV file with the log cleaner dirty ratio of each partition (optional)",
+	)
+	reportCompactionCmd.Flags().StringVar(
+		&reportCompactionConfig.match,
+		"match",
+		"",
+		"Only include topics matching this glob, or regex if wrapped in slashes",
+	)
+	reportCompactionCmd.Flags().Float64Var(
+		&reportCompactionConfig.maxDirtyRatio,
+		"max-dirty-ratio",
+		0.8,
+		"Dirty ratio above which a partition is considered to be lagging behind the cleaner",
+	)
+	reportCompactionCmd.Flags().DurationVar(
+		&reportCompactionConfig.maxSegmentAge,
+		"max-segment-age",
+		7*24*time.Hour,
+		"Largest segment.ms that isn't flagged as delaying compaction",
+	)
+	reportCompactionCmd.Flags().DurationVar(
+		&reportCompactionConfig.minDeleteRetention,
+		"min-delete-retention",
+		time.Hour,
+		"Smallest delete.retention.ms that isn't flagged as dropping tombstones too early",
+	)
+	reportCompactionCmd.Flags().StringVarP(
+		&reportCompactionConfig.output,
+		"output",
+		"o",
+		"table",
+		"Output format, one of [table json]",
+	)
+	reportCompactionCmd.Flags().DurationVar(
+		&reportCompactionConfig.sampleInterval,
+		"sample-interval",
+		0,
+		"If set and cleaner metrics aren't provided, estimate dirty ratios by sampling log dir sizes this far apart",
+	)
+	addSharedFlags(reportCompactionCmd, &reportCompactionConfig.shared)
+
+	reportCmd.AddCommand(reportCompactionCmd)
+	reportCmd.AddCommand(reportUsageCmd)
+	RootCmd.AddCommand(reportCmd)
+}
+
+func reportCompactionPreRun(cmd *cobra.Command, args []string) error {
+	if reportCompactionConfig.maxDirtyRatio < 0 || reportCompactionConfig.maxDirtyRatio > 1 {
+		return fmt.Errorf("Max dirty ratio must be between 0 and 1")
+	}
+	if reportCompactionConfig.cleanerMetrics != "" && reportCompactionConfig.sampleInterval > 0 {
+		return fmt.Errorf("Cannot set both cleaner-metrics and sample-interval")
+	}
+	if reportCompactionConfig.output != "table" && reportCompactionConfig.output != "json" {
+		return fmt.Errorf("Output must be one of [table json]")
+	}
+	return reportCompactionConfig.shared.validate()
+}
+
+func reportCompactionRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	var fetcher metrics.DirtyRatioFetcher
+	if reportCompactionConfig.cleanerMetrics != "" {
+		dirtyRatios, err := metrics.LoadDirtyRatios(reportCompactionConfig.cleanerMetrics)
+		if err != nil {
+			return err
+		}
+		fetcher = dirtyRatios
+	}
+
+	adminClient, err := reportCompactionConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	topicNames, err := adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Strings(topicNames)
+
+	if reportCompactionConfig.match != "" {
+		topicMatcher, err := util.NewTopicMatcher(reportCompactionConfig.match)
+		if err != nil {
+			return err
+		}
+		topicNames = topicMatcher.Filter(topicNames)
+	}
+
+	log.Infof("Getting details for %d topics", len(topicNames))
+	topics, err := adminClient.GetTopics(ctx, topicNames, false)
+	if err != nil {
+		return err
+	}
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Getting log dir sizes from %d brokers", len(brokers))
+	logDirs, err := adminClient.GetLogDirs(ctx, brokers)
+	if err != nil {
+		return err
+	}
+
+	if reportCompactionConfig.sampleInterval > 0 {
+		log.Infof(
+			"Sampling log dir sizes again in %s to estimate dirty ratios",
+			reportCompactionConfig.sampleInterval,
+		)
+		select {
+		case <-time.After(reportCompactionConfig.sampleInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		sampledLogDirs, err := adminClient.GetLogDirs(ctx, brokers)
+		if err != nil {
+			return err
+		}
+		fetcher = report.EstimateDirtyRatios(logDirs, sampledLogDirs)
+		logDirs = sampledLogDirs
+	}
+
+	healths, err := report.CompactionHealths(
+		ctx,
+		topics,
+		logDirs,
+		fetcher,
+		report.CompactionOptions{
+			MaxDirtyRatio:      reportCompactionConfig.maxDirtyRatio,
+			MinDeleteRetention: reportCompactionConfig.minDeleteRetention,
+			MaxSegmentAge:      reportCompactionConfig.maxSegmentAge,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	numCompacted := len(healths)
+	riskyHealths := []report.CompactionHealth{}
+	for _, health := range healths {
+		if health.Risky() {
+			riskyHealths = append(riskyHealths, health)
+		}
+	}
+	if !reportCompactionConfig.all {
+		healths = riskyHealths
+	}
+
+	if reportCompactionConfig.output == "json" {
+		return printReportJSON(healths)
+	}
+
+	log.Infof(
+		"Found problems in %d of %d compacted topics",
+		len(riskyHealths),
+		numCompacted,
+	)
+	if len(healths) > 0 {
+		log.Infof("Compacted topics:\n%s", report.FormatCompactionHealths(healths))
+	}
+	return nil
+}
+
+func reportUsagePreRun(cmd *cobra.Command, args []string) error {
+	if reportUsageConfig.by != "team" && reportUsageConfig.by != "topic" {
+		return fmt.Errorf("By must be one of [team topic]")
+	}
+	switch reportUsageConfig.output {
+	case "table", "csv", "json":
+	default:
+		return fmt.Errorf("Output must be one of [table csv json]")
+	}
+	return reportUsageConfig.shared.validate()
+}
+
+func reportUsageRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	var clusterConfig config.ClusterConfig
+	if reportUsageConfig.shared.clusterConfig != "" {
+		var err error
+		clusterConfig, err = config.LoadClusterFile(reportUsageConfig.shared.clusterConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	owners, err := loadOwnershipIndex(
+		reportUsageConfig.shared.clusterConfig,
+		reportUsageConfig.topicConfigs,
+		clusterConfig,
+	)
+	if err != nil {
+		return err
+	}
+
+	var fetcher metrics.Fetcher
+	if reportUsageConfig.partitionMetrics != "" {
+		fetcher, err = metrics.NewFileFetcher(reportUsageConfig.partitionMetrics)
+		if err != nil {
+			return err
+		}
+	}
+
+	adminClient, err := reportUsageConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	topicNames, err := adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Strings(topicNames)
+
+	if reportUsageConfig.match != "" {
+		topicMatcher, err := util.NewTopicMatcher(reportUsageConfig.match)
+		if err != nil {
+			return err
+		}
+		topicNames = topicMatcher.Filter(topicNames)
+	}
+
+	log.Infof("Getting details for %d topics", len(topicNames))
+	topics, err := adminClient.GetTopics(ctx, topicNames, false)
+	if err != nil {
+		return err
+	}
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Getting log dir sizes from %d brokers", len(brokers))
+	logDirs, err := adminClient.GetLogDirs(ctx, brokers)
+	if err != nil {
+		return err
+	}
+
+	topicUsages, err := report.TopicUsages(ctx, topics, logDirs, fetcher, owners)
+	if err != nil {
+		return err
+	}
+
+	if reportUsageConfig.by == "topic" {
+		switch reportUsageConfig.output {
+		case "csv":
+			return report.WriteTopicUsagesCSV(os.Stdout, topicUsages)
+		case "json":
+			return printReportJSON(topicUsages)
+		default:
+			log.Infof("Usage by topic:\n%s", report.FormatTopicUsages(topicUsages))
+			return nil
+		}
+	}
+
+	teamUsages := report.TeamUsages(topicUsages)
+
+	switch reportUsageConfig.output {
+	case "csv":
+		return report.WriteTeamUsagesCSV(os.Stdout, teamUsages)
+	case "json":
+		return printReportJSON(teamUsages)
+	default:
+		log.Infof("Usage by team:\n%s", report.FormatTeamUsages(teamUsages))
+		return nil
+	}
+}
+
+func printReportJSON(obj interface{}) error {
+	jsonBytes, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
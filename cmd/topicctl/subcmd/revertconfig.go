@@ -0,0 +1,104 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var revertConfigCmd = &cobra.Command{
+	Use:       "revert-config [topic|broker] [name or id]",
+	Short:     "revert the last config change that topicctl made to a topic or broker",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: []string{"topic", "broker"},
+	PreRunE:   revertConfigPreRun,
+	RunE:      revertConfigRun,
+}
+
+type revertConfigCmdConfig struct {
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var revertConfigConfig revertConfigCmdConfig
+
+func init() {
+	revertConfigCmd.Flags().BoolVar(
+		&revertConfigConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	addSharedFlags(revertConfigCmd, &revertConfigConfig.shared)
+
+	RootCmd.AddCommand(revertConfigCmd)
+}
+
+func revertConfigPreRun(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "topic":
+	case "broker":
+		if _, err := strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("Broker ID must be an integer: %s", args[1])
+		}
+	default:
+		return fmt.Errorf("Unrecognized entity type: %s", args[0])
+	}
+	return revertConfigConfig.shared.validate()
+}
+
+func revertConfigRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	entityType := admin.ConfigEntityTopic
+	if args[0] == "broker" {
+		entityType = admin.ConfigEntityBroker
+	}
+	entityName := args[1]
+
+	adminClient, err := revertConfigConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	change, err := adminClient.GetLastConfigChange(ctx, entityType, entityName)
+	if err != nil {
+		return err
+	}
+	if change == nil {
+		return fmt.Errorf("No config changes have been recorded for %s %s", args[0], entityName)
+	}
+
+	log.Infof(
+		"Last config change to %s %s, made by %s@%s at %s:\n%s",
+		args[0],
+		entityName,
+		change.User,
+		change.Host,
+		change.Time.Format(time.RFC3339),
+		admin.FormatConfigChange(*change),
+	)
+
+	ok, _ := apply.Confirm(
+		fmt.Sprintf("OK to restore the before values for %s %s?", args[0], entityName),
+		revertConfigConfig.skipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if _, err := adminClient.RevertLastConfigChange(ctx, entityType, entityName); err != nil {
+		return err
+	}
+	log.Infof("Reverted config change to %s %s", args[0], entityName)
+	return nil
+}
@@ -0,0 +1,133 @@
+package subcmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/apply"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:     "rollback [backup id]",
+	Short:   "restore a topic's configs and assignments from a backup made by apply",
+	Long:    "Restore a topic's configs and assignments from a backup made by apply. If no backup ID is provided, the available backups are listed.",
+	Args:    cobra.MaximumNArgs(1),
+	PreRunE: rollbackPreRun,
+	RunE:    rollbackRun,
+}
+
+type rollbackCmdConfig struct {
+	backupDir     string
+	dryRun        bool
+	skipConfirm   bool
+	sleepLoopTime time.Duration
+
+	shared sharedOptions
+}
+
+var rollbackConfig rollbackCmdConfig
+
+func init() {
+	rollbackCmd.Flags().StringVar(
+		&rollbackConfig.backupDir,
+		"backup-dir",
+		defaultBackupDir(),
+		"Directory that backups were written to",
+	)
+	rollbackCmd.Flags().BoolVar(
+		&rollbackConfig.dryRun,
+		"dry-run",
+		false,
+		"Do a dry-run",
+	)
+	rollbackCmd.Flags().BoolVar(
+		&rollbackConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	rollbackCmd.Flags().DurationVar(
+		&rollbackConfig.sleepLoopTime,
+		"sleep-loop-time",
+		10*time.Second,
+		"Amount of time to wait between reassignment checks",
+	)
+	addSharedFlags(rollbackCmd, &rollbackConfig.shared)
+
+	RootCmd.AddCommand(rollbackCmd)
+}
+
+func rollbackPreRun(cmd *cobra.Command, args []string) error {
+	// The cluster is only needed when restoring a backup
+	if len(args) == 0 {
+		return nil
+	}
+	return rollbackConfig.shared.validate()
+}
+
+func rollbackRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		backups, err := apply.ListTopicBackups(rollbackConfig.backupDir)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			log.Infof("No backups found in %s", rollbackConfig.backupDir)
+			return nil
+		}
+		log.Infof(
+			"Backups in %s:\n%s",
+			rollbackConfig.backupDir,
+			apply.FormatTopicBackups(backups),
+		)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	backup, err := apply.LoadTopicBackup(rollbackConfig.backupDir, args[0])
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := rollbackConfig.shared.getAdminClient(
+		ctx,
+		nil,
+		rollbackConfig.dryRun,
+	)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	log.Infof(
+		"Rolling back topic %s in cluster %s to backup from %s",
+		backup.Topic.Name,
+		backup.Cluster,
+		backup.CreatedAt.Format(time.RFC3339),
+	)
+
+	return apply.RollbackTopic(
+		ctx,
+		adminClient,
+		backup,
+		apply.RollbackConfig{
+			DryRun:        rollbackConfig.dryRun,
+			SkipConfirm:   rollbackConfig.skipConfirm,
+			SleepLoopTime: rollbackConfig.sleepLoopTime,
+		},
+	)
+}
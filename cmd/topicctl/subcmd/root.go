@@ -1,16 +1,27 @@
 package subcmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/logging"
+	"github.com/segmentio/topicctl/pkg/util"
 	"github.com/segmentio/topicctl/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	prefixed "github.com/x-cray/logrus-prefixed-formatter"
 )
 
-var debug bool
+var (
+	debug      bool
+	jsonErrors bool
+	logFormat  string
+	noColor    bool
+	qps        float64
+)
 
 // RootCmd is the cobra CLI root command.
 var RootCmd = &cobra.Command{
@@ -22,10 +33,7 @@ var RootCmd = &cobra.Command{
 }
 
 func init() {
-	log.SetFormatter(&prefixed.TextFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-		FullTimestamp:   true,
-	})
+	logging.SetFormat(logging.FormatText)
 
 	RootCmd.PersistentFlags().BoolVar(
 		&debug,
@@ -33,6 +41,36 @@ func init() {
 		false,
 		"Enable debug logging",
 	)
+	RootCmd.PersistentFlags().BoolVar(
+		&jsonErrors,
+		"json-errors",
+		false,
+		"Output errors as JSON objects with their kind and exit code",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&logFormat,
+		"log-format",
+		defaultLogFormat(),
+		fmt.Sprintf("Log format, one of %+v", logging.AllFormats),
+	)
+	RootCmd.PersistentFlags().BoolVar(
+		&noColor,
+		"no-color",
+		false,
+		"Disable colored output; colors are also disabled if NO_COLOR is set or stderr isn't a terminal",
+	)
+	RootCmd.PersistentFlags().Float64Var(
+		&qps,
+		"qps",
+		defaultQPS(),
+		"Maximum rate of zookeeper and broker requests; overrides the cluster config if set, -1 disables limiting",
+	)
+
+	RootCmd.SetFlagErrorFunc(
+		func(cmd *cobra.Command, err error) error {
+			return exitcode.Wrap(exitcode.KindConfig, err)
+		},
+	)
 }
 
 // Execute runs topicctl.
@@ -40,8 +78,14 @@ func Execute(versionRef string) {
 	RootCmd.Version = fmt.Sprintf("v%s (ref:%s)", version.Version, versionRef)
 
 	if err := RootCmd.Execute(); err != nil {
-		log.Errorf("%+v", err)
-		os.Exit(1)
+		if jsonErrors {
+			// Write the error as a single line so that it's easy to pick out of the logs
+			jsonBytes, _ := json.Marshal(exitcode.ToJSONError(err))
+			fmt.Fprintln(os.Stderr, string(jsonBytes))
+		} else {
+			log.Errorf("%+v", err)
+		}
+		os.Exit(exitcode.Code(err))
 	}
 }
 
@@ -49,5 +93,29 @@ func preRun(cmd *cobra.Command, args []string) error {
 	if debug {
 		log.SetLevel(log.DebugLevel)
 	}
+	if err := logging.SetFormat(logging.Format(logFormat)); err != nil {
+		return exitcode.Wrap(exitcode.KindConfig, err)
+	}
+	if noColor || os.Getenv("NO_COLOR") != "" || !util.StderrInTerminal() {
+		logging.DisableColors()
+	}
+	admin.SetQPSOverride(qps)
 	return nil
 }
+
+func defaultLogFormat() string {
+	if format := os.Getenv("TOPICCTL_LOG_FORMAT"); format != "" {
+		return format
+	}
+	return string(logging.FormatText)
+}
+
+func defaultQPS() float64 {
+	if qpsStr := os.Getenv("TOPICCTL_QPS"); qpsStr != "" {
+		if value, err := strconv.ParseFloat(qpsStr, 64); err == nil {
+			return value
+		}
+		log.Warnf("Ignoring invalid TOPICCTL_QPS value: %s", qpsStr)
+	}
+	return 0
+}
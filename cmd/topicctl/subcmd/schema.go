@@ -0,0 +1,36 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:       "schema [topic|cluster|brokers|connector]",
+	Short:     "print the JSON schema for a config file format",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: config.SchemaKinds,
+	RunE:      schemaRun,
+}
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+}
+
+func schemaRun(cmd *cobra.Command, args []string) error {
+	schema, err := config.GenerateJSONSchema(args[0])
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(contents))
+	return nil
+}
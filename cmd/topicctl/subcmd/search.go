@@ -0,0 +1,205 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/cli"
+	"github.com/segmentio/topicctl/pkg/messages"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:     "search [topic name]",
+	Short:   "search for messages in a topic by key and/or time",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: searchPreRun,
+	RunE:    searchRun,
+}
+
+type searchCmdConfig struct {
+	codec          string
+	format         string
+	includeHeaders bool
+	key            string
+	maxMatches     int
+	maxScan        int64
+	partitioner    string
+	since          string
+	until          string
+
+	shared sharedOptions
+}
+
+var searchConfig searchCmdConfig
+
+func init() {
+	searchCmd.Flags().StringVar(
+		&searchConfig.codec,
+		"codec",
+		"",
+		"Codec for decoding message values, either exec:[command] for a subprocess or plugin:[path] for a Go plugin",
+	)
+	searchCmd.Flags().StringVar(
+		&searchConfig.format,
+		"format",
+		string(messages.TailFormatDefault),
+		fmt.Sprintf("Output format, one of %+v", messages.AllTailFormats),
+	)
+	searchCmd.Flags().BoolVar(
+		&searchConfig.includeHeaders,
+		"include-headers",
+		false,
+		"Include message headers in the output",
+	)
+	searchCmd.Flags().StringVar(
+		&searchConfig.key,
+		"key",
+		"",
+		"Message key to search for; if unset, all messages in the time range match",
+	)
+	searchCmd.Flags().IntVar(
+		&searchConfig.maxMatches,
+		"max-matches",
+		0,
+		"Stop after this many matches; if 0, return all matches",
+	)
+	searchCmd.Flags().Int64Var(
+		&searchConfig.maxScan,
+		"max-scan",
+		1000000,
+		"Maximum number of messages to scan across all partitions",
+	)
+	searchCmd.Flags().StringVar(
+		&searchConfig.partitioner,
+		"partitioner",
+		string(messages.PartitionerNone),
+		fmt.Sprintf(
+			"Partitioner used by the topic's producers, one of %+v; used to narrow keyed searches to a single partition",
+			messages.AllPartitioners,
+		),
+	)
+	searchCmd.Flags().StringVar(
+		&searchConfig.since,
+		"since",
+		"",
+		"Only match messages at or after this time (RFC3339 timestamp or duration ago, e.g. 2h)",
+	)
+	searchCmd.Flags().StringVar(
+		&searchConfig.until,
+		"until",
+		"",
+		"Only match messages before this time (RFC3339 timestamp or duration ago, e.g. 1h)",
+	)
+	addSharedFlags(searchCmd, &searchConfig.shared)
+
+	RootCmd.AddCommand(searchCmd)
+}
+
+func searchPreRun(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("key") && searchConfig.since == "" && searchConfig.until == "" {
+		return errors.New("Must set at least one of key, since, or until")
+	}
+	if searchConfig.maxScan <= 0 {
+		return errors.New("max-scan must be positive")
+	}
+
+	validPartitioner := false
+	for _, partitioner := range messages.AllPartitioners {
+		if searchConfig.partitioner == string(partitioner) {
+			validPartitioner = true
+			break
+		}
+	}
+	if !validPartitioner {
+		return fmt.Errorf("partitioner must be in %+v", messages.AllPartitioners)
+	}
+	if err := searchOutputConfig().Validate(); err != nil {
+		return err
+	}
+	if !searchOutputConfig().Interactive() {
+		// When the output is meant for scripts, only log out errors
+		log.SetLevel(log.ErrorLevel)
+	}
+	return searchConfig.shared.validate()
+}
+
+func searchRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	now := time.Now()
+	since, err := messages.ParseSearchTime(searchConfig.since, now)
+	if err != nil {
+		return err
+	}
+	until, err := messages.ParseSearchTime(searchConfig.until, now)
+	if err != nil {
+		return err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return errors.New("since must be before until")
+	}
+
+	var key []byte
+	if cmd.Flags().Changed("key") {
+		key = []byte(searchConfig.key)
+	}
+
+	adminClient, err := searchConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	outputConfig := searchOutputConfig()
+	if searchConfig.codec != "" {
+		codec, err := messages.NewCodec(searchConfig.codec)
+		if err != nil {
+			return err
+		}
+		defer codec.Close()
+		outputConfig.Codec = codec
+	}
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, outputConfig.Interactive())
+	return cliRunner.Search(
+		ctx,
+		messages.SearchConfig{
+			Topic:           args[0],
+			Key:             key,
+			Partitioner:     messages.Partitioner(searchConfig.partitioner),
+			Since:           since,
+			Until:           until,
+			MaxScanMessages: searchConfig.maxScan,
+			MaxMatches:      searchConfig.maxMatches,
+		},
+		outputConfig,
+	)
+}
+
+func searchOutputConfig() messages.TailOutputConfig {
+	outputConfig := messages.TailOutputConfig{
+		Format:         messages.TailFormat(searchConfig.format),
+		IncludeHeaders: searchConfig.includeHeaders,
+	}
+	if outputConfig.Format == messages.TailFormatJSONL {
+		outputConfig.IncludePartition = true
+		outputConfig.IncludeOffset = true
+		outputConfig.IncludeTimestamp = true
+	}
+	return outputConfig
+}
@@ -0,0 +1,112 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// sharedOptions contains the cluster-targeting flags that are common to most of the
+// subcommands which don't run as part of an apply workflow.
+type sharedOptions struct {
+	clusterConfig string
+	zkAddr        string
+	zkPrefix      string
+}
+
+func addSharedFlags(cmd *cobra.Command, options *sharedOptions) {
+	cmd.Flags().StringVar(
+		&options.clusterConfig,
+		"cluster-config",
+		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
+		"Cluster config",
+	)
+	cmd.Flags().StringVarP(
+		&options.zkAddr,
+		"zk-addr",
+		"z",
+		"",
+		"ZooKeeper address",
+	)
+	cmd.Flags().StringVar(
+		&options.zkPrefix,
+		"zk-prefix",
+		"",
+		"Prefix for cluster-related nodes in zk",
+	)
+}
+
+func (s sharedOptions) validate() error {
+	if s.clusterConfig == "" && s.zkAddr == "" {
+		return exitcode.Wrap(
+			exitcode.KindConfig,
+			errors.New("Must set either cluster-config or zk address"),
+		)
+	}
+	if s.clusterConfig != "" && (s.zkAddr != "" || s.zkPrefix != "") {
+		log.Warn("zk-addr and zk-prefix flags are ignored when using cluster-config")
+	}
+
+	return nil
+}
+
+func (s sharedOptions) getAdminClient(
+	ctx context.Context,
+	sess *session.Session,
+	readOnly bool,
+) (*admin.Client, error) {
+	if s.clusterConfig != "" {
+		clusterConfig, err := config.LoadClusterFile(s.clusterConfig)
+		if err != nil {
+			return nil, err
+		}
+		return clusterConfig.NewAdminClient(ctx, sess, readOnly)
+	}
+
+	return admin.NewClient(
+		ctx,
+		admin.ClientConfig{
+			ZKAddrs:  []string{s.zkAddr},
+			ZKPrefix: s.zkPrefix,
+			Sess:     sess,
+			ReadOnly: readOnly,
+		},
+	)
+}
+
+// loadOwnershipIndex returns an index of the owners of each topic based on the argument
+// cluster config, which can be empty, and the topic configs in the argument directory. If
+// the directory isn't set, the topics directory next to the cluster config is used if it
+// exists.
+func loadOwnershipIndex(
+	clusterConfigPath string,
+	topicConfigsDir string,
+	clusterConfig config.ClusterConfig,
+) (*config.OwnershipIndex, error) {
+	if topicConfigsDir != "" {
+		if _, err := os.Stat(topicConfigsDir); err != nil {
+			return nil, exitcode.Wrap(exitcode.KindConfig, err)
+		}
+	} else if clusterConfigPath != "" {
+		topicConfigsDir = filepath.Join(filepath.Dir(clusterConfigPath), "topics")
+	}
+
+	topicConfigs := []config.TopicConfig{}
+	if topicConfigsDir != "" {
+		var err error
+		topicConfigs, err = config.LoadTopicConfigs(topicConfigsDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config.NewOwnershipIndex(clusterConfig, topicConfigs)
+}
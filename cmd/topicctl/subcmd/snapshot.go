@@ -0,0 +1,174 @@
+package subcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [subcommand]",
+	Short: "save and compare snapshots of cluster metadata",
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:     "save [path]",
+	Short:   "save the brokers, topic configs, and partition state of a cluster to a file",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: snapshotSavePreRun,
+	RunE:    snapshotSaveRun,
+}
+
+var snapshotDiffCmd = &cobra.Command{
+	Use:     "diff [before path] [optional after path]",
+	Short:   "compare two snapshots, or a snapshot and the current cluster state",
+	Args:    cobra.RangeArgs(1, 2),
+	PreRunE: snapshotDiffPreRun,
+	RunE:    snapshotDiffRun,
+}
+
+type snapshotSaveCmdConfig struct {
+	match string
+
+	shared sharedOptions
+}
+
+var snapshotSaveConfig snapshotSaveCmdConfig
+
+type snapshotDiffCmdConfig struct {
+	shared sharedOptions
+}
+
+var snapshotDiffConfig snapshotDiffCmdConfig
+
+func init() {
+	snapshotSaveCmd.Flags().StringVar(
+		&snapshotSaveConfig.match,
+		"match",
+		"",
+		"Only include topics whose names match this glob, or regex if wrapped in slashes",
+	)
+	addSharedFlags(snapshotSaveCmd, &snapshotSaveConfig.shared)
+	addSharedFlags(snapshotDiffCmd, &snapshotDiffConfig.shared)
+
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotDiffCmd)
+	RootCmd.AddCommand(snapshotCmd)
+}
+
+func snapshotSavePreRun(cmd *cobra.Command, args []string) error {
+	return snapshotSaveConfig.shared.validate()
+}
+
+func snapshotSaveRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var topicMatcher *util.TopicMatcher
+	if snapshotSaveConfig.match != "" {
+		var err error
+		topicMatcher, err = util.NewTopicMatcher(snapshotSaveConfig.match)
+		if err != nil {
+			return exitcode.Wrap(exitcode.KindConfig, err)
+		}
+	}
+
+	adminClient, err := snapshotSaveConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	snapshot, err := adminClient.GetClusterSnapshot(ctx, topicMatcher)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.WriteFile(args[0]); err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Saved snapshot of %d broker(s) and %d topic(s) to %s",
+		len(snapshot.Brokers),
+		len(snapshot.Topics),
+		args[0],
+	)
+	return nil
+}
+
+func snapshotDiffPreRun(cmd *cobra.Command, args []string) error {
+	// The cluster is only needed when comparing against the live state
+	if len(args) == 1 {
+		return snapshotDiffConfig.shared.validate()
+	}
+	return nil
+}
+
+func snapshotDiffRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	before, err := admin.LoadSnapshotFile(args[0])
+	if err != nil {
+		return exitcode.Wrap(exitcode.KindConfig, err)
+	}
+
+	var after admin.ClusterSnapshot
+	afterName := "the current cluster state"
+
+	if len(args) == 2 {
+		after, err = admin.LoadSnapshotFile(args[1])
+		if err != nil {
+			return exitcode.Wrap(exitcode.KindConfig, err)
+		}
+		afterName = args[1]
+
+		if before.Match != after.Match {
+			log.Warnf(
+				"Snapshots were filtered with different topic matches (%s and %s); topics that only match one will show as added or removed",
+				before.Match,
+				after.Match,
+			)
+		}
+	} else {
+		// Use the same topic filter as the saved snapshot
+		var topicMatcher *util.TopicMatcher
+		if before.Match != "" {
+			topicMatcher, err = util.NewTopicMatcher(before.Match)
+			if err != nil {
+				return err
+			}
+		}
+
+		adminClient, err := snapshotDiffConfig.shared.getAdminClient(ctx, nil, true)
+		if err != nil {
+			return err
+		}
+		defer adminClient.Close()
+
+		after, err = adminClient.GetClusterSnapshot(ctx, topicMatcher)
+		if err != nil {
+			return err
+		}
+	}
+
+	diffs := admin.DiffSnapshots(before, after)
+	if len(diffs) == 0 {
+		log.Infof("No differences between %s and %s", args[0], afterName)
+		return nil
+	}
+
+	log.Infof(
+		"Differences between %s and %s:\n%s",
+		args[0],
+		afterName,
+		admin.FormatSnapshotDiffs(diffs),
+	)
+	return exitcode.Wrap(
+		exitcode.KindDrift,
+		fmt.Errorf("Found %d difference(s) between the snapshots", len(diffs)),
+	)
+}
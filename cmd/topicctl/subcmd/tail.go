@@ -3,34 +3,62 @@ package subcmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/cli"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/messages"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var tailCmd = &cobra.Command{
-	Use:     "tail [topic name]",
-	Short:   "tail events in a topic",
-	Args:    cobra.MinimumNArgs(1),
+	Use:     "tail [topic name(s)]",
+	Short:   "tail events in one or more topics",
+	Args:    cobra.ArbitraryArgs,
 	PreRunE: tailPreRun,
 	RunE:    tailRun,
+	ValidArgsFunction: func(
+		cmd *cobra.Command,
+		args []string,
+		toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		return completeTopics(
+			sharedOptions{
+				clusterConfig: tailConfig.clusterConfig,
+				zkAddr:        tailConfig.zkAddr,
+				zkPrefix:      tailConfig.zkPrefix,
+			},
+			args,
+			toComplete,
+		)
+	},
 }
 
 type tailCmdConfig struct {
-	clusterConfig string
-	offset        int64
-	partitions    []int
-	raw           bool
-	zkAddr        string
-	zkPrefix      string
+	clusterConfig    string
+	codec            string
+	format           string
+	groupByHeader    string
+	groupID          string
+	includeHeaders   bool
+	includeOffset    bool
+	includePartition bool
+	includeTimestamp bool
+	offset           int64
+	partitions       []int
+	raw              bool
+	sampleWindow     time.Duration
+	topicRegex       string
+	zkAddr           string
+	zkPrefix         string
 }
 
 var tailConfig tailCmdConfig
@@ -42,11 +70,59 @@ func init() {
 		os.Getenv("TOPICCTL_CLUSTER_CONFIG"),
 		"Cluster config",
 	)
+	tailCmd.Flags().StringVar(
+		&tailConfig.codec,
+		"codec",
+		"",
+		"Codec for decoding message values, either exec:[command] for a subprocess or plugin:[path] for a Go plugin",
+	)
+	tailCmd.Flags().StringVar(
+		&tailConfig.format,
+		"format",
+		string(messages.TailFormatDefault),
+		fmt.Sprintf("Output format, one of %+v", messages.AllTailFormats),
+	)
+	tailCmd.Flags().StringVar(
+		&tailConfig.groupByHeader,
+		"group-by-header",
+		"",
+		"Instead of printing messages, count them by the value of this header and print a summary",
+	)
+	tailCmd.Flags().StringVar(
+		&tailConfig.groupID,
+		"group",
+		"",
+		"Consumer group to tail as; offsets are committed so that the tail can be resumed",
+	)
+	tailCmd.Flags().BoolVar(
+		&tailConfig.includeHeaders,
+		"include-headers",
+		false,
+		"Include message headers in the output (non-default formats only)",
+	)
+	tailCmd.Flags().BoolVar(
+		&tailConfig.includeOffset,
+		"include-offset",
+		false,
+		"Include message offsets in the output (non-default formats only)",
+	)
+	tailCmd.Flags().BoolVar(
+		&tailConfig.includePartition,
+		"include-partition",
+		false,
+		"Include message partitions in the output (non-default formats only)",
+	)
+	tailCmd.Flags().BoolVar(
+		&tailConfig.includeTimestamp,
+		"include-timestamp",
+		false,
+		"Include message timestamps in the output (non-default formats only)",
+	)
 	tailCmd.Flags().Int64Var(
 		&tailConfig.offset,
 		"offset",
 		kafka.LastOffset,
-		"Offset (defaults to last)",
+		"Offset (defaults to last); with a group, only used for partitions without committed offsets",
 	)
 	tailCmd.Flags().IntSliceVar(
 		&tailConfig.partitions,
@@ -58,7 +134,19 @@ func init() {
 		&tailConfig.raw,
 		"raw",
 		false,
-		"Output raw values only",
+		"Output raw values only; equivalent to --format=raw",
+	)
+	tailCmd.Flags().DurationVar(
+		&tailConfig.sampleWindow,
+		"sample-window",
+		30*time.Second,
+		"How long to sample messages for with group-by-header; 0 to sample until interrupted",
+	)
+	tailCmd.Flags().StringVar(
+		&tailConfig.topicRegex,
+		"topic-regex",
+		"",
+		"Regex for additional topics to tail",
 	)
 	tailCmd.Flags().StringVarP(
 		&tailConfig.zkAddr,
@@ -78,8 +166,40 @@ func init() {
 }
 
 func tailPreRun(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && tailConfig.topicRegex == "" {
+		return errors.New("Must set at least one topic or a topic regex")
+	}
+	if len(tailConfig.partitions) > 0 && (len(args) != 1 || tailConfig.topicRegex != "") {
+		return errors.New("Partitions can only be set when tailing a single topic")
+	}
+	if tailConfig.groupID != "" {
+		if len(tailConfig.partitions) > 0 {
+			return errors.New("Partitions cannot be set when tailing with a group")
+		}
+		if tailConfig.offset != kafka.FirstOffset && tailConfig.offset != kafka.LastOffset {
+			return errors.New("Offset must be first (-2) or last (-1) when tailing with a group")
+		}
+	}
 	if tailConfig.raw {
-		// In raw mode, only log out errors
+		if cmd.Flags().Changed("format") &&
+			tailConfig.format != string(messages.TailFormatRaw) {
+			return errors.New("Cannot set both raw and a non-raw format")
+		}
+		tailConfig.format = string(messages.TailFormatRaw)
+	}
+	if tailConfig.groupByHeader != "" {
+		if cmd.Flags().Changed("format") || tailConfig.raw {
+			return errors.New("Cannot set a format when grouping by header")
+		}
+		if tailConfig.sampleWindow < 0 {
+			return errors.New("Sample window cannot be negative")
+		}
+	}
+	if err := tailOutputConfig().Validate(); err != nil {
+		return err
+	}
+	if !tailOutputConfig().Interactive() {
+		// When the output is meant for scripts, only log out errors
 		log.SetLevel(log.ErrorLevel)
 	}
 
@@ -98,7 +218,7 @@ func tailRun(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	sigChan := make(chan os.Signal)
+	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
@@ -132,18 +252,57 @@ func tailRun(cmd *cobra.Command, args []string) error {
 	}
 	defer adminClient.Close()
 
+	outputConfig := tailOutputConfig()
+	if tailConfig.codec != "" {
+		codec, err := messages.NewCodec(tailConfig.codec)
+		if err != nil {
+			return err
+		}
+		defer codec.Close()
+		outputConfig.Codec = codec
+	}
+
 	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, false)
+
+	if tailConfig.groupByHeader != "" {
+		return cliRunner.SummarizeHeaders(
+			ctx,
+			args,
+			tailConfig.topicRegex,
+			tailConfig.groupID,
+			tailConfig.offset,
+			tailConfig.partitions,
+			tailConfig.groupByHeader,
+			tailConfig.sampleWindow,
+			-1,
+			"",
+			outputConfig.Codec,
+		)
+	}
+
 	return cliRunner.Tail(
 		ctx,
-		args[0],
+		args,
+		tailConfig.topicRegex,
+		tailConfig.groupID,
 		tailConfig.offset,
 		tailConfig.partitions,
 		-1,
 		"",
-		tailConfig.raw,
+		outputConfig,
 	)
 }
 
+func tailOutputConfig() messages.TailOutputConfig {
+	return messages.TailOutputConfig{
+		Format:           messages.TailFormat(tailConfig.format),
+		IncludeHeaders:   tailConfig.includeHeaders,
+		IncludeOffset:    tailConfig.includeOffset,
+		IncludePartition: tailConfig.includePartition,
+		IncludeTimestamp: tailConfig.includeTimestamp,
+	}
+}
+
 func stringsToInts(strs []string) ([]int, error) {
 	ints := []int{}
 
@@ -0,0 +1,113 @@
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/cli"
+	"github.com/segmentio/topicctl/pkg/messages"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var truncateCmd = &cobra.Command{
+	Use:     "truncate [topic name]",
+	Short:   "delete old records from the partitions of a topic",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: truncatePreRun,
+	RunE:    truncateRun,
+}
+
+type truncateCmdConfig struct {
+	beforeOffset    int64
+	beforeTimestamp string
+	dryRun          bool
+	partitions      []int
+	skipConfirm     bool
+
+	shared sharedOptions
+}
+
+var truncateConfig truncateCmdConfig
+
+func init() {
+	truncateCmd.Flags().Int64Var(
+		&truncateConfig.beforeOffset,
+		"before-offset",
+		-1,
+		"Delete all records before this offset in each partition",
+	)
+	truncateCmd.Flags().StringVar(
+		&truncateConfig.beforeTimestamp,
+		"before-timestamp",
+		"",
+		"Delete all records before this time (RFC3339 timestamp or duration ago, e.g. 24h)",
+	)
+	truncateCmd.Flags().BoolVar(
+		&truncateConfig.dryRun,
+		"dry-run",
+		false,
+		"Show how many offsets would be removed from each partition without deleting anything",
+	)
+	truncateCmd.Flags().IntSliceVar(
+		&truncateConfig.partitions,
+		"partitions",
+		[]int{},
+		"Partitions to truncate (defaults to all)",
+	)
+	truncateCmd.Flags().BoolVar(
+		&truncateConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts during truncate process",
+	)
+	addSharedFlags(truncateCmd, &truncateConfig.shared)
+
+	RootCmd.AddCommand(truncateCmd)
+}
+
+func truncatePreRun(cmd *cobra.Command, args []string) error {
+	offsetSet := cmd.Flags().Changed("before-offset")
+	timestampSet := truncateConfig.beforeTimestamp != ""
+
+	if offsetSet == timestampSet {
+		return errors.New("Must set exactly one of before-offset or before-timestamp")
+	}
+	if offsetSet && truncateConfig.beforeOffset < 0 {
+		return errors.New("before-offset cannot be negative")
+	}
+
+	return truncateConfig.shared.validate()
+}
+
+func truncateRun(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	beforeTime, err := messages.ParseSearchTime(truncateConfig.beforeTimestamp, time.Now())
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := truncateConfig.shared.getAdminClient(
+		ctx,
+		nil,
+		truncateConfig.dryRun,
+	)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	cliRunner := cli.NewCLIRunner(adminClient, log.Infof, true)
+	return cliRunner.Truncate(
+		ctx,
+		args[0],
+		truncateConfig.partitions,
+		truncateConfig.beforeOffset,
+		beforeTime,
+		truncateConfig.dryRun,
+		truncateConfig.skipConfirm,
+	)
+}
@@ -0,0 +1,376 @@
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// userPasswordEnvVar is the environment variable that user passwords are read from. If it
+// isn't set, the password is prompted for instead.
+const userPasswordEnvVar = "TOPICCTL_USER_PASSWORD"
+
+var userCmd = &cobra.Command{
+	Use:   "user [subcommand]",
+	Short: "manage SCRAM users",
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:     "create [user name]",
+	Short:   "create a SCRAM user or replace its password",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: userCreatePreRun,
+	RunE:    userCreateRun,
+}
+
+var userDeleteCmd = &cobra.Command{
+	Use:     "delete [user name]",
+	Short:   "delete the SCRAM credentials of a user",
+	Args:    cobra.ExactArgs(1),
+	PreRunE: userDeletePreRun,
+	RunE:    userDeleteRun,
+}
+
+var userListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "list the SCRAM users in a cluster",
+	Args:    cobra.NoArgs,
+	PreRunE: userListPreRun,
+	RunE:    userListRun,
+}
+
+type userCreateCmdConfig struct {
+	iterations  int
+	mechanisms  []string
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var userCreateConfig userCreateCmdConfig
+
+type userDeleteCmdConfig struct {
+	mechanisms  []string
+	skipConfirm bool
+
+	shared sharedOptions
+}
+
+var userDeleteConfig userDeleteCmdConfig
+
+type userListCmdConfig struct {
+	shared sharedOptions
+}
+
+var userListConfig userListCmdConfig
+
+func init() {
+	allMechanisms := []string{}
+	for _, mechanism := range admin.AllScramMechanisms {
+		allMechanisms = append(allMechanisms, string(mechanism))
+	}
+
+	userCreateCmd.Flags().IntVar(
+		&userCreateConfig.iterations,
+		"iterations",
+		admin.MinScramIterations,
+		"Number of SCRAM iterations",
+	)
+	userCreateCmd.Flags().StringSliceVar(
+		&userCreateConfig.mechanisms,
+		"mechanisms",
+		allMechanisms,
+		"SCRAM mechanisms to create credentials for",
+	)
+	userCreateCmd.Flags().BoolVar(
+		&userCreateConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	addSharedFlags(userCreateCmd, &userCreateConfig.shared)
+
+	userDeleteCmd.Flags().StringSliceVar(
+		&userDeleteConfig.mechanisms,
+		"mechanisms",
+		allMechanisms,
+		"SCRAM mechanisms to delete credentials for",
+	)
+	userDeleteCmd.Flags().BoolVar(
+		&userDeleteConfig.skipConfirm,
+		"skip-confirm",
+		false,
+		"Skip confirmation prompts",
+	)
+	addSharedFlags(userDeleteCmd, &userDeleteConfig.shared)
+
+	addSharedFlags(userListCmd, &userListConfig.shared)
+
+	userCmd.AddCommand(userCreateCmd)
+	userCmd.AddCommand(userDeleteCmd)
+	userCmd.AddCommand(userListCmd)
+	RootCmd.AddCommand(userCmd)
+}
+
+func userCreatePreRun(cmd *cobra.Command, args []string) error {
+	if userCreateConfig.iterations < admin.MinScramIterations ||
+		userCreateConfig.iterations > admin.MaxScramIterations {
+		return exitcode.Wrap(
+			exitcode.KindConfig,
+			fmt.Errorf(
+				"iterations must be between %d and %d",
+				admin.MinScramIterations,
+				admin.MaxScramIterations,
+			),
+		)
+	}
+	if _, err := parseScramMechanisms(userCreateConfig.mechanisms); err != nil {
+		return err
+	}
+	return userCreateConfig.shared.validate()
+}
+
+func userCreateRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	userName := args[0]
+
+	mechanisms, err := parseScramMechanisms(userCreateConfig.mechanisms)
+	if err != nil {
+		return err
+	}
+
+	policy := config.DefaultPasswordPolicy
+	if userCreateConfig.shared.clusterConfig != "" {
+		clusterConfig, err := config.LoadClusterFile(userCreateConfig.shared.clusterConfig)
+		if err != nil {
+			return err
+		}
+		if clusterConfig.Spec.PasswordPolicy != nil {
+			policy = *clusterConfig.Spec.PasswordPolicy
+		}
+	}
+
+	password, err := readUserPassword(userName)
+	if err != nil {
+		return err
+	}
+	if err := policy.Check(userName, password); err != nil {
+		return exitcode.Wrap(
+			exitcode.KindValidation,
+			fmt.Errorf("Password does not meet the cluster's password policy: %+v", err),
+		)
+	}
+
+	adminClient, err := userCreateConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	existingUser, err := getUser(ctx, adminClient, userName)
+	if err != nil {
+		return err
+	}
+	replaced := []string{}
+	for _, mechanism := range mechanisms {
+		if existingUser.HasMechanism(mechanism) {
+			replaced = append(replaced, string(mechanism))
+		}
+	}
+
+	if len(replaced) > 0 {
+		ok, _ := apply.Confirm(
+			fmt.Sprintf(
+				"User %s already has %s credentials; OK to replace them?",
+				userName,
+				strings.Join(replaced, ", "),
+			),
+			userCreateConfig.skipConfirm,
+		)
+		if !ok {
+			return errors.New("Stopping because of user response")
+		}
+	}
+
+	for _, mechanism := range mechanisms {
+		err := adminClient.UpsertUserScramCredential(
+			ctx,
+			userName,
+			mechanism,
+			password,
+			userCreateConfig.iterations,
+		)
+		if err != nil {
+			return err
+		}
+		log.Infof("Set %s credential for user %s", mechanism, userName)
+	}
+
+	return nil
+}
+
+func userDeletePreRun(cmd *cobra.Command, args []string) error {
+	if _, err := parseScramMechanisms(userDeleteConfig.mechanisms); err != nil {
+		return err
+	}
+	return userDeleteConfig.shared.validate()
+}
+
+func userDeleteRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	userName := args[0]
+
+	mechanisms, err := parseScramMechanisms(userDeleteConfig.mechanisms)
+	if err != nil {
+		return err
+	}
+
+	adminClient, err := userDeleteConfig.shared.getAdminClient(ctx, nil, false)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	existingUser, err := getUser(ctx, adminClient, userName)
+	if err != nil {
+		return err
+	}
+
+	toDelete := []admin.ScramMechanism{}
+	for _, mechanism := range mechanisms {
+		if existingUser.HasMechanism(mechanism) {
+			toDelete = append(toDelete, mechanism)
+		}
+	}
+	if len(toDelete) == 0 {
+		return fmt.Errorf("User %s has no credentials for %+v", userName, mechanisms)
+	}
+
+	ok, _ := apply.Confirm(
+		fmt.Sprintf("OK to delete the %+v credentials of user %s?", toDelete, userName),
+		userDeleteConfig.skipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	for _, mechanism := range toDelete {
+		if err := adminClient.DeleteUserScramCredential(ctx, userName, mechanism); err != nil {
+			return err
+		}
+		log.Infof("Deleted %s credential for user %s", mechanism, userName)
+	}
+
+	return nil
+}
+
+func userListPreRun(cmd *cobra.Command, args []string) error {
+	return userListConfig.shared.validate()
+}
+
+func userListRun(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	adminClient, err := userListConfig.shared.getAdminClient(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+	defer adminClient.Close()
+
+	users, err := adminClient.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(users) == 0 {
+		log.Info("No SCRAM users found")
+		return nil
+	}
+
+	log.Infof("SCRAM users:\n%s", admin.FormatUsers(users))
+	return nil
+}
+
+func parseScramMechanisms(values []string) ([]admin.ScramMechanism, error) {
+	if len(values) == 0 {
+		return nil, exitcode.Wrap(
+			exitcode.KindConfig,
+			errors.New("At least one SCRAM mechanism must be set"),
+		)
+	}
+
+	mechanisms := []admin.ScramMechanism{}
+	for _, value := range values {
+		mechanism, err := admin.ParseScramMechanism(value)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.KindConfig, err)
+		}
+		mechanisms = append(mechanisms, mechanism)
+	}
+	return mechanisms, nil
+}
+
+// getUser returns the user with the argument name. If it doesn't exist, a user without any
+// credentials is returned.
+func getUser(ctx context.Context, adminClient *admin.Client, name string) (admin.UserInfo, error) {
+	users, err := adminClient.GetUsers(ctx)
+	if err != nil {
+		return admin.UserInfo{}, err
+	}
+
+	for _, user := range users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return admin.UserInfo{Name: name}, nil
+}
+
+// readUserPassword gets the password for the argument user from the environment or, if it's
+// not set there, from stdin. In a terminal, the password is prompted for twice without
+// echoing it.
+func readUserPassword(userName string) (string, error) {
+	if password := os.Getenv(userPasswordEnvVar); password != "" {
+		return password, nil
+	}
+
+	stdinFd := int(syscall.Stdin)
+	if !terminal.IsTerminal(stdinFd) {
+		password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && password == "" {
+			return "", fmt.Errorf("Error reading password from stdin: %+v", err)
+		}
+		return strings.TrimRight(password, "\r\n"), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Password for user %s: ", userName)
+	password, err := terminal.ReadPassword(stdinFd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirmation, err := terminal.ReadPassword(stdinFd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if string(password) != string(confirmation) {
+		return "", exitcode.Wrap(exitcode.KindValidation, errors.New("Passwords do not match"))
+	}
+	return string(password), nil
+}
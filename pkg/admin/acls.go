@@ -0,0 +1,311 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the ACL APIs, so the (v1)
+	// requests are made via the protocol package instead. v1 requires Kafka 2.0 or newer.
+	describeACLsAPIKey int16 = 29
+	createACLsAPIKey   int16 = 30
+	deleteACLsAPIKey   int16 = 31
+	aclsAPIVersion     int16 = 1
+
+	aclResourceTypeTopic     int8 = 2
+	aclPatternTypeLiteral    int8 = 3
+	aclOperationWrite        int8 = 4
+	aclPermissionTypeDeny    int8 = 2
+	aclAllPrincipals              = "User:*"
+	aclAllHosts                   = "*"
+	aclSecurityDisabledError      = 54
+)
+
+// aclBinding is a single Kafka ACL.
+type aclBinding struct {
+	resourceType   int8
+	resourceName   string
+	patternType    int8
+	principal      string
+	host           string
+	operation      int8
+	permissionType int8
+}
+
+// denyTopicWritesACL returns the ACL that denies writes to the argument topic from all
+// principals and hosts.
+func denyTopicWritesACL(topic string) aclBinding {
+	return aclBinding{
+		resourceType:   aclResourceTypeTopic,
+		resourceName:   topic,
+		patternType:    aclPatternTypeLiteral,
+		principal:      aclAllPrincipals,
+		host:           aclAllHosts,
+		operation:      aclOperationWrite,
+		permissionType: aclPermissionTypeDeny,
+	}
+}
+
+// TopicWritesBlocked returns whether the argument topic has an ACL that denies writes from
+// all principals, i.e. the one created by BlockTopicWrites.
+func (c *Client) TopicWritesBlocked(ctx context.Context, topic string) (bool, error) {
+	if err := c.CheckFeature(ctx, FeatureACLs); err != nil {
+		return false, err
+	}
+
+	var blocked bool
+
+	err := c.withBrokerRetries(ctx, "describe acls", false, func() error {
+		response, err := c.aclsRoundTrip(
+			ctx,
+			describeACLsAPIKey,
+			encodeDescribeACLsRequest(denyTopicWritesACL(topic)),
+		)
+		if err != nil {
+			return err
+		}
+
+		acls, err := decodeDescribeACLsResponse(response)
+		if err != nil {
+			return err
+		}
+		blocked = len(acls) > 0
+		return nil
+	})
+
+	return blocked, err
+}
+
+// BlockTopicWrites adds an ACL that denies writes to the argument topic from all principals.
+// Since deny ACLs take precedence over allow ones, this blocks all produce requests to the
+// topic. It has no effect unless the cluster has an authorizer configured.
+func (c *Client) BlockTopicWrites(ctx context.Context, topic string) error {
+	if c.readOnly {
+		return errors.New("Cannot block topic writes in read-only mode")
+	}
+
+	if err := c.CheckFeature(ctx, FeatureACLs); err != nil {
+		return err
+	}
+
+	log.Debugf("Blocking writes to topic %s", topic)
+
+	// Creating an ACL that already exists is a no-op, so this is safe to retry
+	return c.withBrokerRetries(ctx, "create acls", false, func() error {
+		response, err := c.aclsRoundTrip(
+			ctx,
+			createACLsAPIKey,
+			encodeCreateACLsRequest(denyTopicWritesACL(topic)),
+		)
+		if err != nil {
+			return err
+		}
+		return decodeCreateACLsResponse(response)
+	})
+}
+
+// UnblockTopicWrites removes the ACL added by BlockTopicWrites, if it exists.
+func (c *Client) UnblockTopicWrites(ctx context.Context, topic string) error {
+	if c.readOnly {
+		return errors.New("Cannot unblock topic writes in read-only mode")
+	}
+
+	if err := c.CheckFeature(ctx, FeatureACLs); err != nil {
+		return err
+	}
+
+	log.Debugf("Unblocking writes to topic %s", topic)
+
+	return c.withBrokerRetries(ctx, "delete acls", false, func() error {
+		response, err := c.aclsRoundTrip(
+			ctx,
+			deleteACLsAPIKey,
+			encodeDeleteACLsRequest(denyTopicWritesACL(topic)),
+		)
+		if err != nil {
+			return err
+		}
+		return decodeDeleteACLsResponse(response)
+	})
+}
+
+func (c *Client) aclsRoundTrip(
+	ctx context.Context,
+	apiKey int16,
+	body *protocol.Encoder,
+) (*protocol.Decoder, error) {
+	controllerAddr, err := c.getControllerAddr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.RoundTrip(ctx, controllerAddr, apiKey, aclsAPIVersion, body)
+}
+
+// encodeACL writes the fields of an ACL binding. ACL creations and (fully-specified) ACL
+// filters have the same layout.
+func encodeACL(body *protocol.Encoder, acl aclBinding) {
+	body.WriteInt8(acl.resourceType)
+	body.WriteString(acl.resourceName)
+	body.WriteInt8(acl.patternType)
+	body.WriteString(acl.principal)
+	body.WriteString(acl.host)
+	body.WriteInt8(acl.operation)
+	body.WriteInt8(acl.permissionType)
+}
+
+func decodeACL(response *protocol.Decoder) aclBinding {
+	return aclBinding{
+		resourceType:   response.ReadInt8(),
+		resourceName:   response.ReadString(),
+		patternType:    response.ReadInt8(),
+		principal:      response.ReadString(),
+		host:           response.ReadString(),
+		operation:      response.ReadInt8(),
+		permissionType: response.ReadInt8(),
+	}
+}
+
+// encodeDescribeACLsRequest encodes the body of a v1 DescribeAcls request that matches
+// the argument ACL exactly.
+func encodeDescribeACLsRequest(filter aclBinding) *protocol.Encoder {
+	body := &protocol.Encoder{}
+	encodeACL(body, filter)
+	return body
+}
+
+// encodeCreateACLsRequest encodes the body of a v1 CreateAcls request for the argument ACL.
+func encodeCreateACLsRequest(acl aclBinding) *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	// Creations array (just one ACL)
+	body.WriteInt32(1)
+	encodeACL(body, acl)
+
+	return body
+}
+
+// encodeDeleteACLsRequest encodes the body of a v1 DeleteAcls request that matches the
+// argument ACL exactly.
+func encodeDeleteACLsRequest(filter aclBinding) *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	// Filters array (just one filter)
+	body.WriteInt32(1)
+	encodeACL(body, filter)
+
+	return body
+}
+
+// decodeDescribeACLsResponse decodes the body of a v1 DescribeAcls response and returns the
+// matching ACLs.
+func decodeDescribeACLsResponse(response *protocol.Decoder) ([]aclBinding, error) {
+	// Throttle time
+	response.ReadInt32()
+
+	if err := aclError(response.ReadInt16(), response.ReadString()); err != nil {
+		return nil, err
+	}
+
+	acls := []aclBinding{}
+
+	numResources := response.ReadInt32()
+	for i := 0; i < int(numResources) && response.Err() == nil; i++ {
+		resourceType := response.ReadInt8()
+		resourceName := response.ReadString()
+		patternType := response.ReadInt8()
+
+		numACLs := response.ReadInt32()
+		for j := 0; j < int(numACLs) && response.Err() == nil; j++ {
+			acls = append(
+				acls,
+				aclBinding{
+					resourceType:   resourceType,
+					resourceName:   resourceName,
+					patternType:    patternType,
+					principal:      response.ReadString(),
+					host:           response.ReadString(),
+					operation:      response.ReadInt8(),
+					permissionType: response.ReadInt8(),
+				},
+			)
+		}
+	}
+
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return acls, nil
+}
+
+// decodeCreateACLsResponse decodes the body of a v1 CreateAcls response and returns the
+// first error in it, if any.
+func decodeCreateACLsResponse(response *protocol.Decoder) error {
+	// Throttle time
+	response.ReadInt32()
+
+	var err error
+
+	numResults := response.ReadInt32()
+	for i := 0; i < int(numResults) && response.Err() == nil; i++ {
+		resultErr := aclError(response.ReadInt16(), response.ReadString())
+		if err == nil {
+			err = resultErr
+		}
+	}
+
+	if decodeErr := response.Err(); decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+// decodeDeleteACLsResponse decodes the body of a v1 DeleteAcls response and returns the
+// first error in it, if any.
+func decodeDeleteACLsResponse(response *protocol.Decoder) error {
+	// Throttle time
+	response.ReadInt32()
+
+	var err error
+
+	numResults := response.ReadInt32()
+	for i := 0; i < int(numResults) && response.Err() == nil; i++ {
+		resultErr := aclError(response.ReadInt16(), response.ReadString())
+		if err == nil {
+			err = resultErr
+		}
+
+		numMatches := response.ReadInt32()
+		for j := 0; j < int(numMatches) && response.Err() == nil; j++ {
+			matchErr := aclError(response.ReadInt16(), response.ReadString())
+			if err == nil {
+				err = matchErr
+			}
+			decodeACL(response)
+		}
+	}
+
+	if decodeErr := response.Err(); decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+func aclError(errorCode int16, message string) error {
+	switch errorCode {
+	case 0:
+		return nil
+	case aclSecurityDisabledError:
+		return errors.New("Cannot manage ACLs because the cluster doesn't have an authorizer configured")
+	}
+
+	if message != "" {
+		return fmt.Errorf("ACL request failed: %+v (%s)", kafka.Error(errorCode), message)
+	}
+	return fmt.Errorf("ACL request failed: %+v", kafka.Error(errorCode))
+}
@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeACLsRequests(t *testing.T) {
+	acl := denyTopicWritesACL("test-topic")
+
+	expectedACL := &protocol.Encoder{}
+	expectedACL.WriteInt8(2)
+	expectedACL.WriteString("test-topic")
+	expectedACL.WriteInt8(3)
+	expectedACL.WriteString("User:*")
+	expectedACL.WriteString("*")
+	expectedACL.WriteInt8(4)
+	expectedACL.WriteInt8(2)
+
+	assert.Equal(t, expectedACL.Bytes(), encodeDescribeACLsRequest(acl).Bytes())
+
+	expectedArray := append([]byte{0, 0, 0, 1}, expectedACL.Bytes()...)
+	assert.Equal(t, expectedArray, encodeCreateACLsRequest(acl).Bytes())
+	assert.Equal(t, expectedArray, encodeDeleteACLsRequest(acl).Bytes())
+}
+
+func TestDecodeDescribeACLsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt16(0)
+	response.WriteNullString()
+	response.WriteInt32(1)
+	response.WriteInt8(2)
+	response.WriteString("test-topic")
+	response.WriteInt8(3)
+	response.WriteInt32(1)
+	response.WriteString("User:*")
+	response.WriteString("*")
+	response.WriteInt8(4)
+	response.WriteInt8(2)
+
+	acls, err := decodeDescribeACLsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, []aclBinding{denyTopicWritesACL("test-topic")}, acls)
+
+	_, err = decodeDescribeACLsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes()[:20])),
+	)
+	assert.NotNil(t, err)
+
+	disabled := &protocol.Encoder{}
+	disabled.WriteInt32(0)
+	disabled.WriteInt16(54)
+	disabled.WriteString("No Authorizer is configured on the broker")
+	disabled.WriteInt32(0)
+
+	_, err = decodeDescribeACLsResponse(
+		protocol.NewDecoder(bytes.NewReader(disabled.Bytes())),
+	)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeDeleteACLsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt32(1)
+	response.WriteInt16(0)
+	response.WriteNullString()
+	response.WriteInt32(1)
+	response.WriteInt16(0)
+	response.WriteNullString()
+	encodeACL(response, denyTopicWritesACL("test-topic"))
+
+	err := decodeDeleteACLsResponse(protocol.NewDecoder(bytes.NewReader(response.Bytes())))
+	assert.Nil(t, err)
+
+	failed := &protocol.Encoder{}
+	failed.WriteInt32(0)
+	failed.WriteInt32(1)
+	failed.WriteInt16(31)
+	failed.WriteString("Not authorized")
+	failed.WriteInt32(0)
+
+	err = decodeDeleteACLsResponse(protocol.NewDecoder(bytes.NewReader(failed.Bytes())))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// appliedRefPath is the zk path, relative to the cluster prefix, that stores the git ref
+// that was most recently applied to the cluster. Like the maintenance node, it's only used
+// by topicctl.
+const appliedRefPath = "/topicctl/applied-ref"
+
+// AppliedRef stores the details of a git ref that was applied to a cluster.
+type AppliedRef struct {
+	RepoURL string    `json:"repoURL"`
+	Ref     string    `json:"ref"`
+	SHA     string    `json:"sha"`
+	Path    string    `json:"path"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	Time    time.Time `json:"time"`
+}
+
+// NewAppliedRef returns an AppliedRef for the argument git details, with the user, host,
+// and time filled in from the current environment.
+func NewAppliedRef(repoURL string, ref string, sha string, path string) AppliedRef {
+	appliedRef := AppliedRef{
+		RepoURL: repoURL,
+		Ref:     ref,
+		SHA:     sha,
+		Path:    path,
+		User:    "unknown",
+		Host:    "unknown",
+		Time:    time.Now().UTC(),
+	}
+
+	if currUser, err := user.Current(); err == nil {
+		appliedRef.User = currUser.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		appliedRef.Host = host
+	}
+
+	return appliedRef
+}
+
+// GetAppliedRef returns the git ref that was most recently applied to the cluster, or nil
+// if no ref has been applied.
+func (c *Client) GetAppliedRef(ctx context.Context) (*AppliedRef, error) {
+	zPath := c.zNode(appliedRefPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	appliedRef := &AppliedRef{}
+	if _, err := c.zkClient.GetJSON(ctx, zPath, appliedRef); err != nil {
+		return nil, err
+	}
+
+	return appliedRef, nil
+}
+
+// SetAppliedRef records the argument ref as the one most recently applied to the cluster,
+// replacing any previous record.
+func (c *Client) SetAppliedRef(ctx context.Context, appliedRef AppliedRef) error {
+	if c.readOnly {
+		return errors.New("Cannot set applied ref in read-only mode")
+	}
+
+	zPath := c.zNode(appliedRefPath)
+
+	exists, stats, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		log.Debugf("Updating applied ref at %s: %+v", zPath, appliedRef)
+		_, err := c.zkClient.SetJSON(ctx, zPath, appliedRef, stats.Version)
+		return err
+	}
+
+	// Parent might not already exist
+	zRoot := filepath.Dir(zPath)
+
+	exists, _, err = c.zkClient.Exists(ctx, zRoot)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Infof("Creating topicctl path: %s", zRoot)
+		if err := c.zkClient.Create(ctx, zRoot, nil, false); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Creating applied ref at %s: %+v", zPath, appliedRef)
+	return c.zkClient.CreateJSON(ctx, zPath, appliedRef, false)
+}
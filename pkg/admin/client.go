@@ -8,12 +8,15 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/ratelimit"
 	"github.com/segmentio/topicctl/pkg/util"
 	"github.com/segmentio/topicctl/pkg/zk"
 	log "github.com/sirupsen/logrus"
@@ -26,29 +29,50 @@ const (
 	brokersPath       = "/brokers/ids"
 	topicsPath        = "/brokers/topics"
 	clusterIDPath     = "/cluster/id"
+	controllerPath    = "/controller"
 	brokerConfigsPath = "/config/brokers"
 	configChangesPath = "/config/changes/config_change_"
 	topicConfigsPath  = "/config/topics"
+	deleteTopicsPath  = "/admin/delete_topics"
 
-	// The maximum number of topics to fetch in parallel
+	// The maximum number of zk reads to run in parallel when fetching topics
 	maxPoolSize = 20
+
+	// DefaultQPS is the default maximum rate of zookeeper and broker API requests made by
+	// each client.
+	DefaultQPS = 500.0
+
+	// Settings for retrying broker connections
+	maxDialRetries   = 3
+	dialRetryBackoff = 200 * time.Millisecond
 )
 
 var (
 	// ErrTopicDoesNotExist is returned by admin functions when a topic that should exist
 	// does not.
 	ErrTopicDoesNotExist = errors.New("Topic does not exist")
+
+	// qpsOverride, if non-zero, replaces the QPS values in client configs
+	qpsOverride float64
 )
 
 // Client is a general client for interacting with a kafka cluster. Most
 // interactions are done via zookeeper, but a few (e.g., creating topics or
-// getting the controller address) are done via the broker API instead.
+// getting the controller address) are done via the broker API instead. Operations
+// that depend on the broker version are checked against the APIs that the brokers
+// support; see CheckFeature.
 type Client struct {
 	zkClient       zk.Client
 	zkPrefix       string
 	bootstrapAddrs []string
 	sess           *session.Session
 	readOnly       bool
+	limiter        *ratelimit.Limiter
+	retryConfig    RetryConfig
+
+	// apiVersions caches the API versions supported by the brokers; see CheckFeature
+	apiVersionsMutex sync.Mutex
+	apiVersions      []BrokerAPIVersions
 }
 
 // ClientConfig contains all of the parameters necessary to create a kafka admin client.
@@ -59,6 +83,26 @@ type ClientConfig struct {
 	ExpectedClusterID string
 	Sess              *session.Session
 	ReadOnly          bool
+
+	// ZKOptions contains the zk connection and retry settings; zero values are replaced
+	// with defaults.
+	ZKOptions zk.ClientOptions
+
+	// QPS is the maximum rate of requests, summed across zookeeper and the broker API, that
+	// the client sends. If unset, DefaultQPS is used; set it to a negative value to disable
+	// rate limiting.
+	QPS float64
+
+	// BrokerRetries contains the settings for retrying broker API requests after transient
+	// errors; zero values are replaced with defaults.
+	BrokerRetries RetryConfig
+}
+
+// SetQPSOverride replaces the QPS in the configs of all clients created after this call,
+// e.g. to apply a command-line override on top of the cluster config. Setting it to 0 clears
+// the override.
+func SetQPSOverride(qps float64) {
+	qpsOverride = qps
 }
 
 // NewClient creates and returns a new Client instance.
@@ -66,15 +110,28 @@ func NewClient(
 	ctx context.Context,
 	config ClientConfig,
 ) (*Client, error) {
+	qps := config.QPS
+	if qpsOverride != 0 {
+		qps = qpsOverride
+	}
+	if qps == 0 {
+		qps = DefaultQPS
+	}
+	limiter := ratelimit.NewLimiter(qps, 0)
+	log.Debugf("Limiting admin requests to %f per second", limiter.QPS())
+
+	zkOptions := config.ZKOptions
+	zkOptions.Limiter = limiter
+
 	zkClient, err := zk.NewPooledClient(
 		config.ZKAddrs,
-		time.Minute,
+		zkOptions,
 		&zk.ZKDebugLogger{},
 		10,
 		config.ReadOnly,
 	)
 	if err != nil {
-		return nil, err
+		return nil, exitcode.Wrap(exitcode.KindUnreachable, err)
 	}
 
 	zkPrefix := config.ZKPrefix
@@ -90,23 +147,28 @@ func NewClient(
 	}
 
 	client := &Client{
-		zkClient: zkClient,
-		zkPrefix: zkPrefix,
-		sess:     config.Sess,
-		readOnly: config.ReadOnly,
+		zkClient:    zkClient,
+		zkPrefix:    zkPrefix,
+		sess:        config.Sess,
+		readOnly:    zkClient.ReadOnly(),
+		limiter:     limiter,
+		retryConfig: config.BrokerRetries.withDefaults(),
 	}
 
 	if config.ExpectedClusterID != "" {
 		log.Info("Checking cluster ID against version in cluster")
 		clusterID, err := client.GetClusterID(ctx)
 		if err != nil {
-			return nil, err
+			return nil, exitcode.Wrap(exitcode.KindUnreachable, err)
 		}
 		if clusterID != config.ExpectedClusterID {
-			return nil, fmt.Errorf(
-				"ID in cluster (%s) does not match expected one (%s)",
-				clusterID,
-				config.ExpectedClusterID,
+			return nil, exitcode.Wrap(
+				exitcode.KindValidation,
+				fmt.Errorf(
+					"ID in cluster (%s) does not match expected one (%s)",
+					clusterID,
+					config.ExpectedClusterID,
+				),
 			)
 		}
 	}
@@ -117,12 +179,12 @@ func NewClient(
 		log.Debug("No bootstrap addresses provided, getting one from zookeeper")
 		ids, err := client.GetBrokerIDs(ctx)
 		if err != nil {
-			return nil, err
+			return nil, exitcode.Wrap(exitcode.KindUnreachable, err)
 		}
 		// Just use the first ID
 		brokers, err := client.GetBrokers(ctx, []int{ids[0]})
 		if err != nil {
-			return nil, err
+			return nil, exitcode.Wrap(exitcode.KindUnreachable, err)
 		}
 		bootstrapAddrs = []string{brokers[0].Addr()}
 	} else {
@@ -259,6 +321,32 @@ func (c *Client) GetBrokers(
 	return brokers, nil
 }
 
+// GetClusterDefaultConfig returns the dynamic, cluster-wide broker config defaults that
+// are stored in zookeeper. These don't include static configs set on the brokers
+// themselves.
+func (c *Client) GetClusterDefaultConfig(ctx context.Context) (map[string]string, error) {
+	zPath := c.zNode(brokerConfigsPath, "<default>")
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return map[string]string{}, nil
+	}
+
+	zkBrokerConfig := zkBrokerConfig{}
+	_, err = c.zkClient.GetJSON(ctx, zPath, &zkBrokerConfig)
+	if err != nil {
+		return nil, err
+	}
+	if zkBrokerConfig.Config == nil {
+		return map[string]string{}, nil
+	}
+
+	return zkBrokerConfig.Config, nil
+}
+
 // GetBrokerIDs returns a slice of all broker IDs.
 func (c *Client) GetBrokerIDs(ctx context.Context) ([]int, error) {
 	zPath := c.zNode(brokersPath)
@@ -299,6 +387,28 @@ func (c *Client) GetTopics(
 	ctx context.Context,
 	names []string,
 	detailed bool,
+) ([]TopicInfo, error) {
+	return c.getTopicsSorted(ctx, names, detailed, false)
+}
+
+// ListTopics is like GetTopics, but fetches the topic configs in batches from the brokers
+// if the cluster supports it, which is much faster in large clusters. The brokers apply
+// config changes asynchronously after they're written to zookeeper, so the configs can
+// briefly lag behind recent updates; this should only be used for read-only listings,
+// not for reading back changes.
+func (c *Client) ListTopics(
+	ctx context.Context,
+	names []string,
+	detailed bool,
+) ([]TopicInfo, error) {
+	return c.getTopicsSorted(ctx, names, detailed, true)
+}
+
+func (c *Client) getTopicsSorted(
+	ctx context.Context,
+	names []string,
+	detailed bool,
+	batchConfigs bool,
 ) ([]TopicInfo, error) {
 	var topicNames []string
 	var err error
@@ -318,55 +428,9 @@ func (c *Client) GetTopics(
 		topicNames,
 	)
 
-	topics := []TopicInfo{}
-
-	type topicReq struct {
-		name     string
-		detailed bool
-	}
-
-	type topicResp struct {
-		topic TopicInfo
-		err   error
-	}
-
-	// This operation can be slow if there are a lot of topics, distribute it out
-	topicReqChan := make(chan topicReq, len(topicNames))
-	topicRespChan := make(chan topicResp, len(topicNames))
-	defer close(topicReqChan)
-
-	for _, name := range topicNames {
-		topicReqChan <- topicReq{
-			name:     name,
-			detailed: detailed,
-		}
-	}
-
-	poolSize := minInt(len(topicNames), maxPoolSize)
-
-	for i := 0; i < poolSize; i++ {
-		go func() {
-			for {
-				topicReq, ok := <-topicReqChan
-				if !ok {
-					return
-				}
-				topic, err := c.getTopic(ctx, topicReq.name, topicReq.detailed)
-
-				topicRespChan <- topicResp{
-					topic: topic,
-					err:   err,
-				}
-			}
-		}()
-	}
-
-	for i := 0; i < len(topicNames); i++ {
-		topicResp := <-topicRespChan
-		if topicResp.err != nil {
-			return nil, err
-		}
-		topics = append(topics, topicResp.topic)
+	topics, err := c.getTopics(ctx, topicNames, detailed, batchConfigs)
+	if err != nil {
+		return nil, err
 	}
 
 	sort.Slice(topics, func(i, j int) bool {
@@ -440,13 +504,7 @@ func (c *Client) GetBrokerPartitions(
 		}
 	}
 
-	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", c.bootstrapAddrs[0])
-	if err != nil {
-		return nil, err
-	}
-	defer conn.Close()
-
-	zkPartitions, err := conn.ReadPartitions(topicNames...)
+	zkPartitions, err := c.readPartitions(ctx, topicNames...)
 	if err != nil {
 		return nil, err
 	}
@@ -481,7 +539,8 @@ func (c *Client) GetBrokerPartitions(
 
 // UpdateTopicConfig updates the config JSON for a topic and sets a change
 // notification so that the brokers are notified. If overwrite is true, then
-// it will overwrite existing config entries.
+// it will overwrite existing config entries. The previous values of the updated
+// keys are recorded so that the change can be reverted with RevertLastConfigChange.
 //
 // The function returns the list of keys that were modified. If overwrite is
 // set to false, this can be used to determine the subset of entries
@@ -509,6 +568,7 @@ func (c *Client) UpdateTopicConfig(
 	if err != nil {
 		return updatedKeys, err
 	}
+	prevValues := configValues(configMap)
 
 	updatedKeys, err = updateConfig(configMap, configEntries, overwrite)
 	if err != nil {
@@ -525,8 +585,20 @@ func (c *Client) UpdateTopicConfig(
 		EntityPath: fmt.Sprintf("topics/%s", name),
 	}
 	log.Debugf("Setting change notification: %+v", changeObj)
-	return updatedKeys,
-		c.zkClient.CreateJSON(ctx, c.zNode(configChangesPath), changeObj, true)
+	err = c.zkClient.CreateJSON(ctx, c.zNode(configChangesPath), changeObj, true)
+	if err != nil {
+		return updatedKeys, err
+	}
+
+	c.recordConfigChange(
+		ctx,
+		ConfigEntityTopic,
+		name,
+		prevValues,
+		configValues(configMap),
+		updatedKeys,
+	)
+	return updatedKeys, nil
 }
 
 // UpdateBrokerConfig updates the config JSON for a cluster broker and
@@ -534,51 +606,84 @@ func (c *Client) UpdateTopicConfig(
 // true, then it will overwrite existing config entries.
 //
 // The function returns the list of keys that were modified. If overwrite is
-// set to false, this can be used to determine the subset of entries
+// set to false, this can be used to determine the subset of entries. As with
+// UpdateTopicConfig, the previous values of the updated keys are recorded.
 func (c *Client) UpdateBrokerConfig(
 	ctx context.Context,
 	id int,
 	configEntries []kafka.ConfigEntry,
 	overwrite bool,
 ) ([]string, error) {
-	var updatedKeys []string
+	return c.updateBrokerConfigNode(ctx, fmt.Sprintf("%d", id), configEntries, overwrite)
+}
+
+// UpdateClusterDefaultConfig updates the dynamic, cluster-wide broker config defaults and
+// sets a change notification so the cluster brokers are notified. The overwrite
+// flag and return values have the same semantics as in UpdateBrokerConfig.
+func (c *Client) UpdateClusterDefaultConfig(
+	ctx context.Context,
+	configEntries []kafka.ConfigEntry,
+	overwrite bool,
+) ([]string, error) {
+	return c.updateBrokerConfigNode(ctx, "<default>", configEntries, overwrite)
+}
+
+func (c *Client) updateBrokerConfigNode(
+	ctx context.Context,
+	idStr string,
+	configEntries []kafka.ConfigEntry,
+	overwrite bool,
+) ([]string, error) {
 	if c.readOnly {
-		return updatedKeys, errors.New("Cannot update topic in read-only mode")
+		return nil, errors.New("Cannot update broker config in read-only mode")
 	}
-	log.Debugf("Updating config for broker %d", id)
+	log.Debugf("Updating config for broker %s", idStr)
 
-	idStr := fmt.Sprintf("%d", id)
+	return c.updateEntityConfig(ctx, "brokers", idStr, configEntries, overwrite)
+}
 
-	// Broker configs parent might not already exist
-	zBrokerRoot := c.zNode(brokerConfigsPath)
+// updateEntityConfig updates the zookeeper config node for the argument entity
+// (e.g., a broker or client ID), creating it if needed, and then sets a change
+// notification so that the brokers pick up the change.
+func (c *Client) updateEntityConfig(
+	ctx context.Context,
+	entityType string,
+	entityName string,
+	configEntries []kafka.ConfigEntry,
+	overwrite bool,
+) ([]string, error) {
+	var updatedKeys []string
+
+	// Entity configs parent might not already exist
+	zEntityRoot := c.zNode("config", entityType)
 
-	exists, _, err := c.zkClient.Exists(ctx, zBrokerRoot)
+	exists, _, err := c.zkClient.Exists(ctx, zEntityRoot)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		log.Infof("Creating broker configs path: %s", zBrokerRoot)
-		err := c.zkClient.Create(ctx, zBrokerRoot, nil, false)
+		log.Infof("Creating %s configs path: %s", entityType, zEntityRoot)
+		err := c.zkClient.Create(ctx, zEntityRoot, nil, false)
 		if err != nil {
 			return updatedKeys, err
 		}
 	}
 
-	// Broker config might not exist
-	zBrokerPath := c.zNode(brokerConfigsPath, idStr)
+	// Entity config might not exist
+	zPath := c.zNode("config", entityType, entityName)
 
-	exists, _, err = c.zkClient.Exists(ctx, zBrokerPath)
+	exists, _, err = c.zkClient.Exists(ctx, zPath)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		zkBrokerConfigObj := zkBrokerConfig{
+		zkEntityConfigObj := zkBrokerConfig{
 			Version: 1,
 			Config:  map[string]string{},
 		}
 
-		log.Infof("Creating broker config at %s: %+v", zBrokerPath, zkBrokerConfigObj)
-		err := c.zkClient.CreateJSON(ctx, zBrokerPath, zkBrokerConfigObj, false)
+		log.Infof("Creating %s config at %s: %+v", entityType, zPath, zkEntityConfigObj)
+		err := c.zkClient.CreateJSON(ctx, zPath, zkEntityConfigObj, false)
 		if err != nil {
 			return updatedKeys, err
 		}
@@ -586,8 +691,6 @@ func (c *Client) UpdateBrokerConfig(
 
 	configMap := map[string]interface{}{}
 
-	zPath := c.zNode(brokerConfigsPath, idStr)
-
 	stats, err := c.zkClient.GetJSON(
 		ctx,
 		zPath,
@@ -596,6 +699,7 @@ func (c *Client) UpdateBrokerConfig(
 	if err != nil {
 		return updatedKeys, err
 	}
+	prevValues := configValues(configMap)
 
 	updatedKeys, err = updateConfig(configMap, configEntries, overwrite)
 	if err != nil {
@@ -609,11 +713,26 @@ func (c *Client) UpdateBrokerConfig(
 
 	changeObj := zkChangeNotification{
 		Version:    2,
-		EntityPath: fmt.Sprintf("brokers/%s", idStr),
+		EntityPath: fmt.Sprintf("%s/%s", entityType, entityName),
 	}
 	log.Debugf("Setting change notification: %+v", changeObj)
-	return updatedKeys,
-		c.zkClient.CreateJSON(ctx, c.zNode(configChangesPath), changeObj, true)
+	err = c.zkClient.CreateJSON(ctx, c.zNode(configChangesPath), changeObj, true)
+	if err != nil {
+		return updatedKeys, err
+	}
+
+	// User configs can contain SCRAM credentials, which shouldn't be copied anywhere else
+	if entityType != "users" {
+		c.recordConfigChange(
+			ctx,
+			entityType,
+			entityName,
+			prevValues,
+			configValues(configMap),
+			updatedKeys,
+		)
+	}
+	return updatedKeys, nil
 }
 
 // GetControllerAddr gets the address of the cluster controller. This is needed
@@ -621,22 +740,20 @@ func (c *Client) UpdateBrokerConfig(
 func (c *Client) GetControllerAddr(
 	ctx context.Context,
 ) (string, error) {
-	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", c.bootstrapAddrs[0])
-	if err != nil {
-		return "", err
-	}
-	defer conn.Close()
+	var controllerAddr string
 
-	broker, err := conn.Controller()
-	if err != nil {
-		return "", err
-	}
+	err := c.withBrokerRetries(ctx, "get controller", false, func() error {
+		var err error
+		controllerAddr, err = c.getControllerAddr(ctx)
+		return err
+	})
 
-	return fmt.Sprintf("%s:%d", broker.Host, broker.Port), nil
+	return controllerAddr, err
 }
 
 // CreateTopic creates a new topic with the argument config. It uses
-// the topic creation API exposed on the controller broker.
+// the topic creation API exposed on the controller broker if all brokers
+// support it, and writes the topic directly to zookeeper otherwise.
 func (c *Client) CreateTopic(
 	ctx context.Context,
 	config kafka.TopicConfig,
@@ -645,20 +762,178 @@ func (c *Client) CreateTopic(
 		return errors.New("Cannot create topic in read-only mode")
 	}
 
-	controllerAddr, err := c.GetControllerAddr(ctx)
+	log.Debugf("Creating topic with config %+v", config)
+
+	supported, err := c.SupportsFeature(ctx, FeatureCreateTopics)
 	if err != nil {
 		return err
 	}
+	if !supported {
+		log.Infof(
+			"Not all brokers support the CreateTopics API; creating topic %s via zookeeper",
+			config.Topic,
+		)
+		return c.createTopicZK(ctx, config)
+	}
+
+	// The controller is looked up on each attempt so that the request follows it if it moves
+	return c.withBrokerRetries(ctx, "create topic", true, func() error {
+		controllerAddr, err := c.getControllerAddr(ctx)
+		if err != nil {
+			return err
+		}
+
+		conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", controllerAddr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		return conn.CreateTopics(config)
+	})
+}
+
+// DeleteTopic deletes the argument topic, along with all of its data. Like CreateTopic,
+// this falls back to zookeeper if not all brokers support the topic deletion API.
+func (c *Client) DeleteTopic(ctx context.Context, topic string) error {
+	if c.readOnly {
+		return errors.New("Cannot delete topic in read-only mode")
+	}
+
+	log.Debugf("Deleting topic %s", topic)
 
-	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", controllerAddr)
+	supported, err := c.SupportsFeature(ctx, FeatureDeleteTopics)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	if !supported {
+		log.Infof(
+			"Not all brokers support the DeleteTopics API; deleting topic %s via zookeeper",
+			topic,
+		)
+		zPath := c.zNode(deleteTopicsPath, topic)
+		log.Debugf("Creating topic deletion node at %s", zPath)
+		return c.zkClient.Create(ctx, zPath, nil, false)
+	}
 
-	log.Debugf("Creating topic with config %+v", config)
+	return c.withBrokerRetries(ctx, "delete topic", true, func() error {
+		controllerAddr, err := c.getControllerAddr(ctx)
+		if err != nil {
+			return err
+		}
+
+		conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", controllerAddr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
 
-	return conn.CreateTopics(config)
+		return conn.DeleteTopics(topic)
+	})
+}
+
+// createTopicZK creates a topic by writing its config and partition assignments to
+// zookeeper, which is how topics were created before the CreateTopics API was added. The
+// replicas are assigned round-robin across the brokers, without considering racks; apply
+// fixes up the placement afterwards.
+func (c *Client) createTopicZK(ctx context.Context, config kafka.TopicConfig) error {
+	if len(config.ReplicaAssignments) > 0 {
+		return errors.New("Explicit replica assignments aren't supported when creating topics via zookeeper")
+	}
+
+	exists, _, err := c.zkClient.Exists(ctx, c.zNode(topicsPath, config.Topic))
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("Topic %s already exists", config.Topic)
+	}
+
+	brokerIDs, err := c.GetBrokerIDs(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Ints(brokerIDs)
+
+	assignments, err := roundRobinAssignments(
+		brokerIDs,
+		config.NumPartitions,
+		config.ReplicationFactor,
+	)
+	if err != nil {
+		return err
+	}
+
+	topicConfigObj := zkTopicConfig{
+		Version: 1,
+		Config:  map[string]string{},
+	}
+	for _, entry := range config.ConfigEntries {
+		topicConfigObj.Config[entry.ConfigName] = entry.ConfigValue
+	}
+
+	// The config needs to exist before the topic node so that the brokers pick it up
+	// when they create the partitions
+	err = c.zkClient.CreateJSON(
+		ctx,
+		c.zNode(topicConfigsPath, config.Topic),
+		topicConfigObj,
+		false,
+	)
+	if err != nil {
+		return err
+	}
+
+	topicObj := zkTopicInfo{
+		Version:    1,
+		Partitions: map[string][]int{},
+	}
+	for _, assignment := range assignments {
+		topicObj.Partitions[fmt.Sprintf("%d", assignment.ID)] = assignment.Replicas
+	}
+
+	return c.zkClient.CreateJSON(
+		ctx,
+		c.zNode(topicsPath, config.Topic),
+		topicObj,
+		false,
+	)
+}
+
+// roundRobinAssignments assigns the replicas of each partition to consecutive brokers,
+// starting with a different broker for each partition.
+func roundRobinAssignments(
+	brokerIDs []int,
+	numPartitions int,
+	replicationFactor int,
+) ([]PartitionAssignment, error) {
+	if numPartitions <= 0 || replicationFactor <= 0 {
+		return nil, errors.New("Partition count and replication factor must be set")
+	}
+	if replicationFactor > len(brokerIDs) {
+		return nil, fmt.Errorf(
+			"Replication factor (%d) is larger than the number of brokers (%d)",
+			replicationFactor,
+			len(brokerIDs),
+		)
+	}
+
+	assignments := []PartitionAssignment{}
+	for p := 0; p < numPartitions; p++ {
+		replicas := []int{}
+		for r := 0; r < replicationFactor; r++ {
+			replicas = append(replicas, brokerIDs[(p+r)%len(brokerIDs)])
+		}
+		assignments = append(
+			assignments,
+			PartitionAssignment{
+				ID:       p,
+				Replicas: replicas,
+			},
+		)
+	}
+
+	return assignments, nil
 }
 
 // AssignmentInProgress returns whether the zk assignment node exists.
@@ -852,6 +1127,13 @@ func (c *Client) LockHeld(
 	return len(children) > 0, nil
 }
 
+// EnableCache makes the client cache the results of zookeeper reads for the argument
+// amount of time. This speeds up repeated lookups, e.g. in a repl session, at the cost of
+// possibly returning stale metadata. Writes through the client clear the cache.
+func (c *Client) EnableCache(ttl time.Duration) {
+	c.zkClient = zk.NewCachedClient(c.zkClient, ttl)
+}
+
 // Close closes the connections in the underlying zookeeper client.
 func (c *Client) Close() error {
 	return c.zkClient.Close()
@@ -861,6 +1143,80 @@ func (c *Client) getTopic(
 	ctx context.Context,
 	name string,
 	detailed bool,
+) (TopicInfo, error) {
+	topics, err := c.getTopics(ctx, []string{name}, detailed, false)
+	if err != nil {
+		return TopicInfo{}, err
+	}
+	return topics[0], nil
+}
+
+// getTopics gets the info for the argument topics from zookeeper, in the same order as the
+// names. This can be slow if there are a lot of topics, so the reads are done in parallel. To
+// keep the number of concurrent reads bounded, the partition states for all topics are
+// fetched from a single pool after the topic-level nodes are read. If batchConfigs is set,
+// the topic configs are fetched in batches from the brokers if possible; see getTopicConfigs.
+func (c *Client) getTopics(
+	ctx context.Context,
+	names []string,
+	detailed bool,
+	batchConfigs bool,
+) ([]TopicInfo, error) {
+	topics := make([]TopicInfo, len(names))
+
+	err := util.RunParallel(ctx, len(names), maxPoolSize, func(ctx context.Context, i int) error {
+		var err error
+		topics[i], err = c.getTopicPartitions(ctx, names[i])
+		return err
+	})
+	if err != nil {
+		return topics, err
+	}
+
+	configs, err := c.getTopicConfigs(ctx, names, batchConfigs)
+	if err != nil {
+		return topics, err
+	}
+	for t := range topics {
+		topics[t].Config = configs[topics[t].Name]
+	}
+
+	if !detailed {
+		return topics, nil
+	}
+
+	type partitionRef struct {
+		topicIndex     int
+		partitionIndex int
+	}
+	partitionRefs := []partitionRef{}
+
+	for t, topic := range topics {
+		for p := range topic.Partitions {
+			partitionRefs = append(partitionRefs, partitionRef{t, p})
+		}
+	}
+
+	err = util.RunParallel(
+		ctx,
+		len(partitionRefs),
+		maxPoolSize,
+		func(ctx context.Context, i int) error {
+			ref := partitionRefs[i]
+			return c.getPartitionState(
+				ctx,
+				&topics[ref.topicIndex].Partitions[ref.partitionIndex],
+			)
+		},
+	)
+	return topics, err
+}
+
+// getTopicPartitions gets the info for a single topic, including its partition replicas, but
+// not its config or the partition states.
+func (c *Client) getTopicPartitions(
+	ctx context.Context,
+	name string,
 ) (TopicInfo, error) {
 	log.Debugf("Getting info for topic %s", name)
 
@@ -870,7 +1226,7 @@ func (c *Client) getTopic(
 	}
 	zkTopicInfo := zkTopicInfo{}
 
-	_, err := c.zkClient.GetJSON(
+	stats, err := c.zkClient.GetJSON(
 		ctx,
 		c.zNode(topicsPath, name),
 		&zkTopicInfo,
@@ -880,80 +1236,24 @@ func (c *Client) getTopic(
 	}
 
 	topicInfo.Version = zkTopicInfo.Version
-
-	zkTopicConfig := zkTopicConfig{}
-	_, err = c.zkClient.GetJSON(
-		ctx,
-		c.zNode(topicConfigsPath, name),
-		&zkTopicConfig,
-	)
-	if err != nil {
-		return topicInfo, err
-	}
-
-	topicInfo.Config = zkTopicConfig.Config
-
-	type partitionReq struct {
-		id       int
-		replicas []int
-		detailed bool
-	}
-
-	type partitionResp struct {
-		partition PartitionInfo
-		err       error
+	topicInfo.TopicID = zkTopicInfo.TopicID
+	if stats != nil {
+		topicInfo.CreatedAt = time.Unix(0, stats.Ctime*int64(time.Millisecond)).UTC()
 	}
 
-	// This operation can be slow if there are a lot of partitions, distribute it out
-	partitionReqChan := make(chan partitionReq, len(zkTopicInfo.Partitions))
-	partitionRespChan := make(chan partitionResp, len(zkTopicInfo.Partitions))
-
 	for partitionIDStr, replicas := range zkTopicInfo.Partitions {
 		partitionID, err := strconv.ParseInt(partitionIDStr, 10, 32)
 		if err != nil {
 			return topicInfo, err
 		}
 
-		partitionReqChan <- partitionReq{
-			id:       int(partitionID),
-			replicas: replicas,
-			detailed: detailed,
-		}
-	}
-
-	poolSize := minInt(len(zkTopicInfo.Partitions), maxPoolSize)
-
-	for i := 0; i < poolSize; i++ {
-		go func() {
-			for {
-				partitionReq, ok := <-partitionReqChan
-				if !ok {
-					return
-				}
-				partition, err := c.getPartition(
-					ctx,
-					partitionReq.id,
-					name,
-					partitionReq.detailed,
-					partitionReq.replicas,
-				)
-
-				partitionRespChan <- partitionResp{
-					partition: partition,
-					err:       err,
-				}
-			}
-		}()
-	}
-
-	for i := 0; i < len(zkTopicInfo.Partitions); i++ {
-		partitionResp := <-partitionRespChan
-		if partitionResp.err != nil {
-			return topicInfo, err
-		}
 		topicInfo.Partitions = append(
 			topicInfo.Partitions,
-			partitionResp.partition,
+			PartitionInfo{
+				Topic:    name,
+				ID:       int(partitionID),
+				Replicas: replicas,
+			},
 		)
 	}
 
@@ -964,45 +1264,35 @@ func (c *Client) getTopic(
 	return topicInfo, nil
 }
 
-func (c *Client) getPartition(
+// getPartitionState fills in the leader, ISR, and epochs of the argument partition from its
+// state node in zookeeper.
+func (c *Client) getPartitionState(
 	ctx context.Context,
-	id int,
-	topicName string,
-	detailed bool,
-	replicas []int,
-) (PartitionInfo, error) {
-	partitionInfo := PartitionInfo{
-		Topic:    topicName,
-		ID:       int(id),
-		Replicas: replicas,
+	partitionInfo *PartitionInfo,
+) error {
+	zkPartitionInfo := zkPartitionInfo{}
+	_, err := c.zkClient.GetJSON(
+		ctx,
+		c.zNode(
+			topicsPath,
+			partitionInfo.Topic,
+			"partitions",
+			fmt.Sprintf("%d", partitionInfo.ID),
+			"state",
+		),
+		&zkPartitionInfo,
+	)
+	if err != nil {
+		return err
 	}
 
-	if detailed {
-		zkPartitionInfo := zkPartitionInfo{}
-		_, err := c.zkClient.GetJSON(
-			ctx,
-			c.zNode(
-				topicsPath,
-				topicName,
-				"partitions",
-				fmt.Sprintf("%d", id),
-				"state",
-			),
-			&zkPartitionInfo,
-		)
-		if err != nil {
-			return partitionInfo, err
-		}
-
-		partitionInfo.ControllerEpoch = zkPartitionInfo.ControllerEpoch
-		partitionInfo.ISR = zkPartitionInfo.ISR
-		partitionInfo.Leader = zkPartitionInfo.Leader
-		partitionInfo.ControllerEpoch = zkPartitionInfo.ControllerEpoch
-		partitionInfo.LeaderEpoch = zkPartitionInfo.LeaderEpoch
-		partitionInfo.Version = zkPartitionInfo.Version
-	}
+	partitionInfo.ControllerEpoch = zkPartitionInfo.ControllerEpoch
+	partitionInfo.ISR = zkPartitionInfo.ISR
+	partitionInfo.Leader = zkPartitionInfo.Leader
+	partitionInfo.LeaderEpoch = zkPartitionInfo.LeaderEpoch
+	partitionInfo.Version = zkPartitionInfo.Version
 
-	return partitionInfo, nil
+	return nil
 }
 
 func (c *Client) zNode(elements ...string) string {
@@ -1093,7 +1383,7 @@ func updateConfig(
 			log.Warnf(
 				"Skipping over config key %s because already is set to %s and overwrite is false",
 				entry.ConfigName,
-				currValue,
+				redactConfigValue(entry.ConfigName, currValue),
 			)
 			continue
 		}
@@ -1102,16 +1392,16 @@ func updateConfig(
 			log.Debugf(
 				"Removing config value for key %s: '%v'->'%s'",
 				entry.ConfigName,
-				currValue,
+				redactConfigValue(entry.ConfigName, currValue),
 				entry.ConfigValue,
 			)
 			delete(configKVMap, entry.ConfigName)
 		} else {
 			log.Debugf(
-				"Setting config value for key %s: '%v'->'%s'",
+				"Setting config value for key %s: '%v'->'%v'",
 				entry.ConfigName,
-				currValue,
-				entry.ConfigValue,
+				redactConfigValue(entry.ConfigName, currValue),
+				redactConfigValue(entry.ConfigName, entry.ConfigValue),
 			)
 			configKVMap[entry.ConfigName] = entry.ConfigValue
 		}
@@ -1122,3 +1412,13 @@ func updateConfig(
 	configMap["config"] = configKVMap
 	return updatedKeys, nil
 }
+
+// redactConfigValue returns the argument config value in a form that's safe to log. The
+// values of SCRAM credentials in user configs, which contain the salts and keys derived
+// from the users' passwords, are redacted.
+func redactConfigValue(key string, value interface{}) interface{} {
+	if value == nil || !strings.HasPrefix(key, "SCRAM-") {
+		return value
+	}
+	return "[redacted]"
+}
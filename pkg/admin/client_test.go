@@ -185,6 +185,154 @@ func TestGetBrokers(t *testing.T) {
 	)
 }
 
+func TestGetClusterInfo(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{util.TestZKAddr()},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	require.NotNil(t, zkConn)
+	defer zkConn.Close()
+
+	clusterName := testClusterID("cluster-info")
+	zk.CreateNodes(
+		t,
+		zkConn,
+		[]zk.PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/cluster", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/cluster/id", clusterName),
+				Obj: map[string]interface{}{
+					"version": "1",
+					"id":      "test-cluster-id",
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/controller", clusterName),
+				Obj: map[string]interface{}{
+					"version":   1,
+					"brokerid":  2,
+					"timestamp": "1589603217000",
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers/ids", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers/ids/1", clusterName),
+				Obj: map[string]interface{}{
+					"host":      "test1",
+					"port":      1234,
+					"rack":      "rack1",
+					"timestamp": "1589603217000",
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers/ids/2", clusterName),
+				Obj: map[string]interface{}{
+					"host":      "test2",
+					"port":      1234,
+					"rack":      "rack1",
+					"timestamp": "1589603217000",
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers/topics", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/brokers/topics/topic1", clusterName),
+				Obj: map[string]interface{}{
+					"version": 1,
+					"partitions": map[string]interface{}{
+						"0": []int{1, 2},
+						"1": []int{2, 1},
+					},
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/config", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/config/topics", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/config/topics/topic1", clusterName),
+				Obj: map[string]interface{}{
+					"version": 1,
+					"config":  map[string]string{},
+				},
+			},
+			{
+				Path: fmt.Sprintf("/%s/admin", clusterName),
+				Obj:  nil,
+			},
+			{
+				Path: fmt.Sprintf("/%s/admin/reassign_partitions", clusterName),
+				Obj: map[string]interface{}{
+					"version": 1,
+					"partitions": []map[string]interface{}{
+						{
+							"topic":     "topic1",
+							"partition": 1,
+							"replicas":  []int{1, 2},
+						},
+					},
+				},
+			},
+		},
+	)
+
+	ctx := context.Background()
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       clusterName,
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       true,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	clusterInfo, err := adminClient.GetClusterInfo(ctx)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		ClusterInfo{
+			ClusterID:      "test-cluster-id",
+			ControllerID:   2,
+			ControllerAddr: "test2:1234",
+			NumBrokers:     2,
+			NumRacks:       1,
+			NumTopics:      1,
+			NumPartitions:  2,
+			ReassigningPartitions: map[string]int{
+				"topic1": 1,
+			},
+			ElectingPartitions: map[string]int{},
+		},
+		clusterInfo,
+	)
+	assert.True(t, clusterInfo.AssignmentInProgress())
+	assert.False(t, clusterInfo.ElectionInProgress())
+}
+
 func TestGetTopics(t *testing.T) {
 	zkConn, _, err := szk.Connect(
 		[]string{util.TestZKAddr()},
@@ -256,7 +404,8 @@ func TestGetTopics(t *testing.T) {
 			{
 				Path: fmt.Sprintf("/%s/brokers/topics/topic2", clusterName),
 				Obj: map[string]interface{}{
-					"version": 1,
+					"version":  2,
+					"topic_id": "test-topic-id",
 					"partitions": map[string][]int{
 						"0": {2},
 					},
@@ -325,6 +474,12 @@ func TestGetTopics(t *testing.T) {
 	topics, err := adminClient.GetTopics(ctx, nil, true)
 	assert.Nil(t, err)
 	assert.Equal(t, 2, len(topics))
+
+	// Creation times come from the zk nodes, so just check that they're set
+	for i := range topics {
+		assert.False(t, topics[i].CreatedAt.IsZero())
+		topics[i].CreatedAt = time.Time{}
+	}
 	assert.Equal(
 		t,
 		TopicInfo{
@@ -377,13 +532,16 @@ func TestGetTopics(t *testing.T) {
 					LeaderEpoch:     2,
 				},
 			},
-			Version: 1,
+			Version: 2,
+			TopicID: "test-topic-id",
 		},
 		topics[1],
 	)
 
 	topic1, err := adminClient.GetTopic(ctx, "topic1", true)
 	assert.Nil(t, err)
+	assert.False(t, topic1.CreatedAt.IsZero())
+	topic1.CreatedAt = time.Time{}
 	assert.Equal(
 		t,
 		TopicInfo{
@@ -619,6 +777,38 @@ func TestUpdateTopicConfig(t *testing.T) {
 		`{"entity_path":"topics/topic1","version":2}`,
 		string(change),
 	)
+
+	lastChange, err := adminClient.GetLastConfigChange(ctx, ConfigEntityTopic, "topic1")
+	require.Nil(t, err)
+	require.NotNil(t, lastChange)
+	assert.Equal(
+		t,
+		[]ConfigChangeEntry{
+			{
+				Key:    "key5",
+				Before: "",
+				After:  "new-value",
+			},
+		},
+		lastChange.Entries,
+	)
+
+	_, err = adminClient.RevertLastConfigChange(ctx, ConfigEntityTopic, "topic1")
+	require.Nil(t, err)
+
+	revertedConfig, _, err := adminClient.zkClient.Get(
+		ctx,
+		fmt.Sprintf("/%s/config/topics/topic1", clusterName),
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(
+		t,
+		`{"config":{"key1":"value1","key2":"value2-updated","key3":"value3"},"version":1}`,
+		string(revertedConfig),
+	)
+
+	_, err = adminClient.RevertLastConfigChange(ctx, ConfigEntityTopic, "topic2")
+	assert.NotNil(t, err)
 }
 
 func TestUpdateBrokerConfig(t *testing.T) {
@@ -1064,6 +1254,291 @@ func TestRunLeaderElection(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestBrokerMaintenance(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{util.TestZKAddr()},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	require.NotNil(t, zkConn)
+	defer zkConn.Close()
+
+	clusterName := testClusterID("maintenance")
+	zk.CreateNodes(
+		t,
+		zkConn,
+		[]zk.PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", clusterName),
+				Obj:  nil,
+			},
+		},
+	)
+
+	ctx := context.Background()
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       clusterName,
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	maintenances, err := adminClient.GetBrokersInMaintenance(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, []BrokerMaintenance{}, maintenances)
+
+	startTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, brokerID := range []int{3, 1} {
+		err = adminClient.StartBrokerMaintenance(
+			ctx,
+			BrokerMaintenance{
+				BrokerID:  brokerID,
+				User:      "test-user",
+				Host:      "test-host",
+				Reason:    "disk replacement",
+				StartTime: startTime,
+				MovedLeaders: map[string][]int{
+					"test-topic": {0, 2},
+				},
+			},
+		)
+		require.Nil(t, err)
+	}
+
+	err = adminClient.StartBrokerMaintenance(ctx, BrokerMaintenance{BrokerID: 1})
+	assert.NotNil(t, err)
+
+	maintenances, err = adminClient.GetBrokersInMaintenance(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 3}, MaintenanceBrokerIDs(maintenances))
+	assert.Equal(t, "disk replacement", maintenances[0].Reason)
+	assert.Equal(t, startTime, maintenances[0].StartTime)
+	assert.Equal(
+		t,
+		map[string][]int{"test-topic": {0, 2}},
+		maintenances[0].MovedLeaders,
+	)
+
+	err = adminClient.EndBrokerMaintenance(ctx, 1)
+	require.Nil(t, err)
+	err = adminClient.EndBrokerMaintenance(ctx, 2)
+	assert.NotNil(t, err)
+
+	maintenances, err = adminClient.GetBrokersInMaintenance(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, []int{3}, MaintenanceBrokerIDs(maintenances))
+}
+
+func TestTopicFreezes(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{util.TestZKAddr()},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	require.NotNil(t, zkConn)
+	defer zkConn.Close()
+
+	clusterName := testClusterID("freezes")
+	zk.CreateNodes(
+		t,
+		zkConn,
+		[]zk.PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", clusterName),
+				Obj:  nil,
+			},
+		},
+	)
+
+	ctx := context.Background()
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       clusterName,
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	freezes, err := adminClient.GetTopicFreezes(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, []TopicFreeze{}, freezes)
+
+	startTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, topic := range []string{"topic-b", "topic-a"} {
+		err = adminClient.FreezeTopic(
+			ctx,
+			TopicFreeze{
+				Topic:     topic,
+				User:      "test-user",
+				Host:      "test-host",
+				Reason:    "peak traffic",
+				StartTime: startTime,
+				Expiry:    startTime.Add(time.Hour),
+			},
+		)
+		require.Nil(t, err)
+	}
+
+	freezes, err = adminClient.GetTopicFreezes(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(freezes))
+	assert.Equal(t, "topic-a", freezes[0].Topic)
+	assert.Equal(t, "peak traffic", freezes[0].Reason)
+	assert.True(t, freezes[0].Active(startTime.Add(time.Minute)))
+	assert.False(t, freezes[0].Active(startTime.Add(2*time.Hour)))
+
+	freeze, err := adminClient.GetTopicFreeze(ctx, "topic-b")
+	require.Nil(t, err)
+	require.NotNil(t, freeze)
+	assert.Equal(t, "topic-b", freeze.Topic)
+
+	err = adminClient.UnfreezeTopic(ctx, "topic-b")
+	require.Nil(t, err)
+	err = adminClient.UnfreezeTopic(ctx, "topic-c")
+	assert.NotNil(t, err)
+
+	freeze, err = adminClient.GetTopicFreeze(ctx, "topic-b")
+	require.Nil(t, err)
+	assert.Nil(t, freeze)
+}
+
+func TestRecordTopic(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{util.TestZKAddr()},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	require.NotNil(t, zkConn)
+	defer zkConn.Close()
+
+	clusterName := testClusterID("topic-ids")
+	zk.CreateNodes(
+		t,
+		zkConn,
+		[]zk.PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", clusterName),
+				Obj:  nil,
+			},
+		},
+	)
+
+	ctx := context.Background()
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       clusterName,
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	recordedTopic, err := adminClient.GetRecordedTopic(ctx, "topic-a")
+	require.Nil(t, err)
+	assert.Equal(t, RecordedTopic{}, recordedTopic)
+
+	createdAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err = adminClient.RecordTopic(ctx, TopicInfo{Name: "topic-a", TopicID: "id-1"})
+	require.Nil(t, err)
+	err = adminClient.RecordTopic(ctx, TopicInfo{Name: "topic-b", CreatedAt: createdAt})
+	require.Nil(t, err)
+	err = adminClient.RecordTopic(
+		ctx,
+		TopicInfo{Name: "topic-a", TopicID: "id-3", CreatedAt: createdAt},
+	)
+	require.Nil(t, err)
+
+	recordedTopics, err := adminClient.GetRecordedTopics(ctx)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		map[string]RecordedTopic{
+			"topic-a": {ID: "id-3", CreatedAt: createdAt},
+			"topic-b": {CreatedAt: createdAt},
+		},
+		recordedTopics,
+	)
+}
+
+func TestAppliedRef(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{util.TestZKAddr()},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	require.NotNil(t, zkConn)
+	defer zkConn.Close()
+
+	clusterName := testClusterID("applied-ref")
+	zk.CreateNodes(
+		t,
+		zkConn,
+		[]zk.PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", clusterName),
+				Obj:  nil,
+			},
+		},
+	)
+
+	ctx := context.Background()
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       clusterName,
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	appliedRef, err := adminClient.GetAppliedRef(ctx)
+	require.Nil(t, err)
+	assert.Nil(t, appliedRef)
+
+	applyTime := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, sha := range []string{"abc123", "def456"} {
+		err = adminClient.SetAppliedRef(
+			ctx,
+			AppliedRef{
+				RepoURL: "git@github.com:example/topics.git",
+				Ref:     "main",
+				SHA:     sha,
+				Path:    "topics",
+				User:    "test-user",
+				Host:    "test-host",
+				Time:    applyTime,
+			},
+		)
+		require.Nil(t, err)
+	}
+
+	appliedRef, err = adminClient.GetAppliedRef(ctx)
+	require.Nil(t, err)
+	require.NotNil(t, appliedRef)
+	assert.Equal(t, "def456", appliedRef.SHA)
+	assert.Equal(t, "main", appliedRef.Ref)
+	assert.Equal(t, applyTime, appliedRef.Time)
+}
+
 func testLocking(t *testing.T) {
 	ctx := context.Background()
 	adminClient, err := NewClient(
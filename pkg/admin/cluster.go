@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+
+	szk "github.com/samuel/go-zookeeper/zk"
+)
+
+// ClusterInfo summarizes the state of a cluster as a whole.
+type ClusterInfo struct {
+	ClusterID string `json:"clusterID"`
+
+	// ControllerID is the ID of the controller broker, or -1 if there's currently no
+	// controller.
+	ControllerID   int    `json:"controllerID"`
+	ControllerAddr string `json:"controllerAddr"`
+
+	NumBrokers    int `json:"numBrokers"`
+	NumRacks      int `json:"numRacks"`
+	NumTopics     int `json:"numTopics"`
+	NumPartitions int `json:"numPartitions"`
+
+	// ReassigningPartitions and ElectingPartitions are the number of partitions, by topic,
+	// in the in-progress reassignment and preferred leader election, if any.
+	ReassigningPartitions map[string]int `json:"reassigningPartitions"`
+	ElectingPartitions    map[string]int `json:"electingPartitions"`
+}
+
+// AssignmentInProgress returns whether there's a partition reassignment in progress.
+func (c ClusterInfo) AssignmentInProgress() bool {
+	return len(c.ReassigningPartitions) > 0
+}
+
+// ElectionInProgress returns whether there's a preferred leader election in progress.
+func (c ClusterInfo) ElectionInProgress() bool {
+	return len(c.ElectingPartitions) > 0
+}
+
+// GetClusterInfo gets a summary of the cluster from zookeeper, including the current
+// controller, the number of brokers and topics, and any in-progress reassignments and leader
+// elections.
+func (c *Client) GetClusterInfo(ctx context.Context) (ClusterInfo, error) {
+	clusterInfo := ClusterInfo{
+		ControllerID:          -1,
+		ReassigningPartitions: map[string]int{},
+		ElectingPartitions:    map[string]int{},
+	}
+
+	var err error
+	clusterInfo.ClusterID, err = c.GetClusterID(ctx)
+	if err != nil {
+		return clusterInfo, err
+	}
+
+	brokers, err := c.GetBrokers(ctx, nil)
+	if err != nil {
+		return clusterInfo, err
+	}
+	clusterInfo.NumBrokers = len(brokers)
+	clusterInfo.NumRacks = len(DistinctRacks(brokers))
+
+	controller := zkController{}
+	_, err = c.zkClient.GetJSON(ctx, c.zNode(controllerPath), &controller)
+	if err != nil && err != szk.ErrNoNode {
+		return clusterInfo, err
+	} else if err == nil {
+		clusterInfo.ControllerID = controller.BrokerID
+
+		for _, broker := range brokers {
+			if broker.ID == controller.BrokerID {
+				clusterInfo.ControllerAddr = broker.Addr()
+				break
+			}
+		}
+	}
+
+	topics, err := c.GetTopics(ctx, nil, false)
+	if err != nil {
+		return clusterInfo, err
+	}
+	clusterInfo.NumTopics = len(topics)
+	for _, topic := range topics {
+		clusterInfo.NumPartitions += len(topic.Partitions)
+	}
+
+	assignment := zkAssignment{}
+	_, err = c.zkClient.GetJSON(ctx, c.zNode(assignmentPath), &assignment)
+	if err != nil && err != szk.ErrNoNode {
+		return clusterInfo, err
+	}
+	for _, partition := range assignment.Partitions {
+		clusterInfo.ReassigningPartitions[partition.Topic]++
+	}
+
+	election := zkElection{}
+	_, err = c.zkClient.GetJSON(ctx, c.zNode(electionPath), &election)
+	if err != nil && err != szk.ErrNoNode {
+		return clusterInfo, err
+	}
+	for _, partition := range election.Partitions {
+		clusterInfo.ElectingPartitions[partition.Topic]++
+	}
+
+	return clusterInfo, nil
+}
@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// lastConfigChangesPath is the zk path, relative to the cluster prefix, that stores the last
+// config change made by topicctl to each topic and broker. Like the maintenance node, it's
+// only used by topicctl.
+const lastConfigChangesPath = "/topicctl/config-changes"
+
+const (
+	// ConfigEntityTopic is the entity type for topic config changes.
+	ConfigEntityTopic = "topics"
+
+	// ConfigEntityBroker is the entity type for broker config changes.
+	ConfigEntityBroker = "brokers"
+)
+
+// ConfigChange stores the keys that were updated by a single config change to a topic or
+// broker, along with their values before and after the change.
+type ConfigChange struct {
+	EntityType string              `json:"entityType"`
+	EntityName string              `json:"entityName"`
+	User       string              `json:"user"`
+	Host       string              `json:"host"`
+	Time       time.Time           `json:"time"`
+	Entries    []ConfigChangeEntry `json:"entries"`
+}
+
+// ConfigChangeEntry is the change to a single config key. Empty values mean that the key
+// wasn't set, i.e. that it was added (if Before is empty) or removed (if After is empty).
+type ConfigChangeEntry struct {
+	Key    string `json:"key"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type zkConfigChanges struct {
+	Version int                     `json:"version"`
+	Changes map[string]ConfigChange `json:"changes"`
+}
+
+// GetLastConfigChange returns the last config change that topicctl made to the argument
+// topic or broker, or nil if there isn't one.
+func (c *Client) GetLastConfigChange(
+	ctx context.Context,
+	entityType string,
+	entityName string,
+) (*ConfigChange, error) {
+	changesObj, _, err := c.getConfigChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	change, ok := changesObj.Changes[configEntityPath(entityType, entityName)]
+	if !ok {
+		return nil, nil
+	}
+	return &change, nil
+}
+
+// RevertLastConfigChange restores the values of the keys that were updated by the last
+// config change to the argument topic or broker. Keys that were added by the change are
+// removed. The revert is itself recorded as a change, so reverting twice re-applies the
+// original change. The reverted change is returned.
+func (c *Client) RevertLastConfigChange(
+	ctx context.Context,
+	entityType string,
+	entityName string,
+) (ConfigChange, error) {
+	if c.readOnly {
+		return ConfigChange{}, errors.New("Cannot revert config change in read-only mode")
+	}
+
+	change, err := c.GetLastConfigChange(ctx, entityType, entityName)
+	if err != nil {
+		return ConfigChange{}, err
+	}
+	if change == nil {
+		return ConfigChange{}, fmt.Errorf(
+			"No config changes have been recorded for %s",
+			configEntityPath(entityType, entityName),
+		)
+	}
+
+	configEntries := []kafka.ConfigEntry{}
+	for _, entry := range change.Entries {
+		configEntries = append(
+			configEntries,
+			kafka.ConfigEntry{
+				ConfigName:  entry.Key,
+				ConfigValue: entry.Before,
+			},
+		)
+	}
+
+	switch entityType {
+	case ConfigEntityTopic:
+		_, err = c.UpdateTopicConfig(ctx, entityName, configEntries, true)
+	case ConfigEntityBroker:
+		_, err = c.updateBrokerConfigNode(ctx, entityName, configEntries, true)
+	default:
+		err = fmt.Errorf("Unsupported config entity type: %s", entityType)
+	}
+
+	return *change, err
+}
+
+// recordConfigChange stores the differences between the argument before and after configs
+// for the updated keys as the last config change for the entity. Errors are logged but
+// otherwise ignored since the config has already been updated by the time this is called.
+func (c *Client) recordConfigChange(
+	ctx context.Context,
+	entityType string,
+	entityName string,
+	before map[string]string,
+	after map[string]string,
+	updatedKeys []string,
+) {
+	entries := configChangeEntries(before, after, updatedKeys)
+	if len(entries) == 0 {
+		return
+	}
+
+	change := ConfigChange{
+		EntityType: entityType,
+		EntityName: entityName,
+		User:       "unknown",
+		Host:       "unknown",
+		Time:       time.Now().UTC(),
+		Entries:    entries,
+	}
+	if currUser, err := user.Current(); err == nil {
+		change.User = currUser.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		change.Host = host
+	}
+
+	changesObj, version, err := c.getConfigChanges(ctx)
+	if err == nil {
+		changesObj.Changes[configEntityPath(entityType, entityName)] = change
+		err = c.setConfigChanges(ctx, changesObj, version)
+	}
+	if err != nil {
+		log.Warnf(
+			"Could not record config change for %s; it can't be reverted: %+v",
+			configEntityPath(entityType, entityName),
+			err,
+		)
+	}
+}
+
+// getConfigChanges returns the last config changes along with the version of the zk node.
+// If the node doesn't exist, the version is -1.
+func (c *Client) getConfigChanges(ctx context.Context) (zkConfigChanges, int32, error) {
+	changesObj := zkConfigChanges{
+		Version: 1,
+		Changes: map[string]ConfigChange{},
+	}
+	zPath := c.zNode(lastConfigChangesPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return changesObj, 0, err
+	}
+	if !exists {
+		return changesObj, -1, nil
+	}
+
+	stats, err := c.zkClient.GetJSON(ctx, zPath, &changesObj)
+	if err != nil {
+		return changesObj, 0, err
+	}
+	if changesObj.Changes == nil {
+		changesObj.Changes = map[string]ConfigChange{}
+	}
+
+	return changesObj, stats.Version, nil
+}
+
+func (c *Client) setConfigChanges(
+	ctx context.Context,
+	changesObj zkConfigChanges,
+	version int32,
+) error {
+	zPath := c.zNode(lastConfigChangesPath)
+
+	if version >= 0 {
+		log.Debugf("Updating config changes at %s: %+v", zPath, changesObj)
+		_, err := c.zkClient.SetJSON(ctx, zPath, changesObj, version)
+		return err
+	}
+
+	// Parent might not already exist
+	zRoot := filepath.Dir(zPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zRoot)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Infof("Creating topicctl path: %s", zRoot)
+		if err := c.zkClient.Create(ctx, zRoot, nil, false); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Creating config changes at %s: %+v", zPath, changesObj)
+	return c.zkClient.CreateJSON(ctx, zPath, changesObj, false)
+}
+
+// configChangeEntries returns the entries for the argument updated keys whose values differ
+// between the before and after configs, sorted by key.
+func configChangeEntries(
+	before map[string]string,
+	after map[string]string,
+	updatedKeys []string,
+) []ConfigChangeEntry {
+	entries := []ConfigChangeEntry{}
+
+	for _, key := range updatedKeys {
+		if before[key] == after[key] {
+			continue
+		}
+		entries = append(
+			entries,
+			ConfigChangeEntry{
+				Key:    key,
+				Before: before[key],
+				After:  after[key],
+			},
+		)
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Key < entries[b].Key
+	})
+
+	return entries
+}
+
+// configValues returns a copy of the key-value pairs in a zk config object.
+func configValues(configMap map[string]interface{}) map[string]string {
+	values := map[string]string{}
+
+	configKVMap, ok := configMap["config"].(map[string]interface{})
+	if !ok {
+		return values
+	}
+	for key, value := range configKVMap {
+		values[key] = fmt.Sprintf("%v", value)
+	}
+	return values
+}
+
+func configEntityPath(entityType string, entityName string) string {
+	return fmt.Sprintf("%s/%s", entityType, entityName)
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigChangeEntries(t *testing.T) {
+	before := configValues(
+		map[string]interface{}{
+			"version": 1,
+			"config": map[string]interface{}{
+				"key1": "value1",
+				"key2": "value2",
+				"key3": "value3",
+			},
+		},
+	)
+	after := map[string]string{
+		"key1": "value1",
+		"key2": "value2-updated",
+		"key4": "value4",
+	}
+
+	assert.Equal(
+		t,
+		[]ConfigChangeEntry{
+			{
+				Key:    "key2",
+				Before: "value2",
+				After:  "value2-updated",
+			},
+			{
+				Key:    "key3",
+				Before: "value3",
+				After:  "",
+			},
+			{
+				Key:    "key4",
+				Before: "",
+				After:  "value4",
+			},
+		},
+		configChangeEntries(before, after, []string{"key4", "key1", "key3", "key2"}),
+	)
+	assert.Equal(t, []ConfigChangeEntry{}, configChangeEntries(before, before, []string{"key1"}))
+	assert.Equal(t, map[string]string{}, configValues(map[string]interface{}{}))
+}
@@ -0,0 +1,218 @@
+package admin
+
+import "sort"
+
+// ConfigSource is a string type that describes where the effective value of a topic
+// config key comes from.
+type ConfigSource string
+
+const (
+	// ConfigSourceTopic means that the value is explicitly overridden for the topic.
+	ConfigSourceTopic ConfigSource = "topic override"
+
+	// ConfigSourceClusterDefault means that the value is inherited from a dynamic,
+	// cluster-wide broker default stored in zookeeper.
+	ConfigSourceClusterDefault ConfigSource = "cluster default"
+
+	// ConfigSourceKafkaDefault means that the value is inherited from the Kafka default.
+	// Note that this may be overridden by static broker settings in server.properties,
+	// which aren't visible in zookeeper.
+	ConfigSourceKafkaDefault ConfigSource = "kafka default"
+)
+
+// topicConfigDefault maps a topic-level config key to the broker-level key that
+// provides its default, along with the default value of the latter in Kafka.
+type topicConfigDefault struct {
+	brokerKey    string
+	defaultValue string
+}
+
+// topicConfigDefaults contains the broker keys and default values for topic configs, based
+// on https://kafka.apache.org/documentation/#topicconfigs as of Kafka 2.4.
+var topicConfigDefaults = map[string]topicConfigDefault{
+	"cleanup.policy": {
+		brokerKey:    "log.cleanup.policy",
+		defaultValue: "delete",
+	},
+	"compression.type": {
+		brokerKey:    "compression.type",
+		defaultValue: "producer",
+	},
+	"delete.retention.ms": {
+		brokerKey:    "log.cleaner.delete.retention.ms",
+		defaultValue: "86400000",
+	},
+	"file.delete.delay.ms": {
+		brokerKey:    "log.segment.delete.delay.ms",
+		defaultValue: "60000",
+	},
+	"flush.messages": {
+		brokerKey:    "log.flush.interval.messages",
+		defaultValue: "9223372036854775807",
+	},
+	"flush.ms": {
+		brokerKey:    "log.flush.interval.ms",
+		defaultValue: "9223372036854775807",
+	},
+	"index.interval.bytes": {
+		brokerKey:    "log.index.interval.bytes",
+		defaultValue: "4096",
+	},
+	"max.compaction.lag.ms": {
+		brokerKey:    "log.cleaner.max.compaction.lag.ms",
+		defaultValue: "9223372036854775807",
+	},
+	"max.message.bytes": {
+		brokerKey:    "message.max.bytes",
+		defaultValue: "1000012",
+	},
+	"message.timestamp.difference.max.ms": {
+		brokerKey:    "log.message.timestamp.difference.max.ms",
+		defaultValue: "9223372036854775807",
+	},
+	"message.timestamp.type": {
+		brokerKey:    "log.message.timestamp.type",
+		defaultValue: "CreateTime",
+	},
+	"min.cleanable.dirty.ratio": {
+		brokerKey:    "log.cleaner.min.cleanable.ratio",
+		defaultValue: "0.5",
+	},
+	"min.compaction.lag.ms": {
+		brokerKey:    "log.cleaner.min.compaction.lag.ms",
+		defaultValue: "0",
+	},
+	"min.insync.replicas": {
+		brokerKey:    "min.insync.replicas",
+		defaultValue: "1",
+	},
+	"preallocate": {
+		brokerKey:    "log.preallocate",
+		defaultValue: "false",
+	},
+	"retention.bytes": {
+		brokerKey:    "log.retention.bytes",
+		defaultValue: "-1",
+	},
+	"retention.ms": {
+		brokerKey:    "log.retention.ms",
+		defaultValue: "604800000",
+	},
+	"segment.bytes": {
+		brokerKey:    "log.segment.bytes",
+		defaultValue: "1073741824",
+	},
+	"segment.index.bytes": {
+		brokerKey:    "log.index.size.max.bytes",
+		defaultValue: "10485760",
+	},
+	"segment.jitter.ms": {
+		brokerKey:    "log.roll.jitter.ms",
+		defaultValue: "0",
+	},
+	"segment.ms": {
+		brokerKey:    "log.roll.ms",
+		defaultValue: "604800000",
+	},
+	"unclean.leader.election.enable": {
+		brokerKey:    "unclean.leader.election.enable",
+		defaultValue: "false",
+	},
+}
+
+// TopicConfigDiff compares the value of a single topic config key to the defaults that it
+// would otherwise inherit.
+type TopicConfigDiff struct {
+	Key            string
+	BrokerKey      string
+	TopicValue     string
+	ClusterDefault string
+	KafkaDefault   string
+	Source         ConfigSource
+}
+
+// EffectiveValue returns the value that's in effect for the topic.
+func (d TopicConfigDiff) EffectiveValue() string {
+	switch d.Source {
+	case ConfigSourceTopic:
+		return d.TopicValue
+	case ConfigSourceClusterDefault:
+		return d.ClusterDefault
+	default:
+		return d.KafkaDefault
+	}
+}
+
+// IsOverride returns whether the topic explicitly overrides the value that it would
+// otherwise inherit.
+func (d TopicConfigDiff) IsOverride() bool {
+	return d.Source == ConfigSourceTopic
+}
+
+// EffectiveTopicConfigValue returns the value of a topic config key that's in effect for a
+// topic with the argument config overrides, falling back to the cluster-wide dynamic broker
+// defaults and then the Kafka defaults.
+func EffectiveTopicConfigValue(
+	key string,
+	topicConfig map[string]string,
+	clusterDefaults map[string]string,
+) string {
+	if topicValue, ok := topicConfig[key]; ok {
+		return topicValue
+	}
+
+	configDefault := topicConfigDefaults[key]
+	if configDefault.brokerKey != "" && clusterDefaults[configDefault.brokerKey] != "" {
+		return clusterDefaults[configDefault.brokerKey]
+	}
+
+	return configDefault.defaultValue
+}
+
+// TopicConfigDiffs compares the config overrides for a topic to the cluster-wide dynamic
+// broker defaults and the Kafka defaults. The results include all of the topic configs
+// with known defaults plus any other keys that are set in the topic, sorted by key.
+func TopicConfigDiffs(
+	topicConfig map[string]string,
+	clusterDefaults map[string]string,
+) []TopicConfigDiff {
+	keys := map[string]struct{}{}
+	for key := range topicConfigDefaults {
+		keys[key] = struct{}{}
+	}
+	for key := range topicConfig {
+		keys[key] = struct{}{}
+	}
+
+	diffs := []TopicConfigDiff{}
+
+	for key := range keys {
+		configDefault := topicConfigDefaults[key]
+
+		diff := TopicConfigDiff{
+			Key:          key,
+			BrokerKey:    configDefault.brokerKey,
+			KafkaDefault: configDefault.defaultValue,
+		}
+		if configDefault.brokerKey != "" {
+			diff.ClusterDefault = clusterDefaults[configDefault.brokerKey]
+		}
+
+		if topicValue, ok := topicConfig[key]; ok {
+			diff.TopicValue = topicValue
+			diff.Source = ConfigSourceTopic
+		} else if diff.ClusterDefault != "" {
+			diff.Source = ConfigSourceClusterDefault
+		} else {
+			diff.Source = ConfigSourceKafkaDefault
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	sort.Slice(diffs, func(a, b int) bool {
+		return diffs[a].Key < diffs[b].Key
+	})
+
+	return diffs
+}
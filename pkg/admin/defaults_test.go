@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicConfigDiffs(t *testing.T) {
+	diffs := TopicConfigDiffs(
+		map[string]string{
+			"retention.ms":  "3600000",
+			"custom.plugin": "value",
+		},
+		map[string]string{
+			"log.cleanup.policy": "compact",
+			"log.retention.ms":   "86400000",
+		},
+	)
+
+	diffsMap := map[string]TopicConfigDiff{}
+	for _, diff := range diffs {
+		diffsMap[diff.Key] = diff
+	}
+	assert.Equal(t, len(topicConfigDefaults)+1, len(diffs))
+	assert.Equal(t, "cleanup.policy", diffs[0].Key)
+
+	assert.Equal(
+		t,
+		TopicConfigDiff{
+			Key:            "retention.ms",
+			BrokerKey:      "log.retention.ms",
+			TopicValue:     "3600000",
+			ClusterDefault: "86400000",
+			KafkaDefault:   "604800000",
+			Source:         ConfigSourceTopic,
+		},
+		diffsMap["retention.ms"],
+	)
+	assert.Equal(t, "3600000", diffsMap["retention.ms"].EffectiveValue())
+	assert.True(t, diffsMap["retention.ms"].IsOverride())
+
+	assert.Equal(t, ConfigSourceClusterDefault, diffsMap["cleanup.policy"].Source)
+	assert.Equal(t, "compact", diffsMap["cleanup.policy"].EffectiveValue())
+
+	assert.Equal(t, ConfigSourceKafkaDefault, diffsMap["segment.bytes"].Source)
+	assert.Equal(t, "1073741824", diffsMap["segment.bytes"].EffectiveValue())
+	assert.False(t, diffsMap["segment.bytes"].IsOverride())
+
+	assert.Equal(t, ConfigSourceTopic, diffsMap["custom.plugin"].Source)
+	assert.Equal(t, "", diffsMap["custom.plugin"].KafkaDefault)
+}
+
+func TestEffectiveTopicConfigValue(t *testing.T) {
+	topicConfig := map[string]string{
+		"retention.ms": "3600000",
+	}
+	clusterDefaults := map[string]string{
+		"log.retention.ms":    "86400000",
+		"min.insync.replicas": "2",
+	}
+
+	assert.Equal(
+		t,
+		"3600000",
+		EffectiveTopicConfigValue("retention.ms", topicConfig, clusterDefaults),
+	)
+	assert.Equal(
+		t,
+		"2",
+		EffectiveTopicConfigValue("min.insync.replicas", topicConfig, clusterDefaults),
+	)
+	assert.Equal(
+		t,
+		"delete",
+		EffectiveTopicConfigValue("cleanup.policy", topicConfig, clusterDefaults),
+	)
+	assert.Equal(
+		t,
+		"",
+		EffectiveTopicConfigValue("custom.plugin", topicConfig, clusterDefaults),
+	)
+}
@@ -0,0 +1,238 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Feature is an operation that's only supported by brokers running a minimum version of
+// Kafka. Support is detected through the API that the operation uses.
+type Feature struct {
+	Name          string
+	APIKey        int16
+	MinAPIVersion int16
+
+	// KafkaVersion is the first Kafka release that supports the feature; it's only used in
+	// messages.
+	KafkaVersion string
+}
+
+var (
+	// FeatureCreateTopics is creating topics through the CreateTopics API. On clusters that
+	// don't support it, topics are created through zookeeper instead.
+	FeatureCreateTopics = Feature{
+		Name:          "creating topics via the broker API",
+		APIKey:        19,
+		MinAPIVersion: 0,
+		KafkaVersion:  "0.10.1",
+	}
+
+	// FeatureDeleteTopics is deleting topics through the DeleteTopics API. On clusters that
+	// don't support it, topics are deleted through zookeeper instead.
+	FeatureDeleteTopics = Feature{
+		Name:          "deleting topics via the broker API",
+		APIKey:        20,
+		MinAPIVersion: 0,
+		KafkaVersion:  "0.10.1",
+	}
+
+	// FeatureDescribeConfigs is getting topic configs through the DescribeConfigs API. On
+	// clusters that don't support it, the configs are read from zookeeper instead.
+	FeatureDescribeConfigs = Feature{
+		Name:          "describing configs via the broker API",
+		APIKey:        describeConfigsAPIKey,
+		MinAPIVersion: describeConfigsAPIVersion,
+		KafkaVersion:  "1.1",
+	}
+
+	// FeatureDeleteRecords is deleting the records in a partition before an offset.
+	FeatureDeleteRecords = Feature{
+		Name:          "deleting records",
+		APIKey:        deleteRecordsAPIKey,
+		MinAPIVersion: deleteRecordsAPIVersion,
+		KafkaVersion:  "0.11.0",
+	}
+
+	// FeatureDescribeLogDirs is getting the log directories and replica sizes of brokers.
+	FeatureDescribeLogDirs = Feature{
+		Name:          "describing log dirs",
+		APIKey:        describeLogDirsAPIKey,
+		MinAPIVersion: describeLogDirsAPIVersion,
+		KafkaVersion:  "1.0",
+	}
+
+	// FeatureACLs is blocking topic writes through ACLs.
+	FeatureACLs = Feature{
+		Name:          "blocking topic writes via ACLs",
+		APIKey:        describeACLsAPIKey,
+		MinAPIVersion: aclsAPIVersion,
+		KafkaVersion:  "2.0",
+	}
+
+	// FeatureCancelReassignments is cancelling in-progress partition reassignments.
+	FeatureCancelReassignments = Feature{
+		Name:          "cancelling partition reassignments",
+		APIKey:        alterPartitionReassignmentsAPIKey,
+		MinAPIVersion: alterPartitionReassignmentsAPIVersion,
+		KafkaVersion:  "2.4",
+	}
+
+	// FeatureScramCredentials is managing SCRAM user credentials through the broker APIs. On
+	// clusters that don't support it, the credentials are managed through zookeeper instead.
+	FeatureScramCredentials = Feature{
+		Name:          "managing SCRAM credentials via the broker API",
+		APIKey:        alterUserScramCredentialsAPIKey,
+		MinAPIVersion: scramCredentialsAPIVersion,
+		KafkaVersion:  "2.7",
+	}
+
+	// AllFeatures are all of the features whose support depends on the cluster version.
+	AllFeatures = []Feature{
+		FeatureCreateTopics,
+		FeatureDeleteTopics,
+		FeatureDescribeConfigs,
+		FeatureDeleteRecords,
+		FeatureDescribeLogDirs,
+		FeatureACLs,
+		FeatureCancelReassignments,
+		FeatureScramCredentials,
+	}
+)
+
+// UnsupportedFeatureError is returned when an operation needs a feature that one or more
+// brokers in the cluster don't support.
+type UnsupportedFeatureError struct {
+	Feature Feature
+
+	// Brokers are the IDs of the brokers that don't support the feature.
+	Brokers []int
+
+	// KafkaVersions are the inferred Kafka versions of these brokers, if known.
+	KafkaVersions []string
+}
+
+// Error returns a description of the error.
+func (e *UnsupportedFeatureError) Error() string {
+	brokerStrs := []string{}
+	for _, broker := range e.Brokers {
+		brokerStrs = append(brokerStrs, fmt.Sprintf("%d", broker))
+	}
+
+	versionsStr := ""
+	if len(e.KafkaVersions) > 0 {
+		versionsStr = fmt.Sprintf(", which are running Kafka %s", strings.Join(e.KafkaVersions, ", "))
+	}
+
+	return fmt.Sprintf(
+		"The cluster doesn't support %s: it requires Kafka %s or newer on all brokers, but isn't supported by broker(s) %s%s",
+		e.Feature.Name,
+		e.Feature.KafkaVersion,
+		strings.Join(brokerStrs, ", "),
+		versionsStr,
+	)
+}
+
+// UnsupportedBrokers returns the IDs of the argument brokers that don't support the argument
+// feature, sorted in ascending order. Brokers whose versions couldn't be fetched are ignored.
+func UnsupportedBrokers(brokerVersions []BrokerAPIVersions, feature Feature) []int {
+	brokerIDs := []int{}
+
+	for _, brokerVersion := range brokerVersions {
+		if brokerVersion.Error != "" {
+			continue
+		}
+
+		supported := false
+		for _, apiVersion := range brokerVersion.APIVersions {
+			if apiVersion.ApiKey == feature.APIKey &&
+				apiVersion.MinVersion <= feature.MinAPIVersion &&
+				apiVersion.MaxVersion >= feature.MinAPIVersion {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			brokerIDs = append(brokerIDs, brokerVersion.BrokerID)
+		}
+	}
+
+	sort.Ints(brokerIDs)
+	return brokerIDs
+}
+
+// CheckFeature returns an *UnsupportedFeatureError if any of the brokers in the cluster
+// don't support the argument feature. The API versions of the brokers are fetched on the
+// first call and then cached for the lifetime of the client. If none of the brokers can be
+// reached, the feature is assumed to be supported so that the operation itself can surface
+// the connection error.
+func (c *Client) CheckFeature(ctx context.Context, feature Feature) error {
+	brokerVersions, err := c.getCachedBrokerAPIVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	unsupported := UnsupportedBrokers(brokerVersions, feature)
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	unsupportedVersions := []BrokerAPIVersions{}
+	for _, brokerVersion := range brokerVersions {
+		for _, brokerID := range unsupported {
+			if brokerVersion.BrokerID == brokerID {
+				unsupportedVersions = append(unsupportedVersions, brokerVersion)
+			}
+		}
+	}
+
+	return &UnsupportedFeatureError{
+		Feature:       feature,
+		Brokers:       unsupported,
+		KafkaVersions: DistinctKafkaVersions(unsupportedVersions),
+	}
+}
+
+// SupportsFeature returns whether all of the brokers in the cluster support the argument
+// feature. See CheckFeature for details.
+func (c *Client) SupportsFeature(ctx context.Context, feature Feature) (bool, error) {
+	err := c.CheckFeature(ctx, feature)
+	if _, ok := err.(*UnsupportedFeatureError); ok {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *Client) getCachedBrokerAPIVersions(ctx context.Context) ([]BrokerAPIVersions, error) {
+	c.apiVersionsMutex.Lock()
+	defer c.apiVersionsMutex.Unlock()
+
+	if c.apiVersions != nil {
+		return c.apiVersions, nil
+	}
+
+	brokers, err := c.GetBrokers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	brokerVersions := c.GetBrokerAPIVersions(ctx, brokers)
+
+	reachable := false
+	for _, brokerVersion := range brokerVersions {
+		if brokerVersion.Error == "" {
+			reachable = true
+			break
+		}
+	}
+	if !reachable {
+		// Don't cache the result so that a later call can try again
+		log.Debug("Could not get API versions from any brokers; assuming all features are supported")
+		return brokerVersions, nil
+	}
+
+	c.apiVersions = brokerVersions
+	return brokerVersions, nil
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsupportedBrokers(t *testing.T) {
+	brokerVersions := []BrokerAPIVersions{
+		{
+			BrokerID: 3,
+			APIVersions: []kafka.ApiVersion{
+				{ApiKey: 19, MinVersion: 0, MaxVersion: 5},
+				{ApiKey: 29, MinVersion: 0, MaxVersion: 2},
+			},
+		},
+		{
+			BrokerID: 1,
+			APIVersions: []kafka.ApiVersion{
+				{ApiKey: 19, MinVersion: 0, MaxVersion: 2},
+				{ApiKey: 29, MinVersion: 0, MaxVersion: 0},
+			},
+		},
+		{
+			BrokerID: 2,
+			APIVersions: []kafka.ApiVersion{
+				{ApiKey: 18, MinVersion: 0, MaxVersion: 0},
+			},
+		},
+		{
+			BrokerID: 4,
+			Error:    "connection refused",
+		},
+	}
+
+	assert.Equal(t, []int{2}, UnsupportedBrokers(brokerVersions, FeatureCreateTopics))
+	assert.Equal(t, []int{1, 2}, UnsupportedBrokers(brokerVersions, FeatureACLs))
+	assert.Equal(t, []int{}, UnsupportedBrokers(brokerVersions[:1], FeatureCreateTopics))
+}
+
+func TestUnsupportedFeatureError(t *testing.T) {
+	err := &UnsupportedFeatureError{
+		Feature:       FeatureACLs,
+		Brokers:       []int{1, 2},
+		KafkaVersions: []string{"1.1"},
+	}
+	assert.Equal(
+		t,
+		"The cluster doesn't support blocking topic writes via ACLs: it requires Kafka 2.0 or newer on all brokers, but isn't supported by broker(s) 1, 2, which are running Kafka 1.1",
+		err.Error(),
+	)
+}
+
+func TestRoundRobinAssignments(t *testing.T) {
+	assignments, err := roundRobinAssignments([]int{1, 2, 3}, 4, 2)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]PartitionAssignment{
+			{ID: 0, Replicas: []int{1, 2}},
+			{ID: 1, Replicas: []int{2, 3}},
+			{ID: 2, Replicas: []int{3, 1}},
+			{ID: 3, Replicas: []int{1, 2}},
+		},
+		assignments,
+	)
+
+	_, err = roundRobinAssignments([]int{1, 2}, 4, 3)
+	assert.Error(t, err)
+
+	_, err = roundRobinAssignments([]int{1, 2}, 0, 1)
+	assert.Error(t, err)
+}
@@ -12,10 +12,12 @@ import (
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/segmentio/topicctl/pkg/zk"
 )
 
-// FormatBrokers creates a pretty table from a list of brokers.
-func FormatBrokers(brokers []BrokerInfo, full bool) string {
+// FormatBrokers creates a pretty table from a list of brokers. If stats is non-nil, the
+// Kafka version and partition counts for each broker are included.
+func FormatBrokers(brokers []BrokerInfo, stats []BrokerStats, full bool) string {
 	buf := &bytes.Buffer{}
 
 	table := tablewriter.NewWriter(buf)
@@ -48,6 +50,22 @@ func FormatBrokers(brokers []BrokerInfo, full bool) string {
 		"Timestamp",
 	)
 
+	statsByBroker := map[int]BrokerStats{}
+	for _, brokerStats := range stats {
+		statsByBroker[brokerStats.Broker] = brokerStats
+	}
+
+	if stats != nil {
+		headers = append(
+			headers,
+			"Version",
+			"Replicas",
+			"Leaders",
+			"Out-of-sync\nReplicas",
+			"Under-replicated\nPartitions",
+		)
+	}
+
 	if full {
 		headers = append(headers, "Config")
 	}
@@ -65,6 +83,11 @@ func FormatBrokers(brokers []BrokerInfo, full bool) string {
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
 		},
 	)
 	table.SetBorders(
@@ -97,6 +120,31 @@ func FormatBrokers(brokers []BrokerInfo, full bool) string {
 			broker.Timestamp.UTC().Format(time.RFC3339),
 		)
 
+		if stats != nil {
+			brokerStats := statsByBroker[broker.ID]
+
+			version := brokerStats.KafkaVersion
+			if version == "" {
+				version = "unknown"
+			}
+
+			var outOfSyncPrinter func(f string, a ...interface{}) string
+			if brokerStats.OutOfSync > 0 && util.InTerminal() {
+				outOfSyncPrinter = color.New(color.FgRed).SprintfFunc()
+			} else {
+				outOfSyncPrinter = fmt.Sprintf
+			}
+
+			row = append(
+				row,
+				version,
+				fmt.Sprintf("%d", brokerStats.Replicas),
+				fmt.Sprintf("%d", brokerStats.Leaders),
+				outOfSyncPrinter("%d", brokerStats.OutOfSync),
+				fmt.Sprintf("%d", brokerStats.UnderReplicated),
+			)
+		}
+
 		if full {
 			row = append(row, prettyConfig(broker.Config))
 		}
@@ -349,9 +397,211 @@ func FormatBrokersPerRack(brokers []BrokerInfo) string {
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
+// FormatBrokerVersions creates a pretty table that shows the inferred Kafka version of each
+// broker. If the brokers are running different versions, the versions are highlighted.
+func FormatBrokerVersions(brokers []BrokerInfo, brokerVersions []BrokerAPIVersions) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"ID", "Host", "Rack", "Kafka Version"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	brokersByID := map[int]BrokerInfo{}
+	for _, broker := range brokers {
+		brokersByID[broker.ID] = broker
+	}
+
+	var versionPrinter func(f string, a ...interface{}) string
+	if len(DistinctKafkaVersions(brokerVersions)) > 1 {
+		versionPrinter = color.New(color.FgRed).SprintfFunc()
+	} else {
+		versionPrinter = fmt.Sprintf
+	}
+
+	for _, brokerVersion := range brokerVersions {
+		broker := brokersByID[brokerVersion.BrokerID]
+
+		var versionStr string
+		if brokerVersion.Error != "" {
+			versionStr = fmt.Sprintf("unreachable: %s", brokerVersion.Error)
+		} else if brokerVersion.KafkaVersion == "" {
+			versionStr = "unknown"
+		} else {
+			versionStr = versionPrinter("%s", brokerVersion.KafkaVersion)
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", brokerVersion.BrokerID),
+				broker.Host,
+				broker.Rack,
+				versionStr,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatAPIVersions creates a pretty table that shows the supported version range of each
+// Kafka API by broker. APIs whose ranges differ between brokers are highlighted. Brokers whose
+// versions couldn't be fetched are omitted.
+func FormatAPIVersions(brokerVersions []BrokerAPIVersions) string {
+	buf := &bytes.Buffer{}
+
+	headers := []string{"Key", "API"}
+	reachable := []BrokerAPIVersions{}
+	apiKeysMap := map[int16]struct{}{}
+
+	for _, brokerVersion := range brokerVersions {
+		if brokerVersion.Error != "" {
+			continue
+		}
+		reachable = append(reachable, brokerVersion)
+		headers = append(headers, fmt.Sprintf("Broker %d", brokerVersion.BrokerID))
+
+		for _, apiVersion := range brokerVersion.APIVersions {
+			apiKeysMap[apiVersion.ApiKey] = struct{}{}
+		}
+	}
+
+	apiKeys := []int16{}
+	for apiKey := range apiKeysMap {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Slice(apiKeys, func(a, b int) bool {
+		return apiKeys[a] < apiKeys[b]
+	})
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(headers)
+	table.SetAutoWrapText(false)
+	alignments := []int{}
+	for range headers {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, apiKey := range apiKeys {
+		rangeStrs := []string{}
+		mixed := false
+
+		for _, brokerVersion := range reachable {
+			rangeStr := "-"
+			for _, apiVersion := range brokerVersion.APIVersions {
+				if apiVersion.ApiKey == apiKey {
+					rangeStr = fmt.Sprintf("%d-%d", apiVersion.MinVersion, apiVersion.MaxVersion)
+					break
+				}
+			}
+			if len(rangeStrs) > 0 && rangeStr != rangeStrs[0] {
+				mixed = true
+			}
+			rangeStrs = append(rangeStrs, rangeStr)
+		}
+
+		var rangePrinter func(f string, a ...interface{}) string
+		if mixed {
+			rangePrinter = color.New(color.FgRed).SprintfFunc()
+		} else {
+			rangePrinter = fmt.Sprintf
+		}
+
+		row := []string{fmt.Sprintf("%d", apiKey), APIKeyName(apiKey)}
+		for _, rangeStr := range rangeStrs {
+			row = append(row, rangePrinter("%s", rangeStr))
+		}
+		table.Append(row)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatFeatures creates a pretty table that shows whether the argument brokers support each
+// of the features whose support depends on the cluster version.
+func FormatFeatures(brokerVersions []BrokerAPIVersions) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Feature", "Requires", "Supported"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, feature := range AllFeatures {
+		supportedStr := "yes"
+
+		if unsupported := UnsupportedBrokers(brokerVersions, feature); len(unsupported) > 0 {
+			brokerStrs := []string{}
+			for _, broker := range unsupported {
+				brokerStrs = append(brokerStrs, fmt.Sprintf("%d", broker))
+			}
+			supportedStr = color.New(color.FgRed).Sprintf(
+				"no (brokers %s)",
+				strings.Join(brokerStrs, ","),
+			)
+		}
+
+		table.Append(
+			[]string{
+				feature.Name,
+				fmt.Sprintf("Kafka %s", feature.KafkaVersion),
+				supportedStr,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
 // FormatTopics creates a pretty table that lists the details of the
-// argument topics.
-func FormatTopics(topics []TopicInfo, brokers []BrokerInfo, full bool) string {
+// argument topics. Topics in the recreated map are highlighted.
+func FormatTopics(
+	topics []TopicInfo,
+	brokers []BrokerInfo,
+	full bool,
+	recreated map[string]bool,
+) string {
 	buf := &bytes.Buffer{}
 
 	headers := []string{
@@ -363,7 +613,7 @@ func FormatTopics(topics []TopicInfo, brokers []BrokerInfo, full bool) string {
 	}
 
 	if full {
-		headers = append(headers, "Config")
+		headers = append(headers, "ID", "Created", "Config")
 	}
 
 	table := tablewriter.NewWriter(buf)
@@ -379,6 +629,7 @@ func FormatTopics(topics []TopicInfo, brokers []BrokerInfo, full bool) string {
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
 		},
 	)
 	table.SetBorders(
@@ -402,8 +653,13 @@ func FormatTopics(topics []TopicInfo, brokers []BrokerInfo, full bool) string {
 
 		minRacks, maxRacks, _ := topic.RackCounts(brokerRacks)
 
+		name := topic.Name
+		if recreated[topic.Name] {
+			name = color.New(color.FgRed).Sprintf("%s (re-created)", topic.Name)
+		}
+
 		row := []string{
-			topic.Name,
+			name,
 			fmt.Sprintf("%d", len(topic.Partitions)),
 			fmt.Sprintf("%d", topic.MaxReplication()),
 			retentionStr,
@@ -411,7 +667,11 @@ func FormatTopics(topics []TopicInfo, brokers []BrokerInfo, full bool) string {
 		}
 
 		if full {
-			row = append(row, prettyConfig(topic.Config))
+			var createdStr string
+			if !topic.CreatedAt.IsZero() {
+				createdStr = topic.CreatedAt.Format(time.RFC3339)
+			}
+			row = append(row, topic.TopicID, createdStr, prettyConfig(topic.Config))
 		}
 
 		table.Append(row)
@@ -497,6 +757,66 @@ func FormatTopicPartitions(partitions []PartitionInfo, brokers []BrokerInfo) str
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
+// FormatPartitionSummaries creates a pretty table from the results of a
+// SummarizePartitionsByBroker call.
+func FormatPartitionSummaries(summaries []BrokerPartitionSummary) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Rack",
+			"Leaders",
+			"Replicas",
+			"Out-of-sync\nReplicas",
+			"Under-replicated\nPartitions",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, summary := range summaries {
+		var statusPrinter func(f string, a ...interface{}) string
+		if !util.InTerminal() || (summary.OutOfSync == 0 && summary.UnderReplicated == 0) {
+			statusPrinter = fmt.Sprintf
+		} else {
+			statusPrinter = color.New(color.FgRed).SprintfFunc()
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", summary.Broker),
+				summary.Rack,
+				fmt.Sprintf("%d", summary.Leaders),
+				fmt.Sprintf("%d", summary.Replicas),
+				statusPrinter("%d", summary.OutOfSync),
+				statusPrinter("%d", summary.UnderReplicated),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
 // FormatConfig creates a pretty table with all of the keys and values in a topic or
 // broker config.
 func FormatConfig(configMap map[string]string) string {
@@ -547,15 +867,20 @@ func FormatConfig(configMap map[string]string) string {
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
-// FormatTopicLeadersPerRack creates a pretty table that shows the number
-// of partitions with a leader in each rack.
-func FormatTopicLeadersPerRack(topic TopicInfo, brokers []BrokerInfo) string {
+// FormatTopicConfigDiffs creates a pretty table that shows the config values for a topic
+// side-by-side with the defaults that the topic would otherwise inherit.
+func FormatTopicConfigDiffs(diffs []TopicConfigDiff) string {
 	buf := &bytes.Buffer{}
 
 	table := tablewriter.NewWriter(buf)
 	table.SetHeader(
 		[]string{
-			"Rack", "Num Leaders",
+			"Key",
+			"Effective Value",
+			"Source",
+			"Topic Value",
+			"Cluster Default",
+			"Kafka Default",
 		},
 	)
 	table.SetAutoWrapText(false)
@@ -563,6 +888,10 @@ func FormatTopicLeadersPerRack(topic TopicInfo, brokers []BrokerInfo) string {
 		[]int{
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
 		},
 	)
 	table.SetBorders(
@@ -574,14 +903,22 @@ func FormatTopicLeadersPerRack(topic TopicInfo, brokers []BrokerInfo) string {
 		},
 	)
 
-	leadersPerRack := LeadersPerRack(brokers, topic)
-	racks := DistinctRacks(brokers)
+	for _, diff := range diffs {
+		var overridePrinter func(f string, a ...interface{}) string
+		if diff.IsOverride() && util.InTerminal() {
+			overridePrinter = color.New(color.FgCyan).SprintfFunc()
+		} else {
+			overridePrinter = fmt.Sprintf
+		}
 
-	for _, rack := range racks {
 		table.Append(
 			[]string{
-				rack,
-				fmt.Sprintf("%d", leadersPerRack[rack]),
+				overridePrinter("%s", diff.Key),
+				overridePrinter("%s", diff.EffectiveValue()),
+				overridePrinter("%s", diff.Source),
+				diff.TopicValue,
+				diff.ClusterDefault,
+				diff.KafkaDefault,
 			},
 		)
 	}
@@ -590,23 +927,20 @@ func FormatTopicLeadersPerRack(topic TopicInfo, brokers []BrokerInfo) string {
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
-// FormatAssignentDiffs generates a pretty table that shows the before
-// and after states of a partition replica and/or leader update.
-func FormatAssignentDiffs(
-	curr []PartitionAssignment,
-	desired []PartitionAssignment,
-	brokers []BrokerInfo,
-) string {
+// FormatMinISRStatuses creates a pretty table that shows the min.insync.replicas settings
+// and problems for the argument topics.
+func FormatMinISRStatuses(statuses []MinISRStatus) string {
 	buf := &bytes.Buffer{}
 
 	table := tablewriter.NewWriter(buf)
 	table.SetHeader(
 		[]string{
-			"Partition",
-			"Curr\nReplicas",
-			"Proposed\nReplicas",
-			"Diff?",
-			"New\nLeader?",
+			"Topic",
+			"Min ISR",
+			"Source",
+			"Replication\nFactor",
+			"Blocked\nPartitions",
+			"Problems",
 		},
 	)
 	table.SetAutoWrapText(false)
@@ -616,6 +950,8 @@ func FormatAssignentDiffs(
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
 		},
 	)
 	table.SetBorders(
@@ -627,31 +963,24 @@ func FormatAssignentDiffs(
 		},
 	)
 
-	diffs := AssignmentDiffs(curr, desired)
-
-	brokerRacks := BrokerRacks(brokers)
-	maxWidth := maxValueToMaxWidth(len(brokers))
-
-	for _, diff := range diffs {
-		var diffStr string
-		var newLeaderStr string
-
-		if !reflect.DeepEqual(diff.Old.Replicas, diff.New.Replicas) {
-			diffStr = "Y"
+	for _, status := range statuses {
+		blocked := "none"
+		if len(status.BlockedPartitions) > 0 {
+			blocked = fmt.Sprintf("%+v", PartitionIDs(status.BlockedPartitions))
 		}
-		if len(diff.Old.Replicas) > 0 &&
-			len(diff.New.Replicas) > 0 &&
-			diff.Old.Replicas[0] != diff.New.Replicas[0] {
-			newLeaderStr = "Y"
+		problems := "none"
+		if len(status.Problems) > 0 {
+			problems = strings.Join(status.Problems, "\n")
 		}
 
 		table.Append(
 			[]string{
-				fmt.Sprintf("%d", diff.PartitionID),
-				assignmentRacksStr(diff.Old, brokerRacks, maxWidth),
-				assignmentRacksDiffStr(diff.Old, diff.New, brokerRacks, maxWidth),
-				diffStr,
-				newLeaderStr,
+				status.Topic,
+				fmt.Sprintf("%d", status.MinISR),
+				string(status.Source),
+				fmt.Sprintf("%d", status.ReplicationFactor),
+				blocked,
+				problems,
 			},
 		)
 	}
@@ -660,23 +989,434 @@ func FormatAssignentDiffs(
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
-// FormatBrokerMaxPartitions generates a pretty table that shows the total number of
-// partitions that each broker is involved in for a diff. It's used to evaluate
-// the potential extra load that could occur on brokers during a migration.
-func FormatBrokerMaxPartitions(
-	curr []PartitionAssignment,
-	desired []PartitionAssignment,
-	brokers []BrokerInfo,
-) string {
+// FormatTopicLeadersPerRack creates a pretty table that shows the number
+// of partitions with a leader in each rack.
+func FormatTopicLeadersPerRack(topic TopicInfo, brokers []BrokerInfo) string {
 	buf := &bytes.Buffer{}
 
 	table := tablewriter.NewWriter(buf)
 	table.SetHeader(
 		[]string{
-			"Broker",
-			"Curr\nPartitions",
-			"Max\nPartitions",
-			"Proposed\nPartitions",
+			"Rack", "Num Leaders",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	leadersPerRack := LeadersPerRack(brokers, topic)
+	racks := DistinctRacks(brokers)
+
+	for _, rack := range racks {
+		table.Append(
+			[]string{
+				rack,
+				fmt.Sprintf("%d", leadersPerRack[rack]),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatAssignentDiffs generates a pretty table that shows the before
+// and after states of a partition replica and/or leader update.
+func FormatAssignentDiffs(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+	brokers []BrokerInfo,
+) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Partition",
+			"Curr\nReplicas",
+			"Proposed\nReplicas",
+			"Diff?",
+			"New\nLeader?",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	diffs := AssignmentDiffs(curr, desired)
+
+	brokerRacks := BrokerRacks(brokers)
+	maxWidth := maxValueToMaxWidth(len(brokers))
+
+	for _, diff := range diffs {
+		var diffStr string
+		var newLeaderStr string
+
+		if !reflect.DeepEqual(diff.Old.Replicas, diff.New.Replicas) {
+			diffStr = "Y"
+		}
+		if len(diff.Old.Replicas) > 0 &&
+			len(diff.New.Replicas) > 0 &&
+			diff.Old.Replicas[0] != diff.New.Replicas[0] {
+			newLeaderStr = "Y"
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", diff.PartitionID),
+				assignmentRacksStr(diff.Old, brokerRacks, maxWidth),
+				assignmentRacksDiffStr(diff.Old, diff.New, brokerRacks, maxWidth),
+				diffStr,
+				newLeaderStr,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatBrokerMaxPartitions generates a pretty table that shows the total number of
+// partitions that each broker is involved in for a diff. It's used to evaluate
+// the potential extra load that could occur on brokers during a migration.
+func FormatBrokerMaxPartitions(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+	brokers []BrokerInfo,
+) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Curr\nPartitions",
+			"Max\nPartitions",
+			"Proposed\nPartitions",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	startPartitionsPerBroker := MaxPartitionsPerBroker(curr)
+	maxPartitionsPerBroker := MaxPartitionsPerBroker(curr, desired)
+	finalPartitionsPerBroker := MaxPartitionsPerBroker(desired)
+
+	maxCount := maxInts(
+		maxMapValues(startPartitionsPerBroker),
+		maxMapValues(maxPartitionsPerBroker),
+		maxMapValues(finalPartitionsPerBroker),
+	)
+	maxCountWidth := maxValueToMaxWidth(maxCount)
+
+	for _, broker := range brokers {
+		migrationExtraPartitions := maxPartitionsPerBroker[broker.ID] -
+			startPartitionsPerBroker[broker.ID]
+		finalExtraPartitions := finalPartitionsPerBroker[broker.ID] -
+			startPartitionsPerBroker[broker.ID]
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", broker.ID),
+				fmt.Sprintf("%d", startPartitionsPerBroker[broker.ID]),
+				fmt.Sprintf(
+					"%*d%s",
+					maxCountWidth,
+					maxPartitionsPerBroker[broker.ID],
+					partitionCountDiffStr(migrationExtraPartitions),
+				),
+				fmt.Sprintf(
+					"%*d%s",
+					maxCountWidth,
+					finalPartitionsPerBroker[broker.ID],
+					partitionCountDiffStr(finalExtraPartitions),
+				),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatBrokerMoveBytes creates a pretty table that shows the estimated number of replicas
+// and bytes that each broker will receive and send when going from the current to the
+// desired assignments. See BrokerMoveBytes for how the bytes are estimated.
+func FormatBrokerMoveBytes(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+	partitionSizes map[int]int64,
+	brokers []BrokerInfo,
+) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Rack",
+			"New\nReplicas",
+			"Ingress",
+			"Egress",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	ingress, egress := BrokerMoveBytes(curr, desired, partitionSizes)
+
+	newReplicas := map[int]int{}
+	currByID := map[int]PartitionAssignment{}
+	for _, assignment := range curr {
+		currByID[assignment.ID] = assignment
+	}
+	for _, assignment := range desired {
+		currAssignment, ok := currByID[assignment.ID]
+		if !ok {
+			continue
+		}
+		for _, replica := range assignment.Replicas {
+			if currAssignment.Index(replica) == -1 {
+				newReplicas[replica]++
+			}
+		}
+	}
+
+	for _, broker := range brokers {
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", broker.ID),
+				broker.Rack,
+				fmt.Sprintf("%d", newReplicas[broker.ID]),
+				util.PrettyBytes(ingress[broker.ID]),
+				util.PrettyBytes(egress[broker.ID]),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatReassignmentProgress creates a pretty table that summarizes the replicas that are
+// still being copied to each broker in an in-progress reassignment, along with the brokers'
+// copy rates, throttle utilization, and ETAs.
+func FormatReassignmentProgress(progress ReassignmentProgress) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Remaining\nMoves",
+			"Remaining\nBytes",
+			"Rate",
+			"Throttle",
+			"Throttle\nUtilization",
+			"ETA",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, brokerProgress := range progress.Brokers {
+		remainingStr := "n/a"
+		if brokerProgress.RemainingBytes >= 0 {
+			remainingStr = util.PrettyBytes(brokerProgress.RemainingBytes)
+		}
+
+		rateStr := "n/a"
+		if brokerProgress.BytesPerSec >= 0 {
+			rateStr = fmt.Sprintf("%s/sec", util.PrettyBytes(int64(brokerProgress.BytesPerSec)))
+		}
+
+		throttleStr := "none"
+		if brokerProgress.ThrottleBytes > 0 {
+			throttleStr = fmt.Sprintf("%s/sec", util.PrettyBytes(brokerProgress.ThrottleBytes))
+		}
+
+		utilizationStr := "n/a"
+		if utilization, ok := brokerProgress.ThrottleUtilization(); ok {
+			utilizationStr = fmt.Sprintf("%.0f%%", 100.0*utilization)
+			if utilization >= 0.9 {
+				utilizationStr = color.New(color.FgYellow).Sprint(utilizationStr)
+			}
+		}
+
+		etaStr := "n/a"
+		if eta, ok := brokerProgress.ETA(); ok {
+			etaStr = util.PrettyDuration(eta)
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", brokerProgress.Broker),
+				fmt.Sprintf("%d", brokerProgress.RemainingMoves),
+				remainingStr,
+				rateStr,
+				throttleStr,
+				utilizationStr,
+				etaStr,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatLocks creates a pretty table from a list of locks.
+func FormatLocks(locks []zk.LockInfo, now time.Time) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Path",
+			"User",
+			"Host",
+			"PID",
+			"Start Time",
+			"Age",
+			"Waiters",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, lock := range locks {
+		row := []string{lock.Path}
+
+		if lock.Metadata != nil {
+			row = append(
+				row,
+				lock.Metadata.User,
+				lock.Metadata.Host,
+				fmt.Sprintf("%d", lock.Metadata.PID),
+				lock.Metadata.StartTime.UTC().Format(time.RFC3339),
+				now.Sub(lock.Metadata.StartTime).Round(time.Second).String(),
+			)
+		} else {
+			// Lock was acquired without metadata
+			row = append(row, "", "", "", "", "")
+		}
+
+		row = append(row, fmt.Sprintf("%d", lock.Waiters))
+		table.Append(row)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatTopicFreezes creates a pretty table that lists the details of the argument topic
+// freezes.
+func FormatTopicFreezes(freezes []TopicFreeze, now time.Time) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Topic",
+			"User",
+			"Host",
+			"Reason",
+			"Start Time",
+			"Expiry",
+			"Active",
 		},
 	)
 	table.SetAutoWrapText(false)
@@ -686,6 +1426,9 @@ func FormatBrokerMaxPartitions(
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
 			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
 		},
 	)
 	table.SetBorders(
@@ -697,39 +1440,216 @@ func FormatBrokerMaxPartitions(
 		},
 	)
 
-	startPartitionsPerBroker := MaxPartitionsPerBroker(curr)
-	maxPartitionsPerBroker := MaxPartitionsPerBroker(curr, desired)
-	finalPartitionsPerBroker := MaxPartitionsPerBroker(desired)
+	for _, freeze := range freezes {
+		expiry := "never"
+		if !freeze.Expiry.IsZero() {
+			expiry = freeze.Expiry.UTC().Format(time.RFC3339)
+		}
 
-	maxCount := maxInts(
-		maxMapValues(startPartitionsPerBroker),
-		maxMapValues(maxPartitionsPerBroker),
-		maxMapValues(finalPartitionsPerBroker),
+		table.Append(
+			[]string{
+				freeze.Topic,
+				freeze.User,
+				freeze.Host,
+				freeze.Reason,
+				freeze.StartTime.UTC().Format(time.RFC3339),
+				expiry,
+				fmt.Sprintf("%v", freeze.Active(now)),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatConfigChange creates a pretty table that lists the keys updated by the argument
+// config change, with their values before and after the change.
+func FormatConfigChange(change ConfigChange) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Key",
+			"Before",
+			"After",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
 	)
-	maxCountWidth := maxValueToMaxWidth(maxCount)
 
-	for _, broker := range brokers {
-		migrationExtraPartitions := maxPartitionsPerBroker[broker.ID] -
-			startPartitionsPerBroker[broker.ID]
-		finalExtraPartitions := finalPartitionsPerBroker[broker.ID] -
-			startPartitionsPerBroker[broker.ID]
+	for _, entry := range change.Entries {
+		table.Append(
+			[]string{
+				entry.Key,
+				entry.Before,
+				entry.After,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatAppliedRef creates a pretty table with the details of the git ref that was last
+// applied to a cluster.
+func FormatAppliedRef(appliedRef AppliedRef) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Key",
+			"Value",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	table.AppendBulk(
+		[][]string{
+			{"Repo", appliedRef.RepoURL},
+			{"Path", appliedRef.Path},
+			{"Ref", appliedRef.Ref},
+			{"SHA", appliedRef.SHA},
+			{"User", appliedRef.User},
+			{"Host", appliedRef.Host},
+			{"Time", appliedRef.Time.UTC().Format(time.RFC3339)},
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatClusterInfo creates a pretty table with the summary of a cluster.
+func FormatClusterInfo(clusterInfo ClusterInfo) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Key",
+			"Value",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	controllerStr := "none"
+	if clusterInfo.ControllerID >= 0 {
+		controllerStr = fmt.Sprintf("%d", clusterInfo.ControllerID)
+		if clusterInfo.ControllerAddr != "" {
+			controllerStr += fmt.Sprintf(" (%s)", clusterInfo.ControllerAddr)
+		}
+	}
+
+	table.AppendBulk(
+		[][]string{
+			{"Cluster ID", clusterInfo.ClusterID},
+			{"Controller", controllerStr},
+			{"Brokers", fmt.Sprintf("%d", clusterInfo.NumBrokers)},
+			{"Racks", fmt.Sprintf("%d", clusterInfo.NumRacks)},
+			{"Topics", fmt.Sprintf("%d", clusterInfo.NumTopics)},
+			{"Partitions", fmt.Sprintf("%d", clusterInfo.NumPartitions)},
+			{
+				"Reassignment",
+				inProgressStr(clusterInfo.ReassigningPartitions),
+			},
+			{
+				"Leader Election",
+				inProgressStr(clusterInfo.ElectingPartitions),
+			},
+		},
+	)
 
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatLogDirs creates a pretty table that summarizes the log directories on each broker.
+func FormatLogDirs(logDirs []LogDirInfo) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Path",
+			"Replicas",
+			"Size",
+			"Error",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, logDir := range logDirs {
 		table.Append(
 			[]string{
-				fmt.Sprintf("%d", broker.ID),
-				fmt.Sprintf("%d", startPartitionsPerBroker[broker.ID]),
-				fmt.Sprintf(
-					"%*d%s",
-					maxCountWidth,
-					maxPartitionsPerBroker[broker.ID],
-					partitionCountDiffStr(migrationExtraPartitions),
-				),
-				fmt.Sprintf(
-					"%*d%s",
-					maxCountWidth,
-					finalPartitionsPerBroker[broker.ID],
-					partitionCountDiffStr(finalExtraPartitions),
-				),
+				fmt.Sprintf("%d", logDir.Broker),
+				logDir.Path,
+				fmt.Sprintf("%d", len(logDir.Replicas)),
+				util.PrettyBytes(logDir.TotalSize()),
+				logDir.Error,
 			},
 		)
 	}
@@ -738,6 +1658,85 @@ func FormatBrokerMaxPartitions(
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
+// FormatLogDirReplicas creates a pretty table with the partition replicas in each log
+// directory.
+func FormatLogDirReplicas(logDirs []LogDirInfo) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Path",
+			"Topic",
+			"Partition",
+			"Size",
+			"Offset Lag",
+			"Future",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, logDir := range logDirs {
+		for _, replica := range logDir.Replicas {
+			table.Append(
+				[]string{
+					fmt.Sprintf("%d", logDir.Broker),
+					logDir.Path,
+					replica.Topic,
+					fmt.Sprintf("%d", replica.Partition),
+					util.PrettyBytes(replica.Size),
+					fmt.Sprintf("%d", replica.OffsetLag),
+					fmt.Sprintf("%v", replica.IsFuture),
+				},
+			)
+		}
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// inProgressStr summarizes the partitions, by topic, that are part of an in-progress
+// reassignment or election.
+func inProgressStr(partitionsByTopic map[string]int) string {
+	if len(partitionsByTopic) == 0 {
+		return "none"
+	}
+
+	topics := []string{}
+	for topic := range partitionsByTopic {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	rows := []string{}
+	for _, topic := range topics {
+		rows = append(rows, fmt.Sprintf("%s (%d partitions)", topic, partitionsByTopic[topic]))
+	}
+
+	return strings.Join(rows, "\n")
+}
+
 func prettyConfig(config map[string]string) string {
 	rows := []string{}
 
@@ -877,3 +1876,54 @@ func maxMapValues(inputMap map[int]int) int {
 
 	return maxValue
 }
+
+// FormatUsers creates a pretty table that lists the SCRAM credentials of each user.
+func FormatUsers(users []UserInfo) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"User",
+			"Mechanism",
+			"Iterations",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, user := range users {
+		for c, credential := range user.Credentials {
+			// Only show the user name in the first row for each user
+			userName := ""
+			if c == 0 {
+				userName = user.Name
+			}
+
+			table.Append(
+				[]string{
+					userName,
+					string(credential.Mechanism),
+					fmt.Sprintf("%d", credential.Iterations),
+				},
+			)
+		}
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
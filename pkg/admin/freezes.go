@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// freezesPath is the zk path, relative to the cluster prefix, that stores the topics that
+// are currently frozen. Like the maintenance node, it's only used by topicctl.
+const freezesPath = "/topicctl/freezes"
+
+// TopicFreeze stores the details of a topic that's frozen in the cluster. While a topic is
+// frozen, apply skips it instead of making any changes.
+type TopicFreeze struct {
+	Topic     string    `json:"topic"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	Reason    string    `json:"reason,omitempty"`
+	StartTime time.Time `json:"startTime"`
+
+	// Expiry is the time at which the freeze stops applying. If it's zero, the freeze lasts
+	// until it's removed.
+	Expiry time.Time `json:"expiry"`
+}
+
+// NewTopicFreeze returns a TopicFreeze for the argument topic, with the user, host, and
+// start time filled in from the current environment. If the duration is zero, the freeze
+// doesn't expire.
+func NewTopicFreeze(topic string, reason string, duration time.Duration) TopicFreeze {
+	freeze := TopicFreeze{
+		Topic:     topic,
+		User:      "unknown",
+		Host:      "unknown",
+		Reason:    reason,
+		StartTime: time.Now().UTC(),
+	}
+	if duration > 0 {
+		freeze.Expiry = freeze.StartTime.Add(duration)
+	}
+
+	if currUser, err := user.Current(); err == nil {
+		freeze.User = currUser.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		freeze.Host = host
+	}
+
+	return freeze
+}
+
+// Active returns whether the freeze applies at the argument time.
+func (f TopicFreeze) Active(now time.Time) bool {
+	return f.Expiry.IsZero() || now.Before(f.Expiry)
+}
+
+type zkFreezes struct {
+	Version int                    `json:"version"`
+	Topics  map[string]TopicFreeze `json:"topics"`
+}
+
+// GetTopicFreezes returns the topics that are frozen in the cluster, including ones whose
+// freezes have expired, sorted by topic name.
+func (c *Client) GetTopicFreezes(ctx context.Context) ([]TopicFreeze, error) {
+	freezesObj, _, err := c.getFreezes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	freezes := []TopicFreeze{}
+	for _, freeze := range freezesObj.Topics {
+		freezes = append(freezes, freeze)
+	}
+	sort.Slice(freezes, func(a, b int) bool {
+		return freezes[a].Topic < freezes[b].Topic
+	})
+
+	return freezes, nil
+}
+
+// GetTopicFreeze returns the freeze for the argument topic, or nil if it isn't frozen in the
+// cluster.
+func (c *Client) GetTopicFreeze(ctx context.Context, topic string) (*TopicFreeze, error) {
+	freezesObj, _, err := c.getFreezes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	freeze, ok := freezesObj.Topics[topic]
+	if !ok {
+		return nil, nil
+	}
+	return &freeze, nil
+}
+
+// FreezeTopic records that the argument topic is frozen, replacing any existing freeze for
+// it.
+func (c *Client) FreezeTopic(ctx context.Context, freeze TopicFreeze) error {
+	if c.readOnly {
+		return errors.New("Cannot freeze topic in read-only mode")
+	}
+
+	freezesObj, version, err := c.getFreezes(ctx)
+	if err != nil {
+		return err
+	}
+	freezesObj.Topics[freeze.Topic] = freeze
+
+	return c.setFreezes(ctx, freezesObj, version)
+}
+
+// UnfreezeTopic removes the freeze for the argument topic. It returns an error if the topic
+// isn't frozen.
+func (c *Client) UnfreezeTopic(ctx context.Context, topic string) error {
+	if c.readOnly {
+		return errors.New("Cannot unfreeze topic in read-only mode")
+	}
+
+	freezesObj, version, err := c.getFreezes(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := freezesObj.Topics[topic]; !ok {
+		return fmt.Errorf("Topic %s is not frozen", topic)
+	}
+	delete(freezesObj.Topics, topic)
+
+	return c.setFreezes(ctx, freezesObj, version)
+}
+
+// getFreezes returns the current freezes along with the version of the zk node. If the node
+// doesn't exist, the version is -1.
+func (c *Client) getFreezes(ctx context.Context) (zkFreezes, int32, error) {
+	freezesObj := zkFreezes{
+		Version: 1,
+		Topics:  map[string]TopicFreeze{},
+	}
+	zPath := c.zNode(freezesPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return freezesObj, 0, err
+	}
+	if !exists {
+		return freezesObj, -1, nil
+	}
+
+	stats, err := c.zkClient.GetJSON(ctx, zPath, &freezesObj)
+	if err != nil {
+		return freezesObj, 0, err
+	}
+	if freezesObj.Topics == nil {
+		freezesObj.Topics = map[string]TopicFreeze{}
+	}
+
+	return freezesObj, stats.Version, nil
+}
+
+func (c *Client) setFreezes(
+	ctx context.Context,
+	freezesObj zkFreezes,
+	version int32,
+) error {
+	zPath := c.zNode(freezesPath)
+
+	if version >= 0 {
+		log.Debugf("Updating freezes at %s: %+v", zPath, freezesObj)
+		_, err := c.zkClient.SetJSON(ctx, zPath, freezesObj, version)
+		return err
+	}
+
+	// Parent might not already exist
+	zRoot := filepath.Dir(zPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zRoot)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Infof("Creating topicctl path: %s", zRoot)
+		if err := c.zkClient.Create(ctx, zRoot, nil, false); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Creating freezes at %s: %+v", zPath, freezesObj)
+	return c.zkClient.CreateJSON(ctx, zPath, freezesObj, false)
+}
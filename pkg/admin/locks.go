@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/segmentio/topicctl/pkg/zk"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetLocks returns the locks under the argument root path that are currently held, sorted by
+// path.
+func (c *Client) GetLocks(ctx context.Context, rootPath string) ([]zk.LockInfo, error) {
+	exists, _, err := c.zkClient.Exists(ctx, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []zk.LockInfo{}, nil
+	}
+
+	lockNames, _, err := c.zkClient.Children(ctx, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(lockNames)
+
+	locks := []zk.LockInfo{}
+
+	for _, lockName := range lockNames {
+		lockInfo, held, err := c.GetLock(ctx, filepath.Join(rootPath, lockName))
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			locks = append(locks, lockInfo)
+		}
+	}
+
+	return locks, nil
+}
+
+// GetLock returns the details of the lock at the argument path, along with whether it's
+// currently held.
+func (c *Client) GetLock(ctx context.Context, path string) (zk.LockInfo, bool, error) {
+	lockInfo := zk.LockInfo{
+		Path: path,
+	}
+
+	children, _, err := c.zkClient.Children(ctx, path)
+	if err != nil {
+		return lockInfo, false, err
+	}
+	if len(children) == 0 {
+		return lockInfo, false, nil
+	}
+
+	holder, err := zk.HolderNode(children)
+	if err != nil {
+		return lockInfo, false, err
+	}
+	lockInfo.HolderNode = filepath.Join(path, holder)
+	lockInfo.Waiters = len(children) - 1
+
+	data, _, err := c.zkClient.Get(ctx, lockInfo.HolderNode)
+	if err != nil {
+		return lockInfo, false, err
+	}
+	if len(data) > 0 {
+		metadata := zk.LockMetadata{}
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			log.Warnf("Could not parse metadata for lock %s: %+v", path, err)
+		} else {
+			lockInfo.Metadata = &metadata
+		}
+	}
+
+	return lockInfo, true, nil
+}
+
+// LockHolder returns the metadata of the process that holds the lock at the argument path,
+// along with whether the lock is held.
+func (c *Client) LockHolder(
+	ctx context.Context,
+	path string,
+) (*zk.LockMetadata, bool, error) {
+	held, err := c.LockHeld(ctx, path)
+	if err != nil || !held {
+		return nil, false, err
+	}
+
+	lockInfo, held, err := c.GetLock(ctx, path)
+	if err != nil {
+		return nil, false, err
+	}
+	return lockInfo.Metadata, held, nil
+}
+
+// ReleaseLock forcibly releases the lock at the argument path by deleting the node of its
+// current holder. This should only be used to clean up locks that were orphaned by processes
+// that are no longer running; if the holder is still running, it will continue as if it held
+// the lock.
+func (c *Client) ReleaseLock(ctx context.Context, lockInfo zk.LockInfo) error {
+	if c.readOnly {
+		return errors.New("Cannot release lock in read-only mode")
+	}
+	if lockInfo.HolderNode == "" {
+		return fmt.Errorf("Lock %s is not held", lockInfo.Path)
+	}
+
+	log.Infof("Deleting lock node %s", lockInfo.HolderNode)
+	return c.zkClient.Delete(ctx, lockInfo.HolderNode, -1)
+}
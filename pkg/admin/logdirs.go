@@ -0,0 +1,250 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the DescribeLogDirs API, so
+	// the (v0) requests are made via the protocol package instead.
+	describeLogDirsAPIKey     int16 = 35
+	describeLogDirsAPIVersion int16 = 0
+)
+
+// LogDirInfo represents a single log directory (i.e., disk path) on a broker and the
+// replicas stored in it.
+type LogDirInfo struct {
+	Broker   int
+	Path     string
+	Error    string
+	Replicas []LogDirReplica
+}
+
+// LogDirReplica represents a single partition replica in a log directory.
+type LogDirReplica struct {
+	Topic     string
+	Partition int
+	Size      int64
+	OffsetLag int64
+
+	// IsFuture is true if the replica is being moved into this log directory from another
+	// one on the same broker.
+	IsFuture bool
+}
+
+// TotalSize returns the sum of the sizes of all replicas in the log directory.
+func (l LogDirInfo) TotalSize() int64 {
+	var total int64
+	for _, replica := range l.Replicas {
+		total += replica.Size
+	}
+	return total
+}
+
+// LeastUsedLogDirs returns the path of the log directory with the smallest total size on
+// each broker, ignoring directories that returned errors. This can be used as a hint for
+// placing new replicas on brokers with multiple disks (i.e., JBOD setups).
+func LeastUsedLogDirs(logDirs []LogDirInfo) map[int]string {
+	paths := map[int]string{}
+	sizes := map[int]int64{}
+
+	for _, logDir := range logDirs {
+		if logDir.Error != "" {
+			continue
+		}
+		size := logDir.TotalSize()
+		currSize, ok := sizes[logDir.Broker]
+
+		if !ok || size < currSize ||
+			(size == currSize && logDir.Path < paths[logDir.Broker]) {
+			paths[logDir.Broker] = logDir.Path
+			sizes[logDir.Broker] = size
+		}
+	}
+
+	return paths
+}
+
+// LeaderReplicaSizes returns the size of the leader replica of each partition in the argument
+// topic, keyed by partition ID. Partitions whose leaders aren't in the argument log dirs
+// are omitted, as are future replicas that are still being moved between directories.
+func LeaderReplicaSizes(logDirs []LogDirInfo, topicInfo TopicInfo) map[int]int64 {
+	leaders := map[int]int{}
+	for _, partition := range topicInfo.Partitions {
+		leaders[partition.ID] = partition.Leader
+	}
+
+	sizes := map[int]int64{}
+
+	for _, logDir := range logDirs {
+		if logDir.Error != "" {
+			continue
+		}
+		for _, replica := range logDir.Replicas {
+			if replica.Topic != topicInfo.Name || replica.IsFuture {
+				continue
+			}
+			if leader, ok := leaders[replica.Partition]; ok && leader == logDir.Broker {
+				sizes[replica.Partition] = replica.Size
+			}
+		}
+	}
+
+	return sizes
+}
+
+// TopicReplicaSize returns the total size of all of the replicas of the argument topic in the
+// argument log dirs, excluding future replicas.
+func TopicReplicaSize(logDirs []LogDirInfo, topic string) int64 {
+	var size int64
+
+	for _, logDir := range logDirs {
+		if logDir.Error != "" {
+			continue
+		}
+		for _, replica := range logDir.Replicas {
+			if replica.Topic == topic && !replica.IsFuture {
+				size += replica.Size
+			}
+		}
+	}
+
+	return size
+}
+
+// GetLogDirs gets the log directories of each argument broker, along with the replicas
+// stored in each one. The results are sorted by broker ID and then path.
+func (c *Client) GetLogDirs(
+	ctx context.Context,
+	brokers []BrokerInfo,
+) ([]LogDirInfo, error) {
+	if err := c.CheckFeature(ctx, FeatureDescribeLogDirs); err != nil {
+		return nil, err
+	}
+
+	logDirs := []LogDirInfo{}
+	var mutex sync.Mutex
+
+	err := util.RunParallel(ctx, len(brokers), maxPoolSize, func(ctx context.Context, i int) error {
+		var response *protocol.Decoder
+		err := c.withBrokerRetries(ctx, "describe log dirs", false, func() error {
+			var err error
+			response, err = protocol.RoundTrip(
+				ctx,
+				brokers[i].Addr(),
+				describeLogDirsAPIKey,
+				describeLogDirsAPIVersion,
+				encodeDescribeLogDirsRequest(),
+			)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf(
+				"Error getting log dirs from broker %d: %+v",
+				brokers[i].ID,
+				err,
+			)
+		}
+
+		brokerLogDirs, err := decodeDescribeLogDirsResponse(response, brokers[i].ID)
+		if err != nil {
+			return fmt.Errorf(
+				"Error decoding log dirs from broker %d: %+v",
+				brokers[i].ID,
+				err,
+			)
+		}
+
+		mutex.Lock()
+		logDirs = append(logDirs, brokerLogDirs...)
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(logDirs, func(a, b int) bool {
+		if logDirs[a].Broker != logDirs[b].Broker {
+			return logDirs[a].Broker < logDirs[b].Broker
+		}
+		return logDirs[a].Path < logDirs[b].Path
+	})
+
+	return logDirs, nil
+}
+
+// encodeDescribeLogDirsRequest encodes the body of a v0 DescribeLogDirs request for all
+// topics.
+func encodeDescribeLogDirsRequest() *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	// A null topics array requests all topics
+	body.WriteInt32(-1)
+
+	return body
+}
+
+// decodeDescribeLogDirsResponse decodes the body of a v0 DescribeLogDirs response from the
+// argument broker. The replicas in each log dir are sorted by topic and then partition.
+func decodeDescribeLogDirsResponse(
+	response *protocol.Decoder,
+	broker int,
+) ([]LogDirInfo, error) {
+	logDirs := []LogDirInfo{}
+
+	// Throttle time
+	response.ReadInt32()
+
+	numResults := response.ReadInt32()
+	for i := 0; i < int(numResults) && response.Err() == nil; i++ {
+		errorCode := response.ReadInt16()
+		logDir := LogDirInfo{
+			Broker:   broker,
+			Path:     response.ReadString(),
+			Replicas: []LogDirReplica{},
+		}
+		if errorCode != 0 {
+			logDir.Error = kafka.Error(errorCode).Error()
+		}
+
+		numTopics := response.ReadInt32()
+		for j := 0; j < int(numTopics) && response.Err() == nil; j++ {
+			topic := response.ReadString()
+			numPartitions := response.ReadInt32()
+
+			for k := 0; k < int(numPartitions) && response.Err() == nil; k++ {
+				logDir.Replicas = append(
+					logDir.Replicas,
+					LogDirReplica{
+						Topic:     topic,
+						Partition: int(response.ReadInt32()),
+						Size:      response.ReadInt64(),
+						OffsetLag: response.ReadInt64(),
+						IsFuture:  response.ReadBool(),
+					},
+				)
+			}
+		}
+
+		sort.Slice(logDir.Replicas, func(a, b int) bool {
+			if logDir.Replicas[a].Topic != logDir.Replicas[b].Topic {
+				return logDir.Replicas[a].Topic < logDir.Replicas[b].Topic
+			}
+			return logDir.Replicas[a].Partition < logDir.Replicas[b].Partition
+		})
+		logDirs = append(logDirs, logDir)
+	}
+
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return logDirs, nil
+}
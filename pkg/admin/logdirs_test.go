@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogDirs(t *testing.T) {
+	ctx := context.Background()
+	util.SkipIfTestAPIUnsupported(ctx, t, describeLogDirsAPIKey)
+
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       "",
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       true,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	require.Nil(t, err)
+
+	logDirs, err := adminClient.GetLogDirs(ctx, brokers)
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, len(logDirs), len(brokers))
+
+	for _, logDir := range logDirs {
+		assert.NotEqual(t, "", logDir.Path)
+		assert.Equal(t, "", logDir.Error)
+	}
+	assert.Equal(t, len(brokers), len(LeastUsedLogDirs(logDirs)))
+}
+
+func TestEncodeDescribeLogDirsRequest(t *testing.T) {
+	expected := &protocol.Encoder{}
+	expected.WriteInt32(-1)
+
+	assert.Equal(t, expected.Bytes(), encodeDescribeLogDirsRequest().Bytes())
+}
+
+func TestDecodeDescribeLogDirsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt32(2)
+
+	response.WriteInt16(0)
+	response.WriteString("/data/kafka1")
+	response.WriteInt32(1)
+	response.WriteString("test-topic")
+	response.WriteInt32(2)
+	response.WriteInt32(3)
+	response.WriteInt64(1000)
+	response.WriteInt64(0)
+	response.WriteBool(false)
+	response.WriteInt32(1)
+	response.WriteInt64(2000)
+	response.WriteInt64(5)
+	response.WriteBool(true)
+
+	response.WriteInt16(56)
+	response.WriteString("/data/kafka2")
+	response.WriteInt32(0)
+
+	logDirs, err := decodeDescribeLogDirsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		4,
+	)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(logDirs))
+
+	assert.Equal(
+		t,
+		LogDirInfo{
+			Broker: 4,
+			Path:   "/data/kafka1",
+			Replicas: []LogDirReplica{
+				{
+					Topic:     "test-topic",
+					Partition: 1,
+					Size:      2000,
+					OffsetLag: 5,
+					IsFuture:  true,
+				},
+				{
+					Topic:     "test-topic",
+					Partition: 3,
+					Size:      1000,
+					OffsetLag: 0,
+					IsFuture:  false,
+				},
+			},
+		},
+		logDirs[0],
+	)
+	assert.Equal(t, int64(3000), logDirs[0].TotalSize())
+	assert.Equal(t, "/data/kafka2", logDirs[1].Path)
+	assert.NotEqual(t, "", logDirs[1].Error)
+	assert.Equal(t, 0, len(logDirs[1].Replicas))
+
+	_, err = decodeDescribeLogDirsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes()[:20])),
+		4,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestLeastUsedLogDirs(t *testing.T) {
+	logDirs := []LogDirInfo{
+		{
+			Broker:   1,
+			Path:     "/data/a",
+			Replicas: []LogDirReplica{{Size: 100}, {Size: 200}},
+		},
+		{
+			Broker:   1,
+			Path:     "/data/b",
+			Replicas: []LogDirReplica{{Size: 250}},
+		},
+		{
+			Broker: 1,
+			Path:   "/data/c",
+			Error:  "KafkaStorageException",
+		},
+		{
+			Broker:   2,
+			Path:     "/data/b",
+			Replicas: []LogDirReplica{{Size: 10}},
+		},
+		{
+			Broker:   2,
+			Path:     "/data/a",
+			Replicas: []LogDirReplica{{Size: 10}},
+		},
+	}
+
+	assert.Equal(
+		t,
+		map[int]string{
+			1: "/data/b",
+			2: "/data/a",
+		},
+		LeastUsedLogDirs(logDirs),
+	)
+}
+
+func TestLeaderReplicaSizes(t *testing.T) {
+	topicInfo := TopicInfo{
+		Name: "topic1",
+		Partitions: []PartitionInfo{
+			{ID: 0, Leader: 1},
+			{ID: 1, Leader: 2},
+			{ID: 2, Leader: 3},
+		},
+	}
+	logDirs := []LogDirInfo{
+		{
+			Broker: 1,
+			Path:   "/data/a",
+			Replicas: []LogDirReplica{
+				{Topic: "topic1", Partition: 0, Size: 100},
+				{Topic: "topic1", Partition: 1, Size: 90},
+				{Topic: "topic2", Partition: 0, Size: 500},
+			},
+		},
+		{
+			Broker: 2,
+			Path:   "/data/a",
+			Replicas: []LogDirReplica{
+				{Topic: "topic1", Partition: 0, Size: 95},
+				{Topic: "topic1", Partition: 1, Size: 110},
+			},
+		},
+		{
+			Broker: 2,
+			Path:   "/data/b",
+			Replicas: []LogDirReplica{
+				{Topic: "topic1", Partition: 1, Size: 20, IsFuture: true},
+			},
+		},
+	}
+
+	assert.Equal(
+		t,
+		map[int]int64{0: 100, 1: 110},
+		LeaderReplicaSizes(logDirs, topicInfo),
+	)
+	assert.Equal(t, int64(395), TopicReplicaSize(logDirs, "topic1"))
+	assert.Equal(t, int64(500), TopicReplicaSize(logDirs, "topic2"))
+	assert.Equal(t, int64(0), TopicReplicaSize(logDirs, "topic3"))
+}
@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maintenancePath is the zk path, relative to the cluster prefix, that stores the brokers
+// that are currently in maintenance mode. Kafka doesn't know anything about this node; it's
+// only used by topicctl.
+const maintenancePath = "/topicctl/maintenance"
+
+// BrokerMaintenance stores the details of a broker that's in maintenance mode. While a
+// broker is in maintenance, topicctl moves leadership away from it and avoids making it
+// the preferred leader of any partitions.
+type BrokerMaintenance struct {
+	BrokerID  int       `json:"brokerID"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	Reason    string    `json:"reason,omitempty"`
+	StartTime time.Time `json:"startTime"`
+
+	// MovedLeaders contains the partitions, keyed by topic, whose preferred leader was
+	// moved off of the broker when maintenance started. These are restored when
+	// maintenance ends.
+	MovedLeaders map[string][]int `json:"movedLeaders,omitempty"`
+}
+
+type zkMaintenance struct {
+	Version int                          `json:"version"`
+	Brokers map[string]BrokerMaintenance `json:"brokers"`
+}
+
+// GetBrokersInMaintenance returns the brokers that are currently in maintenance mode,
+// sorted by ID.
+func (c *Client) GetBrokersInMaintenance(
+	ctx context.Context,
+) ([]BrokerMaintenance, error) {
+	maintenanceObj, _, err := c.getMaintenance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenances := []BrokerMaintenance{}
+	for _, maintenance := range maintenanceObj.Brokers {
+		maintenances = append(maintenances, maintenance)
+	}
+	sort.Slice(maintenances, func(a, b int) bool {
+		return maintenances[a].BrokerID < maintenances[b].BrokerID
+	})
+
+	return maintenances, nil
+}
+
+// StartBrokerMaintenance records that the argument broker is in maintenance mode. It
+// returns an error if the broker is already in maintenance.
+func (c *Client) StartBrokerMaintenance(
+	ctx context.Context,
+	maintenance BrokerMaintenance,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot start broker maintenance in read-only mode")
+	}
+
+	maintenanceObj, version, err := c.getMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+
+	idStr := fmt.Sprintf("%d", maintenance.BrokerID)
+	if _, ok := maintenanceObj.Brokers[idStr]; ok {
+		return fmt.Errorf("Broker %d is already in maintenance", maintenance.BrokerID)
+	}
+	maintenanceObj.Brokers[idStr] = maintenance
+
+	return c.setMaintenance(ctx, maintenanceObj, version)
+}
+
+// EndBrokerMaintenance removes the maintenance record for the argument broker. It returns
+// an error if the broker isn't in maintenance.
+func (c *Client) EndBrokerMaintenance(
+	ctx context.Context,
+	brokerID int,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot end broker maintenance in read-only mode")
+	}
+
+	maintenanceObj, version, err := c.getMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+
+	idStr := fmt.Sprintf("%d", brokerID)
+	if _, ok := maintenanceObj.Brokers[idStr]; !ok {
+		return fmt.Errorf("Broker %d is not in maintenance", brokerID)
+	}
+	delete(maintenanceObj.Brokers, idStr)
+
+	return c.setMaintenance(ctx, maintenanceObj, version)
+}
+
+// MaintenanceBrokerIDs returns the IDs of the brokers in the argument maintenance records.
+func MaintenanceBrokerIDs(maintenances []BrokerMaintenance) []int {
+	brokerIDs := []int{}
+	for _, maintenance := range maintenances {
+		brokerIDs = append(brokerIDs, maintenance.BrokerID)
+	}
+	return brokerIDs
+}
+
+// getMaintenance returns the current maintenance state along with the version of the
+// zk node. If the node doesn't exist, the version is -1.
+func (c *Client) getMaintenance(ctx context.Context) (zkMaintenance, int32, error) {
+	maintenanceObj := zkMaintenance{
+		Version: 1,
+		Brokers: map[string]BrokerMaintenance{},
+	}
+	zPath := c.zNode(maintenancePath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return maintenanceObj, 0, err
+	}
+	if !exists {
+		return maintenanceObj, -1, nil
+	}
+
+	stats, err := c.zkClient.GetJSON(ctx, zPath, &maintenanceObj)
+	if err != nil {
+		return maintenanceObj, 0, err
+	}
+	if maintenanceObj.Brokers == nil {
+		maintenanceObj.Brokers = map[string]BrokerMaintenance{}
+	}
+
+	return maintenanceObj, stats.Version, nil
+}
+
+func (c *Client) setMaintenance(
+	ctx context.Context,
+	maintenanceObj zkMaintenance,
+	version int32,
+) error {
+	zPath := c.zNode(maintenancePath)
+
+	if version >= 0 {
+		log.Debugf("Updating maintenance state at %s: %+v", zPath, maintenanceObj)
+		_, err := c.zkClient.SetJSON(ctx, zPath, maintenanceObj, version)
+		return err
+	}
+
+	// Parent might not already exist
+	zRoot := filepath.Dir(zPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zRoot)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Infof("Creating topicctl path: %s", zRoot)
+		if err := c.zkClient.Create(ctx, zRoot, nil, false); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Creating maintenance state at %s: %+v", zPath, maintenanceObj)
+	return c.zkClient.CreateJSON(ctx, zPath, maintenanceObj, false)
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const minISRKey = "min.insync.replicas"
+
+// MinISRStatus describes how the min.insync.replicas setting of a topic compares to its
+// replication factor, the cluster default, and the current in-sync replicas of its
+// partitions.
+type MinISRStatus struct {
+	Topic             string
+	MinISR            int
+	Source            ConfigSource
+	ClusterDefault    int
+	ReplicationFactor int
+
+	// Problems contains descriptions of the ways in which the setting is incompatible with
+	// the topic's replication factor or the cluster default.
+	Problems []string
+
+	// BlockedPartitions are the partitions that currently have fewer in-sync replicas than
+	// MinISR. Producers using acks=all can't write to these until their replicas catch up.
+	BlockedPartitions []PartitionInfo
+}
+
+// OK returns whether no problems or blocked partitions were found.
+func (s MinISRStatus) OK() bool {
+	return len(s.Problems) == 0 && len(s.BlockedPartitions) == 0
+}
+
+// Summary returns a one-line description of the problems and blocked partitions, if any.
+func (s MinISRStatus) Summary() string {
+	descriptions := append([]string{}, s.Problems...)
+
+	if len(s.BlockedPartitions) > 0 {
+		descriptions = append(
+			descriptions,
+			fmt.Sprintf(
+				"%d partition(s) have fewer in-sync replicas than min.insync.replicas (%d), so producers using acks=all are blocked: %+v",
+				len(s.BlockedPartitions),
+				s.MinISR,
+				PartitionIDs(s.BlockedPartitions),
+			),
+		)
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// GetMinISRStatus checks the effective min.insync.replicas of the argument topic, which
+// should have been fetched with detailed set to true so that its ISRs are filled in. The
+// cluster defaults are the dynamic broker defaults from GetClusterDefaultConfig.
+func GetMinISRStatus(
+	topicInfo TopicInfo,
+	clusterDefaults map[string]string,
+) (MinISRStatus, error) {
+	status := MinISRStatus{
+		Topic:             topicInfo.Name,
+		ReplicationFactor: topicInfo.MaxReplication(),
+		Problems:          []string{},
+		BlockedPartitions: []PartitionInfo{},
+	}
+
+	var err error
+
+	minISRStr := EffectiveTopicConfigValue(minISRKey, topicInfo.Config, clusterDefaults)
+	status.MinISR, err = strconv.Atoi(minISRStr)
+	if err != nil {
+		return status, fmt.Errorf(
+			"Could not parse min.insync.replicas value %s for topic %s: %+v",
+			minISRStr,
+			topicInfo.Name,
+			err,
+		)
+	}
+
+	clusterDefaultStr := EffectiveTopicConfigValue(minISRKey, nil, clusterDefaults)
+	status.ClusterDefault, err = strconv.Atoi(clusterDefaultStr)
+	if err != nil {
+		return status, fmt.Errorf(
+			"Could not parse cluster default min.insync.replicas value %s: %+v",
+			clusterDefaultStr,
+			err,
+		)
+	}
+
+	if _, ok := topicInfo.Config[minISRKey]; ok {
+		status.Source = ConfigSourceTopic
+	} else if clusterDefaults[minISRKey] != "" {
+		status.Source = ConfigSourceClusterDefault
+	} else {
+		status.Source = ConfigSourceKafkaDefault
+	}
+
+	switch {
+	case status.MinISR < 1:
+		status.Problems = append(
+			status.Problems,
+			fmt.Sprintf("min.insync.replicas (%d) must be at least 1", status.MinISR),
+		)
+	case status.MinISR > status.ReplicationFactor:
+		status.Problems = append(
+			status.Problems,
+			fmt.Sprintf(
+				"min.insync.replicas (%d, from %s) is greater than the replication factor (%d); producers using acks=all will always fail",
+				status.MinISR,
+				status.Source,
+				status.ReplicationFactor,
+			),
+		)
+	case status.MinISR == status.ReplicationFactor && status.ReplicationFactor > 1:
+		status.Problems = append(
+			status.Problems,
+			fmt.Sprintf(
+				"min.insync.replicas (%d, from %s) is equal to the replication factor; producers using acks=all will fail if any replica is down",
+				status.MinISR,
+				status.Source,
+			),
+		)
+	}
+
+	if status.Source == ConfigSourceTopic && status.MinISR < status.ClusterDefault &&
+		status.ClusterDefault <= status.ReplicationFactor {
+		status.Problems = append(
+			status.Problems,
+			fmt.Sprintf(
+				"min.insync.replicas (%d) is below the cluster default (%d)",
+				status.MinISR,
+				status.ClusterDefault,
+			),
+		)
+	}
+
+	for _, partition := range topicInfo.Partitions {
+		if len(partition.ISR) < status.MinISR {
+			status.BlockedPartitions = append(status.BlockedPartitions, partition)
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMinISRStatus(t *testing.T) {
+	partitions := []PartitionInfo{
+		{ID: 0, Replicas: []int{1, 2, 3}, ISR: []int{1, 2, 3}},
+		{ID: 1, Replicas: []int{2, 3, 4}, ISR: []int{2}},
+	}
+
+	type minISRTestCase struct {
+		description     string
+		config          map[string]string
+		clusterDefaults map[string]string
+		expectedMinISR  int
+		expectedSource  ConfigSource
+		expectedBlocked []int
+		expectedNumErrs int
+	}
+
+	testCases := []minISRTestCase{
+		{
+			description:     "kafka default",
+			expectedMinISR:  1,
+			expectedSource:  ConfigSourceKafkaDefault,
+			expectedBlocked: []int{},
+		},
+		{
+			description: "cluster default",
+			clusterDefaults: map[string]string{
+				"min.insync.replicas": "2",
+			},
+			expectedMinISR:  2,
+			expectedSource:  ConfigSourceClusterDefault,
+			expectedBlocked: []int{1},
+		},
+		{
+			description: "equal to replication factor",
+			config: map[string]string{
+				"min.insync.replicas": "3",
+			},
+			expectedMinISR:  3,
+			expectedSource:  ConfigSourceTopic,
+			expectedBlocked: []int{1},
+			expectedNumErrs: 1,
+		},
+		{
+			description: "above replication factor",
+			clusterDefaults: map[string]string{
+				"min.insync.replicas": "4",
+			},
+			expectedMinISR:  4,
+			expectedSource:  ConfigSourceClusterDefault,
+			expectedBlocked: []int{0, 1},
+			expectedNumErrs: 1,
+		},
+		{
+			description: "below cluster default",
+			config: map[string]string{
+				"min.insync.replicas": "1",
+			},
+			clusterDefaults: map[string]string{
+				"min.insync.replicas": "2",
+			},
+			expectedMinISR:  1,
+			expectedSource:  ConfigSourceTopic,
+			expectedBlocked: []int{},
+			expectedNumErrs: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		status, err := GetMinISRStatus(
+			TopicInfo{
+				Name:       "test-topic",
+				Config:     testCase.config,
+				Partitions: partitions,
+			},
+			testCase.clusterDefaults,
+		)
+		require.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.expectedMinISR, status.MinISR, testCase.description)
+		assert.Equal(t, testCase.expectedSource, status.Source, testCase.description)
+		assert.Equal(t, 3, status.ReplicationFactor, testCase.description)
+		assert.Equal(
+			t,
+			testCase.expectedBlocked,
+			PartitionIDs(status.BlockedPartitions),
+			testCase.description,
+		)
+		assert.Equal(t, testCase.expectedNumErrs, len(status.Problems), testCase.description)
+		assert.Equal(
+			t,
+			testCase.expectedNumErrs == 0 && len(testCase.expectedBlocked) == 0,
+			status.OK(),
+			testCase.description,
+		)
+	}
+
+	_, err := GetMinISRStatus(
+		TopicInfo{
+			Name: "test-topic",
+			Config: map[string]string{
+				"min.insync.replicas": "two",
+			},
+		},
+		nil,
+	)
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"sort"
+)
+
+// PartitionFilter is used to select a subset of the partitions in a topic. Empty fields
+// are ignored; partitions must match all of the non-empty ones.
+type PartitionFilter struct {
+	// LeaderBrokers selects partitions whose leader is one of these brokers.
+	LeaderBrokers []int
+
+	// ReplicaBrokers selects partitions that have a replica on one of these brokers.
+	ReplicaBrokers []int
+
+	// Racks selects partitions that have a replica in one of these racks.
+	Racks []string
+
+	// UnderReplicated selects partitions with fewer in-sync replicas than replicas.
+	UnderReplicated bool
+
+	// MaxISR selects partitions with at most this many in-sync replicas if greater than 0.
+	MaxISR int
+}
+
+// IsEmpty returns whether the filter has no criteria set.
+func (f PartitionFilter) IsEmpty() bool {
+	return len(f.LeaderBrokers) == 0 &&
+		len(f.ReplicaBrokers) == 0 &&
+		len(f.Racks) == 0 &&
+		!f.UnderReplicated &&
+		f.MaxISR <= 0
+}
+
+// Matches returns whether the argument partition matches the filter.
+func (f PartitionFilter) Matches(partition PartitionInfo, brokerRacks map[int]string) bool {
+	if len(f.LeaderBrokers) > 0 && !intInSlice(partition.Leader, f.LeaderBrokers) {
+		return false
+	}
+
+	if len(f.ReplicaBrokers) > 0 {
+		found := false
+		for _, replica := range partition.Replicas {
+			if intInSlice(replica, f.ReplicaBrokers) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(f.Racks) > 0 {
+		found := false
+		for _, replica := range partition.Replicas {
+			rack, ok := brokerRacks[replica]
+			if ok && stringInSlice(rack, f.Racks) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.UnderReplicated && len(partition.ISR) >= len(partition.Replicas) {
+		return false
+	}
+
+	if f.MaxISR > 0 && len(partition.ISR) > f.MaxISR {
+		return false
+	}
+
+	return true
+}
+
+// FilterPartitions returns the partitions that match the argument filter.
+func FilterPartitions(
+	partitions []PartitionInfo,
+	brokerRacks map[int]string,
+	filter PartitionFilter,
+) []PartitionInfo {
+	filtered := []PartitionInfo{}
+
+	for _, partition := range partitions {
+		if filter.Matches(partition, brokerRacks) {
+			filtered = append(filtered, partition)
+		}
+	}
+
+	return filtered
+}
+
+// BrokerPartitionSummary aggregates the partitions in a topic for a single broker.
+type BrokerPartitionSummary struct {
+	Broker          int
+	Rack            string
+	Leaders         int
+	Replicas        int
+	OutOfSync       int
+	UnderReplicated int
+}
+
+// SummarizePartitionsByBroker aggregates the argument partitions by broker. All of the
+// argument brokers are included, even if they don't have any replicas.
+func SummarizePartitionsByBroker(
+	partitions []PartitionInfo,
+	brokers []BrokerInfo,
+) []BrokerPartitionSummary {
+	summariesMap := map[int]*BrokerPartitionSummary{}
+
+	getSummary := func(broker int) *BrokerPartitionSummary {
+		summary, ok := summariesMap[broker]
+		if !ok {
+			summary = &BrokerPartitionSummary{Broker: broker}
+			summariesMap[broker] = summary
+		}
+		return summary
+	}
+
+	for _, broker := range brokers {
+		getSummary(broker.ID).Rack = broker.Rack
+	}
+
+	for _, partition := range partitions {
+		underReplicated := len(partition.ISR) < len(partition.Replicas)
+
+		for _, replica := range partition.Replicas {
+			summary := getSummary(replica)
+			summary.Replicas++
+
+			if replica == partition.Leader {
+				summary.Leaders++
+			}
+			if !intInSlice(replica, partition.ISR) {
+				summary.OutOfSync++
+			}
+			if underReplicated {
+				summary.UnderReplicated++
+			}
+		}
+	}
+
+	summaries := []BrokerPartitionSummary{}
+	for _, summary := range summariesMap {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(a, b int) bool {
+		return summaries[a].Broker < summaries[b].Broker
+	})
+
+	return summaries
+}
+
+func intInSlice(value int, values []int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func stringInSlice(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterPartitions(t *testing.T) {
+	partitions := []PartitionInfo{
+		{
+			ID:       0,
+			Leader:   1,
+			Replicas: []int{1, 2, 3},
+			ISR:      []int{1, 2, 3},
+		},
+		{
+			ID:       1,
+			Leader:   2,
+			Replicas: []int{2, 3, 4},
+			ISR:      []int{2, 4},
+		},
+		{
+			ID:       2,
+			Leader:   3,
+			Replicas: []int{3, 4, 1},
+			ISR:      []int{3},
+		},
+	}
+	brokerRacks := map[int]string{
+		1: "rack1",
+		2: "rack1",
+		3: "rack2",
+		4: "rack3",
+	}
+
+	type testCase struct {
+		description string
+		filter      PartitionFilter
+		expIDs      []int
+	}
+
+	testCases := []testCase{
+		{
+			description: "empty filter",
+			filter:      PartitionFilter{},
+			expIDs:      []int{0, 1, 2},
+		},
+		{
+			description: "leaders",
+			filter: PartitionFilter{
+				LeaderBrokers: []int{1, 3},
+			},
+			expIDs: []int{0, 2},
+		},
+		{
+			description: "replicas",
+			filter: PartitionFilter{
+				ReplicaBrokers: []int{4},
+			},
+			expIDs: []int{1, 2},
+		},
+		{
+			description: "racks",
+			filter: PartitionFilter{
+				Racks: []string{"rack3"},
+			},
+			expIDs: []int{1, 2},
+		},
+		{
+			description: "under-replicated",
+			filter: PartitionFilter{
+				UnderReplicated: true,
+			},
+			expIDs: []int{1, 2},
+		},
+		{
+			description: "max ISR",
+			filter: PartitionFilter{
+				MaxISR: 1,
+			},
+			expIDs: []int{2},
+		},
+		{
+			description: "multiple criteria",
+			filter: PartitionFilter{
+				LeaderBrokers:   []int{1, 2},
+				UnderReplicated: true,
+			},
+			expIDs: []int{1},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(
+			t,
+			testCase.expIDs,
+			PartitionIDs(FilterPartitions(partitions, brokerRacks, testCase.filter)),
+			testCase.description,
+		)
+		assert.Equal(
+			t,
+			testCase.description == "empty filter",
+			testCase.filter.IsEmpty(),
+			testCase.description,
+		)
+	}
+}
+
+func TestSummarizePartitionsByBroker(t *testing.T) {
+	partitions := []PartitionInfo{
+		{
+			ID:       0,
+			Leader:   1,
+			Replicas: []int{1, 2},
+			ISR:      []int{1, 2},
+		},
+		{
+			ID:       1,
+			Leader:   2,
+			Replicas: []int{2, 3},
+			ISR:      []int{2},
+		},
+	}
+	brokers := []BrokerInfo{
+		{
+			ID:   1,
+			Rack: "rack1",
+		},
+		{
+			ID:   2,
+			Rack: "rack2",
+		},
+		{
+			ID:   3,
+			Rack: "rack3",
+		},
+		{
+			ID:   4,
+			Rack: "rack4",
+		},
+	}
+
+	assert.Equal(
+		t,
+		[]BrokerPartitionSummary{
+			{
+				Broker:   1,
+				Rack:     "rack1",
+				Leaders:  1,
+				Replicas: 1,
+			},
+			{
+				Broker:          2,
+				Rack:            "rack2",
+				Leaders:         1,
+				Replicas:        2,
+				UnderReplicated: 1,
+			},
+			{
+				Broker:          3,
+				Rack:            "rack3",
+				Replicas:        1,
+				OutOfSync:       1,
+				UnderReplicated: 1,
+			},
+			{
+				Broker: 4,
+				Rack:   "rack4",
+			},
+		},
+		SummarizePartitionsByBroker(partitions, brokers),
+	)
+}
@@ -0,0 +1,229 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ProducerByteRateKey is the quota key for the produce bandwidth limit (bytes/sec).
+	ProducerByteRateKey = "producer_byte_rate"
+
+	// ConsumerByteRateKey is the quota key for the fetch bandwidth limit (bytes/sec).
+	ConsumerByteRateKey = "consumer_byte_rate"
+
+	// RequestPercentageKey is the quota key for the request handler time limit, as a
+	// percentage of a single network or I/O thread.
+	RequestPercentageKey = "request_percentage"
+
+	// AnonymousUser is the principal that Kafka assigns to unauthenticated connections.
+	AnonymousUser = "ANONYMOUS"
+
+	defaultQuotaEntity = "<default>"
+)
+
+// QuotaKeys contains all of the client quota keys, in display order.
+var QuotaKeys = []string{
+	ProducerByteRateKey,
+	ConsumerByteRateKey,
+	RequestPercentageKey,
+}
+
+// ClientQuota represents the effective value of a single quota for a client, along
+// with the zookeeper entity path that it was resolved from.
+type ClientQuota struct {
+	Key        string
+	Value      string
+	EntityPath string
+}
+
+// QuotaEntityPaths returns the config entity paths that apply to the argument user and
+// client ID, in the order of precedence that Kafka uses when resolving quotas.
+func QuotaEntityPaths(user string, clientID string) []string {
+	sanitizedUser := sanitizeEntityName(user)
+	sanitizedClientID := sanitizeEntityName(clientID)
+
+	return []string{
+		filepath.Join("users", sanitizedUser, "clients", sanitizedClientID),
+		filepath.Join("users", sanitizedUser, "clients", defaultQuotaEntity),
+		filepath.Join("users", sanitizedUser),
+		filepath.Join("users", defaultQuotaEntity, "clients", sanitizedClientID),
+		filepath.Join("users", defaultQuotaEntity, "clients", defaultQuotaEntity),
+		filepath.Join("users", defaultQuotaEntity),
+		filepath.Join("clients", sanitizedClientID),
+		filepath.Join("clients", defaultQuotaEntity),
+	}
+}
+
+// ResolveClientQuotas determines the effective quotas given the configs stored at each
+// of the argument entity paths. Each quota key is resolved independently using the first
+// path (in the argument order) that sets it. Keys that aren't set anywhere are omitted.
+func ResolveClientQuotas(
+	entityPaths []string,
+	entityConfigs map[string]map[string]string,
+) []ClientQuota {
+	quotas := []ClientQuota{}
+
+	for _, key := range QuotaKeys {
+		for _, entityPath := range entityPaths {
+			value, ok := entityConfigs[entityPath][key]
+			if !ok {
+				continue
+			}
+
+			quotas = append(
+				quotas,
+				ClientQuota{
+					Key:        key,
+					Value:      value,
+					EntityPath: entityPath,
+				},
+			)
+			break
+		}
+	}
+
+	return quotas
+}
+
+// GetClientQuotas returns the effective quotas that apply to connections from the argument
+// user and client ID. The result is empty if no quotas apply.
+func (c *Client) GetClientQuotas(
+	ctx context.Context,
+	user string,
+	clientID string,
+) ([]ClientQuota, error) {
+	entityPaths := QuotaEntityPaths(user, clientID)
+	entityConfigs := map[string]map[string]string{}
+
+	for _, entityPath := range entityPaths {
+		zPath := c.zNode("config", entityPath)
+
+		exists, _, err := c.zkClient.Exists(ctx, zPath)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		zkEntityConfig := zkBrokerConfig{}
+		_, err = c.zkClient.GetJSON(ctx, zPath, &zkEntityConfig)
+		if err != nil {
+			return nil, err
+		}
+		entityConfigs[entityPath] = zkEntityConfig.Config
+	}
+
+	return ResolveClientQuotas(entityPaths, entityConfigs), nil
+}
+
+// GetQuotaEntityConfig returns the quota config that's set at the argument entity path, e.g.
+// one from a ClientQuota, i.e. not inherited from any other entities.
+func (c *Client) GetQuotaEntityConfig(
+	ctx context.Context,
+	entityPath string,
+) (map[string]string, error) {
+	if _, _, err := ParseQuotaEntityPath(entityPath); err != nil {
+		return nil, err
+	}
+	zPath := c.zNode("config", entityPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return map[string]string{}, nil
+	}
+
+	zkEntityConfig := zkBrokerConfig{}
+	_, err = c.zkClient.GetJSON(ctx, zPath, &zkEntityConfig)
+	if err != nil {
+		return nil, err
+	}
+	if zkEntityConfig.Config == nil {
+		return map[string]string{}, nil
+	}
+
+	return zkEntityConfig.Config, nil
+}
+
+// UpdateQuotaEntityConfig updates the quota config at the argument entity path and sets a
+// change notification so the brokers are notified. Entries with blank values are removed.
+// The overwrite flag and return values have the same semantics as in UpdateBrokerConfig.
+func (c *Client) UpdateQuotaEntityConfig(
+	ctx context.Context,
+	entityPath string,
+	configEntries []kafka.ConfigEntry,
+	overwrite bool,
+) ([]string, error) {
+	if c.readOnly {
+		return nil, errors.New("Cannot update client quotas in read-only mode")
+	}
+	entityType, entityName, err := ParseQuotaEntityPath(entityPath)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Updating quotas for %s", entityPath)
+
+	return c.updateEntityConfig(
+		ctx,
+		entityType,
+		entityName,
+		configEntries,
+		overwrite,
+	)
+}
+
+// ParseQuotaEntityPath splits a quota entity path, as returned by QuotaEntityPaths, into
+// the entity type and the (possibly nested) entity name used in zookeeper. It returns an
+// error if the path isn't one of the forms that Kafka resolves quotas from.
+func ParseQuotaEntityPath(entityPath string) (string, string, error) {
+	elements := strings.Split(entityPath, "/")
+	for _, element := range elements {
+		if element == "" {
+			return "", "", fmt.Errorf("Invalid quota entity path: '%s'", entityPath)
+		}
+	}
+
+	switch {
+	case len(elements) == 2 && (elements[0] == "users" || elements[0] == "clients"):
+		return elements[0], elements[1], nil
+	case len(elements) == 4 && elements[0] == "users" && elements[2] == "clients":
+		return elements[0], strings.Join(elements[1:], "/"), nil
+	default:
+		return "", "", fmt.Errorf("Invalid quota entity path: '%s'", entityPath)
+	}
+}
+
+// IsDefaultQuotaEntityPath returns whether the argument entity path is for a default
+// quota, which applies to all users or clients that don't have their own.
+func IsDefaultQuotaEntityPath(entityPath string) bool {
+	for _, element := range strings.Split(entityPath, "/") {
+		if element == defaultQuotaEntity {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeEntityName converts a user or client ID into the form that Kafka uses in
+// zookeeper paths. This matches the behavior of Kafka's Sanitizer class.
+func sanitizeEntityName(name string) string {
+	if name == defaultQuotaEntity {
+		return name
+	}
+
+	sanitized := url.QueryEscape(name)
+	sanitized = strings.ReplaceAll(sanitized, "+", "%20")
+	sanitized = strings.ReplaceAll(sanitized, "~", "%7E")
+	return sanitized
+}
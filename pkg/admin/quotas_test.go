@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaEntityPaths(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{
+			"users/ANONYMOUS/clients/topicctl%40host%20%28github.com%2Fsegmentio%2Fkafka-go%29",
+			"users/ANONYMOUS/clients/<default>",
+			"users/ANONYMOUS",
+			"users/<default>/clients/topicctl%40host%20%28github.com%2Fsegmentio%2Fkafka-go%29",
+			"users/<default>/clients/<default>",
+			"users/<default>",
+			"clients/topicctl%40host%20%28github.com%2Fsegmentio%2Fkafka-go%29",
+			"clients/<default>",
+		},
+		QuotaEntityPaths(AnonymousUser, "topicctl@host (github.com/segmentio/kafka-go)"),
+	)
+}
+
+func TestResolveClientQuotas(t *testing.T) {
+	entityPaths := QuotaEntityPaths(AnonymousUser, "test-client")
+
+	assert.Equal(
+		t,
+		[]ClientQuota{},
+		ResolveClientQuotas(entityPaths, map[string]map[string]string{}),
+	)
+	assert.Equal(
+		t,
+		[]ClientQuota{
+			{
+				Key:        ProducerByteRateKey,
+				Value:      "2000",
+				EntityPath: "clients/test-client",
+			},
+			{
+				Key:        ConsumerByteRateKey,
+				Value:      "1000",
+				EntityPath: "users/<default>",
+			},
+			{
+				Key:        RequestPercentageKey,
+				Value:      "50",
+				EntityPath: "clients/<default>",
+			},
+		},
+		ResolveClientQuotas(
+			entityPaths,
+			map[string]map[string]string{
+				"users/<default>": {
+					ConsumerByteRateKey: "1000",
+				},
+				"clients/test-client": {
+					ProducerByteRateKey: "2000",
+				},
+				"clients/<default>": {
+					ProducerByteRateKey:  "500",
+					ConsumerByteRateKey:  "500",
+					RequestPercentageKey: "50",
+				},
+				"users/other-user": {
+					ProducerByteRateKey: "100",
+				},
+			},
+		),
+	)
+}
+
+func TestParseQuotaEntityPath(t *testing.T) {
+	type testCase struct {
+		entityPath    string
+		expEntityType string
+		expEntityName string
+		expError      bool
+	}
+
+	testCases := []testCase{
+		{
+			entityPath:    "clients/test-client",
+			expEntityType: "clients",
+			expEntityName: "test-client",
+		},
+		{
+			entityPath:    "users/<default>",
+			expEntityType: "users",
+			expEntityName: "<default>",
+		},
+		{
+			entityPath:    "users/ANONYMOUS/clients/test-client",
+			expEntityType: "users",
+			expEntityName: "ANONYMOUS/clients/test-client",
+		},
+		{
+			entityPath: "",
+			expError:   true,
+		},
+		{
+			entityPath: "brokers/1",
+			expError:   true,
+		},
+		{
+			entityPath: "clients/test-client/users/ANONYMOUS",
+			expError:   true,
+		},
+		{
+			entityPath: "users//clients/test-client",
+			expError:   true,
+		},
+	}
+
+	for _, testCaseObj := range testCases {
+		entityType, entityName, err := ParseQuotaEntityPath(testCaseObj.entityPath)
+		if testCaseObj.expError {
+			assert.Error(t, err, testCaseObj.entityPath)
+		} else {
+			assert.NoError(t, err, testCaseObj.entityPath)
+			assert.Equal(t, testCaseObj.expEntityType, entityType, testCaseObj.entityPath)
+			assert.Equal(t, testCaseObj.expEntityName, entityName, testCaseObj.entityPath)
+		}
+	}
+
+	assert.True(t, IsDefaultQuotaEntityPath("users/<default>/clients/test-client"))
+	assert.False(t, IsDefaultQuotaEntityPath("users/ANONYMOUS/clients/test-client"))
+}
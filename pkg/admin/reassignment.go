@@ -0,0 +1,475 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	szk "github.com/samuel/go-zookeeper/zk"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the AlterPartitionReassignments
+	// API, so the (v0) requests are made via the protocol package instead. v0 requires Kafka
+	// 2.4 or newer and is a flexible version.
+	alterPartitionReassignmentsAPIKey     int16 = 45
+	alterPartitionReassignmentsAPIVersion int16 = 0
+	alterPartitionReassignmentsTimeoutMs        = 30000
+
+	noReassignmentInProgressError = 85
+)
+
+// PartitionReassignment is a partition in the in-progress reassignment, along with its
+// current state.
+type PartitionReassignment struct {
+	Topic          string
+	Partition      int
+	TargetReplicas []int
+
+	// Leader, Replicas, and ISR are the current state of the partition. While the reassignment
+	// is in progress, Replicas includes both the old and the new replicas.
+	Leader   int
+	Replicas []int
+	ISR      []int
+}
+
+// PendingReplicas returns the target replicas that haven't joined the ISR yet, i.e. the ones
+// that are still copying data from the leader.
+func (p PartitionReassignment) PendingReplicas() []int {
+	isr := map[int]struct{}{}
+	for _, replica := range p.ISR {
+		isr[replica] = struct{}{}
+	}
+
+	pending := []int{}
+	for _, replica := range p.TargetReplicas {
+		if _, ok := isr[replica]; !ok {
+			pending = append(pending, replica)
+		}
+	}
+	return pending
+}
+
+// GetReassignments returns the partitions in the in-progress reassignment, sorted by topic
+// and then partition. The result is empty if there's no reassignment in progress.
+func (c *Client) GetReassignments(ctx context.Context) ([]PartitionReassignment, error) {
+	reassignments := []PartitionReassignment{}
+
+	assignment := zkAssignment{}
+	_, err := c.zkClient.GetJSON(ctx, c.zNode(assignmentPath), &assignment)
+	if err == szk.ErrNoNode {
+		return reassignments, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	topicNamesMap := map[string]struct{}{}
+	for _, partition := range assignment.Partitions {
+		topicNamesMap[partition.Topic] = struct{}{}
+	}
+	topicNames := []string{}
+	for topicName := range topicNamesMap {
+		topicNames = append(topicNames, topicName)
+	}
+
+	topics, err := c.GetTopics(ctx, topicNames, true)
+	if err != nil {
+		return nil, err
+	}
+	partitionInfos := map[string]map[int]PartitionInfo{}
+	for _, topic := range topics {
+		partitionInfos[topic.Name] = map[int]PartitionInfo{}
+		for _, partition := range topic.Partitions {
+			partitionInfos[topic.Name][partition.ID] = partition
+		}
+	}
+
+	for _, partition := range assignment.Partitions {
+		partitionInfo := partitionInfos[partition.Topic][partition.Partition]
+
+		reassignments = append(
+			reassignments,
+			PartitionReassignment{
+				Topic:          partition.Topic,
+				Partition:      partition.Partition,
+				TargetReplicas: partition.Replicas,
+				Leader:         partitionInfo.Leader,
+				Replicas:       partitionInfo.Replicas,
+				ISR:            partitionInfo.ISR,
+			},
+		)
+	}
+
+	sort.Slice(reassignments, func(a, b int) bool {
+		if reassignments[a].Topic != reassignments[b].Topic {
+			return reassignments[a].Topic < reassignments[b].Topic
+		}
+		return reassignments[a].Partition < reassignments[b].Partition
+	})
+
+	return reassignments, nil
+}
+
+// CancelReassignments cancels the in-progress reassignments of the argument partitions of a
+// topic, moving them back to their original replicas. Partitions that aren't being
+// reassigned are ignored. This requires Kafka 2.4 or newer; reassignments started through
+// zookeeper, like the ones made by AssignPartitions, can be cancelled as well.
+func (c *Client) CancelReassignments(
+	ctx context.Context,
+	topic string,
+	partitions []int,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot cancel reassignments in read-only mode")
+	}
+	if err := c.CheckFeature(ctx, FeatureCancelReassignments); err != nil {
+		return err
+	}
+
+	body := &protocol.Encoder{}
+	body.WriteInt32(alterPartitionReassignmentsTimeoutMs)
+	body.WriteCompactArrayLength(1)
+	body.WriteCompactString(topic)
+	body.WriteCompactArrayLength(len(partitions))
+	for _, partition := range partitions {
+		body.WriteInt32(int32(partition))
+		// A null replica list cancels the reassignment
+		body.WriteCompactNullArray()
+		body.WriteEmptyTaggedFields()
+	}
+	body.WriteEmptyTaggedFields()
+	body.WriteEmptyTaggedFields()
+
+	controllerAddr, err := c.getControllerAddr(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Cancelling reassignments of topic %s, partitions %+v", topic, partitions)
+	response, err := protocol.FlexibleRoundTrip(
+		ctx,
+		controllerAddr,
+		alterPartitionReassignmentsAPIKey,
+		alterPartitionReassignmentsAPIVersion,
+		body,
+	)
+	if err != nil {
+		return err
+	}
+	return decodeAlterPartitionReassignmentsResponse(response)
+}
+
+func decodeAlterPartitionReassignmentsResponse(response *protocol.Decoder) error {
+	// Throttle time
+	response.ReadInt32()
+
+	err := reassignmentError(response.ReadInt16(), response.ReadCompactString())
+
+	numTopics := response.ReadCompactArrayLength()
+	for i := 0; i < numTopics && response.Err() == nil; i++ {
+		topic := response.ReadCompactString()
+
+		numPartitions := response.ReadCompactArrayLength()
+		for j := 0; j < numPartitions && response.Err() == nil; j++ {
+			partition := response.ReadInt32()
+			partitionErr := reassignmentError(response.ReadInt16(), response.ReadCompactString())
+			if partitionErr != nil && err == nil {
+				err = fmt.Errorf("Partition %d of topic %s: %+v", partition, topic, partitionErr)
+			}
+			response.SkipTaggedFields()
+		}
+		response.SkipTaggedFields()
+	}
+	response.SkipTaggedFields()
+
+	if decodeErr := response.Err(); decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+func reassignmentError(errorCode int16, message string) error {
+	switch errorCode {
+	case 0, noReassignmentInProgressError:
+		return nil
+	}
+
+	if message != "" {
+		return fmt.Errorf(
+			"Reassignment request failed: %+v (%s)",
+			kafka.Error(errorCode),
+			message,
+		)
+	}
+	return fmt.Errorf("Reassignment request failed: %+v", kafka.Error(errorCode))
+}
+
+// ReassignmentProgress is a snapshot of the progress of an in-progress reassignment.
+type ReassignmentProgress struct {
+	Time time.Time
+
+	// Partitions is the number of partitions in the reassignment, and DonePartitions is the
+	// number of them whose target replicas are all in-sync.
+	Partitions     int
+	DonePartitions int
+
+	// Moves are the replicas that are still being copied to their new brokers.
+	Moves []ReplicaMoveProgress
+
+	// Brokers summarizes the moves by the broker that they're being copied to, sorted by ID.
+	Brokers []BrokerReassignmentProgress
+}
+
+// ReplicaMoveProgress describes a single replica that's being copied to a new broker.
+type ReplicaMoveProgress struct {
+	Topic     string
+	Partition int
+	Broker    int
+
+	// CopiedBytes is the size of the new replica so far, and TotalBytes is the size of the
+	// leader replica; these are -1 if the sizes aren't known.
+	CopiedBytes int64
+	TotalBytes  int64
+}
+
+// RemainingBytes returns the number of bytes that still need to be copied for the move, or -1
+// if this isn't known.
+func (r ReplicaMoveProgress) RemainingBytes() int64 {
+	if r.CopiedBytes < 0 || r.TotalBytes < 0 {
+		return -1
+	}
+	if r.CopiedBytes >= r.TotalBytes {
+		return 0
+	}
+	return r.TotalBytes - r.CopiedBytes
+}
+
+// BrokerReassignmentProgress summarizes the replicas that are being copied to a single
+// broker.
+type BrokerReassignmentProgress struct {
+	Broker         int
+	RemainingMoves int
+
+	// RemainingBytes is the total number of bytes left to copy to the broker, or -1 if this
+	// isn't known.
+	RemainingBytes int64
+
+	// ThrottleBytes is the follower replication throttle of the broker in bytes per second, or 0
+	// if it isn't throttled.
+	ThrottleBytes int64
+
+	// BytesPerSec is the rate at which the broker is copying new replicas, or -1 if it isn't
+	// known. It's only filled in by SetRates.
+	BytesPerSec float64
+}
+
+// ThrottleUtilization returns the fraction of the broker's throttle that its current copy
+// rate is using. The second return value is false if the broker isn't throttled or its rate
+// isn't known.
+func (b BrokerReassignmentProgress) ThrottleUtilization() (float64, bool) {
+	if b.ThrottleBytes <= 0 || b.BytesPerSec < 0 {
+		return 0, false
+	}
+	return b.BytesPerSec / float64(b.ThrottleBytes), true
+}
+
+// ETA returns the estimated time until the broker finishes copying its new replicas. The
+// second return value is false if this can't be estimated.
+func (b BrokerReassignmentProgress) ETA() (time.Duration, bool) {
+	if b.RemainingBytes == 0 {
+		return 0, true
+	}
+	if b.RemainingBytes < 0 || b.BytesPerSec <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(b.RemainingBytes) / b.BytesPerSec * float64(time.Second)), true
+}
+
+// NewReassignmentProgress creates a snapshot of the progress of the argument reassignments.
+// Sizes are taken from the argument log dirs, which can be nil if they aren't available, and
+// throttles from the configs of the argument brokers.
+func NewReassignmentProgress(
+	reassignments []PartitionReassignment,
+	logDirs []LogDirInfo,
+	brokers []BrokerInfo,
+	now time.Time,
+) ReassignmentProgress {
+	progress := ReassignmentProgress{
+		Time:       now,
+		Partitions: len(reassignments),
+		Moves:      []ReplicaMoveProgress{},
+		Brokers:    []BrokerReassignmentProgress{},
+	}
+
+	type replicaKey struct {
+		topic     string
+		partition int
+		broker    int
+	}
+	sizes := map[replicaKey]int64{}
+
+	for _, logDir := range logDirs {
+		if logDir.Error != "" {
+			continue
+		}
+		for _, replica := range logDir.Replicas {
+			if replica.IsFuture {
+				continue
+			}
+			sizes[replicaKey{
+				topic:     replica.Topic,
+				partition: replica.Partition,
+				broker:    logDir.Broker,
+			}] = replica.Size
+		}
+	}
+
+	brokerProgresses := map[int]*BrokerReassignmentProgress{}
+
+	for _, reassignment := range reassignments {
+		pending := reassignment.PendingReplicas()
+		if len(pending) == 0 {
+			progress.DonePartitions++
+			continue
+		}
+
+		totalBytes := int64(-1)
+		if logDirs != nil {
+			if size, ok := sizes[replicaKey{
+				topic:     reassignment.Topic,
+				partition: reassignment.Partition,
+				broker:    reassignment.Leader,
+			}]; ok {
+				totalBytes = size
+			}
+		}
+
+		for _, broker := range pending {
+			move := ReplicaMoveProgress{
+				Topic:       reassignment.Topic,
+				Partition:   reassignment.Partition,
+				Broker:      broker,
+				CopiedBytes: -1,
+				TotalBytes:  totalBytes,
+			}
+			if logDirs != nil {
+				// The new replica might not have been created yet
+				move.CopiedBytes = sizes[replicaKey{
+					topic:     reassignment.Topic,
+					partition: reassignment.Partition,
+					broker:    broker,
+				}]
+			}
+			progress.Moves = append(progress.Moves, move)
+
+			brokerProgress, ok := brokerProgresses[broker]
+			if !ok {
+				brokerProgress = &BrokerReassignmentProgress{
+					Broker:      broker,
+					BytesPerSec: -1,
+				}
+				brokerProgresses[broker] = brokerProgress
+			}
+			brokerProgress.RemainingMoves++
+			if brokerProgress.RemainingBytes >= 0 {
+				if remaining := move.RemainingBytes(); remaining >= 0 {
+					brokerProgress.RemainingBytes += remaining
+				} else {
+					brokerProgress.RemainingBytes = -1
+				}
+			}
+		}
+	}
+
+	throttles := map[int]int64{}
+	for _, broker := range brokers {
+		if value, ok := broker.Config[FollowerThrottledKey]; ok {
+			throttle, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				throttles[broker.ID] = throttle
+			}
+		}
+	}
+
+	for _, brokerProgress := range brokerProgresses {
+		brokerProgress.ThrottleBytes = throttles[brokerProgress.Broker]
+		progress.Brokers = append(progress.Brokers, *brokerProgress)
+	}
+	sort.Slice(progress.Brokers, func(a, b int) bool {
+		return progress.Brokers[a].Broker < progress.Brokers[b].Broker
+	})
+
+	return progress
+}
+
+// SetRates fills in the copy rate of each broker based on how much the replicas being
+// copied to it have grown since the argument previous snapshot. Only moves that are in both
+// snapshots are counted.
+func (r *ReassignmentProgress) SetRates(prev ReassignmentProgress) {
+	elapsed := r.Time.Sub(prev.Time).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	type moveKey struct {
+		topic     string
+		partition int
+		broker    int
+	}
+	prevCopied := map[moveKey]int64{}
+	for _, move := range prev.Moves {
+		prevCopied[moveKey{
+			topic:     move.Topic,
+			partition: move.Partition,
+			broker:    move.Broker,
+		}] = move.CopiedBytes
+	}
+
+	copied := map[int]int64{}
+	known := map[int]bool{}
+
+	for _, move := range r.Moves {
+		prevBytes, ok := prevCopied[moveKey{
+			topic:     move.Topic,
+			partition: move.Partition,
+			broker:    move.Broker,
+		}]
+		if !ok || prevBytes < 0 || move.CopiedBytes < 0 {
+			continue
+		}
+		known[move.Broker] = true
+		if move.CopiedBytes > prevBytes {
+			copied[move.Broker] += move.CopiedBytes - prevBytes
+		}
+	}
+
+	for i, brokerProgress := range r.Brokers {
+		if known[brokerProgress.Broker] {
+			r.Brokers[i].BytesPerSec = float64(copied[brokerProgress.Broker]) / elapsed
+		}
+	}
+}
+
+// ETA returns the estimated time until all of the brokers finish copying their new replicas.
+// The second return value is false if this can't be estimated for any of the brokers.
+func (r ReassignmentProgress) ETA() (time.Duration, bool) {
+	var maxETA time.Duration
+
+	for _, brokerProgress := range r.Brokers {
+		eta, ok := brokerProgress.ETA()
+		if !ok {
+			return 0, false
+		}
+		if eta > maxETA {
+			maxETA = eta
+		}
+	}
+
+	return maxETA, true
+}
@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionReassignmentPendingReplicas(t *testing.T) {
+	reassignment := PartitionReassignment{
+		TargetReplicas: []int{3, 4, 1},
+		Replicas:       []int{1, 2, 3, 4},
+		ISR:            []int{1, 2, 3},
+	}
+	assert.Equal(t, []int{4}, reassignment.PendingReplicas())
+
+	reassignment.ISR = []int{1, 2, 3, 4}
+	assert.Equal(t, []int{}, reassignment.PendingReplicas())
+}
+
+func TestReassignmentProgress(t *testing.T) {
+	reassignments := []PartitionReassignment{
+		{
+			Topic:          "topic1",
+			Partition:      0,
+			TargetReplicas: []int{1, 3},
+			Leader:         1,
+			Replicas:       []int{1, 2, 3},
+			ISR:            []int{1, 2},
+		},
+		{
+			Topic:          "topic1",
+			Partition:      1,
+			TargetReplicas: []int{2, 3, 4},
+			Leader:         2,
+			Replicas:       []int{2, 1, 3, 4},
+			ISR:            []int{2, 1},
+		},
+		{
+			Topic:          "topic1",
+			Partition:      2,
+			TargetReplicas: []int{3},
+			Leader:         3,
+			Replicas:       []int{3, 1},
+			ISR:            []int{3, 1},
+		},
+	}
+	brokers := []BrokerInfo{
+		{
+			ID:     3,
+			Config: map[string]string{FollowerThrottledKey: "1000"},
+		},
+		{
+			ID:     4,
+			Config: map[string]string{},
+		},
+	}
+	logDirs := func(sizes map[int]map[int]int64) []LogDirInfo {
+		dirs := []LogDirInfo{}
+		for broker, partitionSizes := range sizes {
+			dir := LogDirInfo{Broker: broker}
+			for partition, size := range partitionSizes {
+				dir.Replicas = append(
+					dir.Replicas,
+					LogDirReplica{Topic: "topic1", Partition: partition, Size: size},
+				)
+			}
+			dirs = append(dirs, dir)
+		}
+		return dirs
+	}
+
+	now := time.Now()
+
+	start := NewReassignmentProgress(
+		reassignments,
+		logDirs(
+			map[int]map[int]int64{
+				1: {0: 10000},
+				2: {1: 20000},
+				3: {0: 1000},
+			},
+		),
+		brokers,
+		now,
+	)
+	assert.Equal(t, 3, start.Partitions)
+	assert.Equal(t, 1, start.DonePartitions)
+	assert.Equal(
+		t,
+		[]ReplicaMoveProgress{
+			{Topic: "topic1", Partition: 0, Broker: 3, CopiedBytes: 1000, TotalBytes: 10000},
+			{Topic: "topic1", Partition: 1, Broker: 3, CopiedBytes: 0, TotalBytes: 20000},
+			{Topic: "topic1", Partition: 1, Broker: 4, CopiedBytes: 0, TotalBytes: 20000},
+		},
+		start.Moves,
+	)
+	assert.Equal(
+		t,
+		[]BrokerReassignmentProgress{
+			{
+				Broker:         3,
+				RemainingMoves: 2,
+				RemainingBytes: 29000,
+				ThrottleBytes:  1000,
+				BytesPerSec:    -1,
+			},
+			{
+				Broker:         4,
+				RemainingMoves: 1,
+				RemainingBytes: 20000,
+				BytesPerSec:    -1,
+			},
+		},
+		start.Brokers,
+	)
+	_, ok := start.ETA()
+	assert.False(t, ok)
+
+	end := NewReassignmentProgress(
+		reassignments,
+		logDirs(
+			map[int]map[int]int64{
+				1: {0: 10000},
+				2: {1: 20000},
+				3: {0: 6000, 1: 5000},
+				4: {1: 10000},
+			},
+		),
+		brokers,
+		now.Add(10*time.Second),
+	)
+	end.SetRates(start)
+
+	assert.Equal(t, 1000.0, end.Brokers[0].BytesPerSec)
+	assert.Equal(t, 1000.0, end.Brokers[1].BytesPerSec)
+
+	utilization, ok := end.Brokers[0].ThrottleUtilization()
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, utilization)
+	_, ok = end.Brokers[1].ThrottleUtilization()
+	assert.False(t, ok)
+
+	eta, ok := end.Brokers[0].ETA()
+	assert.True(t, ok)
+	assert.Equal(t, 19*time.Second, eta)
+
+	eta, ok = end.ETA()
+	assert.True(t, ok)
+	assert.Equal(t, 19*time.Second, eta)
+
+	// Sizes are unknown without log dirs
+	noSizes := NewReassignmentProgress(reassignments, nil, brokers, now)
+	assert.Equal(t, int64(-1), noSizes.Moves[0].RemainingBytes())
+	assert.Equal(t, int64(-1), noSizes.Brokers[0].RemainingBytes)
+}
+
+func TestDecodeAlterPartitionReassignmentsResponse(t *testing.T) {
+	encodeResponse := func(partitionErrorCodes ...int16) []byte {
+		response := &protocol.Encoder{}
+		response.WriteInt32(0)
+		response.WriteInt16(0)
+		response.WriteUvarint(0)
+		response.WriteCompactArrayLength(1)
+		response.WriteCompactString("test-topic")
+		response.WriteCompactArrayLength(len(partitionErrorCodes))
+		for p, errorCode := range partitionErrorCodes {
+			response.WriteInt32(int32(p))
+			response.WriteInt16(errorCode)
+			response.WriteUvarint(0)
+			response.WriteEmptyTaggedFields()
+		}
+		response.WriteEmptyTaggedFields()
+		response.WriteEmptyTaggedFields()
+		return response.Bytes()
+	}
+
+	// Partitions that aren't being reassigned are ignored
+	err := decodeAlterPartitionReassignmentsResponse(
+		protocol.NewDecoder(bytes.NewReader(encodeResponse(0, noReassignmentInProgressError))),
+	)
+	assert.Nil(t, err)
+
+	err = decodeAlterPartitionReassignmentsResponse(
+		protocol.NewDecoder(bytes.NewReader(encodeResponse(0, 29))),
+	)
+	assert.NotNil(t, err)
+
+	err = decodeAlterPartitionReassignmentsResponse(
+		protocol.NewDecoder(bytes.NewReader(encodeResponse(0, 0)[:12])),
+	)
+	assert.NotNil(t, err)
+}
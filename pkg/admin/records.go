@@ -0,0 +1,206 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the DeleteRecords API, so
+	// the (v0) requests are made via the protocol package instead.
+	deleteRecordsAPIKey     int16 = 21
+	deleteRecordsAPIVersion int16 = 0
+	deleteRecordsTimeout          = 30 * time.Second
+)
+
+// DeleteRecords deletes all of the records before the argument offsets in one or more
+// partitions of a topic. The partitionOffsets map is keyed by partition ID.
+//
+// Deletion only moves the log start offset of each partition forward, so calling this
+// multiple times with the same arguments (e.g., after a partial failure) is safe.
+//
+// The function returns the new log start offset (i.e., low watermark) of each partition.
+func (c *Client) DeleteRecords(
+	ctx context.Context,
+	topic string,
+	partitionOffsets map[int]int64,
+) (map[int]int64, error) {
+	if c.readOnly {
+		return nil, errors.New("Cannot delete records in read-only mode")
+	}
+	if err := c.CheckFeature(ctx, FeatureDeleteRecords); err != nil {
+		return nil, err
+	}
+
+	partitions, err := c.readPartitions(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests need to be sent to the leader of each partition
+	leaders := map[string]map[int]int64{}
+	partitionsFound := map[int]struct{}{}
+
+	for _, partition := range partitions {
+		offset, ok := partitionOffsets[partition.ID]
+		if !ok {
+			continue
+		}
+		partitionsFound[partition.ID] = struct{}{}
+
+		leaderAddr := net.JoinHostPort(
+			partition.Leader.Host,
+			strconv.Itoa(partition.Leader.Port),
+		)
+		if _, ok := leaders[leaderAddr]; !ok {
+			leaders[leaderAddr] = map[int]int64{}
+		}
+		leaders[leaderAddr][partition.ID] = offset
+	}
+
+	for partition := range partitionOffsets {
+		if _, ok := partitionsFound[partition]; !ok {
+			return nil, fmt.Errorf("Partition %d not found in topic %s", partition, topic)
+		}
+	}
+
+	lowWatermarks := map[int]int64{}
+
+	for leaderAddr, leaderOffsets := range leaders {
+		log.Debugf(
+			"Deleting records in topic %s via broker %s: %+v",
+			topic,
+			leaderAddr,
+			leaderOffsets,
+		)
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		results, err := deleteRecordsFromLeader(ctx, leaderAddr, topic, leaderOffsets)
+		if err != nil {
+			return nil, err
+		}
+
+		for partition := range leaderOffsets {
+			result, ok := results[partition]
+			if !ok {
+				return nil, fmt.Errorf(
+					"Response from broker %s is missing partition %d",
+					leaderAddr,
+					partition,
+				)
+			}
+			if result.errorCode != 0 {
+				return nil, fmt.Errorf(
+					"Error deleting records in partition %d: %+v",
+					partition,
+					kafka.Error(result.errorCode),
+				)
+			}
+			lowWatermarks[partition] = result.lowWatermark
+		}
+	}
+
+	return lowWatermarks, nil
+}
+
+type deleteRecordsResult struct {
+	lowWatermark int64
+	errorCode    int16
+}
+
+func deleteRecordsFromLeader(
+	ctx context.Context,
+	leaderAddr string,
+	topic string,
+	partitionOffsets map[int]int64,
+) (map[int]deleteRecordsResult, error) {
+	response, err := protocol.RoundTrip(
+		ctx,
+		leaderAddr,
+		deleteRecordsAPIKey,
+		deleteRecordsAPIVersion,
+		encodeDeleteRecordsRequest(topic, partitionOffsets, deleteRecordsTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeDeleteRecordsResponse(response, topic)
+}
+
+// encodeDeleteRecordsRequest encodes the body of a v0 DeleteRecords request for the
+// argument topic partitions.
+func encodeDeleteRecordsRequest(
+	topic string,
+	partitionOffsets map[int]int64,
+	timeout time.Duration,
+) *protocol.Encoder {
+	partitions := []int{}
+	for partition := range partitionOffsets {
+		partitions = append(partitions, partition)
+	}
+	sort.Ints(partitions)
+
+	body := &protocol.Encoder{}
+
+	// Topics array (just one topic)
+	body.WriteInt32(1)
+	body.WriteString(topic)
+
+	// Partitions array
+	body.WriteInt32(int32(len(partitions)))
+	for _, partition := range partitions {
+		body.WriteInt32(int32(partition))
+		body.WriteInt64(partitionOffsets[partition])
+	}
+
+	body.WriteInt32(int32(timeout / time.Millisecond))
+
+	return body
+}
+
+// decodeDeleteRecordsResponse decodes the body of a v0 DeleteRecords response and returns
+// the results for each partition in the argument topic.
+func decodeDeleteRecordsResponse(
+	response *protocol.Decoder,
+	topic string,
+) (map[int]deleteRecordsResult, error) {
+	results := map[int]deleteRecordsResult{}
+
+	// Throttle time
+	response.ReadInt32()
+
+	numTopics := response.ReadInt32()
+	for i := 0; i < int(numTopics) && response.Err() == nil; i++ {
+		responseTopic := response.ReadString()
+		numPartitions := response.ReadInt32()
+
+		for j := 0; j < int(numPartitions) && response.Err() == nil; j++ {
+			partition := response.ReadInt32()
+			result := deleteRecordsResult{
+				lowWatermark: response.ReadInt64(),
+				errorCode:    response.ReadInt16(),
+			}
+
+			if responseTopic == topic {
+				results[int(partition)] = result
+			}
+		}
+	}
+
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteRecords(t *testing.T) {
+	ctx := context.Background()
+	util.SkipIfTestAPIUnsupported(ctx, t, deleteRecordsAPIKey)
+
+	adminClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       "",
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	topicName := util.RandomString("topic-delete-records-", 6)
+
+	err = adminClient.CreateTopic(
+		ctx,
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     2,
+			ReplicationFactor: 2,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(250 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:   []string{util.TestKafkaAddr()},
+			Topic:     topicName,
+			Balancer:  &kafka.RoundRobin{},
+			BatchSize: 10,
+		},
+	)
+	defer writer.Close()
+
+	messages := []kafka.Message{}
+	for i := 0; i < 20; i++ {
+		messages = append(
+			messages,
+			kafka.Message{
+				Key:   []byte(fmt.Sprintf("key%d", i)),
+				Value: []byte(fmt.Sprintf("value%d", i)),
+			},
+		)
+	}
+	require.Nil(t, writer.WriteMessages(ctx, messages...))
+
+	lowWatermarks, err := adminClient.DeleteRecords(
+		ctx,
+		topicName,
+		map[int]int64{
+			0: 4,
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, map[int]int64{0: 4}, lowWatermarks)
+
+	conn, err := kafka.DialLeader(ctx, "tcp", util.TestKafkaAddr(), topicName, 0)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	firstOffset, _, err := conn.ReadOffsets()
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), firstOffset)
+
+	// Re-running is a no-op
+	lowWatermarks, err = adminClient.DeleteRecords(
+		ctx,
+		topicName,
+		map[int]int64{
+			0: 4,
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, map[int]int64{0: 4}, lowWatermarks)
+
+	_, err = adminClient.DeleteRecords(
+		ctx,
+		topicName,
+		map[int]int64{
+			5: 4,
+		},
+	)
+	assert.NotNil(t, err)
+
+	readOnlyClient, err := NewClient(
+		ctx,
+		ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       "",
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       true,
+		},
+	)
+	require.Nil(t, err)
+	defer readOnlyClient.Close()
+
+	_, err = readOnlyClient.DeleteRecords(
+		ctx,
+		topicName,
+		map[int]int64{
+			0: 5,
+		},
+	)
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDeleteRecordsRequest(t *testing.T) {
+	body := encodeDeleteRecordsRequest(
+		"test-topic",
+		map[int]int64{
+			2: 100,
+			1: 50,
+		},
+		5*time.Second,
+	)
+
+	expected := &protocol.Encoder{}
+	expected.WriteInt32(1)
+	expected.WriteString("test-topic")
+	expected.WriteInt32(2)
+	expected.WriteInt32(1)
+	expected.WriteInt64(50)
+	expected.WriteInt32(2)
+	expected.WriteInt64(100)
+	expected.WriteInt32(5000)
+
+	assert.Equal(t, expected.Bytes(), body.Bytes())
+}
+
+func TestDecodeDeleteRecordsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt32(1)
+	response.WriteString("test-topic")
+	response.WriteInt32(2)
+	response.WriteInt32(1)
+	response.WriteInt64(50)
+	response.WriteInt16(0)
+	response.WriteInt32(2)
+	response.WriteInt64(-1)
+	response.WriteInt16(1)
+
+	results, err := decodeDeleteRecordsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"test-topic",
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		map[int]deleteRecordsResult{
+			1: {
+				lowWatermark: 50,
+				errorCode:    0,
+			},
+			2: {
+				lowWatermark: -1,
+				errorCode:    1,
+			},
+		},
+		results,
+	)
+
+	_, err = decodeDeleteRecordsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes()[:10])),
+		"test-topic",
+	)
+	assert.NotNil(t, err)
+}
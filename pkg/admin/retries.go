@@ -0,0 +1,160 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// RetryConfig contains the settings for retrying broker API requests that fail with
+// transient errors, e.g. because the controller moved or a coordinator is still loading.
+// Zero values are replaced with reasonable defaults.
+type RetryConfig struct {
+	// MaxRetries is the number of times that a request is retried. Set this to a negative
+	// value to disable retries.
+	MaxRetries int
+
+	// Backoff is the amount of time to wait before the first retry. It's doubled for each
+	// subsequent retry, up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxRetries == 0 {
+		r.MaxRetries = 3
+	} else if r.MaxRetries < 0 {
+		r.MaxRetries = 0
+	}
+	if r.Backoff == 0 {
+		r.Backoff = 200 * time.Millisecond
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = 5 * time.Second
+	}
+	return r
+}
+
+// withBrokerRetries runs the argument broker operation, retrying it with exponential backoff
+// if it fails with a transient error. Each attempt waits for the client's rate limiter.
+func (c *Client) withBrokerRetries(
+	ctx context.Context,
+	name string,
+	write bool,
+	operation func() error,
+) error {
+	backoff := c.retryConfig.Backoff
+
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := operation()
+		if err == nil ||
+			attempt > c.retryConfig.MaxRetries ||
+			ctx.Err() != nil ||
+			!isRetryableBrokerError(err, write) {
+			return err
+		}
+
+		log.Warnf(
+			"Got transient error for broker %s (attempt %d/%d), retrying in %s: %+v",
+			name,
+			attempt,
+			c.retryConfig.MaxRetries+1,
+			backoff,
+			err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.retryConfig.MaxBackoff {
+			backoff = c.retryConfig.MaxBackoff
+		}
+	}
+}
+
+// isRetryableBrokerError returns whether a broker operation that failed with the argument
+// error can be retried. As with zk operations, writes are only retried if the error shows
+// that they weren't applied; a timed-out topic creation, for instance, may still complete.
+func isRetryableBrokerError(err error, write bool) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		// Nothing was sent
+		return true
+	}
+
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		switch kafkaErr {
+		case kafka.NotController,
+			kafka.GroupLoadInProgress,
+			kafka.GroupCoordinatorNotAvailable,
+			kafka.NotCoordinatorForGroup:
+			return true
+		case kafka.RequestTimedOut,
+			kafka.LeaderNotAvailable,
+			kafka.NetworkException:
+			return !write
+		default:
+			return false
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return !write
+	}
+
+	return false
+}
+
+// readPartitions gets the partitions of the argument topics from the bootstrap broker,
+// retrying transient failures.
+func (c *Client) readPartitions(
+	ctx context.Context,
+	topicNames ...string,
+) ([]kafka.Partition, error) {
+	var partitions []kafka.Partition
+
+	err := c.withBrokerRetries(ctx, "read partitions", false, func() error {
+		conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", c.bootstrapAddrs[0])
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		partitions, err = conn.ReadPartitions(topicNames...)
+		return err
+	})
+
+	return partitions, err
+}
+
+// getControllerAddr gets the address of the cluster controller from the bootstrap broker,
+// without retries.
+func (c *Client) getControllerAddr(ctx context.Context) (string, error) {
+	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", c.bootstrapAddrs[0])
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	broker, err := conn.Controller()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%d", broker.Host, broker.Port), nil
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryableBrokerError(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset")}
+
+	assert.True(t, isRetryableBrokerError(dialErr, false))
+	assert.True(t, isRetryableBrokerError(dialErr, true))
+	assert.False(t, isRetryableBrokerError(readErr, false))
+
+	assert.True(t, isRetryableBrokerError(kafka.NotController, true))
+	assert.True(t, isRetryableBrokerError(kafka.GroupLoadInProgress, false))
+	assert.True(t, isRetryableBrokerError(fmt.Errorf("wrapped: %w", kafka.NotController), true))
+
+	assert.True(t, isRetryableBrokerError(kafka.RequestTimedOut, false))
+	assert.False(t, isRetryableBrokerError(kafka.RequestTimedOut, true))
+
+	assert.False(t, isRetryableBrokerError(kafka.TopicAlreadyExists, true))
+	assert.False(t, isRetryableBrokerError(kafka.InvalidReplicationFactor, false))
+	assert.False(t, isRetryableBrokerError(errors.New("some other error"), false))
+}
+
+func TestWithBrokerRetries(t *testing.T) {
+	ctx := context.Background()
+	client := &Client{
+		retryConfig: RetryConfig{
+			MaxRetries: 2,
+			Backoff:    time.Millisecond,
+		}.withDefaults(),
+	}
+
+	attempts := 0
+	err := client.withBrokerRetries(ctx, "test", true, func() error {
+		attempts++
+		if attempts < 3 {
+			return kafka.NotController
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = client.withBrokerRetries(ctx, "test", false, func() error {
+		attempts++
+		return kafka.RequestTimedOut
+	})
+	assert.Equal(t, kafka.RequestTimedOut, err)
+	assert.Equal(t, 3, attempts)
+
+	// Writes that may have been applied aren't retried
+	attempts = 0
+	err = client.withBrokerRetries(ctx, "test", true, func() error {
+		attempts++
+		return kafka.RequestTimedOut
+	})
+	assert.Equal(t, kafka.RequestTimedOut, err)
+	assert.Equal(t, 1, attempts)
+
+	client.retryConfig = RetryConfig{MaxRetries: -1}.withDefaults()
+	attempts = 0
+	err = client.withBrokerRetries(ctx, "test", false, func() error {
+		attempts++
+		return kafka.NotController
+	})
+	assert.Equal(t, kafka.NotController, err)
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,371 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+const snapshotVersion = 1
+
+// ClusterSnapshot is a point-in-time copy of the metadata for a cluster's brokers and
+// topics, e.g. for verifying that risky maintenance didn't change anything unexpected.
+type ClusterSnapshot struct {
+	Version   int          `json:"version"`
+	ClusterID string       `json:"clusterID"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Brokers   []BrokerInfo `json:"brokers"`
+	Topics    []TopicInfo  `json:"topics"`
+
+	// Match is the pattern that the topics were filtered by, if any.
+	Match string `json:"match,omitempty"`
+}
+
+// SnapshotDiff is a single difference between two cluster snapshots. Values that are missing
+// in one of the snapshots are empty.
+type SnapshotDiff struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+}
+
+// GetClusterSnapshot returns a snapshot of the brokers and the topics that match the argument
+// matcher (or all topics if it's nil), including the leaders and ISRs of every partition.
+func (c *Client) GetClusterSnapshot(
+	ctx context.Context,
+	topicMatcher *util.TopicMatcher,
+) (ClusterSnapshot, error) {
+	snapshot := ClusterSnapshot{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	if topicMatcher != nil {
+		snapshot.Match = topicMatcher.String()
+	}
+
+	var err error
+
+	snapshot.ClusterID, err = c.GetClusterID(ctx)
+	if err != nil {
+		return snapshot, err
+	}
+	snapshot.Brokers, err = c.GetBrokers(ctx, nil)
+	if err != nil {
+		return snapshot, err
+	}
+
+	topicNames, err := c.GetTopicNames(ctx)
+	if err != nil {
+		return snapshot, err
+	}
+	topicNames = topicMatcher.Filter(topicNames)
+
+	snapshot.Topics = []TopicInfo{}
+	if len(topicNames) > 0 {
+		snapshot.Topics, err = c.GetTopics(ctx, topicNames, true)
+		if err != nil {
+			return snapshot, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// LoadSnapshotFile loads a cluster snapshot from the argument path.
+func LoadSnapshotFile(path string) (ClusterSnapshot, error) {
+	snapshot := ClusterSnapshot{}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(contents, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("Could not parse snapshot %s: %+v", path, err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return snapshot, fmt.Errorf(
+			"Snapshot %s has version %d; this version of topicctl only supports %d",
+			path,
+			snapshot.Version,
+			snapshotVersion,
+		)
+	}
+
+	return snapshot, nil
+}
+
+// WriteFile writes the snapshot as indented JSON to the argument path.
+func (s ClusterSnapshot) WriteFile(path string) error {
+	contents, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// DiffSnapshots returns the differences between two snapshots, ordered by resource. Brokers
+// are compared by their racks, endpoints, and dynamic configs, and topics by their IDs,
+// configs, and the replicas, leader, and ISR of each partition.
+func DiffSnapshots(before ClusterSnapshot, after ClusterSnapshot) []SnapshotDiff {
+	diffs := []SnapshotDiff{}
+
+	if before.ClusterID != after.ClusterID {
+		diffs = append(
+			diffs,
+			SnapshotDiff{
+				Resource: "cluster",
+				Field:    "id",
+				Before:   before.ClusterID,
+				After:    after.ClusterID,
+			},
+		)
+	}
+
+	brokerIDs := []int{}
+	beforeBrokers := map[int]BrokerInfo{}
+	for _, broker := range before.Brokers {
+		beforeBrokers[broker.ID] = broker
+		brokerIDs = append(brokerIDs, broker.ID)
+	}
+	afterBrokers := map[int]BrokerInfo{}
+	for _, broker := range after.Brokers {
+		afterBrokers[broker.ID] = broker
+		brokerIDs = append(brokerIDs, broker.ID)
+	}
+
+	for _, brokerID := range sortedUniqueInts(brokerIDs) {
+		beforeBroker, inBefore := beforeBrokers[brokerID]
+		afterBroker, inAfter := afterBrokers[brokerID]
+		resource := fmt.Sprintf("broker %d", brokerID)
+
+		if !inBefore || !inAfter {
+			diffs = append(diffs, existenceDiff(resource, inBefore, inAfter))
+			continue
+		}
+
+		diffs = appendDiff(diffs, resource, "rack", beforeBroker.Rack, afterBroker.Rack)
+		diffs = appendDiff(
+			diffs,
+			resource,
+			"endpoints",
+			strings.Join(beforeBroker.Endpoints, ","),
+			strings.Join(afterBroker.Endpoints, ","),
+		)
+		diffs = appendConfigDiffs(diffs, resource, beforeBroker.Config, afterBroker.Config)
+	}
+
+	beforeTopics := map[string]TopicInfo{}
+	for _, topic := range before.Topics {
+		beforeTopics[topic.Name] = topic
+	}
+	afterTopics := map[string]TopicInfo{}
+	for _, topic := range after.Topics {
+		afterTopics[topic.Name] = topic
+	}
+
+	topicNames := []string{}
+	for name := range beforeTopics {
+		topicNames = append(topicNames, name)
+	}
+	for name := range afterTopics {
+		if _, ok := beforeTopics[name]; !ok {
+			topicNames = append(topicNames, name)
+		}
+	}
+	sort.Strings(topicNames)
+
+	for _, topicName := range topicNames {
+		beforeTopic, inBefore := beforeTopics[topicName]
+		afterTopic, inAfter := afterTopics[topicName]
+		resource := fmt.Sprintf("topic %s", topicName)
+
+		if !inBefore || !inAfter {
+			diffs = append(diffs, existenceDiff(resource, inBefore, inAfter))
+			continue
+		}
+
+		// A changed ID means that the topic was deleted and re-created. IDs are only compared
+		// if both are set since older clusters don't have them.
+		if beforeTopic.TopicID != "" && afterTopic.TopicID != "" {
+			diffs = appendDiff(diffs, resource, "id", beforeTopic.TopicID, afterTopic.TopicID)
+		}
+		diffs = appendConfigDiffs(diffs, resource, beforeTopic.Config, afterTopic.Config)
+
+		partitionIDs := []int{}
+		beforePartitions := map[int]PartitionInfo{}
+		for _, partition := range beforeTopic.Partitions {
+			beforePartitions[partition.ID] = partition
+			partitionIDs = append(partitionIDs, partition.ID)
+		}
+		afterPartitions := map[int]PartitionInfo{}
+		for _, partition := range afterTopic.Partitions {
+			afterPartitions[partition.ID] = partition
+			partitionIDs = append(partitionIDs, partition.ID)
+		}
+
+		for _, partitionID := range sortedUniqueInts(partitionIDs) {
+			beforePartition, inBefore := beforePartitions[partitionID]
+			afterPartition, inAfter := afterPartitions[partitionID]
+			resource := fmt.Sprintf("topic %s partition %d", topicName, partitionID)
+
+			if !inBefore || !inAfter {
+				diffs = append(diffs, existenceDiff(resource, inBefore, inAfter))
+				continue
+			}
+
+			diffs = appendDiff(
+				diffs,
+				resource,
+				"replicas",
+				intsString(beforePartition.Replicas),
+				intsString(afterPartition.Replicas),
+			)
+			diffs = appendDiff(
+				diffs,
+				resource,
+				"leader",
+				fmt.Sprintf("%d", beforePartition.Leader),
+				fmt.Sprintf("%d", afterPartition.Leader),
+			)
+
+			// ISR order isn't meaningful
+			beforeISR := util.CopyInts(beforePartition.ISR)
+			sort.Ints(beforeISR)
+			afterISR := util.CopyInts(afterPartition.ISR)
+			sort.Ints(afterISR)
+			diffs = appendDiff(diffs, resource, "isr", intsString(beforeISR), intsString(afterISR))
+		}
+	}
+
+	return diffs
+}
+
+// FormatSnapshotDiffs creates a pretty table that lists the argument snapshot differences.
+func FormatSnapshotDiffs(diffs []SnapshotDiff) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Resource",
+			"Field",
+			"Before",
+			"After",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, diff := range diffs {
+		table.Append(
+			[]string{
+				diff.Resource,
+				diff.Field,
+				diff.Before,
+				diff.After,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func existenceDiff(resource string, inBefore bool, inAfter bool) SnapshotDiff {
+	return SnapshotDiff{
+		Resource: resource,
+		Field:    "exists",
+		Before:   fmt.Sprintf("%v", inBefore),
+		After:    fmt.Sprintf("%v", inAfter),
+	}
+}
+
+func appendDiff(
+	diffs []SnapshotDiff,
+	resource string,
+	field string,
+	before string,
+	after string,
+) []SnapshotDiff {
+	if before == after {
+		return diffs
+	}
+	return append(
+		diffs,
+		SnapshotDiff{
+			Resource: resource,
+			Field:    field,
+			Before:   before,
+			After:    after,
+		},
+	)
+}
+
+func appendConfigDiffs(
+	diffs []SnapshotDiff,
+	resource string,
+	before map[string]string,
+	after map[string]string,
+) []SnapshotDiff {
+	keys := []string{}
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		diffs = appendDiff(diffs, resource, fmt.Sprintf("config %s", key), before[key], after[key])
+	}
+	return diffs
+}
+
+func sortedUniqueInts(values []int) []int {
+	valueSet := map[int]struct{}{}
+	unique := []int{}
+
+	for _, value := range values {
+		if _, ok := valueSet[value]; !ok {
+			valueSet[value] = struct{}{}
+			unique = append(unique, value)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
+
+func intsString(values []int) string {
+	strs := []string{}
+	for _, value := range values {
+		strs = append(strs, fmt.Sprintf("%d", value))
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	before := ClusterSnapshot{
+		Version:   snapshotVersion,
+		ClusterID: "test-cluster",
+		CreatedAt: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		Brokers: []BrokerInfo{
+			{
+				ID:        1,
+				Endpoints: []string{"PLAINTEXT://host1:9092"},
+				Rack:      "zone1",
+			},
+			{
+				ID:        2,
+				Endpoints: []string{"PLAINTEXT://host2:9092"},
+				Rack:      "zone2",
+				Config:    map[string]string{"log.retention.ms": "1000"},
+			},
+		},
+		Topics: []TopicInfo{
+			{
+				Name:   "topic1",
+				Config: map[string]string{"retention.ms": "1000"},
+				Partitions: []PartitionInfo{
+					{ID: 0, Leader: 1, Replicas: []int{1, 2}, ISR: []int{1, 2}},
+					{ID: 1, Leader: 2, Replicas: []int{2, 1}, ISR: []int{2, 1}},
+				},
+			},
+			{
+				Name:    "topic2",
+				TopicID: "old-id",
+				Partitions: []PartitionInfo{
+					{ID: 0, Leader: 1, Replicas: []int{1}, ISR: []int{1}},
+				},
+			},
+		},
+	}
+
+	tempDir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "snapshot.json")
+	require.NoError(t, before.WriteFile(path))
+
+	loaded, err := LoadSnapshotFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []SnapshotDiff{}, DiffSnapshots(before, loaded))
+
+	after, err := LoadSnapshotFile(path)
+	require.NoError(t, err)
+	after.Brokers = after.Brokers[:1]
+	after.Topics[0].Config["retention.ms"] = "2000"
+	after.Topics[0].Partitions[0].Leader = 2
+	after.Topics[0].Partitions[0].Replicas = []int{2, 1}
+	after.Topics[0].Partitions[1].ISR = []int{1, 2}
+	after.Topics[0].Partitions = append(
+		after.Topics[0].Partitions,
+		PartitionInfo{ID: 2, Leader: 1, Replicas: []int{1, 2}, ISR: []int{1, 2}},
+	)
+	after.Topics = append(after.Topics, TopicInfo{Name: "topic0"})
+	after.Topics[1].TopicID = "new-id"
+
+	assert.Equal(
+		t,
+		[]SnapshotDiff{
+			{
+				Resource: "broker 2",
+				Field:    "exists",
+				Before:   "true",
+				After:    "false",
+			},
+			{
+				Resource: "topic topic0",
+				Field:    "exists",
+				Before:   "false",
+				After:    "true",
+			},
+			{
+				Resource: "topic topic1",
+				Field:    "config retention.ms",
+				Before:   "1000",
+				After:    "2000",
+			},
+			{
+				Resource: "topic topic1 partition 0",
+				Field:    "replicas",
+				Before:   "1,2",
+				After:    "2,1",
+			},
+			{
+				Resource: "topic topic1 partition 0",
+				Field:    "leader",
+				Before:   "1",
+				After:    "2",
+			},
+			{
+				Resource: "topic topic1 partition 2",
+				Field:    "exists",
+				Before:   "false",
+				After:    "true",
+			},
+			{
+				Resource: "topic topic2",
+				Field:    "id",
+				Before:   "old-id",
+				After:    "new-id",
+			},
+		},
+		DiffSnapshots(before, after),
+	)
+
+	invalid := filepath.Join(tempDir, "invalid.json")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte(`{"version": 2}`), 0644))
+	_, err = LoadSnapshotFile(invalid)
+	assert.Error(t, err)
+}
@@ -0,0 +1,214 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the DescribeConfigs API, so
+	// the requests are made via the protocol package instead. v1 is the first version that
+	// returns the source of each config, which is needed to pick out the topic overrides.
+	describeConfigsAPIKey     int16 = 32
+	describeConfigsAPIVersion int16 = 1
+
+	topicResourceType        int8 = 2
+	dynamicTopicConfigSource int8 = 1
+
+	// describeConfigsBatchSize is the maximum number of topics in a single DescribeConfigs
+	// request.
+	describeConfigsBatchSize = 500
+)
+
+// getTopicConfigs gets the config overrides of the argument topics, keyed by topic name. If
+// batched is set and the cluster supports it, the configs are fetched with batched
+// DescribeConfigs requests, which is much faster than reading them one at a time from
+// zookeeper in large clusters. Topics that the brokers can't describe, e.g. because they were
+// just created and the brokers' metadata isn't up-to-date yet, are read from zookeeper
+// instead.
+//
+// Config updates are written to zookeeper, so the batched path is only used for read-only
+// listings; readbacks need to see the zookeeper state directly.
+func (c *Client) getTopicConfigs(
+	ctx context.Context,
+	names []string,
+	batched bool,
+) (map[string]map[string]string, error) {
+	configs := map[string]map[string]string{}
+
+	supported := false
+	if batched {
+		var err error
+		supported, err = c.SupportsFeature(ctx, FeatureDescribeConfigs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if supported {
+		for start := 0; start < len(names); start += describeConfigsBatchSize {
+			end := minInt(start+describeConfigsBatchSize, len(names))
+
+			batchConfigs, err := c.describeTopicConfigs(ctx, names[start:end])
+			if err != nil {
+				// Fall back to zookeeper, which has the same information
+				log.Debugf("Error describing topic configs, reading them from zookeeper: %+v", err)
+				break
+			}
+			for name, config := range batchConfigs {
+				configs[name] = config
+			}
+		}
+	}
+
+	missingNames := []string{}
+	for _, name := range names {
+		if _, ok := configs[name]; !ok {
+			missingNames = append(missingNames, name)
+		}
+	}
+	if len(missingNames) == 0 {
+		return configs, nil
+	}
+
+	zkConfigs := make([]map[string]string, len(missingNames))
+	err := util.RunParallel(
+		ctx,
+		len(missingNames),
+		maxPoolSize,
+		func(ctx context.Context, i int) error {
+			var err error
+			zkConfigs[i], err = c.getZKTopicConfig(ctx, missingNames[i])
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range missingNames {
+		configs[name] = zkConfigs[i]
+	}
+	return configs, nil
+}
+
+// describeTopicConfigs gets the config overrides of the argument topics via a single
+// DescribeConfigs request. Topics that the broker returned errors for are omitted.
+func (c *Client) describeTopicConfigs(
+	ctx context.Context,
+	names []string,
+) (map[string]map[string]string, error) {
+	var response *protocol.Decoder
+	err := c.withBrokerRetries(ctx, "describe configs", false, func() error {
+		var err error
+		response, err = protocol.RoundTrip(
+			ctx,
+			c.bootstrapAddrs[0],
+			describeConfigsAPIKey,
+			describeConfigsAPIVersion,
+			encodeDescribeConfigsRequest(names),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeDescribeConfigsResponse(response)
+}
+
+func (c *Client) getZKTopicConfig(ctx context.Context, name string) (map[string]string, error) {
+	zkTopicConfig := zkTopicConfig{}
+	_, err := c.zkClient.GetJSON(
+		ctx,
+		c.zNode(topicConfigsPath, name),
+		&zkTopicConfig,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return zkTopicConfig.Config, nil
+}
+
+// encodeDescribeConfigsRequest encodes the body of a v1 DescribeConfigs request for all of
+// the configs of the argument topics.
+func encodeDescribeConfigsRequest(names []string) *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	body.WriteInt32(int32(len(names)))
+	for _, name := range names {
+		body.WriteInt8(topicResourceType)
+		body.WriteString(name)
+		// A null keys array requests all configs
+		body.WriteInt32(-1)
+	}
+
+	// Don't include synonyms
+	body.WriteBool(false)
+
+	return body
+}
+
+// decodeDescribeConfigsResponse decodes the body of a v1 DescribeConfigs response into the
+// config overrides of each topic.
+func decodeDescribeConfigsResponse(
+	response *protocol.Decoder,
+) (map[string]map[string]string, error) {
+	configs := map[string]map[string]string{}
+
+	// Throttle time
+	response.ReadInt32()
+
+	numResources := response.ReadInt32()
+	for i := 0; i < int(numResources) && response.Err() == nil; i++ {
+		errorCode := response.ReadInt16()
+		errorMessage := response.ReadString()
+		response.ReadInt8()
+		name := response.ReadString()
+
+		config := map[string]string{}
+
+		numEntries := response.ReadInt32()
+		for j := 0; j < int(numEntries) && response.Err() == nil; j++ {
+			key := response.ReadString()
+			value := response.ReadString()
+			// Read-only
+			response.ReadBool()
+			source := response.ReadInt8()
+			// Sensitive
+			response.ReadBool()
+
+			numSynonyms := response.ReadInt32()
+			for k := 0; k < int(numSynonyms) && response.Err() == nil; k++ {
+				response.ReadString()
+				response.ReadString()
+				response.ReadInt8()
+			}
+
+			if source == dynamicTopicConfigSource {
+				config[key] = value
+			}
+		}
+
+		if errorCode != 0 {
+			log.Debugf(
+				"Error describing configs of topic %s: %+v (%s)",
+				name,
+				kafka.Error(errorCode),
+				errorMessage,
+			)
+			continue
+		}
+		configs[name] = config
+	}
+
+	if err := response.Err(); err != nil {
+		return nil, fmt.Errorf("Error decoding DescribeConfigs response: %+v", err)
+	}
+	return configs, nil
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeDescribeConfigsResponse(t *testing.T) {
+	type configEntry struct {
+		key    string
+		value  string
+		source int8
+	}
+
+	response := &protocol.Encoder{}
+	encodeResource := func(name string, errorCode int16, entries []configEntry) {
+		response.WriteInt16(errorCode)
+		response.WriteNullString()
+		response.WriteInt8(topicResourceType)
+		response.WriteString(name)
+		response.WriteInt32(int32(len(entries)))
+		for _, entry := range entries {
+			response.WriteString(entry.key)
+			response.WriteString(entry.value)
+			response.WriteBool(false)
+			response.WriteInt8(entry.source)
+			response.WriteBool(false)
+			response.WriteInt32(0)
+		}
+	}
+
+	response.WriteInt32(0)
+	response.WriteInt32(3)
+	encodeResource(
+		"topic1",
+		0,
+		[]configEntry{
+			{key: "retention.ms", value: "100000", source: dynamicTopicConfigSource},
+			{key: "cleanup.policy", value: "delete", source: 5},
+		},
+	)
+	encodeResource(
+		"topic2",
+		0,
+		[]configEntry{
+			{key: "cleanup.policy", value: "delete", source: 5},
+		},
+	)
+	// Topics with errors are omitted so that they're read from zookeeper instead
+	encodeResource("topic3", 3, nil)
+
+	configs, err := decodeDescribeConfigsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]map[string]string{
+			"topic1": {
+				"retention.ms": "100000",
+			},
+			"topic2": {},
+		},
+		configs,
+	)
+
+	_, err = decodeDescribeConfigsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes()[:30])),
+	)
+	assert.Error(t, err)
+}
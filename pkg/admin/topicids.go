@@ -0,0 +1,176 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// topicIDsPath is the zk path, relative to the cluster prefix, that stores the IDs and
+// creation times of the topics that topicctl has applied. Like the maintenance node, it's
+// only used by topicctl.
+const topicIDsPath = "/topicctl/topic-ids"
+
+// DefaultRecreatedWindow is the default for how long after being re-created that topics are
+// flagged.
+const DefaultRecreatedWindow = 7 * 24 * time.Hour
+
+type zkTopicIDs struct {
+	Version       int                  `json:"version"`
+	Topics        map[string]string    `json:"topics"`
+	CreationTimes map[string]time.Time `json:"creationTimes,omitempty"`
+}
+
+// RecordedTopic is the identity of a topic as of the last time that it was applied.
+type RecordedTopic struct {
+	// ID is the topic ID; it's empty in clusters running versions of Kafka before 2.8.
+	ID string
+
+	// CreatedAt is the time that the topic was created.
+	CreatedAt time.Time
+}
+
+// Recreated returns whether the argument topic was deleted and re-created since it was
+// recorded. Topic IDs are compared if both are set; otherwise, the creation times are.
+func (r RecordedTopic) Recreated(topicInfo TopicInfo) bool {
+	if r.ID != "" && topicInfo.TopicID != "" {
+		return r.ID != topicInfo.TopicID
+	}
+	if !r.CreatedAt.IsZero() && !topicInfo.CreatedAt.IsZero() {
+		return !r.CreatedAt.Equal(topicInfo.CreatedAt)
+	}
+	return false
+}
+
+// RecreatedWithin returns whether the argument topic was re-created since it was recorded
+// and is younger than the argument window. A window of 0 means no limit.
+func (r RecordedTopic) RecreatedWithin(
+	topicInfo TopicInfo,
+	window time.Duration,
+	now time.Time,
+) bool {
+	if !r.Recreated(topicInfo) {
+		return false
+	}
+	return window == 0 || topicInfo.CreatedAt.IsZero() || now.Sub(topicInfo.CreatedAt) <= window
+}
+
+// GetRecordedTopics returns the recorded identities of all of the topics that topicctl has
+// applied, keyed by topic name.
+func (c *Client) GetRecordedTopics(ctx context.Context) (map[string]RecordedTopic, error) {
+	topicIDsObj, _, err := c.getTopicIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedTopics := map[string]RecordedTopic{}
+	for topic, topicID := range topicIDsObj.Topics {
+		recordedTopics[topic] = RecordedTopic{ID: topicID}
+	}
+	for topic, createdAt := range topicIDsObj.CreationTimes {
+		recordedTopic := recordedTopics[topic]
+		recordedTopic.CreatedAt = createdAt
+		recordedTopics[topic] = recordedTopic
+	}
+
+	return recordedTopics, nil
+}
+
+// GetRecordedTopic returns the identity that was last recorded for the argument topic. Its
+// fields are empty if none has been recorded. Comparing this to the current state of the
+// topic shows whether it was deleted and re-created since then.
+func (c *Client) GetRecordedTopic(ctx context.Context, topic string) (RecordedTopic, error) {
+	recordedTopics, err := c.GetRecordedTopics(ctx)
+	if err != nil {
+		return RecordedTopic{}, err
+	}
+	return recordedTopics[topic], nil
+}
+
+// RecordTopic records the ID and creation time of the argument topic, replacing any
+// previous ones.
+func (c *Client) RecordTopic(ctx context.Context, topicInfo TopicInfo) error {
+	if c.readOnly {
+		return errors.New("Cannot record topic in read-only mode")
+	}
+
+	topicIDsObj, version, err := c.getTopicIDs(ctx)
+	if err != nil {
+		return err
+	}
+	if topicIDsObj.Topics[topicInfo.Name] == topicInfo.TopicID &&
+		topicIDsObj.CreationTimes[topicInfo.Name].Equal(topicInfo.CreatedAt) {
+		return nil
+	}
+
+	if topicInfo.TopicID != "" {
+		topicIDsObj.Topics[topicInfo.Name] = topicInfo.TopicID
+	} else {
+		delete(topicIDsObj.Topics, topicInfo.Name)
+	}
+	if !topicInfo.CreatedAt.IsZero() {
+		topicIDsObj.CreationTimes[topicInfo.Name] = topicInfo.CreatedAt
+	} else {
+		delete(topicIDsObj.CreationTimes, topicInfo.Name)
+	}
+
+	zPath := c.zNode(topicIDsPath)
+
+	if version >= 0 {
+		log.Debugf("Updating topic IDs at %s: %+v", zPath, topicIDsObj)
+		_, err := c.zkClient.SetJSON(ctx, zPath, topicIDsObj, version)
+		return err
+	}
+
+	// Parent might not already exist
+	zRoot := filepath.Dir(zPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zRoot)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Infof("Creating topicctl path: %s", zRoot)
+		if err := c.zkClient.Create(ctx, zRoot, nil, false); err != nil {
+			return err
+		}
+	}
+
+	log.Debugf("Creating topic IDs at %s: %+v", zPath, topicIDsObj)
+	return c.zkClient.CreateJSON(ctx, zPath, topicIDsObj, false)
+}
+
+// getTopicIDs returns the recorded topic IDs along with the version of the zk node. If the
+// node doesn't exist, the version is -1.
+func (c *Client) getTopicIDs(ctx context.Context) (zkTopicIDs, int32, error) {
+	topicIDsObj := zkTopicIDs{
+		Version:       1,
+		Topics:        map[string]string{},
+		CreationTimes: map[string]time.Time{},
+	}
+	zPath := c.zNode(topicIDsPath)
+
+	exists, _, err := c.zkClient.Exists(ctx, zPath)
+	if err != nil {
+		return topicIDsObj, 0, err
+	}
+	if !exists {
+		return topicIDsObj, -1, nil
+	}
+
+	stats, err := c.zkClient.GetJSON(ctx, zPath, &topicIDsObj)
+	if err != nil {
+		return topicIDsObj, 0, err
+	}
+	if topicIDsObj.Topics == nil {
+		topicIDsObj.Topics = map[string]string{}
+	}
+	if topicIDsObj.CreationTimes == nil {
+		topicIDsObj.CreationTimes = map[string]time.Time{}
+	}
+
+	return topicIDsObj, stats.Version, nil
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordedTopicRecreated(t *testing.T) {
+	createdAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := createdAt.Add(48 * time.Hour)
+
+	type testCase struct {
+		description     string
+		recordedTopic   RecordedTopic
+		topicInfo       TopicInfo
+		expRecreated    bool
+		expRecentWindow bool
+	}
+
+	testCases := []testCase{
+		{
+			description:   "nothing recorded",
+			recordedTopic: RecordedTopic{},
+			topicInfo:     TopicInfo{TopicID: "id-1", CreatedAt: createdAt},
+		},
+		{
+			description:   "same ID",
+			recordedTopic: RecordedTopic{ID: "id-1", CreatedAt: createdAt.Add(-time.Hour)},
+			topicInfo:     TopicInfo{TopicID: "id-1", CreatedAt: createdAt},
+		},
+		{
+			description:     "different ID",
+			recordedTopic:   RecordedTopic{ID: "id-1", CreatedAt: createdAt},
+			topicInfo:       TopicInfo{TopicID: "id-2", CreatedAt: createdAt.Add(47 * time.Hour)},
+			expRecreated:    true,
+			expRecentWindow: true,
+		},
+		{
+			description:   "different creation time without IDs",
+			recordedTopic: RecordedTopic{CreatedAt: createdAt.Add(-time.Hour)},
+			topicInfo:     TopicInfo{CreatedAt: createdAt},
+			expRecreated:  true,
+		},
+		{
+			description:   "same creation time without IDs",
+			recordedTopic: RecordedTopic{CreatedAt: createdAt},
+			topicInfo:     TopicInfo{CreatedAt: createdAt},
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(
+			t,
+			testCase.expRecreated,
+			testCase.recordedTopic.Recreated(testCase.topicInfo),
+			testCase.description,
+		)
+		assert.Equal(
+			t,
+			testCase.expRecentWindow,
+			testCase.recordedTopic.RecreatedWithin(testCase.topicInfo, 24*time.Hour, now),
+			testCase.description,
+		)
+		assert.Equal(
+			t,
+			testCase.expRecreated,
+			testCase.recordedTopic.RecreatedWithin(testCase.topicInfo, 0, now),
+			testCase.description,
+		)
+	}
+}
@@ -15,6 +15,9 @@ const (
 	// RetentionKey is the config key used for topic time retention.
 	RetentionKey = "retention.ms"
 
+	// RetentionBytesKey is the config key used for topic size retention.
+	RetentionBytesKey = "retention.bytes"
+
 	// LeaderThrottledKey is the config key for the leader throttle rate.
 	LeaderThrottledKey = "leader.replication.throttled.rate"
 
@@ -45,12 +48,27 @@ type BrokerInfo struct {
 	Config           map[string]string `json:"config"`
 }
 
+// BrokerStats contains the partition counts and the inferred Kafka version for a single
+// broker.
+type BrokerStats struct {
+	BrokerPartitionSummary
+	KafkaVersion string `json:"kafkaVersion"`
+}
+
 // TopicInfo represents the information stored about a topic in zookeeper.
 type TopicInfo struct {
 	Name       string            `json:"name"`
 	Config     map[string]string `json:"config"`
 	Partitions []PartitionInfo   `json:"partitions"`
 	Version    int               `json:"version"`
+
+	// TopicID is the UUID that Kafka assigns to the topic when it's created. It's only set
+	// in clusters running Kafka 2.8 or later.
+	TopicID string `json:"topicID,omitempty"`
+
+	// CreatedAt is the time that the topic was created, based on the creation time of its
+	// node in zookeeper.
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 // PartitionInfo represents the information stored about a topic
@@ -95,6 +113,7 @@ type zkBrokerConfig struct {
 type zkTopicInfo struct {
 	Version    int              `json:"version"`
 	Partitions map[string][]int `json:"partitions"`
+	TopicID    string           `json:"topic_id"`
 }
 
 type zkTopicConfig struct {
@@ -131,6 +150,12 @@ type zkElectionTopicPartition struct {
 	Partition int    `json:"partition"`
 }
 
+type zkController struct {
+	Version   int    `json:"version"`
+	BrokerID  int    `json:"brokerid"`
+	Timestamp string `json:"timestamp"`
+}
+
 type zkChangeNotification struct {
 	Version    int    `json:"version"`
 	EntityPath string `json:"entity_path"`
@@ -323,6 +348,40 @@ func (t TopicInfo) RackCounts(brokerRacks map[int]string) (int, int, error) {
 	return minRacks, maxRacks, nil
 }
 
+// RackViolations returns the partitions whose replicas aren't spread across the expected
+// number of distinct racks. If expectedRacks is 0, then each partition is expected to be
+// spread across as many racks as possible given its replica count and the racks of the
+// argument brokers. Partitions with replicas on brokers that aren't in the argument list
+// (e.g., because they were replaced) are always included.
+func (t TopicInfo) RackViolations(brokers []BrokerInfo, expectedRacks int) []PartitionInfo {
+	brokerRacks := BrokerRacks(brokers)
+	numRacks := len(DistinctRacks(brokers))
+	violations := []PartitionInfo{}
+
+	for _, partition := range t.Partitions {
+		partitionRacks, err := partition.NumRacks(brokerRacks)
+		if err != nil ||
+			partitionRacks != ExpectedPartitionRacks(
+				len(partition.Replicas),
+				numRacks,
+				expectedRacks,
+			) {
+			violations = append(violations, partition)
+		}
+	}
+
+	return violations
+}
+
+// ExpectedPartitionRacks returns the number of distinct racks that a partition with the
+// argument number of replicas should be spread across. See RackViolations for details.
+func ExpectedPartitionRacks(numReplicas int, numRacks int, expectedRacks int) int {
+	if expectedRacks > 0 {
+		return expectedRacks
+	}
+	return minInt(numReplicas, numRacks)
+}
+
 // AllReplicasInSync returns whether all partitions have ISR == replicas
 // (ignoring order).
 func (t TopicInfo) AllReplicasInSync() bool {
@@ -750,6 +809,83 @@ func AssignmentDiffs(
 	return results
 }
 
+// ReplicaMoves returns the number of replicas in each of the desired assignments that aren't
+// in the corresponding current assignment, i.e. the number of replicas whose data needs to
+// be copied to a new broker, keyed by partition ID. Changes to the order of the replicas,
+// e.g. leader changes, don't count as moves.
+func ReplicaMoves(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+) map[int]int {
+	currByID := map[int]PartitionAssignment{}
+	for _, assignment := range curr {
+		currByID[assignment.ID] = assignment
+	}
+
+	moves := map[int]int{}
+
+	for _, assignment := range desired {
+		currAssignment := currByID[assignment.ID]
+		for _, replica := range assignment.Replicas {
+			if currAssignment.Index(replica) == -1 {
+				moves[assignment.ID]++
+			}
+		}
+	}
+
+	return moves
+}
+
+// MoveBytes estimates the number of bytes that need to be copied between brokers to get
+// from the current to the desired assignments, given the size of a single replica of each
+// partition, keyed by partition ID. Partitions without a size are counted as empty.
+func MoveBytes(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+	partitionSizes map[int]int64,
+) int64 {
+	var total int64
+	for partition, moves := range ReplicaMoves(curr, desired) {
+		total += int64(moves) * partitionSizes[partition]
+	}
+	return total
+}
+
+// BrokerMoveBytes estimates the number of bytes that each broker will receive (ingress) and
+// send (egress) when going from the current to the desired assignments, given the size of a
+// single replica of each partition, keyed by partition ID. New replicas fetch their data from
+// the partition's current leader, so all of the egress for a partition is attributed to it.
+func BrokerMoveBytes(
+	curr []PartitionAssignment,
+	desired []PartitionAssignment,
+	partitionSizes map[int]int64,
+) (map[int]int64, map[int]int64) {
+	currByID := map[int]PartitionAssignment{}
+	for _, assignment := range curr {
+		currByID[assignment.ID] = assignment
+	}
+
+	ingress := map[int]int64{}
+	egress := map[int]int64{}
+
+	for _, assignment := range desired {
+		currAssignment, ok := currByID[assignment.ID]
+		if !ok || len(currAssignment.Replicas) == 0 {
+			continue
+		}
+		leader := currAssignment.Replicas[0]
+
+		for _, replica := range assignment.Replicas {
+			if currAssignment.Index(replica) == -1 {
+				ingress[replica] += partitionSizes[assignment.ID]
+				egress[leader] += partitionSizes[assignment.ID]
+			}
+		}
+	}
+
+	return ingress, egress
+}
+
 // AssignmentsToUpdate returns the subset of assignments that need to be
 // updated given the current and desired states.
 func AssignmentsToUpdate(
@@ -774,6 +910,82 @@ func AssignmentsToUpdate(
 	return newAssignments
 }
 
+// CheckReplicaRemovals checks that updating the partitions of the argument topic to the
+// desired assignments won't remove replicas in an unsafe way. For each partition that loses
+// replicas, it checks that the removed replicas aren't the only in-sync ones when some of the
+// current replicas are kept. It also checks that the kept in-sync replicas plus any new ones,
+// which Kafka waits for before completing a reassignment, will be enough to satisfy minISR.
+func CheckReplicaRemovals(
+	topicInfo TopicInfo,
+	desired []PartitionAssignment,
+	minISR int,
+) error {
+	partitionsByID := map[int]PartitionInfo{}
+	for _, partition := range topicInfo.Partitions {
+		partitionsByID[partition.ID] = partition
+	}
+
+	for _, assignment := range desired {
+		partition, ok := partitionsByID[assignment.ID]
+		if !ok {
+			// New partition, nothing can be removed
+			continue
+		}
+
+		removed := []int{}
+		for _, replica := range partition.Replicas {
+			if assignment.Index(replica) == -1 {
+				removed = append(removed, replica)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		var kept, keptInSync, added int
+
+		for _, replica := range assignment.Replicas {
+			if !intInSlice(replica, partition.Replicas) {
+				added++
+				continue
+			}
+			kept++
+			if intInSlice(replica, partition.ISR) {
+				keptInSync++
+			}
+		}
+
+		removedInSync := []int{}
+		for _, replica := range removed {
+			if intInSlice(replica, partition.ISR) {
+				removedInSync = append(removedInSync, replica)
+			}
+		}
+
+		if kept > 0 && keptInSync == 0 && len(removedInSync) > 0 {
+			return fmt.Errorf(
+				"Cannot remove replica(s) %+v from partition %d of topic %s because they are its only in-sync replicas (ISR: %+v)",
+				removedInSync,
+				partition.ID,
+				topicInfo.Name,
+				partition.ISR,
+			)
+		}
+		if keptInSync+added < minISR {
+			return fmt.Errorf(
+				"Cannot remove replica(s) %+v from partition %d of topic %s because it would have %d in-sync replica(s), fewer than min.insync.replicas (%d)",
+				removed,
+				partition.ID,
+				topicInfo.Name,
+				keptInSync+added,
+				minISR,
+			)
+		}
+	}
+
+	return nil
+}
+
 // NewLeaderPartitions returns the partition IDs which will have new leaders
 // given the current and desired assignments.
 func NewLeaderPartitions(
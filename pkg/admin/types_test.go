@@ -166,6 +166,23 @@ func TestTopicRackHelpers(t *testing.T) {
 	racks, err := testTopic.Partitions[0].Racks(brokerRacks)
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"rack1", "rack2", "rack3"}, racks)
+
+	assert.Equal(
+		t,
+		[]int{1},
+		PartitionIDs(testTopic.RackViolations(testBrokers, 0)),
+	)
+	assert.Equal(
+		t,
+		[]int{0, 2},
+		PartitionIDs(testTopic.RackViolations(testBrokers, 1)),
+	)
+	// Replicas on brokers that are no longer in the cluster are always violations
+	assert.Equal(
+		t,
+		[]int{0, 1, 2},
+		PartitionIDs(testTopic.RackViolations(testBrokers[0:4], 0)),
+	)
 }
 
 func TestTopicThrottleHelpers(t *testing.T) {
@@ -617,4 +634,107 @@ func TestDiffHelpers(t *testing.T) {
 		[]int{3, 4},
 		NewLeaderPartitions(curr, desired),
 	)
+	assert.Equal(
+		t,
+		map[int]int{2: 1, 4: 1, 5: 3},
+		ReplicaMoves(curr, desired),
+	)
+	assert.Equal(
+		t,
+		int64(180),
+		MoveBytes(curr, desired, map[int]int64{2: 100, 4: 50, 5: 10}),
+	)
+
+	ingress, egress := BrokerMoveBytes(curr, desired, map[int]int64{2: 100, 4: 50, 5: 10})
+	assert.Equal(t, map[int]int64{2: 50, 4: 100}, ingress)
+	assert.Equal(t, map[int]int64{1: 100, 6: 50}, egress)
+}
+
+func TestCheckReplicaRemovals(t *testing.T) {
+	topicInfo := TopicInfo{
+		Name: "topic1",
+		Partitions: []PartitionInfo{
+			{
+				ID:       0,
+				Replicas: []int{1, 2, 3},
+				ISR:      []int{1, 2, 3},
+			},
+			{
+				ID:       1,
+				Replicas: []int{1, 2, 3},
+				ISR:      []int{3},
+			},
+			{
+				ID:       2,
+				Replicas: []int{1, 2, 3},
+				ISR:      []int{1, 2},
+			},
+			{
+				ID:       3,
+				Replicas: []int{1, 2},
+				ISR:      []int{1},
+			},
+			{
+				ID:       4,
+				Replicas: []int{1, 2},
+				ISR:      []int{2},
+			},
+		},
+	}
+
+	type testCase struct {
+		description string
+		desired     []PartitionAssignment
+		minISR      int
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "Remove out-of-sync replica",
+			desired:     []PartitionAssignment{{ID: 0, Replicas: []int{1, 2}}},
+			minISR:      2,
+		},
+		{
+			description: "Remove only in-sync replica",
+			desired:     []PartitionAssignment{{ID: 1, Replicas: []int{1, 2}}},
+			minISR:      1,
+			expectedErr: true,
+		},
+		{
+			description: "Remove replica below min ISR",
+			desired:     []PartitionAssignment{{ID: 2, Replicas: []int{1, 3}}},
+			minISR:      2,
+			expectedErr: true,
+		},
+		{
+			description: "Remove replica at min ISR",
+			desired:     []PartitionAssignment{{ID: 2, Replicas: []int{1, 3}}},
+			minISR:      1,
+		},
+		{
+			description: "Move all replicas",
+			desired:     []PartitionAssignment{{ID: 3, Replicas: []int{3, 4}}},
+			minISR:      2,
+		},
+		{
+			description: "Only add replicas",
+			desired:     []PartitionAssignment{{ID: 4, Replicas: []int{2, 1, 3}}},
+			minISR:      3,
+		},
+		{
+			description: "New partition",
+			desired:     []PartitionAssignment{{ID: 5, Replicas: []int{1}}},
+			minISR:      2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := CheckReplicaRemovals(topicInfo, testCase.desired, testCase.minISR)
+		if testCase.expectedErr {
+			assert.NotNil(t, err, testCase.description)
+		} else {
+			assert.Nil(t, err, testCase.description)
+		}
+	}
 }
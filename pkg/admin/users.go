@@ -0,0 +1,541 @@
+package admin
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	szk "github.com/samuel/go-zookeeper/zk"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support the SCRAM credential APIs,
+	// so the (v0) requests are made via the protocol package instead. v0 requires Kafka 2.7
+	// or newer and is a flexible version.
+	describeUserScramCredentialsAPIKey int16 = 50
+	alterUserScramCredentialsAPIKey    int16 = 51
+	scramCredentialsAPIVersion         int16 = 0
+
+	// MinScramIterations and MaxScramIterations are the bounds on the number of iterations
+	// that Kafka accepts for SCRAM credentials. MinScramIterations is also the default.
+	MinScramIterations = 4096
+	MaxScramIterations = 16384
+
+	userConfigsPath = "/config/users"
+
+	scramSaltSize = 32
+
+	resourceNotFoundError = 91
+)
+
+// ScramMechanism is the name of a SCRAM mechanism supported by Kafka.
+type ScramMechanism string
+
+const (
+	// ScramMechanismSHA256 is SCRAM with SHA-256 hashes.
+	ScramMechanismSHA256 ScramMechanism = "SCRAM-SHA-256"
+
+	// ScramMechanismSHA512 is SCRAM with SHA-512 hashes.
+	ScramMechanismSHA512 ScramMechanism = "SCRAM-SHA-512"
+)
+
+// AllScramMechanisms are all of the SCRAM mechanisms supported by Kafka.
+var AllScramMechanisms = []ScramMechanism{
+	ScramMechanismSHA256,
+	ScramMechanismSHA512,
+}
+
+// ParseScramMechanism converts the argument string into a ScramMechanism, ignoring case.
+func ParseScramMechanism(value string) (ScramMechanism, error) {
+	for _, mechanism := range AllScramMechanisms {
+		if strings.EqualFold(value, string(mechanism)) {
+			return mechanism, nil
+		}
+	}
+	return "", fmt.Errorf("SCRAM mechanism must be in %+v", AllScramMechanisms)
+}
+
+// typeID returns the ID of the mechanism in the SCRAM credential APIs.
+func (m ScramMechanism) typeID() int8 {
+	switch m {
+	case ScramMechanismSHA256:
+		return 1
+	case ScramMechanismSHA512:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (m ScramMechanism) hash() func() hash.Hash {
+	if m == ScramMechanismSHA512 {
+		return sha512.New
+	}
+	return sha256.New
+}
+
+func scramMechanismFromTypeID(typeID int8) ScramMechanism {
+	for _, mechanism := range AllScramMechanisms {
+		if mechanism.typeID() == typeID {
+			return mechanism
+		}
+	}
+	return ScramMechanism(fmt.Sprintf("unknown (%d)", typeID))
+}
+
+// UserInfo represents a SCRAM user and the mechanisms that it has credentials for.
+type UserInfo struct {
+	Name        string
+	Credentials []ScramCredentialInfo
+}
+
+// ScramCredentialInfo describes a single SCRAM credential of a user. The credentials
+// themselves are never returned by Kafka.
+type ScramCredentialInfo struct {
+	Mechanism  ScramMechanism
+	Iterations int
+}
+
+// HasMechanism returns whether the user has a credential for the argument mechanism.
+func (u UserInfo) HasMechanism(mechanism ScramMechanism) bool {
+	for _, credential := range u.Credentials {
+		if credential.Mechanism == mechanism {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUsers returns all of the users in the cluster with SCRAM credentials, sorted by name.
+// If the cluster supports it, the users are fetched through the DescribeUserScramCredentials
+// API; otherwise, they're read from zookeeper.
+func (c *Client) GetUsers(ctx context.Context) ([]UserInfo, error) {
+	supported, err := c.SupportsFeature(ctx, FeatureScramCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []UserInfo
+	if supported {
+		var response *protocol.Decoder
+		err = c.withBrokerRetries(ctx, "describe users", false, func() error {
+			var err error
+			response, err = protocol.FlexibleRoundTrip(
+				ctx,
+				c.bootstrapAddrs[0],
+				describeUserScramCredentialsAPIKey,
+				scramCredentialsAPIVersion,
+				encodeDescribeUserScramCredentialsRequest(),
+			)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		users, err = decodeDescribeUserScramCredentialsResponse(response)
+	} else {
+		users, err = c.getZKUsers(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(a, b int) bool {
+		return users[a].Name < users[b].Name
+	})
+	return users, nil
+}
+
+// UpsertUserScramCredential creates a SCRAM credential for the argument user and mechanism
+// with the argument password, replacing the existing one if it's already set. The password
+// is only sent to the cluster in salted and hashed form.
+func (c *Client) UpsertUserScramCredential(
+	ctx context.Context,
+	user string,
+	mechanism ScramMechanism,
+	password string,
+	iterations int,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot update users in read-only mode")
+	}
+	if mechanism.typeID() == 0 {
+		return fmt.Errorf("SCRAM mechanism must be in %+v", AllScramMechanisms)
+	}
+	if iterations < MinScramIterations || iterations > MaxScramIterations {
+		return fmt.Errorf(
+			"SCRAM iterations must be between %d and %d",
+			MinScramIterations,
+			MaxScramIterations,
+		)
+	}
+
+	salt := make([]byte, scramSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	credential := newScramCredential(mechanism, password, salt, iterations)
+
+	supported, err := c.SupportsFeature(ctx, FeatureScramCredentials)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Setting %s credential for user %s", mechanism, user)
+	if !supported {
+		return c.updateZKUserConfig(ctx, user, mechanism, credential.String())
+	}
+
+	body := &protocol.Encoder{}
+	// Deletions
+	body.WriteCompactArrayLength(0)
+	// Upsertions
+	body.WriteCompactArrayLength(1)
+	body.WriteCompactString(user)
+	body.WriteInt8(mechanism.typeID())
+	body.WriteInt32(int32(iterations))
+	body.WriteCompactBytes(credential.salt)
+	body.WriteCompactBytes(credential.saltedPassword)
+	body.WriteEmptyTaggedFields()
+	body.WriteEmptyTaggedFields()
+
+	return c.alterUserScramCredentials(ctx, body)
+}
+
+// DeleteUserScramCredential deletes the SCRAM credential of the argument user for the
+// argument mechanism.
+func (c *Client) DeleteUserScramCredential(
+	ctx context.Context,
+	user string,
+	mechanism ScramMechanism,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot update users in read-only mode")
+	}
+	if mechanism.typeID() == 0 {
+		return fmt.Errorf("SCRAM mechanism must be in %+v", AllScramMechanisms)
+	}
+
+	supported, err := c.SupportsFeature(ctx, FeatureScramCredentials)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Deleting %s credential for user %s", mechanism, user)
+	if !supported {
+		return c.updateZKUserConfig(ctx, user, mechanism, "")
+	}
+
+	body := &protocol.Encoder{}
+	// Deletions
+	body.WriteCompactArrayLength(1)
+	body.WriteCompactString(user)
+	body.WriteInt8(mechanism.typeID())
+	body.WriteEmptyTaggedFields()
+	// Upsertions
+	body.WriteCompactArrayLength(0)
+	body.WriteEmptyTaggedFields()
+
+	return c.alterUserScramCredentials(ctx, body)
+}
+
+func (c *Client) alterUserScramCredentials(ctx context.Context, body *protocol.Encoder) error {
+	// Alterations need to go to the controller, which is looked up on each attempt so that
+	// the request follows it if it moves
+	return c.withBrokerRetries(ctx, "alter users", true, func() error {
+		controllerAddr, err := c.getControllerAddr(ctx)
+		if err != nil {
+			return err
+		}
+
+		response, err := protocol.FlexibleRoundTrip(
+			ctx,
+			controllerAddr,
+			alterUserScramCredentialsAPIKey,
+			scramCredentialsAPIVersion,
+			body,
+		)
+		if err != nil {
+			return err
+		}
+		return decodeAlterUserScramCredentialsResponse(response)
+	})
+}
+
+// getZKUsers gets the users with SCRAM credentials from their config nodes in zookeeper.
+// Users that only have quotas are ignored.
+func (c *Client) getZKUsers(ctx context.Context) ([]UserInfo, error) {
+	users := []UserInfo{}
+
+	names, _, err := c.zkClient.Children(ctx, c.zNode(userConfigsPath))
+	if err == szk.ErrNoNode {
+		return users, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		zkUserConfig := zkBrokerConfig{}
+		_, err := c.zkClient.GetJSON(ctx, c.zNode(userConfigsPath, name), &zkUserConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err := userFromZKConfig(name, zkUserConfig.Config)
+		if err != nil {
+			return nil, err
+		}
+		if len(user.Credentials) > 0 {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+// updateZKUserConfig sets (or, if the value is blank, removes) the credential for the
+// argument mechanism in the user's zookeeper config node.
+func (c *Client) updateZKUserConfig(
+	ctx context.Context,
+	user string,
+	mechanism ScramMechanism,
+	value string,
+) error {
+	_, err := c.updateEntityConfig(
+		ctx,
+		"users",
+		sanitizeEntityName(user),
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  string(mechanism),
+				ConfigValue: value,
+			},
+		},
+		true,
+	)
+	return err
+}
+
+// userFromZKConfig converts the config stored in zookeeper for a user into a UserInfo. The
+// argument name is the (sanitized) name of the user's config node.
+func userFromZKConfig(name string, config map[string]string) (UserInfo, error) {
+	desanitizedName, err := url.QueryUnescape(name)
+	if err != nil {
+		desanitizedName = name
+	}
+
+	user := UserInfo{
+		Name:        desanitizedName,
+		Credentials: []ScramCredentialInfo{},
+	}
+
+	for _, mechanism := range AllScramMechanisms {
+		value, ok := config[string(mechanism)]
+		if !ok {
+			continue
+		}
+
+		iterations, err := scramCredentialIterations(value)
+		if err != nil {
+			return user, fmt.Errorf(
+				"Error parsing %s credential of user %s: %+v",
+				mechanism,
+				desanitizedName,
+				err,
+			)
+		}
+		user.Credentials = append(
+			user.Credentials,
+			ScramCredentialInfo{
+				Mechanism:  mechanism,
+				Iterations: iterations,
+			},
+		)
+	}
+
+	return user, nil
+}
+
+// scramCredential is a SCRAM credential derived from a password, as described in RFC 5802.
+type scramCredential struct {
+	salt           []byte
+	saltedPassword []byte
+	storedKey      []byte
+	serverKey      []byte
+	iterations     int
+}
+
+func newScramCredential(
+	mechanism ScramMechanism,
+	password string,
+	salt []byte,
+	iterations int,
+) scramCredential {
+	hashFunc := mechanism.hash()
+	saltedPassword := pbkdf2.Key(
+		[]byte(password),
+		salt,
+		iterations,
+		hashFunc().Size(),
+		hashFunc,
+	)
+
+	clientKeyHMAC := hmac.New(hashFunc, saltedPassword)
+	clientKeyHMAC.Write([]byte("Client Key"))
+	storedKeyHash := hashFunc()
+	storedKeyHash.Write(clientKeyHMAC.Sum(nil))
+
+	serverKeyHMAC := hmac.New(hashFunc, saltedPassword)
+	serverKeyHMAC.Write([]byte("Server Key"))
+
+	return scramCredential{
+		salt:           salt,
+		saltedPassword: saltedPassword,
+		storedKey:      storedKeyHash.Sum(nil),
+		serverKey:      serverKeyHMAC.Sum(nil),
+		iterations:     iterations,
+	}
+}
+
+// String returns the credential in the format that Kafka stores in zookeeper.
+func (s scramCredential) String() string {
+	return fmt.Sprintf(
+		"salt=%s,stored_key=%s,server_key=%s,iterations=%d",
+		base64.StdEncoding.EncodeToString(s.salt),
+		base64.StdEncoding.EncodeToString(s.storedKey),
+		base64.StdEncoding.EncodeToString(s.serverKey),
+		s.iterations,
+	)
+}
+
+// scramCredentialIterations gets the number of iterations from a credential in the format
+// that Kafka stores in zookeeper.
+func scramCredentialIterations(value string) (int, error) {
+	for _, element := range strings.Split(value, ",") {
+		if strings.HasPrefix(element, "iterations=") {
+			return strconv.Atoi(strings.TrimPrefix(element, "iterations="))
+		}
+	}
+	return 0, errors.New("Credential does not have an iterations value")
+}
+
+// encodeDescribeUserScramCredentialsRequest encodes the body of a v0
+// DescribeUserScramCredentials request for all users.
+func encodeDescribeUserScramCredentialsRequest() *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	// A null users array requests all users
+	body.WriteCompactNullArray()
+	body.WriteEmptyTaggedFields()
+
+	return body
+}
+
+// decodeDescribeUserScramCredentialsResponse decodes the body of a v0
+// DescribeUserScramCredentials response. Users that don't have any credentials are omitted.
+func decodeDescribeUserScramCredentialsResponse(
+	response *protocol.Decoder,
+) ([]UserInfo, error) {
+	users := []UserInfo{}
+
+	// Throttle time
+	response.ReadInt32()
+
+	err := userScramCredentialsError(response.ReadInt16(), response.ReadCompactString())
+
+	numResults := response.ReadCompactArrayLength()
+	for i := 0; i < numResults && response.Err() == nil; i++ {
+		user := UserInfo{
+			Name:        response.ReadCompactString(),
+			Credentials: []ScramCredentialInfo{},
+		}
+		userErr := userScramCredentialsError(response.ReadInt16(), response.ReadCompactString())
+		if userErr != nil && err == nil {
+			err = fmt.Errorf("User %s: %w", user.Name, userErr)
+		}
+
+		numCredentials := response.ReadCompactArrayLength()
+		for j := 0; j < numCredentials && response.Err() == nil; j++ {
+			user.Credentials = append(
+				user.Credentials,
+				ScramCredentialInfo{
+					Mechanism:  scramMechanismFromTypeID(response.ReadInt8()),
+					Iterations: int(response.ReadInt32()),
+				},
+			)
+			response.SkipTaggedFields()
+		}
+		response.SkipTaggedFields()
+
+		if len(user.Credentials) > 0 {
+			users = append(users, user)
+		}
+	}
+	response.SkipTaggedFields()
+
+	if decodeErr := response.Err(); decodeErr != nil {
+		return nil, decodeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// decodeAlterUserScramCredentialsResponse decodes the body of a v0 AlterUserScramCredentials
+// response, returning the first error in it, if any.
+func decodeAlterUserScramCredentialsResponse(response *protocol.Decoder) error {
+	var err error
+
+	// Throttle time
+	response.ReadInt32()
+
+	numResults := response.ReadCompactArrayLength()
+	for i := 0; i < numResults && response.Err() == nil; i++ {
+		user := response.ReadCompactString()
+		userErr := userScramCredentialsError(response.ReadInt16(), response.ReadCompactString())
+		if userErr != nil && err == nil {
+			err = fmt.Errorf("User %s: %w", user, userErr)
+		}
+		response.SkipTaggedFields()
+	}
+	response.SkipTaggedFields()
+
+	if decodeErr := response.Err(); decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
+func userScramCredentialsError(errorCode int16, message string) error {
+	switch errorCode {
+	case 0:
+		return nil
+	case resourceNotFoundError:
+		// Kafka 2.7 doesn't include this code in its error table
+		if message != "" {
+			return fmt.Errorf("Resource not found (%s)", message)
+		}
+		return errors.New("Resource not found")
+	}
+
+	// The kafka error is wrapped so that retryable ones, e.g. NOT_CONTROLLER, are retried
+	if message != "" {
+		return fmt.Errorf("%w (%s)", kafka.Error(errorCode), message)
+	}
+	return kafka.Error(errorCode)
+}
@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScramMechanism(t *testing.T) {
+	mechanism, err := ParseScramMechanism("scram-sha-512")
+	require.NoError(t, err)
+	assert.Equal(t, ScramMechanismSHA512, mechanism)
+
+	_, err = ParseScramMechanism("PLAIN")
+	assert.Error(t, err)
+}
+
+func TestScramCredential(t *testing.T) {
+	// Expected values generated independently with python's hashlib and hmac modules
+	assert.Equal(
+		t,
+		"salt=dGVzdC1zYWx0,stored_key=Sp+aKmzNi6FOpPjObDBiruGy/JmOHXGFK1yho3wKAR0=,server_key=07wJiRK1dtwfN15PXRLLMzQ8o5m5vmAc4npzxlV85qY=,iterations=4096",
+		newScramCredential(
+			ScramMechanismSHA256,
+			"test-password",
+			[]byte("test-salt"),
+			4096,
+		).String(),
+	)
+	assert.Equal(
+		t,
+		"salt=dGVzdC1zYWx0,stored_key=vjLcDatKuZF3gplpsyQRNUqS13V2DF4t+WsQb8jR0MpJixsE6GtpqegLYq9xzooah2B7m1OP9ubADLzCMEFf6w==,server_key=wNwHNOm915ALZHZNeBjywbNIVLKRB5zrGDaFlYV0PHTI0P7OwFB/t/ZJIdUWPyVifouHYmJOXRcpQdCFF+DvXg==,iterations=4096",
+		newScramCredential(
+			ScramMechanismSHA512,
+			"test-password",
+			[]byte("test-salt"),
+			4096,
+		).String(),
+	)
+}
+
+func TestUserFromZKConfig(t *testing.T) {
+	user, err := userFromZKConfig(
+		"user%40example.com",
+		map[string]string{
+			"SCRAM-SHA-512":      "salt=abc,stored_key=def,server_key=ghi,iterations=8192",
+			"producer_byte_rate": "1024",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		UserInfo{
+			Name: "user@example.com",
+			Credentials: []ScramCredentialInfo{
+				{
+					Mechanism:  ScramMechanismSHA512,
+					Iterations: 8192,
+				},
+			},
+		},
+		user,
+	)
+	assert.True(t, user.HasMechanism(ScramMechanismSHA512))
+	assert.False(t, user.HasMechanism(ScramMechanismSHA256))
+
+	_, err = userFromZKConfig(
+		"user",
+		map[string]string{
+			"SCRAM-SHA-256": "salt=abc,stored_key=def,server_key=ghi",
+		},
+	)
+	assert.Error(t, err)
+}
+
+func TestDecodeDescribeUserScramCredentialsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt16(0)
+	response.WriteCompactString("")
+	response.WriteCompactArrayLength(2)
+
+	response.WriteCompactString("user1")
+	response.WriteInt16(0)
+	response.WriteCompactString("")
+	response.WriteCompactArrayLength(2)
+	response.WriteInt8(ScramMechanismSHA256.typeID())
+	response.WriteInt32(4096)
+	response.WriteEmptyTaggedFields()
+	response.WriteInt8(ScramMechanismSHA512.typeID())
+	response.WriteInt32(8192)
+	response.WriteEmptyTaggedFields()
+	response.WriteEmptyTaggedFields()
+
+	// Users without credentials are omitted
+	response.WriteCompactString("user2")
+	response.WriteInt16(0)
+	response.WriteCompactString("")
+	response.WriteCompactArrayLength(0)
+	response.WriteEmptyTaggedFields()
+
+	response.WriteEmptyTaggedFields()
+
+	users, err := decodeDescribeUserScramCredentialsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]UserInfo{
+			{
+				Name: "user1",
+				Credentials: []ScramCredentialInfo{
+					{
+						Mechanism:  ScramMechanismSHA256,
+						Iterations: 4096,
+					},
+					{
+						Mechanism:  ScramMechanismSHA512,
+						Iterations: 8192,
+					},
+				},
+			},
+		},
+		users,
+	)
+
+	_, err = decodeDescribeUserScramCredentialsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes()[:15])),
+	)
+	assert.Error(t, err)
+}
+
+func TestDecodeAlterUserScramCredentialsResponse(t *testing.T) {
+	encodeResponse := func(errorCode int16, message string) []byte {
+		response := &protocol.Encoder{}
+		response.WriteInt32(0)
+		response.WriteCompactArrayLength(1)
+		response.WriteCompactString("user1")
+		response.WriteInt16(errorCode)
+		response.WriteCompactString(message)
+		response.WriteEmptyTaggedFields()
+		response.WriteEmptyTaggedFields()
+		return response.Bytes()
+	}
+
+	err := decodeAlterUserScramCredentialsResponse(
+		protocol.NewDecoder(bytes.NewReader(encodeResponse(0, ""))),
+	)
+	assert.NoError(t, err)
+
+	err = decodeAlterUserScramCredentialsResponse(
+		protocol.NewDecoder(
+			bytes.NewReader(
+				encodeResponse(resourceNotFoundError, "Attempt to delete a user credential that does not exist"),
+			),
+		),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "user1")
+	assert.Contains(t, err.Error(), "does not exist")
+
+	// Controller moves are retried
+	err = decodeAlterUserScramCredentialsResponse(
+		protocol.NewDecoder(
+			bytes.NewReader(encodeResponse(int16(kafka.NotController), "")),
+		),
+	)
+	require.Error(t, err)
+	assert.True(t, isRetryableBrokerError(err, true))
+}
+
+func TestRedactConfigValue(t *testing.T) {
+	assert.Equal(
+		t,
+		"[redacted]",
+		redactConfigValue(
+			string(ScramMechanismSHA256),
+			"salt=c2FsdA==,stored_key=a2V5,server_key=a2V5,iterations=4096",
+		),
+	)
+	assert.Nil(t, redactConfigValue(string(ScramMechanismSHA512), nil))
+	assert.Equal(t, "1000", redactConfigValue("producer_byte_rate", "1000"))
+}
@@ -0,0 +1,252 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The maximum amount of time to wait for a broker's API versions
+	brokerVersionTimeout = 5 * time.Second
+)
+
+// kafkaVersionMarker is an API that first appeared, or got a new version, in a particular
+// Kafka release.
+type kafkaVersionMarker struct {
+	version    string
+	apiKey     int16
+	minVersion int16
+}
+
+// kafkaVersionMarkers are used to infer broker versions from their supported APIs. These
+// are in increasing order of Kafka version.
+var kafkaVersionMarkers = []kafkaVersionMarker{
+	{version: "0.10.0", apiKey: 18, minVersion: 0}, // ApiVersions
+	{version: "0.10.1", apiKey: 2, minVersion: 1},  // ListOffsets v1
+	{version: "0.10.2", apiKey: 9, minVersion: 2},  // OffsetFetch v2
+	{version: "0.11.0", apiKey: 0, minVersion: 3},  // Produce v3
+	{version: "1.0", apiKey: 0, minVersion: 5},     // Produce v5
+	{version: "1.1", apiKey: 1, minVersion: 7},     // Fetch v7
+	{version: "2.0", apiKey: 18, minVersion: 2},    // ApiVersions v2
+	{version: "2.1", apiKey: 1, minVersion: 10},    // Fetch v10
+	{version: "2.2", apiKey: 43, minVersion: 0},    // ElectLeaders
+	{version: "2.3", apiKey: 44, minVersion: 0},    // IncrementalAlterConfigs
+	{version: "2.4", apiKey: 45, minVersion: 0},    // AlterPartitionReassignments
+	{version: "2.5", apiKey: 28, minVersion: 3},    // TxnOffsetCommit v3
+	{version: "2.6", apiKey: 48, minVersion: 0},    // DescribeClientQuotas
+	{version: "2.7", apiKey: 50, minVersion: 0},    // DescribeUserScramCredentials
+	{version: "2.8", apiKey: 60, minVersion: 0},    // DescribeCluster
+	{version: "3.0", apiKey: 61, minVersion: 0},    // DescribeProducers
+}
+
+// apiKeyNames are the names of the Kafka APIs, by key.
+var apiKeyNames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	4:  "LeaderAndIsr",
+	5:  "StopReplica",
+	6:  "UpdateMetadata",
+	7:  "ControlledShutdown",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	11: "JoinGroup",
+	12: "Heartbeat",
+	13: "LeaveGroup",
+	14: "SyncGroup",
+	15: "DescribeGroups",
+	16: "ListGroups",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	20: "DeleteTopics",
+	21: "DeleteRecords",
+	22: "InitProducerId",
+	23: "OffsetForLeaderEpoch",
+	24: "AddPartitionsToTxn",
+	25: "AddOffsetsToTxn",
+	26: "EndTxn",
+	27: "WriteTxnMarkers",
+	28: "TxnOffsetCommit",
+	29: "DescribeAcls",
+	30: "CreateAcls",
+	31: "DeleteAcls",
+	32: "DescribeConfigs",
+	33: "AlterConfigs",
+	34: "AlterReplicaLogDirs",
+	35: "DescribeLogDirs",
+	36: "SaslAuthenticate",
+	37: "CreatePartitions",
+	38: "CreateDelegationToken",
+	39: "RenewDelegationToken",
+	40: "ExpireDelegationToken",
+	41: "DescribeDelegationToken",
+	42: "DeleteGroups",
+	43: "ElectLeaders",
+	44: "IncrementalAlterConfigs",
+	45: "AlterPartitionReassignments",
+	46: "ListPartitionReassignments",
+	47: "OffsetDelete",
+	48: "DescribeClientQuotas",
+	49: "AlterClientQuotas",
+	50: "DescribeUserScramCredentials",
+	51: "AlterUserScramCredentials",
+	52: "Vote",
+	53: "BeginQuorumEpoch",
+	54: "EndQuorumEpoch",
+	55: "DescribeQuorum",
+	56: "AlterIsr",
+	57: "UpdateFeatures",
+	58: "Envelope",
+	59: "FetchSnapshot",
+	60: "DescribeCluster",
+	61: "DescribeProducers",
+}
+
+// BrokerAPIVersions contains the API version ranges supported by a single broker along with
+// the Kafka version that's inferred from them.
+type BrokerAPIVersions struct {
+	BrokerID     int
+	KafkaVersion string
+	APIVersions  []kafka.ApiVersion
+
+	// Error is set if the API versions couldn't be fetched from the broker.
+	Error string
+}
+
+// APIKeyName returns the name of the Kafka API with the argument key.
+func APIKeyName(apiKey int16) string {
+	if name, ok := apiKeyNames[apiKey]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", apiKey)
+}
+
+// DistinctKafkaVersions returns the sorted, distinct Kafka versions across the argument
+// brokers, ignoring the ones whose versions are unknown. More than one version usually means
+// that the cluster is in the middle of an upgrade.
+func DistinctKafkaVersions(brokerVersions []BrokerAPIVersions) []string {
+	versionsMap := map[string]struct{}{}
+	for _, brokerVersion := range brokerVersions {
+		if brokerVersion.KafkaVersion != "" {
+			versionsMap[brokerVersion.KafkaVersion] = struct{}{}
+		}
+	}
+
+	versions := []string{}
+	for version := range versionsMap {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	return versions
+}
+
+// KafkaVersionFromAPIVersions infers the Kafka version of a broker from the API versions that
+// it supports. Since not every release changes the protocol, the result is the earliest minor
+// release that's consistent with the argument versions. An empty string is returned if the
+// version can't be determined.
+func KafkaVersionFromAPIVersions(apiVersions []kafka.ApiVersion) string {
+	maxVersions := map[int16]int16{}
+	for _, apiVersion := range apiVersions {
+		maxVersions[apiVersion.ApiKey] = apiVersion.MaxVersion
+	}
+
+	var version string
+
+	for _, marker := range kafkaVersionMarkers {
+		maxVersion, ok := maxVersions[marker.apiKey]
+		if !ok || maxVersion < marker.minVersion {
+			break
+		}
+		version = marker.version
+	}
+
+	return version
+}
+
+// GetBrokerVersions gets the Kafka version of each argument broker by connecting to it and
+// fetching its supported API versions. Brokers whose versions can't be fetched are omitted
+// from the result.
+func (c *Client) GetBrokerVersions(
+	ctx context.Context,
+	brokers []BrokerInfo,
+) map[int]string {
+	versions := map[int]string{}
+
+	for _, brokerVersion := range c.GetBrokerAPIVersions(ctx, brokers) {
+		if brokerVersion.Error == "" {
+			versions[brokerVersion.BrokerID] = brokerVersion.KafkaVersion
+		}
+	}
+
+	return versions
+}
+
+// GetBrokerAPIVersions gets the API version ranges supported by each argument broker, in the
+// same order as the brokers. Brokers whose versions can't be fetched have their Error set.
+func (c *Client) GetBrokerAPIVersions(
+	ctx context.Context,
+	brokers []BrokerInfo,
+) []BrokerAPIVersions {
+	brokerVersions := make([]BrokerAPIVersions, len(brokers))
+
+	// Errors are logged instead of returned so that one unreachable broker doesn't hide the
+	// versions of the others
+	util.RunParallel(ctx, len(brokers), maxPoolSize, func(ctx context.Context, i int) error {
+		brokerVersion := BrokerAPIVersions{
+			BrokerID:    brokers[i].ID,
+			APIVersions: []kafka.ApiVersion{},
+		}
+
+		apiVersions, err := c.getBrokerAPIVersions(ctx, brokers[i].Addr())
+		if err != nil {
+			log.Debugf("Could not get API versions for broker %d: %+v", brokers[i].ID, err)
+			brokerVersion.Error = err.Error()
+		} else {
+			sort.Slice(apiVersions, func(a, b int) bool {
+				return apiVersions[a].ApiKey < apiVersions[b].ApiKey
+			})
+			brokerVersion.APIVersions = apiVersions
+			brokerVersion.KafkaVersion = KafkaVersionFromAPIVersions(apiVersions)
+		}
+
+		// Each call writes to its own index, so no locking is needed
+		brokerVersions[i] = brokerVersion
+		return nil
+	})
+
+	return brokerVersions
+}
+
+func (c *Client) getBrokerAPIVersions(
+	ctx context.Context,
+	brokerAddr string,
+) ([]kafka.ApiVersion, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, brokerVersionTimeout)
+	defer cancel()
+
+	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", brokerAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	return conn.ApiVersions()
+}
@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaVersionFromAPIVersions(t *testing.T) {
+	type testCase struct {
+		description string
+		apiVersions []kafka.ApiVersion
+		expected    string
+	}
+
+	testCases := []testCase{
+		{
+			description: "no versions",
+			apiVersions: []kafka.ApiVersion{},
+			expected:    "",
+		},
+		{
+			description: "v0.10.2",
+			apiVersions: []kafka.ApiVersion{
+				{ApiKey: 0, MaxVersion: 2},
+				{ApiKey: 1, MaxVersion: 3},
+				{ApiKey: 2, MaxVersion: 1},
+				{ApiKey: 9, MaxVersion: 2},
+				{ApiKey: 18, MaxVersion: 0},
+			},
+			expected: "0.10.2",
+		},
+		{
+			description: "v2.4",
+			apiVersions: []kafka.ApiVersion{
+				{ApiKey: 0, MaxVersion: 8},
+				{ApiKey: 1, MaxVersion: 11},
+				{ApiKey: 2, MaxVersion: 5},
+				{ApiKey: 9, MaxVersion: 6},
+				{ApiKey: 18, MaxVersion: 3},
+				{ApiKey: 43, MaxVersion: 2},
+				{ApiKey: 44, MaxVersion: 1},
+				{ApiKey: 45, MaxVersion: 0},
+				{ApiKey: 47, MaxVersion: 0},
+			},
+			expected: "2.4",
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(
+			t,
+			testCase.expected,
+			KafkaVersionFromAPIVersions(testCase.apiVersions),
+			testCase.description,
+		)
+	}
+}
+
+func TestAPIKeyName(t *testing.T) {
+	assert.Equal(t, "Produce", APIKeyName(0))
+	assert.Equal(t, "DescribeCluster", APIKeyName(60))
+	assert.Equal(t, "Unknown(1000)", APIKeyName(1000))
+}
+
+func TestDistinctKafkaVersions(t *testing.T) {
+	assert.Equal(t, []string{}, DistinctKafkaVersions(nil))
+	assert.Equal(
+		t,
+		[]string{"2.4", "2.8"},
+		DistinctKafkaVersions(
+			[]BrokerAPIVersions{
+				{BrokerID: 1, KafkaVersion: "2.8"},
+				{BrokerID: 2, KafkaVersion: "2.4"},
+				{BrokerID: 3, KafkaVersion: "2.8"},
+				{BrokerID: 4, Error: "connection refused"},
+			},
+		),
+	)
+}
@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -16,6 +20,11 @@ import (
 	"github.com/segmentio/topicctl/pkg/apply/pickers"
 	"github.com/segmentio/topicctl/pkg/apply/rebalancers"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/locks"
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/metrics"
+	"github.com/segmentio/topicctl/pkg/schemas"
 	"github.com/segmentio/topicctl/pkg/util"
 	"github.com/segmentio/topicctl/pkg/zk"
 	log "github.com/sirupsen/logrus"
@@ -24,14 +33,43 @@ import (
 // TopicApplierConfig contains the configuration for a TopicApplier struct.
 type TopicApplierConfig struct {
 	BrokerThrottleMBsOverride  int
+	AllowCleanupPolicyChange   bool
+	AllowRepartitioning        bool
+	AllowRetentionReduction    bool
+	AutoApprove                []ApprovalCategory
+	BackupDir                  string
 	BrokersToRemove            []int
+	CancelReassignmentsOnStop  bool
+	ChangeReport               *ChangeReport
+	CleanupMonitorWindow       time.Duration
 	ClusterConfig              config.ClusterConfig
+	Confirmer                  Confirmer
+	DeleteRetired              bool
 	DryRun                     bool
+	FixRackViolations          bool
+	IgnoreMaintenanceWindow    bool
+	Locker                     locks.Locker
+	LockWait                   time.Duration
 	PartitionBatchSizeOverride int
+	PartitionMetrics           metrics.Fetcher
+	PartitionStepDelay         time.Duration
+	PartitionStepSize          int
+	PlannedChanges             *TopicChanges
+	RaiseAdminQuotas           bool
 	Rebalance                  bool
 	SkipConfirm                bool
 	SleepLoopTime              time.Duration
+	Stop                       <-chan struct{}
 	TopicConfig                config.TopicConfig
+	TopicSetMembers            []string
+}
+
+// unlimitedQuotaValues are the values used when temporarily raising the admin client's
+// quotas. These match the defaults that Kafka uses when no quotas are set.
+var unlimitedQuotaValues = map[string]string{
+	admin.ProducerByteRateKey:  "9223372036854775807",
+	admin.ConsumerByteRateKey:  "9223372036854775807",
+	admin.RequestPercentageKey: "2147483647",
 }
 
 // TopicApplier executes an "apply" run on a topic by comparing the actual
@@ -42,6 +80,34 @@ type TopicApplier struct {
 	adminClient *admin.Client
 	brokers     []admin.BrokerInfo
 
+	// changes records the changes made by the apply; it's only included in a report if
+	// ChangeReport is set in the config
+	changes *TopicChanges
+
+	// locker is used for the cluster and topic locks; it defaults to the admin client,
+	// i.e. to zookeeper, if not set in the config
+	locker locks.Locker
+
+	// placementBrokers are the brokers that replicas can be placed on, i.e. the ones that
+	// aren't excluded in the topic config
+	placementBrokers []admin.BrokerInfo
+
+	// schemasClient is only set if the cluster has a schema registry
+	schemasClient *schemas.Client
+
+	// migrationStarted is set once the pre-migrate hooks have run
+	migrationStarted bool
+
+	// backupID is set once the state of the topic has been backed up before its first change;
+	// backups are only written if BackupDir is set in the config
+	backupID string
+
+	// cancelApply cancels the context of the current apply; it's used to stop the apply if
+	// one of its locks is lost, in which case lostLockPath is set to the lock's path
+	cancelApply  context.CancelFunc
+	lostLockPath string
+	lockMutex    sync.Mutex
+
 	// Pull out some fields for easier access
 	clusterConfig config.ClusterConfig
 	maxBatchSize  int
@@ -82,10 +148,36 @@ func NewTopicApplier(
 		throttleBytes = 120000000
 	}
 
+	var locker locks.Locker = adminClient
+	if applierConfig.Locker != nil {
+		locker = applierConfig.Locker
+	}
+
+	var schemasClient *schemas.Client
+	if applierConfig.ClusterConfig.Spec.SchemaRegistryURL != "" {
+		schemasClient = schemas.NewClient(applierConfig.ClusterConfig.Spec.SchemaRegistryURL)
+	}
+
+	changes := &TopicChanges{}
+	if applierConfig.ChangeReport != nil {
+		changes = applierConfig.ChangeReport.AddTopic(
+			applierConfig.TopicConfig.Meta.Name,
+			applierConfig.TopicConfig.Meta.Cluster,
+			applierConfig.TopicConfig.Meta.Environment,
+		)
+	}
+
 	return &TopicApplier{
 		adminClient:   adminClient,
+		schemasClient: schemasClient,
 		config:        applierConfig,
 		brokers:       brokers,
+		changes:       changes,
+		locker:        locker,
+		placementBrokers: assigners.FilterBrokers(
+			brokers,
+			applierConfig.TopicConfig.Spec.PlacementConfig.ExcludeBrokers,
+		),
 		clusterConfig: applierConfig.ClusterConfig,
 		maxBatchSize:  maxBatchSize,
 		throttleBytes: throttleBytes,
@@ -97,43 +189,147 @@ func NewTopicApplier(
 // Apply runs a single "apply" run on the configured topic. The general flow is:
 //
 // 1. Validate configs
-// 2. Acquire topic lock
-// 3. Check if topic already exists
-// 4. If new:
+// 2. Check whether the admin client is subject to quotas (and raise them if configured)
+// 3. Acquire topic lock
+// 4. Check if topic already exists
+// 5. If new:
 //   a. Create the topic
 //   b. Update the placement in accordance with the configured strategy
-// 5. If exists:
+// 6. If exists:
 //   a. Check retention and update if needed
 //   b. Check replication factor (can't be updated by topicctl)
 //   c. Check partition count and extend if needed
 //   d. Check partition placement and update/migrate if needed
 //   e. Check partition leaders and update if needed
+// 7. Check schema subjects and compatibility levels (if configured) and update if needed
+// 8. Check the produce block for deprecated and retiring topics and update if needed
+//
+// Retiring topics that are past their deletion date are deleted instead of being updated,
+// if enabled, and aren't created if they don't exist.
+//
+// If BackupDir is set, then the configs and assignments of an existing topic are backed up
+// right before they're first changed so that they can be restored with RollbackTopic.
+//
+// If the Stop channel in the config is closed, no new changes are started and
+// ErrApplyStopped is returned. An in-flight reassignment batch is either waited for or, if
+// CancelReassignmentsOnStop is set, cancelled first.
 func (t *TopicApplier) Apply(ctx context.Context) error {
+	applyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	t.cancelApply = cancel
+
+	err := t.apply(applyCtx)
+	if lostLockPath := t.getLostLockPath(); lostLockPath != "" {
+		// Any changes after this point weren't protected by the lock, so fail the apply even
+		// if they went through
+		t.changes.Stopped = true
+		return fmt.Errorf(
+			"Stopping because lock %s was lost during the apply: %w",
+			lostLockPath,
+			locks.ErrLockLost,
+		)
+	}
+	if err != nil && (ctx.Err() != nil || t.stopRequested()) {
+		t.changes.Stopped = true
+	}
+	return err
+}
+
+func (t *TopicApplier) apply(ctx context.Context) error {
 	log.Info("Validating configs...")
-	brokerRacks := admin.DistinctRacks(t.brokers)
+	brokerRacks := admin.DistinctRacks(t.placementBrokers)
 
 	if err := t.clusterConfig.Validate(); err != nil {
-		return err
+		return exitcode.Wrap(exitcode.KindValidation, err)
 	}
 
 	if err := t.topicConfig.Validate(len(brokerRacks)); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+	if err := t.topicConfig.ValidateBrokers(t.brokers); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+
+	frozen, err := t.checkFrozen(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if frozen {
+		return nil
+	}
+
+	deletionDue, err := t.checkLifecycle(time.Now())
+	if err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+
+	if err := t.avoidMaintenanceLeaders(ctx); err != nil {
 		return err
 	}
+	if t.topicConfig.Spec.PlacementConfig.Strategy == config.PlacementStrategyBalancedTopicSet {
+		if err := t.resolveTopicSet(ctx); err != nil {
+			return err
+		}
+	}
 	if err := config.CheckConsistency(t.topicConfig, t.clusterConfig); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+
+	restoreQuotas, err := t.checkQuotas(ctx)
+	if err != nil {
 		return err
 	}
+	defer restoreQuotas()
 
 	log.Info("Checking if topic already exists...")
 
 	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
-	if err != nil {
-		if err == admin.ErrTopicDoesNotExist {
-			return t.applyNewTopic(ctx)
+	if err == admin.ErrTopicDoesNotExist {
+		if deletionDue {
+			log.Infof("Topic %s is past its deletion date and doesn't exist; not creating it", t.topicName)
+			return nil
+		}
+		if err := t.checkPlanState(nil); err != nil {
+			return err
+		}
+		if err := t.applyNewTopic(ctx); err != nil {
+			return err
+		}
+		if !t.config.DryRun {
+			topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, false)
+			if err != nil {
+				return err
+			}
+			t.checkRecreated(ctx, topicInfo)
+		}
+		if err := t.updateProduceBlock(ctx); err != nil {
+			return err
 		}
+		return t.checkPlanComplete()
+	} else if err != nil {
 		return err
 	}
 
-	return t.applyExistingTopic(ctx, topicInfo)
+	if err := t.checkPlanState(&topicInfo); err != nil {
+		return err
+	}
+	t.checkRecreated(ctx, topicInfo)
+	if deletionDue {
+		deleted, err := t.deleteRetiredTopic(ctx)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			return t.checkPlanComplete()
+		}
+	}
+	if err := t.applyExistingTopic(ctx, topicInfo); err != nil {
+		return err
+	}
+	if err := t.updateProduceBlock(ctx); err != nil {
+		return err
+	}
+	return t.checkPlanComplete()
 }
 
 func (t *TopicApplier) applyNewTopic(ctx context.Context) error {
@@ -144,7 +340,8 @@ func (t *TopicApplier) applyNewTopic(ctx context.Context) error {
 
 	if t.config.DryRun {
 		log.Infof("Would create topic with config %+v", newTopicConfig)
-		return nil
+		t.changes.Created = true
+		return t.updateSchemas(ctx)
 	}
 
 	log.Infof(
@@ -152,11 +349,22 @@ func (t *TopicApplier) applyNewTopic(ctx context.Context) error {
 		FormatNewTopicConfig(newTopicConfig),
 	)
 
-	ok, _ := Confirm("OK to continue?", t.config.SkipConfirm)
+	if err := t.checkPlanned(&TopicChanges{Created: true}); err != nil {
+		return err
+	}
+
+	ok, err := t.confirm("OK to continue?", ApprovalCategoryCreate)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return errors.New("Stopping because of user response")
 	}
 
+	if err := t.runHooks(ctx, config.HookPhasePre, config.HookEventCreate); err != nil {
+		return err
+	}
+
 	log.Infof("Creating new topic with config %+v", newTopicConfig)
 
 	err = t.adminClient.CreateTopic(
@@ -166,6 +374,7 @@ func (t *TopicApplier) applyNewTopic(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	t.changes.Created = true
 
 	// Just do a short sleep to ensure that zk is updated before we check
 	if err := interruptableSleep(ctx, t.config.SleepLoopTime/5); err != nil {
@@ -180,7 +389,11 @@ func (t *TopicApplier) applyNewTopic(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	if err := t.updateSchemas(ctx); err != nil {
+		return err
+	}
+
+	return t.runHooks(ctx, config.HookPhasePost, config.HookEventCreate)
 }
 
 func (t *TopicApplier) applyExistingTopic(
@@ -213,6 +426,13 @@ func (t *TopicApplier) applyExistingTopic(
 		return err
 	}
 
+	if err := t.updateRacks(
+		ctx,
+		t.maxBatchSize,
+	); err != nil {
+		return err
+	}
+
 	if err := t.updateLeaders(
 		ctx,
 		-1,
@@ -236,6 +456,13 @@ func (t *TopicApplier) applyExistingTopic(
 		}
 	}
 
+	if err := t.updateSchemas(ctx); err != nil {
+		return err
+	}
+
+	if t.migrationStarted {
+		return t.runHooks(ctx, config.HookPhasePost, config.HookEventMigrate)
+	}
 	return nil
 }
 
@@ -272,7 +499,7 @@ func (t *TopicApplier) checkExistingState(
 			if t.config.DryRun {
 				log.Infof("Skipping update because dryRun is set to true")
 			} else {
-				ok, err := Confirm("OK to remove these?", t.config.SkipConfirm)
+				ok, err := t.confirm("OK to remove these?", ApprovalCategoryReassign)
 				if err != nil {
 					return err
 				} else if !ok {
@@ -315,7 +542,7 @@ func (t *TopicApplier) checkExistingState(
 				if t.config.DryRun {
 					log.Infof("Skipping update because dryRun is set to true")
 				} else {
-					ok, err := Confirm("OK to remove broker throttles?", t.config.SkipConfirm)
+					ok, err := t.confirm("OK to remove broker throttles?", ApprovalCategoryReassign)
 					if err != nil {
 						return err
 					} else if !ok {
@@ -355,7 +582,7 @@ func (t *TopicApplier) updateSettings(
 	}
 
 	if len(diffKeys) > 0 {
-		diffsTable, err := FormatSettingsDiff(topicSettings, topicInfo.Config, diffKeys)
+		settingsDiff, err := FormatSettingsDiff(topicSettings, topicInfo.Config, diffKeys)
 		if err != nil {
 			return err
 		}
@@ -363,37 +590,75 @@ func (t *TopicApplier) updateSettings(
 		log.Infof(
 			"Found %d key(s) with different values:\n%s",
 			len(diffKeys),
-			diffsTable,
+			settingsDiff,
+		)
+
+		if err := t.checkRetentionReduction(
+			ctx,
+			topicSettings,
+			topicInfo,
+			diffKeys,
+		); err != nil {
+			return err
+		}
+
+		policyChange, err := t.checkCleanupPolicyChange(
+			ctx,
+			topicSettings,
+			topicInfo,
+			diffKeys,
 		)
+		if err != nil {
+			return err
+		}
+
+		configEntries, err := topicSettings.ToConfigEntries(diffKeys)
+		if err != nil {
+			return err
+		}
 
 		if t.config.DryRun {
 			log.Infof("Skipping update because dryRun is set to true")
+			t.changes.addConfigChanges(topicInfo.Config, configEntries)
 			return nil
 		}
 
-		ok, _ := Confirm(
+		proposed := &TopicChanges{}
+		proposed.addConfigChanges(topicInfo.Config, configEntries)
+		if err := t.checkPlanned(proposed); err != nil {
+			return err
+		}
+
+		ok, err := t.confirm(
 			"OK to update to the new values in the topic config?",
-			t.config.SkipConfirm,
+			ApprovalCategoryConfig,
 		)
+		if err != nil {
+			return err
+		}
 		if !ok {
 			return errors.New("Stopping because of user response")
 		}
 		log.Infof("OK, updating")
 
-		configEntries, err := topicSettings.ToConfigEntries(diffKeys)
-		if err != nil {
+		if err := t.backupTopic(ctx); err != nil {
 			return err
 		}
 
-		_, err = t.adminClient.UpdateTopicConfig(
-			ctx,
-			t.topicName,
-			configEntries,
-			true,
-		)
+		if policyChange != nil {
+			err = t.updateCleanupPolicy(ctx, topicInfo, configEntries, *policyChange)
+		} else {
+			_, err = t.adminClient.UpdateTopicConfig(
+				ctx,
+				t.topicName,
+				configEntries,
+				true,
+			)
+		}
 		if err != nil {
 			return err
 		}
+		t.changes.addConfigChanges(topicInfo.Config, configEntries)
 	}
 
 	if len(missingKeys) > 0 {
@@ -407,99 +672,456 @@ func (t *TopicApplier) updateSettings(
 	return nil
 }
 
-func (t *TopicApplier) updateReplication(
+// checkRetentionReduction determines whether the argument settings diffs would reduce
+// the retention of the topic. If so, it estimates how much data would become eligible for
+// deletion and returns an error unless the AllowRetentionReduction option is set.
+func (t *TopicApplier) checkRetentionReduction(
 	ctx context.Context,
+	topicSettings config.TopicSettings,
 	topicInfo admin.TopicInfo,
+	diffKeys []string,
 ) error {
-	log.Infof("Checking replication...")
+	reducedKeys := []string{}
+	var clusterDefaults map[string]string
 
-	currReplication := topicInfo.MaxISR()
-	if currReplication != t.topicConfig.Spec.ReplicationFactor {
-		return fmt.Errorf(
-			"Replication in topic config (%d) is not equal to observed max ISR (%d); this cannot be resolved by topicctl",
-			t.topicConfig.Spec.ReplicationFactor,
-			currReplication,
+	for _, key := range diffKeys {
+		if key != admin.RetentionKey && key != admin.RetentionBytesKey {
+			continue
+		}
+
+		// Topics without an override use the cluster-wide or Kafka default, so compare
+		// against whichever value is actually in effect
+		if clusterDefaults == nil {
+			var err error
+			clusterDefaults, err = t.adminClient.GetClusterDefaultConfig(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		currValueStr := admin.EffectiveTopicConfigValue(key, topicInfo.Config, clusterDefaults)
+		newValueStr, err := topicSettings.GetValueStr(key)
+		if err != nil {
+			return err
+		}
+
+		reduced, err := isRetentionReduction(currValueStr, newValueStr)
+		if err != nil {
+			return err
+		}
+		if reduced {
+			reducedKeys = append(reducedKeys, key)
+		}
+	}
+
+	if len(reducedKeys) == 0 {
+		return nil
+	}
+
+	log.Warnf("This apply will reduce the retention of the topic (%+v)", reducedKeys)
+
+	for _, key := range reducedKeys {
+		newValueStr, _ := topicSettings.GetValueStr(key)
+
+		if key == admin.RetentionBytesKey {
+			// retention.bytes applies to each partition, so the data at risk can be estimated
+			// from the current partition sizes
+			newRetentionBytes, _ := strconv.ParseInt(newValueStr, 10, 64)
+
+			logDirs, err := t.adminClient.GetLogDirs(ctx, t.brokers)
+			if err != nil {
+				log.Warnf("Could not get log dirs to estimate data loss: %+v", err)
+				continue
+			}
+
+			losses := messages.EstimateRetentionBytesLoss(
+				admin.LeaderReplicaSizes(logDirs, topicInfo),
+				newRetentionBytes,
+			)
+			log.Warnf(
+				"Estimated data eligible for deletion with the new %s:\n%s",
+				key,
+				messages.FormatRetentionBytesLosses(losses),
+			)
+			continue
+		}
+
+		newRetentionMs, _ := strconv.ParseInt(newValueStr, 10, 64)
+
+		bounds, err := messages.GetAllPartitionBounds(
+			ctx,
+			t.adminClient.GetBootstrapAddrs()[0],
+			t.topicName,
+			nil,
+		)
+		if err != nil {
+			log.Warnf("Could not get partition bounds to estimate data loss: %+v", err)
+			continue
+		}
+
+		losses := messages.EstimateRetentionLoss(
+			bounds,
+			time.Duration(newRetentionMs)*time.Millisecond,
+			time.Now(),
+		)
+		log.Warnf(
+			"Estimated messages eligible for deletion with the new %s:\n%s",
+			key,
+			messages.FormatRetentionLosses(losses),
+		)
+	}
+
+	if !t.config.AllowRetentionReduction {
+		if t.config.DryRun {
+			log.Warnf("Applying this change will require --allow-retention-reduction")
+			return nil
+		}
+		return errors.New(
+			"Stopping because retention would be reduced; set --allow-retention-reduction to proceed",
 		)
 	}
 
 	return nil
 }
 
-func (t *TopicApplier) updatePartitions(
-	ctx context.Context,
-	topicInfo admin.TopicInfo,
-) error {
-	log.Infof("Checking partition count...")
+func (t *TopicApplier) updateSchemas(ctx context.Context) error {
+	schemasConfig := t.topicConfig.Spec.SchemasConfig
+	if schemasConfig == nil || t.schemasClient == nil {
+		return nil
+	}
 
-	currPartitions := len(topicInfo.Partitions)
+	log.Infof("Checking schema subjects...")
 
-	if currPartitions > t.topicConfig.Spec.Partitions {
-		return fmt.Errorf(
-			"Fewer partitions in topic config (%d) than observed (%d); this cannot be resolved by topicctl",
-			t.topicConfig.Spec.Partitions,
-			currPartitions,
+	for _, subject := range schemasConfig.Subjects() {
+		status, err := t.schemasClient.GetSubjectStatus(ctx, subject)
+		if err != nil {
+			return err
+		}
+
+		if !status.Registered {
+			log.Warnf(
+				"Subject %s does not have any registered schemas; these must be registered by producers or by hand",
+				subject,
+			)
+		} else {
+			log.Infof("Subject %s has %d registered version(s)", subject, status.NumVersions)
+		}
+
+		if schemasConfig.Compatibility == "" ||
+			status.Compatibility == schemasConfig.Compatibility {
+			continue
+		}
+
+		log.Infof(
+			"Compatibility for subject %s is %s, but should be %s",
+			subject,
+			status.Compatibility,
+			schemasConfig.Compatibility,
+		)
+
+		if t.config.DryRun {
+			log.Infof("Skipping update because dryRun is set to true")
+			continue
+		}
+
+		ok, err := t.confirm(
+			fmt.Sprintf("OK to update compatibility for subject %s?", subject),
+			ApprovalCategoryConfig,
 		)
-	} else if currPartitions < t.topicConfig.Spec.Partitions {
-		lock, path, err := t.acquireClusterLock(ctx)
 		if err != nil {
 			return err
 		}
-		if lock != nil {
-			defer func() {
-				log.Infof("Releasing cluster lock: %s", path)
-				lock.Unlock()
-			}()
+		if !ok {
+			return errors.New("Stopping because of user response")
 		}
+		log.Infof("OK, updating")
 
-		return t.updatePartitionsHelper(
+		if err := t.schemasClient.SetCompatibility(
 			ctx,
-			t.topicConfig.Spec.PlacementConfig.Strategy,
-		)
+			subject,
+			schemasConfig.Compatibility,
+		); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func (t *TopicApplier) updatePartitionsHelper(
+func (t *TopicApplier) updateReplication(
 	ctx context.Context,
-	desiredPlacement config.PlacementStrategy,
+	topicInfo admin.TopicInfo,
 ) error {
-	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
-	if err != nil {
-		return err
+	log.Infof("Checking replication...")
+
+	desiredReplication := t.topicConfig.Spec.ReplicationFactor
+	currReplication := topicInfo.MaxReplication()
+
+	if currReplication == desiredReplication &&
+		topicInfo.MaxISR() == desiredReplication {
+		return nil
+	}
+
+	if !topicInfo.AllReplicasInSync() {
+		return fmt.Errorf(
+			"Replication in topic config (%d) is not equal to observed replication (%d), but cannot update it while replicas are out-of-sync",
+			desiredReplication,
+			currReplication,
+		)
 	}
-	currAssignments := topicInfo.ToAssignments()
 
-	extraPartitions := t.topicConfig.Spec.Partitions - len(topicInfo.Partitions)
 	log.Infof(
-		"Trying to add %d additional partitions consistent with '%s' strategy",
-		extraPartitions,
-		desiredPlacement,
+		"Replication in topic config (%d) is not equal to observed replication (%d)",
+		desiredReplication,
+		currReplication,
 	)
 
-	picker, err := t.getPicker(ctx)
+	desiredPlacement := t.topicConfig.Spec.PlacementConfig.Strategy
+	if desiredPlacement == config.PlacementStrategyStatic {
+		log.Infof("Replicas will be updated to match the static assignments when checking placement")
+		return nil
+	}
+
+	lock, path, err := t.acquireClusterLock(ctx)
 	if err != nil {
 		return err
 	}
+	if lock != nil {
+		defer func() {
+			log.Infof("Releasing cluster lock: %s", path)
+			lock.Unlock()
+		}()
+	}
 
-	var extender extenders.Extender
+	picker, err := t.getPicker(ctx)
+	if err != nil {
+		return err
+	}
 
-	switch desiredPlacement {
-	case config.PlacementStrategyStatic:
-		extender = &extenders.StaticExtender{
+	// New replicas are added while keeping the existing ones in place; the reassignment
+	// isn't considered done until all of them are in the ISR. Any placement changes needed
+	// for the desired strategy are made afterwards.
+	assigner := assigners.NewConstrainedAssigner(
+		assigners.NewReplicationAssigner(
+			t.placementBrokers,
+			desiredReplication,
+			desiredPlacement == config.PlacementStrategyInRack ||
+				desiredPlacement == config.PlacementStrategyStaticInRack,
+			picker,
+		),
+		t.brokers,
+		picker,
+		t.topicConfig.Spec.PlacementConfig,
+	)
+
+	currAssignments := topicInfo.ToAssignments()
+	desiredAssignments, err := assigner.Assign(t.topicName, currAssignments)
+	if err != nil {
+		return err
+	}
+
+	batchSize := t.maxBatchSize
+	if batchSize < 0 {
+		batchSize = len(topicInfo.Partitions)
+	}
+
+	return t.updatePlacementRunner(
+		ctx,
+		currAssignments,
+		desiredAssignments,
+		batchSize,
+		false,
+	)
+}
+
+func (t *TopicApplier) updatePartitions(
+	ctx context.Context,
+	topicInfo admin.TopicInfo,
+) error {
+	log.Infof("Checking partition count...")
+
+	currPartitions := len(topicInfo.Partitions)
+
+	if currPartitions > t.topicConfig.Spec.Partitions {
+		return fmt.Errorf(
+			"Fewer partitions in topic config (%d) than observed (%d); this cannot be resolved by topicctl",
+			t.topicConfig.Spec.Partitions,
+			currPartitions,
+		)
+	} else if currPartitions < t.topicConfig.Spec.Partitions {
+		if t.topicConfig.Spec.Keyed {
+			log.Warnf(
+				"Topic is keyed; increasing partitions from %d to %d will change which partition most keys map to. Consumers that rely on per-key ordering or co-partitioning may see out-of-order or misrouted messages.",
+				currPartitions,
+				t.topicConfig.Spec.Partitions,
+			)
+
+			if !t.config.AllowRepartitioning {
+				if !t.config.DryRun {
+					return errors.New(
+						"Stopping because keyed topic would be repartitioned; set --allow-repartitioning to proceed",
+					)
+				}
+				// Keep going so that the dry run shows the rest of the change
+				log.Warnf("Applying this change will require --allow-repartitioning")
+			}
+		}
+
+		lock, path, err := t.acquireClusterLock(ctx)
+		if err != nil {
+			return err
+		}
+		if lock != nil {
+			defer func() {
+				log.Infof("Releasing cluster lock: %s", path)
+				lock.Unlock()
+			}()
+		}
+
+		if t.config.PartitionStepSize > 0 {
+			return t.updatePartitionsProgressive(ctx, currPartitions)
+		}
+
+		return t.updatePartitionsHelper(
+			ctx,
+			t.topicConfig.Spec.PlacementConfig.Strategy,
+			t.topicConfig.Spec.Partitions,
+		)
+	}
+
+	return nil
+}
+
+// updatePartitionsProgressive adds partitions to the topic in steps of the configured size,
+// waiting for all replicas in the topic to be in-sync (plus the configured delay) between
+// each step.
+func (t *TopicApplier) updatePartitionsProgressive(
+	ctx context.Context,
+	currPartitions int,
+) error {
+	steps := partitionSteps(
+		currPartitions,
+		t.topicConfig.Spec.Partitions,
+		t.config.PartitionStepSize,
+	)
+
+	stepStrs := []string{fmt.Sprintf("%d", currPartitions)}
+	for _, step := range steps {
+		stepStrs = append(stepStrs, fmt.Sprintf("%d", step))
+	}
+	log.Infof(
+		"Expanding partitions in %d step(s): %s",
+		len(steps),
+		strings.Join(stepStrs, " -> "),
+	)
+
+	if t.config.DryRun {
+		// Nothing will actually be added, so just show the diffs for the full expansion
+		return t.updatePartitionsHelper(
+			ctx,
+			t.topicConfig.Spec.PlacementConfig.Strategy,
+			t.topicConfig.Spec.Partitions,
+		)
+	}
+
+	for s, step := range steps {
+		log.Infof("Expanding partitions to %d (step %d/%d)", step, s+1, len(steps))
+
+		if err := t.updatePartitionsHelper(
+			ctx,
+			t.topicConfig.Spec.PlacementConfig.Strategy,
+			step,
+		); err != nil {
+			return err
+		}
+
+		if s == len(steps)-1 {
+			break
+		}
+
+		for {
+			topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
+			if err != nil {
+				return err
+			}
+			if topicInfo.AllReplicasInSync() {
+				break
+			}
+
+			log.Infof(
+				"Waiting for all replicas to be in-sync before the next step:\n%s",
+				admin.FormatTopicPartitions(topicInfo.OutOfSyncPartitions(nil), t.brokers),
+			)
+			if err := interruptableSleep(ctx, t.config.SleepLoopTime); err != nil {
+				return err
+			}
+		}
+
+		if t.config.PartitionStepDelay > 0 {
+			if err := interruptableSleep(ctx, t.config.PartitionStepDelay); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *TopicApplier) updatePartitionsHelper(
+	ctx context.Context,
+	desiredPlacement config.PlacementStrategy,
+	targetPartitions int,
+) error {
+	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
+	if err != nil {
+		return err
+	}
+	currAssignments := topicInfo.ToAssignments()
+
+	extraPartitions := targetPartitions - len(topicInfo.Partitions)
+	log.Infof(
+		"Trying to add %d additional partitions consistent with '%s' strategy",
+		extraPartitions,
+		desiredPlacement,
+	)
+
+	picker, err := t.getPicker(ctx)
+	if err != nil {
+		return err
+	}
+
+	var extender extenders.Extender
+
+	switch desiredPlacement {
+	case config.PlacementStrategyStatic:
+		extender = &extenders.StaticExtender{
 			Assignments: admin.ReplicasToAssignments(
 				t.topicConfig.Spec.PlacementConfig.StaticAssignments,
 			),
 		}
 	case config.PlacementStrategyInRack:
 		extender = extenders.NewBalancedExtender(
-			t.brokers,
+			t.placementBrokers,
 			true,
 			picker,
 		)
+	case config.PlacementStrategyBalancedTopicSet:
+		topicSetAssignments := t.topicConfig.Spec.PlacementConfig.TopicSetAssignments
+		if len(topicSetAssignments) >= targetPartitions {
+			extender = &extenders.StaticExtender{
+				Assignments: admin.ReplicasToAssignments(
+					topicSetAssignments[:targetPartitions],
+				),
+			}
+		} else {
+			extender = extenders.NewBalancedExtender(
+				t.placementBrokers,
+				false,
+				picker,
+			)
+		}
 	case config.PlacementStrategyBalancedLeaders, config.PlacementStrategyAny:
 		extender = extenders.NewBalancedExtender(
-			t.brokers,
+			t.placementBrokers,
 			false,
 			picker,
 		)
@@ -515,6 +1137,10 @@ func (t *TopicApplier) updatePartitionsHelper(
 	if err != nil {
 		return err
 	}
+	desiredAssignments = assigners.ApplyLeaderPreferences(
+		desiredAssignments,
+		t.topicConfig.Spec.PlacementConfig.PreferredLeaderBrokers,
+	)
 
 	// Only consider the added partitions
 	currAssignments = []admin.PartitionAssignment{}
@@ -531,18 +1157,36 @@ func (t *TopicApplier) updatePartitionsHelper(
 
 	if t.config.DryRun {
 		log.Infof("Skipping update because dryRun is set to true")
+		t.changes.addPartitions(desiredAssignments)
 		return nil
 	}
 
-	ok, _ := Confirm("OK to apply?", t.config.SkipConfirm)
+	proposed := &TopicChanges{}
+	proposed.addPartitions(desiredAssignments)
+	if err := t.checkPlanned(proposed); err != nil {
+		return err
+	}
+
+	ok, err := t.confirm("OK to apply?", ApprovalCategoryConfig)
+	if err != nil {
+		return err
+	}
 	if !ok {
 		return errors.New("Stopping because of user response")
 	}
 
+	if err := t.backupTopic(ctx); err != nil {
+		return err
+	}
+	if err := t.startMigration(ctx); err != nil {
+		return err
+	}
+
 	err = t.updatePartitionsIteration(ctx, currAssignments, desiredAssignments, true)
 	if err != nil {
 		return err
 	}
+	t.changes.addPartitions(desiredAssignments)
 
 	topicInfo, err = t.adminClient.GetTopic(ctx, t.topicName, true)
 	if err != nil {
@@ -573,7 +1217,7 @@ func (t *TopicApplier) updatePlacement(
 
 	result, err := assigners.EvaluateAssignments(
 		currAssignments,
-		t.brokers,
+		t.placementBrokers,
 		t.topicConfig.Spec.PlacementConfig,
 	)
 	if err != nil {
@@ -609,7 +1253,8 @@ func (t *TopicApplier) updatePlacement(
 	switch desiredPlacement {
 	case config.PlacementStrategyStatic,
 		config.PlacementStrategyStaticInRack,
-		config.PlacementStrategyBalancedLeaders:
+		config.PlacementStrategyBalancedLeaders,
+		config.PlacementStrategyBalancedTopicSet:
 		return t.updatePlacementHelper(
 			ctx,
 			desiredPlacement,
@@ -621,7 +1266,7 @@ func (t *TopicApplier) updatePlacement(
 		// block this, but we should at least warn the user before continuing.
 		result, err = assigners.EvaluateAssignments(
 			currAssignments,
-			t.brokers,
+			t.placementBrokers,
 			config.TopicPlacementConfig{
 				Strategy: config.PlacementStrategyBalancedLeaders,
 			},
@@ -634,10 +1279,16 @@ func (t *TopicApplier) updatePlacement(
 				"Desired strategy is in-rack, but leaders aren't balanced. It is strongly suggested to do the latter first.",
 			)
 
-			ok, _ := Confirm(
-				"OK to apply in-rack despite having unbalanced leaders?",
-				t.config.SkipConfirm || t.config.DryRun,
-			)
+			ok := true
+			if !t.config.DryRun {
+				ok, err = t.confirm(
+					"OK to apply in-rack despite having unbalanced leaders?",
+					ApprovalCategoryReassign,
+				)
+				if err != nil {
+					return err
+				}
+			}
 			if !ok {
 				return errors.New("Stopping because of user response")
 			}
@@ -654,24 +1305,160 @@ func (t *TopicApplier) updatePlacement(
 	}
 }
 
-func (t *TopicApplier) updateBalance(
+// updateRacks checks for partitions whose replicas aren't spread across the expected number
+// of racks, e.g. because brokers were replaced with ones in different racks. If the
+// FixRackViolations option is set, then the affected replicas are moved to fix these.
+func (t *TopicApplier) updateRacks(
 	ctx context.Context,
 	batchSize int,
 ) error {
-	log.Info("Running rebalance...")
+	expectedRacks := t.topicConfig.ExpectedRacks()
+	if t.topicConfig.Spec.PlacementConfig.Strategy == config.PlacementStrategyStatic ||
+		(expectedRacks == 0 && !t.config.FixRackViolations) {
+		return nil
+	}
+
+	log.Infof("Checking partition racks...")
 
 	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
 	if err != nil {
 		return err
 	}
+	violations := topicInfo.RackViolations(t.placementBrokers, expectedRacks)
+
+	if len(violations) == 0 {
+		log.Infof("Partition racks look good")
+		return nil
+	}
+
+	log.Warnf(
+		"The following %d partitions have rack violations:\n%s",
+		len(violations),
+		admin.FormatTopicPartitions(violations, t.brokers),
+	)
+
+	if !t.config.FixRackViolations {
+		log.Warnf("Re-run apply with --fix-rack-violations to fix these")
+		return nil
+	}
+
+	lock, path, err := t.acquireClusterLock(ctx)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		defer func() {
+			log.Infof("Releasing cluster lock: %s", path)
+			lock.Unlock()
+		}()
+	}
+
+	picker, err := t.getPicker(ctx)
+	if err != nil {
+		return err
+	}
+
 	currAssignments := topicInfo.ToAssignments()
+	desiredAssignments, err := assigners.NewRackSpreadAssigner(
+		t.placementBrokers,
+		picker,
+		expectedRacks,
+	).Assign(t.topicName, currAssignments)
+	if err != nil {
+		return err
+	}
 
-	// TODO: Make these parameters configurable?
-	rebalancer := rebalancers.NewFrequencyRebalancer(
-		t.brokers,
-		pickers.NewRandomizedPicker(),
+	ok, err := assigners.EvaluateAssignments(
+		desiredAssignments,
+		t.placementBrokers,
 		t.topicConfig.Spec.PlacementConfig,
 	)
+	if err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf(
+			"Fixing the rack violations would make the placement inconsistent with strategy '%s'",
+			t.topicConfig.Spec.PlacementConfig.Strategy,
+		)
+	}
+
+	if batchSize < 0 {
+		// Do all partitions at once
+		batchSize = len(topicInfo.Partitions)
+	}
+
+	return t.updatePlacementRunner(
+		ctx,
+		currAssignments,
+		desiredAssignments,
+		batchSize,
+		false,
+	)
+}
+
+func (t *TopicApplier) updateBalance(
+	ctx context.Context,
+	batchSize int,
+) error {
+	log.Info("Running rebalance...")
+
+	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
+	if err != nil {
+		return err
+	}
+	currAssignments := topicInfo.ToAssignments()
+
+	var rebalancer rebalancers.Rebalancer
+	var partitionRates map[int]float64
+
+	if t.config.PartitionMetrics != nil {
+		partitionRates, err = t.config.PartitionMetrics.FetchPartitionRates(ctx, t.topicName)
+		if err != nil {
+			return err
+		}
+		for _, assignment := range currAssignments {
+			if _, ok := partitionRates[assignment.ID]; !ok {
+				log.Warnf(
+					"No metrics found for partition %d; assuming that it has no traffic",
+					assignment.ID,
+				)
+			}
+		}
+	}
+
+	if len(t.clusterConfig.Spec.RebalanceGoals) > 0 {
+		// With time-based retention, the amount of data stored for each partition is
+		// roughly proportional to its bytes-in rate, so use the rates for the disk balance
+		// goal if they're available.
+		goals, err := assigners.GoalsFromConfig(
+			t.clusterConfig.Spec.RebalanceGoals,
+			partitionRates,
+		)
+		if err != nil {
+			return err
+		}
+		log.Infof("Rebalancing with goals %+v", t.clusterConfig.Spec.RebalanceGoals)
+
+		rebalancer = rebalancers.NewGoalRebalancer(
+			t.placementBrokers,
+			t.topicConfig.Spec.PlacementConfig,
+			goals,
+		)
+	} else if partitionRates != nil {
+		rebalancer = rebalancers.NewThroughputRebalancer(
+			t.placementBrokers,
+			t.topicConfig.Spec.PlacementConfig,
+			partitionRates,
+		)
+	} else {
+		// TODO: Make these parameters configurable?
+		rebalancer = rebalancers.NewFrequencyRebalancer(
+			t.placementBrokers,
+			pickers.NewRandomizedPicker(),
+			t.topicConfig.Spec.PlacementConfig,
+		)
+	}
+
 	desiredAssignments, err := rebalancer.Rebalance(
 		t.topicName,
 		currAssignments,
@@ -680,6 +1467,37 @@ func (t *TopicApplier) updateBalance(
 	if err != nil {
 		return err
 	}
+	if t.topicConfig.Spec.PlacementConfig.Sticky {
+		// Only put replicas back on brokers that aren't being removed
+		desiredAssignments, err = assigners.MinimizeMoves(
+			currAssignments,
+			desiredAssignments,
+			assigners.FilterBrokers(t.placementBrokers, t.config.BrokersToRemove),
+			t.topicConfig.Spec.PlacementConfig,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	desiredAssignments = assigners.ApplyLeaderPreferences(
+		desiredAssignments,
+		t.topicConfig.Spec.PlacementConfig.PreferredLeaderBrokers,
+	)
+
+	if partitionRates != nil {
+		log.Infof(
+			"Leader bytes-in rates by broker before rebalance: %s",
+			formatBrokerRates(
+				rebalancers.BrokerLeaderRates(currAssignments, t.brokers, partitionRates),
+			),
+		)
+		log.Infof(
+			"Leader bytes-in rates by broker after rebalance: %s",
+			formatBrokerRates(
+				rebalancers.BrokerLeaderRates(desiredAssignments, t.brokers, partitionRates),
+			),
+		)
+	}
 
 	assignmentsToUpdate := admin.AssignmentsToUpdate(
 		currAssignments,
@@ -721,9 +1539,9 @@ func (t *TopicApplier) updatePlacementHelper(
 
 	switch desiredPlacement {
 	case config.PlacementStrategyBalancedLeaders:
-		assigner = assigners.NewBalancedLeaderAssigner(t.brokers, picker)
+		assigner = assigners.NewBalancedLeaderAssigner(t.placementBrokers, picker)
 	case config.PlacementStrategyInRack:
-		assigner = assigners.NewSingleRackAssigner(t.brokers, picker)
+		assigner = assigners.NewSingleRackAssigner(t.placementBrokers, picker)
 	case config.PlacementStrategyStatic:
 		assigner = &assigners.StaticAssigner{
 			Assignments: admin.ReplicasToAssignments(
@@ -732,19 +1550,86 @@ func (t *TopicApplier) updatePlacementHelper(
 		}
 	case config.PlacementStrategyStaticInRack:
 		assigner = assigners.NewStaticSingleRackAssigner(
-			t.brokers,
+			t.placementBrokers,
 			t.topicConfig.Spec.PlacementConfig.StaticRackAssignments,
 			picker,
 		)
+	case config.PlacementStrategyBalancedTopicSet:
+		topicSetAssignments := t.topicConfig.Spec.PlacementConfig.TopicSetAssignments
+		if topicSetAssignments == nil {
+			assigner = assigners.NewBalancedLeaderAssigner(t.placementBrokers, picker)
+		} else if len(topicSetAssignments) >= len(currAssignments) {
+			assigner = &assigners.StaticAssigner{
+				Assignments: admin.ReplicasToAssignments(
+					topicSetAssignments[:len(currAssignments)],
+				),
+			}
+		} else {
+			return fmt.Errorf(
+				"Topic has more partitions (%d) than the other topics in topic set %s (%d)",
+				len(currAssignments),
+				t.topicConfig.Spec.PlacementConfig.TopicSet,
+				len(topicSetAssignments),
+			)
+		}
 	default:
 		return fmt.Errorf("Cannot update using strategy %s", desiredPlacement)
 	}
 
+	assigner = assigners.NewConstrainedAssigner(
+		assigner,
+		t.brokers,
+		picker,
+		t.topicConfig.Spec.PlacementConfig,
+	)
+	if t.topicConfig.Spec.PlacementConfig.Sticky {
+		assigner = assigners.NewStickyAssigner(
+			assigner,
+			t.placementBrokers,
+			t.topicConfig.Spec.PlacementConfig,
+		)
+	}
+
 	desiredAssignments, err := assigner.Assign(t.topicName, currAssignments)
 	if err != nil {
 		return err
 	}
 
+	// If only some partitions are out of line, e.g. because a broker moved racks, then
+	// just move those instead of rewriting the rest of the topic
+	violations, err := assigners.PlacementViolations(
+		currAssignments,
+		t.brokers,
+		t.topicConfig.Spec.PlacementConfig,
+	)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		repairedAssignments := assigners.RepairViolations(
+			currAssignments,
+			desiredAssignments,
+			violations,
+		)
+		ok, err := assigners.EvaluateAssignments(
+			repairedAssignments,
+			t.placementBrokers,
+			t.topicConfig.Spec.PlacementConfig,
+		)
+		if err != nil {
+			return err
+		}
+		if ok {
+			log.Infof(
+				"Only updating the %d partition(s) that violate strategy '%s':\n%s",
+				len(violations),
+				desiredPlacement,
+				assigners.FormatPlacementViolations(violations),
+			)
+			desiredAssignments = repairedAssignments
+		}
+	}
+
 	return t.updatePlacementRunner(
 		ctx,
 		currAssignments,
@@ -779,71 +1664,297 @@ func (t *TopicApplier) updatePlacementRunner(
 		),
 	)
 
-	log.Infof(
-		"They will be applied in batches of %d partitions each, with a throttle of %d bytes/sec (%d MB/sec)",
-		batchSize,
-		t.throttleBytes,
-		t.throttleBytes/1000000,
-	)
+	log.Infof(
+		"They will be applied in batches of %d partitions each, with a throttle of %d bytes/sec (%d MB/sec)",
+		batchSize,
+		t.throttleBytes,
+		t.throttleBytes/1000000,
+	)
+	if priority := t.topicConfig.MigrationPriority(); priority != 0 {
+		log.Infof(
+			"The topic has a migration priority of %d, so it's moved before topics with lower priorities in the same apply",
+			priority,
+		)
+	}
+
+	assignmentsToUpdate := admin.AssignmentsToUpdate(
+		currAssignments,
+		desiredAssignments,
+	)
+
+	if !newTopic {
+		t.logReplicaMoves(ctx, currAssignments, desiredAssignments)
+
+		if err := t.checkReplicaRemovals(ctx, assignmentsToUpdate); err != nil {
+			return err
+		}
+		if err := t.checkMaintenanceWindow(time.Now()); err != nil {
+			return err
+		}
+	}
+
+	currDiffAssignments := []admin.PartitionAssignment{}
+
+	for _, diff := range assignmentsToUpdate {
+		currDiffAssignments = append(
+			currDiffAssignments,
+			currAssignments[diff.ID],
+		)
+	}
+
+	if t.config.DryRun {
+		log.Infof("Skipping update because dryRun is set to true")
+		t.changes.addReassignments(currDiffAssignments, assignmentsToUpdate)
+		return nil
+	}
+
+	if !newTopic {
+		// Placement changes right after a topic is created aren't planned since a dry run
+		// doesn't create the topic
+		proposed := &TopicChanges{}
+		proposed.addReassignments(currDiffAssignments, assignmentsToUpdate)
+		if err := t.checkPlanned(proposed); err != nil {
+			return err
+		}
+	}
+
+	ok, err := t.confirm("OK to apply?", ApprovalCategoryReassign)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if !newTopic {
+		if err := t.backupTopic(ctx); err != nil {
+			return err
+		}
+		if err := t.startMigration(ctx); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < len(assignmentsToUpdate); i += batchSize {
+		end := i + batchSize
+
+		if end > len(assignmentsToUpdate) {
+			end = len(assignmentsToUpdate)
+		}
+
+		err := t.updatePartitionsIteration(
+			ctx,
+			currDiffAssignments[i:end],
+			assignmentsToUpdate[i:end],
+			newTopic,
+		)
+		if err != nil {
+			return err
+		}
+		t.changes.addReassignments(
+			currDiffAssignments[i:end],
+			assignmentsToUpdate[i:end],
+		)
+
+		ok, err := t.confirm("OK to continue?", ApprovalCategoryReassign)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("Stopping because of user response")
+		}
+	}
+
+	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"Update complete; new partition status:\n%s",
+		admin.FormatTopicPartitions(topicInfo.Partitions, t.brokers),
+	)
+
+	return nil
+}
+
+// logReplicaMoves logs the number of replicas that will be copied to new brokers by the
+// argument update and, if the log dirs of the brokers can be fetched, an estimate of the
+// number of bytes that will be copied in total and into and out of each broker.
+func (t *TopicApplier) logReplicaMoves(
+	ctx context.Context,
+	currAssignments []admin.PartitionAssignment,
+	desiredAssignments []admin.PartitionAssignment,
+) {
+	replicaMoves := admin.ReplicaMoves(currAssignments, desiredAssignments)
+
+	totalMoves := 0
+	for _, moves := range replicaMoves {
+		totalMoves += moves
+	}
+	if totalMoves == 0 {
+		log.Infof("The update doesn't move any replicas to new brokers")
+		return
+	}
+
+	logDirs, err := t.adminClient.GetLogDirs(ctx, t.brokers)
+	if err != nil {
+		log.Warnf("Could not get log dirs to estimate the bytes to move: %+v", err)
+		log.Infof(
+			"The update moves %d partition-replica(s) across %d partition(s)",
+			totalMoves,
+			len(replicaMoves),
+		)
+		return
+	}
+
+	partitionSizes := map[int]int64{}
+	for _, logDir := range logDirs {
+		for _, replica := range logDir.Replicas {
+			if replica.Topic == t.topicName && !replica.IsFuture &&
+				replica.Size > partitionSizes[replica.Partition] {
+				partitionSizes[replica.Partition] = replica.Size
+			}
+		}
+	}
+
+	log.Infof(
+		"The update moves %d partition-replica(s) across %d partition(s), copying an estimated %s",
+		totalMoves,
+		len(replicaMoves),
+		util.PrettyBytes(admin.MoveBytes(currAssignments, desiredAssignments, partitionSizes)),
+	)
+	log.Infof(
+		"Here is the estimated data movement per broker, based on the current replica sizes:\n%s",
+		admin.FormatBrokerMoveBytes(
+			currAssignments,
+			desiredAssignments,
+			partitionSizes,
+			t.brokers,
+		),
+	)
+}
+
+// checkFrozen returns whether the topic is frozen, either by its config or in the cluster,
+// at the argument time. If so, it logs a warning and records that the topic was skipped.
+func (t *TopicApplier) checkFrozen(ctx context.Context, now time.Time) (bool, error) {
+	var source, reason, expiry string
 
-	if t.config.DryRun {
-		log.Infof("Skipping update because dryRun is set to true")
-		return nil
+	frozen, err := t.topicConfig.Meta.FrozenAt(now)
+	if err != nil {
+		return false, err
+	}
+	if frozen {
+		source = "its config"
+		reason = t.topicConfig.Meta.FreezeReason
+		expiry = t.topicConfig.Meta.FreezeExpiry
+	} else {
+		freeze, err := t.adminClient.GetTopicFreeze(ctx, t.topicName)
+		if err != nil {
+			return false, err
+		}
+		if freeze != nil && freeze.Active(now) {
+			frozen = true
+			source = fmt.Sprintf("%s@%s", freeze.User, freeze.Host)
+			reason = freeze.Reason
+			if !freeze.Expiry.IsZero() {
+				expiry = freeze.Expiry.Format(time.RFC3339)
+			}
+		} else if freeze != nil {
+			log.Infof("The cluster freeze for topic %s has expired; applying it", t.topicName)
+		}
 	}
 
-	ok, _ := Confirm("OK to apply?", t.config.SkipConfirm)
-	if !ok {
-		return errors.New("Stopping because of user response")
+	if !frozen {
+		return false, nil
 	}
 
-	assignmentsToUpdate := admin.AssignmentsToUpdate(
-		currAssignments,
-		desiredAssignments,
+	if reason == "" {
+		reason = "no reason given"
+	}
+	if expiry == "" {
+		expiry = "never"
+	}
+	log.Warnf(
+		"**** SKIPPING TOPIC %s: it's frozen by %s (reason: %s, expires: %s) ****",
+		t.topicName,
+		source,
+		reason,
+		expiry,
 	)
+	t.changes.Skipped = fmt.Sprintf("frozen: %s", reason)
 
-	currDiffAssignments := []admin.PartitionAssignment{}
+	return true, nil
+}
 
-	for _, diff := range assignmentsToUpdate {
-		currDiffAssignments = append(
-			currDiffAssignments,
-			currAssignments[diff.ID],
-		)
+// checkMaintenanceWindow returns an error if the argument time is outside of the cluster's
+// maintenance windows, unless the window is ignored. In dry-run mode, it only logs a warning.
+func (t *TopicApplier) checkMaintenanceWindow(now time.Time) error {
+	ok, err := t.clusterConfig.InMaintenanceWindow(now)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
 	}
 
-	for i := 0; i < len(assignmentsToUpdate); i += batchSize {
-		end := i + batchSize
-
-		if end > len(assignmentsToUpdate) {
-			end = len(assignmentsToUpdate)
-		}
+	next, err := t.clusterConfig.NextMaintenanceWindow(now)
+	if err != nil {
+		return err
+	}
+	nextStr := "none found in the next year"
+	if !next.IsZero() {
+		nextStr = next.Format(time.RFC3339)
+	}
 
-		err := t.updatePartitionsIteration(
-			ctx,
-			currDiffAssignments[i:end],
-			assignmentsToUpdate[i:end],
-			newTopic,
+	switch {
+	case t.config.IgnoreMaintenanceWindow:
+		log.Warnf(
+			"Moving data outside of the cluster's maintenance windows (next one starts: %s) because --ignore-window is set",
+			nextStr,
+		)
+		return nil
+	case t.config.DryRun:
+		log.Warnf(
+			"This update moves data, but it's outside of the cluster's maintenance windows (next one starts: %s); a real apply would be refused without --ignore-window",
+			nextStr,
+		)
+		return nil
+	default:
+		return fmt.Errorf(
+			"Not moving data outside of the cluster's maintenance windows (next one starts: %s); re-run in a window or with --ignore-window to override",
+			nextStr,
 		)
-		if err != nil {
-			return err
-		}
-
-		ok, _ := Confirm("OK to continue?", t.config.SkipConfirm)
-		if !ok {
-			return errors.New("Stopping because of user response")
-		}
 	}
+}
 
+// checkReplicaRemovals verifies that the argument reassignments won't remove the only
+// in-sync replicas of any partition or leave it with fewer in-sync replicas than the
+// topic's min.insync.replicas.
+func (t *TopicApplier) checkReplicaRemovals(
+	ctx context.Context,
+	assignmentsToUpdate []admin.PartitionAssignment,
+) error {
 	topicInfo, err := t.adminClient.GetTopic(ctx, t.topicName, true)
 	if err != nil {
 		return err
 	}
+	clusterDefaults, err := t.adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		return err
+	}
 
-	log.Infof(
-		"Update complete; new partition status:\n%s",
-		admin.FormatTopicPartitions(topicInfo.Partitions, t.brokers),
+	minISRStr := admin.EffectiveTopicConfigValue(
+		"min.insync.replicas",
+		topicInfo.Config,
+		clusterDefaults,
 	)
+	minISR, err := strconv.Atoi(minISRStr)
+	if err != nil {
+		return fmt.Errorf("Could not parse min.insync.replicas value %s: %+v", minISRStr, err)
+	}
 
-	return nil
+	return admin.CheckReplicaRemovals(topicInfo, assignmentsToUpdate, minISR)
 }
 
 func (t *TopicApplier) updatePartitionsIteration(
@@ -890,11 +2001,41 @@ func (t *TopicApplier) updatePartitionsIteration(
 	checkTimer := time.NewTicker(t.config.SleepLoopTime)
 	defer checkTimer.Stop()
 
+	// stop is set to nil once a stop request has been handled so that it isn't selected
+	// again while the in-flight reassignment finishes
+	stop := t.config.Stop
+
 	log.Info("Sleeping then entering check loop")
 
 outerLoop:
 	for {
 		select {
+		case <-stop:
+			// Partition additions can't be cancelled, so they're always waited for
+			if !t.config.CancelReassignmentsOnStop || len(currAssignments) == 0 {
+				log.Infof(
+					"Stop requested; waiting for the in-flight update of partition(s) %+v to finish",
+					idsToUpdate,
+				)
+				stop = nil
+				continue
+			}
+
+			log.Infof(
+				"Stop requested; cancelling the in-flight update of partition(s) %+v",
+				idsToUpdate,
+			)
+			if err := t.cancelReassignments(
+				idsToUpdate,
+				currAssignments,
+				assignmentsToUpdate,
+				throttledTopic,
+				throttledBrokers,
+			); err != nil {
+				t.changes.addInFlightReassignments(currAssignments, assignmentsToUpdate)
+				return err
+			}
+			return ErrApplyStopped
 		case <-checkTimer.C:
 			log.Info("Checking if all partitions in topic are properly replicated...")
 
@@ -947,6 +2088,11 @@ outerLoop:
 			)
 			log.Infof("Sleeping for %s", t.config.SleepLoopTime.String())
 		case <-ctx.Done():
+			// The reassignment continues in the cluster; record it so that it shows up
+			// in the report
+			if len(currAssignments) > 0 {
+				t.changes.addInFlightReassignments(currAssignments, assignmentsToUpdate)
+			}
 			return ctx.Err()
 		}
 	}
@@ -955,6 +2101,137 @@ outerLoop:
 	return t.removeThottles(ctx, throttledTopic, throttledBrokers)
 }
 
+// checkQuotas warns if the client ID used by the admin client is subject to any quotas
+// that could slow down the apply. If the RaiseAdminQuotas option is set, the quotas are
+// temporarily raised at the user and client entities that they're resolved from; the
+// returned function restores them and should be called once the apply is done.
+func (t *TopicApplier) checkQuotas(ctx context.Context) (func(), error) {
+	noop := func() {}
+
+	quotas, err := t.adminClient.GetClientQuotas(
+		ctx,
+		admin.AnonymousUser,
+		kafka.DefaultClientID,
+	)
+	if err != nil {
+		return noop, err
+	}
+	if len(quotas) == 0 {
+		return noop, nil
+	}
+
+	for _, quota := range quotas {
+		log.Warnf(
+			"Admin client ID '%s' is subject to quota %s=%s (from /config/%s)",
+			kafka.DefaultClientID,
+			quota.Key,
+			quota.Value,
+			quota.EntityPath,
+		)
+	}
+
+	if !t.config.RaiseAdminQuotas {
+		log.Warn(
+			"Requests from this tool may be throttled; set --raise-admin-quotas to raise the quotas for the duration of the apply",
+		)
+		return noop, nil
+	}
+	if t.config.DryRun {
+		log.Infof("Skipping quota update because dryRun is set to true")
+		return noop, nil
+	}
+
+	ok, err := t.confirm(
+		"OK to temporarily raise the quotas that apply to the admin client ID?",
+		ApprovalCategoryReassign,
+	)
+	if err != nil {
+		return noop, err
+	}
+	if !ok {
+		return noop, errors.New("Stopping because of user response")
+	}
+
+	// Each quota has to be raised at the entity path that it was resolved from since the
+	// more specific entities take precedence over any others
+	entityPaths := []string{}
+	raisedEntries := map[string][]kafka.ConfigEntry{}
+	restoreEntries := map[string][]kafka.ConfigEntry{}
+
+	for _, quota := range quotas {
+		if _, _, err := admin.ParseQuotaEntityPath(quota.EntityPath); err != nil {
+			return noop, fmt.Errorf(
+				"Cannot raise quota %s because the entity it's set on can't be determined: %+v",
+				quota.Key,
+				err,
+			)
+		}
+		if _, ok := raisedEntries[quota.EntityPath]; !ok {
+			entityPaths = append(entityPaths, quota.EntityPath)
+		}
+
+		raisedEntries[quota.EntityPath] = append(
+			raisedEntries[quota.EntityPath],
+			kafka.ConfigEntry{
+				ConfigName:  quota.Key,
+				ConfigValue: unlimitedQuotaValues[quota.Key],
+			},
+		)
+		restoreEntries[quota.EntityPath] = append(
+			restoreEntries[quota.EntityPath],
+			kafka.ConfigEntry{
+				ConfigName:  quota.Key,
+				ConfigValue: quota.Value,
+			},
+		)
+	}
+
+	restore := func() {
+		// Use a fresh context so that the quotas are restored even if the apply
+		// was interrupted.
+		for _, entityPath := range entityPaths {
+			log.Infof("Restoring quotas at /config/%s", entityPath)
+			_, err := t.adminClient.UpdateQuotaEntityConfig(
+				context.Background(),
+				entityPath,
+				restoreEntries[entityPath],
+				true,
+			)
+			if err != nil {
+				log.Warnf("Error restoring quotas at /config/%s: %+v", entityPath, err)
+			}
+		}
+	}
+
+	for e, entityPath := range entityPaths {
+		if admin.IsDefaultQuotaEntityPath(entityPath) {
+			log.Warnf(
+				"Quotas at /config/%s are defaults, so raising them also affects other clients until the apply is done",
+				entityPath,
+			)
+		}
+		log.Infof(
+			"Raising quotas at /config/%s: %+v",
+			entityPath,
+			raisedEntries[entityPath],
+		)
+		_, err = t.adminClient.UpdateQuotaEntityConfig(
+			ctx,
+			entityPath,
+			raisedEntries[entityPath],
+			true,
+		)
+		if err != nil {
+			// Put back any quotas that were already raised
+			entityPaths = entityPaths[:e]
+			restore()
+			return noop, err
+		}
+	}
+
+	return restore, nil
+}
+
 func (t *TopicApplier) applyThrottles(
 	ctx context.Context,
 	currAssignments []admin.PartitionAssignment,
@@ -1133,13 +2410,16 @@ func (t *TopicApplier) updateLeaders(
 			batchSize = len(wrongLeaders)
 		}
 
-		ok, _ := Confirm(
+		ok, err := t.confirm(
 			fmt.Sprintf(
 				"OK to run leader elections (in batches of %d partitions each) ?",
 				batchSize,
 			),
-			t.config.SkipConfirm,
+			ApprovalCategoryReassign,
 		)
+		if err != nil {
+			return err
+		}
 		if !ok {
 			return errors.New("Stopping because of user response")
 		}
@@ -1147,6 +2427,10 @@ func (t *TopicApplier) updateLeaders(
 		partitionIDs := admin.PartitionIDs(wrongLeaders)
 
 		for i := 0; i < len(partitionIDs); i += batchSize {
+			if err := t.checkStop(); err != nil {
+				return err
+			}
+
 			end := i + batchSize
 
 			if end > len(partitionIDs) {
@@ -1228,10 +2512,7 @@ func (t *TopicApplier) acquireClusterLock(ctx context.Context) (zk.Lock, string,
 
 	lockPath := t.clusterLockPath()
 	log.Infof("Acquiring cluster lock: %s", lockPath)
-	lockCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	lock, err := t.adminClient.AcquireLock(lockCtx, lockPath)
+	lock, err := t.acquireLock(ctx, lockPath)
 	return lock, lockPath, err
 }
 
@@ -1242,15 +2523,72 @@ func (t *TopicApplier) acquireTopicLock(ctx context.Context) (zk.Lock, string, e
 
 	lockPath := t.clusterLockPath()
 	log.Infof("Acquiring topic lock: %s", lockPath)
+	lock, err := t.acquireLock(ctx, lockPath)
+	return lock, lockPath, err
+}
+
+// acquireLock acquires the lock at the argument path. If LockWait is set in the config and
+// the lock is held by another process, e.g. a concurrent CI job, this first waits in line
+// for up to that long; otherwise, it gives up after 30 seconds.
+func (t *TopicApplier) acquireLock(ctx context.Context, lockPath string) (zk.Lock, error) {
+	if t.config.LockWait > 0 {
+		if err := locks.WaitForLock(ctx, t.locker, lockPath, t.config.LockWait); err != nil {
+			return nil, err
+		}
+	}
+
 	lockCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	lock, err := t.adminClient.AcquireLock(lockCtx, lockPath)
-	return lock, lockPath, err
+	lock, err := t.locker.AcquireLock(lockCtx, lockPath)
+	if err == context.DeadlineExceeded {
+		return nil, t.lockHeldError(ctx, lockPath)
+	}
+	if err == nil {
+		t.watchLock(ctx, lock, lockPath)
+	}
+	return lock, err
+}
+
+// watchLock stops the apply if the argument lock is lost before the apply is done. Only
+// some lock backends, e.g. DynamoDB, can lose locks that are held.
+func (t *TopicApplier) watchLock(ctx context.Context, lock zk.Lock, lockPath string) {
+	losableLock, ok := lock.(locks.LosableLock)
+	if !ok || t.cancelApply == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-losableLock.Lost():
+			log.Errorf("Lost lock %s; stopping the apply", lockPath)
+			t.lockMutex.Lock()
+			t.lostLockPath = lockPath
+			t.lockMutex.Unlock()
+			t.cancelApply()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+func (t *TopicApplier) getLostLockPath() string {
+	t.lockMutex.Lock()
+	defer t.lockMutex.Unlock()
+	return t.lostLockPath
+}
+
+// lockHeldError returns an error for a lock that couldn't be acquired in time, including
+// the process that holds it if that's known.
+func (t *TopicApplier) lockHeldError(ctx context.Context, lockPath string) error {
+	metadata, _, err := t.locker.LockHolder(ctx, lockPath)
+	if err != nil || metadata == nil {
+		return fmt.Errorf("Timed out acquiring lock %s", lockPath)
+	}
+	return fmt.Errorf("Timed out acquiring lock %s, which is held by %s", lockPath, metadata)
 }
 
 func (t *TopicApplier) clusterLockHeld(ctx context.Context) (bool, error) {
-	return t.adminClient.LockHeld(ctx, t.clusterLockPath())
+	return t.locker.LockHeld(ctx, t.clusterLockPath())
 }
 
 func (t *TopicApplier) clusterLockPath() string {
@@ -1278,6 +2616,124 @@ func (t *TopicApplier) topicLockPath() string {
 	)
 }
 
+// resolveTopicSet looks for existing topics in the same topic set as the one being applied.
+// If there are any, the one with the most partitions is used as the reference that this topic
+// follows. Otherwise, this topic is the first in the set and is placed like a
+// balanced-leaders topic.
+func (t *TopicApplier) resolveTopicSet(ctx context.Context) error {
+	placementConfig := &t.topicConfig.Spec.PlacementConfig
+	placementConfig.TopicSetAssignments = nil
+
+	var reference *admin.TopicInfo
+
+	for _, member := range t.config.TopicSetMembers {
+		if member == t.topicName {
+			continue
+		}
+
+		topicInfo, err := t.adminClient.GetTopic(ctx, member, false)
+		if err != nil {
+			if err == admin.ErrTopicDoesNotExist {
+				log.Debugf("Topic set member %s does not exist yet", member)
+				continue
+			}
+			return err
+		}
+
+		if reference == nil || len(topicInfo.Partitions) > len(reference.Partitions) {
+			reference = &topicInfo
+		}
+	}
+
+	if reference == nil {
+		log.Infof(
+			"No other topics in topic set %s exist, so placing this topic with balanced leaders",
+			placementConfig.TopicSet,
+		)
+		return nil
+	}
+
+	if len(reference.Partitions) < t.topicConfig.Spec.Partitions {
+		log.Warnf(
+			"Topic %s in topic set %s only has %d partitions; placing this topic with balanced leaders until it's expanded",
+			reference.Name,
+			placementConfig.TopicSet,
+			len(reference.Partitions),
+		)
+		return nil
+	}
+
+	replicas, err := admin.AssignmentsToReplicas(reference.ToAssignments())
+	if err != nil {
+		return err
+	}
+	if len(replicas) > 0 && len(replicas[0]) != t.topicConfig.Spec.ReplicationFactor {
+		return fmt.Errorf(
+			"Topic %s in topic set %s has replication factor %d, but this topic has %d",
+			reference.Name,
+			placementConfig.TopicSet,
+			len(replicas[0]),
+			t.topicConfig.Spec.ReplicationFactor,
+		)
+	}
+
+	log.Infof(
+		"Aligning placement with topic %s in topic set %s",
+		reference.Name,
+		placementConfig.TopicSet,
+	)
+	placementConfig.TopicSetAssignments = replicas[:t.topicConfig.Spec.Partitions]
+
+	return nil
+}
+
+// avoidMaintenanceLeaders removes any brokers that are in maintenance mode from the topic's
+// preferred leaders so that this apply doesn't make them partition leaders. If the topic
+// doesn't have any preferred leaders configured, then all of the other brokers are used.
+func (t *TopicApplier) avoidMaintenanceLeaders(ctx context.Context) error {
+	maintenances, err := t.adminClient.GetBrokersInMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+	if len(maintenances) == 0 {
+		return nil
+	}
+
+	maintenanceIDs := admin.MaintenanceBrokerIDs(maintenances)
+	placementConfig := &t.topicConfig.Spec.PlacementConfig
+
+	if placementConfig.Strategy == config.PlacementStrategyStatic {
+		log.Warnf(
+			"Broker(s) %+v are in maintenance, but leaders for static placements are not changed",
+			maintenanceIDs,
+		)
+		return nil
+	}
+
+	preferredLeaders := placementConfig.PreferredLeaderBrokers
+	if len(preferredLeaders) == 0 {
+		preferredLeaders = admin.BrokerIDs(t.placementBrokers)
+	}
+	preferredLeaders = MaintenancePreferredLeaders(preferredLeaders, maintenanceIDs)
+
+	if len(preferredLeaders) == 0 {
+		log.Warnf(
+			"All of the preferred leaders for this topic are in maintenance (%+v); ignoring maintenance",
+			maintenanceIDs,
+		)
+		return nil
+	}
+
+	log.Infof(
+		"Broker(s) %+v are in maintenance; using %+v as the preferred leaders",
+		maintenanceIDs,
+		preferredLeaders,
+	)
+	placementConfig.PreferredLeaderBrokers = preferredLeaders
+
+	return nil
+}
+
 func (t *TopicApplier) getPicker(ctx context.Context) (pickers.Picker, error) {
 	var picker pickers.Picker
 
@@ -1304,7 +2760,7 @@ func (t *TopicApplier) getPicker(ctx context.Context) (pickers.Picker, error) {
 			}
 		}
 
-		picker = pickers.NewClusterUsePicker(t.brokers, nonAppliedTopics)
+		picker = pickers.NewClusterUsePicker(t.placementBrokers, nonAppliedTopics)
 	case config.PickerMethodLowestIndex:
 		picker = pickers.NewLowestIndexPicker()
 	case config.PickerMethodRandomized:
@@ -1332,3 +2788,59 @@ func interruptableSleep(ctx context.Context, duration time.Duration) error {
 		return nil
 	}
 }
+
+// isRetentionReduction returns whether changing a retention setting from the argument
+// current value to the new value would reduce retention. A value of -1 means that
+// retention is unlimited.
+func isRetentionReduction(currValueStr string, newValueStr string) (bool, error) {
+	currValue, err := strconv.ParseInt(currValueStr, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	newValue, err := strconv.ParseInt(newValueStr, 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	if newValue < 0 {
+		return false, nil
+	} else if currValue < 0 {
+		return true, nil
+	}
+	return newValue < currValue, nil
+}
+
+// partitionSteps returns the intermediate and final partition counts for expanding a topic
+// from currPartitions to desiredPartitions in increments of stepSize.
+func partitionSteps(currPartitions int, desiredPartitions int, stepSize int) []int {
+	steps := []int{}
+
+	for count := currPartitions + stepSize; count < desiredPartitions; count += stepSize {
+		steps = append(steps, count)
+	}
+	if currPartitions < desiredPartitions {
+		steps = append(steps, desiredPartitions)
+	}
+
+	return steps
+}
+
+// formatBrokerRates returns a compact, broker-ordered representation of the argument
+// per-broker bytes-in rates for logging.
+func formatBrokerRates(rates map[int]float64) string {
+	brokerIDs := []int{}
+	for brokerID := range rates {
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+	sort.Ints(brokerIDs)
+
+	elements := []string{}
+	for _, brokerID := range brokerIDs {
+		elements = append(
+			elements,
+			fmt.Sprintf("%d=%s/s", brokerID, util.PrettyBytes(int64(rates[brokerID]))),
+		)
+	}
+
+	return strings.Join(elements, ", ")
+}
@@ -61,21 +61,43 @@ func TestApplyBasicUpdates(t *testing.T) {
 	assert.Equal(t, "30000000", topicInfo.Config[admin.RetentionKey])
 	assert.Equal(t, "compact", topicInfo.Config["cleanup.policy"])
 
-	// Update retention and settings
+	// Changing the cleanup policy is blocked unless it's explicitly allowed
 	applier.topicConfig.Spec.RetentionMinutes = 501
 	applier.topicConfig.Spec.Settings["cleanup.policy"] = "delete"
 	err = applier.Apply(ctx)
+	require.NotNil(t, err)
+	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
+	require.Nil(t, err)
+	assert.Equal(t, "compact", topicInfo.Config["cleanup.policy"])
+
+	// Update retention and settings
+	applier.config.AllowCleanupPolicyChange = true
+	err = applier.Apply(ctx)
 	require.Nil(t, err)
 	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
 	require.Nil(t, err)
 	assert.Equal(t, "30060000", topicInfo.Config[admin.RetentionKey])
 	assert.Equal(t, "delete", topicInfo.Config["cleanup.policy"])
 
-	// Updating replication factor not allowed
-	applier.topicConfig.Spec.Partitions = 9
+	// Increase replication factor
 	applier.topicConfig.Spec.ReplicationFactor = 3
 	err = applier.Apply(ctx)
-	require.NotNil(t, err)
+	require.Nil(t, err)
+	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
+	require.Nil(t, err)
+	for _, partition := range topicInfo.Partitions {
+		assert.Equal(t, 3, len(partition.Replicas))
+	}
+
+	// Decrease replication factor
+	applier.topicConfig.Spec.ReplicationFactor = 2
+	err = applier.Apply(ctx)
+	require.Nil(t, err)
+	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
+	require.Nil(t, err)
+	for _, partition := range topicInfo.Partitions {
+		assert.Equal(t, 2, len(partition.Replicas))
+	}
 }
 
 func TestApplyPlacementUpdates(t *testing.T) {
@@ -358,6 +380,24 @@ func TestApplyExtendPartitions(t *testing.T) {
 	// No throttles on brokers or topic
 	assert.Equal(t, 0, len(admin.ThrottledBrokerIDs(brokers)))
 	assert.False(t, topicInfo.IsThrottled())
+
+	// Extending a keyed topic requires repartitioning to be allowed
+	applier.topicConfig.Spec.Keyed = true
+	applier.topicConfig.Spec.Partitions = 7
+	err = applier.Apply(ctx)
+	require.NotNil(t, err)
+
+	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
+	require.Nil(t, err)
+	assert.Equal(t, 6, len(topicInfo.Partitions))
+
+	applier.config.AllowRepartitioning = true
+	err = applier.Apply(ctx)
+	require.Nil(t, err)
+
+	topicInfo, err = applier.adminClient.GetTopic(ctx, topicName, true)
+	require.Nil(t, err)
+	assert.Equal(t, 7, len(topicInfo.Partitions))
 }
 
 func TestApplyExistingThrottles(t *testing.T) {
@@ -586,9 +626,25 @@ func TestApplyDryRun(t *testing.T) {
 	applier.topicConfig.Spec.PlacementConfig.Strategy = config.PlacementStrategyInRack
 
 	applier.config.DryRun = true
+	applier.changes = &TopicChanges{}
 	err = applier.Apply(ctx)
 	require.Nil(t, err)
 
+	// Changes recorded as planned
+	assert.False(t, applier.changes.Created)
+	assert.Equal(
+		t,
+		[]ConfigChange{
+			{
+				Key:      admin.RetentionKey,
+				OldValue: "30000000",
+				NewValue: "36000000",
+			},
+		},
+		applier.changes.ConfigChanges,
+	)
+	assert.Equal(t, 3, len(applier.changes.AddedPartitions))
+
 	// Changes not made
 	updatedTopic, err := applier.adminClient.GetTopic(ctx, topicName, false)
 	require.Nil(t, err)
@@ -916,3 +972,63 @@ func testApplier(
 	require.Nil(t, err)
 	return applier
 }
+
+func TestIsRetentionReduction(t *testing.T) {
+	type testCase struct {
+		description string
+		currValue   string
+		newValue    string
+		expReduced  bool
+		expErr      bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "increase",
+			currValue:   "3600000",
+			newValue:    "7200000",
+			expReduced:  false,
+		},
+		{
+			description: "decrease",
+			currValue:   "7200000",
+			newValue:    "3600000",
+			expReduced:  true,
+		},
+		{
+			description: "unlimited to limited",
+			currValue:   "-1",
+			newValue:    "3600000",
+			expReduced:  true,
+		},
+		{
+			description: "limited to unlimited",
+			currValue:   "3600000",
+			newValue:    "-1",
+			expReduced:  false,
+		},
+		{
+			description: "bad value",
+			currValue:   "3600000",
+			newValue:    "1h",
+			expErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		reduced, err := isRetentionReduction(testCase.currValue, testCase.newValue)
+		if testCase.expErr {
+			assert.NotNil(t, err, testCase.description)
+		} else {
+			assert.Nil(t, err, testCase.description)
+			assert.Equal(t, testCase.expReduced, reduced, testCase.description)
+		}
+	}
+}
+
+func TestPartitionSteps(t *testing.T) {
+	assert.Equal(t, []int{48, 64}, partitionSteps(32, 64, 16))
+	assert.Equal(t, []int{40, 50, 55}, partitionSteps(30, 55, 10))
+	assert.Equal(t, []int{10}, partitionSteps(5, 10, 20))
+	assert.Equal(t, []int{}, partitionSteps(10, 10, 5))
+}
@@ -0,0 +1,131 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ApprovalCategory is a string type that groups the changes made by apply so that some of
+// them can be approved automatically, e.g. when running in CI.
+type ApprovalCategory string
+
+const (
+	// ApprovalCategoryCreate covers the creation of topics and connectors.
+	ApprovalCategoryCreate ApprovalCategory = "create"
+
+	// ApprovalCategoryConfig covers updates to topic, broker, and connector settings, schema
+	// compatibility levels, and partition count increases.
+	ApprovalCategoryConfig ApprovalCategory = "config"
+
+	// ApprovalCategoryReassign covers replica reassignments, leader elections, and the
+	// throttles and quota changes that go along with them.
+	ApprovalCategoryReassign ApprovalCategory = "reassign"
+
+	// ApprovalCategoryDelete covers the deletion of retired topics and the blocking of
+	// produce requests to retiring ones. It's destructive, so it's only auto-approved if
+	// explicitly listed.
+	ApprovalCategoryDelete ApprovalCategory = "delete"
+)
+
+// AllApprovalCategories contains all of the valid approval categories.
+var AllApprovalCategories = []ApprovalCategory{
+	ApprovalCategoryCreate,
+	ApprovalCategoryConfig,
+	ApprovalCategoryReassign,
+	ApprovalCategoryDelete,
+}
+
+// DefaultAutoApproveCategories are the categories that are auto-approved if auto-approval
+// is turned on without listing any categories. The destructive ones aren't included.
+var DefaultAutoApproveCategories = []ApprovalCategory{
+	ApprovalCategoryCreate,
+	ApprovalCategoryConfig,
+	ApprovalCategoryReassign,
+}
+
+// Confirmer decides whether a change in the argument category can be made. It's used in
+// place of the interactive prompts when topicctl is embedded in other tools.
+type Confirmer func(prompt string, category ApprovalCategory) (bool, error)
+
+// ApproveAll is a Confirmer that approves every change.
+func ApproveAll(prompt string, category ApprovalCategory) (bool, error) {
+	return true, nil
+}
+
+// ParseApprovalCategories converts the argument strings into approval categories, returning
+// an error if any of them aren't valid.
+func ParseApprovalCategories(values []string) ([]ApprovalCategory, error) {
+	categories := []ApprovalCategory{}
+
+	for _, value := range values {
+		category := ApprovalCategory(strings.TrimSpace(strings.ToLower(value)))
+		if !containsApprovalCategory(AllApprovalCategories, category) {
+			return nil, fmt.Errorf(
+				"Invalid approval category %s; must be one of %+v",
+				value,
+				AllApprovalCategories,
+			)
+		}
+		if !containsApprovalCategory(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories, nil
+}
+
+// confirmChange is like Confirm, but also answers yes automatically if the argument
+// category is one of the auto-approved ones. Changes in other categories still need a
+// response from the user, even if auto-approval is on for some categories.
+func confirmChange(
+	prompt string,
+	category ApprovalCategory,
+	skip bool,
+	autoApprove []ApprovalCategory,
+) (bool, error) {
+	if !skip && len(autoApprove) > 0 {
+		if containsApprovalCategory(autoApprove, category) {
+			fmt.Printf("%s (yes/no) ", prompt)
+			log.Infof(
+				"Automatically answering yes because %s changes are auto-approved",
+				category,
+			)
+			return true, nil
+		}
+
+		log.Infof(
+			"Changes in the %s category aren't auto-approved; waiting for a response",
+			category,
+		)
+	}
+
+	return Confirm(prompt, skip)
+}
+
+func containsApprovalCategory(
+	categories []ApprovalCategory,
+	category ApprovalCategory,
+) bool {
+	for _, c := range categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks the user to confirm a change in the argument category, answering yes
+// automatically if confirmations are skipped or the category is auto-approved. If a
+// Confirmer is set in the config, it's used instead. Once a stop has been requested, it
+// returns ErrApplyStopped without asking.
+func (t *TopicApplier) confirm(prompt string, category ApprovalCategory) (bool, error) {
+	if err := t.checkStop(); err != nil {
+		return false, err
+	}
+	if t.config.Confirmer != nil {
+		return t.config.Confirmer(prompt, category)
+	}
+	return confirmChange(prompt, category, t.config.SkipConfirm, t.config.AutoApprove)
+}
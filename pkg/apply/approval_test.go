@@ -0,0 +1,54 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseApprovalCategories(t *testing.T) {
+	categories, err := ParseApprovalCategories([]string{"config", " Reassign", "config"})
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]ApprovalCategory{ApprovalCategoryConfig, ApprovalCategoryReassign},
+		categories,
+	)
+
+	categories, err = ParseApprovalCategories([]string{})
+	require.NoError(t, err)
+	assert.Equal(t, []ApprovalCategory{}, categories)
+
+	_, err = ParseApprovalCategories([]string{"create", "everything"})
+	assert.Error(t, err)
+}
+
+func TestConfirmChangeAutoApprove(t *testing.T) {
+	ok, err := confirmChange(
+		"OK to apply?",
+		ApprovalCategoryReassign,
+		false,
+		DefaultAutoApproveCategories,
+	)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = confirmChange(
+		"OK to delete topic?",
+		ApprovalCategoryDelete,
+		false,
+		[]ApprovalCategory{ApprovalCategoryDelete},
+	)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = confirmChange(
+		"OK to delete topic?",
+		ApprovalCategoryDelete,
+		true,
+		DefaultAutoApproveCategories,
+	)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
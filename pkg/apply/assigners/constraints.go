@@ -0,0 +1,185 @@
+package assigners
+
+import (
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// ConstrainedAssigner is an Assigner that wraps another one so that its results honor the
+// broker constraints in a topic's placement config. The algorithm used is:
+//
+//   for each replica on an excluded broker:
+//     use the picker to replace it with a non-excluded broker, preferring ones in the same
+//       rack so that rack-based strategies are still satisfiable
+//   run the wrapped assigner on the result
+//   for each partition whose leader isn't a preferred leader:
+//     if one of its followers is a preferred leader, swap it into the leader position
+//
+// The wrapped assigner should be created with the non-excluded brokers only (see
+// FilterBrokers) so that it doesn't move replicas back onto the excluded ones.
+type ConstrainedAssigner struct {
+	assigner               Assigner
+	brokerRacks            map[int]string
+	brokersPerRack         map[string][]int
+	allowedBrokerIDs       []int
+	excludeBrokers         []int
+	preferredLeaderBrokers []int
+	picker                 pickers.Picker
+}
+
+var _ Assigner = (*ConstrainedAssigner)(nil)
+
+// NewConstrainedAssigner creates and returns a ConstrainedAssigner instance. The brokers
+// argument should contain all of the brokers in the cluster, including the excluded ones.
+func NewConstrainedAssigner(
+	assigner Assigner,
+	brokers []admin.BrokerInfo,
+	picker pickers.Picker,
+	placementConfig config.TopicPlacementConfig,
+) *ConstrainedAssigner {
+	allowedBrokers := FilterBrokers(brokers, placementConfig.ExcludeBrokers)
+	allowedBrokerIDs := []int{}
+	for _, broker := range allowedBrokers {
+		allowedBrokerIDs = append(allowedBrokerIDs, broker.ID)
+	}
+
+	return &ConstrainedAssigner{
+		assigner:               assigner,
+		brokerRacks:            admin.BrokerRacks(brokers),
+		brokersPerRack:         admin.BrokersPerRack(allowedBrokers),
+		allowedBrokerIDs:       allowedBrokerIDs,
+		excludeBrokers:         placementConfig.ExcludeBrokers,
+		preferredLeaderBrokers: placementConfig.PreferredLeaderBrokers,
+		picker:                 picker,
+	}
+}
+
+// Assign returns a new partition assignment according to the assigner-specific logic.
+func (c *ConstrainedAssigner) Assign(
+	topic string,
+	curr []admin.PartitionAssignment,
+) ([]admin.PartitionAssignment, error) {
+	if err := admin.CheckAssignments(curr); err != nil {
+		return nil, err
+	}
+
+	desired := admin.CopyAssignments(curr)
+
+	for p, assignment := range desired {
+		for i, replica := range assignment.Replicas {
+			if !intInSlice(replica, c.excludeBrokers) {
+				continue
+			}
+
+			err := c.picker.PickNew(
+				topic,
+				c.brokersPerRack[c.brokerRacks[replica]],
+				desired,
+				p,
+				i,
+			)
+			if err == pickers.ErrNoFeasibleChoice {
+				// Fall back to brokers in any rack
+				err = c.picker.PickNew(topic, c.allowedBrokerIDs, desired, p, i)
+			}
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Could not replace excluded broker %d in partition %d: %+v",
+					replica,
+					p,
+					err,
+				)
+			}
+		}
+	}
+
+	desired, err := c.assigner.Assign(topic, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	return ApplyLeaderPreferences(desired, c.preferredLeaderBrokers), nil
+}
+
+// FilterBrokers returns the brokers that are not in the argument excluded list.
+func FilterBrokers(brokers []admin.BrokerInfo, excludeBrokers []int) []admin.BrokerInfo {
+	filtered := []admin.BrokerInfo{}
+
+	for _, broker := range brokers {
+		if !intInSlice(broker.ID, excludeBrokers) {
+			filtered = append(filtered, broker)
+		}
+	}
+
+	return filtered
+}
+
+// ApplyLeaderPreferences returns a copy of the argument assignments in which the leader of
+// each partition is a preferred leader whenever at least one of the partition's replicas is.
+// This only reorders replicas within each partition, so it doesn't require any data
+// movement.
+func ApplyLeaderPreferences(
+	assignments []admin.PartitionAssignment,
+	preferredLeaderBrokers []int,
+) []admin.PartitionAssignment {
+	updated := admin.CopyAssignments(assignments)
+	if len(preferredLeaderBrokers) == 0 {
+		return updated
+	}
+
+	for p, assignment := range updated {
+		if len(assignment.Replicas) == 0 ||
+			intInSlice(assignment.Replicas[0], preferredLeaderBrokers) {
+			continue
+		}
+
+		for r, replica := range assignment.Replicas[1:] {
+			if intInSlice(replica, preferredLeaderBrokers) {
+				updated[p].Replicas[0], updated[p].Replicas[r+1] =
+					replica, updated[p].Replicas[0]
+				break
+			}
+		}
+	}
+
+	return updated
+}
+
+// satisfiesConstraints returns whether the argument assignments honor the broker exclusions
+// and leader preferences in the argument placement config.
+func satisfiesConstraints(
+	assignments []admin.PartitionAssignment,
+	placementConfig config.TopicPlacementConfig,
+) bool {
+	for _, assignment := range assignments {
+		hasPreferred := false
+
+		for _, replica := range assignment.Replicas {
+			if intInSlice(replica, placementConfig.ExcludeBrokers) {
+				return false
+			}
+			if intInSlice(replica, placementConfig.PreferredLeaderBrokers) {
+				hasPreferred = true
+			}
+		}
+
+		if hasPreferred &&
+			!intInSlice(assignment.Replicas[0], placementConfig.PreferredLeaderBrokers) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func intInSlice(value int, values []int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package assigners
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstrainedAssignerBalancedLeaders(t *testing.T) {
+	brokers := testBrokers(6, 3)
+	placementConfig := config.TopicPlacementConfig{
+		Strategy:       config.PlacementStrategyBalancedLeaders,
+		ExcludeBrokers: []int{1},
+	}
+	picker := pickers.NewLowestIndexPicker()
+
+	assigner := NewConstrainedAssigner(
+		NewBalancedLeaderAssigner(FilterBrokers(brokers, placementConfig.ExcludeBrokers), picker),
+		brokers,
+		picker,
+		placementConfig,
+	)
+
+	testCases := []assignerTestCase{
+		{
+			description: "Already satisfied",
+			curr: [][]int{
+				{2, 3},
+				{3, 2},
+				{4, 5},
+			},
+			expected: [][]int{
+				{2, 3},
+				{3, 2},
+				{4, 5},
+			},
+		},
+		{
+			description: "Excluded broker replaced in same rack",
+			curr: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+			},
+			expected: [][]int{
+				{4, 2},
+				{2, 3},
+				{3, 4},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, assigner)
+	}
+}
+
+func TestConstrainedAssignerPreferredLeaders(t *testing.T) {
+	brokers := testBrokers(4, 2)
+	placementConfig := config.TopicPlacementConfig{
+		Strategy:               config.PlacementStrategyAny,
+		PreferredLeaderBrokers: []int{2, 3, 4},
+	}
+	picker := pickers.NewLowestIndexPicker()
+
+	assigner := NewConstrainedAssigner(
+		&StaticAssigner{
+			Assignments: admin.ReplicasToAssignments(
+				[][]int{
+					{1, 2},
+					{1, 3},
+					{4, 1},
+				},
+			),
+		},
+		brokers,
+		picker,
+		placementConfig,
+	)
+
+	testCases := []assignerTestCase{
+		{
+			description: "Leaders drained from broker 1",
+			curr: [][]int{
+				{1, 2},
+				{1, 3},
+				{4, 1},
+			},
+			expected: [][]int{
+				{2, 1},
+				{3, 1},
+				{4, 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, assigner)
+	}
+}
+
+func TestApplyLeaderPreferences(t *testing.T) {
+	assignments := admin.ReplicasToAssignments(
+		[][]int{
+			{1, 2, 3},
+			{1, 4, 5},
+			{2, 1, 3},
+		},
+	)
+
+	updated := ApplyLeaderPreferences(assignments, []int{3, 5})
+	replicas, err := admin.AssignmentsToReplicas(updated)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[][]int{
+			{3, 2, 1},
+			{5, 4, 1},
+			{3, 1, 2},
+		},
+		replicas,
+	)
+
+	// The input isn't modified
+	assert.Equal(t, 1, assignments[0].Replicas[0])
+}
+
+func TestEvaluateAssignmentsConstraints(t *testing.T) {
+	brokers := testBrokers(6, 3)
+
+	type evaluateTestCase struct {
+		description     string
+		replicaSlices   [][]int
+		placementConfig config.TopicPlacementConfig
+		expectedResult  bool
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			description: "No excluded brokers used",
+			replicaSlices: [][]int{
+				{2, 3},
+				{3, 4},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:       config.PlacementStrategyAny,
+				ExcludeBrokers: []int{1},
+			},
+			expectedResult: true,
+		},
+		{
+			description: "Excluded broker used",
+			replicaSlices: [][]int{
+				{2, 3},
+				{3, 1},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:       config.PlacementStrategyAny,
+				ExcludeBrokers: []int{1},
+			},
+			expectedResult: false,
+		},
+		{
+			description: "Preferred leaders honored",
+			replicaSlices: [][]int{
+				{2, 1},
+				{3, 1},
+				{1, 4},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:               config.PlacementStrategyAny,
+				PreferredLeaderBrokers: []int{2, 3},
+			},
+			expectedResult: true,
+		},
+		{
+			description: "Preferred leaders not honored",
+			replicaSlices: [][]int{
+				{2, 1},
+				{1, 3},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:               config.PlacementStrategyAny,
+				PreferredLeaderBrokers: []int{2, 3},
+			},
+			expectedResult: false,
+		},
+		{
+			description: "Preferred leaders take precedence over leader balance",
+			replicaSlices: [][]int{
+				{2, 1},
+				{2, 3},
+				{5, 3},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:               config.PlacementStrategyBalancedLeaders,
+				PreferredLeaderBrokers: []int{2, 5},
+			},
+			expectedResult: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := EvaluateAssignments(
+			admin.ReplicasToAssignments(testCase.replicaSlices),
+			brokers,
+			testCase.placementConfig,
+		)
+		assert.Nil(t, err, testCase.description)
+		assert.Equal(t, testCase.expectedResult, result, testCase.description)
+	}
+}
@@ -19,8 +19,15 @@ func EvaluateAssignments(
 		return false, err
 	}
 
+	if !satisfiesConstraints(assignments, placementConfig) {
+		return false, nil
+	}
+
 	minRacks, maxRacks, leaderRackCounts := minMaxRacks(assignments, brokers)
-	balanced := balancedLeaders(leaderRackCounts)
+
+	// Leader preferences take precedence over leader balance
+	balanced := len(placementConfig.PreferredLeaderBrokers) > 0 ||
+		balancedLeaders(leaderRackCounts)
 
 	switch placementConfig.Strategy {
 	case config.PlacementStrategyAny:
@@ -55,6 +62,23 @@ func EvaluateAssignments(
 		return true, nil
 	case config.PlacementStrategyBalancedLeaders:
 		return balanced, nil
+	case config.PlacementStrategyBalancedTopicSet:
+		if placementConfig.TopicSetAssignments == nil {
+			// This is the first topic in the set, so it just needs balanced leaders
+			return balanced, nil
+		}
+		if len(placementConfig.TopicSetAssignments) < len(assignments) {
+			return false, nil
+		}
+
+		replicas, err := admin.AssignmentsToReplicas(assignments)
+		if err != nil {
+			return false, err
+		}
+		return reflect.DeepEqual(
+			replicas,
+			placementConfig.TopicSetAssignments[:len(assignments)],
+		), nil
 	case config.PlacementStrategyInRack:
 		return minRacks == 1 && maxRacks == 1, nil
 	default:
@@ -171,3 +171,86 @@ func TestEvaluateAssignmentsNonStatic(t *testing.T) {
 		}
 	}
 }
+
+func TestEvaluateAssignmentsTopicSet(t *testing.T) {
+	brokers := testBrokers(6, 3)
+
+	type evaluateTestCase struct {
+		description         string
+		replicaSlices       [][]int
+		topicSetAssignments [][]int
+		expectedResult      bool
+	}
+
+	testCases := []evaluateTestCase{
+		{
+			description: "First in set with balanced leaders",
+			replicaSlices: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+			},
+			expectedResult: true,
+		},
+		{
+			description: "First in set without balanced leaders",
+			replicaSlices: [][]int{
+				{1, 2},
+				{1, 3},
+				{3, 1},
+			},
+			expectedResult: false,
+		},
+		{
+			description: "Matches set",
+			replicaSlices: [][]int{
+				{1, 2},
+				{2, 3},
+			},
+			topicSetAssignments: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+			},
+			expectedResult: true,
+		},
+		{
+			description: "Does not match set",
+			replicaSlices: [][]int{
+				{1, 2},
+				{3, 2},
+			},
+			topicSetAssignments: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+			},
+			expectedResult: false,
+		},
+		{
+			description: "Set has fewer partitions",
+			replicaSlices: [][]int{
+				{1, 2},
+				{2, 3},
+			},
+			topicSetAssignments: [][]int{
+				{1, 2},
+			},
+			expectedResult: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := EvaluateAssignments(
+			admin.ReplicasToAssignments(testCase.replicaSlices),
+			brokers,
+			config.TopicPlacementConfig{
+				Strategy:            config.PlacementStrategyBalancedTopicSet,
+				TopicSet:            "test-set",
+				TopicSetAssignments: testCase.topicSetAssignments,
+			},
+		)
+		assert.Nil(t, err, testCase.description)
+		assert.Equal(t, testCase.expectedResult, result, testCase.description)
+	}
+}
@@ -0,0 +1,256 @@
+package assigners
+
+import (
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// scoreEpsilon is the minimum difference between two goal penalties for them to be
+// considered different. This prevents floating point noise from being treated as an
+// improvement.
+const scoreEpsilon = 1e-9
+
+// Goal is an interface for structs that measure how well a set of partition assignments
+// achieves some objective, e.g. an even distribution of leaders across brokers. Goals
+// can be combined, in priority order, to decide between candidate assignments.
+type Goal interface {
+	// Name returns the name of the goal.
+	Name() string
+
+	// Penalty returns how far the argument assignments are from achieving the goal, given
+	// the brokers that the replicas can be placed on. Lower values are better.
+	Penalty(assignments []admin.PartitionAssignment, brokers []admin.BrokerInfo) float64
+}
+
+// RackDistributionGoal is a Goal that spreads the replicas for each partition across as
+// many racks as possible. The penalty is the total number of replicas, across all
+// partitions, that share a rack with another replica in the same partition when they
+// didn't need to.
+type RackDistributionGoal struct{}
+
+var _ Goal = (*RackDistributionGoal)(nil)
+
+// Name returns the name of the goal.
+func (g *RackDistributionGoal) Name() string {
+	return string(config.RebalanceGoalRackDistribution)
+}
+
+// Penalty returns the penalty for the argument assignments.
+func (g *RackDistributionGoal) Penalty(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+) float64 {
+	brokerRacks := admin.BrokerRacks(brokers)
+	numRacks := len(admin.DistinctRacks(brokers))
+	penalty := 0
+
+	for _, assignment := range assignments {
+		maxRacks := len(assignment.Replicas)
+		if numRacks < maxRacks {
+			maxRacks = numRacks
+		}
+		penalty += maxRacks - len(assignment.DistinctRacks(brokerRacks))
+	}
+
+	return float64(penalty)
+}
+
+// ReplicaBalanceGoal is a Goal that evens out the number of replicas on each broker. The
+// penalty is the sum of the squared differences between each broker's replica count and
+// the mean.
+type ReplicaBalanceGoal struct{}
+
+var _ Goal = (*ReplicaBalanceGoal)(nil)
+
+// Name returns the name of the goal.
+func (g *ReplicaBalanceGoal) Name() string {
+	return string(config.RebalanceGoalReplicaBalance)
+}
+
+// Penalty returns the penalty for the argument assignments.
+func (g *ReplicaBalanceGoal) Penalty(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+) float64 {
+	counts := brokerValues(brokers)
+
+	for _, assignment := range assignments {
+		for _, replica := range assignment.Replicas {
+			if _, ok := counts[replica]; ok {
+				counts[replica]++
+			}
+		}
+	}
+
+	return squaredDeviations(counts)
+}
+
+// LeaderBalanceGoal is a Goal that evens out the number of partition leaders (i.e., the
+// first replica in each partition) on each broker. The penalty is the sum of the squared
+// differences between each broker's leader count and the mean.
+type LeaderBalanceGoal struct{}
+
+var _ Goal = (*LeaderBalanceGoal)(nil)
+
+// Name returns the name of the goal.
+func (g *LeaderBalanceGoal) Name() string {
+	return string(config.RebalanceGoalLeaderBalance)
+}
+
+// Penalty returns the penalty for the argument assignments.
+func (g *LeaderBalanceGoal) Penalty(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+) float64 {
+	counts := brokerValues(brokers)
+
+	for _, assignment := range assignments {
+		if len(assignment.Replicas) == 0 {
+			continue
+		}
+		if _, ok := counts[assignment.Replicas[0]]; ok {
+			counts[assignment.Replicas[0]]++
+		}
+	}
+
+	return squaredDeviations(counts)
+}
+
+// DiskBalanceGoal is a Goal that evens out the amount of data stored on each broker. The
+// penalty is the sum of the squared differences between each broker's total partition
+// size and the mean, divided by the squared mean so that it doesn't depend on the units
+// of the sizes.
+type DiskBalanceGoal struct {
+	partitionSizes map[int]float64
+}
+
+var _ Goal = (*DiskBalanceGoal)(nil)
+
+// NewDiskBalanceGoal creates a new DiskBalanceGoal instance. The partitionSizes map
+// contains the relative size of each partition; if it's nil, then all partitions are
+// assumed to be the same size.
+func NewDiskBalanceGoal(partitionSizes map[int]float64) *DiskBalanceGoal {
+	return &DiskBalanceGoal{
+		partitionSizes: partitionSizes,
+	}
+}
+
+// Name returns the name of the goal.
+func (g *DiskBalanceGoal) Name() string {
+	return string(config.RebalanceGoalDiskBalance)
+}
+
+// Penalty returns the penalty for the argument assignments.
+func (g *DiskBalanceGoal) Penalty(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+) float64 {
+	sizes := brokerValues(brokers)
+	total := 0.0
+
+	for _, assignment := range assignments {
+		size := 1.0
+		if g.partitionSizes != nil {
+			size = g.partitionSizes[assignment.ID]
+		}
+
+		for _, replica := range assignment.Replicas {
+			if _, ok := sizes[replica]; ok {
+				sizes[replica] += size
+				total += size
+			}
+		}
+	}
+
+	if len(sizes) == 0 || total == 0 {
+		return 0.0
+	}
+
+	mean := total / float64(len(sizes))
+	return squaredDeviations(sizes) / (mean * mean)
+}
+
+// GoalsFromConfig returns the goals for the argument names, in the same order. The
+// partitionSizes map is used for the disk balance goal; see NewDiskBalanceGoal for details.
+func GoalsFromConfig(
+	goalNames []config.RebalanceGoal,
+	partitionSizes map[int]float64,
+) ([]Goal, error) {
+	goals := []Goal{}
+
+	for _, goalName := range goalNames {
+		switch goalName {
+		case config.RebalanceGoalRackDistribution:
+			goals = append(goals, &RackDistributionGoal{})
+		case config.RebalanceGoalReplicaBalance:
+			goals = append(goals, &ReplicaBalanceGoal{})
+		case config.RebalanceGoalLeaderBalance:
+			goals = append(goals, &LeaderBalanceGoal{})
+		case config.RebalanceGoalDiskBalance:
+			goals = append(goals, NewDiskBalanceGoal(partitionSizes))
+		default:
+			return nil, fmt.Errorf("Unrecognized rebalance goal: %s", goalName)
+		}
+	}
+
+	return goals, nil
+}
+
+// ScoreAssignments returns the penalty for each of the argument goals, in the same order.
+func ScoreAssignments(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+	goals []Goal,
+) []float64 {
+	scores := []float64{}
+
+	for _, goal := range goals {
+		scores = append(scores, goal.Penalty(assignments, brokers))
+	}
+
+	return scores
+}
+
+// CompareScores compares two sets of goal penalties, as returned by ScoreAssignments, in
+// priority order. It returns -1 if the first is better (i.e., it has a lower penalty for the
+// first goal where the two differ), 1 if the second is better, and 0 if they're the same.
+func CompareScores(scores1 []float64, scores2 []float64) int {
+	for s := 0; s < len(scores1) && s < len(scores2); s++ {
+		if scores1[s] < scores2[s]-scoreEpsilon {
+			return -1
+		} else if scores1[s] > scores2[s]+scoreEpsilon {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func brokerValues(brokers []admin.BrokerInfo) map[int]float64 {
+	values := map[int]float64{}
+	for _, broker := range brokers {
+		values[broker.ID] = 0
+	}
+	return values
+}
+
+func squaredDeviations(values map[int]float64) float64 {
+	if len(values) == 0 {
+		return 0.0
+	}
+
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	mean := total / float64(len(values))
+
+	deviations := 0.0
+	for _, value := range values {
+		deviations += (value - mean) * (value - mean)
+	}
+
+	return deviations
+}
@@ -0,0 +1,142 @@
+package assigners
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalPenalties(t *testing.T) {
+	brokers := testBrokers(4, 2)
+
+	type goalTestCase struct {
+		description string
+		goal        Goal
+		replicas    [][]int
+		expected    float64
+	}
+
+	testCases := []goalTestCase{
+		{
+			description: "Racks spread",
+			goal:        &RackDistributionGoal{},
+			replicas: [][]int{
+				{1, 2},
+				{3, 4},
+			},
+			expected: 0.0,
+		},
+		{
+			description: "Racks not spread",
+			goal:        &RackDistributionGoal{},
+			replicas: [][]int{
+				{1, 3},
+				{2, 4},
+				{1, 2},
+			},
+			expected: 2.0,
+		},
+		{
+			description: "Replicas balanced",
+			goal:        &ReplicaBalanceGoal{},
+			replicas: [][]int{
+				{1, 2},
+				{3, 4},
+			},
+			expected: 0.0,
+		},
+		{
+			description: "Replicas not balanced",
+			goal:        &ReplicaBalanceGoal{},
+			replicas: [][]int{
+				{1, 2},
+				{1, 3},
+			},
+			// Counts are 2, 1, 1, 0
+			expected: 2.0,
+		},
+		{
+			description: "Leaders balanced",
+			goal:        &LeaderBalanceGoal{},
+			replicas: [][]int{
+				{1, 2},
+				{2, 1},
+				{3, 4},
+				{4, 3},
+			},
+			expected: 0.0,
+		},
+		{
+			description: "Leaders not balanced",
+			goal:        &LeaderBalanceGoal{},
+			replicas: [][]int{
+				{1, 2},
+				{1, 3},
+				{3, 4},
+				{4, 3},
+			},
+			// Counts are 2, 0, 1, 1
+			expected: 2.0,
+		},
+		{
+			description: "Disk balanced with sizes",
+			goal:        NewDiskBalanceGoal(map[int]float64{0: 30.0, 1: 10.0, 2: 10.0, 3: 10.0}),
+			replicas: [][]int{
+				{1},
+				{2},
+				{2},
+				{2},
+			},
+			// Sizes are 30, 30, 0, 0 with a mean of 15
+			expected: 4.0,
+		},
+		{
+			description: "Disk without sizes",
+			goal:        NewDiskBalanceGoal(nil),
+			replicas: [][]int{
+				{1},
+				{2},
+				{3},
+				{4},
+			},
+			expected: 0.0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.InDelta(
+			t,
+			testCase.expected,
+			testCase.goal.Penalty(admin.ReplicasToAssignments(testCase.replicas), brokers),
+			1e-6,
+			testCase.description,
+		)
+	}
+}
+
+func TestGoalsFromConfig(t *testing.T) {
+	goals, err := GoalsFromConfig(
+		[]config.RebalanceGoal{
+			config.RebalanceGoalLeaderBalance,
+			config.RebalanceGoalRackDistribution,
+		},
+		nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(goals))
+	assert.Equal(t, "leader-balance", goals[0].Name())
+	assert.Equal(t, "rack-distribution", goals[1].Name())
+
+	_, err = GoalsFromConfig([]config.RebalanceGoal{"bad-goal"}, nil)
+	assert.Error(t, err)
+}
+
+func TestCompareScores(t *testing.T) {
+	assert.Equal(t, 0, CompareScores([]float64{1.0, 2.0}, []float64{1.0, 2.0}))
+	assert.Equal(t, -1, CompareScores([]float64{1.0, 5.0}, []float64{2.0, 0.0}))
+	assert.Equal(t, 1, CompareScores([]float64{1.0, 2.0}, []float64{1.0, 1.0}))
+	assert.Equal(t, 0, CompareScores([]float64{1.0}, []float64{1.0 + 1e-12}))
+}
@@ -0,0 +1,159 @@
+package assigners
+
+import (
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+)
+
+// RackSpreadAssigner is an Assigner that fixes rack violations, i.e. partitions whose
+// replicas aren't spread across the expected number of racks. The algorithm is:
+//
+//   for each partition with the wrong number of distinct racks:
+//     for each follower, starting from the last one:
+//       if there are too few racks and the follower shares a rack with another replica:
+//         use the picker to replace it with a broker in a rack that isn't used yet
+//       if there are too many racks and the follower is the only replica in its rack:
+//         use the picker to replace it with a broker in a rack that's already used
+//
+// Replicas on brokers that aren't in the cluster anymore are always replaced. Leaders are
+// never changed, so this doesn't affect leader balance.
+type RackSpreadAssigner struct {
+	brokers        []admin.BrokerInfo
+	brokerRacks    map[int]string
+	brokersPerRack map[string][]int
+	expectedRacks  int
+	picker         pickers.Picker
+}
+
+var _ Assigner = (*RackSpreadAssigner)(nil)
+
+// NewRackSpreadAssigner creates and returns a RackSpreadAssigner instance. See
+// admin.TopicInfo.RackViolations for the meaning of expectedRacks.
+func NewRackSpreadAssigner(
+	brokers []admin.BrokerInfo,
+	picker pickers.Picker,
+	expectedRacks int,
+) *RackSpreadAssigner {
+	return &RackSpreadAssigner{
+		brokers:        brokers,
+		brokerRacks:    admin.BrokerRacks(brokers),
+		brokersPerRack: admin.BrokersPerRack(brokers),
+		expectedRacks:  expectedRacks,
+		picker:         picker,
+	}
+}
+
+// Assign returns a new partition assignment according to the assigner-specific logic.
+func (r *RackSpreadAssigner) Assign(
+	topic string,
+	curr []admin.PartitionAssignment,
+) ([]admin.PartitionAssignment, error) {
+	if err := admin.CheckAssignments(curr); err != nil {
+		return nil, err
+	}
+
+	desired := admin.CopyAssignments(curr)
+	numRacks := len(r.brokersPerRack)
+
+	for p, assignment := range desired {
+		expected := admin.ExpectedPartitionRacks(
+			len(assignment.Replicas),
+			numRacks,
+			r.expectedRacks,
+		)
+
+		for i := len(assignment.Replicas) - 1; i > 0; i-- {
+			replica := assignment.Replicas[i]
+			replicaRack, known := r.brokerRacks[replica]
+			otherRacks := r.otherRacks(assignment, i)
+
+			numDistinct := len(otherRacks)
+			if known && !otherRacks[replicaRack] {
+				numDistinct++
+			}
+
+			var err error
+
+			switch {
+			case !known && len(otherRacks) < expected,
+				known && numDistinct < expected && otherRacks[replicaRack]:
+				err = r.picker.PickNew(
+					topic,
+					r.rackBrokers(func(rack string) bool { return !otherRacks[rack] }),
+					desired,
+					p,
+					i,
+				)
+			case !known,
+				known && numDistinct > expected && !otherRacks[replicaRack]:
+				// Prefer the leader's rack, since that's always one of the racks that's kept
+				leaderRack := r.brokerRacks[assignment.Replicas[0]]
+				err = r.picker.PickNew(topic, r.brokersPerRack[leaderRack], desired, p, i)
+				if err == pickers.ErrNoFeasibleChoice {
+					err = r.picker.PickNew(
+						topic,
+						r.rackBrokers(func(rack string) bool { return otherRacks[rack] }),
+						desired,
+						p,
+						i,
+					)
+				}
+			default:
+				continue
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Could not fix rack violation for partition %d: %+v",
+					assignment.ID,
+					err,
+				)
+			}
+		}
+
+		if len(r.otherRacks(desired[p], -1)) != expected {
+			return nil, fmt.Errorf(
+				"Could not spread partition %d across %d racks",
+				assignment.ID,
+				expected,
+			)
+		}
+	}
+
+	return desired, nil
+}
+
+// rackBrokers returns the IDs of the brokers in the racks that match the argument filter.
+func (r *RackSpreadAssigner) rackBrokers(filter func(rack string) bool) []int {
+	brokerIDs := []int{}
+
+	for _, rack := range admin.DistinctRacks(r.brokers) {
+		if filter(rack) {
+			brokerIDs = append(brokerIDs, r.brokersPerRack[rack]...)
+		}
+	}
+
+	return brokerIDs
+}
+
+// otherRacks returns the racks of all of the replicas in the argument assignment except for
+// the one at the argument index.
+func (r *RackSpreadAssigner) otherRacks(
+	assignment admin.PartitionAssignment,
+	index int,
+) map[string]bool {
+	racks := map[string]bool{}
+
+	for i, replica := range assignment.Replicas {
+		if i == index {
+			continue
+		}
+		if rack, ok := r.brokerRacks[replica]; ok {
+			racks[rack] = true
+		}
+	}
+
+	return racks
+}
@@ -0,0 +1,112 @@
+package assigners
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+)
+
+func TestRackSpreadAssigner(t *testing.T) {
+	// Brokers 1-9 alternate between zone1, zone2, and zone3
+	brokers := testBrokers(9, 3)
+
+	type testCase struct {
+		assignerTestCase
+		expectedRacks int
+	}
+
+	testCases := []testCase{
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Already spread across all racks",
+				curr: [][]int{
+					{1, 2, 3},
+					{2, 3, 4},
+				},
+				expected: [][]int{
+					{1, 2, 3},
+					{2, 3, 4},
+				},
+			},
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Too few racks",
+				curr: [][]int{
+					{1, 4, 2},
+					{2, 5, 3},
+					{3, 6, 1},
+				},
+				expected: [][]int{
+					{1, 4, 2},
+					{2, 5, 3},
+					{3, 6, 1},
+				},
+			},
+			expectedRacks: 2,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Replica on removed broker",
+				curr: [][]int{
+					{1, 2, 10},
+					{2, 3, 1},
+				},
+				expected: [][]int{
+					{1, 2, 3},
+					{2, 3, 1},
+				},
+			},
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Too few racks, fixed",
+				curr: [][]int{
+					{1, 4, 2},
+					{2, 5, 6},
+				},
+				expected: [][]int{
+					{1, 3, 2},
+					{2, 1, 6},
+				},
+			},
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Too many racks",
+				curr: [][]int{
+					{1, 2, 4},
+					{2, 3, 1},
+				},
+				expected: [][]int{
+					{1, 2, 4},
+					{2, 3, 5},
+				},
+			},
+			expectedRacks: 2,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Single rack",
+				curr: [][]int{
+					{1, 2, 3},
+				},
+				expected: [][]int{
+					{1, 7, 4},
+				},
+			},
+			expectedRacks: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(
+			t,
+			NewRackSpreadAssigner(
+				brokers,
+				pickers.NewLowestIndexPicker(),
+				testCase.expectedRacks,
+			),
+		)
+	}
+}
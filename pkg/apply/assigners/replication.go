@@ -0,0 +1,186 @@
+package assigners
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+)
+
+// ReplicationAssigner is an assigner that changes the number of replicas in each partition
+// of a topic. The algorithm is:
+//
+// if increasing:
+//   for each partition:
+//     add placeholders (-1) for the new replicas
+//   for each partition:
+//     for each placeholder:
+//       if inRack, choose the leader's rack; otherwise, choose the rack with the fewest
+//         replicas of the partition so far
+//       use picker to replace the placeholder with a broker in the chosen rack
+//
+// if decreasing:
+//   for each partition:
+//     until the partition has the desired number of replicas:
+//       remove the last follower in the rack with the most replicas of the partition
+//
+// Note that this assigner never changes the leader of a partition. The results may not
+// satisfy the topic's placement strategy, so the placement should be updated afterwards.
+type ReplicationAssigner struct {
+	brokers           []admin.BrokerInfo
+	replicationFactor int
+	inRack            bool
+	racks             []string
+	brokerRacks       map[int]string
+	brokersPerRack    map[string][]int
+	picker            pickers.Picker
+}
+
+var _ Assigner = (*ReplicationAssigner)(nil)
+
+// NewReplicationAssigner creates and returns a ReplicationAssigner instance. If inRack is
+// true, then new replicas are added in the same rack as the leader of each partition.
+func NewReplicationAssigner(
+	brokers []admin.BrokerInfo,
+	replicationFactor int,
+	inRack bool,
+	picker pickers.Picker,
+) *ReplicationAssigner {
+	return &ReplicationAssigner{
+		brokers:           brokers,
+		replicationFactor: replicationFactor,
+		inRack:            inRack,
+		racks:             admin.DistinctRacks(brokers),
+		brokerRacks:       admin.BrokerRacks(brokers),
+		brokersPerRack:    admin.BrokersPerRack(brokers),
+		picker:            picker,
+	}
+}
+
+// Assign returns a new partition assignment according to the assigner-specific logic.
+func (r *ReplicationAssigner) Assign(
+	topic string,
+	curr []admin.PartitionAssignment,
+) ([]admin.PartitionAssignment, error) {
+	if err := admin.CheckAssignments(curr); err != nil {
+		return nil, err
+	}
+	if r.replicationFactor <= 0 {
+		return nil, errors.New("Replication factor must be > 0")
+	}
+	if r.replicationFactor > len(r.brokers) {
+		return nil, fmt.Errorf(
+			"Replication factor (%d) is greater than the number of brokers (%d)",
+			r.replicationFactor,
+			len(r.brokers),
+		)
+	}
+
+	desired := admin.CopyAssignments(curr)
+	currReplication := len(curr[0].Replicas)
+
+	if r.replicationFactor > currReplication {
+		for p := range desired {
+			for i := currReplication; i < r.replicationFactor; i++ {
+				desired[p].Replicas = append(desired[p].Replicas, -1)
+			}
+		}
+
+		for p := range desired {
+			for i := currReplication; i < r.replicationFactor; i++ {
+				if err := r.pickNew(topic, desired, p, i); err != nil {
+					return nil, err
+				}
+			}
+		}
+	} else {
+		for p := range desired {
+			for len(desired[p].Replicas) > r.replicationFactor {
+				desired[p].Replicas = r.removeReplica(desired[p].Replicas)
+			}
+		}
+	}
+
+	return desired, nil
+}
+
+func (r *ReplicationAssigner) pickNew(
+	topic string,
+	desired []admin.PartitionAssignment,
+	partition int,
+	index int,
+) error {
+	if r.inRack {
+		leaderRack := r.brokerRacks[desired[partition].Replicas[0]]
+		err := r.picker.PickNew(
+			topic,
+			r.brokersPerRack[leaderRack],
+			desired,
+			partition,
+			index,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"Could not add replica to partition %d in rack %s: %+v",
+				partition,
+				leaderRack,
+				err,
+			)
+		}
+		return nil
+	}
+
+	rackCounts := r.rackCounts(desired[partition].Replicas)
+
+	// Try the racks in order of how many replicas of the partition they already have
+	racks := make([]string, len(r.racks))
+	copy(racks, r.racks)
+	sort.SliceStable(racks, func(a, b int) bool {
+		return rackCounts[racks[a]] < rackCounts[racks[b]]
+	})
+
+	for _, rack := range racks {
+		err := r.picker.PickNew(
+			topic,
+			r.brokersPerRack[rack],
+			desired,
+			partition,
+			index,
+		)
+		if err == nil {
+			return nil
+		} else if err != pickers.ErrNoFeasibleChoice {
+			return err
+		}
+	}
+
+	return fmt.Errorf("Could not find a broker for new replica in partition %d", partition)
+}
+
+func (r *ReplicationAssigner) removeReplica(replicas []int) []int {
+	rackCounts := r.rackCounts(replicas)
+
+	toRemove := len(replicas) - 1
+	for i := len(replicas) - 1; i > 0; i-- {
+		if rackCounts[r.brokerRacks[replicas[i]]] >
+			rackCounts[r.brokerRacks[replicas[toRemove]]] {
+			toRemove = i
+		}
+	}
+
+	updated := []int{}
+	updated = append(updated, replicas[:toRemove]...)
+	return append(updated, replicas[toRemove+1:]...)
+}
+
+func (r *ReplicationAssigner) rackCounts(replicas []int) map[string]int {
+	counts := map[string]int{}
+	for _, replica := range replicas {
+		if replica >= 0 {
+			counts[r.brokerRacks[replica]]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,122 @@
+package assigners
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/apply/pickers"
+)
+
+var errTest = errors.New("test error")
+
+func TestReplicationAssigner(t *testing.T) {
+	testCases := []struct {
+		assignerTestCase
+		numBrokers        int
+		replicationFactor int
+		inRack            bool
+	}{
+		{
+			assignerTestCase: assignerTestCase{
+				description: "No change",
+				curr: [][]int{
+					{1, 2},
+					{2, 3},
+				},
+				expected: [][]int{
+					{1, 2},
+					{2, 3},
+				},
+			},
+			numBrokers:        6,
+			replicationFactor: 2,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Increase across racks",
+				curr: [][]int{
+					{1, 2},
+					{2, 3},
+					{3, 1},
+				},
+				expected: [][]int{
+					{1, 2, 3},
+					{2, 3, 1},
+					{3, 1, 2},
+				},
+			},
+			numBrokers:        6,
+			replicationFactor: 3,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Increase in rack",
+				curr: [][]int{
+					{1, 4},
+					{2, 5},
+					{3, 6},
+				},
+				expected: [][]int{
+					{1, 4, 7},
+					{2, 5, 8},
+					{3, 6, 9},
+				},
+			},
+			numBrokers:        9,
+			replicationFactor: 3,
+			inRack:            true,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Decrease removes replicas in duplicated racks first",
+				curr: [][]int{
+					{1, 4, 2},
+					{2, 3, 6},
+					{3, 1, 2},
+				},
+				expected: [][]int{
+					{1, 2},
+					{2, 3},
+					{3, 1},
+				},
+			},
+			numBrokers:        6,
+			replicationFactor: 2,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Not enough brokers",
+				curr: [][]int{
+					{1, 2},
+					{2, 3},
+				},
+				err: errTest,
+			},
+			numBrokers:        3,
+			replicationFactor: 4,
+		},
+		{
+			assignerTestCase: assignerTestCase{
+				description: "Not enough brokers in rack",
+				curr: [][]int{
+					{1, 4},
+					{2, 5},
+				},
+				err: errTest,
+			},
+			numBrokers:        6,
+			replicationFactor: 3,
+			inRack:            true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assigner := NewReplicationAssigner(
+			testBrokers(testCase.numBrokers, 3),
+			testCase.replicationFactor,
+			testCase.inRack,
+			pickers.NewLowestIndexPicker(),
+		)
+		testCase.evaluate(t, assigner)
+	}
+}
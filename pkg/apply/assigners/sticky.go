@@ -0,0 +1,234 @@
+package assigners
+
+import (
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// StickyAssigner is an Assigner that wraps another one and then undoes as many of its
+// replica moves as possible, so that the current assignments are treated as the starting
+// point and only the moves needed to satisfy the placement strategy are kept. See
+// MinimizeMoves for details.
+type StickyAssigner struct {
+	assigner        Assigner
+	brokers         []admin.BrokerInfo
+	placementConfig config.TopicPlacementConfig
+}
+
+var _ Assigner = (*StickyAssigner)(nil)
+
+// NewStickyAssigner creates and returns a StickyAssigner instance. The brokers should be the
+// ones that replicas can be placed on.
+func NewStickyAssigner(
+	assigner Assigner,
+	brokers []admin.BrokerInfo,
+	placementConfig config.TopicPlacementConfig,
+) *StickyAssigner {
+	return &StickyAssigner{
+		assigner:        assigner,
+		brokers:         brokers,
+		placementConfig: placementConfig,
+	}
+}
+
+// Assign returns a new partition assignment according to the assigner-specific logic.
+func (s *StickyAssigner) Assign(
+	topic string,
+	curr []admin.PartitionAssignment,
+) ([]admin.PartitionAssignment, error) {
+	desired, err := s.assigner.Assign(topic, curr)
+	if err != nil {
+		return nil, err
+	}
+
+	return MinimizeMoves(curr, desired, s.brokers, s.placementConfig)
+}
+
+// MinimizeMoves returns a version of the desired assignments that's reached from the
+// current ones with as few replica moves as possible. The algorithm is:
+//
+//  1. For each partition whose desired replicas differ from its current ones, try reverting
+//     it to its current replicas. If that doesn't work, try reverting it along with each of
+//     the other changed partitions, which undoes replicas that were swapped between them.
+//  2. For each replica in a desired assignment that isn't in the current one, try putting
+//     back each of the current replicas that were moved away from the partition.
+//  3. Repeat until nothing changes.
+//
+// where each "try" keeps the change only if the result still satisfies the placement config
+// and isn't less balanced, i.e. the spread between the brokers with the most and fewest
+// replicas or leaders isn't larger than in the desired assignments. Current replicas are
+// only put back on the argument brokers, so moves away from removed or excluded brokers are
+// always kept. If the desired assignments don't satisfy the placement config in the first
+// place, they're returned as-is.
+func MinimizeMoves(
+	curr []admin.PartitionAssignment,
+	desired []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+	placementConfig config.TopicPlacementConfig,
+) ([]admin.PartitionAssignment, error) {
+	ok, err := EvaluateAssignments(desired, brokers, placementConfig)
+	if err != nil {
+		return nil, err
+	}
+	result := admin.CopyAssignments(desired)
+	if !ok {
+		return result, nil
+	}
+
+	brokerIDs := map[int]struct{}{}
+	for _, broker := range brokers {
+		brokerIDs[broker.ID] = struct{}{}
+	}
+
+	currByID := map[int]admin.PartitionAssignment{}
+	for _, assignment := range curr {
+		currByID[assignment.ID] = assignment
+	}
+
+	maxReplicaSpread, maxLeaderSpread := countSpreads(desired, brokers)
+
+	accept := func() (bool, error) {
+		ok, err := EvaluateAssignments(result, brokers, placementConfig)
+		if err != nil || !ok {
+			return false, err
+		}
+		replicaSpread, leaderSpread := countSpreads(result, brokers)
+		return replicaSpread <= maxReplicaSpread && leaderSpread <= maxLeaderSpread, nil
+	}
+
+	for changed := true; changed; {
+		changed = false
+
+		revertible := func(a int) bool {
+			currAssignment, ok := currByID[result[a].ID]
+			return ok && len(currAssignment.Replicas) == len(result[a].Replicas) &&
+				allInBrokers(currAssignment.Replicas, brokerIDs) &&
+				!util.SameElements(currAssignment.Replicas, result[a].Replicas)
+		}
+
+		for a := range result {
+			if !revertible(a) {
+				continue
+			}
+			prev := result[a]
+			result[a] = currByID[prev.ID].Copy()
+
+			ok, err := accept()
+			if err != nil {
+				return nil, err
+			}
+
+			for b := a + 1; !ok && b < len(result); b++ {
+				if !revertible(b) {
+					continue
+				}
+				prevOther := result[b]
+				result[b] = currByID[prevOther.ID].Copy()
+
+				ok, err = accept()
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					result[b] = prevOther
+				}
+			}
+
+			if ok {
+				changed = true
+			} else {
+				result[a] = prev
+			}
+		}
+
+		for a, assignment := range result {
+			currAssignment, ok := currByID[assignment.ID]
+			if !ok {
+				continue
+			}
+
+			for r := range assignment.Replicas {
+				newReplica := result[a].Replicas[r]
+				if currAssignment.Index(newReplica) != -1 {
+					continue
+				}
+
+				for _, currReplica := range currAssignment.Replicas {
+					if _, ok := brokerIDs[currReplica]; !ok || result[a].Index(currReplica) != -1 {
+						continue
+					}
+
+					result[a].Replicas[r] = currReplica
+					ok, err := accept()
+					if err != nil {
+						return nil, err
+					}
+					if ok {
+						changed = true
+						break
+					}
+					result[a].Replicas[r] = newReplica
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func allInBrokers(replicas []int, brokerIDs map[int]struct{}) bool {
+	for _, replica := range replicas {
+		if _, ok := brokerIDs[replica]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// countSpreads returns the differences between the maximum and minimum number of replicas
+// and leaders per broker in the argument assignments.
+func countSpreads(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+) (int, int) {
+	replicaCounts := map[int]int{}
+	leaderCounts := map[int]int{}
+	for _, broker := range brokers {
+		replicaCounts[broker.ID] = 0
+		leaderCounts[broker.ID] = 0
+	}
+
+	for _, assignment := range assignments {
+		for r, replica := range assignment.Replicas {
+			replicaCounts[replica]++
+			if r == 0 {
+				leaderCounts[replica]++
+			}
+		}
+	}
+
+	return spread(replicaCounts), spread(leaderCounts)
+}
+
+func spread(counts map[int]int) int {
+	first := true
+	var minCount, maxCount int
+
+	for _, count := range counts {
+		if first {
+			minCount = count
+			maxCount = count
+			first = false
+		} else {
+			if count < minCount {
+				minCount = count
+			}
+			if count > maxCount {
+				maxCount = count
+			}
+		}
+	}
+
+	return maxCount - minCount
+}
@@ -0,0 +1,162 @@
+package assigners
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimizeMoves(t *testing.T) {
+	// Brokers 1 and 4 are in zone1, 2 and 5 in zone2, and 3 and 6 in zone3
+	brokers := testBrokers(6, 3)
+
+	type minimizeTestCase struct {
+		description     string
+		curr            [][]int
+		desired         [][]int
+		brokers         []admin.BrokerInfo
+		placementConfig config.TopicPlacementConfig
+		expected        [][]int
+	}
+
+	testCases := []minimizeTestCase{
+		{
+			description: "shuffled replicas are put back",
+			curr: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 6},
+			},
+			desired: [][]int{
+				{1, 2},
+				{3, 5},
+				{4, 6},
+			},
+			brokers: brokers,
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyAny,
+			},
+			expected: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 6},
+			},
+		},
+		{
+			description: "leader changes are kept",
+			curr: [][]int{
+				{1, 2},
+				{3, 4},
+			},
+			desired: [][]int{
+				{2, 1},
+				{3, 4},
+			},
+			brokers: brokers,
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyAny,
+			},
+			expected: [][]int{
+				{2, 1},
+				{3, 4},
+			},
+		},
+		{
+			description: "moves off of removed brokers are kept",
+			curr: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 6},
+			},
+			desired: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 1},
+			},
+			brokers: brokers[:5],
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyAny,
+			},
+			expected: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 1},
+			},
+		},
+		{
+			description: "moves needed for the strategy are kept",
+			curr: [][]int{
+				{1, 4},
+				{2, 6},
+			},
+			desired: [][]int{
+				{1, 4},
+				{2, 5},
+			},
+			brokers: brokers,
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyInRack,
+			},
+			expected: [][]int{
+				{1, 4},
+				{2, 5},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		result, err := MinimizeMoves(
+			admin.ReplicasToAssignments(testCase.curr),
+			admin.ReplicasToAssignments(testCase.desired),
+			testCase.brokers,
+			testCase.placementConfig,
+		)
+		require.NoError(t, err, testCase.description)
+
+		replicas, err := admin.AssignmentsToReplicas(result)
+		require.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.expected, replicas, testCase.description)
+	}
+}
+
+func TestStickyAssigner(t *testing.T) {
+	brokers := testBrokers(6, 3)
+	assigner := NewStickyAssigner(
+		&StaticAssigner{
+			Assignments: admin.ReplicasToAssignments(
+				[][]int{
+					{1, 2},
+					{3, 5},
+					{4, 6},
+				},
+			),
+		},
+		brokers,
+		config.TopicPlacementConfig{
+			Strategy: config.PlacementStrategyAny,
+		},
+	)
+
+	testCases := []assignerTestCase{
+		{
+			description: "Only moves that are needed",
+			curr: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 6},
+			},
+			expected: [][]int{
+				{1, 2},
+				{3, 4},
+				{5, 6},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, assigner)
+	}
+}
@@ -0,0 +1,150 @@
+package assigners
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// PlacementViolation describes a single partition whose replicas don't satisfy the
+// placement config of its topic.
+type PlacementViolation struct {
+	Partition int
+	Reason    string
+}
+
+// PlacementViolations returns the partitions in the argument assignments that don't satisfy
+// the argument placement config on their own, e.g. because one of their brokers was
+// replaced by one in a different rack. Only the per-partition parts of each strategy are
+// checked; properties of the topic as a whole, like the leader balance required by
+// balanced-leaders, are left to EvaluateAssignments.
+//
+// The brokers argument should contain all of the brokers in the cluster so that replicas on
+// brokers that no longer exist can be flagged.
+func PlacementViolations(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+	placementConfig config.TopicPlacementConfig,
+) ([]PlacementViolation, error) {
+	if err := admin.CheckAssignments(assignments); err != nil {
+		return nil, err
+	}
+
+	brokerRacks := admin.BrokerRacks(brokers)
+	violations := []PlacementViolation{}
+
+	for a, assignment := range assignments {
+		reason := ""
+
+		for _, replica := range assignment.Replicas {
+			if _, ok := brokerRacks[replica]; !ok {
+				reason = fmt.Sprintf("replica on broker %d, which isn't in the cluster", replica)
+				break
+			}
+			if intInSlice(replica, placementConfig.ExcludeBrokers) {
+				reason = fmt.Sprintf("replica on excluded broker %d", replica)
+				break
+			}
+		}
+
+		if reason == "" {
+			switch placementConfig.Strategy {
+			case config.PlacementStrategyInRack, config.PlacementStrategyStaticInRack:
+				racks := assignment.DistinctRacks(brokerRacks)
+				expectedRack := brokerRacks[assignment.Replicas[0]]
+				if placementConfig.Strategy == config.PlacementStrategyStaticInRack &&
+					a < len(placementConfig.StaticRackAssignments) {
+					expectedRack = placementConfig.StaticRackAssignments[a]
+				}
+
+				if len(racks) > 1 {
+					reason = fmt.Sprintf("replicas span racks %+v", racks)
+				} else if brokerRacks[assignment.Replicas[0]] != expectedRack {
+					reason = fmt.Sprintf(
+						"replicas are in rack %s instead of %s",
+						brokerRacks[assignment.Replicas[0]],
+						expectedRack,
+					)
+				}
+			case config.PlacementStrategyStatic:
+				if a < len(placementConfig.StaticAssignments) &&
+					!reflect.DeepEqual(assignment.Replicas, placementConfig.StaticAssignments[a]) {
+					reason = fmt.Sprintf(
+						"replicas %+v don't match static assignment %+v",
+						assignment.Replicas,
+						placementConfig.StaticAssignments[a],
+					)
+				}
+			case config.PlacementStrategyBalancedTopicSet:
+				if a < len(placementConfig.TopicSetAssignments) &&
+					!reflect.DeepEqual(assignment.Replicas, placementConfig.TopicSetAssignments[a]) {
+					reason = fmt.Sprintf(
+						"replicas %+v don't match the other topics in the set %+v",
+						assignment.Replicas,
+						placementConfig.TopicSetAssignments[a],
+					)
+				}
+			}
+		}
+
+		if reason != "" {
+			violations = append(
+				violations,
+				PlacementViolation{
+					Partition: assignment.ID,
+					Reason:    reason,
+				},
+			)
+		}
+	}
+
+	return violations, nil
+}
+
+// RepairViolations returns a copy of the current assignments in which only the partitions
+// with the argument violations are replaced by their desired assignments. This limits the
+// replicas that are moved when a few partitions are out of line, e.g. after a broker moves
+// racks, to the ones that actually need to be fixed.
+func RepairViolations(
+	curr []admin.PartitionAssignment,
+	desired []admin.PartitionAssignment,
+	violations []PlacementViolation,
+) []admin.PartitionAssignment {
+	violatingIDs := map[int]struct{}{}
+	for _, violation := range violations {
+		violatingIDs[violation.Partition] = struct{}{}
+	}
+
+	desiredByID := map[int]admin.PartitionAssignment{}
+	for _, assignment := range desired {
+		desiredByID[assignment.ID] = assignment
+	}
+
+	repaired := admin.CopyAssignments(curr)
+	for a, assignment := range repaired {
+		if _, ok := violatingIDs[assignment.ID]; !ok {
+			continue
+		}
+		if desiredAssignment, ok := desiredByID[assignment.ID]; ok {
+			repaired[a] = desiredAssignment.Copy()
+		}
+	}
+
+	return repaired
+}
+
+// FormatPlacementViolations returns a string with one line per violation, suitable for
+// logging.
+func FormatPlacementViolations(violations []PlacementViolation) string {
+	lines := []string{}
+	for _, violation := range violations {
+		lines = append(
+			lines,
+			fmt.Sprintf("  partition %d: %s", violation.Partition, violation.Reason),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,141 @@
+package assigners
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementViolations(t *testing.T) {
+	// Brokers 1, 4, and 7 are in zone1, 2, 5, and 8 in zone2, and 3, 6, and 9 in zone3
+	brokers := testBrokers(9, 3)
+
+	type violationsTestCase struct {
+		description        string
+		replicas           [][]int
+		placementConfig    config.TopicPlacementConfig
+		expectedPartitions []int
+	}
+
+	testCases := []violationsTestCase{
+		{
+			description: "in-rack satisfied",
+			replicas: [][]int{
+				{1, 4, 7},
+				{2, 5, 8},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyInRack,
+			},
+			expectedPartitions: []int{},
+		},
+		{
+			description: "in-rack with a replica in another rack",
+			replicas: [][]int{
+				{1, 4, 7},
+				{2, 5, 9},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyInRack,
+			},
+			expectedPartitions: []int{1},
+		},
+		{
+			description: "static-in-rack in the wrong rack",
+			replicas: [][]int{
+				{1, 4, 7},
+				{2, 5, 8},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:              config.PlacementStrategyStaticInRack,
+				StaticRackAssignments: []string{"zone1", "zone3"},
+			},
+			expectedPartitions: []int{1},
+		},
+		{
+			description: "static mismatch",
+			replicas: [][]int{
+				{1, 2, 3},
+				{4, 5, 6},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:          config.PlacementStrategyStatic,
+				StaticAssignments: [][]int{{1, 2, 3}, {5, 4, 6}},
+			},
+			expectedPartitions: []int{1},
+		},
+		{
+			description: "missing and excluded brokers",
+			replicas: [][]int{
+				{1, 2, 10},
+				{4, 5, 6},
+				{7, 8, 9},
+			},
+			placementConfig: config.TopicPlacementConfig{
+				Strategy:       config.PlacementStrategyAny,
+				ExcludeBrokers: []int{9},
+			},
+			expectedPartitions: []int{0, 2},
+		},
+	}
+
+	for _, testCase := range testCases {
+		violations, err := PlacementViolations(
+			admin.ReplicasToAssignments(testCase.replicas),
+			brokers,
+			testCase.placementConfig,
+		)
+		require.NoError(t, err, testCase.description)
+
+		partitions := []int{}
+		for _, violation := range violations {
+			partitions = append(partitions, violation.Partition)
+		}
+		assert.Equal(t, testCase.expectedPartitions, partitions, testCase.description)
+	}
+}
+
+func TestRepairViolations(t *testing.T) {
+	curr := admin.ReplicasToAssignments(
+		[][]int{
+			{1, 4, 7},
+			{2, 5, 9},
+			{3, 6, 9},
+		},
+	)
+	desired := admin.ReplicasToAssignments(
+		[][]int{
+			{4, 1, 7},
+			{2, 5, 8},
+			{6, 3, 9},
+		},
+	)
+
+	repaired := RepairViolations(
+		curr,
+		desired,
+		[]PlacementViolation{
+			{
+				Partition: 1,
+				Reason:    "replicas span racks [zone2 zone3]",
+			},
+		},
+	)
+	replicas, err := admin.AssignmentsToReplicas(repaired)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[][]int{
+			{1, 4, 7},
+			{2, 5, 8},
+			{3, 6, 9},
+		},
+		replicas,
+	)
+
+	// The current assignments aren't modified
+	assert.Equal(t, []int{2, 5, 9}, curr[1].Replicas)
+}
@@ -0,0 +1,373 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	log "github.com/sirupsen/logrus"
+)
+
+const backupTimeFormat = "20060102-150405"
+
+// TopicBackup is a copy of the configs and partition assignments of a topic from just
+// before an apply changed them. It can be restored with RollbackTopic.
+type TopicBackup struct {
+	ID        string          `json:"id"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Cluster   string          `json:"cluster"`
+	ClusterID string          `json:"clusterID"`
+	Topic     admin.TopicInfo `json:"topic"`
+}
+
+// RollbackConfig contains the configuration for a RollbackTopic call.
+type RollbackConfig struct {
+	DryRun        bool
+	SkipConfirm   bool
+	SleepLoopTime time.Duration
+}
+
+// NewTopicBackup creates a backup of the current state of the argument topic. The ID of the
+// backup is based on the cluster name, topic name, and argument time.
+func NewTopicBackup(
+	ctx context.Context,
+	adminClient *admin.Client,
+	cluster string,
+	topic string,
+	now time.Time,
+) (TopicBackup, error) {
+	backup := TopicBackup{
+		ID:        fmt.Sprintf("%s-%s-%s", cluster, topic, now.UTC().Format(backupTimeFormat)),
+		CreatedAt: now.UTC(),
+		Cluster:   cluster,
+	}
+
+	var err error
+
+	backup.ClusterID, err = adminClient.GetClusterID(ctx)
+	if err != nil {
+		return backup, err
+	}
+	backup.Topic, err = adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		return backup, err
+	}
+
+	return backup, nil
+}
+
+// WriteFile writes the backup as JSON to the argument directory, creating the latter if
+// needed, and returns the path of the file. If a backup with the same ID already exists,
+// then a numeric suffix is added to the ID.
+func (b *TopicBackup) WriteFile(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	// Multiple backups of the same topic could be made in the same second
+	baseID := b.ID
+	for i := 2; ; i++ {
+		if _, err := os.Stat(backupPath(dir, b.ID)); os.IsNotExist(err) {
+			break
+		}
+		b.ID = fmt.Sprintf("%s-%d", baseID, i)
+	}
+
+	contents, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := backupPath(dir, b.ID)
+	return path, ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// LoadTopicBackup loads the backup with the argument ID from the argument directory.
+func LoadTopicBackup(dir string, id string) (TopicBackup, error) {
+	backup := TopicBackup{}
+
+	contents, err := ioutil.ReadFile(backupPath(dir, id))
+	if os.IsNotExist(err) {
+		return backup, fmt.Errorf("Could not find backup %s in %s", id, dir)
+	} else if err != nil {
+		return backup, err
+	}
+	if err := json.Unmarshal(contents, &backup); err != nil {
+		return backup, fmt.Errorf("Could not parse backup %s: %+v", id, err)
+	}
+
+	return backup, nil
+}
+
+// ListTopicBackups returns all of the backups in the argument directory, newest first. A
+// directory that doesn't exist is treated as empty.
+func ListTopicBackups(dir string) ([]TopicBackup, error) {
+	backups := []TopicBackup{}
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return backups, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() || !strings.HasSuffix(fileInfo.Name(), ".json") {
+			continue
+		}
+
+		backup, err := LoadTopicBackup(dir, strings.TrimSuffix(fileInfo.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(a, b int) bool {
+		if backups[a].CreatedAt.Equal(backups[b].CreatedAt) {
+			return backups[a].ID > backups[b].ID
+		}
+		return backups[a].CreatedAt.After(backups[b].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// RollbackTopic restores the configs and partition assignments of a topic to the ones in
+// the argument backup. Partitions that were added since the backup can't be removed, so
+// they're left as-is.
+func RollbackTopic(
+	ctx context.Context,
+	adminClient *admin.Client,
+	backup TopicBackup,
+	rollbackConfig RollbackConfig,
+) error {
+	topicName := backup.Topic.Name
+
+	clusterID, err := adminClient.GetClusterID(ctx)
+	if err != nil {
+		return err
+	}
+	if backup.ClusterID != "" && clusterID != backup.ClusterID {
+		return fmt.Errorf(
+			"Backup %s is from cluster %s (ID %s), but the current cluster has ID %s",
+			backup.ID,
+			backup.Cluster,
+			backup.ClusterID,
+			clusterID,
+		)
+	}
+
+	topicInfo, err := adminClient.GetTopic(ctx, topicName, false)
+	if err == admin.ErrTopicDoesNotExist {
+		return fmt.Errorf("Topic %s no longer exists, so it can't be rolled back", topicName)
+	} else if err != nil {
+		return err
+	}
+
+	diffs := admin.DiffSnapshots(
+		admin.ClusterSnapshot{Topics: []admin.TopicInfo{topicInfo}},
+		admin.ClusterSnapshot{Topics: []admin.TopicInfo{backup.Topic}},
+	)
+	if len(diffs) == 0 {
+		log.Infof("Topic %s already matches backup %s", topicName, backup.ID)
+		return nil
+	}
+
+	log.Infof(
+		"Differences between the current state of topic %s and backup %s:\n%s",
+		topicName,
+		backup.ID,
+		admin.FormatSnapshotDiffs(diffs),
+	)
+
+	configEntries := rollbackConfigEntries(topicInfo.Config, backup.Topic.Config)
+	assignments, addedPartitions := rollbackAssignments(
+		topicInfo.ToAssignments(),
+		backup.Topic.ToAssignments(),
+	)
+
+	if len(addedPartitions) > 0 {
+		log.Warnf(
+			"Partition(s) %v were added after the backup; these can't be removed and will be left as-is",
+			addedPartitions,
+		)
+	}
+
+	if rollbackConfig.DryRun {
+		log.Infof("Skipping rollback because dryRun is set to true")
+		return nil
+	}
+
+	ok, _ := Confirm(
+		fmt.Sprintf("OK to roll topic %s back to backup %s?", topicName, backup.ID),
+		rollbackConfig.SkipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if len(configEntries) > 0 {
+		log.Infof("Restoring %d config key(s) for topic %s", len(configEntries), topicName)
+		if _, err := adminClient.UpdateTopicConfig(
+			ctx,
+			topicName,
+			configEntries,
+			true,
+		); err != nil {
+			return err
+		}
+	}
+
+	if len(assignments) == 0 {
+		return nil
+	}
+
+	inProgress, err := adminClient.AssignmentInProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if inProgress {
+		return errors.New(
+			"Cannot restore partition assignments while another reassignment is in progress",
+		)
+	}
+
+	log.Infof("Restoring assignments for %d partition(s)", len(assignments))
+	if err := adminClient.AssignPartitions(ctx, topicName, assignments); err != nil {
+		return err
+	}
+
+	for {
+		log.Infof("Sleeping for %s", rollbackConfig.SleepLoopTime.String())
+		if err := interruptableSleep(ctx, rollbackConfig.SleepLoopTime); err != nil {
+			return err
+		}
+
+		inProgress, err := adminClient.AssignmentInProgress(ctx)
+		if err != nil {
+			return err
+		}
+		if !inProgress {
+			break
+		}
+		log.Info("Reassignment still in progress")
+	}
+
+	partitionIDs := []int{}
+	for _, assignment := range assignments {
+		partitionIDs = append(partitionIDs, assignment.ID)
+	}
+
+	// Move the leaders back to the first replicas in the restored assignments
+	log.Infof("Running leader election for %d partition(s)", len(partitionIDs))
+	return adminClient.RunLeaderElection(ctx, topicName, partitionIDs)
+}
+
+// backupTopic writes a backup of the topic before the first change to an existing topic.
+// It's a no-op if backups are disabled or one has already been written during this apply.
+func (t *TopicApplier) backupTopic(ctx context.Context) error {
+	if t.config.BackupDir == "" || t.backupID != "" {
+		return nil
+	}
+
+	backup, err := NewTopicBackup(
+		ctx,
+		t.adminClient,
+		t.clusterConfig.Meta.Name,
+		t.topicName,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("Could not back up topic %s: %+v", t.topicName, err)
+	}
+
+	path, err := backup.WriteFile(t.config.BackupDir)
+	if err != nil {
+		return fmt.Errorf("Could not write backup of topic %s: %+v", t.topicName, err)
+	}
+
+	t.backupID = backup.ID
+	log.Infof(
+		"Backed up the current state of topic %s to %s; run 'topicctl rollback %s' to restore it",
+		t.topicName,
+		path,
+		backup.ID,
+	)
+	return nil
+}
+
+// rollbackConfigEntries returns the config entries needed to change the current topic
+// configs to the backed up ones. Keys that weren't set in the backup have empty values so
+// that they're removed.
+func rollbackConfigEntries(
+	current map[string]string,
+	backedUp map[string]string,
+) []kafka.ConfigEntry {
+	keys := []string{}
+	for key := range current {
+		if _, ok := backedUp[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range backedUp {
+		if current[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	configEntries := []kafka.ConfigEntry{}
+	for _, key := range keys {
+		configEntries = append(
+			configEntries,
+			kafka.ConfigEntry{
+				ConfigName:  key,
+				ConfigValue: backedUp[key],
+			},
+		)
+	}
+	return configEntries
+}
+
+// rollbackAssignments returns the backed up assignments of the partitions whose replicas
+// have changed, along with the IDs of any partitions that didn't exist in the backup.
+func rollbackAssignments(
+	current []admin.PartitionAssignment,
+	backedUp []admin.PartitionAssignment,
+) ([]admin.PartitionAssignment, []int) {
+	backedUpByID := map[int]admin.PartitionAssignment{}
+	for _, assignment := range backedUp {
+		backedUpByID[assignment.ID] = assignment
+	}
+
+	assignments := []admin.PartitionAssignment{}
+	addedPartitions := []int{}
+
+	for _, assignment := range current {
+		backedUpAssignment, ok := backedUpByID[assignment.ID]
+		if !ok {
+			addedPartitions = append(addedPartitions, assignment.ID)
+			continue
+		}
+		if !reflect.DeepEqual(assignment.Replicas, backedUpAssignment.Replicas) {
+			assignments = append(assignments, backedUpAssignment.Copy())
+		}
+	}
+
+	return assignments, addedPartitions
+}
+
+func backupPath(dir string, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.json", id))
+}
@@ -0,0 +1,117 @@
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicBackupFiles(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "backups")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	backups, err := ListTopicBackups(tempDir + "/missing")
+	require.NoError(t, err)
+	assert.Equal(t, []TopicBackup{}, backups)
+
+	createdAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	backup1 := TopicBackup{
+		ID:        "test-cluster-topic1-20210301-120000",
+		CreatedAt: createdAt,
+		Cluster:   "test-cluster",
+		ClusterID: "test-id",
+		Topic: admin.TopicInfo{
+			Name:   "topic1",
+			Config: map[string]string{"retention.ms": "1000"},
+			Partitions: []admin.PartitionInfo{
+				{Topic: "topic1", ID: 0, Replicas: []int{1, 2}},
+			},
+		},
+	}
+	backup2 := backup1
+	backup2.CreatedAt = createdAt.Add(time.Minute)
+	backup2.ID = "test-cluster-topic1-20210301-120100"
+
+	_, err = backup1.WriteFile(tempDir)
+	require.NoError(t, err)
+	_, err = backup2.WriteFile(tempDir)
+	require.NoError(t, err)
+
+	// Writing a backup with an existing ID adds a suffix
+	backup3 := backup2
+	_, err = backup3.WriteFile(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, "test-cluster-topic1-20210301-120100-2", backup3.ID)
+
+	loaded, err := LoadTopicBackup(tempDir, backup1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backup1, loaded)
+
+	_, err = LoadTopicBackup(tempDir, "non-existent")
+	assert.Error(t, err)
+
+	backups, err = ListTopicBackups(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, []TopicBackup{backup3, backup2, backup1}, backups)
+}
+
+func TestRollbackConfigEntries(t *testing.T) {
+	assert.Equal(
+		t,
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  "cleanup.policy",
+				ConfigValue: "",
+			},
+			{
+				ConfigName:  "retention.ms",
+				ConfigValue: "1000",
+			},
+			{
+				ConfigName:  "segment.bytes",
+				ConfigValue: "2000",
+			},
+		},
+		rollbackConfigEntries(
+			map[string]string{
+				"cleanup.policy":    "compact",
+				"retention.ms":      "500",
+				"max.message.bytes": "100",
+			},
+			map[string]string{
+				"retention.ms":      "1000",
+				"segment.bytes":     "2000",
+				"max.message.bytes": "100",
+			},
+		),
+	)
+}
+
+func TestRollbackAssignments(t *testing.T) {
+	assignments, addedPartitions := rollbackAssignments(
+		[]admin.PartitionAssignment{
+			{ID: 0, Replicas: []int{1, 2}},
+			{ID: 1, Replicas: []int{3, 4}},
+			{ID: 2, Replicas: []int{2, 3}},
+		},
+		[]admin.PartitionAssignment{
+			{ID: 0, Replicas: []int{1, 2}},
+			{ID: 1, Replicas: []int{2, 1}},
+		},
+	)
+	assert.Equal(
+		t,
+		[]admin.PartitionAssignment{
+			{ID: 1, Replicas: []int{2, 1}},
+		},
+		assignments,
+	)
+	assert.Equal(t, []int{2}, addedPartitions)
+}
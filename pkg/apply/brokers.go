@@ -0,0 +1,200 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	log "github.com/sirupsen/logrus"
+)
+
+// BrokersApplierConfig contains the configuration for a BrokersApplier struct.
+type BrokersApplierConfig struct {
+	AutoApprove   []ApprovalCategory
+	BrokersConfig config.BrokersConfig
+	ClusterConfig config.ClusterConfig
+	DryRun        bool
+	SkipConfirm   bool
+}
+
+// BrokersApplier updates the dynamic broker configs in a cluster so that they match the
+// settings in a brokers config. Only the keys that are set in the brokers config are
+// updated; other keys are left as-is.
+type BrokersApplier struct {
+	config      BrokersApplierConfig
+	adminClient *admin.Client
+}
+
+// NewBrokersApplier creates and returns a new BrokersApplier instance.
+func NewBrokersApplier(
+	adminClient *admin.Client,
+	applierConfig BrokersApplierConfig,
+) *BrokersApplier {
+	return &BrokersApplier{
+		config:      applierConfig,
+		adminClient: adminClient,
+	}
+}
+
+// Apply runs a single "apply" run on the configured brokers.
+func (b *BrokersApplier) Apply(ctx context.Context) error {
+	log.Info("Validating configs...")
+
+	if err := b.config.ClusterConfig.Validate(); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+	if err := b.config.BrokersConfig.Validate(); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+	if err := config.CheckBrokersConsistency(
+		b.config.BrokersConfig,
+		b.config.ClusterConfig,
+	); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+
+	if len(b.config.BrokersConfig.Spec.DefaultSettings) > 0 {
+		log.Info("Checking cluster-wide broker defaults...")
+
+		clusterDefaults, err := b.adminClient.GetClusterDefaultConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := b.updateSettings(
+			ctx,
+			"cluster-wide defaults",
+			b.config.BrokersConfig.Spec.DefaultSettings,
+			clusterDefaults,
+			func(ctx context.Context, keys []string) error {
+				entries, err := b.config.BrokersConfig.Spec.DefaultSettings.ToConfigEntries(keys)
+				if err != nil {
+					return err
+				}
+				_, err = b.adminClient.UpdateClusterDefaultConfig(ctx, entries, true)
+				return err
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	brokerIDs := b.config.BrokersConfig.BrokerIDs()
+	if len(brokerIDs) == 0 {
+		return nil
+	}
+
+	brokers, err := b.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return err
+	}
+	brokersMap := map[int]admin.BrokerInfo{}
+	for _, broker := range brokers {
+		brokersMap[broker.ID] = broker
+	}
+
+	for _, brokerID := range brokerIDs {
+		broker, ok := brokersMap[brokerID]
+		if !ok {
+			return fmt.Errorf("Broker %d in brokers config is not in cluster", brokerID)
+		}
+
+		log.Infof("Checking settings for broker %d...", brokerID)
+		settings := b.config.BrokersConfig.SettingsForBroker(brokerID)
+
+		currConfig := broker.Config
+		if currConfig == nil {
+			currConfig = map[string]string{}
+		}
+
+		if err := b.updateSettings(
+			ctx,
+			fmt.Sprintf("broker %d", brokerID),
+			settings,
+			currConfig,
+			func(ctx context.Context, keys []string) error {
+				entries, err := settings.ToConfigEntries(keys)
+				if err != nil {
+					return err
+				}
+				_, err = b.adminClient.UpdateBrokerConfig(ctx, brokerID, entries, true)
+				return err
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *BrokersApplier) updateSettings(
+	ctx context.Context,
+	name string,
+	settings config.BrokerSettings,
+	currConfig map[string]string,
+	updater func(ctx context.Context, keys []string) error,
+) error {
+	diffKeys, missingKeys, err := settings.ConfigMapDiffs(currConfig)
+	if err != nil {
+		return err
+	}
+
+	if len(diffKeys) > 0 {
+		settingsDiff, err := FormatSettingsDiff(settings.ToTopicSettings(), currConfig, diffKeys)
+		if err != nil {
+			return err
+		}
+
+		log.Infof(
+			"Found %d key(s) with different values for %s:\n%s",
+			len(diffKeys),
+			name,
+			settingsDiff,
+		)
+
+		if b.config.DryRun {
+			log.Infof("Skipping update because dryRun is set to true")
+		} else {
+			ok, _ := confirmChange(
+				fmt.Sprintf("OK to update %s to the new values in the brokers config?", name),
+				ApprovalCategoryConfig,
+				b.config.SkipConfirm,
+				b.config.AutoApprove,
+			)
+			if !ok {
+				return errors.New("Stopping because of user response")
+			}
+			log.Infof("OK, updating")
+
+			if err := updater(ctx, diffKeys); err != nil {
+				return err
+			}
+		}
+	} else {
+		log.Infof("Settings for %s are up-to-date", name)
+	}
+
+	// Don't warn about the throttles since these are managed by topic applies
+	unmanagedKeys := []string{}
+	for _, key := range missingKeys {
+		if key == admin.LeaderThrottledKey || key == admin.FollowerThrottledKey {
+			continue
+		}
+		unmanagedKeys = append(unmanagedKeys, key)
+	}
+
+	if len(unmanagedKeys) > 0 {
+		log.Warnf(
+			"Found %d key(s) set for %s but missing from config:\n%s\nThese will be left as-is.",
+			len(unmanagedKeys),
+			name,
+			FormatMissingKeys(currConfig, unmanagedKeys),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package apply
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// ChangeReport is a machine-readable summary of the changes made by one or more topic
+// applies. In dry-run mode, it contains the changes that would have been made instead.
+type ChangeReport struct {
+	DryRun bool            `json:"dryRun"`
+	Topics []*TopicChanges `json:"topics"`
+
+	// Error is set if the apply failed; the report then only includes the changes made
+	// before the failure.
+	Error string `json:"error,omitempty"`
+}
+
+// TopicChanges stores the changes to a single topic.
+type TopicChanges struct {
+	Topic       string `json:"topic"`
+	Cluster     string `json:"cluster"`
+	Environment string `json:"environment"`
+
+	Created         bool                        `json:"created"`
+	ConfigChanges   []ConfigChange              `json:"configChanges"`
+	AddedPartitions []admin.PartitionAssignment `json:"addedPartitions"`
+	Reassignments   []PartitionReassignment     `json:"reassignments"`
+
+	// Deleted is set if a retiring topic was deleted. BlockedProduce and UnblockedProduce are
+	// set if the ACL that blocks produce requests to the topic was added or removed.
+	Deleted          bool `json:"deleted,omitempty"`
+	BlockedProduce   bool `json:"blockedProduce,omitempty"`
+	UnblockedProduce bool `json:"unblockedProduce,omitempty"`
+
+	// Stopped is set if the apply was stopped before all of its changes were made.
+	// InFlightReassignments are the reassignments that were started but hadn't finished when
+	// the apply stopped; CancelledReassignments are the ones that were cancelled instead.
+	Stopped                bool                    `json:"stopped,omitempty"`
+	InFlightReassignments  []PartitionReassignment `json:"inFlightReassignments,omitempty"`
+	CancelledReassignments []PartitionReassignment `json:"cancelledReassignments,omitempty"`
+
+	// Skipped is set to the reason that the apply skipped the topic, e.g. because it's
+	// frozen.
+	Skipped string `json:"skipped,omitempty"`
+
+	// StateFingerprint is a hash of the brokers and the state of the topic before the apply;
+	// see StateFingerprint for details.
+	StateFingerprint string `json:"stateFingerprint,omitempty"`
+}
+
+// ConfigChange is a change to a single topic config key. OldValue is empty if the key
+// wasn't previously set.
+type ConfigChange struct {
+	Key      string `json:"key"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// PartitionReassignment is a change to the replicas of a single partition.
+type PartitionReassignment struct {
+	Partition   int   `json:"partition"`
+	OldReplicas []int `json:"oldReplicas"`
+	NewReplicas []int `json:"newReplicas"`
+}
+
+// AddTopic adds a new, empty entry for a topic to the report and returns it.
+func (r *ChangeReport) AddTopic(topic string, cluster string, environment string) *TopicChanges {
+	topicChanges := &TopicChanges{
+		Topic:           topic,
+		Cluster:         cluster,
+		Environment:     environment,
+		ConfigChanges:   []ConfigChange{},
+		AddedPartitions: []admin.PartitionAssignment{},
+		Reassignments:   []PartitionReassignment{},
+	}
+	r.Topics = append(r.Topics, topicChanges)
+	return topicChanges
+}
+
+// WriteFile writes the report as indented JSON to the argument path.
+func (r *ChangeReport) WriteFile(path string) error {
+	contents, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// NumChanges returns the total number of changes to the topic. Creating or deleting the
+// topic, changing a single config key, adding a single partition, reassigning a single
+// partition, and blocking or unblocking produce requests each count as one change.
+func (t *TopicChanges) NumChanges() int {
+	numChanges := len(t.ConfigChanges) + len(t.AddedPartitions) + len(t.Reassignments)
+	for _, changed := range []bool{t.Created, t.Deleted, t.BlockedProduce, t.UnblockedProduce} {
+		if changed {
+			numChanges++
+		}
+	}
+	return numChanges
+}
+
+func (t *TopicChanges) addConfigChanges(
+	currConfig map[string]string,
+	configEntries []kafka.ConfigEntry,
+) {
+	for _, entry := range configEntries {
+		t.ConfigChanges = append(
+			t.ConfigChanges,
+			ConfigChange{
+				Key:      entry.ConfigName,
+				OldValue: currConfig[entry.ConfigName],
+				NewValue: entry.ConfigValue,
+			},
+		)
+	}
+
+	sort.Slice(t.ConfigChanges, func(a, b int) bool {
+		return t.ConfigChanges[a].Key < t.ConfigChanges[b].Key
+	})
+}
+
+func (t *TopicChanges) addPartitions(assignments []admin.PartitionAssignment) {
+	for _, assignment := range assignments {
+		t.AddedPartitions = append(
+			t.AddedPartitions,
+			admin.PartitionAssignment{
+				ID:       assignment.ID,
+				Replicas: util.CopyInts(assignment.Replicas),
+			},
+		)
+	}
+}
+
+func (t *TopicChanges) addReassignments(
+	currAssignments []admin.PartitionAssignment,
+	newAssignments []admin.PartitionAssignment,
+) {
+	t.Reassignments = append(
+		t.Reassignments,
+		partitionReassignments(currAssignments, newAssignments)...,
+	)
+}
+
+func (t *TopicChanges) addInFlightReassignments(
+	currAssignments []admin.PartitionAssignment,
+	newAssignments []admin.PartitionAssignment,
+) {
+	t.InFlightReassignments = append(
+		t.InFlightReassignments,
+		partitionReassignments(currAssignments, newAssignments)...,
+	)
+}
+
+func (t *TopicChanges) addCancelledReassignments(
+	currAssignments []admin.PartitionAssignment,
+	newAssignments []admin.PartitionAssignment,
+) {
+	t.CancelledReassignments = append(
+		t.CancelledReassignments,
+		partitionReassignments(currAssignments, newAssignments)...,
+	)
+}
+
+func partitionReassignments(
+	currAssignments []admin.PartitionAssignment,
+	newAssignments []admin.PartitionAssignment,
+) []PartitionReassignment {
+	reassignments := []PartitionReassignment{}
+
+	for i, assignment := range newAssignments {
+		reassignments = append(
+			reassignments,
+			PartitionReassignment{
+				Partition:   assignment.ID,
+				OldReplicas: util.CopyInts(currAssignments[i].Replicas),
+				NewReplicas: util.CopyInts(assignment.Replicas),
+			},
+		)
+	}
+
+	return reassignments
+}
@@ -0,0 +1,109 @@
+package apply
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeReport(t *testing.T) {
+	report := &ChangeReport{
+		DryRun: true,
+		Topics: []*TopicChanges{},
+	}
+
+	topic1 := report.AddTopic("topic1", "test-cluster", "test-env")
+	topic1.Created = true
+
+	topic2 := report.AddTopic("topic2", "test-cluster", "test-env")
+	topic2.addConfigChanges(
+		map[string]string{
+			"retention.ms":   "3600000",
+			"cleanup.policy": "delete",
+		},
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  "retention.ms",
+				ConfigValue: "7200000",
+			},
+			{
+				ConfigName:  "min.insync.replicas",
+				ConfigValue: "2",
+			},
+		},
+	)
+	topic2.addPartitions(
+		[]admin.PartitionAssignment{
+			{
+				ID:       3,
+				Replicas: []int{1, 2},
+			},
+		},
+	)
+	topic2.addReassignments(
+		[]admin.PartitionAssignment{
+			{
+				ID:       0,
+				Replicas: []int{1, 2},
+			},
+		},
+		[]admin.PartitionAssignment{
+			{
+				ID:       0,
+				Replicas: []int{3, 2},
+			},
+		},
+	)
+
+	assert.Equal(t, 1, topic1.NumChanges())
+	assert.Equal(t, 4, topic2.NumChanges())
+
+	assert.Equal(
+		t,
+		[]ConfigChange{
+			{
+				Key:      "min.insync.replicas",
+				OldValue: "",
+				NewValue: "2",
+			},
+			{
+				Key:      "retention.ms",
+				OldValue: "3600000",
+				NewValue: "7200000",
+			},
+		},
+		topic2.ConfigChanges,
+	)
+	assert.Equal(
+		t,
+		[]PartitionReassignment{
+			{
+				Partition:   0,
+				OldReplicas: []int{1, 2},
+				NewReplicas: []int{3, 2},
+			},
+		},
+		topic2.Reassignments,
+	)
+
+	tempDir, err := ioutil.TempDir("", "changes")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	reportPath := filepath.Join(tempDir, "plan.json")
+	require.Nil(t, report.WriteFile(reportPath))
+
+	contents, err := ioutil.ReadFile(reportPath)
+	require.Nil(t, err)
+
+	readReport := ChangeReport{}
+	require.Nil(t, json.Unmarshal(contents, &readReport))
+	assert.Equal(t, *report, readReport)
+}
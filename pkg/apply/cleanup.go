@@ -0,0 +1,456 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	cleanupPolicyKey = "cleanup.policy"
+
+	// cleanupKeySampleSize is the number of recent messages that are checked for keys before
+	// compaction is turned on for a topic.
+	cleanupKeySampleSize = 1000
+
+	// maxCompactedSegmentAge is the largest segment.ms that isn't flagged when compaction is
+	// turned on. The active segment isn't compacted, so long segments delay compaction on
+	// low-throughput topics.
+	maxCompactedSegmentAge = 24 * time.Hour
+)
+
+// cleanupPolicyChange is a change to the effective cleanup.policy of a topic.
+type cleanupPolicyChange struct {
+	oldPolicy string
+	newPolicy string
+}
+
+func (c cleanupPolicyChange) addsCompaction() bool {
+	return !cleanupPolicyHas(c.oldPolicy, "compact") && cleanupPolicyHas(c.newPolicy, "compact")
+}
+
+func (c cleanupPolicyChange) removesCompaction() bool {
+	return cleanupPolicyHas(c.oldPolicy, "compact") && !cleanupPolicyHas(c.newPolicy, "compact")
+}
+
+func (c cleanupPolicyChange) addsDeletion() bool {
+	return !cleanupPolicyHas(c.oldPolicy, "delete") && cleanupPolicyHas(c.newPolicy, "delete")
+}
+
+func (c cleanupPolicyChange) removesDeletion() bool {
+	return cleanupPolicyHas(c.oldPolicy, "delete") && !cleanupPolicyHas(c.newPolicy, "delete")
+}
+
+func (c cleanupPolicyChange) changed() bool {
+	return c.addsCompaction() || c.removesCompaction() || c.addsDeletion() || c.removesDeletion()
+}
+
+// cleanupPolicyHas returns whether the argument cleanup.policy value, which is a
+// comma-separated list, contains the argument policy.
+func cleanupPolicyHas(cleanupPolicy string, policy string) bool {
+	for _, element := range strings.Split(cleanupPolicy, ",") {
+		if strings.TrimSpace(element) == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCleanupPolicyChange determines whether the argument settings diffs would change the
+// cleanup.policy of the topic. If so, it checks the prerequisites for the new policy, warns
+// about any problems found, and returns an error unless the AllowCleanupPolicyChange option
+// is set. The returned change is nil if the policy isn't changed.
+func (t *TopicApplier) checkCleanupPolicyChange(
+	ctx context.Context,
+	topicSettings config.TopicSettings,
+	topicInfo admin.TopicInfo,
+	diffKeys []string,
+) (*cleanupPolicyChange, error) {
+	policyDiff := false
+	for _, key := range diffKeys {
+		if key == cleanupPolicyKey {
+			policyDiff = true
+			break
+		}
+	}
+	if !policyDiff {
+		return nil, nil
+	}
+
+	clusterDefaults, err := t.adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	effectiveValue := func(key string) string {
+		if value, err := topicSettings.GetValueStr(key); err == nil {
+			return value
+		}
+		return admin.EffectiveTopicConfigValue(key, topicInfo.Config, clusterDefaults)
+	}
+
+	change := cleanupPolicyChange{
+		oldPolicy: admin.EffectiveTopicConfigValue(
+			cleanupPolicyKey,
+			topicInfo.Config,
+			clusterDefaults,
+		),
+		newPolicy: effectiveValue(cleanupPolicyKey),
+	}
+	if !change.changed() {
+		return nil, nil
+	}
+
+	log.Warnf(
+		"This apply will change the %s of the topic from %s to %s",
+		cleanupPolicyKey,
+		change.oldPolicy,
+		change.newPolicy,
+	)
+
+	if change.addsCompaction() {
+		t.checkCompactionPrereqs(ctx, topicInfo, effectiveValue)
+	}
+	if change.removesDeletion() {
+		log.Warnf(
+			"Without delete in %s, %s and %s no longer limit the size of the topic; it will grow with the number of distinct keys",
+			cleanupPolicyKey,
+			admin.RetentionKey,
+			admin.RetentionBytesKey,
+		)
+	}
+	if change.removesCompaction() {
+		t.checkCompactionRemoval(ctx, change, effectiveValue)
+	}
+
+	if !t.config.AllowCleanupPolicyChange {
+		if t.config.DryRun {
+			log.Warnf("Applying this change will require --allow-cleanup-policy-change")
+			return &change, nil
+		}
+		return nil, errors.New(
+			"Stopping because cleanup.policy would be changed; set --allow-cleanup-policy-change to proceed",
+		)
+	}
+
+	return &change, nil
+}
+
+// checkCompactionPrereqs checks that the messages in the topic have keys and that its
+// segments roll often enough for compaction to take effect.
+func (t *TopicApplier) checkCompactionPrereqs(
+	ctx context.Context,
+	topicInfo admin.TopicInfo,
+	effectiveValue func(key string) string,
+) {
+	sampled, err := messages.SampleKeys(
+		ctx,
+		t.adminClient.GetBootstrapAddrs()[0],
+		t.topicName,
+		topicInfo.PartitionIDs(),
+		cleanupKeySampleSize,
+	)
+	if err != nil {
+		log.Warnf("Could not sample messages to check for keys: %+v", err)
+	} else {
+		keyless := 0
+		for _, sampledKey := range sampled {
+			if sampledKey.Key == nil {
+				keyless++
+			}
+		}
+
+		if len(sampled) == 0 {
+			log.Infof("The topic is empty, so it couldn't be checked for messages without keys")
+		} else if keyless > 0 {
+			log.Warnf(
+				"%d of the %d most recent message(s) in the topic don't have keys; producers can't write messages without keys to compacted topics, and the log cleaner discards existing ones",
+				keyless,
+				len(sampled),
+			)
+		} else {
+			log.Infof("All %d sampled message(s) have keys", len(sampled))
+		}
+	}
+
+	segmentMsStr := effectiveValue("segment.ms")
+	segmentBytesStr := effectiveValue("segment.bytes")
+
+	segmentMs, err := strconv.ParseInt(segmentMsStr, 10, 64)
+	if err != nil {
+		log.Warnf("Could not parse segment.ms value %s: %+v", segmentMsStr, err)
+		return
+	}
+	segmentAge := time.Duration(segmentMs) * time.Millisecond
+
+	segmentBytes, err := strconv.ParseInt(segmentBytesStr, 10, 64)
+	if err != nil {
+		log.Warnf("Could not parse segment.bytes value %s: %+v", segmentBytesStr, err)
+		return
+	}
+
+	log.Infof(
+		"Segments will roll after %s or %s, whichever comes first",
+		util.PrettyDuration(segmentAge),
+		util.PrettyBytes(segmentBytes),
+	)
+	if segmentAge > maxCompactedSegmentAge {
+		log.Warnf(
+			"The active segment isn't compacted, so records can go uncompacted for up to %s on low-throughput topics; consider setting segment.ms to %s or less in the topic config",
+			util.PrettyDuration(segmentAge),
+			util.PrettyDuration(maxCompactedSegmentAge),
+		)
+	}
+}
+
+// checkCompactionRemoval warns about the data that can be deleted once a topic is no longer
+// compacted.
+func (t *TopicApplier) checkCompactionRemoval(
+	ctx context.Context,
+	change cleanupPolicyChange,
+	effectiveValue func(key string) string,
+) {
+	log.Warnf(
+		"Without compaction, tombstones (messages without values) no longer remove keys from the topic",
+	)
+
+	if !cleanupPolicyHas(change.newPolicy, "delete") {
+		return
+	}
+
+	log.Warnf(
+		"Without compaction, the latest value of each key is deleted once it's older than %s; consumers that rebuild state from the topic will lose keys that haven't been updated recently",
+		admin.RetentionKey,
+	)
+
+	retentionMsStr := effectiveValue(admin.RetentionKey)
+	retentionMs, err := strconv.ParseInt(retentionMsStr, 10, 64)
+	if err != nil {
+		log.Warnf("Could not parse %s value %s: %+v", admin.RetentionKey, retentionMsStr, err)
+		return
+	}
+	if retentionMs < 0 {
+		// Retention is unlimited, so nothing will be deleted by time
+		return
+	}
+
+	bounds, err := messages.GetAllPartitionBounds(
+		ctx,
+		t.adminClient.GetBootstrapAddrs()[0],
+		t.topicName,
+		nil,
+	)
+	if err != nil {
+		log.Warnf("Could not get partition bounds to estimate data loss: %+v", err)
+		return
+	}
+
+	losses := messages.EstimateRetentionLoss(
+		bounds,
+		time.Duration(retentionMs)*time.Millisecond,
+		time.Now(),
+	)
+	log.Warnf(
+		"Estimated messages eligible for deletion once compaction is removed:\n%s",
+		messages.FormatRetentionLosses(losses),
+	)
+}
+
+// splitCleanupPolicyEntries splits the argument config entries into the cleanup.policy
+// entry and all of the others.
+func splitCleanupPolicyEntries(
+	configEntries []kafka.ConfigEntry,
+) ([]kafka.ConfigEntry, []kafka.ConfigEntry) {
+	otherEntries := []kafka.ConfigEntry{}
+	policyEntries := []kafka.ConfigEntry{}
+
+	for _, entry := range configEntries {
+		if entry.ConfigName == cleanupPolicyKey {
+			policyEntries = append(policyEntries, entry)
+		} else {
+			otherEntries = append(otherEntries, entry)
+		}
+	}
+
+	return otherEntries, policyEntries
+}
+
+// updateCleanupPolicy updates the topic config for a cleanup.policy change. The other keys
+// are updated first so that settings like segment.ms and retention.ms are already in
+// effect when the log cleaner picks up the new policy. Afterwards, the cleaner is monitored
+// for the CleanupMonitorWindow set in the config.
+func (t *TopicApplier) updateCleanupPolicy(
+	ctx context.Context,
+	topicInfo admin.TopicInfo,
+	configEntries []kafka.ConfigEntry,
+	change cleanupPolicyChange,
+) error {
+	otherEntries, policyEntries := splitCleanupPolicyEntries(configEntries)
+
+	if len(otherEntries) > 0 {
+		log.Infof("Updating %d other key(s) before %s", len(otherEntries), cleanupPolicyKey)
+		_, err := t.adminClient.UpdateTopicConfig(ctx, t.topicName, otherEntries, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	var before *cleanerSnapshot
+	if t.config.CleanupMonitorWindow > 0 {
+		snapshot, err := t.getCleanerSnapshot(ctx, topicInfo)
+		if err != nil {
+			log.Warnf("Could not get the state of the topic to monitor the cleaner: %+v", err)
+		} else {
+			before = &snapshot
+		}
+	}
+
+	log.Infof(
+		"Updating %s from %s to %s",
+		cleanupPolicyKey,
+		change.oldPolicy,
+		change.newPolicy,
+	)
+	_, err := t.adminClient.UpdateTopicConfig(ctx, t.topicName, policyEntries, true)
+	if err != nil {
+		return err
+	}
+
+	if before == nil {
+		return nil
+	}
+	return t.monitorCleaner(ctx, topicInfo, change, *before)
+}
+
+// cleanerSnapshot contains the state of a topic that changes as its log is cleaned.
+type cleanerSnapshot struct {
+	startOffsets map[int]int64
+
+	// sizes are the sizes of the leader replicas, keyed by partition; they're nil if the
+	// cluster doesn't support fetching log dirs
+	sizes map[int]int64
+}
+
+func (t *TopicApplier) getCleanerSnapshot(
+	ctx context.Context,
+	topicInfo admin.TopicInfo,
+) (cleanerSnapshot, error) {
+	startOffsets, err := messages.GetStartOffsets(
+		ctx,
+		t.adminClient.GetBootstrapAddrs()[0],
+		t.topicName,
+		topicInfo.PartitionIDs(),
+	)
+	if err != nil {
+		return cleanerSnapshot{}, err
+	}
+
+	snapshot := cleanerSnapshot{startOffsets: startOffsets}
+
+	logDirs, err := t.adminClient.GetLogDirs(ctx, t.brokers)
+	if err != nil {
+		log.Debugf("Could not get log dirs, so sizes won't be monitored: %+v", err)
+	} else {
+		snapshot.sizes = admin.LeaderReplicaSizes(logDirs, topicInfo)
+	}
+
+	return snapshot, nil
+}
+
+// cleanerProgress summarizes the differences between two cleaner snapshots.
+type cleanerProgress struct {
+	partitions         int
+	advancedPartitions int
+
+	sizesKnown bool
+	startSize  int64
+	endSize    int64
+}
+
+func newCleanerProgress(before cleanerSnapshot, after cleanerSnapshot) cleanerProgress {
+	progress := cleanerProgress{
+		sizesKnown: before.sizes != nil && after.sizes != nil,
+	}
+
+	for partition, startOffset := range before.startOffsets {
+		endOffset, ok := after.startOffsets[partition]
+		if !ok {
+			continue
+		}
+		progress.partitions++
+		if endOffset > startOffset {
+			progress.advancedPartitions++
+		}
+	}
+
+	if progress.sizesKnown {
+		for partition, size := range before.sizes {
+			if afterSize, ok := after.sizes[partition]; ok {
+				progress.startSize += size
+				progress.endSize += afterSize
+			}
+		}
+	}
+
+	return progress
+}
+
+// monitorCleaner waits for the CleanupMonitorWindow set in the config and then logs whether
+// the topic has been cleaned under its new policy.
+func (t *TopicApplier) monitorCleaner(
+	ctx context.Context,
+	topicInfo admin.TopicInfo,
+	change cleanupPolicyChange,
+	before cleanerSnapshot,
+) error {
+	log.Infof(
+		"Monitoring the log cleaner for %s",
+		util.PrettyDuration(t.config.CleanupMonitorWindow),
+	)
+	if err := interruptableSleep(ctx, t.config.CleanupMonitorWindow); err != nil {
+		return err
+	}
+
+	after, err := t.getCleanerSnapshot(ctx, topicInfo)
+	if err != nil {
+		log.Warnf("Could not get the state of the topic after the change: %+v", err)
+		return nil
+	}
+	progress := newCleanerProgress(before, after)
+
+	log.Infof(
+		"Start offsets advanced in %d of %d partition(s) after the change",
+		progress.advancedPartitions,
+		progress.partitions,
+	)
+	if progress.sizesKnown {
+		log.Infof(
+			"Total leader replica size went from %s to %s after the change",
+			util.PrettyBytes(progress.startSize),
+			util.PrettyBytes(progress.endSize),
+		)
+	}
+
+	if change.addsCompaction() && (!progress.sizesKnown || progress.endSize >= progress.startSize) {
+		log.Infof(
+			"No compaction seen yet; the cleaner only compacts closed segments, and only once their dirty ratio exceeds min.cleanable.dirty.ratio. Check on it later with 'topicctl report compaction'.",
+		)
+	}
+	if (change.addsDeletion() || change.removesCompaction()) &&
+		cleanupPolicyHas(change.newPolicy, "delete") &&
+		progress.advancedPartitions == 0 {
+		log.Infof(
+			"No segments deleted yet; brokers check retention every log.retention.check.interval.ms (5 minutes by default), and only delete closed segments",
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanupPolicyChange(t *testing.T) {
+	toCompact := cleanupPolicyChange{oldPolicy: "delete", newPolicy: "compact"}
+	assert.True(t, toCompact.changed())
+	assert.True(t, toCompact.addsCompaction())
+	assert.True(t, toCompact.removesDeletion())
+	assert.False(t, toCompact.removesCompaction())
+	assert.False(t, toCompact.addsDeletion())
+
+	toDelete := cleanupPolicyChange{oldPolicy: "compact", newPolicy: "delete"}
+	assert.True(t, toDelete.changed())
+	assert.True(t, toDelete.removesCompaction())
+	assert.True(t, toDelete.addsDeletion())
+	assert.False(t, toDelete.addsCompaction())
+
+	toBoth := cleanupPolicyChange{oldPolicy: "delete", newPolicy: "compact, delete"}
+	assert.True(t, toBoth.changed())
+	assert.True(t, toBoth.addsCompaction())
+	assert.False(t, toBoth.removesDeletion())
+
+	reordered := cleanupPolicyChange{oldPolicy: "compact,delete", newPolicy: "delete,compact"}
+	assert.False(t, reordered.changed())
+}
+
+func TestSplitCleanupPolicyEntries(t *testing.T) {
+	otherEntries, policyEntries := splitCleanupPolicyEntries(
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  "segment.ms",
+				ConfigValue: "3600000",
+			},
+			{
+				ConfigName:  "cleanup.policy",
+				ConfigValue: "compact",
+			},
+			{
+				ConfigName:  "min.cleanable.dirty.ratio",
+				ConfigValue: "0.1",
+			},
+		},
+	)
+	assert.Equal(
+		t,
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  "segment.ms",
+				ConfigValue: "3600000",
+			},
+			{
+				ConfigName:  "min.cleanable.dirty.ratio",
+				ConfigValue: "0.1",
+			},
+		},
+		otherEntries,
+	)
+	assert.Equal(
+		t,
+		[]kafka.ConfigEntry{
+			{
+				ConfigName:  "cleanup.policy",
+				ConfigValue: "compact",
+			},
+		},
+		policyEntries,
+	)
+}
+
+func TestCleanerProgress(t *testing.T) {
+	before := cleanerSnapshot{
+		startOffsets: map[int]int64{0: 10, 1: 20, 2: 30},
+		sizes:        map[int]int64{0: 1000, 1: 2000, 2: 3000},
+	}
+	after := cleanerSnapshot{
+		startOffsets: map[int]int64{0: 10, 1: 25, 2: 40},
+		sizes:        map[int]int64{0: 1000, 1: 1500, 2: 2000},
+	}
+
+	assert.Equal(
+		t,
+		cleanerProgress{
+			partitions:         3,
+			advancedPartitions: 2,
+			sizesKnown:         true,
+			startSize:          6000,
+			endSize:            4500,
+		},
+		newCleanerProgress(before, after),
+	)
+
+	after.sizes = nil
+	assert.Equal(
+		t,
+		cleanerProgress{
+			partitions:         3,
+			advancedPartitions: 2,
+		},
+		newCleanerProgress(before, after),
+	)
+}
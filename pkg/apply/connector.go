@@ -0,0 +1,141 @@
+package apply
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/connect"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConnectorApplierConfig contains the configuration for a ConnectorApplier struct.
+type ConnectorApplierConfig struct {
+	AutoApprove     []ApprovalCategory
+	ClusterConfig   config.ClusterConfig
+	ConnectorConfig config.ConnectorConfig
+	DryRun          bool
+	SkipConfirm     bool
+}
+
+// ConnectorApplier reconciles a connector config against the connector state in a
+// Kafka Connect cluster.
+type ConnectorApplier struct {
+	config        ConnectorApplierConfig
+	connectClient *connect.Client
+	connectorName string
+}
+
+// NewConnectorApplier creates and returns a new ConnectorApplier instance.
+func NewConnectorApplier(applierConfig ConnectorApplierConfig) *ConnectorApplier {
+	return &ConnectorApplier{
+		config:        applierConfig,
+		connectClient: connect.NewClient(applierConfig.ClusterConfig.Spec.ConnectURL),
+		connectorName: applierConfig.ConnectorConfig.Meta.Name,
+	}
+}
+
+// Apply runs a single "apply" run on the configured connector. Connectors that don't exist
+// are created, and connectors whose configs differ from the desired ones are updated. The
+// Connect API replaces the full config on each update, so keys that are set in the cluster
+// but not in the config are removed.
+func (c *ConnectorApplier) Apply(ctx context.Context) error {
+	log.Info("Validating configs...")
+
+	if err := c.config.ClusterConfig.Validate(); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+	if err := c.config.ConnectorConfig.Validate(); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+	if err := config.CheckConnectorConsistency(
+		c.config.ConnectorConfig,
+		c.config.ClusterConfig,
+	); err != nil {
+		return exitcode.Wrap(exitcode.KindValidation, err)
+	}
+
+	desiredConfig, err := c.config.ConnectorConfig.ToConnectConfig()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Checking if connector already exists...")
+
+	category := ApprovalCategoryConfig
+
+	currConfig, err := c.connectClient.GetConnectorConfig(ctx, c.connectorName)
+	if err == connect.ErrConnectorNotFound {
+		category = ApprovalCategoryCreate
+		currConfig = map[string]string{}
+		log.Infof(
+			"It looks like this connector doesn't already exist. Will create it with this config:\n%s",
+			connect.FormatConfigDiff(
+				desiredConfig,
+				currConfig,
+				connect.ConfigDiffs(desiredConfig, currConfig),
+			),
+		)
+	} else if err != nil {
+		return err
+	} else {
+		diffKeys := connect.ConfigDiffs(desiredConfig, currConfig)
+		if len(diffKeys) == 0 {
+			log.Infof("Connector config is up-to-date")
+			return c.logStatus(ctx)
+		}
+
+		log.Infof(
+			"Found %d key(s) with different values:\n%s",
+			len(diffKeys),
+			connect.FormatConfigDiff(desiredConfig, currConfig, diffKeys),
+		)
+	}
+
+	if c.config.DryRun {
+		log.Infof("Skipping update because dryRun is set to true")
+		return nil
+	}
+
+	ok, _ := confirmChange(
+		"OK to apply the connector config?",
+		category,
+		c.config.SkipConfirm,
+		c.config.AutoApprove,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+	log.Infof("OK, updating")
+
+	if err := c.connectClient.PutConnectorConfig(
+		ctx,
+		c.connectorName,
+		desiredConfig,
+	); err != nil {
+		return err
+	}
+
+	return c.logStatus(ctx)
+}
+
+func (c *ConnectorApplier) logStatus(ctx context.Context) error {
+	status, err := c.connectClient.GetConnectorStatus(ctx, c.connectorName)
+	if err != nil {
+		return err
+	}
+
+	if status.Running() {
+		log.Infof(
+			"Connector status:\n%s",
+			connect.FormatConnectorStatuses([]connect.ConnectorStatus{status}),
+		)
+	} else {
+		log.Warnf(
+			"Connector is not fully running:\n%s",
+			connect.FormatConnectorStatuses([]connect.ConnectorStatus{status}),
+		)
+	}
+	return nil
+}
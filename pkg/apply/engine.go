@@ -0,0 +1,263 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/check"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+	"github.com/segmentio/topicctl/pkg/locks"
+	"github.com/segmentio/topicctl/pkg/metrics"
+)
+
+// EngineOptions contains the options for an Engine.
+type EngineOptions struct {
+	// PlanOptions are the options that affect which changes are made. They're recorded in
+	// the plans made by the engine.
+	PlanOptions
+
+	// Confirmer decides whether each change can be made. If unset, all changes are approved;
+	// the engine never prompts on stdin.
+	Confirmer Confirmer
+
+	// Locker is used for the cluster and topic locks. If unset, the lock backend in the
+	// cluster config is used, as in the CLI.
+	Locker   locks.Locker
+	LockWait time.Duration
+
+	// BackupDir is the directory that topics are backed up to before they're changed. If
+	// unset, no backups are made.
+	BackupDir string
+
+	// PartitionMetrics, if set, is used to balance leader throughput in rebalances. It
+	// takes the place of the PartitionMetrics path in PlanOptions, which is only recorded.
+	PartitionMetrics metrics.Fetcher
+
+	// SleepLoopTime is the amount of time to wait between checks on in-progress
+	// reassignments. It defaults to 10 seconds.
+	SleepLoopTime time.Duration
+}
+
+// Engine applies and checks topic configs in a single cluster. It wraps TopicApplier and
+// the check package in an API for tools that embed topicctl, e.g. Terraform providers: the
+// clients are injected, configs are passed in memory instead of being loaded from files,
+// and the results are returned instead of being printed. Progress is still logged via
+// logrus.
+type Engine struct {
+	adminClient   *admin.Client
+	clusterConfig config.ClusterConfig
+	options       EngineOptions
+
+	// locker is resolved from the cluster config when it's first needed if it isn't set
+	// in the options
+	locker locks.Locker
+}
+
+// NewEngine creates and returns a new Engine instance for the cluster that the argument
+// admin client is connected to. The client should only be read-only if the engine is just
+// used for diffs, plans, and checks.
+func NewEngine(
+	adminClient *admin.Client,
+	clusterConfig config.ClusterConfig,
+	options EngineOptions,
+) *Engine {
+	if options.Confirmer == nil {
+		options.Confirmer = ApproveAll
+	}
+	if options.SleepLoopTime == 0 {
+		options.SleepLoopTime = 10 * time.Second
+	}
+
+	return &Engine{
+		adminClient:   adminClient,
+		clusterConfig: clusterConfig,
+		options:       options,
+		locker:        options.Locker,
+	}
+}
+
+// Diff returns the changes that applying the argument topic config would make, without
+// making them.
+func (e *Engine) Diff(ctx context.Context, topicConfig config.TopicConfig) (*TopicChanges, error) {
+	report := &ChangeReport{
+		DryRun: true,
+		Topics: []*TopicChanges{},
+	}
+	if err := e.applyTopic(ctx, topicConfig, nil, nil, report, true); err != nil {
+		return nil, err
+	}
+	return report.Topics[0], nil
+}
+
+// Plan returns a plan with the changes that applying each of the argument topic configs
+// would make, without making them. Members of balanced topic sets are looked up among the
+// argument configs. The plan isn't signed; call Sign on it before storing it if it will be
+// loaded again with LoadPlanFile.
+func (e *Engine) Plan(
+	ctx context.Context,
+	topicConfigs []config.TopicConfig,
+	now time.Time,
+) (*Plan, error) {
+	plan := NewPlan(e.options.PlanOptions, now)
+	report := &ChangeReport{
+		DryRun: true,
+		Topics: []*TopicChanges{},
+	}
+
+	for _, topicConfig := range topicConfigs {
+		if err := e.applyTopic(ctx, topicConfig, topicConfigs, nil, report, true); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.Topics = report.Topics
+	return plan, nil
+}
+
+// Execute applies the argument topic configs, making only the changes in the argument plan.
+// It stops with a drift error if the state of a topic has changed since the plan was made
+// or if the apply would make changes that aren't in the plan. The plan's options replace
+// the ones that the engine was created with. The returned report contains the changes that
+// were made, including those made before any error.
+func (e *Engine) Execute(
+	ctx context.Context,
+	topicConfigs []config.TopicConfig,
+	plan *Plan,
+) (*ChangeReport, error) {
+	report := &ChangeReport{
+		DryRun: false,
+		Topics: []*TopicChanges{},
+	}
+
+	for _, topicConfig := range topicConfigs {
+		planned := plan.TopicChanges(topicConfig.Meta.Name, topicConfig.Meta.Cluster)
+		if planned == nil {
+			return report, exitcode.Wrap(
+				exitcode.KindDrift,
+				fmt.Errorf(
+					"Topic %s in cluster %s isn't in the plan",
+					topicConfig.Meta.Name,
+					topicConfig.Meta.Cluster,
+				),
+			)
+		}
+
+		options := e.options
+		options.PlanOptions = plan.Options
+		executor := &Engine{
+			adminClient:   e.adminClient,
+			clusterConfig: e.clusterConfig,
+			options:       options,
+		}
+
+		if err := executor.applyTopic(
+			ctx,
+			topicConfig,
+			topicConfigs,
+			planned,
+			report,
+			false,
+		); err != nil {
+			report.Error = err.Error()
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// Check runs the same checks on the argument topic config as the check subcommand.
+func (e *Engine) Check(
+	ctx context.Context,
+	topicConfig config.TopicConfig,
+	checkLeaders bool,
+) (check.TopicCheckResults, error) {
+	if err := topicConfig.ResolveClusterDefaults(e.clusterConfig); err != nil {
+		return check.TopicCheckResults{}, err
+	}
+
+	brokers, err := e.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return check.TopicCheckResults{}, err
+	}
+
+	return check.CheckTopic(
+		ctx,
+		check.CheckConfig{
+			AdminClient:    e.adminClient,
+			ClusterConfig:  e.clusterConfig,
+			CheckLeaders:   checkLeaders,
+			CheckPlacement: true,
+			NumRacks:       len(admin.DistinctRacks(brokers)),
+			TopicConfig:    topicConfig,
+		},
+	)
+}
+
+func (e *Engine) applyTopic(
+	ctx context.Context,
+	topicConfig config.TopicConfig,
+	allConfigs []config.TopicConfig,
+	planned *TopicChanges,
+	report *ChangeReport,
+	dryRun bool,
+) error {
+	if err := topicConfig.ResolveClusterDefaults(e.clusterConfig); err != nil {
+		return exitcode.Wrap(exitcode.KindConfig, err)
+	}
+
+	if e.locker == nil {
+		locker, err := e.clusterConfig.NewLocker(e.adminClient, nil)
+		if err != nil {
+			return exitcode.Wrap(exitcode.KindConfig, err)
+		}
+		e.locker = locker
+	}
+
+	applierConfig := TopicApplierConfig{
+		AllowCleanupPolicyChange: e.options.AllowCleanupPolicyChange,
+		AllowRepartitioning:      e.options.AllowRepartitioning,
+		AllowRetentionReduction:  e.options.AllowRetentionReduction,
+		BackupDir:                e.options.BackupDir,
+		BrokersToRemove:          e.options.BrokersToRemove,
+		ChangeReport:             report,
+		ClusterConfig:            e.clusterConfig,
+		Confirmer:                e.options.Confirmer,
+		DeleteRetired:            e.options.DeleteRetired,
+		DryRun:                   dryRun,
+		FixRackViolations:        e.options.FixRackViolations,
+		Locker:                   e.locker,
+		LockWait:                 e.options.LockWait,
+		PartitionMetrics:         e.options.PartitionMetrics,
+		PlannedChanges:           planned,
+		Rebalance:                e.options.Rebalance,
+		SleepLoopTime:            e.options.SleepLoopTime,
+		TopicConfig:              topicConfig,
+	}
+
+	if topicConfig.Spec.PlacementConfig.Strategy == config.PlacementStrategyBalancedTopicSet {
+		members := config.TopicSetMembers(
+			topicConfig,
+			allConfigs,
+			e.clusterConfig.Spec.TopicDefaults,
+		)
+		if err := config.CheckTopicSetConsistency(topicConfig, members); err != nil {
+			return exitcode.Wrap(exitcode.KindValidation, err)
+		}
+		for _, member := range members {
+			applierConfig.TopicSetMembers = append(
+				applierConfig.TopicSetMembers,
+				member.Meta.Name,
+			)
+		}
+	}
+
+	applier, err := NewTopicApplier(ctx, e.adminClient, applierConfig)
+	if err != nil {
+		return err
+	}
+	return applier.Apply(ctx)
+}
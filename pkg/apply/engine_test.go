@@ -0,0 +1,90 @@
+package apply
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnginePlanExecute(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clusterConfig := config.ClusterConfig{
+		Meta: config.ClusterMeta{
+			Name:        "test-cluster",
+			Region:      "test-region",
+			Environment: "test-environment",
+		},
+		Spec: config.ClusterSpec{
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKLockPath:     "/topicctl/locks",
+		},
+	}
+	adminClient, err := clusterConfig.NewAdminClient(ctx, nil, false)
+	require.NoError(t, err)
+	defer adminClient.Close()
+
+	topicName := util.RandomString("engine-topic-", 6)
+	topicConfig := config.TopicConfig{
+		Meta: config.TopicMeta{
+			Name:        topicName,
+			Cluster:     "test-cluster",
+			Region:      "test-region",
+			Environment: "test-environment",
+		},
+		Spec: config.TopicSpec{
+			Partitions:        3,
+			ReplicationFactor: 2,
+			RetentionMinutes:  100,
+			PlacementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyAny,
+			},
+		},
+	}
+
+	confirmed := []ApprovalCategory{}
+	engine := NewEngine(
+		adminClient,
+		clusterConfig,
+		EngineOptions{
+			Confirmer: func(prompt string, category ApprovalCategory) (bool, error) {
+				confirmed = append(confirmed, category)
+				return true, nil
+			},
+			SleepLoopTime: 500 * time.Millisecond,
+		},
+	)
+
+	changes, err := engine.Diff(ctx, topicConfig)
+	require.NoError(t, err)
+	assert.True(t, changes.Created)
+
+	plan, err := engine.Plan(ctx, []config.TopicConfig{topicConfig}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, len(plan.Topics))
+	assert.True(t, plan.Topics[0].Created)
+	assert.Equal(t, []ApprovalCategory{}, confirmed)
+
+	report, err := engine.Execute(ctx, []config.TopicConfig{topicConfig}, plan)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(report.Topics))
+	assert.True(t, report.Topics[0].Created)
+	assert.Contains(t, confirmed, ApprovalCategoryCreate)
+
+	changes, err = engine.Diff(ctx, topicConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changes.NumChanges())
+
+	// Topics that aren't in the plan can't be executed
+	otherConfig := topicConfig
+	otherConfig.Meta.Name = util.RandomString("engine-topic-", 6)
+	_, err = engine.Execute(ctx, []config.TopicConfig{otherConfig}, plan)
+	assert.Error(t, err)
+}
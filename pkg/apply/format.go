@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/topicctl/pkg/config"
@@ -25,49 +28,36 @@ func FormatNewTopicConfig(config kafka.TopicConfig) string {
 	return string(content)
 }
 
-// FormatSettingsDiff generates a table that summarizes the differences between
-// the topic settings from a topic config and the settings from ZK.
+// FormatSettingsDiff generates a unified, diff-style summary of the differences between
+// the topic settings from a topic config and the settings from ZK. A changed key is shown as
+// a removed line with its current value followed by an added line with its new one; keys
+// that are only set on one side get a single line. The lines are colored unless colors are
+// disabled, e.g. because the output isn't going to a terminal.
 func FormatSettingsDiff(
 	topicSettings config.TopicSettings,
 	configMap map[string]string,
 	diffKeys []string,
 ) (string, error) {
-	buf := &bytes.Buffer{}
-
-	table := tablewriter.NewWriter(buf)
+	headerColor := color.New(color.Bold)
+	removedColor := color.New(color.FgRed)
+	addedColor := color.New(color.FgGreen)
 
-	headers := []string{
-		"Key",
-		"Cluster Value (Curr)",
-		"Config Value (New)",
+	lines := []string{
+		headerColor.Sprint("--- cluster (current)"),
+		headerColor.Sprint("+++ config (new)"),
 	}
 
-	table.SetHeader(headers)
-
-	table.SetAutoWrapText(false)
-	table.SetColumnAlignment(
-		[]int{
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-		},
-	)
-	table.SetBorders(
-		tablewriter.Border{
-			Left:   false,
-			Top:    true,
-			Right:  false,
-			Bottom: true,
-		},
-	)
+	sortedKeys := append([]string{}, diffKeys...)
+	sort.Strings(sortedKeys)
 
-	for _, diffKey := range diffKeys {
-		configValueStr := configMap[diffKey]
+	for _, diffKey := range sortedKeys {
+		configValueStr, inCluster := configMap[diffKey]
 
 		var valueStr string
 		var err error
 
-		if topicSettings.HasKey(diffKey) {
+		inConfig := topicSettings.HasKey(diffKey)
+		if inConfig {
 			valueStr, err = topicSettings.GetValueStr(diffKey)
 			if err != nil {
 				return "", err
@@ -80,17 +70,21 @@ func FormatSettingsDiff(
 			valueStr = fmt.Sprintf("%s%s", valueStr, timeSuffix(valueStr))
 		}
 
-		row := []string{
-			diffKey,
-			configValueStr,
-			valueStr,
+		if inCluster {
+			lines = append(
+				lines,
+				removedColor.Sprintf("- %s: %s", diffKey, configValueStr),
+			)
+		}
+		if inConfig {
+			lines = append(
+				lines,
+				addedColor.Sprintf("+ %s: %s", diffKey, valueStr),
+			)
 		}
-
-		table.Append(row)
 	}
 
-	table.Render()
-	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+	return strings.Join(lines, "\n"), nil
 }
 
 // FormatMissingKeys generates a table that summarizes the key/value pairs
@@ -146,6 +140,150 @@ func FormatMissingKeys(
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
+// FormatTopicBackups creates a pretty table that lists the argument topic backups.
+func FormatTopicBackups(backups []TopicBackup) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"ID",
+			"Created",
+			"Cluster",
+			"Topic",
+			"Partitions",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, backup := range backups {
+		table.Append(
+			[]string{
+				backup.ID,
+				backup.CreatedAt.Format(time.RFC3339),
+				backup.Cluster,
+				backup.Topic.Name,
+				fmt.Sprintf("%d", len(backup.Topic.Partitions)),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatConfigHistory creates a pretty table that shows the argument config history events
+// as a timeline.
+func FormatConfigHistory(topic string, events []ConfigHistoryEvent) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Time",
+			"Source",
+			"User",
+			"Changes",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+	table.SetRowLine(true)
+
+	topicResource := fmt.Sprintf("topic %s", topic)
+
+	for _, event := range events {
+		source := event.Source
+		if event.ID != "" {
+			source = fmt.Sprintf("%s %s", source, event.ID)
+		}
+		if event.Failed {
+			source = fmt.Sprintf("%s (failed)", source)
+		}
+
+		user := ""
+		if event.User != "" {
+			user = fmt.Sprintf("%s@%s", event.User, event.Host)
+		}
+
+		changeLines := []string{}
+		for _, change := range event.Changes {
+			field := change.Field
+			if change.Resource != topicResource {
+				field = fmt.Sprintf(
+					"%s %s",
+					strings.TrimPrefix(change.Resource, topicResource+" "),
+					field,
+				)
+			}
+
+			changeLines = append(
+				changeLines,
+				fmt.Sprintf(
+					"%s: %s -> %s",
+					field,
+					historyValue(change.Before),
+					historyValue(change.After),
+				),
+			)
+		}
+		if event.Source == HistorySourceBackup {
+			changeLines = append(changeLines, "State saved before a change")
+		}
+
+		table.Append(
+			[]string{
+				event.Time.UTC().Format(time.RFC3339),
+				source,
+				user,
+				strings.Join(changeLines, "\n"),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func historyValue(value string) string {
+	if value == "" {
+		return "(unset)"
+	}
+	return value
+}
+
 func timeSuffix(msStr string) string {
 	msInt, err := strconv.ParseInt(msStr, 10, 64)
 	if err != nil {
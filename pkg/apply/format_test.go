@@ -0,0 +1,45 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSettingsDiff(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = true
+	defer func() {
+		color.NoColor = origNoColor
+	}()
+
+	diff, err := FormatSettingsDiff(
+		config.TopicSettings{
+			"cleanup.policy": "compact",
+			"retention.ms":   7200000,
+			"segment.bytes":  1000000,
+		},
+		map[string]string{
+			"cleanup.policy":            "delete",
+			"retention.ms":              "3600000",
+			"min.cleanable.dirty.ratio": "0.5",
+		},
+		[]string{"segment.bytes", "retention.ms", "min.cleanable.dirty.ratio", "cleanup.policy"},
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		`--- cluster (current)
++++ config (new)
+- cleanup.policy: delete
++ cleanup.policy: compact
+- min.cleanable.dirty.ratio: 0.5
+- retention.ms: 3600000 (60 min)
++ retention.ms: 7200000 (120 min)
++ segment.bytes: 1000000`,
+		diff,
+	)
+}
@@ -0,0 +1,184 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/artifacts"
+)
+
+const (
+	// HistorySourceApply is the source of history events for apply runs.
+	HistorySourceApply = "apply"
+
+	// HistorySourceBackup is the source of history events for topic backups.
+	HistorySourceBackup = "backup"
+
+	// HistorySourceConfigChange is the source of history events for the config change that's
+	// recorded in the cluster.
+	HistorySourceConfigChange = "config-change"
+)
+
+// ConfigHistorySources are the places that the config history of a topic is assembled from.
+// Any of them can be empty to skip it.
+type ConfigHistorySources struct {
+	// ArtifactsDir is the root directory of the apply run artifacts.
+	ArtifactsDir string
+
+	// BackupDir is the directory of the topic backups. Only the backups for ClusterID, if set,
+	// are included.
+	BackupDir string
+	ClusterID string
+
+	// LastChange is the last config change to the topic that's recorded in the cluster.
+	LastChange *admin.ConfigChange
+}
+
+// ConfigHistoryEvent is a single entry in the config history of a topic.
+type ConfigHistoryEvent struct {
+	Time   time.Time
+	Source string
+	ID     string
+	User   string
+	Host   string
+
+	// Failed is set for apply runs that returned an error, which might only have made some
+	// of their changes.
+	Failed bool
+
+	// Changes are the config and assignment changes made by the event. They're empty for
+	// backups, which only record the state of the topic before a change.
+	Changes []admin.SnapshotDiff
+}
+
+// TopicConfigHistory returns the config and assignment changes to the argument topic that are
+// recorded in the argument sources, oldest first. Dry runs and apply runs that didn't change
+// the topic are skipped. The last config change in the cluster is also skipped if it was made
+// during one of the apply runs.
+func TopicConfigHistory(
+	topic string,
+	sources ConfigHistorySources,
+) ([]ConfigHistoryEvent, error) {
+	events := []ConfigHistoryEvent{}
+	lastChangeApplied := false
+
+	if sources.ArtifactsDir != "" {
+		runs, err := artifacts.LoadFinishedRuns(sources.ArtifactsDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, run := range runs {
+			if run.Summary.DryRun {
+				continue
+			}
+
+			diffs, err := runTopicDiffs(run, topic)
+			if err != nil {
+				return nil, err
+			}
+			if len(diffs) == 0 {
+				continue
+			}
+
+			events = append(
+				events,
+				ConfigHistoryEvent{
+					Time:    run.Summary.StartTime,
+					Source:  HistorySourceApply,
+					ID:      run.Summary.ID,
+					User:    run.Summary.User,
+					Host:    run.Summary.Host,
+					Failed:  !run.Summary.Succeeded,
+					Changes: diffs,
+				},
+			)
+
+			if sources.LastChange != nil &&
+				!sources.LastChange.Time.Before(run.Summary.StartTime) &&
+				!sources.LastChange.Time.After(run.Summary.EndTime) {
+				lastChangeApplied = true
+			}
+		}
+	}
+
+	if sources.BackupDir != "" {
+		backups, err := ListTopicBackups(sources.BackupDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, backup := range backups {
+			if backup.Topic.Name != topic ||
+				(sources.ClusterID != "" && backup.ClusterID != sources.ClusterID) {
+				continue
+			}
+
+			events = append(
+				events,
+				ConfigHistoryEvent{
+					Time:   backup.CreatedAt,
+					Source: HistorySourceBackup,
+					ID:     backup.ID,
+				},
+			)
+		}
+	}
+
+	if sources.LastChange != nil && !lastChangeApplied {
+		event := ConfigHistoryEvent{
+			Time:    sources.LastChange.Time,
+			Source:  HistorySourceConfigChange,
+			User:    sources.LastChange.User,
+			Host:    sources.LastChange.Host,
+			Changes: []admin.SnapshotDiff{},
+		}
+		for _, entry := range sources.LastChange.Entries {
+			event.Changes = append(
+				event.Changes,
+				admin.SnapshotDiff{
+					Resource: fmt.Sprintf("topic %s", topic),
+					Field:    fmt.Sprintf("config %s", entry.Key),
+					Before:   entry.Before,
+					After:    entry.After,
+				},
+			)
+		}
+		events = append(events, event)
+	}
+
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].Time.Before(events[b].Time)
+	})
+
+	return events, nil
+}
+
+// runTopicDiffs returns the differences between the before and after snapshots of the
+// argument topic in an apply run. The before snapshot is missing if the run created the
+// topic, and the after one is missing if it deleted it.
+func runTopicDiffs(run artifacts.FinishedRun, topic string) ([]admin.SnapshotDiff, error) {
+	before := admin.ClusterSnapshot{Topics: []admin.TopicInfo{}}
+	after := admin.ClusterSnapshot{Topics: []admin.TopicInfo{}}
+
+	for _, snapshot := range []struct {
+		suffix   string
+		snapshot *admin.ClusterSnapshot
+	}{
+		{suffix: "before", snapshot: &before},
+		{suffix: "after", snapshot: &after},
+	} {
+		topicInfo := admin.TopicInfo{}
+		ok, err := run.LoadSnapshot(fmt.Sprintf("topic-%s-%s", topic, snapshot.suffix), &topicInfo)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			snapshot.snapshot.Topics = append(snapshot.snapshot.Topics, topicInfo)
+		}
+	}
+
+	return admin.DiffSnapshots(before, after), nil
+}
@@ -0,0 +1,199 @@
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/artifacts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicConfigHistory(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "history")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	artifactsDir := filepath.Join(tempDir, "runs")
+	backupDir := filepath.Join(tempDir, "backups")
+
+	before := admin.TopicInfo{
+		Name:   "topic1",
+		Config: map[string]string{"retention.ms": "1000"},
+		Partitions: []admin.PartitionInfo{
+			{Topic: "topic1", ID: 0, Replicas: []int{1, 2}},
+		},
+	}
+	after := admin.TopicInfo{
+		Name:   "topic1",
+		Config: map[string]string{"retention.ms": "2000"},
+		Partitions: []admin.PartitionInfo{
+			{Topic: "topic1", ID: 0, Replicas: []int{2, 1}},
+		},
+	}
+
+	addRun := func(dryRun bool, snapshots map[string]admin.TopicInfo) artifacts.Summary {
+		run, err := artifacts.NewRun(artifactsDir, "apply", []string{}, dryRun)
+		require.NoError(t, err)
+		for name, topicInfo := range snapshots {
+			require.NoError(t, run.AddSnapshot(name, topicInfo))
+		}
+		require.NoError(t, run.Finish(nil))
+
+		runs, err := artifacts.LoadFinishedRuns(artifactsDir)
+		require.NoError(t, err)
+		for _, finishedRun := range runs {
+			if finishedRun.Dir == run.Dir {
+				return finishedRun.Summary
+			}
+		}
+		require.FailNow(t, "Run not found")
+		return artifacts.Summary{}
+	}
+
+	applyRun := addRun(
+		false,
+		map[string]admin.TopicInfo{
+			"topic-topic1-before": before,
+			"topic-topic1-after":  after,
+		},
+	)
+
+	// Dry runs, runs that didn't change the topic, and runs for other topics are skipped
+	addRun(
+		true,
+		map[string]admin.TopicInfo{
+			"topic-topic1-before": before,
+			"topic-topic1-after":  after,
+		},
+	)
+	addRun(
+		false,
+		map[string]admin.TopicInfo{
+			"topic-topic1-before": after,
+			"topic-topic1-after":  after,
+		},
+	)
+	addRun(
+		false,
+		map[string]admin.TopicInfo{
+			"topic-topic2-after": after,
+		},
+	)
+
+	backup := TopicBackup{
+		ID:        "test-cluster-topic1-20210301-120000",
+		CreatedAt: applyRun.StartTime.Add(-time.Minute),
+		Cluster:   "test-cluster",
+		ClusterID: "test-id",
+		Topic:     before,
+	}
+	_, err = backup.WriteFile(backupDir)
+	require.NoError(t, err)
+
+	otherBackup := backup
+	otherBackup.ID = "other-cluster-topic1-20210301-120000"
+	otherBackup.ClusterID = "other-id"
+	_, err = otherBackup.WriteFile(backupDir)
+	require.NoError(t, err)
+
+	applyChanges := []admin.SnapshotDiff{
+		{
+			Resource: "topic topic1",
+			Field:    "config retention.ms",
+			Before:   "1000",
+			After:    "2000",
+		},
+		{
+			Resource: "topic topic1 partition 0",
+			Field:    "replicas",
+			Before:   "1,2",
+			After:    "2,1",
+		},
+	}
+
+	// The last change happened during the apply run, so it isn't listed separately
+	events, err := TopicConfigHistory(
+		"topic1",
+		ConfigHistorySources{
+			ArtifactsDir: artifactsDir,
+			BackupDir:    backupDir,
+			ClusterID:    "test-id",
+			LastChange: &admin.ConfigChange{
+				Time: applyRun.StartTime,
+				Entries: []admin.ConfigChangeEntry{
+					{Key: "retention.ms", Before: "1000", After: "2000"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]ConfigHistoryEvent{
+			{
+				Time:   backup.CreatedAt,
+				Source: HistorySourceBackup,
+				ID:     backup.ID,
+			},
+			{
+				Time:    applyRun.StartTime,
+				Source:  HistorySourceApply,
+				ID:      applyRun.ID,
+				User:    applyRun.User,
+				Host:    applyRun.Host,
+				Changes: applyChanges,
+			},
+		},
+		events,
+	)
+
+	changeTime := applyRun.EndTime.Add(time.Hour)
+	events, err = TopicConfigHistory(
+		"topic1",
+		ConfigHistorySources{
+			ArtifactsDir: artifactsDir,
+			LastChange: &admin.ConfigChange{
+				Time: changeTime,
+				User: "test-user",
+				Host: "test-host",
+				Entries: []admin.ConfigChangeEntry{
+					{Key: "retention.ms", Before: "2000", After: "1000"},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]ConfigHistoryEvent{
+			{
+				Time:    applyRun.StartTime,
+				Source:  HistorySourceApply,
+				ID:      applyRun.ID,
+				User:    applyRun.User,
+				Host:    applyRun.Host,
+				Changes: applyChanges,
+			},
+			{
+				Time:   changeTime,
+				Source: HistorySourceConfigChange,
+				User:   "test-user",
+				Host:   "test-host",
+				Changes: []admin.SnapshotDiff{
+					{
+						Resource: "topic topic1",
+						Field:    "config retention.ms",
+						Before:   "2000",
+						After:    "1000",
+					},
+				},
+			},
+		},
+		events,
+	)
+}
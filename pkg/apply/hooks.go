@@ -0,0 +1,162 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultHookTimeout = time.Minute
+
+// HookPayload contains the details of a change that are passed to hooks.
+type HookPayload struct {
+	Phase       config.HookPhase `json:"phase"`
+	Event       config.HookEvent `json:"event"`
+	Topic       string           `json:"topic"`
+	Cluster     string           `json:"cluster"`
+	Environment string           `json:"environment"`
+	Region      string           `json:"region"`
+}
+
+// Env returns the environment variables that are set when running command hooks.
+func (p HookPayload) Env() []string {
+	return []string{
+		fmt.Sprintf("TOPICCTL_HOOK_PHASE=%s", p.Phase),
+		fmt.Sprintf("TOPICCTL_HOOK_EVENT=%s", p.Event),
+		fmt.Sprintf("TOPICCTL_HOOK_TOPIC=%s", p.Topic),
+		fmt.Sprintf("TOPICCTL_HOOK_CLUSTER=%s", p.Cluster),
+		fmt.Sprintf("TOPICCTL_HOOK_ENVIRONMENT=%s", p.Environment),
+		fmt.Sprintf("TOPICCTL_HOOK_REGION=%s", p.Region),
+	}
+}
+
+// RunHook runs a single command or webhook hook. An error is returned if the command exits
+// with a non-zero status or the webhook responds with a non-2xx status.
+func RunHook(ctx context.Context, hook config.HookConfig, payload HookPayload) error {
+	timeout := defaultHookTimeout
+	if hook.TimeoutSeconds > 0 {
+		timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if len(hook.Command) > 0 {
+		return runCommandHook(ctx, hook, payload)
+	} else if hook.URL != "" {
+		return runWebhook(ctx, hook, payload)
+	}
+	return errors.New("Hook has no command or url")
+}
+
+func runCommandHook(ctx context.Context, hook config.HookConfig, payload HookPayload) error {
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Env = append(os.Environ(), payload.Env()...)
+
+	output, err := cmd.CombinedOutput()
+	if outputStr := strings.TrimSpace(string(output)); outputStr != "" {
+		log.Infof("Output from hook %s:\n%s", hook.DisplayName(), outputStr)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("Timed out running %s", strings.Join(hook.Command, " "))
+	}
+	return err
+}
+
+func runWebhook(ctx context.Context, hook config.HookConfig, payload HookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf(
+			"Webhook returned status %d: %s",
+			resp.StatusCode,
+			strings.TrimSpace(string(respBody)),
+		)
+	}
+
+	return nil
+}
+
+// runHooks runs the cluster and topic hooks for the argument phase and event in order. If a
+// hook fails, the remaining ones are skipped and an error is returned, unless the hook is
+// configured to continue on errors.
+func (t *TopicApplier) runHooks(
+	ctx context.Context,
+	phase config.HookPhase,
+	event config.HookEvent,
+) error {
+	payload := HookPayload{
+		Phase:       phase,
+		Event:       event,
+		Topic:       t.topicName,
+		Cluster:     t.clusterConfig.Meta.Name,
+		Environment: t.clusterConfig.Meta.Environment,
+		Region:      t.clusterConfig.Meta.Region,
+	}
+
+	for _, hook := range config.MatchingHooks(t.clusterConfig, t.topicConfig, phase, event) {
+		log.Infof("Running %s-%s hook %s", phase, event, hook.DisplayName())
+
+		if err := RunHook(ctx, hook, payload); err != nil {
+			if hook.ContinueOnError {
+				log.Warnf(
+					"Hook %s (%s-%s) failed, continuing: %+v",
+					hook.DisplayName(),
+					phase,
+					event,
+					err,
+				)
+				continue
+			}
+			return fmt.Errorf(
+				"Hook %s (%s-%s) failed: %+v",
+				hook.DisplayName(),
+				phase,
+				event,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// startMigration runs the pre-migrate hooks the first time that the partitions of an
+// existing topic are about to be changed. The post-migrate hooks are run at the end of the
+// apply if this was called.
+func (t *TopicApplier) startMigration(ctx context.Context) error {
+	if t.migrationStarted {
+		return nil
+	}
+	if err := t.runHooks(ctx, config.HookPhasePre, config.HookEventMigrate); err != nil {
+		return err
+	}
+	t.migrationStarted = true
+	return nil
+}
@@ -0,0 +1,104 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandHook(t *testing.T) {
+	ctx := context.Background()
+	tempDir, err := ioutil.TempDir("", "hooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	outputPath := filepath.Join(tempDir, "output.txt")
+
+	payload := HookPayload{
+		Phase:   config.HookPhasePre,
+		Event:   config.HookEventCreate,
+		Topic:   "test-topic",
+		Cluster: "test-cluster",
+	}
+
+	err = RunHook(
+		ctx,
+		config.HookConfig{
+			Phase: config.HookPhasePre,
+			Command: []string{
+				"sh",
+				"-c",
+				"echo $TOPICCTL_HOOK_PHASE $TOPICCTL_HOOK_EVENT $TOPICCTL_HOOK_TOPIC > " + outputPath,
+			},
+		},
+		payload,
+	)
+	require.NoError(t, err)
+
+	contents, err := ioutil.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "pre create test-topic\n", string(contents))
+
+	err = RunHook(
+		ctx,
+		config.HookConfig{
+			Phase:   config.HookPhasePre,
+			Command: []string{"sh", "-c", "exit 1"},
+		},
+		payload,
+	)
+	assert.Error(t, err)
+
+	err = RunHook(
+		ctx,
+		config.HookConfig{
+			Phase:          config.HookPhasePre,
+			Command:        []string{"sleep", "5"},
+			TimeoutSeconds: 1,
+		},
+		payload,
+	)
+	assert.Error(t, err)
+}
+
+func TestRunWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	var received HookPayload
+	status := http.StatusOK
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(status)
+		}),
+	)
+	defer server.Close()
+
+	hook := config.HookConfig{
+		Phase: config.HookPhasePost,
+		URL:   server.URL,
+	}
+	payload := HookPayload{
+		Phase:       config.HookPhasePost,
+		Event:       config.HookEventMigrate,
+		Topic:       "test-topic",
+		Cluster:     "test-cluster",
+		Environment: "test-env",
+	}
+
+	require.NoError(t, RunHook(ctx, hook, payload))
+	assert.Equal(t, payload, received)
+
+	status = http.StatusConflict
+	assert.Error(t, RunHook(ctx, hook, payload))
+}
@@ -0,0 +1,151 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkLifecycle logs a warning if the topic is deprecated or retiring, and returns whether
+// its deletion date has passed at the argument time.
+func (t *TopicApplier) checkLifecycle(now time.Time) (bool, error) {
+	if warning := t.topicConfig.Meta.LifecycleWarning(); warning != "" {
+		log.Warn(warning)
+	}
+	return t.topicConfig.Meta.DeletionDue(now)
+}
+
+// deleteRetiredTopic deletes a retiring topic that's past its deletion date and returns
+// whether it was (or, in dry-run mode, would have been) deleted. Deletion is only done if
+// DeleteRetired is set in the config; otherwise, this just logs a warning.
+func (t *TopicApplier) deleteRetiredTopic(ctx context.Context) (bool, error) {
+	deletionDate := t.topicConfig.Meta.DeletionDate
+
+	if !t.config.DeleteRetired {
+		log.Warnf(
+			"Topic %s is past its deletion date (%s); re-run with --delete-retired to delete it",
+			t.topicName,
+			deletionDate,
+		)
+		return false, nil
+	}
+
+	if t.config.DryRun {
+		log.Infof("Would delete topic %s, which is past its deletion date (%s)", t.topicName, deletionDate)
+		t.changes.Deleted = true
+		return true, nil
+	}
+
+	log.Warnf(
+		"Topic %s is past its deletion date (%s); deleting it will permanently remove all of its data",
+		t.topicName,
+		deletionDate,
+	)
+
+	if err := t.checkPlanned(&TopicChanges{Deleted: true}); err != nil {
+		return false, err
+	}
+
+	ok, err := t.confirm(fmt.Sprintf("OK to delete topic %s?", t.topicName), ApprovalCategoryDelete)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, errors.New("Stopping because of user response")
+	}
+
+	if err := t.runHooks(ctx, config.HookPhasePre, config.HookEventDelete); err != nil {
+		return false, err
+	}
+
+	log.Infof("Deleting topic %s", t.topicName)
+	if err := t.adminClient.DeleteTopic(ctx, t.topicName); err != nil {
+		return false, err
+	}
+	t.changes.Deleted = true
+
+	// ACLs outlive their topics, so remove the produce block in case the topic name is reused
+	if t.topicConfig.Meta.BlockProduce {
+		if err := t.adminClient.UnblockTopicWrites(ctx, t.topicName); err != nil {
+			log.Warnf("Could not remove the produce block for topic %s: %+v", t.topicName, err)
+		}
+	}
+
+	return true, t.runHooks(ctx, config.HookPhasePost, config.HookEventDelete)
+}
+
+// updateProduceBlock adds or removes the ACL that blocks produce requests to the topic so
+// that it matches the config. Only deprecated and retiring topics are checked.
+func (t *TopicApplier) updateProduceBlock(ctx context.Context) error {
+	if t.topicConfig.Meta.LifecycleState() == config.TopicLifecycleActive {
+		return nil
+	}
+
+	log.Info("Checking produce block...")
+	desired := t.topicConfig.Meta.ProduceBlocked()
+
+	blocked, err := t.adminClient.TopicWritesBlocked(ctx, t.topicName)
+	if err != nil {
+		if !desired {
+			// Clusters without an authorizer can't have a block
+			log.Warnf("Could not check produce block for topic %s: %+v", t.topicName, err)
+			return nil
+		}
+		return err
+	}
+
+	if blocked == desired {
+		log.Info("Produce block looks good")
+		return nil
+	}
+
+	action := "block"
+	if !desired {
+		action = "unblock"
+	}
+
+	if t.config.DryRun {
+		log.Infof("Would %s produce requests to topic %s", action, t.topicName)
+		t.changes.BlockedProduce = desired
+		t.changes.UnblockedProduce = !desired
+		return nil
+	}
+
+	err = t.checkPlanned(
+		&TopicChanges{
+			BlockedProduce:   desired,
+			UnblockedProduce: !desired,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	ok, err := t.confirm(
+		fmt.Sprintf("OK to %s produce requests to topic %s?", action, t.topicName),
+		ApprovalCategoryDelete,
+	)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if desired {
+		err = t.adminClient.BlockTopicWrites(ctx, t.topicName)
+	} else {
+		err = t.adminClient.UnblockTopicWrites(ctx, t.topicName)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.changes.BlockedProduce = desired
+	t.changes.UnblockedProduce = !desired
+	return nil
+}
@@ -0,0 +1,427 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/assigners"
+	log "github.com/sirupsen/logrus"
+)
+
+// MaintenanceApplierConfig contains the configuration for a MaintenanceApplier struct.
+type MaintenanceApplierConfig struct {
+	BrokerID      int
+	DryRun        bool
+	Enable        bool
+	Reason        string
+	SkipConfirm   bool
+	SleepLoopTime time.Duration
+}
+
+// MaintenanceApplier puts a broker into or takes it out of maintenance mode. Starting
+// maintenance moves the preferred leadership of all partitions led by the broker to other
+// replicas and then runs leader elections; no replicas are moved. The maintenance state is
+// recorded in zookeeper so that subsequent applies and rebalances don't make the broker a
+// leader again. Ending maintenance clears this state and restores the leaders that were
+// moved.
+type MaintenanceApplier struct {
+	config      MaintenanceApplierConfig
+	adminClient *admin.Client
+	brokers     []admin.BrokerInfo
+}
+
+// leaderMove contains the current and desired assignments for the partitions in a single
+// topic that are getting new preferred leaders.
+type leaderMove struct {
+	topic   string
+	curr    []admin.PartitionAssignment
+	desired []admin.PartitionAssignment
+}
+
+func newLeaderMove(
+	topic string,
+	curr []admin.PartitionAssignment,
+	desired []admin.PartitionAssignment,
+) (leaderMove, bool) {
+	move := leaderMove{
+		topic:   topic,
+		desired: admin.AssignmentsToUpdate(curr, desired),
+	}
+	for _, assignment := range move.desired {
+		move.curr = append(move.curr, curr[assignment.ID])
+	}
+
+	return move, len(move.desired) > 0
+}
+
+// NewMaintenanceApplier creates and returns a new MaintenanceApplier instance.
+func NewMaintenanceApplier(
+	ctx context.Context,
+	adminClient *admin.Client,
+	applierConfig MaintenanceApplierConfig,
+) (*MaintenanceApplier, error) {
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaintenanceApplier{
+		config:      applierConfig,
+		adminClient: adminClient,
+		brokers:     brokers,
+	}, nil
+}
+
+// Apply starts or ends maintenance on the configured broker.
+func (m *MaintenanceApplier) Apply(ctx context.Context) error {
+	found := false
+	for _, broker := range m.brokers {
+		if broker.ID == m.config.BrokerID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("Broker %d is not in the cluster", m.config.BrokerID)
+	}
+
+	maintenances, err := m.adminClient.GetBrokersInMaintenance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if m.config.Enable {
+		return m.startMaintenance(ctx, maintenances)
+	}
+	return m.endMaintenance(ctx, maintenances)
+}
+
+func (m *MaintenanceApplier) startMaintenance(
+	ctx context.Context,
+	maintenances []admin.BrokerMaintenance,
+) error {
+	maintenanceIDs := admin.MaintenanceBrokerIDs(maintenances)
+	for _, brokerID := range maintenanceIDs {
+		if brokerID == m.config.BrokerID {
+			return fmt.Errorf("Broker %d is already in maintenance", m.config.BrokerID)
+		}
+	}
+
+	log.Infof("Checking leaders on broker %d...", m.config.BrokerID)
+
+	topics, err := m.adminClient.GetTopics(ctx, nil, true)
+	if err != nil {
+		return err
+	}
+
+	preferredLeaders := MaintenancePreferredLeaders(
+		admin.BrokerIDs(m.brokers),
+		append(maintenanceIDs, m.config.BrokerID),
+	)
+	moves := []leaderMove{}
+	movedLeaders := map[string][]int{}
+	stuckPartitions := []admin.PartitionInfo{}
+
+	for _, topic := range topics {
+		curr := topic.ToAssignments()
+		desired := assigners.ApplyLeaderPreferences(curr, preferredLeaders)
+
+		for _, partition := range topic.Partitions {
+			if len(partition.Replicas) > 0 &&
+				partition.Replicas[0] == m.config.BrokerID &&
+				desired[partition.ID].Replicas[0] == m.config.BrokerID {
+				stuckPartitions = append(stuckPartitions, partition)
+			}
+		}
+
+		if move, ok := newLeaderMove(topic.Name, curr, desired); ok {
+			moves = append(moves, move)
+			movedLeaders[topic.Name] = admin.NewLeaderPartitions(move.curr, move.desired)
+		}
+	}
+
+	if len(stuckPartitions) > 0 {
+		log.Warnf(
+			"The following partitions have no other replicas that can take over leadership:\n%s",
+			admin.FormatTopicPartitions(stuckPartitions, m.brokers),
+		)
+	}
+
+	m.logMoves(moves)
+
+	if m.config.DryRun {
+		log.Infof("Skipping update because dryRun is set to true")
+		return nil
+	}
+
+	ok, _ := Confirm(
+		fmt.Sprintf("OK to start maintenance on broker %d?", m.config.BrokerID),
+		m.config.SkipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	// Record the maintenance before moving anything so that any applies that run in the
+	// meantime don't move leadership back.
+	err = m.adminClient.StartBrokerMaintenance(
+		ctx,
+		admin.BrokerMaintenance{
+			BrokerID:     m.config.BrokerID,
+			User:         maintenanceUser(),
+			Host:         maintenanceHost(),
+			Reason:       m.config.Reason,
+			StartTime:    time.Now().UTC(),
+			MovedLeaders: movedLeaders,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := m.applyMoves(ctx, moves); err != nil {
+		return err
+	}
+
+	log.Infof("Broker %d is now in maintenance", m.config.BrokerID)
+	return nil
+}
+
+func (m *MaintenanceApplier) endMaintenance(
+	ctx context.Context,
+	maintenances []admin.BrokerMaintenance,
+) error {
+	var maintenance *admin.BrokerMaintenance
+	for _, curr := range maintenances {
+		if curr.BrokerID == m.config.BrokerID {
+			maintenance = &curr
+			break
+		}
+	}
+	if maintenance == nil {
+		return fmt.Errorf("Broker %d is not in maintenance", m.config.BrokerID)
+	}
+
+	log.Infof(
+		"Broker %d has been in maintenance since %s (started by %s@%s, reason: %q)",
+		maintenance.BrokerID,
+		maintenance.StartTime.Format(time.RFC3339),
+		maintenance.User,
+		maintenance.Host,
+		maintenance.Reason,
+	)
+
+	topicNames := []string{}
+	for topicName := range maintenance.MovedLeaders {
+		topicNames = append(topicNames, topicName)
+	}
+	sort.Strings(topicNames)
+
+	moves := []leaderMove{}
+
+	if len(topicNames) > 0 {
+		topics, err := m.adminClient.GetTopics(ctx, topicNames, true)
+		if err != nil {
+			return err
+		}
+
+		for _, topic := range topics {
+			curr := topic.ToAssignments()
+			desired := RestoreLeaders(
+				curr,
+				m.config.BrokerID,
+				maintenance.MovedLeaders[topic.Name],
+			)
+			if move, ok := newLeaderMove(topic.Name, curr, desired); ok {
+				moves = append(moves, move)
+			}
+		}
+	}
+
+	m.logMoves(moves)
+
+	if m.config.DryRun {
+		log.Infof("Skipping update because dryRun is set to true")
+		return nil
+	}
+
+	ok, _ := Confirm(
+		fmt.Sprintf("OK to end maintenance on broker %d?", m.config.BrokerID),
+		m.config.SkipConfirm,
+	)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	if err := m.adminClient.EndBrokerMaintenance(ctx, m.config.BrokerID); err != nil {
+		return err
+	}
+
+	if err := m.applyMoves(ctx, moves); err != nil {
+		return err
+	}
+
+	log.Infof("Broker %d is no longer in maintenance", m.config.BrokerID)
+	return nil
+}
+
+func (m *MaintenanceApplier) logMoves(moves []leaderMove) {
+	if len(moves) == 0 {
+		log.Infof("No partition leaders need to be moved")
+		return
+	}
+
+	for _, move := range moves {
+		log.Infof(
+			"Here are the proposed leader changes for topic %s:\n%s",
+			move.topic,
+			admin.FormatAssignentDiffs(move.curr, move.desired, m.brokers),
+		)
+	}
+}
+
+// applyMoves updates the replica orderings for each topic and then runs leader elections
+// for the partitions whose preferred leaders changed. Topics are updated one at a time since
+// only one reassignment and election can be in progress in the cluster.
+func (m *MaintenanceApplier) applyMoves(ctx context.Context, moves []leaderMove) error {
+	for _, move := range moves {
+		electionPartitions := admin.NewLeaderPartitions(move.curr, move.desired)
+
+		if err := m.waitUntilIdle(ctx); err != nil {
+			return err
+		}
+
+		log.Infof("Updating replica order for topic %s", move.topic)
+		if err := m.adminClient.AssignPartitions(ctx, move.topic, move.desired); err != nil {
+			return err
+		}
+		if err := m.waitUntilIdle(ctx); err != nil {
+			return err
+		}
+
+		if len(electionPartitions) == 0 {
+			continue
+		}
+
+		log.Infof(
+			"Running leader elections for topic %s, partitions %+v",
+			move.topic,
+			electionPartitions,
+		)
+		err := m.adminClient.RunLeaderElection(ctx, move.topic, electionPartitions)
+		if err != nil {
+			return err
+		}
+		if err := m.waitUntilIdle(ctx); err != nil {
+			return err
+		}
+
+		topicInfo, err := m.adminClient.GetTopic(ctx, move.topic, true)
+		if err != nil {
+			return err
+		}
+		wrongLeaders := topicInfo.WrongLeaderPartitions(electionPartitions)
+		if len(wrongLeaders) > 0 {
+			log.Warnf(
+				"The following partitions in topic %s did not get their new leaders, possibly because the replicas are out-of-sync:\n%s",
+				move.topic,
+				admin.FormatTopicPartitions(wrongLeaders, m.brokers),
+			)
+		}
+	}
+
+	return nil
+}
+
+// waitUntilIdle waits until there are no reassignments or leader elections in progress.
+func (m *MaintenanceApplier) waitUntilIdle(ctx context.Context) error {
+	for {
+		assignmentInProgress, err := m.adminClient.AssignmentInProgress(ctx)
+		if err != nil {
+			return err
+		}
+		electionInProgress, err := m.adminClient.ElectionInProgress(ctx)
+		if err != nil {
+			return err
+		}
+		if !assignmentInProgress && !electionInProgress {
+			return nil
+		}
+
+		log.Infof(
+			"Reassignment or election in progress, sleeping for %s",
+			m.config.SleepLoopTime.String(),
+		)
+		if err := interruptableSleep(ctx, m.config.SleepLoopTime); err != nil {
+			return err
+		}
+	}
+}
+
+// MaintenancePreferredLeaders returns the argument broker IDs that can be preferred leaders,
+// i.e. the ones that aren't in maintenance.
+func MaintenancePreferredLeaders(brokerIDs []int, maintenanceIDs []int) []int {
+	preferred := []int{}
+
+	for _, brokerID := range brokerIDs {
+		inMaintenance := false
+		for _, maintenanceID := range maintenanceIDs {
+			if brokerID == maintenanceID {
+				inMaintenance = true
+				break
+			}
+		}
+		if !inMaintenance {
+			preferred = append(preferred, brokerID)
+		}
+	}
+
+	return preferred
+}
+
+// RestoreLeaders returns a copy of the argument assignments in which the argument broker is
+// moved back into the leader position for each of the argument partitions. Partitions that
+// no longer have a replica on the broker are left as-is.
+func RestoreLeaders(
+	assignments []admin.PartitionAssignment,
+	brokerID int,
+	partitions []int,
+) []admin.PartitionAssignment {
+	updated := admin.CopyAssignments(assignments)
+
+	for _, partition := range partitions {
+		if partition < 0 || partition >= len(updated) {
+			continue
+		}
+
+		replicas := updated[partition].Replicas
+		index := updated[partition].Index(brokerID)
+		if index > 0 {
+			replicas[0], replicas[index] = replicas[index], replicas[0]
+		}
+	}
+
+	return updated
+}
+
+func maintenanceUser() string {
+	currUser, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+	return currUser.Username
+}
+
+func maintenanceHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
@@ -0,0 +1,51 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenancePreferredLeaders(t *testing.T) {
+	assert.Equal(
+		t,
+		[]int{1, 3, 5},
+		MaintenancePreferredLeaders([]int{1, 2, 3, 4, 5}, []int{2, 4}),
+	)
+	assert.Equal(
+		t,
+		[]int{1, 2, 3},
+		MaintenancePreferredLeaders([]int{1, 2, 3}, nil),
+	)
+	assert.Equal(
+		t,
+		[]int{},
+		MaintenancePreferredLeaders([]int{1, 2}, []int{1, 2}),
+	)
+}
+
+func TestRestoreLeaders(t *testing.T) {
+	curr := []admin.PartitionAssignment{
+		{ID: 0, Replicas: []int{2, 1, 3}},
+		{ID: 1, Replicas: []int{3, 2, 1}},
+		{ID: 2, Replicas: []int{3, 4, 2}},
+		{ID: 3, Replicas: []int{2, 3, 4}},
+	}
+
+	assert.Equal(
+		t,
+		[]admin.PartitionAssignment{
+			{ID: 0, Replicas: []int{1, 2, 3}},
+			{ID: 1, Replicas: []int{1, 2, 3}},
+			// Broker 1 is no longer a replica
+			{ID: 2, Replicas: []int{3, 4, 2}},
+			// Partition wasn't moved
+			{ID: 3, Replicas: []int{2, 3, 4}},
+		},
+		RestoreLeaders(curr, 1, []int{0, 1, 2, 7}),
+	)
+
+	// Argument assignments aren't modified
+	assert.Equal(t, []int{2, 1, 3}, curr[0].Replicas)
+}
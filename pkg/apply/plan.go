@@ -0,0 +1,404 @@
+package apply
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/exitcode"
+)
+
+const (
+	// PlanKeyEnvVar is the environment variable that, if set, contains the key used to sign
+	// and verify plans. Without it, plans are only hashed, which catches accidental edits
+	// but not deliberate ones.
+	PlanKeyEnvVar = "TOPICCTL_PLAN_KEY"
+
+	planVersion    = 1
+	planHashPrefix = "sha256:"
+	planHMACPrefix = "hmac-sha256:"
+)
+
+// Plan is a reviewed set of topic changes that can be carried out later. It's produced
+// by a dry-run apply and records the state of each topic when the plan was made, along with
+// the configs and options that the changes were computed from, so that an execution can
+// verify that it's doing exactly what was reviewed.
+type Plan struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Configs are the absolute paths of the topic configs that were applied, in order.
+	Configs []string `json:"configs"`
+
+	// Files contains the SHA-256 hash of each topic config, cluster config, and metrics
+	// file that was read, keyed by absolute path.
+	Files map[string]string `json:"files"`
+
+	Options PlanOptions     `json:"options"`
+	Topics  []*TopicChanges `json:"topics"`
+
+	// Hash is the hash (or, if a key is set, HMAC) of the rest of the plan.
+	Hash string `json:"hash"`
+}
+
+// PlanOptions are the apply options that affect which changes are made.
+type PlanOptions struct {
+	AllowCleanupPolicyChange bool   `json:"allowCleanupPolicyChange"`
+	AllowRepartitioning      bool   `json:"allowRepartitioning"`
+	AllowRetentionReduction  bool   `json:"allowRetentionReduction"`
+	BrokersToRemove          []int  `json:"brokersToRemove"`
+	DeleteRetired            bool   `json:"deleteRetired"`
+	FixRackViolations        bool   `json:"fixRackViolations"`
+	PartitionMetrics         string `json:"partitionMetrics,omitempty"`
+	Rebalance                bool   `json:"rebalance"`
+}
+
+// NewPlan returns a new, empty plan with the argument options.
+func NewPlan(options PlanOptions, now time.Time) *Plan {
+	return &Plan{
+		Version:   planVersion,
+		CreatedAt: now.UTC(),
+		Configs:   []string{},
+		Files:     map[string]string{},
+		Options:   options,
+		Topics:    []*TopicChanges{},
+	}
+}
+
+// LoadPlanFile loads a plan from the argument path and verifies its hash.
+func LoadPlanFile(path string, key []byte) (*Plan, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	if err := json.Unmarshal(contents, plan); err != nil {
+		return nil, fmt.Errorf("Could not parse plan %s: %+v", path, err)
+	}
+	if plan.Version != planVersion {
+		return nil, fmt.Errorf(
+			"Plan %s has version %d; this version of topicctl only supports %d",
+			path,
+			plan.Version,
+			planVersion,
+		)
+	}
+	if err := plan.Verify(key); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// AddFile records the hash of the argument file in the plan.
+func (p *Plan) AddFile(path string) error {
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	p.Files[path] = hash
+	return nil
+}
+
+// CheckFiles returns an error if any of the files recorded in the plan have changed since
+// it was made.
+func (p *Plan) CheckFiles() error {
+	paths := []string{}
+	for path := range p.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		if hash != p.Files[path] {
+			return exitcode.Wrap(
+				exitcode.KindDrift,
+				fmt.Errorf("File %s has changed since the plan was made", path),
+			)
+		}
+	}
+
+	return nil
+}
+
+// TopicChanges returns the planned changes for the argument topic and cluster, or nil if
+// the topic isn't in the plan.
+func (p *Plan) TopicChanges(topic string, cluster string) *TopicChanges {
+	for _, topicChanges := range p.Topics {
+		if topicChanges.Topic == topic && topicChanges.Cluster == cluster {
+			return topicChanges
+		}
+	}
+	return nil
+}
+
+// Sign sets the hash of the plan, using an HMAC if the argument key is non-empty.
+func (p *Plan) Sign(key []byte) error {
+	hash, err := p.computeHash(key)
+	if err != nil {
+		return err
+	}
+	p.Hash = hash
+	return nil
+}
+
+// Verify returns an error if the plan's hash doesn't match its contents. If a key is set,
+// then the plan must have been signed with the same key.
+func (p *Plan) Verify(key []byte) error {
+	if len(key) > 0 && !strings.HasPrefix(p.Hash, planHMACPrefix) {
+		return fmt.Errorf("Plan isn't signed, but %s is set", PlanKeyEnvVar)
+	} else if len(key) == 0 && strings.HasPrefix(p.Hash, planHMACPrefix) {
+		return fmt.Errorf("Plan is signed; set %s to verify it", PlanKeyEnvVar)
+	}
+
+	expected, err := p.computeHash(key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(p.Hash)) {
+		return errors.New("Plan hash doesn't match its contents; it may have been edited")
+	}
+	return nil
+}
+
+// WriteFile writes the plan as indented JSON to the argument path.
+func (p *Plan) WriteFile(path string) error {
+	contents, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+func (p *Plan) computeHash(key []byte) (string, error) {
+	unsigned := *p
+	unsigned.Hash = ""
+
+	contents, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) > 0 {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(contents)
+		return planHMACPrefix + hex.EncodeToString(mac.Sum(nil)), nil
+	}
+
+	sum := sha256.Sum256(contents)
+	return planHashPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// PlanKey returns the plan signing key from the environment, if any.
+func PlanKey() []byte {
+	return []byte(os.Getenv(PlanKeyEnvVar))
+}
+
+// StateFingerprint returns a hash of the parts of the cluster state that an apply of the
+// argument topic depends on: the brokers and their racks, and the topic's config and
+// replica assignments. The topic info is nil if the topic doesn't exist. Leaders and ISRs
+// aren't included since they change on their own.
+func StateFingerprint(topicInfo *admin.TopicInfo, brokers []admin.BrokerInfo) string {
+	type brokerState struct {
+		ID   int    `json:"id"`
+		Rack string `json:"rack"`
+	}
+	type topicState struct {
+		Config   map[string]string `json:"config"`
+		Replicas [][]int           `json:"replicas"`
+	}
+	state := struct {
+		Brokers []brokerState `json:"brokers"`
+		Topic   *topicState   `json:"topic"`
+	}{
+		Brokers: []brokerState{},
+	}
+
+	for _, broker := range brokers {
+		state.Brokers = append(state.Brokers, brokerState{ID: broker.ID, Rack: broker.Rack})
+	}
+	sort.Slice(state.Brokers, func(a, b int) bool {
+		return state.Brokers[a].ID < state.Brokers[b].ID
+	})
+
+	if topicInfo != nil {
+		state.Topic = &topicState{
+			Config:   topicInfo.Config,
+			Replicas: [][]int{},
+		}
+		for _, partition := range topicInfo.Partitions {
+			state.Topic.Replicas = append(state.Topic.Replicas, partition.Replicas)
+		}
+	}
+
+	// Maps are marshaled with sorted keys, so this is deterministic
+	contents, _ := json.Marshal(state)
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkPlanned returns an error if the current apply is executing a plan and the argument
+// changes aren't all in it.
+func (t *TopicApplier) checkPlanned(proposed *TopicChanges) error {
+	planned := t.config.PlannedChanges
+	if planned == nil {
+		return nil
+	}
+
+	unplanned := []string{}
+
+	if proposed.Created && !planned.Created {
+		unplanned = append(unplanned, "create topic")
+	}
+	if proposed.Deleted && !planned.Deleted {
+		unplanned = append(unplanned, "delete topic")
+	}
+	if proposed.BlockedProduce && !planned.BlockedProduce {
+		unplanned = append(unplanned, "block produce requests")
+	}
+	if proposed.UnblockedProduce && !planned.UnblockedProduce {
+		unplanned = append(unplanned, "unblock produce requests")
+	}
+	for _, change := range proposed.ConfigChanges {
+		if !inPlan(change, planned.ConfigChanges) {
+			unplanned = append(
+				unplanned,
+				fmt.Sprintf("set %s from '%s' to '%s'", change.Key, change.OldValue, change.NewValue),
+			)
+		}
+	}
+	for _, assignment := range proposed.AddedPartitions {
+		if !inPlan(assignment, planned.AddedPartitions) {
+			unplanned = append(
+				unplanned,
+				fmt.Sprintf("add partition %d on %+v", assignment.ID, assignment.Replicas),
+			)
+		}
+	}
+	for _, reassignment := range proposed.Reassignments {
+		if !inPlan(reassignment, planned.Reassignments) {
+			unplanned = append(
+				unplanned,
+				fmt.Sprintf(
+					"move partition %d from %+v to %+v",
+					reassignment.Partition,
+					reassignment.OldReplicas,
+					reassignment.NewReplicas,
+				),
+			)
+		}
+	}
+
+	if len(unplanned) > 0 {
+		return exitcode.Wrap(
+			exitcode.KindDrift,
+			fmt.Errorf(
+				"The apply would make changes that aren't in the plan; re-run with --plan-only to make a new one:\n  %s",
+				strings.Join(unplanned, "\n  "),
+			),
+		)
+	}
+	return nil
+}
+
+// checkPlanState returns an error if the current apply is executing a plan and the state of
+// the topic has changed since the plan was made.
+func (t *TopicApplier) checkPlanState(topicInfo *admin.TopicInfo) error {
+	t.changes.StateFingerprint = StateFingerprint(topicInfo, t.brokers)
+
+	planned := t.config.PlannedChanges
+	if planned == nil || planned.StateFingerprint == t.changes.StateFingerprint {
+		return nil
+	}
+
+	return exitcode.Wrap(
+		exitcode.KindDrift,
+		fmt.Errorf(
+			"The brokers or the state of topic %s have changed since the plan was made; re-run with --plan-only to make a new one",
+			t.topicName,
+		),
+	)
+}
+
+// checkPlanComplete returns an error if the current apply is executing a plan and didn't
+// make all of the planned changes.
+func (t *TopicApplier) checkPlanComplete() error {
+	planned := t.config.PlannedChanges
+	if planned == nil {
+		return nil
+	}
+
+	missing := 0
+	for _, change := range [][2]bool{
+		{planned.Created, t.changes.Created},
+		{planned.Deleted, t.changes.Deleted},
+		{planned.BlockedProduce, t.changes.BlockedProduce},
+		{planned.UnblockedProduce, t.changes.UnblockedProduce},
+	} {
+		if change[0] && !change[1] {
+			missing++
+		}
+	}
+	for _, change := range planned.ConfigChanges {
+		if !inPlan(change, t.changes.ConfigChanges) {
+			missing++
+		}
+	}
+	for _, assignment := range planned.AddedPartitions {
+		if !inPlan(assignment, t.changes.AddedPartitions) {
+			missing++
+		}
+	}
+	for _, reassignment := range planned.Reassignments {
+		if !inPlan(reassignment, t.changes.Reassignments) {
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		return exitcode.Wrap(
+			exitcode.KindDrift,
+			fmt.Errorf(
+				"%d of the %d planned changes to topic %s weren't made",
+				missing,
+				planned.NumChanges(),
+				t.topicName,
+			),
+		)
+	}
+	return nil
+}
+
+func inPlan(value interface{}, planned interface{}) bool {
+	plannedValue := reflect.ValueOf(planned)
+	for i := 0; i < plannedValue.Len(); i++ {
+		if reflect.DeepEqual(value, plannedValue.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+func hashFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:]), nil
+}
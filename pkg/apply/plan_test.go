@@ -0,0 +1,186 @@
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanSignAndLoad(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "plan")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "topic.yaml")
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("meta:\n  name: test\n"), 0644))
+	planPath := filepath.Join(tempDir, "plan.json")
+
+	plan := NewPlan(PlanOptions{Rebalance: true}, time.Now())
+	require.NoError(t, plan.AddFile(configPath))
+	plan.Configs = append(plan.Configs, configPath)
+	plan.Topics = append(
+		plan.Topics,
+		&TopicChanges{
+			Topic:   "test-topic",
+			Cluster: "test-cluster",
+			Reassignments: []PartitionReassignment{
+				{
+					Partition:   0,
+					OldReplicas: []int{1, 2},
+					NewReplicas: []int{3, 2},
+				},
+			},
+		},
+	)
+
+	// Hashed, but not signed
+	require.NoError(t, plan.Sign(nil))
+	require.NoError(t, plan.WriteFile(planPath))
+
+	loaded, err := LoadPlanFile(planPath, nil)
+	require.NoError(t, err)
+	assert.True(t, loaded.Options.Rebalance)
+	assert.NotNil(t, loaded.TopicChanges("test-topic", "test-cluster"))
+	assert.Nil(t, loaded.TopicChanges("test-topic", "other-cluster"))
+	assert.NoError(t, loaded.CheckFiles())
+
+	_, err = LoadPlanFile(planPath, []byte("secret"))
+	assert.Error(t, err)
+
+	// Signed
+	require.NoError(t, plan.Sign([]byte("secret")))
+	require.NoError(t, plan.WriteFile(planPath))
+
+	_, err = LoadPlanFile(planPath, []byte("secret"))
+	require.NoError(t, err)
+	_, err = LoadPlanFile(planPath, []byte("other-secret"))
+	assert.Error(t, err)
+	_, err = LoadPlanFile(planPath, nil)
+	assert.Error(t, err)
+
+	// Edited
+	plan.Topics[0].Reassignments[0].NewReplicas = []int{4, 2}
+	require.NoError(t, plan.WriteFile(planPath))
+	_, err = LoadPlanFile(planPath, []byte("secret"))
+	assert.Error(t, err)
+
+	// Config changed
+	require.NoError(t, ioutil.WriteFile(configPath, []byte("meta:\n  name: other\n"), 0644))
+	assert.Error(t, loaded.CheckFiles())
+}
+
+func TestStateFingerprint(t *testing.T) {
+	brokers := []admin.BrokerInfo{
+		{ID: 2, Rack: "zone2"},
+		{ID: 1, Rack: "zone1"},
+	}
+	topicInfo := &admin.TopicInfo{
+		Name:   "test-topic",
+		Config: map[string]string{"retention.ms": "1000"},
+		Partitions: []admin.PartitionInfo{
+			{ID: 0, Leader: 1, Replicas: []int{1, 2}, ISR: []int{1, 2}},
+		},
+	}
+
+	fingerprint := StateFingerprint(topicInfo, brokers)
+	assert.NotEqual(t, fingerprint, StateFingerprint(nil, brokers))
+
+	// Broker order and leader changes don't matter
+	topicInfo.Partitions[0].Leader = 2
+	topicInfo.Partitions[0].ISR = []int{2}
+	assert.Equal(
+		t,
+		fingerprint,
+		StateFingerprint(topicInfo, []admin.BrokerInfo{brokers[1], brokers[0]}),
+	)
+
+	// Replica changes do
+	topicInfo.Partitions[0].Replicas = []int{2, 1}
+	assert.NotEqual(t, fingerprint, StateFingerprint(topicInfo, brokers))
+}
+
+func TestCheckPlanned(t *testing.T) {
+	applier := &TopicApplier{
+		changes:   &TopicChanges{},
+		topicName: "test-topic",
+		config: TopicApplierConfig{
+			PlannedChanges: &TopicChanges{
+				ConfigChanges: []ConfigChange{
+					{
+						Key:      "retention.ms",
+						OldValue: "1000",
+						NewValue: "2000",
+					},
+				},
+				Reassignments: []PartitionReassignment{
+					{
+						Partition:   0,
+						OldReplicas: []int{1, 2},
+						NewReplicas: []int{3, 2},
+					},
+				},
+			},
+		},
+	}
+
+	assert.NoError(
+		t,
+		applier.checkPlanned(
+			&TopicChanges{
+				ConfigChanges: []ConfigChange{
+					{
+						Key:      "retention.ms",
+						OldValue: "1000",
+						NewValue: "2000",
+					},
+				},
+			},
+		),
+	)
+	assert.Error(
+		t,
+		applier.checkPlanned(
+			&TopicChanges{
+				ConfigChanges: []ConfigChange{
+					{
+						Key:      "retention.ms",
+						OldValue: "1500",
+						NewValue: "2000",
+					},
+				},
+			},
+		),
+	)
+	assert.Error(
+		t,
+		applier.checkPlanned(
+			&TopicChanges{
+				Reassignments: []PartitionReassignment{
+					{
+						Partition:   0,
+						OldReplicas: []int{1, 2},
+						NewReplicas: []int{4, 2},
+					},
+				},
+			},
+		),
+	)
+	assert.Error(t, applier.checkPlanned(&TopicChanges{Created: true}))
+
+	// Only some of the planned changes were made
+	applier.changes.ConfigChanges = applier.config.PlannedChanges.ConfigChanges
+	assert.Error(t, applier.checkPlanComplete())
+
+	applier.changes.Reassignments = applier.config.PlannedChanges.Reassignments
+	assert.NoError(t, applier.checkPlanComplete())
+
+	// No plan
+	applier.config.PlannedChanges = nil
+	assert.NoError(t, applier.checkPlanned(&TopicChanges{Created: true}))
+}
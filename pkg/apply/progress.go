@@ -0,0 +1,349 @@
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// ConfigStatus is the status of a single config in a multi-config apply.
+type ConfigStatus string
+
+const (
+	ConfigStatusPending    ConfigStatus = "pending"
+	ConfigStatusInProgress ConfigStatus = "in-progress"
+	ConfigStatusChanged    ConfigStatus = "changed"
+	ConfigStatusUnchanged  ConfigStatus = "unchanged"
+	ConfigStatusSkipped    ConfigStatus = "skipped"
+	ConfigStatusFailed     ConfigStatus = "failed"
+
+	// ConfigStatusApplied is used for configs that were applied without recording their
+	// changes, e.g. connector configs.
+	ConfigStatusApplied ConfigStatus = "applied"
+)
+
+// progressBarWidth is the number of characters in the bar shown by ApplyProgress.Format.
+const progressBarWidth = 20
+
+// ApplyProgress tracks the statuses of the configs in a multi-config apply. It's also a
+// machine-readable summary of the apply once it's done.
+type ApplyProgress struct {
+	DryRun  bool              `json:"dryRun"`
+	Configs []*ConfigProgress `json:"configs"`
+}
+
+// ConfigProgress is the status of a single config in an ApplyProgress.
+type ConfigProgress struct {
+	Path   string       `json:"path"`
+	Topic  string       `json:"topic,omitempty"`
+	Status ConfigStatus `json:"status"`
+
+	// Priority is the migration priority of the topic in the config; see
+	// ApplyProgress.OrderByPriority.
+	Priority int `json:"priority"`
+
+	// NumChanges is the number of changes made to the topic (or, in dry-run mode, the
+	// number that would have been made); see TopicChanges.NumChanges.
+	NumChanges int           `json:"numChanges"`
+	Duration   time.Duration `json:"durationNs"`
+	Error      string        `json:"error,omitempty"`
+
+	startTime time.Time
+}
+
+// NewApplyProgress returns an ApplyProgress with all of the argument config paths pending.
+func NewApplyProgress(paths []string, dryRun bool) *ApplyProgress {
+	progress := &ApplyProgress{
+		DryRun:  dryRun,
+		Configs: []*ConfigProgress{},
+	}
+	for _, path := range paths {
+		progress.Configs = append(
+			progress.Configs,
+			&ConfigProgress{
+				Path:   path,
+				Status: ConfigStatusPending,
+			},
+		)
+	}
+	return progress
+}
+
+// OrderByPriority sets the priority of each config from the argument map, which is keyed
+// by path, and then sorts the configs so that the ones with higher priorities come first.
+// Configs with the same priority keep their original order. Configs that aren't in the
+// map have a priority of 0.
+func (p *ApplyProgress) OrderByPriority(priorities map[string]int) {
+	for _, configProgress := range p.Configs {
+		configProgress.Priority = priorities[configProgress.Path]
+	}
+	sort.SliceStable(p.Configs, func(a, b int) bool {
+		return p.Configs[a].Priority > p.Configs[b].Priority
+	})
+}
+
+// Tiers returns the distinct priorities of the configs, from highest to lowest.
+func (p *ApplyProgress) Tiers() []int {
+	tiers := []int{}
+	seen := map[int]struct{}{}
+
+	for _, configProgress := range p.Configs {
+		if _, ok := seen[configProgress.Priority]; ok {
+			continue
+		}
+		seen[configProgress.Priority] = struct{}{}
+		tiers = append(tiers, configProgress.Priority)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(tiers)))
+	return tiers
+}
+
+// FormatTiers returns a one-line summary of the progress in each priority tier, e.g.
+// "priority 10: 2/2 done, priority 0: 1/5 done, priority -5: 0/3 done".
+func (p *ApplyProgress) FormatTiers() string {
+	tierStrs := []string{}
+
+	for _, tier := range p.Tiers() {
+		total := 0
+		done := 0
+		for _, configProgress := range p.Configs {
+			if configProgress.Priority != tier {
+				continue
+			}
+			total++
+			if configProgress.Status != ConfigStatusPending &&
+				configProgress.Status != ConfigStatusInProgress {
+				done++
+			}
+		}
+		tierStrs = append(tierStrs, fmt.Sprintf("priority %d: %d/%d done", tier, done, total))
+	}
+
+	return strings.Join(tierStrs, ", ")
+}
+
+// Start marks the config with the argument index as in-progress.
+func (p *ApplyProgress) Start(index int, now time.Time) {
+	p.Configs[index].Status = ConfigStatusInProgress
+	p.Configs[index].startTime = now
+}
+
+// Finish updates the status of the config with the argument index based on the result of
+// its apply. The changes are nil if the apply didn't record any, e.g. because it was for a
+// connector or failed before reaching the cluster.
+func (p *ApplyProgress) Finish(
+	index int,
+	changes *TopicChanges,
+	err error,
+	now time.Time,
+) {
+	configProgress := p.Configs[index]
+	configProgress.Duration = now.Sub(configProgress.startTime)
+
+	if changes != nil {
+		configProgress.Topic = changes.Topic
+		configProgress.NumChanges = changes.NumChanges()
+	}
+
+	switch {
+	case err != nil:
+		configProgress.Status = ConfigStatusFailed
+		configProgress.Error = err.Error()
+	case changes == nil:
+		configProgress.Status = ConfigStatusApplied
+	case changes.Skipped != "":
+		configProgress.Status = ConfigStatusSkipped
+	case configProgress.NumChanges > 0:
+		configProgress.Status = ConfigStatusChanged
+	default:
+		configProgress.Status = ConfigStatusUnchanged
+	}
+}
+
+// Counts returns the number of configs with each status.
+func (p *ApplyProgress) Counts() map[ConfigStatus]int {
+	counts := map[ConfigStatus]int{}
+	for _, configProgress := range p.Configs {
+		counts[configProgress.Status]++
+	}
+	return counts
+}
+
+// Pending returns the paths of the configs that haven't been started.
+func (p *ApplyProgress) Pending() []string {
+	pending := []string{}
+	for _, configProgress := range p.Configs {
+		if configProgress.Status == ConfigStatusPending {
+			pending = append(pending, configProgress.Path)
+		}
+	}
+	return pending
+}
+
+// Format returns a one-line summary of the progress, e.g.
+// "[#####...............] 5/20 configs done: 2 changed, 3 unchanged, 15 pending".
+func (p *ApplyProgress) Format() string {
+	counts := p.Counts()
+	done := len(p.Configs) - counts[ConfigStatusPending] - counts[ConfigStatusInProgress]
+
+	filled := 0
+	if len(p.Configs) > 0 {
+		filled = progressBarWidth * done / len(p.Configs)
+	}
+
+	countStrs := []string{}
+	for _, status := range []ConfigStatus{
+		ConfigStatusChanged,
+		ConfigStatusUnchanged,
+		ConfigStatusApplied,
+		ConfigStatusSkipped,
+		ConfigStatusFailed,
+		ConfigStatusInProgress,
+		ConfigStatusPending,
+	} {
+		if counts[status] > 0 {
+			countStrs = append(countStrs, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+
+	return fmt.Sprintf(
+		"[%s%s] %d/%d configs done: %s",
+		strings.Repeat("#", filled),
+		strings.Repeat(".", progressBarWidth-filled),
+		done,
+		len(p.Configs),
+		strings.Join(countStrs, ", "),
+	)
+}
+
+// WriteFile writes the progress as indented JSON to the argument path.
+func (p *ApplyProgress) WriteFile(path string) error {
+	contents, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(contents, '\n'), 0644)
+}
+
+// FormatApplyProgress creates a pretty table that lists the status of each config in a
+// multi-config apply. If the configs have different priorities, they're grouped by
+// priority tier, with a summary row after each tier.
+func FormatApplyProgress(progress *ApplyProgress) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Priority",
+			"Config",
+			"Topic",
+			"Status",
+			"Changes",
+			"Duration",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	tiers := progress.Tiers()
+
+	for c, configProgress := range progress.Configs {
+		statusStr := string(configProgress.Status)
+		switch configProgress.Status {
+		case ConfigStatusChanged:
+			statusStr = color.New(color.FgGreen).Sprint(statusStr)
+		case ConfigStatusFailed:
+			statusStr = color.New(color.FgRed).Sprint(statusStr)
+		case ConfigStatusSkipped:
+			statusStr = color.New(color.FgYellow).Sprint(statusStr)
+		}
+
+		durationStr := ""
+		if configProgress.Status != ConfigStatusPending {
+			durationStr = util.PrettyDuration(configProgress.Duration)
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", configProgress.Priority),
+				configProgress.Path,
+				configProgress.Topic,
+				statusStr,
+				fmt.Sprintf("%d", configProgress.NumChanges),
+				durationStr,
+			},
+		)
+
+		lastInTier := c == len(progress.Configs)-1 ||
+			progress.Configs[c+1].Priority != configProgress.Priority
+		if len(tiers) > 1 && lastInTier {
+			table.Append(tierSummaryRow(progress, configProgress.Priority))
+		}
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// tierSummaryRow returns a table row with the number of configs with each status in the
+// argument priority tier.
+func tierSummaryRow(progress *ApplyProgress, tier int) []string {
+	counts := map[ConfigStatus]int{}
+	numChanges := 0
+	for _, configProgress := range progress.Configs {
+		if configProgress.Priority == tier {
+			counts[configProgress.Status]++
+			numChanges += configProgress.NumChanges
+		}
+	}
+
+	countStrs := []string{}
+	for _, status := range []ConfigStatus{
+		ConfigStatusChanged,
+		ConfigStatusUnchanged,
+		ConfigStatusApplied,
+		ConfigStatusSkipped,
+		ConfigStatusFailed,
+		ConfigStatusInProgress,
+		ConfigStatusPending,
+	} {
+		if counts[status] > 0 {
+			countStrs = append(countStrs, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("%d total", tier),
+		"",
+		"",
+		strings.Join(countStrs, ", "),
+		fmt.Sprintf("%d", numChanges),
+		"",
+	}
+}
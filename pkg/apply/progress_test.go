@@ -0,0 +1,90 @@
+package apply
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProgress(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	progress := NewApplyProgress(
+		[]string{"topic1.yaml", "topic2.yaml", "topic3.yaml", "topic4.yaml"},
+		false,
+	)
+	assert.Equal(
+		t,
+		"[....................] 0/4 configs done: 4 pending",
+		progress.Format(),
+	)
+
+	progress.Start(0, now)
+	progress.Finish(
+		0,
+		&TopicChanges{
+			Topic:         "topic1",
+			ConfigChanges: []ConfigChange{{Key: "retention.ms"}},
+		},
+		nil,
+		now.Add(2*time.Second),
+	)
+	progress.Start(1, now)
+	progress.Finish(1, &TopicChanges{Topic: "topic2"}, nil, now)
+	progress.Start(2, now)
+	progress.Finish(2, &TopicChanges{Topic: "topic3"}, errors.New("test error"), now)
+
+	assert.Equal(t, ConfigStatusChanged, progress.Configs[0].Status)
+	assert.Equal(t, "topic1", progress.Configs[0].Topic)
+	assert.Equal(t, 1, progress.Configs[0].NumChanges)
+	assert.Equal(t, 2*time.Second, progress.Configs[0].Duration)
+	assert.Equal(t, ConfigStatusUnchanged, progress.Configs[1].Status)
+	assert.Equal(t, ConfigStatusFailed, progress.Configs[2].Status)
+	assert.Equal(t, "test error", progress.Configs[2].Error)
+	assert.Equal(t, []string{"topic4.yaml"}, progress.Pending())
+	assert.Equal(
+		t,
+		"[###############.....] 3/4 configs done: 1 changed, 1 unchanged, 1 failed, 1 pending",
+		progress.Format(),
+	)
+}
+
+func TestApplyProgressPriorities(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	progress := NewApplyProgress(
+		[]string{"low.yaml", "default1.yaml", "high.yaml", "default2.yaml"},
+		false,
+	)
+	progress.OrderByPriority(
+		map[string]int{
+			"low.yaml":  -5,
+			"high.yaml": 10,
+		},
+	)
+
+	paths := []string{}
+	for _, configProgress := range progress.Configs {
+		paths = append(paths, configProgress.Path)
+	}
+	assert.Equal(
+		t,
+		[]string{"high.yaml", "default1.yaml", "default2.yaml", "low.yaml"},
+		paths,
+	)
+	assert.Equal(t, []int{10, 0, -5}, progress.Tiers())
+
+	progress.Start(0, now)
+	progress.Finish(0, &TopicChanges{Topic: "high"}, nil, now)
+	progress.Start(1, now)
+
+	assert.Equal(
+		t,
+		"priority 10: 1/1 done, priority 0: 0/2 done, priority -5: 0/1 done",
+		progress.FormatTiers(),
+	)
+
+	table := FormatApplyProgress(progress)
+	assert.Contains(t, table, "10 total")
+	assert.Contains(t, table, "-5 total")
+}
@@ -0,0 +1,220 @@
+package rebalancers
+
+import (
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/assigners"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// GoalRebalancer is a Rebalancer that optimizes a prioritized list of goals (see
+// assigners.Goal). The algorithm used is:
+//
+//   for each replica on a broker to be removed:
+//     replace it with the remaining broker that gives the best goal scores
+//   while true:
+//     for each partition:
+//       try swapping each follower into the leader position
+//       try replacing each replica with each broker not already in the partition
+//     if the best move improves the goal scores and is consistent with the placement
+//       strategy, make it
+//     otherwise, stop
+//
+// Goal scores are compared in priority order, so a move is only made if it improves a goal
+// without making any higher-priority goal worse. Swaps don't require any data to be moved, so
+// they're preferred over replacements when both give the same scores.
+type GoalRebalancer struct {
+	brokers         []admin.BrokerInfo
+	placementConfig config.TopicPlacementConfig
+	goals           []assigners.Goal
+}
+
+var _ Rebalancer = (*GoalRebalancer)(nil)
+
+// NewGoalRebalancer creates a new GoalRebalancer instance. The goals should be in priority
+// order, highest first.
+func NewGoalRebalancer(
+	brokers []admin.BrokerInfo,
+	placementConfig config.TopicPlacementConfig,
+	goals []assigners.Goal,
+) *GoalRebalancer {
+	return &GoalRebalancer{
+		brokers:         brokers,
+		placementConfig: placementConfig,
+		goals:           goals,
+	}
+}
+
+// Rebalance rebalances the argument partition assignments according to the algorithm
+// described earlier.
+func (g *GoalRebalancer) Rebalance(
+	topic string,
+	curr []admin.PartitionAssignment,
+	brokersToRemove []int,
+) ([]admin.PartitionAssignment, error) {
+	ok, err := assigners.EvaluateAssignments(curr, g.brokers, g.placementConfig)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("Starting assignments do not satisfy placement config")
+	}
+
+	desired := admin.CopyAssignments(curr)
+
+	toRemoveMap := map[int]struct{}{}
+	for _, brokerID := range brokersToRemove {
+		toRemoveMap[brokerID] = struct{}{}
+	}
+
+	// Goals are only scored against the brokers that will remain
+	remaining := []admin.BrokerInfo{}
+	for _, broker := range g.brokers {
+		if _, ok := toRemoveMap[broker.ID]; !ok {
+			remaining = append(remaining, broker)
+		}
+	}
+
+	for a := range desired {
+		for i, replica := range desired[a].Replicas {
+			if _, ok := toRemoveMap[replica]; !ok {
+				continue
+			}
+			if !g.replaceBest(desired, remaining, a, i) {
+				return nil, fmt.Errorf(
+					"Could not find a feasible replacement for broker %d",
+					replica,
+				)
+			}
+		}
+	}
+
+	// Each move strictly improves the scores, so this will terminate, but cap the
+	// iterations to be safe.
+	maxMoves := len(desired) * len(g.brokers) * len(desired[0].Replicas)
+	for m := 0; m < maxMoves; m++ {
+		if !g.makeBestMove(desired, remaining) {
+			break
+		}
+	}
+
+	return desired, nil
+}
+
+type goalMove struct {
+	partition int
+	index     int
+	broker    int
+	swap      bool
+}
+
+// makeBestMove finds the single swap or replacement that improves the goal scores the most
+// and applies it. It returns whether a move was made.
+func (g *GoalRebalancer) makeBestMove(
+	desired []admin.PartitionAssignment,
+	remaining []admin.BrokerInfo,
+) bool {
+	bestScores := assigners.ScoreAssignments(desired, remaining, g.goals)
+	var bestMove *goalMove
+
+	tryMove := func(move goalMove) {
+		replicas := desired[move.partition].Replicas
+		original := append([]int{}, replicas...)
+		defer copy(replicas, original)
+
+		g.applyMove(desired, move)
+
+		scores := assigners.ScoreAssignments(desired, remaining, g.goals)
+		if assigners.CompareScores(scores, bestScores) >= 0 {
+			return
+		}
+		if ok, err := assigners.EvaluateAssignments(
+			desired,
+			g.brokers,
+			g.placementConfig,
+		); !ok || err != nil {
+			return
+		}
+
+		bestScores = scores
+		bestMove = &move
+	}
+
+	// Try swaps first so that they win ties
+	for a, assignment := range desired {
+		for i := 1; i < len(assignment.Replicas); i++ {
+			tryMove(goalMove{partition: a, index: i, swap: true})
+		}
+	}
+
+	for a, assignment := range desired {
+		for i := range assignment.Replicas {
+			for _, broker := range remaining {
+				if desired[a].Index(broker.ID) >= 0 {
+					continue
+				}
+				tryMove(goalMove{partition: a, index: i, broker: broker.ID})
+			}
+		}
+	}
+
+	if bestMove == nil {
+		return false
+	}
+
+	g.applyMove(desired, *bestMove)
+	return true
+}
+
+// replaceBest replaces the replica at the argument partition and index with the remaining
+// broker that gives the best goal scores while still being consistent with the placement
+// strategy. It returns whether a replacement was found.
+func (g *GoalRebalancer) replaceBest(
+	desired []admin.PartitionAssignment,
+	remaining []admin.BrokerInfo,
+	partition int,
+	index int,
+) bool {
+	original := desired[partition].Replicas[index]
+	bestBroker := -1
+	var bestScores []float64
+
+	for _, broker := range remaining {
+		if desired[partition].Index(broker.ID) >= 0 {
+			continue
+		}
+
+		desired[partition].Replicas[index] = broker.ID
+		scores := assigners.ScoreAssignments(desired, remaining, g.goals)
+
+		if bestScores == nil || assigners.CompareScores(scores, bestScores) < 0 {
+			if ok, err := assigners.EvaluateAssignments(
+				desired,
+				g.brokers,
+				g.placementConfig,
+			); ok && err == nil {
+				bestScores = scores
+				bestBroker = broker.ID
+			}
+		}
+
+		desired[partition].Replicas[index] = original
+	}
+
+	if bestBroker == -1 {
+		return false
+	}
+
+	desired[partition].Replicas[index] = bestBroker
+	return true
+}
+
+func (g *GoalRebalancer) applyMove(desired []admin.PartitionAssignment, move goalMove) {
+	replicas := desired[move.partition].Replicas
+
+	if move.swap {
+		replicas[0], replicas[move.index] = replicas[move.index], replicas[0]
+	} else {
+		replicas[move.index] = move.broker
+	}
+}
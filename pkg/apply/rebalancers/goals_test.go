@@ -0,0 +1,144 @@
+package rebalancers
+
+import (
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/apply/assigners"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalRebalancerAny(t *testing.T) {
+	brokers := testBrokers(4, 2)
+	goals, err := assigners.GoalsFromConfig(
+		[]config.RebalanceGoal{
+			config.RebalanceGoalRackDistribution,
+			config.RebalanceGoalReplicaBalance,
+			config.RebalanceGoalLeaderBalance,
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	rebalancer := NewGoalRebalancer(
+		brokers,
+		config.TopicPlacementConfig{
+			Strategy: config.PlacementStrategyAny,
+		},
+		goals,
+	)
+
+	testCases := []rebalancerTestCase{
+		{
+			description: "Already balanced",
+			curr: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 4},
+				{4, 1},
+			},
+			expected: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 4},
+				{4, 1},
+			},
+		},
+		{
+			description: "Leader swaps",
+			curr: [][]int{
+				{1, 2},
+				{1, 4},
+				{3, 2},
+				{3, 4},
+			},
+			expected: [][]int{
+				{2, 1},
+				{1, 4},
+				{3, 2},
+				{4, 3},
+			},
+		},
+		{
+			description: "Rack spread",
+			curr: [][]int{
+				{1, 3},
+				{2, 4},
+				{1, 2},
+				{3, 4},
+			},
+			expected: [][]int{
+				{4, 3},
+				{2, 1},
+				{1, 2},
+				{3, 4},
+			},
+		},
+		{
+			description: "Removals",
+			curr: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 4},
+				{4, 1},
+			},
+			toRemove: []int{4},
+			// Broker 2 is the only one left in zone2, so rack distribution requires that it be
+			// in every partition
+			expected: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 2},
+				{2, 1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, rebalancer)
+	}
+}
+
+func TestGoalRebalancerInRack(t *testing.T) {
+	brokers := testBrokers(4, 2)
+	goals, err := assigners.GoalsFromConfig(
+		[]config.RebalanceGoal{
+			config.RebalanceGoalRackDistribution,
+			config.RebalanceGoalLeaderBalance,
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	rebalancer := NewGoalRebalancer(
+		brokers,
+		config.TopicPlacementConfig{
+			Strategy: config.PlacementStrategyInRack,
+		},
+		goals,
+	)
+
+	testCases := []rebalancerTestCase{
+		{
+			// Rack distribution can't be improved without violating the placement strategy,
+			// but leaders can still be balanced within each rack
+			description: "Leader swaps in rack",
+			curr: [][]int{
+				{1, 3},
+				{1, 3},
+				{2, 4},
+				{2, 4},
+			},
+			expected: [][]int{
+				{3, 1},
+				{1, 3},
+				{4, 2},
+				{2, 4},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, rebalancer)
+	}
+}
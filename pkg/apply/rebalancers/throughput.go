@@ -0,0 +1,272 @@
+package rebalancers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/assigners"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// ThroughputRebalancer is a Rebalancer that uses observed per-partition throughput to
+// balance the leader load (i.e., the produce and consume traffic) across brokers, instead
+// of just the partition counts. The algorithm used is:
+//
+//   move all replicas off of the brokers to be removed, choosing the brokers with the
+//     lowest total replica throughput as replacements
+//   while true:
+//     find the brokers with the highest and lowest leader throughput
+//     for each partition led by the highest broker, in decreasing order of throughput:
+//       if moving the partition's leadership to the lowest broker would reduce the gap:
+//         if the lowest broker is already a replica, swap it into the leader position
+//         otherwise, replace the leader with the lowest broker
+//         if the result is consistent with the placement strategy, keep it
+//     if no moves were made, stop
+//
+// Swapping replica positions doesn't require any data to be moved, so it's preferred over
+// replacements.
+type ThroughputRebalancer struct {
+	brokers         []admin.BrokerInfo
+	placementConfig config.TopicPlacementConfig
+	partitionRates  map[int]float64
+}
+
+var _ Rebalancer = (*ThroughputRebalancer)(nil)
+
+// NewThroughputRebalancer creates a new ThroughputRebalancer instance. The partitionRates
+// map contains the bytes-in rate for each partition in the topic; partitions that aren't
+// in the map are assumed to have no traffic.
+func NewThroughputRebalancer(
+	brokers []admin.BrokerInfo,
+	placementConfig config.TopicPlacementConfig,
+	partitionRates map[int]float64,
+) *ThroughputRebalancer {
+	return &ThroughputRebalancer{
+		brokers:         brokers,
+		placementConfig: placementConfig,
+		partitionRates:  partitionRates,
+	}
+}
+
+// Rebalance rebalances the argument partition assignments according to the algorithm
+// described earlier.
+func (r *ThroughputRebalancer) Rebalance(
+	topic string,
+	curr []admin.PartitionAssignment,
+	brokersToRemove []int,
+) ([]admin.PartitionAssignment, error) {
+	ok, err := assigners.EvaluateAssignments(curr, r.brokers, r.placementConfig)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("Starting assignments do not satisfy placement config")
+	}
+
+	desired := admin.CopyAssignments(curr)
+
+	toRemoveMap := map[int]struct{}{}
+	for _, brokerID := range brokersToRemove {
+		toRemoveMap[brokerID] = struct{}{}
+	}
+
+	if err := r.removeBrokers(desired, toRemoveMap); err != nil {
+		return nil, err
+	}
+
+	// Each iteration strictly decreases the sum of squared leader loads, so this will
+	// terminate, but cap the iterations to be safe.
+	for i := 0; i < len(desired)*len(r.brokers); i++ {
+		if !r.moveLeader(desired, toRemoveMap) {
+			break
+		}
+	}
+
+	return desired, nil
+}
+
+// BrokerLeaderRates returns the total bytes-in rate of the partitions led by each broker
+// in the argument assignments. The leader is assumed to be the first replica.
+func BrokerLeaderRates(
+	assignments []admin.PartitionAssignment,
+	brokers []admin.BrokerInfo,
+	partitionRates map[int]float64,
+) map[int]float64 {
+	rates := map[int]float64{}
+	for _, broker := range brokers {
+		rates[broker.ID] = 0
+	}
+
+	for _, assignment := range assignments {
+		if len(assignment.Replicas) > 0 {
+			rates[assignment.Replicas[0]] += partitionRates[assignment.ID]
+		}
+	}
+
+	return rates
+}
+
+func (r *ThroughputRebalancer) removeBrokers(
+	desired []admin.PartitionAssignment,
+	toRemoveMap map[int]struct{},
+) error {
+	for a, assignment := range desired {
+		for i, replica := range assignment.Replicas {
+			if _, ok := toRemoveMap[replica]; !ok {
+				continue
+			}
+
+			replicaRates := r.brokerReplicaRates(desired)
+			candidates := []int{}
+
+			for _, broker := range r.brokers {
+				if _, ok := toRemoveMap[broker.ID]; ok {
+					continue
+				}
+				if desired[a].Index(broker.ID) >= 0 {
+					continue
+				}
+				candidates = append(candidates, broker.ID)
+			}
+			sort.Slice(candidates, func(c1, c2 int) bool {
+				return replicaRates[candidates[c1]] < replicaRates[candidates[c2]] ||
+					(replicaRates[candidates[c1]] == replicaRates[candidates[c2]] &&
+						candidates[c1] < candidates[c2])
+			})
+
+			replaced := false
+			for _, candidate := range candidates {
+				desired[a].Replicas[i] = candidate
+				if ok, err := assigners.EvaluateAssignments(
+					desired,
+					r.brokers,
+					r.placementConfig,
+				); ok && err == nil {
+					replaced = true
+					break
+				}
+			}
+
+			if !replaced {
+				return fmt.Errorf(
+					"Could not find a feasible replacement for broker %d",
+					replica,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveLeader tries to move leadership of a single partition from the broker with the
+// highest leader rate to the one with the lowest. It returns whether a move was made.
+func (r *ThroughputRebalancer) moveLeader(
+	desired []admin.PartitionAssignment,
+	toRemoveMap map[int]struct{},
+) bool {
+	leaderRates := BrokerLeaderRates(desired, r.brokers, r.partitionRates)
+
+	brokerIDs := []int{}
+	for _, broker := range r.brokers {
+		if _, ok := toRemoveMap[broker.ID]; ok {
+			continue
+		}
+		brokerIDs = append(brokerIDs, broker.ID)
+	}
+	if len(brokerIDs) < 2 {
+		return false
+	}
+
+	sort.Slice(brokerIDs, func(a, b int) bool {
+		return leaderRates[brokerIDs[a]] < leaderRates[brokerIDs[b]] ||
+			(leaderRates[brokerIDs[a]] == leaderRates[brokerIDs[b]] &&
+				brokerIDs[a] < brokerIDs[b])
+	})
+
+	// Try each (higher, lower) pair, starting with the most extreme ones
+	for h := len(brokerIDs) - 1; h > 0; h-- {
+		for l := 0; l < h; l++ {
+			if r.moveLeaderBetween(desired, brokerIDs[h], brokerIDs[l], leaderRates) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *ThroughputRebalancer) moveLeaderBetween(
+	desired []admin.PartitionAssignment,
+	higherBroker int,
+	lowerBroker int,
+	leaderRates map[int]float64,
+) bool {
+	gap := leaderRates[higherBroker] - leaderRates[lowerBroker]
+
+	candidates := []int{}
+	for a, assignment := range desired {
+		rate := r.partitionRates[assignment.ID]
+		// Moving the partition only helps if its rate is positive and less than the gap
+		if assignment.Replicas[0] == higherBroker && rate > 0 && rate < gap {
+			candidates = append(candidates, a)
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return r.partitionRates[desired[candidates[a]].ID] >
+			r.partitionRates[desired[candidates[b]].ID]
+	})
+
+	// First, try swaps, which don't require any data movement
+	for _, a := range candidates {
+		index := desired[a].Index(lowerBroker)
+		if index < 0 {
+			continue
+		}
+
+		replicas := desired[a].Replicas
+		replicas[0], replicas[index] = replicas[index], replicas[0]
+		if ok, err := assigners.EvaluateAssignments(
+			desired,
+			r.brokers,
+			r.placementConfig,
+		); ok && err == nil {
+			return true
+		}
+		replicas[0], replicas[index] = replicas[index], replicas[0]
+	}
+
+	// Then, try replacing the leader
+	for _, a := range candidates {
+		if desired[a].Index(lowerBroker) >= 0 {
+			continue
+		}
+
+		desired[a].Replicas[0] = lowerBroker
+		if ok, err := assigners.EvaluateAssignments(
+			desired,
+			r.brokers,
+			r.placementConfig,
+		); ok && err == nil {
+			return true
+		}
+		desired[a].Replicas[0] = higherBroker
+	}
+
+	return false
+}
+
+// brokerReplicaRates returns the total bytes-in rate of all of the replicas on each broker.
+func (r *ThroughputRebalancer) brokerReplicaRates(
+	assignments []admin.PartitionAssignment,
+) map[int]float64 {
+	rates := map[int]float64{}
+
+	for _, assignment := range assignments {
+		for _, replica := range assignment.Replicas {
+			rates[replica] += r.partitionRates[assignment.ID]
+		}
+	}
+
+	return rates
+}
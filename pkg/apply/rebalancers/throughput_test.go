@@ -0,0 +1,133 @@
+package rebalancers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughputRebalancerAny(t *testing.T) {
+	brokers := testBrokers(3, 3)
+	rebalancer := NewThroughputRebalancer(
+		brokers,
+		config.TopicPlacementConfig{
+			Strategy: config.PlacementStrategyAny,
+		},
+		map[int]float64{
+			0: 100.0,
+			1: 100.0,
+			2: 10.0,
+			3: 10.0,
+		},
+	)
+
+	testCases := []rebalancerTestCase{
+		{
+			description: "Swaps",
+			curr: [][]int{
+				{1, 2},
+				{1, 3},
+				{2, 3},
+				{3, 1},
+			},
+			expected: [][]int{
+				{2, 1},
+				{1, 3},
+				{3, 2},
+				{3, 1},
+			},
+		},
+		{
+			description: "Replacements",
+			curr: [][]int{
+				{1},
+				{1},
+				{2},
+				{2},
+			},
+			expected: [][]int{
+				{3},
+				{1},
+				{2},
+				{2},
+			},
+		},
+		{
+			description: "Already balanced",
+			curr: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+				{3, 2},
+			},
+			expected: [][]int{
+				{1, 2},
+				{2, 3},
+				{3, 1},
+				{3, 2},
+			},
+		},
+		{
+			description: "Removals",
+			curr: [][]int{
+				{1},
+				{2},
+				{3},
+				{3},
+			},
+			toRemove: []int{3},
+			expected: [][]int{
+				{1},
+				{2},
+				{1},
+				{2},
+			},
+		},
+		{
+			description: "Infeasible removals",
+			curr: [][]int{
+				{1, 2, 3},
+				{1, 2, 3},
+				{1, 2, 3},
+				{1, 2, 3},
+			},
+			toRemove: []int{3},
+			err:      errors.New("Could not find a feasible replacement"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.evaluate(t, rebalancer)
+	}
+}
+
+func TestBrokerLeaderRates(t *testing.T) {
+	assignments := admin.ReplicasToAssignments(
+		[][]int{
+			{1, 2},
+			{2, 1},
+			{1, 3},
+		},
+	)
+
+	assert.Equal(
+		t,
+		map[int]float64{
+			1: 15.0,
+			2: 20.0,
+			3: 0.0,
+		},
+		BrokerLeaderRates(
+			assignments,
+			testBrokers(3, 3),
+			map[int]float64{
+				0: 10.0,
+				1: 20.0,
+				2: 5.0,
+			},
+		),
+	)
+}
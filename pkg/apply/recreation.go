@@ -0,0 +1,43 @@
+package apply
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkRecreated logs a warning if the topic has been re-created since it was last recorded
+// and then records its current ID and creation time. Re-creations are detected through topic
+// IDs in Kafka 2.8 and later, and through topic creation times otherwise. Errors are logged
+// but otherwise ignored.
+func (t *TopicApplier) checkRecreated(ctx context.Context, topicInfo admin.TopicInfo) {
+	if topicInfo.TopicID == "" && topicInfo.CreatedAt.IsZero() {
+		return
+	}
+
+	recordedTopic, err := t.adminClient.GetRecordedTopic(ctx, t.topicName)
+	if err != nil {
+		log.Warnf("Could not get the recorded state of topic %s: %+v", t.topicName, err)
+		return
+	}
+	if recordedTopic.ID == topicInfo.TopicID && recordedTopic.CreatedAt.Equal(topicInfo.CreatedAt) {
+		return
+	}
+
+	if recordedTopic.Recreated(topicInfo) {
+		log.Warnf(
+			"Topic %s was re-created at %s since it was last applied; consumer group offsets from the old topic may no longer be valid",
+			t.topicName,
+			topicInfo.CreatedAt.Format(time.RFC3339),
+		)
+	}
+
+	if t.config.DryRun {
+		return
+	}
+	if err := t.adminClient.RecordTopic(ctx, topicInfo); err != nil {
+		log.Warnf("Could not record the state of topic %s: %+v", t.topicName, err)
+	}
+}
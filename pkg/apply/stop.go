@@ -0,0 +1,143 @@
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+)
+
+// ErrApplyStopped is returned when an apply stops before making all of its changes because
+// the Stop channel in its config was closed.
+var ErrApplyStopped = errors.New("Apply stopped before all changes were made")
+
+// cancelReassignmentsTimeout is the maximum amount of time used to cancel in-flight
+// reassignments and remove their throttles when an apply is stopped.
+const cancelReassignmentsTimeout = 30 * time.Second
+
+// stopRequested returns whether the Stop channel in the config has been closed.
+func (t *TopicApplier) stopRequested() bool {
+	if t.config.Stop == nil {
+		return false
+	}
+
+	select {
+	case <-t.config.Stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkStop returns ErrApplyStopped if a stop has been requested. It's called before
+// each change so that no new changes are started after the request.
+func (t *TopicApplier) checkStop() error {
+	if t.stopRequested() {
+		return ErrApplyStopped
+	}
+	return nil
+}
+
+// cancelReassignments cancels the in-flight reassignments of the argument partitions and
+// removes the throttles that were applied for them. A separate context is used since
+// the apply's context may have already been cancelled.
+func (t *TopicApplier) cancelReassignments(
+	partitionIDs []int,
+	currAssignments []admin.PartitionAssignment,
+	assignmentsToUpdate []admin.PartitionAssignment,
+	throttledTopic bool,
+	throttledBrokers []int,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelReassignmentsTimeout)
+	defer cancel()
+
+	if err := t.adminClient.CancelReassignments(ctx, t.topicName, partitionIDs); err != nil {
+		return err
+	}
+	t.changes.addCancelledReassignments(currAssignments, assignmentsToUpdate)
+
+	return t.removeThottles(ctx, throttledTopic, throttledBrokers)
+}
+
+// FormatStopSummary generates a summary of the state of a stopped or aborted apply from its
+// change report: the topics that were completed, the topic that was interrupted along with
+// its finished, in-flight, and cancelled reassignments, and the configs that weren't
+// started. Applies are idempotent, so re-running the same command resumes from this state.
+func FormatStopSummary(report *ChangeReport, notStarted []string) string {
+	lines := []string{"Apply stopped; state at the time of the stop:"}
+
+	completed := []string{}
+	for _, topicChanges := range report.Topics {
+		if !topicChanges.Stopped {
+			completed = append(completed, topicChanges.Topic)
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  Interrupted topic: %s", topicChanges.Topic))
+		if len(topicChanges.Reassignments) > 0 {
+			lines = append(
+				lines,
+				fmt.Sprintf(
+					"    Reassignments completed: %s",
+					formatReassignments(topicChanges.Reassignments),
+				),
+			)
+		}
+		if len(topicChanges.InFlightReassignments) > 0 {
+			lines = append(
+				lines,
+				fmt.Sprintf(
+					"    Reassignments still in progress: %s",
+					formatReassignments(topicChanges.InFlightReassignments),
+				),
+			)
+		}
+		if len(topicChanges.CancelledReassignments) > 0 {
+			lines = append(
+				lines,
+				fmt.Sprintf(
+					"    Reassignments cancelled: %s",
+					formatReassignments(topicChanges.CancelledReassignments),
+				),
+			)
+		}
+	}
+
+	if len(completed) > 0 {
+		lines = append(
+			lines,
+			fmt.Sprintf("  Completed topics: %s", strings.Join(completed, ", ")),
+		)
+	}
+	if len(notStarted) > 0 {
+		lines = append(
+			lines,
+			fmt.Sprintf("  Configs not started: %s", strings.Join(notStarted, ", ")),
+		)
+	}
+	lines = append(
+		lines,
+		"Re-run the same command to resume; changes that were already made won't be repeated.",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+func formatReassignments(reassignments []PartitionReassignment) string {
+	reassignmentStrs := []string{}
+	for _, reassignment := range reassignments {
+		reassignmentStrs = append(
+			reassignmentStrs,
+			fmt.Sprintf(
+				"%d %+v->%+v",
+				reassignment.Partition,
+				reassignment.OldReplicas,
+				reassignment.NewReplicas,
+			),
+		)
+	}
+	return strings.Join(reassignmentStrs, ", ")
+}
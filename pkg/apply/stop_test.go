@@ -0,0 +1,61 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplierStop(t *testing.T) {
+	stop := make(chan struct{})
+	applier := &TopicApplier{
+		config: TopicApplierConfig{
+			SkipConfirm: true,
+			Stop:        stop,
+		},
+		changes: &TopicChanges{},
+	}
+
+	ok, err := applier.confirm("OK to apply?", ApprovalCategoryConfig)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	close(stop)
+	ok, err = applier.confirm("OK to apply?", ApprovalCategoryConfig)
+	assert.Equal(t, ErrApplyStopped, err)
+	assert.False(t, ok)
+}
+
+func TestFormatStopSummary(t *testing.T) {
+	report := &ChangeReport{}
+	report.AddTopic("topic1", "test-cluster", "test-env")
+	topic2 := report.AddTopic("topic2", "test-cluster", "test-env")
+	topic2.Stopped = true
+	topic2.Reassignments = []PartitionReassignment{
+		{
+			Partition:   0,
+			OldReplicas: []int{1, 2},
+			NewReplicas: []int{3, 4},
+		},
+	}
+	topic2.InFlightReassignments = []PartitionReassignment{
+		{
+			Partition:   1,
+			OldReplicas: []int{2, 1},
+			NewReplicas: []int{4, 3},
+		},
+	}
+
+	assert.Equal(
+		t,
+		`Apply stopped; state at the time of the stop:
+  Interrupted topic: topic2
+    Reassignments completed: 0 [1 2]->[3 4]
+    Reassignments still in progress: 1 [2 1]->[4 3]
+  Completed topics: topic1
+  Configs not started: topic3.yaml
+Re-run the same command to resume; changes that were already made won't be repeated.`,
+		FormatStopSummary(report, []string{"topic3.yaml"}),
+	)
+}
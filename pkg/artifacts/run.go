@@ -0,0 +1,413 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// LogFileName is the name of the file in the run directory that stores the run logs.
+	LogFileName = "run.log"
+
+	// AuditFileName is the name of the file in the run directory that stores the audit
+	// entries, one JSON object per line.
+	AuditFileName = "audit.jsonl"
+
+	// SummaryFileName is the name of the file in the run directory that stores the run
+	// summary.
+	SummaryFileName = "summary.json"
+
+	// PlanDirName is the name of the subdirectory that stores copies of the configs that
+	// were applied, in the order they were processed.
+	PlanDirName = "plan"
+
+	// SnapshotsDirName is the name of the subdirectory that stores the before and after
+	// states of the resources touched by the run.
+	SnapshotsDirName = "snapshots"
+
+	runTimeFormat = "20060102-150405"
+)
+
+// Summary stores the high-level details of a run. It's written to the run directory
+// when the run finishes.
+type Summary struct {
+	ID        string    `json:"id"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	DryRun    bool      `json:"dryRun"`
+	Succeeded bool      `json:"succeeded"`
+	Error     string    `json:"error,omitempty"`
+	NumAudits int       `json:"numAudits"`
+}
+
+// AuditEntry records a single action taken (or skipped) as part of a run.
+type AuditEntry struct {
+	RunID      string    `json:"runID"`
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Host       string    `json:"host"`
+	Kind       string    `json:"kind"`
+	ConfigPath string    `json:"configPath"`
+	DryRun     bool      `json:"dryRun"`
+	Succeeded  bool      `json:"succeeded"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Run manages the artifact directory for a single invocation of a command like apply.
+// The directory contains the run logs, the configs that were applied, snapshots of
+// the affected resources, audit entries, and a summary.
+type Run struct {
+	sync.Mutex
+
+	Dir string
+
+	// ID is the correlation ID of the run. It's added to all log entries while the run is
+	// active, along with the audit entries and summary.
+	ID string
+
+	summary   Summary
+	logFile   *os.File
+	auditFile *os.File
+	origHooks log.LevelHooks
+	planIndex int
+}
+
+// NewRun creates a new, timestamped run directory under the argument root directory and
+// starts capturing logs into it.
+func NewRun(rootDir string, command string, args []string, dryRun bool) (*Run, error) {
+	startTime := time.Now()
+
+	dir := filepath.Join(
+		rootDir,
+		fmt.Sprintf("%s-%s", command, startTime.UTC().Format(runTimeFormat)),
+	)
+
+	// Multiple runs could be started in the same second
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		dir = filepath.Join(
+			rootDir,
+			fmt.Sprintf("%s-%s-%d", command, startTime.UTC().Format(runTimeFormat), i),
+		)
+	}
+
+	for _, subDir := range []string{PlanDirName, SnapshotsDirName} {
+		if err := os.MkdirAll(filepath.Join(dir, subDir), 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	logFile, err := os.Create(filepath.Join(dir, LogFileName))
+	if err != nil {
+		return nil, err
+	}
+	auditFile, err := os.Create(filepath.Join(dir, AuditFileName))
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+
+	id := logging.NewCorrelationID()
+
+	run := &Run{
+		Dir: dir,
+		ID:  id,
+		summary: Summary{
+			ID:        id,
+			Command:   command,
+			Args:      args,
+			User:      currentUser(),
+			Host:      currentHost(),
+			StartTime: startTime,
+			DryRun:    dryRun,
+		},
+		logFile:   logFile,
+		auditFile: auditFile,
+	}
+
+	// Copy the existing hooks so that they can be restored when the run finishes
+	run.origHooks = log.LevelHooks{}
+	for level, hooks := range log.StandardLogger().Hooks {
+		run.origHooks[level] = append([]log.Hook{}, hooks...)
+	}
+
+	// The correlation hook needs to go first so that the ID is in the run log too
+	log.AddHook(logging.NewCorrelationHook(id))
+
+	var formatter log.Formatter = &log.TextFormatter{
+		DisableColors:   true,
+		FullTimestamp:   true,
+		TimestampFormat: "2006-01-02 15:04:05",
+	}
+	if _, ok := log.StandardLogger().Formatter.(*log.JSONFormatter); ok {
+		formatter = &log.JSONFormatter{}
+	}
+	log.AddHook(&logHook{
+		writer:    logFile,
+		formatter: formatter,
+	})
+
+	return run, nil
+}
+
+// AddPlanFile copies the argument config file into the plan subdirectory of the run. Files
+// are prefixed with their index so that the processing order is preserved.
+func (r *Run) AddPlanFile(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.planIndex++
+	name := fmt.Sprintf("%03d-%s", r.planIndex, filepath.Base(path))
+	r.Unlock()
+
+	return ioutil.WriteFile(filepath.Join(r.Dir, PlanDirName, name), contents, 0644)
+}
+
+// AddSnapshot writes a JSON representation of the argument object into the snapshots
+// subdirectory of the run.
+func (r *Run) AddSnapshot(name string, obj interface{}) error {
+	contents, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(
+		filepath.Join(r.Dir, SnapshotsDirName, fmt.Sprintf("%s.json", name)),
+		contents,
+		0644,
+	)
+}
+
+// AddAuditEntry appends an entry to the run's audit log. The time, user, host, and
+// dry-run fields are filled in automatically.
+func (r *Run) AddAuditEntry(entry AuditEntry) error {
+	r.Lock()
+	defer r.Unlock()
+
+	entry.RunID = r.ID
+	entry.Time = time.Now()
+	entry.User = r.summary.User
+	entry.Host = r.summary.Host
+	entry.DryRun = r.summary.DryRun
+
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := r.auditFile.Write(append(contents, '\n')); err != nil {
+		return err
+	}
+
+	r.summary.NumAudits++
+	return nil
+}
+
+// Finish writes the run summary, stops capturing logs, and closes the run's files. The
+// argument error is the final result of the command, if any.
+func (r *Run) Finish(runErr error) error {
+	r.Lock()
+	defer r.Unlock()
+
+	log.StandardLogger().ReplaceHooks(r.origHooks)
+
+	r.summary.EndTime = time.Now()
+	r.summary.Succeeded = runErr == nil
+	if runErr != nil {
+		r.summary.Error = runErr.Error()
+	}
+
+	r.logFile.Close()
+	r.auditFile.Close()
+
+	contents, err := json.MarshalIndent(r.summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(r.Dir, SummaryFileName), contents, 0644)
+}
+
+// Bundle creates a gzipped tarball of the run directory next to it and returns its path.
+// It should be called after Finish.
+func (r *Run) Bundle() (string, error) {
+	bundlePath := fmt.Sprintf("%s.tar.gz", r.Dir)
+
+	bundleFile, err := os.Create(bundlePath)
+	if err != nil {
+		return "", err
+	}
+	defer bundleFile.Close()
+
+	gzipWriter := gzip.NewWriter(bundleFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	baseDir := filepath.Dir(r.Dir)
+
+	err = filepath.Walk(
+		r.Dir,
+		func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			header, err := tar.FileInfoHeader(info, "")
+			if err != nil {
+				return err
+			}
+			header.Name, err = filepath.Rel(baseDir, path)
+			if err != nil {
+				return err
+			}
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			_, err = io.Copy(tarWriter, file)
+			return err
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return "", err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+
+	return bundlePath, nil
+}
+
+// FinishedRun is a run directory that has a summary, i.e. one for a run that has finished.
+type FinishedRun struct {
+	Dir     string
+	Summary Summary
+}
+
+// LoadFinishedRuns returns the finished runs under the argument root directory, ordered by
+// start time. Directories without summaries are skipped, and a root directory that doesn't
+// exist is treated as empty.
+func LoadFinishedRuns(rootDir string) ([]FinishedRun, error) {
+	runs := []FinishedRun{}
+
+	fileInfos, err := ioutil.ReadDir(rootDir)
+	if os.IsNotExist(err) {
+		return runs, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, fileInfo := range fileInfos {
+		if !fileInfo.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(rootDir, fileInfo.Name())
+		contents, err := ioutil.ReadFile(filepath.Join(dir, SummaryFileName))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		run := FinishedRun{Dir: dir}
+		if err := json.Unmarshal(contents, &run.Summary); err != nil {
+			return nil, fmt.Errorf("Could not parse summary for run in %s: %+v", dir, err)
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(a, b int) bool {
+		return runs[a].Summary.StartTime.Before(runs[b].Summary.StartTime)
+	})
+
+	return runs, nil
+}
+
+// LoadSnapshot reads the snapshot with the argument name into obj. It returns false if the
+// run doesn't have a snapshot with that name.
+func (r FinishedRun) LoadSnapshot(name string, obj interface{}) (bool, error) {
+	contents, err := ioutil.ReadFile(
+		filepath.Join(r.Dir, SnapshotsDirName, fmt.Sprintf("%s.json", name)),
+	)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(contents, obj)
+}
+
+// logHook is a logrus hook that writes all log entries to a file.
+type logHook struct {
+	sync.Mutex
+
+	writer    io.Writer
+	formatter log.Formatter
+}
+
+func (h *logHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *logHook) Fire(entry *log.Entry) error {
+	contents, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	_, err = h.writer.Write(contents)
+	return err
+}
+
+func currentUser() string {
+	currUser, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+	return currUser.Username
+}
+
+func currentHost() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return host
+}
@@ -0,0 +1,142 @@
+package artifacts
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "artifacts")
+	require.Nil(t, err)
+	defer os.RemoveAll(rootDir)
+
+	configPath := filepath.Join(rootDir, "topic-test.yaml")
+	require.Nil(t, ioutil.WriteFile(configPath, []byte("meta:\n  name: topic-test\n"), 0644))
+
+	run, err := NewRun(rootDir, "apply", []string{configPath}, true)
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(run.Dir), "apply-"))
+
+	log.Info("test log message")
+
+	require.Nil(t, run.AddPlanFile(configPath))
+	require.Nil(t, run.AddSnapshot("topic-test-before", map[string]int{"partitions": 3}))
+	require.Nil(t, run.AddAuditEntry(AuditEntry{Kind: "topic", ConfigPath: configPath}))
+	require.Nil(
+		t,
+		run.AddAuditEntry(
+			AuditEntry{Kind: "topic", ConfigPath: configPath, Error: "test error"},
+		),
+	)
+	require.Nil(t, run.Finish(errors.New("test error")))
+
+	// Logs after the run is finished shouldn't be captured
+	log.Info("another log message")
+
+	logContents, err := ioutil.ReadFile(filepath.Join(run.Dir, LogFileName))
+	require.Nil(t, err)
+	assert.Contains(t, string(logContents), "test log message")
+	assert.Contains(t, string(logContents), "correlation_id="+run.ID)
+	assert.NotContains(t, string(logContents), "another log message")
+
+	planContents, err := ioutil.ReadFile(
+		filepath.Join(run.Dir, PlanDirName, "001-topic-test.yaml"),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, "meta:\n  name: topic-test\n", string(planContents))
+
+	auditFile, err := os.Open(filepath.Join(run.Dir, AuditFileName))
+	require.Nil(t, err)
+	defer auditFile.Close()
+
+	auditEntries := []AuditEntry{}
+	scanner := bufio.NewScanner(auditFile)
+	for scanner.Scan() {
+		entry := AuditEntry{}
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &entry))
+		auditEntries = append(auditEntries, entry)
+	}
+	require.Equal(t, 2, len(auditEntries))
+	assert.True(t, auditEntries[0].DryRun)
+	assert.Equal(t, run.ID, auditEntries[0].RunID)
+	assert.Equal(t, "test error", auditEntries[1].Error)
+
+	summaryContents, err := ioutil.ReadFile(filepath.Join(run.Dir, SummaryFileName))
+	require.Nil(t, err)
+	summary := Summary{}
+	require.Nil(t, json.Unmarshal(summaryContents, &summary))
+	assert.Equal(t, "apply", summary.Command)
+	assert.Equal(t, run.ID, summary.ID)
+	assert.False(t, summary.Succeeded)
+	assert.Equal(t, "test error", summary.Error)
+	assert.Equal(t, 2, summary.NumAudits)
+
+	bundlePath, err := run.Bundle()
+	require.Nil(t, err)
+
+	bundleFile, err := os.Open(bundlePath)
+	require.Nil(t, err)
+	defer bundleFile.Close()
+
+	gzipReader, err := gzip.NewReader(bundleFile)
+	require.Nil(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	names := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, strings.TrimPrefix(header.Name, filepath.Base(run.Dir)))
+	}
+	sort.Strings(names)
+
+	assert.Equal(
+		t,
+		[]string{
+			"",
+			"/audit.jsonl",
+			"/plan",
+			"/plan/001-topic-test.yaml",
+			"/run.log",
+			"/snapshots",
+			"/snapshots/topic-test-before.json",
+			"/summary.json",
+		},
+		names,
+	)
+
+	finishedRuns, err := LoadFinishedRuns(rootDir)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(finishedRuns))
+	assert.Equal(t, run.Dir, finishedRuns[0].Dir)
+	assert.Equal(t, run.ID, finishedRuns[0].Summary.ID)
+
+	snapshot := map[string]int{}
+	ok, err := finishedRuns[0].LoadSnapshot("topic-test-before", &snapshot)
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]int{"partitions": 3}, snapshot)
+
+	ok, err = finishedRuns[0].LoadSnapshot("topic-test-after", &snapshot)
+	require.Nil(t, err)
+	assert.False(t, ok)
+
+	finishedRuns, err = LoadFinishedRuns(filepath.Join(rootDir, "missing"))
+	require.Nil(t, err)
+	assert.Equal(t, []FinishedRun{}, finishedRuns)
+}
@@ -0,0 +1,289 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/groups"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Reads are retried with an exponential backoff after errors; after this many errors
+	// in a row, e.g. because the topic was deleted or the client isn't authorized to read
+	// it, the benchmark is stopped.
+	maxConsecutiveReadErrors = 10
+	readErrorInitBackoff     = 100 * time.Millisecond
+	readErrorMaxBackoff      = 5 * time.Second
+)
+
+// ConsumeConfig contains the configuration for a consume benchmark.
+type ConsumeConfig struct {
+	BrokerAddrs     []string
+	Topic           string
+	Duration        time.Duration
+	NumGroups       int
+	MembersPerGroup int
+	GroupPrefix     string
+	FromBeginning   bool
+
+	// LagInterval is how often the lag of each group is sampled. If zero, then lag isn't
+	// sampled.
+	LagInterval time.Duration
+}
+
+// ConsumeResults summarizes the outcome of a consume benchmark.
+type ConsumeResults struct {
+	Topic        string
+	Elapsed      time.Duration
+	GroupResults []ConsumeGroupResults
+}
+
+// ConsumeGroupResults summarizes the outcome of a consume benchmark for a single
+// consumer group.
+type ConsumeGroupResults struct {
+	GroupID          string
+	Members          int
+	MessagesConsumed int64
+	BytesConsumed    int64
+	Rebalances       int64
+	Errors           int64
+
+	// JoinTimes contains, for each member, the time between starting the member and
+	// receiving its first message. This includes the time to join the group and complete
+	// the initial rebalance.
+	JoinTimes LatencyStats
+
+	// MaxLag and FinalLag are the maximum and last sampled offset lags, summed across all
+	// partitions. These are -1 if the lag was never sampled.
+	MaxLag   int64
+	FinalLag int64
+}
+
+// Validate evaluates whether the consume config is valid.
+func (c ConsumeConfig) Validate() error {
+	if len(c.BrokerAddrs) == 0 {
+		return errors.New("At least one broker address must be set")
+	}
+	if c.Topic == "" {
+		return errors.New("Topic must be set")
+	}
+	if c.Duration <= 0 {
+		return errors.New("Duration must be positive")
+	}
+	if c.NumGroups <= 0 {
+		return errors.New("Number of groups must be positive")
+	}
+	if c.MembersPerGroup <= 0 {
+		return errors.New("Members per group must be positive")
+	}
+	if c.GroupPrefix == "" {
+		return errors.New("Group prefix must be set")
+	}
+	return nil
+}
+
+// RunConsumeBenchmark starts up the configured number of synthetic consumer groups, reads
+// from the topic for the configured duration, and returns statistics about each group.
+func RunConsumeBenchmark(ctx context.Context, config ConsumeConfig) (ConsumeResults, error) {
+	results := ConsumeResults{
+		Topic: config.Topic,
+	}
+
+	if err := config.Validate(); err != nil {
+		return results, err
+	}
+
+	startOffset := kafka.LastOffset
+	if config.FromBeginning {
+		startOffset = kafka.FirstOffset
+	}
+
+	benchCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	groupResults := make([]ConsumeGroupResults, config.NumGroups)
+	joinTimes := make([][]time.Duration, config.NumGroups)
+	mutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	// readErr is set if a member stops because of repeated read errors
+	var readErr error
+
+	startTime := time.Now()
+	runID := startTime.Unix()
+
+	for g := 0; g < config.NumGroups; g++ {
+		groupResults[g] = ConsumeGroupResults{
+			GroupID:  fmt.Sprintf("%s-%d-%d", config.GroupPrefix, runID, g),
+			Members:  config.MembersPerGroup,
+			MaxLag:   -1,
+			FinalLag: -1,
+		}
+
+		for m := 0; m < config.MembersPerGroup; m++ {
+			wg.Add(1)
+
+			go func(g int) {
+				defer wg.Done()
+
+				reader := kafka.NewReader(
+					kafka.ReaderConfig{
+						Brokers:        config.BrokerAddrs,
+						GroupID:        groupResults[g].GroupID,
+						Topic:          config.Topic,
+						MinBytes:       10e3, // 10KB
+						MaxBytes:       10e6, // 10MB
+						StartOffset:    startOffset,
+						CommitInterval: time.Second,
+					},
+				)
+
+				memberStart := time.Now()
+				var joinTime time.Duration
+				var messages, bytes, numErrors int64
+				var consecutiveErrors int
+				backoff := readErrorInitBackoff
+
+				for {
+					message, err := reader.ReadMessage(benchCtx)
+					if benchCtx.Err() != nil {
+						break
+					} else if err != nil {
+						log.Debugf("Error reading message: %+v", err)
+						numErrors++
+						consecutiveErrors++
+
+						if consecutiveErrors >= maxConsecutiveReadErrors {
+							mutex.Lock()
+							if readErr == nil {
+								readErr = err
+							}
+							mutex.Unlock()
+							cancel()
+							break
+						}
+
+						select {
+						case <-benchCtx.Done():
+						case <-time.After(backoff):
+						}
+						backoff *= 2
+						if backoff > readErrorMaxBackoff {
+							backoff = readErrorMaxBackoff
+						}
+						continue
+					}
+					consecutiveErrors = 0
+					backoff = readErrorInitBackoff
+
+					if messages == 0 {
+						joinTime = time.Since(memberStart)
+					}
+					messages++
+					bytes += int64(len(message.Key) + len(message.Value))
+				}
+
+				stats := reader.Stats()
+				if err := reader.Close(); err != nil {
+					log.Debugf("Error closing reader: %+v", err)
+				}
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				groupResults[g].MessagesConsumed += messages
+				groupResults[g].BytesConsumed += bytes
+				groupResults[g].Errors += numErrors
+				groupResults[g].Rebalances += stats.Rebalances
+				if messages > 0 {
+					joinTimes[g] = append(joinTimes[g], joinTime)
+				}
+			}(g)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var lagChan <-chan time.Time
+	if config.LagInterval > 0 {
+		lagTicker := time.NewTicker(config.LagInterval)
+		defer lagTicker.Stop()
+		lagChan = lagTicker.C
+	}
+
+	groupsClient := groups.NewClient(config.BrokerAddrs[0])
+
+loop:
+	for {
+		select {
+		case <-done:
+			break loop
+		case <-lagChan:
+			for g := range groupResults {
+				lag, err := groupLag(benchCtx, groupsClient, config.Topic, groupResults[g].GroupID)
+				if err != nil {
+					log.Debugf("Error getting lag for group %s: %+v", groupResults[g].GroupID, err)
+					continue
+				}
+
+				mutex.Lock()
+				groupResults[g].FinalLag = lag
+				if lag > groupResults[g].MaxLag {
+					groupResults[g].MaxLag = lag
+				}
+				mutex.Unlock()
+			}
+
+			log.Infof(
+				"Benchmark running for %s...",
+				time.Since(startTime).Round(time.Second),
+			)
+		}
+	}
+
+	results.Elapsed = time.Since(startTime)
+	for g := range groupResults {
+		groupResults[g].JoinTimes = ComputeLatencyStats(joinTimes[g])
+	}
+	results.GroupResults = groupResults
+
+	if readErr != nil {
+		return results, fmt.Errorf(
+			"Stopping because of %d consecutive errors reading from topic %s: %+v",
+			maxConsecutiveReadErrors,
+			config.Topic,
+			readErr,
+		)
+	}
+	return results, nil
+}
+
+func groupLag(
+	ctx context.Context,
+	groupsClient *groups.Client,
+	topic string,
+	groupID string,
+) (int64, error) {
+	partitionLags, err := groupsClient.GetMemberLags(ctx, topic, groupID)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalLag int64
+	for _, partitionLag := range partitionLags {
+		if partitionLag.MemberOffset < 0 {
+			// Offsets haven't been committed yet
+			continue
+		}
+		totalLag += partitionLag.OffsetLag()
+	}
+	return totalLag, nil
+}
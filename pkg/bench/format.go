@@ -0,0 +1,153 @@
+package bench
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// FormatProduceResults generates a pretty table from a ProduceResults instance.
+func FormatProduceResults(results ProduceResults) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Metric", "Value"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	var targetRate string
+	if results.TargetBytesRate > 0 {
+		targetRate = fmt.Sprintf("%s/sec", util.PrettyBytes(results.TargetBytesRate))
+	} else {
+		targetRate = "unlimited"
+	}
+
+	table.AppendBulk(
+		[][]string{
+			{"Elapsed", results.Elapsed.Round(time.Millisecond).String()},
+			{"Messages Sent", fmt.Sprintf("%d", results.MessagesSent)},
+			{"Bytes Sent", util.PrettyBytes(results.BytesSent)},
+			{"Failed Requests", fmt.Sprintf("%d", results.Errors)},
+			{"Target Throughput", targetRate},
+			{
+				"Achieved Throughput",
+				fmt.Sprintf(
+					"%s/sec (%0.1f messages/sec)",
+					util.PrettyBytes(int64(results.BytesRate())),
+					results.MessagesRate(),
+				),
+			},
+			{"Produce Requests", fmt.Sprintf("%d", results.Latencies.Count)},
+			{"Mean Latency", results.Latencies.Mean.Round(time.Microsecond).String()},
+			{"P50 Latency", results.Latencies.P50.Round(time.Microsecond).String()},
+			{"P99 Latency", results.Latencies.P99.Round(time.Microsecond).String()},
+			{"Max Latency", results.Latencies.Max.Round(time.Microsecond).String()},
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatConsumeResults generates a pretty table from a ConsumeResults instance.
+func FormatConsumeResults(results ConsumeResults) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Group",
+			"Members",
+			"Messages",
+			"Throughput",
+			"Rebalances",
+			"Errors",
+			"Join Time\n(P50/Max)",
+			"Lag\n(Max/Final)",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, groupResults := range results.GroupResults {
+		var throughput string
+		if results.Elapsed > 0 {
+			throughput = fmt.Sprintf(
+				"%s/sec\n(%0.1f messages/sec)",
+				util.PrettyBytes(
+					int64(float64(groupResults.BytesConsumed)/results.Elapsed.Seconds()),
+				),
+				float64(groupResults.MessagesConsumed)/results.Elapsed.Seconds(),
+			)
+		}
+
+		var joinTimes string
+		if groupResults.JoinTimes.Count > 0 {
+			joinTimes = fmt.Sprintf(
+				"%s/%s",
+				groupResults.JoinTimes.P50.Round(time.Millisecond),
+				groupResults.JoinTimes.Max.Round(time.Millisecond),
+			)
+		} else {
+			joinTimes = "no messages"
+		}
+
+		var lags string
+		if groupResults.MaxLag >= 0 {
+			lags = fmt.Sprintf("%d/%d", groupResults.MaxLag, groupResults.FinalLag)
+		} else {
+			lags = "unknown"
+		}
+
+		table.Append(
+			[]string{
+				groupResults.GroupID,
+				fmt.Sprintf("%d", groupResults.Members),
+				fmt.Sprintf("%d", groupResults.MessagesConsumed),
+				throughput,
+				fmt.Sprintf("%d", groupResults.Rebalances),
+				fmt.Sprintf("%d", groupResults.Errors),
+				joinTimes,
+				lags,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
@@ -0,0 +1,82 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// KeyDistribution is a string type that stores how message keys are chosen in a
+// produce benchmark.
+type KeyDistribution string
+
+const (
+	// KeyDistributionNone sends messages without keys, so that they're spread across all
+	// partitions in a round-robin fashion.
+	KeyDistributionNone KeyDistribution = "none"
+
+	// KeyDistributionUniform chooses keys uniformly at random from the key space.
+	KeyDistributionUniform KeyDistribution = "uniform"
+
+	// KeyDistributionZipf chooses keys from the key space according to a Zipf distribution,
+	// so that a small number of "hot" keys get most of the traffic.
+	KeyDistributionZipf KeyDistribution = "zipf"
+)
+
+// AllKeyDistributions contains all of the supported key distributions.
+var AllKeyDistributions = []KeyDistribution{
+	KeyDistributionNone,
+	KeyDistributionUniform,
+	KeyDistributionZipf,
+}
+
+// keyGenerator generates message keys according to a KeyDistribution. It is not
+// safe for concurrent use.
+type keyGenerator struct {
+	distribution KeyDistribution
+	numKeys      int
+	random       *rand.Rand
+	zipf         *rand.Zipf
+}
+
+func newKeyGenerator(
+	distribution KeyDistribution,
+	numKeys int,
+	seed int64,
+) (*keyGenerator, error) {
+	generator := &keyGenerator{
+		distribution: distribution,
+		numKeys:      numKeys,
+		random:       rand.New(rand.NewSource(seed)),
+	}
+
+	switch distribution {
+	case KeyDistributionNone:
+	case KeyDistributionUniform, KeyDistributionZipf:
+		if numKeys <= 0 {
+			return nil, fmt.Errorf("Number of keys must be positive for %s distribution", distribution)
+		}
+		if distribution == KeyDistributionZipf {
+			generator.zipf = rand.NewZipf(generator.random, 1.1, 1.0, uint64(numKeys-1))
+		}
+	default:
+		return nil, fmt.Errorf(
+			"Unrecognized key distribution %s; must be in %+v",
+			distribution,
+			AllKeyDistributions,
+		)
+	}
+
+	return generator, nil
+}
+
+// next returns the next key, or nil if keys aren't being used.
+func (k *keyGenerator) next() []byte {
+	switch k.distribution {
+	case KeyDistributionUniform:
+		return []byte(fmt.Sprintf("key-%d", k.random.Intn(k.numKeys)))
+	case KeyDistributionZipf:
+		return []byte(fmt.Sprintf("key-%d", k.zipf.Uint64()))
+	default:
+		return nil
+	}
+}
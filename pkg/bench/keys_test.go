@@ -0,0 +1,41 @@
+package bench
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyGenerator(t *testing.T) {
+	generator, err := newKeyGenerator(KeyDistributionNone, 0, 1)
+	require.NoError(t, err)
+	assert.Nil(t, generator.next())
+
+	_, err = newKeyGenerator(KeyDistributionUniform, 0, 1)
+	assert.Error(t, err)
+
+	_, err = newKeyGenerator(KeyDistribution("bad"), 10, 1)
+	assert.Error(t, err)
+
+	for _, distribution := range []KeyDistribution{
+		KeyDistributionUniform,
+		KeyDistributionZipf,
+	} {
+		generator, err := newKeyGenerator(distribution, 10, 1)
+		require.NoError(t, err)
+
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			counts[string(generator.next())]++
+		}
+
+		assert.LessOrEqual(t, len(counts), 10, string(distribution))
+		assert.Greater(t, len(counts), 1, string(distribution))
+
+		if distribution == KeyDistributionZipf {
+			// The lowest key should be much hotter than the highest one
+			assert.Greater(t, counts["key-0"], 5*counts["key-9"])
+		}
+	}
+}
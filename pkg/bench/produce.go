@@ -0,0 +1,230 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProduceConfig contains the configuration for a produce benchmark.
+type ProduceConfig struct {
+	BrokerAddrs     []string
+	Topic           string
+	Duration        time.Duration
+	MessageSize     int
+	BatchSize       int
+	NumWorkers      int
+	KeyDistribution KeyDistribution
+	NumKeys         int
+
+	// ThroughputBytes is the target throughput in bytes per second across all workers. If
+	// zero, then messages are produced as fast as possible.
+	ThroughputBytes int64
+}
+
+// ProduceResults summarizes the outcome of a produce benchmark.
+type ProduceResults struct {
+	Topic           string
+	Elapsed         time.Duration
+	MessagesSent    int64
+	BytesSent       int64
+	Errors          int64
+	TargetBytesRate int64
+
+	// Latencies is computed over individual produce requests, each of which contains
+	// BatchSize messages.
+	Latencies LatencyStats
+}
+
+// BytesRate returns the achieved throughput in bytes per second.
+func (r ProduceResults) BytesRate() float64 {
+	if r.Elapsed == 0 {
+		return 0
+	}
+	return float64(r.BytesSent) / r.Elapsed.Seconds()
+}
+
+// MessagesRate returns the achieved throughput in messages per second.
+func (r ProduceResults) MessagesRate() float64 {
+	if r.Elapsed == 0 {
+		return 0
+	}
+	return float64(r.MessagesSent) / r.Elapsed.Seconds()
+}
+
+// Validate evaluates whether the produce config is valid.
+func (c ProduceConfig) Validate() error {
+	if len(c.BrokerAddrs) == 0 {
+		return errors.New("At least one broker address must be set")
+	}
+	if c.Topic == "" {
+		return errors.New("Topic must be set")
+	}
+	if c.Duration <= 0 {
+		return errors.New("Duration must be positive")
+	}
+	if c.MessageSize <= 0 {
+		return errors.New("Message size must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return errors.New("Batch size must be positive")
+	}
+	if c.NumWorkers <= 0 {
+		return errors.New("Number of workers must be positive")
+	}
+	if c.ThroughputBytes < 0 {
+		return errors.New("Throughput cannot be negative")
+	}
+	return nil
+}
+
+// RunProduceBenchmark writes synthetic messages to a topic for the configured duration
+// and returns statistics about the achieved throughput and latency.
+func RunProduceBenchmark(ctx context.Context, config ProduceConfig) (ProduceResults, error) {
+	results := ProduceResults{
+		Topic:           config.Topic,
+		TargetBytesRate: config.ThroughputBytes,
+	}
+
+	if err := config.Validate(); err != nil {
+		return results, err
+	}
+
+	var balancer kafka.Balancer
+	if config.KeyDistribution == KeyDistributionNone {
+		balancer = &kafka.RoundRobin{}
+	} else {
+		balancer = &kafka.Hash{}
+	}
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:      config.BrokerAddrs,
+			Topic:        config.Topic,
+			Balancer:     balancer,
+			BatchSize:    config.BatchSize,
+			BatchTimeout: 5 * time.Millisecond,
+			RequiredAcks: -1,
+		},
+	)
+	defer writer.Close()
+
+	benchCtx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	workerRate := float64(config.ThroughputBytes) / float64(config.NumWorkers)
+	payload := make([]byte, config.MessageSize)
+	for i := range payload {
+		payload[i] = byte('a' + i%26)
+	}
+
+	mutex := sync.Mutex{}
+	latencies := []time.Duration{}
+	wg := sync.WaitGroup{}
+
+	startTime := time.Now()
+
+	for w := 0; w < config.NumWorkers; w++ {
+		generator, err := newKeyGenerator(
+			config.KeyDistribution,
+			config.NumKeys,
+			startTime.UnixNano()+int64(w),
+		)
+		if err != nil {
+			return results, err
+		}
+
+		wg.Add(1)
+
+		go func(generator *keyGenerator) {
+			defer wg.Done()
+
+			var workerBytes int64
+			workerLatencies := []time.Duration{}
+			var workerMessages, workerErrors int64
+
+			for benchCtx.Err() == nil {
+				messages := make([]kafka.Message, config.BatchSize)
+				for m := range messages {
+					messages[m] = kafka.Message{
+						Key:   generator.next(),
+						Value: payload,
+					}
+				}
+
+				writeStart := time.Now()
+				err := writer.WriteMessages(benchCtx, messages...)
+				if benchCtx.Err() != nil {
+					// Don't count writes that were cut off by the end of the run
+					break
+				}
+				workerLatencies = append(workerLatencies, time.Since(writeStart))
+
+				if err != nil {
+					log.Debugf("Error writing messages: %+v", err)
+					workerErrors++
+					continue
+				}
+
+				workerMessages += int64(len(messages))
+				workerBytes += int64(len(messages) * config.MessageSize)
+
+				if workerRate > 0 {
+					// Sleep until we're back on schedule for the target rate
+					expectedElapsed := time.Duration(
+						float64(workerBytes) / workerRate * float64(time.Second),
+					)
+					if sleepTime := expectedElapsed - time.Since(startTime); sleepTime > 0 {
+						select {
+						case <-benchCtx.Done():
+						case <-time.After(sleepTime):
+						}
+					}
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			latencies = append(latencies, workerLatencies...)
+			results.MessagesSent += workerMessages
+			results.BytesSent += workerBytes
+			results.Errors += workerErrors
+		}(generator)
+	}
+
+	logTicker := time.NewTicker(5 * time.Second)
+	defer logTicker.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			results.Elapsed = time.Since(startTime)
+			results.Latencies = ComputeLatencyStats(latencies)
+
+			if results.MessagesSent == 0 && results.Errors > 0 {
+				return results, fmt.Errorf(
+					"All %d produce requests to topic %s failed",
+					results.Errors,
+					config.Topic,
+				)
+			}
+			return results, nil
+		case <-logTicker.C:
+			log.Infof(
+				"Benchmark running for %s...",
+				time.Since(startTime).Round(time.Second),
+			)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bench
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LatencyStats summarizes a set of latency observations.
+type LatencyStats struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// ComputeLatencyStats generates a LatencyStats struct from the argument latencies. The
+// argument slice is not modified.
+func ComputeLatencyStats(latencies []time.Duration) LatencyStats {
+	stats := LatencyStats{
+		Count: len(latencies),
+	}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a] < sorted[b]
+	})
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	stats.Mean = total / time.Duration(len(sorted))
+	stats.P50 = percentile(sorted, 0.50)
+	stats.P99 = percentile(sorted, 0.99)
+	stats.Max = sorted[len(sorted)-1]
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile from an already-sorted slice.
+func percentile(sorted []time.Duration, fraction float64) time.Duration {
+	rank := int(math.Ceil(fraction*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,32 @@
+package bench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeLatencyStats(t *testing.T) {
+	assert.Equal(t, LatencyStats{}, ComputeLatencyStats(nil))
+
+	latencies := []time.Duration{}
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(
+		t,
+		LatencyStats{
+			Count: 100,
+			Mean:  50500 * time.Microsecond,
+			P50:   50 * time.Millisecond,
+			P99:   99 * time.Millisecond,
+			Max:   100 * time.Millisecond,
+		},
+		ComputeLatencyStats(latencies),
+	)
+
+	// Input should not be re-ordered
+	assert.Equal(t, 100*time.Millisecond, latencies[0])
+}
@@ -0,0 +1,193 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// BrokersCheckConfig contains all of the context necessary to check a brokers config.
+type BrokersCheckConfig struct {
+	AdminClient   *admin.Client
+	BrokersConfig config.BrokersConfig
+	ClusterConfig config.ClusterConfig
+	ValidateOnly  bool
+}
+
+// CheckBrokers compares the dynamic broker configs in the cluster against the argument
+// brokers config and returns a result. The results use the same types as the topic checks
+// so that they can be formatted in the same way.
+//
+// Unlike apply, which leaves keys that aren't in the brokers config alone, the check treats
+// these as drift since they're usually the result of manual changes, e.g. via
+// kafka-configs.sh. The replication throttles are the exception; these are managed by topic
+// applies, so they're only checked for brokers whose settings declare them.
+func CheckBrokers(
+	ctx context.Context,
+	checkConfig BrokersCheckConfig,
+) (TopicCheckResults, error) {
+	results := TopicCheckResults{}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConfigCorrect,
+		},
+	)
+	if err := checkConfig.BrokersConfig.Validate(); err == nil {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf("config validation error: %+v", err),
+		)
+		return results, nil
+	}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConfigsConsistent,
+		},
+	)
+	if err := config.CheckBrokersConsistency(
+		checkConfig.BrokersConfig,
+		checkConfig.ClusterConfig,
+	); err == nil {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf("config consistency error: %+v", err),
+		)
+		return results, nil
+	}
+
+	if checkConfig.ValidateOnly {
+		return results, nil
+	}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameBrokerDefaultsCorrect,
+		},
+	)
+	clusterDefaults, err := checkConfig.AdminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		return results, err
+	}
+	drift, err := BrokerSettingsDrift(
+		checkConfig.BrokersConfig.Spec.DefaultSettings,
+		clusterDefaults,
+	)
+	if err != nil {
+		return results, err
+	}
+	if len(drift) == 0 {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(false, strings.Join(drift, "; "))
+	}
+
+	brokers, err := checkConfig.AdminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return results, err
+	}
+	brokersMap := map[int]admin.BrokerInfo{}
+	for _, broker := range brokers {
+		brokersMap[broker.ID] = broker
+	}
+
+	// Check all of the brokers in the cluster, plus the ones in the config that aren't
+	// in the cluster
+	brokerIDs := checkConfig.BrokersConfig.BrokerIDs()
+	for _, broker := range brokers {
+		if checkConfig.BrokersConfig.SettingsForBroker(broker.ID) == nil {
+			brokerIDs = append(brokerIDs, broker.ID)
+		}
+	}
+	sort.Ints(brokerIDs)
+
+	for _, brokerID := range brokerIDs {
+		results.AppendResult(
+			TopicCheckResult{
+				Name: CheckNameBrokerSettingsCorrect,
+			},
+		)
+
+		broker, ok := brokersMap[brokerID]
+		if !ok {
+			results.UpdateLastResult(
+				false,
+				fmt.Sprintf("broker %d: in brokers config but not in cluster", brokerID),
+			)
+			continue
+		}
+
+		drift, err := BrokerSettingsDrift(
+			checkConfig.BrokersConfig.SettingsForBroker(brokerID),
+			broker.Config,
+		)
+		if err != nil {
+			return results, err
+		}
+		if len(drift) == 0 {
+			results.UpdateLastResult(true, fmt.Sprintf("broker %d", brokerID))
+		} else {
+			results.UpdateLastResult(
+				false,
+				fmt.Sprintf("broker %d: %s", brokerID, strings.Join(drift, "; ")),
+			)
+		}
+	}
+
+	return results, nil
+}
+
+// BrokerSettingsDrift returns a description of each difference between the argument
+// settings from a brokers config and the dynamic config of a broker (or the cluster-wide
+// defaults) in the cluster, sorted by key. Replication throttles that aren't in the
+// settings are ignored.
+func BrokerSettingsDrift(
+	settings config.BrokerSettings,
+	currConfig map[string]string,
+) ([]string, error) {
+	diffKeys, missingKeys, err := settings.ConfigMapDiffs(currConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	drift := []string{}
+
+	for _, key := range diffKeys {
+		value, err := settings.ToTopicSettings().GetValueStr(key)
+		if err != nil {
+			return nil, err
+		}
+
+		currValue, ok := currConfig[key]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s not set in cluster (config=%s)", key, value))
+			continue
+		}
+		drift = append(
+			drift,
+			fmt.Sprintf("%s differs (cluster=%s, config=%s)", key, currValue, value),
+		)
+	}
+
+	for _, key := range missingKeys {
+		if key == admin.LeaderThrottledKey || key == admin.FollowerThrottledKey {
+			continue
+		}
+		drift = append(
+			drift,
+			fmt.Sprintf("%s set in cluster but not in config (cluster=%s)", key, currConfig[key]),
+		)
+	}
+
+	sort.Strings(drift)
+	return drift, nil
+}
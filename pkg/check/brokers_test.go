@@ -0,0 +1,106 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerSettingsDrift(t *testing.T) {
+	drift, err := BrokerSettingsDrift(
+		config.BrokerSettings{
+			"log.cleaner.threads":  2,
+			"num.replica.fetchers": 4,
+			"num.io.threads":       8,
+		},
+		map[string]string{
+			"log.cleaner.threads":                 "2",
+			"num.replica.fetchers":                "2",
+			"message.max.bytes":                   "2000000",
+			"follower.replication.throttled.rate": "1000000",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"message.max.bytes set in cluster but not in config (cluster=2000000)",
+			"num.io.threads not set in cluster (config=8)",
+			"num.replica.fetchers differs (cluster=2, config=4)",
+		},
+		drift,
+	)
+
+	// Throttles are only checked if they're declared
+	drift, err = BrokerSettingsDrift(
+		config.BrokerSettings{
+			"follower.replication.throttled.rate": 2000000,
+		},
+		map[string]string{
+			"follower.replication.throttled.rate": "1000000",
+			"leader.replication.throttled.rate":   "1000000",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"follower.replication.throttled.rate differs (cluster=1000000, config=2000000)",
+		},
+		drift,
+	)
+
+	drift, err = BrokerSettingsDrift(nil, map[string]string{})
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+}
+
+func TestCheckBrokersValidateOnly(t *testing.T) {
+	clusterConfig := config.ClusterConfig{
+		Meta: config.ClusterMeta{
+			Name:        "test-cluster",
+			Region:      "test-region",
+			Environment: "test-env",
+		},
+	}
+	brokersConfig := config.BrokersConfig{
+		Meta: config.BrokersMeta{
+			Cluster:     "test-cluster",
+			Region:      "test-region",
+			Environment: "test-env",
+		},
+		Spec: config.BrokersSpec{
+			DefaultSettings: config.BrokerSettings{
+				"log.cleaner.threads": 2,
+			},
+		},
+	}
+
+	results, err := CheckBrokers(
+		context.Background(),
+		BrokersCheckConfig{
+			BrokersConfig: brokersConfig,
+			ClusterConfig: clusterConfig,
+			ValidateOnly:  true,
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, results.AllOK())
+	assert.Equal(t, 2, len(results.Results))
+
+	brokersConfig.Meta.Environment = "other-env"
+	results, err = CheckBrokers(
+		context.Background(),
+		BrokersCheckConfig{
+			BrokersConfig: brokersConfig,
+			ClusterConfig: clusterConfig,
+			ValidateOnly:  true,
+		},
+	)
+	require.NoError(t, err)
+	assert.False(t, results.AllOK())
+	assert.False(t, results.ValidationOK())
+}
@@ -4,20 +4,33 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply/assigners"
 	"github.com/segmentio/topicctl/pkg/config"
 	tconfig "github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/schemas"
 )
 
 // CheckConfig contains all of the context necessary to check a single topic config.
 type CheckConfig struct {
-	AdminClient   *admin.Client
-	ClusterConfig config.ClusterConfig
-	CheckLeaders  bool
-	NumRacks      int
-	TopicConfig   config.TopicConfig
-	ValidateOnly  bool
+	AdminClient    *admin.Client
+	ClusterConfig  config.ClusterConfig
+	CheckLeaders   bool
+	CheckPlacement bool
+	NumRacks       int
+	TopicConfig    config.TopicConfig
+	ValidateOnly   bool
+
+	// RecreatedWindow is how long after being re-created that topics are flagged; 0 means
+	// no limit.
+	RecreatedWindow time.Duration
+
+	// ClusterState, if set, is used instead of looking up the cluster metadata via the
+	// admin client.
+	ClusterState *ClusterState
 }
 
 // CheckTopic runs the topic check and returns a result. If there's a non-topic-specific error
@@ -70,7 +83,7 @@ func CheckTopic(ctx context.Context, config CheckConfig) (TopicCheckResults, err
 		},
 	)
 
-	topicInfo, err := config.AdminClient.GetTopic(ctx, config.TopicConfig.Meta.Name, true)
+	topicInfo, err := getTopic(ctx, config)
 	if err != nil {
 		// Don't bother with remaining checks if we can't get the topic
 		if err == admin.ErrTopicDoesNotExist {
@@ -81,6 +94,40 @@ func CheckTopic(ctx context.Context, config CheckConfig) (TopicCheckResults, err
 	}
 	results.UpdateLastResult(true, "")
 
+	// Check whether the topic was re-created since it was last applied, based on its ID
+	// (available in Kafka 2.8 and later) or its creation time
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameTopicNotRecreated,
+		},
+	)
+	recordedTopic, err := getRecordedTopic(ctx, config)
+	if err != nil {
+		return results, err
+	}
+
+	if !recordedTopic.RecreatedWithin(topicInfo, config.RecreatedWindow, time.Now()) {
+		results.UpdateLastResult(true, "")
+	} else if recordedTopic.ID != "" && topicInfo.TopicID != "" {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf(
+				"topic ID changed from %s to %s at %s since the last apply; consumer group offsets may be invalid",
+				recordedTopic.ID,
+				topicInfo.TopicID,
+				topicInfo.CreatedAt.Format(time.RFC3339),
+			),
+		)
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf(
+				"topic was re-created at %s since the last apply; consumer group offsets may be invalid",
+				topicInfo.CreatedAt.Format(time.RFC3339),
+			),
+		)
+	}
+
 	// Check retention
 	results.AppendResult(
 		TopicCheckResult{
@@ -199,6 +246,117 @@ func CheckTopic(ctx context.Context, config CheckConfig) (TopicCheckResults, err
 		)
 	}
 
+	// Check min ISR
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameMinISRCompatible,
+		},
+	)
+	clusterDefaults, err := getClusterDefaults(ctx, config)
+	if err != nil {
+		return results, err
+	}
+	minISRStatus, err := admin.GetMinISRStatus(topicInfo, clusterDefaults)
+	if err != nil {
+		return results, err
+	}
+
+	if minISRStatus.OK() {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(false, minISRStatus.Summary())
+	}
+
+	// Check racks
+	expectedRacks := config.TopicConfig.ExpectedRacks()
+	if expectedRacks > 0 {
+		results.AppendResult(
+			TopicCheckResult{
+				Name: CheckNameRacksCorrect,
+			},
+		)
+		brokers, err := getBrokers(ctx, config)
+		if err != nil {
+			return results, err
+		}
+		rackViolations := topicInfo.RackViolations(brokers, expectedRacks)
+
+		if len(rackViolations) == 0 {
+			results.UpdateLastResult(true, "")
+		} else {
+			results.UpdateLastResult(
+				false,
+				fmt.Sprintf(
+					"%d/%d partitions are not spread across %d rack(s): %+v",
+					len(rackViolations),
+					len(topicInfo.Partitions),
+					expectedRacks,
+					admin.PartitionIDs(rackViolations),
+				),
+			)
+		}
+	}
+
+	// Check placement
+	if config.CheckPlacement {
+		results.AppendResult(
+			TopicCheckResult{
+				Name: CheckNamePlacementCorrect,
+			},
+		)
+		brokers, err := getBrokers(ctx, config)
+		if err != nil {
+			return results, err
+		}
+
+		placementConfig := config.TopicConfig.Spec.PlacementConfig
+		assignments := topicInfo.ToAssignments()
+
+		violations, err := assigners.PlacementViolations(assignments, brokers, placementConfig)
+		if err != nil {
+			return results, err
+		}
+		satisfied, err := assigners.EvaluateAssignments(
+			assignments,
+			assigners.FilterBrokers(brokers, placementConfig.ExcludeBrokers),
+			placementConfig,
+		)
+		if err != nil {
+			return results, err
+		}
+
+		if len(violations) > 0 {
+			reasons := []string{}
+			for _, violation := range violations {
+				reasons = append(
+					reasons,
+					fmt.Sprintf("partition %d (%s)", violation.Partition, violation.Reason),
+				)
+			}
+
+			results.UpdateLastResult(
+				false,
+				fmt.Sprintf(
+					"%d/%d partitions don't satisfy strategy '%s': %s",
+					len(violations),
+					len(topicInfo.Partitions),
+					placementConfig.Strategy,
+					strings.Join(reasons, ", "),
+				),
+			)
+		} else if !satisfied {
+			results.UpdateLastResult(
+				false,
+				fmt.Sprintf(
+					"partition assignments don't satisfy strategy '%s'",
+					placementConfig.Strategy,
+				),
+			)
+		} else {
+			results.UpdateLastResult(true, "")
+		}
+	}
+
 	// Check leaders
 	if config.CheckLeaders {
 		results.AppendResult(
@@ -222,5 +380,47 @@ func CheckTopic(ctx context.Context, config CheckConfig) (TopicCheckResults, err
 		}
 	}
 
+	// Check schemas
+	schemasConfig := config.TopicConfig.Spec.SchemasConfig
+	if schemasConfig != nil {
+		results.AppendResult(
+			TopicCheckResult{
+				Name: CheckNameSchemasCorrect,
+			},
+		)
+		schemasClient := schemas.NewClient(config.ClusterConfig.Spec.SchemaRegistryURL)
+
+		problems := []string{}
+
+		for _, subject := range schemasConfig.Subjects() {
+			status, err := schemasClient.GetSubjectStatus(ctx, subject)
+			if err != nil {
+				return results, err
+			}
+
+			if !status.Registered {
+				problems = append(problems, fmt.Sprintf("subject %s not registered", subject))
+			}
+			if schemasConfig.Compatibility != "" &&
+				status.Compatibility != schemasConfig.Compatibility {
+				problems = append(
+					problems,
+					fmt.Sprintf(
+						"subject %s has compatibility %s, expected %s",
+						subject,
+						status.Compatibility,
+						schemasConfig.Compatibility,
+					),
+				)
+			}
+		}
+
+		if len(problems) == 0 {
+			results.UpdateLastResult(true, "")
+		} else {
+			results.UpdateLastResult(false, strings.Join(problems, "; "))
+		}
+	}
+
 	return results, nil
 }
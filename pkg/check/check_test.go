@@ -1,4 +1,4 @@
-package check
+package check_test
 
 import (
 	"context"
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/check"
 	"github.com/segmentio/topicctl/pkg/config"
 	"github.com/segmentio/topicctl/pkg/util"
 	"github.com/stretchr/testify/assert"
@@ -73,7 +74,7 @@ func TestCheck(t *testing.T) {
 	type testCase struct {
 		description      string
 		checkTopicConfig config.TopicConfig
-		expectedResults  map[CheckName]bool
+		expectedResults  map[check.CheckName]bool
 		validateOnly     bool
 	}
 
@@ -81,24 +82,25 @@ func TestCheck(t *testing.T) {
 		{
 			description:      "all good",
 			checkTopicConfig: topicConfig,
-			expectedResults: map[CheckName]bool{
-				CheckNameConfigCorrect:            true,
-				CheckNameConfigsConsistent:        true,
-				CheckNameTopicExists:              true,
-				CheckNameConfigSettingsCorrect:    true,
-				CheckNameReplicationFactorCorrect: true,
-				CheckNamePartitionCountCorrect:    true,
-				CheckNameThrottlesClear:           true,
-				CheckNameReplicasInSync:           true,
-				CheckNameLeadersCorrect:           true,
+			expectedResults: map[check.CheckName]bool{
+				check.CheckNameConfigCorrect:            true,
+				check.CheckNameConfigsConsistent:        true,
+				check.CheckNameTopicExists:              true,
+				check.CheckNameConfigSettingsCorrect:    true,
+				check.CheckNameReplicationFactorCorrect: true,
+				check.CheckNamePartitionCountCorrect:    true,
+				check.CheckNameThrottlesClear:           true,
+				check.CheckNameReplicasInSync:           true,
+				check.CheckNameMinISRCompatible:         true,
+				check.CheckNameLeadersCorrect:           true,
 			},
 		},
 		{
 			description:      "all good (validate only)",
 			checkTopicConfig: topicConfig,
-			expectedResults: map[CheckName]bool{
-				CheckNameConfigCorrect:     true,
-				CheckNameConfigsConsistent: true,
+			expectedResults: map[check.CheckName]bool{
+				check.CheckNameConfigCorrect:     true,
+				check.CheckNameConfigsConsistent: true,
 			},
 			validateOnly: true,
 		},
@@ -125,9 +127,9 @@ func TestCheck(t *testing.T) {
 					},
 				},
 			},
-			expectedResults: map[CheckName]bool{
-				CheckNameConfigCorrect:     true,
-				CheckNameConfigsConsistent: false,
+			expectedResults: map[check.CheckName]bool{
+				check.CheckNameConfigCorrect:     true,
+				check.CheckNameConfigsConsistent: false,
 			},
 		},
 		{
@@ -153,10 +155,10 @@ func TestCheck(t *testing.T) {
 					},
 				},
 			},
-			expectedResults: map[CheckName]bool{
-				CheckNameConfigCorrect:     true,
-				CheckNameConfigsConsistent: true,
-				CheckNameTopicExists:       false,
+			expectedResults: map[check.CheckName]bool{
+				check.CheckNameConfigCorrect:     true,
+				check.CheckNameConfigsConsistent: true,
+				check.CheckNameTopicExists:       false,
 			},
 		},
 		{
@@ -182,24 +184,25 @@ func TestCheck(t *testing.T) {
 					},
 				},
 			},
-			expectedResults: map[CheckName]bool{
-				CheckNameConfigCorrect:            true,
-				CheckNameConfigsConsistent:        true,
-				CheckNameTopicExists:              true,
-				CheckNameConfigSettingsCorrect:    false,
-				CheckNameReplicationFactorCorrect: false,
-				CheckNamePartitionCountCorrect:    false,
-				CheckNameThrottlesClear:           true,
-				CheckNameReplicasInSync:           true,
-				CheckNameLeadersCorrect:           true,
+			expectedResults: map[check.CheckName]bool{
+				check.CheckNameConfigCorrect:            true,
+				check.CheckNameConfigsConsistent:        true,
+				check.CheckNameTopicExists:              true,
+				check.CheckNameConfigSettingsCorrect:    false,
+				check.CheckNameReplicationFactorCorrect: false,
+				check.CheckNamePartitionCountCorrect:    false,
+				check.CheckNameThrottlesClear:           true,
+				check.CheckNameReplicasInSync:           true,
+				check.CheckNameMinISRCompatible:         true,
+				check.CheckNameLeadersCorrect:           true,
 			},
 		},
 	}
 
 	for _, testCase := range testCases {
-		results, err := CheckTopic(
+		results, err := check.CheckTopic(
 			ctx,
-			CheckConfig{
+			check.CheckConfig{
 				AdminClient:   adminClient,
 				ClusterConfig: clusterConfig,
 				CheckLeaders:  true,
@@ -210,7 +213,7 @@ func TestCheck(t *testing.T) {
 		)
 		require.Nil(t, err, testCase.description)
 
-		resultsSummary := map[CheckName]bool{}
+		resultsSummary := map[check.CheckName]bool{}
 		for _, result := range results.Results {
 			resultsSummary[result.Name] = result.OK
 		}
@@ -0,0 +1,128 @@
+package check
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/connect"
+)
+
+// ConnectorCheckConfig contains all of the context necessary to check a single connector
+// config.
+type ConnectorCheckConfig struct {
+	ClusterConfig   config.ClusterConfig
+	ConnectorConfig config.ConnectorConfig
+	ValidateOnly    bool
+}
+
+// CheckConnector runs the connector check and returns a result. The results use the same
+// types as the topic checks so that they can be formatted in the same way.
+func CheckConnector(
+	ctx context.Context,
+	checkConfig ConnectorCheckConfig,
+) (TopicCheckResults, error) {
+	results := TopicCheckResults{}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConfigCorrect,
+		},
+	)
+	if err := checkConfig.ConnectorConfig.Validate(); err == nil {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf("config validation error: %+v", err),
+		)
+		return results, nil
+	}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConfigsConsistent,
+		},
+	)
+	if err := config.CheckConnectorConsistency(
+		checkConfig.ConnectorConfig,
+		checkConfig.ClusterConfig,
+	); err == nil {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf("config consistency error: %+v", err),
+		)
+		return results, nil
+	}
+
+	if checkConfig.ValidateOnly {
+		return results, nil
+	}
+
+	connectClient := connect.NewClient(checkConfig.ClusterConfig.Spec.ConnectURL)
+	name := checkConfig.ConnectorConfig.Meta.Name
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConnectorExists,
+		},
+	)
+	currConfig, err := connectClient.GetConnectorConfig(ctx, name)
+	if err == connect.ErrConnectorNotFound {
+		results.UpdateLastResult(false, "")
+		return results, nil
+	} else if err != nil {
+		return results, err
+	}
+	results.UpdateLastResult(true, "")
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConfigSettingsCorrect,
+		},
+	)
+	desiredConfig, err := checkConfig.ConnectorConfig.ToConnectConfig()
+	if err != nil {
+		return results, err
+	}
+	diffKeys := connect.ConfigDiffs(desiredConfig, currConfig)
+	if len(diffKeys) == 0 {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf(
+				"%d keys have different values between cluster and connector config: %v",
+				len(diffKeys),
+				diffKeys,
+			),
+		)
+	}
+
+	results.AppendResult(
+		TopicCheckResult{
+			Name: CheckNameConnectorRunning,
+		},
+	)
+	status, err := connectClient.GetConnectorStatus(ctx, name)
+	if err != nil {
+		return results, err
+	}
+	if status.Running() {
+		results.UpdateLastResult(true, "")
+	} else {
+		results.UpdateLastResult(
+			false,
+			fmt.Sprintf(
+				"connector state is %s, %d/%d tasks running",
+				status.Connector.State,
+				status.NumTasksRunning(),
+				len(status.Tasks),
+			),
+		)
+	}
+
+	return results, nil
+}
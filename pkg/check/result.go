@@ -5,15 +5,24 @@ type CheckName string
 
 const (
 	// All possible CheckName values.
+	CheckNameBrokerDefaultsCorrect    CheckName = "broker defaults correct"
+	CheckNameBrokerSettingsCorrect    CheckName = "broker settings correct"
 	CheckNameConfigsConsistent        CheckName = "configs consistent"
 	CheckNameConfigCorrect            CheckName = "config correct"
 	CheckNameConfigSettingsCorrect    CheckName = "config settings correct"
+	CheckNameConnectorExists          CheckName = "connector exists"
+	CheckNameConnectorRunning         CheckName = "connector running"
 	CheckNameLeadersCorrect           CheckName = "leaders correct"
+	CheckNameMinISRCompatible         CheckName = "min ISR compatible"
 	CheckNamePartitionCountCorrect    CheckName = "partition count correct"
+	CheckNamePlacementCorrect         CheckName = "placement correct"
+	CheckNameRacksCorrect             CheckName = "replica racks correct"
 	CheckNameReplicasInSync           CheckName = "replicas in-sync"
 	CheckNameReplicationFactorCorrect CheckName = "replication factor correct"
+	CheckNameSchemasCorrect           CheckName = "schemas correct"
 	CheckNameThrottlesClear           CheckName = "throttles clear"
 	CheckNameTopicExists              CheckName = "topic exists"
+	CheckNameTopicNotRecreated        CheckName = "topic not re-created"
 )
 
 // TopicCheckResults stores the result of checking a single topic.
@@ -39,6 +48,19 @@ func (r *TopicCheckResults) AllOK() bool {
 	return true
 }
 
+// ValidationOK returns true if the checks of the configs themselves, as opposed to the
+// checks against the cluster state, are OK.
+func (r *TopicCheckResults) ValidationOK() bool {
+	for _, result := range r.Results {
+		if !result.OK &&
+			(result.Name == CheckNameConfigCorrect || result.Name == CheckNameConfigsConsistent) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // AppendResult adds a new check result to the results.
 func (r *TopicCheckResults) AppendResult(result TopicCheckResult) {
 	r.Results = append(r.Results, result)
@@ -0,0 +1,108 @@
+package check
+
+import (
+	"context"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+)
+
+// ClusterState is a snapshot of the cluster metadata that topic checks use. When checking
+// many topics in the same cluster, it can be fetched once via GetClusterState and shared
+// across the checks instead of each check looking up the same metadata.
+type ClusterState struct {
+	Brokers         []admin.BrokerInfo
+	ClusterDefaults map[string]string
+	RecordedTopics  map[string]admin.RecordedTopic
+
+	// Topics contains the detailed info of the topics that the state was fetched for,
+	// keyed by name; topics that don't exist in the cluster are omitted.
+	Topics map[string]admin.TopicInfo
+}
+
+// GetClusterState fetches the cluster metadata used to check the argument topics.
+func GetClusterState(
+	ctx context.Context,
+	adminClient *admin.Client,
+	topicNames []string,
+) (*ClusterState, error) {
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	clusterDefaults, err := adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recordedTopics, err := adminClient.GetRecordedTopics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only look up the topics that exist since GetTopics fails on missing ones
+	allTopicNames, err := adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allTopicNamesMap := map[string]struct{}{}
+	for _, name := range allTopicNames {
+		allTopicNamesMap[name] = struct{}{}
+	}
+
+	existingNames := []string{}
+	for _, name := range topicNames {
+		if _, ok := allTopicNamesMap[name]; ok {
+			existingNames = append(existingNames, name)
+		}
+	}
+
+	topics := map[string]admin.TopicInfo{}
+	if len(existingNames) > 0 {
+		topicInfos, err := adminClient.ListTopics(ctx, existingNames, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, topicInfo := range topicInfos {
+			topics[topicInfo.Name] = topicInfo
+		}
+	}
+
+	return &ClusterState{
+		Brokers:         brokers,
+		ClusterDefaults: clusterDefaults,
+		RecordedTopics:  recordedTopics,
+		Topics:          topics,
+	}, nil
+}
+
+func getTopic(ctx context.Context, config CheckConfig) (admin.TopicInfo, error) {
+	if config.ClusterState == nil {
+		return config.AdminClient.GetTopic(ctx, config.TopicConfig.Meta.Name, true)
+	}
+
+	topicInfo, ok := config.ClusterState.Topics[config.TopicConfig.Meta.Name]
+	if !ok {
+		return admin.TopicInfo{}, admin.ErrTopicDoesNotExist
+	}
+	return topicInfo, nil
+}
+
+func getRecordedTopic(ctx context.Context, config CheckConfig) (admin.RecordedTopic, error) {
+	if config.ClusterState == nil {
+		return config.AdminClient.GetRecordedTopic(ctx, config.TopicConfig.Meta.Name)
+	}
+	return config.ClusterState.RecordedTopics[config.TopicConfig.Meta.Name], nil
+}
+
+func getBrokers(ctx context.Context, config CheckConfig) ([]admin.BrokerInfo, error) {
+	if config.ClusterState == nil {
+		return config.AdminClient.GetBrokers(ctx, nil)
+	}
+	return config.ClusterState.Brokers, nil
+}
+
+func getClusterDefaults(ctx context.Context, config CheckConfig) (map[string]string, error) {
+	if config.ClusterState == nil {
+		return config.AdminClient.GetClusterDefaultConfig(ctx)
+	}
+	return config.ClusterState.ClusterDefaults, nil
+}
@@ -0,0 +1,102 @@
+package check
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTopicClusterState(t *testing.T) {
+	clusterConfig := config.ClusterConfig{
+		Meta: config.ClusterMeta{
+			Name:        "test-cluster",
+			Region:      "test-region",
+			Environment: "test-environment",
+		},
+	}
+	topicConfig := config.TopicConfig{
+		Meta: config.TopicMeta{
+			Name:        "test-topic",
+			Cluster:     "test-cluster",
+			Region:      "test-region",
+			Environment: "test-environment",
+		},
+		Spec: config.TopicSpec{
+			Partitions:        2,
+			ReplicationFactor: 2,
+			RetentionMinutes:  500,
+			PlacementConfig: config.TopicPlacementConfig{
+				Strategy: config.PlacementStrategyAny,
+				Picker:   config.PickerMethodLowestIndex,
+			},
+		},
+	}
+
+	// No admin client is set, so all of the cluster metadata has to come from the state
+	clusterState := &ClusterState{
+		Brokers: []admin.BrokerInfo{
+			{ID: 1, Rack: "rack1"},
+			{ID: 2, Rack: "rack2"},
+		},
+		ClusterDefaults: map[string]string{},
+		RecordedTopics:  map[string]admin.RecordedTopic{},
+		Topics: map[string]admin.TopicInfo{
+			"test-topic": {
+				Name: "test-topic",
+				Config: map[string]string{
+					admin.RetentionKey: "30000000",
+				},
+				Partitions: []admin.PartitionInfo{
+					{
+						Topic:    "test-topic",
+						ID:       0,
+						Leader:   1,
+						Replicas: []int{1, 2},
+						ISR:      []int{1, 2},
+					},
+					{
+						Topic:    "test-topic",
+						ID:       1,
+						Leader:   2,
+						Replicas: []int{2, 1},
+						ISR:      []int{2, 1},
+					},
+				},
+			},
+		},
+	}
+
+	results, err := CheckTopic(
+		context.Background(),
+		CheckConfig{
+			CheckLeaders:   true,
+			CheckPlacement: true,
+			ClusterConfig:  clusterConfig,
+			ClusterState:   clusterState,
+			NumRacks:       -1,
+			TopicConfig:    topicConfig,
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, results.AllOK(), FormatResults(results))
+
+	missingConfig := topicConfig
+	missingConfig.Meta.Name = "missing-topic"
+
+	results, err = CheckTopic(
+		context.Background(),
+		CheckConfig{
+			ClusterConfig: clusterConfig,
+			ClusterState:  clusterState,
+			NumRacks:      -1,
+			TopicConfig:   missingConfig,
+		},
+	)
+	require.NoError(t, err)
+	assert.False(t, results.AllOK())
+	assert.Equal(t, CheckNameTopicExists, results.Results[len(results.Results)-1].Name)
+}
@@ -2,30 +2,87 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/apply"
 	"github.com/segmentio/topicctl/pkg/check"
 	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/connect"
 	"github.com/segmentio/topicctl/pkg/groups"
 	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
 	spinnerCharSet  = 36
 	spinnerDuration = 200 * time.Millisecond
+
+	// The number of topics to fetch and print at a time when listing topics
+	topicBatchSize = 500
+
+	// The number of partitions to show in the hottest partitions table of get skew
+	maxHottestPartitions = 5
+
+	// The ratio of the hottest partition's messages to the mean above which get skew warns
+	// about the partition keys
+	skewWarningRatio = 2.0
 )
 
+// Pagination controls which page of a long list is fetched and printed. If Limit is zero, all
+// items are included.
+type Pagination struct {
+	// Limit is the maximum number of items per page.
+	Limit int
+
+	// Page is the 1-based index of the page to show.
+	Page int
+}
+
+// bounds returns the start (inclusive) and end (exclusive) indices of the current page in a
+// list with the argument number of items.
+func (p Pagination) bounds(total int) (int, int) {
+	if p.Limit <= 0 {
+		return 0, total
+	}
+
+	start := minInt((p.page()-1)*p.Limit, total)
+	end := minInt(start+p.Limit, total)
+	return start, end
+}
+
+// description returns a suffix describing the current page, for use in output headers.
+func (p Pagination) description(start int, end int, total int) string {
+	if p.Limit <= 0 {
+		return ""
+	}
+	if start == end {
+		return fmt.Sprintf(" (page %d, no items out of %d total)", p.page(), total)
+	}
+	return fmt.Sprintf(" (page %d, %d-%d out of %d total)", p.page(), start+1, end, total)
+}
+
+// page returns the page to show, treating unset or invalid pages as the first one.
+func (p Pagination) page() int {
+	if p.Page < 1 {
+		return 1
+	}
+	return p.Page
+}
+
 // CLIRunner is a utility that runs commands from either the command-line or the repl.
 type CLIRunner struct {
 	adminClient  *admin.Client
@@ -64,22 +121,75 @@ func NewCLIRunner(
 	return cliRunner
 }
 
-// GetBrokers gets all brokers and prints out a summary for the user.
+// GetBrokers gets all brokers, along with their Kafka versions and partition counts, and
+// prints out a summary for the user.
 func (c *CLIRunner) GetBrokers(ctx context.Context, full bool) error {
 	c.startSpinner()
 
 	brokers, err := c.adminClient.GetBrokers(ctx, nil)
-	c.stopSpinner()
 	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	topics, err := c.adminClient.GetTopics(ctx, nil, true)
+	if err != nil {
+		c.stopSpinner()
 		return err
 	}
+	versions := c.adminClient.GetBrokerVersions(ctx, brokers)
+	c.stopSpinner()
+
+	partitions := []admin.PartitionInfo{}
+	for _, topic := range topics {
+		partitions = append(partitions, topic.Partitions...)
+	}
+
+	stats := []admin.BrokerStats{}
+	for _, summary := range admin.SummarizePartitionsByBroker(partitions, brokers) {
+		stats = append(
+			stats,
+			admin.BrokerStats{
+				BrokerPartitionSummary: summary,
+				KafkaVersion:           versions[summary.Broker],
+			},
+		)
+	}
 
-	c.printer("Brokers:\n%s", admin.FormatBrokers(brokers, full))
+	c.printer("Brokers:\n%s", admin.FormatBrokers(brokers, stats, full))
 	c.printer("Brokers per rack:\n%s", admin.FormatBrokersPerRack(brokers))
 
 	return nil
 }
 
+// GetVersions prints out the inferred Kafka version of each broker in the cluster along with
+// the version ranges of the APIs that they support and the version-dependent features that
+// are available.
+func (c *CLIRunner) GetVersions(ctx context.Context) error {
+	c.startSpinner()
+
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	brokerVersions := c.adminClient.GetBrokerAPIVersions(ctx, brokers)
+	c.stopSpinner()
+
+	c.printer("Broker versions:\n%s", admin.FormatBrokerVersions(brokers, brokerVersions))
+	c.printer("API versions:\n%s", admin.FormatAPIVersions(brokerVersions))
+	c.printer("Features:\n%s", admin.FormatFeatures(brokerVersions))
+
+	if versions := admin.DistinctKafkaVersions(brokerVersions); len(versions) > 1 {
+		log.Warnf(
+			"Brokers are running different Kafka versions (%s); the cluster may be in the middle of an upgrade",
+			strings.Join(versions, ", "),
+		)
+	}
+
+	return nil
+}
+
 // ApplyTopic does an apply run according to the spec in the argument config.
 func (c *CLIRunner) ApplyTopic(
 	ctx context.Context,
@@ -110,6 +220,50 @@ func (c *CLIRunner) ApplyTopic(
 	return nil
 }
 
+// ApplyBrokers does an apply run for the dynamic broker settings in the argument
+// brokers config.
+func (c *CLIRunner) ApplyBrokers(
+	ctx context.Context,
+	applierConfig apply.BrokersApplierConfig,
+) error {
+	applier := apply.NewBrokersApplier(c.adminClient, applierConfig)
+
+	c.printer(
+		"Starting apply for brokers in environment %s, cluster %s",
+		applierConfig.BrokersConfig.Meta.Environment,
+		applierConfig.BrokersConfig.Meta.Cluster,
+	)
+
+	if err := applier.Apply(ctx); err != nil {
+		return err
+	}
+
+	c.printer("Apply completed successfully!")
+	return nil
+}
+
+// ApplyConnector does an apply run according to the spec in the argument connector config.
+func (c *CLIRunner) ApplyConnector(
+	ctx context.Context,
+	applierConfig apply.ConnectorApplierConfig,
+) error {
+	applier := apply.NewConnectorApplier(applierConfig)
+
+	c.printer(
+		"Starting apply for connector %s in environment %s, cluster %s",
+		applierConfig.ConnectorConfig.Meta.Name,
+		applierConfig.ConnectorConfig.Meta.Environment,
+		applierConfig.ConnectorConfig.Meta.Cluster,
+	)
+
+	if err := applier.Apply(ctx); err != nil {
+		return err
+	}
+
+	c.printer("Apply completed successfully!")
+	return nil
+}
+
 // BootstrapTopics creates configs for one or more topics based on their current state in the
 // cluster.
 func (c *CLIRunner) BootstrapTopics(
@@ -191,13 +345,23 @@ func (c *CLIRunner) BootstrapTopics(
 	return nil
 }
 
-// CheckTopic runs a topic check against a single topic and prints a summary of the results out.
+// CheckTopic runs a topic check against a single topic, prints a summary of the results out,
+// and returns the results.
 func (c *CLIRunner) CheckTopic(
 	ctx context.Context,
 	checkConfig check.CheckConfig,
-) (bool, error) {
+) (check.TopicCheckResults, error) {
 	results, err := check.CheckTopic(ctx, checkConfig)
+	c.PrintTopicCheckResults(checkConfig, results)
+	return results, err
+}
 
+// PrintTopicCheckResults prints a summary of the results of a topic check. It's used
+// instead of CheckTopic when the checks are run separately, e.g. in parallel.
+func (c *CLIRunner) PrintTopicCheckResults(
+	checkConfig check.CheckConfig,
+	results check.TopicCheckResults,
+) {
 	if results.AllOK() {
 		c.printer(
 			"Topic %s (cluster=%s, env=%s) OK",
@@ -214,311 +378,1651 @@ func (c *CLIRunner) CheckTopic(
 			check.FormatResults(results),
 		)
 	}
+}
 
-	return results.AllOK(), err
+// CheckConnector runs a check against a single connector, prints a summary of the
+// results out, and returns the results.
+func (c *CLIRunner) CheckConnector(
+	ctx context.Context,
+	checkConfig check.ConnectorCheckConfig,
+) (check.TopicCheckResults, error) {
+	results, err := check.CheckConnector(ctx, checkConfig)
+	c.PrintConnectorCheckResults(checkConfig, results)
+	return results, err
 }
 
-// GetBrokerBalance evaluates the balance of the brokers for a single topic and prints a summary
-// out for user inspection.
-func (c *CLIRunner) GetBrokerBalance(ctx context.Context, topicName string) error {
-	c.startSpinner()
+// PrintConnectorCheckResults prints a summary of the results of a connector check.
+func (c *CLIRunner) PrintConnectorCheckResults(
+	checkConfig check.ConnectorCheckConfig,
+	results check.TopicCheckResults,
+) {
+	if results.AllOK() {
+		c.printer(
+			"Connector %s (cluster=%s, env=%s) OK",
+			checkConfig.ConnectorConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Environment,
+		)
+	} else {
+		c.printer(
+			"Check failed for connector %s (cluster=%s, env=%s):\n%s",
+			checkConfig.ConnectorConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Environment,
+			check.FormatResults(results),
+		)
+	}
+}
 
-	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+// PrintBrokersCheckResults prints a summary of the results of a brokers config check.
+func (c *CLIRunner) PrintBrokersCheckResults(
+	checkConfig check.BrokersCheckConfig,
+	results check.TopicCheckResults,
+) {
+	if results.AllOK() {
+		c.printer(
+			"Broker configs (cluster=%s, env=%s) OK",
+			checkConfig.ClusterConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Environment,
+		)
+	} else {
+		c.printer(
+			"Check failed for broker configs (cluster=%s, env=%s):\n%s",
+			checkConfig.ClusterConfig.Meta.Name,
+			checkConfig.ClusterConfig.Meta.Environment,
+			check.FormatResults(results),
+		)
+	}
+}
+
+// CopyOffsets copies the committed offsets of a source consumer group in a topic to a
+// destination group, optionally shifting them by a number of messages or a duration.
+func (c *CLIRunner) CopyOffsets(
+	ctx context.Context,
+	topic string,
+	sourceGroupID string,
+	destGroupID string,
+	offsetShift int64,
+	timeShift time.Duration,
+	skipConfirm bool,
+) error {
+	c.startSpinner()
+	offsetCopies, err := c.groupsClient.GetOffsetCopies(
+		ctx,
+		topic,
+		sourceGroupID,
+		offsetShift,
+		timeShift,
+	)
+	c.stopSpinner()
 	if err != nil {
-		c.stopSpinner()
 		return err
 	}
 
-	var topicNames []string
-	if topicName != "" {
-		topicNames = []string{topicName}
+	log.Infof(
+		"This will set the offsets for group %s in topic %s based on the offsets of group %s:\n%s",
+		destGroupID,
+		topic,
+		sourceGroupID,
+		groups.FormatOffsetCopies(offsetCopies),
+	)
+	ok, _ := apply.Confirm("OK to continue?", skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
 	}
 
-	topics, err := c.adminClient.GetTopics(ctx, topicNames, false)
+	c.startSpinner()
+	err = c.groupsClient.CopyOffsets(ctx, topic, destGroupID, offsetCopies)
+	c.stopSpinner()
 	if err != nil {
-		c.stopSpinner()
 		return err
 	}
 
-	c.stopSpinner()
-
-	c.printer("Broker replicas:\n%s", admin.FormatBrokerReplicas(brokers, topics))
-	c.printer("Broker rack replicas:\n%s", admin.FormatBrokerRackReplicas(brokers, topics))
-
+	c.printer("Success")
 	return nil
 }
 
-// GetConfig fetches the config for a broker or topic and prints it out for user inspection.
-func (c *CLIRunner) GetConfig(ctx context.Context, brokerOrTopic string) error {
-	c.startSpinner()
-
-	brokerIDs, err := c.adminClient.GetBrokerIDs(ctx)
-	if err != nil {
-		c.stopSpinner()
-		return err
+// DeleteGroup deletes a consumer group, including all of its committed offsets.
+func (c *CLIRunner) DeleteGroup(
+	ctx context.Context,
+	groupID string,
+	skipConfirm bool,
+) error {
+	log.Infof(
+		"This will delete group %s, including all of its committed offsets.",
+		groupID,
+	)
+	ok, _ := apply.Confirm("OK to continue?", skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
 	}
 
-	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	c.startSpinner()
+	err := c.groupsClient.DeleteGroup(ctx, groupID)
+	c.stopSpinner()
 	if err != nil {
-		c.stopSpinner()
 		return err
 	}
 
-	for _, brokerID := range brokerIDs {
-		if fmt.Sprintf("%d", brokerID) == brokerOrTopic {
-			brokers, err := c.adminClient.GetBrokers(ctx, []int{brokerID})
-			if err != nil {
-				c.stopSpinner()
-				return err
-			}
-			if len(brokers) != 1 {
-				c.stopSpinner()
-				return fmt.Errorf("Could not find broker %d", brokerID)
-			}
-			c.stopSpinner()
-
-			c.printer(
-				"Config for broker %d:\n%s",
-				brokerID,
-				admin.FormatConfig(brokers[0].Config),
-			)
-			return nil
-		}
-	}
-
-	for _, topicName := range topicNames {
-		if topicName == brokerOrTopic {
-			topics, err := c.adminClient.GetTopics(ctx, []string{topicName}, false)
-			if err != nil {
-				c.stopSpinner()
-				return err
-			}
-			if len(topics) != 1 {
-				c.stopSpinner()
-				return fmt.Errorf("Could not find topic %s", topicName)
-			}
-			c.stopSpinner()
+	c.printer("Success")
+	return nil
+}
 
-			c.printer(
-				"Config for topic %s:\n%s",
-				topicName,
-				admin.FormatConfig(topics[0].Config),
-			)
-			return nil
-		}
+// DeleteGroupOffsets deletes the committed offsets for a consumer group in a single topic.
+func (c *CLIRunner) DeleteGroupOffsets(
+	ctx context.Context,
+	groupID string,
+	topic string,
+	skipConfirm bool,
+) error {
+	log.Infof(
+		"This will delete the committed offsets for group %s in all partitions of topic %s.",
+		groupID,
+		topic,
+	)
+	ok, _ := apply.Confirm("OK to continue?", skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
 	}
 
-	c.stopSpinner()
-	return fmt.Errorf("Could not find broker or topic named %s", brokerOrTopic)
-}
-
-// GetGroups fetches all consumer groups and prints them out for user inspection.
-func (c *CLIRunner) GetGroups(ctx context.Context) error {
 	c.startSpinner()
-
-	groupCoordinators, err := c.groupsClient.GetGroups(ctx)
+	err := c.groupsClient.DeleteGroupOffsets(ctx, groupID, topic)
 	c.stopSpinner()
 	if err != nil {
 		return err
 	}
 
-	c.printer("Groups:\n%s", groups.FormatGroupCoordinators(groupCoordinators))
+	c.printer("Success")
 	return nil
 }
 
-// GetGroupMembers fetches and prints out information about every member in a consumer group.
-func (c *CLIRunner) GetGroupMembers(ctx context.Context, groupID string, full bool) error {
+// GetAppliedRef fetches and prints out the git ref that was last applied to the cluster.
+func (c *CLIRunner) GetAppliedRef(ctx context.Context) error {
 	c.startSpinner()
-
-	groupDetails, err := c.groupsClient.GetGroupDetails(ctx, groupID)
+	appliedRef, err := c.adminClient.GetAppliedRef(ctx)
 	c.stopSpinner()
 	if err != nil {
 		return err
 	}
 
-	c.printer("Group state: %s", groupDetails.State)
-	c.printer(
-		"Group members (%d):\n%s",
-		len(groupDetails.Members),
-		groups.FormatGroupMembers(groupDetails.Members, full),
-	)
-	c.printer(
-		"Member frequency by partition count:\n%s",
-		groups.FormatMemberPartitionCounts(groupDetails.Members),
-	)
+	if appliedRef == nil {
+		c.printer("No git ref has been applied to this cluster")
+		return nil
+	}
 
+	c.printer("Applied git ref:\n%s", admin.FormatAppliedRef(*appliedRef))
 	return nil
 }
 
-// GetMemberLags fetches and prints a summary of the consumer group lag for each partition
-// in a single topic.
-func (c *CLIRunner) GetMemberLags(ctx context.Context, topic string, groupID string) error {
+// GetTopicFreezes fetches and prints out the topics that are frozen in the cluster.
+func (c *CLIRunner) GetTopicFreezes(ctx context.Context) error {
 	c.startSpinner()
-
-	// Check that topic exists before getting offsets; otherwise, the topic get
-	// created as part of the lag check.
-	_, err := c.adminClient.GetTopic(ctx, topic, false)
-	if err != nil {
-		c.stopSpinner()
-		return fmt.Errorf("Error fetching topic info: %+v", err)
-	}
-
-	memberLags, err := c.groupsClient.GetMemberLags(ctx, topic, groupID)
+	freezes, err := c.adminClient.GetTopicFreezes(ctx)
 	c.stopSpinner()
-
 	if err != nil {
 		return err
 	}
 
-	c.printer("Group member lags:\n%s", groups.FormatMemberLags(memberLags))
+	if len(freezes) == 0 {
+		c.printer("No topics are frozen in this cluster")
+		return nil
+	}
+
+	c.printer("Frozen topics:\n%s", admin.FormatTopicFreezes(freezes, time.Now()))
 	return nil
 }
 
-// GetPartitions fetches the details of each partition in a topic and prints out a summary for
-// user inspection.
-func (c *CLIRunner) GetPartitions(ctx context.Context, topic string) error {
+// GetConfigHistory prints a timeline of the config and assignment changes to the argument
+// topic, assembled from the apply artifacts and backups in the argument directories and the
+// last config change recorded in the cluster.
+func (c *CLIRunner) GetConfigHistory(
+	ctx context.Context,
+	topic string,
+	artifactsDir string,
+	backupDir string,
+) error {
 	c.startSpinner()
-
-	topicInfo, err := c.adminClient.GetTopic(ctx, topic, true)
+	clusterID, err := c.adminClient.GetClusterID(ctx)
 	if err != nil {
 		c.stopSpinner()
 		return err
 	}
-
-	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	lastChange, err := c.adminClient.GetLastConfigChange(ctx, admin.ConfigEntityTopic, topic)
 	c.stopSpinner()
 	if err != nil {
 		return err
 	}
 
-	c.printer(
-		"Partitions for topic %s:\n%s",
+	events, err := apply.TopicConfigHistory(
 		topic,
-		admin.FormatTopicPartitions(topicInfo.Partitions, brokers),
+		apply.ConfigHistorySources{
+			ArtifactsDir: artifactsDir,
+			BackupDir:    backupDir,
+			ClusterID:    clusterID,
+			LastChange:   lastChange,
+		},
 	)
+	if err != nil {
+		return err
+	}
 
+	if len(events) == 0 {
+		c.printer("No config history found for topic %s", topic)
+		return nil
+	}
+
+	c.printer("Config history for topic %s:\n%s", topic, apply.FormatConfigHistory(topic, events))
 	return nil
 }
 
-// GetOffsets fetches details about all partition offsets in a single topic and prints out
-// a summary.
+// GetBrokerBalance evaluates the balance of the brokers for a single topic and prints a summary
+// out for user inspection.
+func (c *CLIRunner) GetBrokerBalance(ctx context.Context, topicName string) error {
+	c.startSpinner()
+
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	var topicNames []string
+	if topicName != "" {
+		topicNames = []string{topicName}
+	}
+
+	topics, err := c.adminClient.GetTopics(ctx, topicNames, false)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	c.stopSpinner()
+
+	c.printer("Broker replicas:\n%s", admin.FormatBrokerReplicas(brokers, topics))
+	c.printer("Broker rack replicas:\n%s", admin.FormatBrokerRackReplicas(brokers, topics))
+
+	return nil
+}
+
+// GetMessagesAtOffset fetches and prints out the messages in a single topic partition with
+// offsets between startOffset and endOffset (inclusive).
+func (c *CLIRunner) GetMessagesAtOffset(
+	ctx context.Context,
+	topic string,
+	partition int,
+	startOffset int64,
+	endOffset int64,
+	format messages.TailFormat,
+) error {
+	outputConfig := messages.TailOutputConfig{
+		Format:         format,
+		IncludeHeaders: true,
+	}
+	if err := outputConfig.Validate(); err != nil {
+		return err
+	}
+	if format == messages.TailFormatJSONL {
+		outputConfig.IncludePartition = true
+		outputConfig.IncludeOffset = true
+		outputConfig.IncludeTimestamp = true
+	}
+
+	c.startSpinner()
+
+	// Check that topic exists before fetching; otherwise, the topic might be created
+	// when dialing the partition leader.
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+	if partition < 0 || partition >= len(topicInfo.Partitions) {
+		c.stopSpinner()
+		return fmt.Errorf("Topic %s does not have partition %d", topic, partition)
+	}
+
+	fetched, err := messages.GetMessagesInRange(
+		ctx,
+		c.adminClient.GetBootstrapAddrs()[0],
+		topic,
+		partition,
+		startOffset,
+		endOffset,
+	)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	if len(fetched) == 0 {
+		log.Infof(
+			"No messages found in partition %d at offsets %d->%d; they may have been compacted",
+			partition,
+			startOffset,
+			endOffset,
+		)
+		return nil
+	}
+
+	for _, message := range fetched {
+		if err := messages.PrintMessage(message, outputConfig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRackViolations finds the partitions whose replicas aren't spread across the expected
+// number of racks and prints them out for user inspection. If topicName is empty, then all
+// topics are checked. See admin.TopicInfo.RackViolations for the meaning of expectedRacks.
+func (c *CLIRunner) GetRackViolations(
+	ctx context.Context,
+	topicName string,
+	expectedRacks int,
+) error {
+	c.startSpinner()
+
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	var topicNames []string
+	if topicName != "" {
+		topicNames = []string{topicName}
+	}
+
+	topics, err := c.adminClient.GetTopics(ctx, topicNames, true)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	numViolations := 0
+
+	for _, topic := range topics {
+		violations := topic.RackViolations(brokers, expectedRacks)
+		if len(violations) == 0 {
+			continue
+		}
+		numViolations += len(violations)
+
+		c.printer(
+			"Rack violations for topic %s (%d/%d partitions):\n%s",
+			topic.Name,
+			len(violations),
+			len(topic.Partitions),
+			admin.FormatTopicPartitions(violations, brokers),
+		)
+	}
+
+	if numViolations == 0 {
+		c.printer("No rack violations found")
+	}
+
+	return nil
+}
+
+// GetHealth checks the min.insync.replicas of each topic in the cluster, or the ones that
+// match the argument matcher if it's non-nil, against its replication factor, the cluster
+// default, and the current ISRs, and prints out the topics with problems.
+func (c *CLIRunner) GetHealth(ctx context.Context, topicMatcher *util.TopicMatcher) error {
+	c.startSpinner()
+
+	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	sort.Strings(topicNames)
+	topicNames = topicMatcher.Filter(topicNames)
+	if len(topicNames) == 0 {
+		c.stopSpinner()
+		c.printer("No topics found")
+		return nil
+	}
+
+	clusterDefaults, err := c.adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	topics, err := c.adminClient.GetTopics(ctx, topicNames, true)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	statuses := []admin.MinISRStatus{}
+
+	for _, topic := range topics {
+		status, err := admin.GetMinISRStatus(topic, clusterDefaults)
+		if err != nil {
+			return err
+		}
+		if !status.OK() {
+			statuses = append(statuses, status)
+		}
+	}
+
+	if len(statuses) == 0 {
+		c.printer("No min.insync.replicas problems found in %d topics", len(topics))
+		return nil
+	}
+
+	c.printer(
+		"Found min.insync.replicas problems in %d/%d topics:\n%s",
+		len(statuses),
+		len(topics),
+		admin.FormatMinISRStatuses(statuses),
+	)
+	return nil
+}
+
+// GetConfigDiff fetches the config for a topic and prints it out side-by-side with the
+// cluster and Kafka defaults for user inspection.
+func (c *CLIRunner) GetConfigDiff(ctx context.Context, topic string) error {
+	c.startSpinner()
+
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	clusterDefaults, err := c.adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	c.stopSpinner()
+
+	diffs := admin.TopicConfigDiffs(topicInfo.Config, clusterDefaults)
+
+	c.printer(
+		"Config for topic %s (%d overrides):\n%s",
+		topic,
+		len(topicInfo.Config),
+		admin.FormatTopicConfigDiffs(diffs),
+	)
+	return nil
+}
+
+// GetConnectors gets the status of all connectors in the Kafka Connect cluster at the
+// argument URL and prints a summary for the user.
+func (c *CLIRunner) GetConnectors(ctx context.Context, connectURL string) error {
+	c.startSpinner()
+
+	connectClient := connect.NewClient(connectURL)
+
+	names, err := connectClient.GetConnectorNames(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	statuses := []connect.ConnectorStatus{}
+
+	for _, name := range names {
+		status, err := connectClient.GetConnectorStatus(ctx, name)
+		if err != nil {
+			c.stopSpinner()
+			return err
+		}
+		statuses = append(statuses, status)
+	}
+	c.stopSpinner()
+
+	c.printer("Connectors:\n%s", connect.FormatConnectorStatuses(statuses))
+	return nil
+}
+
+// GetClusterInfo fetches a summary of the cluster, including the controller and any
+// in-progress operations, and prints it out.
+func (c *CLIRunner) GetClusterInfo(ctx context.Context) error {
+	c.startSpinner()
+	clusterInfo, err := c.adminClient.GetClusterInfo(ctx)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer("Cluster:\n%s", admin.FormatClusterInfo(clusterInfo))
+	return nil
+}
+
+// WatchReassignment prints the progress of the in-progress partition reassignment, including
+// the remaining replica moves, copy rate, throttle utilization, and ETA for each broker that's
+// getting new replicas. The output is refreshed in place every refreshInterval until the
+// reassignment finishes or the context is cancelled.
+func (c *CLIRunner) WatchReassignment(ctx context.Context, refreshInterval time.Duration) error {
+	var prevProgress *admin.ReassignmentProgress
+	prevLines := 0
+
+	for {
+		reassignments, err := c.adminClient.GetReassignments(ctx)
+		if err != nil {
+			return err
+		}
+		if len(reassignments) == 0 {
+			if prevProgress == nil {
+				c.printer("No reassignment in progress")
+			} else {
+				c.printer("Reassignment complete")
+			}
+			return nil
+		}
+
+		brokers, err := c.adminClient.GetBrokers(ctx, nil)
+		if err != nil {
+			return err
+		}
+		logDirs, err := c.adminClient.GetLogDirs(ctx, brokers)
+		if err != nil {
+			log.Debugf("Could not get log dirs, so sizes won't be shown: %+v", err)
+			logDirs = nil
+		}
+
+		progress := admin.NewReassignmentProgress(reassignments, logDirs, brokers, time.Now())
+		if prevProgress != nil {
+			progress.SetRates(*prevProgress)
+		}
+
+		etaStr := "n/a"
+		if eta, ok := progress.ETA(); ok && prevProgress != nil {
+			etaStr = util.PrettyDuration(eta)
+		}
+		output := fmt.Sprintf(
+			"Reassignment of %d partition(s) as of %s: %d done, %d replica move(s) remaining, ETA %s\n%s",
+			progress.Partitions,
+			progress.Time.Format("15:04:05"),
+			progress.DonePartitions,
+			len(progress.Moves),
+			etaStr,
+			admin.FormatReassignmentProgress(progress),
+		)
+
+		// Move the cursor back up over the previous output and clear it so that the new
+		// output replaces it
+		if prevLines > 0 {
+			fmt.Printf("\033[%dA\033[J", prevLines)
+		}
+		c.printer("%s", output)
+		prevLines = strings.Count(output, "\n") + 1
+		prevProgress = &progress
+
+		select {
+		case <-time.After(refreshInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// GetLogDirs fetches the log directories on one or more brokers and prints them out. If
+// full is true or a single broker is requested, the replicas in each directory are also
+// printed.
+func (c *CLIRunner) GetLogDirs(ctx context.Context, brokerIDs []int, full bool) error {
+	c.startSpinner()
+
+	brokers, err := c.adminClient.GetBrokers(ctx, brokerIDs)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	logDirs, err := c.adminClient.GetLogDirs(ctx, brokers)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer("Log dirs:\n%s", admin.FormatLogDirs(logDirs))
+
+	if full || len(brokerIDs) == 1 {
+		c.printer("Log dir replicas:\n%s", admin.FormatLogDirReplicas(logDirs))
+	}
+
+	return nil
+}
+
+// GetConfig fetches the config for a broker or topic and prints it out for user inspection.
+func (c *CLIRunner) GetConfig(ctx context.Context, brokerOrTopic string) error {
+	c.startSpinner()
+
+	brokerIDs, err := c.adminClient.GetBrokerIDs(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	for _, brokerID := range brokerIDs {
+		if fmt.Sprintf("%d", brokerID) == brokerOrTopic {
+			brokers, err := c.adminClient.GetBrokers(ctx, []int{brokerID})
+			if err != nil {
+				c.stopSpinner()
+				return err
+			}
+			if len(brokers) != 1 {
+				c.stopSpinner()
+				return fmt.Errorf("Could not find broker %d", brokerID)
+			}
+			c.stopSpinner()
+
+			c.printer(
+				"Config for broker %d:\n%s",
+				brokerID,
+				admin.FormatConfig(brokers[0].Config),
+			)
+			return nil
+		}
+	}
+
+	for _, topicName := range topicNames {
+		if topicName == brokerOrTopic {
+			topics, err := c.adminClient.GetTopics(ctx, []string{topicName}, false)
+			if err != nil {
+				c.stopSpinner()
+				return err
+			}
+			if len(topics) != 1 {
+				c.stopSpinner()
+				return fmt.Errorf("Could not find topic %s", topicName)
+			}
+			c.stopSpinner()
+
+			c.printer(
+				"Config for topic %s:\n%s",
+				topicName,
+				admin.FormatConfig(topics[0].Config),
+			)
+			return nil
+		}
+	}
+
+	c.stopSpinner()
+	return fmt.Errorf("Could not find broker or topic named %s", brokerOrTopic)
+}
+
+// GetGroups fetches all consumer groups and prints them out for user inspection.
+func (c *CLIRunner) GetGroups(ctx context.Context) error {
+	c.startSpinner()
+
+	groupCoordinators, err := c.groupsClient.GetGroups(ctx)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer("Groups:\n%s", groups.FormatGroupCoordinators(groupCoordinators))
+	return nil
+}
+
+// GetGroupMembers fetches and prints out information about every member in a consumer group.
+func (c *CLIRunner) GetGroupMembers(ctx context.Context, groupID string, full bool) error {
+	c.startSpinner()
+
+	groupDetails, err := c.groupsClient.GetGroupDetails(ctx, groupID)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer("Group state: %s", groupDetails.State)
+	c.printer(
+		"Group members (%d):\n%s",
+		len(groupDetails.Members),
+		groups.FormatGroupMembers(groupDetails.Members, full),
+	)
+	c.printer(
+		"Member frequency by partition count:\n%s",
+		groups.FormatMemberPartitionCounts(groupDetails.Members),
+	)
+
+	return nil
+}
+
+// GetMemberLags fetches and prints a summary of the consumer group lag for each partition
+// in a single topic.
+func (c *CLIRunner) GetMemberLags(ctx context.Context, topic string, groupID string) error {
+	c.startSpinner()
+
+	// Check that topic exists before getting offsets; otherwise, the topic get
+	// created as part of the lag check.
+	_, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+
+	memberLags, err := c.groupsClient.GetMemberLags(ctx, topic, groupID)
+	c.stopSpinner()
+
+	if err != nil {
+		return err
+	}
+
+	c.printer("Group member lags:\n%s", groups.FormatMemberLags(memberLags))
+	return nil
+}
+
+// GetPartitions fetches the details of each partition in a topic and prints out a summary for
+// user inspection. Only the partitions that match the argument filter are shown. If summary
+// is true, then the partitions are aggregated by broker instead of being listed individually.
+func (c *CLIRunner) GetPartitions(
+	ctx context.Context,
+	topic string,
+	filter admin.PartitionFilter,
+	summary bool,
+	pagination Pagination,
+) error {
+	c.startSpinner()
+
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, true)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	partitions := admin.FilterPartitions(
+		topicInfo.Partitions,
+		admin.BrokerRacks(brokers),
+		filter,
+	)
+
+	var filteredStr string
+	if !filter.IsEmpty() {
+		filteredStr = fmt.Sprintf(
+			" (%d/%d matching filters)",
+			len(partitions),
+			len(topicInfo.Partitions),
+		)
+	}
+
+	if summary {
+		c.printer(
+			"Partition summary by broker for topic %s%s:\n%s",
+			topic,
+			filteredStr,
+			admin.FormatPartitionSummaries(
+				admin.SummarizePartitionsByBroker(partitions, brokers),
+			),
+		)
+		return nil
+	}
+
+	start, end := pagination.bounds(len(partitions))
+
+	c.printer(
+		"Partitions for topic %s%s%s:\n%s",
+		topic,
+		filteredStr,
+		pagination.description(start, end, len(partitions)),
+		admin.FormatTopicPartitions(partitions[start:end], brokers),
+	)
+
+	return nil
+}
+
+// GetOffsets fetches details about all partition offsets in a single topic and prints out
+// a summary.
 func (c *CLIRunner) GetOffsets(ctx context.Context, topic string) error {
 	c.startSpinner()
 
-	// Check that topic exists before getting offsets; otherwise, the topic might
-	// be created as part of the bounds check.
-	_, err := c.adminClient.GetTopic(ctx, topic, false)
+	// Check that topic exists before getting offsets; otherwise, the topic might
+	// be created as part of the bounds check.
+	_, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+
+	bounds, err := messages.GetAllPartitionBounds(
+		ctx,
+		c.adminClient.GetBootstrapAddrs()[0],
+		topic,
+		nil,
+	)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer(
+		"Partition bounds for topic %s:\n%s",
+		topic,
+		messages.FormatBounds(bounds),
+	)
+
+	formattedTotals := messages.FormatBoundTotals(bounds)
+
+	if formattedTotals != "" {
+		c.printer(
+			"Total bounds for topic %s across all partitions:\n%s",
+			topic,
+			formattedTotals,
+		)
+	}
+
+	return nil
+}
+
+// GetThroughput samples the end offsets and sizes of all partitions in a topic at the start
+// and end of the argument window and prints out the resulting message and byte rates. The
+// byte rates are based on the sizes of the leader replicas, so they're omitted if the cluster
+// doesn't support describing log dirs.
+func (c *CLIRunner) GetThroughput(
+	ctx context.Context,
+	topic string,
+	window time.Duration,
+) error {
+	throughput, err := c.sampleThroughput(ctx, topic, window)
+	if err != nil {
+		return err
+	}
+
+	c.printer(
+		"Throughput for topic %s over %s:\n%s",
+		topic,
+		throughput.Window().Round(time.Millisecond),
+		messages.FormatThroughput(throughput),
+	)
+
+	return nil
+}
+
+// GetSkew samples the message counts and bytes of all partitions in a topic over the
+// argument window, in the same way as GetThroughput, and prints out statistics on how
+// evenly they're spread across the partitions along with the hottest partitions.
+func (c *CLIRunner) GetSkew(
+	ctx context.Context,
+	topic string,
+	window time.Duration,
+) error {
+	throughput, err := c.sampleThroughput(ctx, topic, window)
+	if err != nil {
+		return err
+	}
+
+	skew := messages.NewPartitionSkew(throughput)
+	if skew.Messages.Total == 0 {
+		c.printer(
+			"No messages were produced to topic %s over %s",
+			topic,
+			throughput.Window().Round(time.Millisecond),
+		)
+		return nil
+	}
+
+	c.printer(
+		"Partition skew for topic %s over %s:\n%s",
+		topic,
+		throughput.Window().Round(time.Millisecond),
+		messages.FormatPartitionSkew(skew),
+	)
+	c.printer(
+		"Hottest partitions:\n%s",
+		messages.FormatHottestPartitions(skew, maxHottestPartitions),
+	)
+
+	if skew.Messages.MaxToMean() >= skewWarningRatio {
+		log.Warnf(
+			"The hottest partition is getting %.1fx the average number of messages; the partition keys may be unevenly distributed",
+			skew.Messages.MaxToMean(),
+		)
+	}
+
+	return nil
+}
+
+func (c *CLIRunner) sampleThroughput(
+	ctx context.Context,
+	topic string,
+	window time.Duration,
+) (messages.TopicThroughput, error) {
+	c.startSpinner()
+
+	// Check that topic exists before dialing partition leaders; otherwise, the topic might
+	// be created as part of the offsets check.
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return messages.TopicThroughput{}, fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+
+	leaderCounts := map[int]int{}
+	for _, partition := range topicInfo.Partitions {
+		leaderCounts[partition.Leader]++
+	}
+	leaders, err := c.adminClient.GetBrokers(ctx, util.SortedKeys(leaderCounts))
+	if err != nil {
+		c.stopSpinner()
+		return messages.TopicThroughput{}, err
+	}
+
+	includeSizes := true
+
+	sample := func() (time.Time, map[int]int64, map[int]int64, error) {
+		sampleTime := time.Now()
+
+		offsets, err := messages.GetEndOffsets(
+			ctx,
+			c.adminClient.GetBootstrapAddrs()[0],
+			topic,
+			topicInfo.PartitionIDs(),
+		)
+		if err != nil {
+			return sampleTime, nil, nil, err
+		}
+
+		if !includeSizes {
+			return sampleTime, offsets, nil, nil
+		}
+
+		logDirs, err := c.adminClient.GetLogDirs(ctx, leaders)
+		if err != nil {
+			log.Warnf("Could not get partition sizes, so byte rates won't be shown: %+v", err)
+			includeSizes = false
+			return sampleTime, offsets, nil, nil
+		}
+		return sampleTime, offsets, admin.LeaderReplicaSizes(logDirs, topicInfo), nil
+	}
+
+	startTime, startOffsets, startSizes, err := sample()
+	if err != nil {
+		c.stopSpinner()
+		return messages.TopicThroughput{}, err
+	}
+
+	c.stopSpinner()
+	c.printer("Sampling partitions in topic %s for %s", topic, window)
+	c.startSpinner()
+
+	select {
+	case <-time.After(window):
+	case <-ctx.Done():
+		c.stopSpinner()
+		return messages.TopicThroughput{}, ctx.Err()
+	}
+
+	endTime, endOffsets, endSizes, err := sample()
+	c.stopSpinner()
+	if err != nil {
+		return messages.TopicThroughput{}, err
+	}
+	if !includeSizes {
+		startSizes = nil
+	}
+
+	return messages.NewTopicThroughput(
+		topic,
+		startTime,
+		startOffsets,
+		startSizes,
+		endTime,
+		endOffsets,
+		endSizes,
+	), nil
+}
+
+// GetTopics fetches the details of each topic in the cluster, or the ones that match the
+// argument matcher if it's non-nil, and prints out a summary.
+func (c *CLIRunner) GetTopics(
+	ctx context.Context,
+	full bool,
+	topicMatcher *util.TopicMatcher,
+	pagination Pagination,
+	recreatedWindow time.Duration,
+) error {
+	c.startSpinner()
+
+	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	recordedTopics, err := c.adminClient.GetRecordedTopics(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	recreatedCount := 0
+	recreatedTopics := func(topics []admin.TopicInfo) map[string]bool {
+		recreated := map[string]bool{}
+		for _, topic := range topics {
+			if recordedTopics[topic.Name].RecreatedWithin(topic, recreatedWindow, time.Now()) {
+				recreated[topic.Name] = true
+				recreatedCount++
+			}
+		}
+		return recreated
+	}
+
+	sort.Strings(topicNames)
+	topicNames = topicMatcher.Filter(topicNames)
+	totalTopics := len(topicNames)
+	start, end := pagination.bounds(totalTopics)
+	pageStr := pagination.description(start, end, totalTopics)
+	topicNames = topicNames[start:end]
+
+	if len(topicNames) <= topicBatchSize {
+		topics, err := c.adminClient.ListTopics(ctx, topicNames, false)
+		c.stopSpinner()
+		if err != nil {
+			return err
+		}
+
+		c.printer(
+			"Topics%s:\n%s",
+			pageStr,
+			admin.FormatTopics(topics, brokers, full, recreatedTopics(topics)),
+		)
+		c.printRecreatedWarning(recreatedCount, recreatedWindow)
+		return nil
+	}
+
+	// Print the topics in batches as they're fetched instead of holding all of them in
+	// memory. The batches are numbered by their positions in the full list so that they
+	// line up with the page description.
+	for batchStart := 0; batchStart < len(topicNames); batchStart += topicBatchSize {
+		batchEnd := minInt(batchStart+topicBatchSize, len(topicNames))
+
+		c.startSpinner()
+		topics, err := c.adminClient.ListTopics(ctx, topicNames[batchStart:batchEnd], false)
+		c.stopSpinner()
+		if err != nil {
+			return err
+		}
+
+		c.printer(
+			"Topics %d-%d of %d%s:\n%s",
+			start+batchStart+1,
+			start+batchEnd,
+			totalTopics,
+			pageStr,
+			admin.FormatTopics(topics, brokers, full, recreatedTopics(topics)),
+		)
+	}
+	c.printRecreatedWarning(recreatedCount, recreatedWindow)
+
+	return nil
+}
+
+func (c *CLIRunner) printRecreatedWarning(recreatedCount int, recreatedWindow time.Duration) {
+	if recreatedCount == 0 {
+		return
+	}
+
+	windowStr := ""
+	if recreatedWindow > 0 {
+		windowStr = fmt.Sprintf(" in the last %s", recreatedWindow)
+	}
+	log.Warnf(
+		"%d topic(s) were deleted and re-created%s since they were last applied; consumer group offsets for them may be invalid",
+		recreatedCount,
+		windowStr,
+	)
+}
+
+// topicJSON is the JSON representation of a topic used by GetTopicsJSON.
+type topicJSON struct {
+	Name              string    `json:"name"`
+	TopicID           string    `json:"topicID,omitempty"`
+	CreatedAt         time.Time `json:"createdAt"`
+	Recreated         bool      `json:"recreated,omitempty"`
+	Partitions        int       `json:"partitions"`
+	ReplicationFactor int       `json:"replicationFactor"`
+	RetentionMinutes  int       `json:"retentionMinutes,omitempty"`
+	Lifecycle         string    `json:"lifecycle,omitempty"`
+	config.Ownership
+}
+
+// GetTopicsJSON prints out a JSON array that describes the topics in the cluster, including
+// their owners and lifecycle states if the argument index is non-nil. Unlike GetTopics, the
+// output is written to stdout so that it can be piped into other tools.
+func (c *CLIRunner) GetTopicsJSON(
+	ctx context.Context,
+	topicMatcher *util.TopicMatcher,
+	pagination Pagination,
+	owners *config.OwnershipIndex,
+	recreatedWindow time.Duration,
+) error {
+	c.startSpinner()
+	defer c.stopSpinner()
+
+	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return err
+	}
+	recordedTopics, err := c.adminClient.GetRecordedTopics(ctx)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(topicNames)
+	topicNames = topicMatcher.Filter(topicNames)
+	start, end := pagination.bounds(len(topicNames))
+	topicNames = topicNames[start:end]
+
+	results := []topicJSON{}
+
+	for batchStart := 0; batchStart < len(topicNames); batchStart += topicBatchSize {
+		batchEnd := minInt(batchStart+topicBatchSize, len(topicNames))
+
+		topics, err := c.adminClient.ListTopics(ctx, topicNames[batchStart:batchEnd], false)
+		if err != nil {
+			return err
+		}
+
+		for _, topic := range topics {
+			result := topicJSON{
+				Name:              topic.Name,
+				TopicID:           topic.TopicID,
+				CreatedAt:         topic.CreatedAt,
+				Recreated:         recordedTopics[topic.Name].RecreatedWithin(topic, recreatedWindow, time.Now()),
+				Partitions:        len(topic.Partitions),
+				ReplicationFactor: topic.MaxReplication(),
+				RetentionMinutes:  int(topic.Retention().Minutes()),
+			}
+			if owners != nil {
+				result.Ownership = owners.Lookup(topic.Name).Ownership
+				if topicConfig, ok := owners.TopicConfig(topic.Name); ok {
+					result.Lifecycle = string(topicConfig.Meta.LifecycleState())
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	c.stopSpinner()
+	return printJSON(results)
+}
+
+// GetTopicOwners prints out the owners of the argument topics.
+func (c *CLIRunner) GetTopicOwners(
+	topicNames []string,
+	owners *config.OwnershipIndex,
+	jsonOutput bool,
+) error {
+	ownerships := []config.TopicOwnership{}
+	for _, topicName := range topicNames {
+		ownerships = append(ownerships, owners.Lookup(topicName))
+	}
+
+	if jsonOutput {
+		return printJSON(ownerships)
+	}
+
+	c.printer("Owners:\n%s", config.FormatTopicOwnerships(ownerships))
+	return nil
+}
+
+// PreviewAssignment computes and prints out the partitions that each member of a consumer
+// group would own in the argument topic under the given assignment strategy. If numMembers is
+// <= 0, then the current number of group members is used.
+func (c *CLIRunner) PreviewAssignment(
+	ctx context.Context,
+	topic string,
+	groupID string,
+	strategy groups.AssignmentStrategy,
+	numMembers int,
+	full bool,
+) error {
+	c.startSpinner()
+
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
 	if err != nil {
 		c.stopSpinner()
 		return fmt.Errorf("Error fetching topic info: %+v", err)
 	}
 
-	bounds, err := messages.GetAllPartitionBounds(
-		ctx,
-		c.adminClient.GetBootstrapAddrs()[0],
+	currMembers := []string{}
+	currAssignments := map[string][]int{}
+
+	if groupID != "" {
+		groupDetails, err := c.groupsClient.GetGroupDetails(ctx, groupID)
+		if err != nil {
+			c.stopSpinner()
+			return err
+		}
+
+		for _, member := range groupDetails.Members {
+			if _, ok := member.TopicPartitions[topic]; !ok {
+				continue
+			}
+			currMembers = append(currMembers, member.MemberID)
+			currAssignments[member.MemberID] = member.TopicPartitions[topic]
+		}
+	}
+	c.stopSpinner()
+
+	if numMembers <= 0 && len(currMembers) == 0 {
+		return errors.New(
+			"Group has no members consuming from topic; must set number of members explicitly",
+		)
+	}
+
+	previews, err := groups.PreviewAssignments(
+		strategy,
 		topic,
-		nil,
+		topicInfo.PartitionIDs(),
+		groups.PreviewMemberIDs(currMembers, numMembers),
+		currAssignments,
 	)
-	c.stopSpinner()
 	if err != nil {
 		return err
 	}
 
 	c.printer(
-		"Partition bounds for topic %s:\n%s",
+		"Preview of '%s' assignments for topic %s (%d partitions, %d members):\n%s",
+		strategy,
 		topic,
-		messages.FormatBounds(bounds),
+		len(topicInfo.Partitions),
+		len(previews),
+		groups.FormatAssignmentPreviews(previews, full),
 	)
 
-	formattedTotals := messages.FormatBoundTotals(bounds)
+	return nil
+}
 
-	if formattedTotals != "" {
-		c.printer(
-			"Total bounds for topic %s across all partitions:\n%s",
-			topic,
-			formattedTotals,
-		)
+// ResetOffsets resets the offsets for a single consumer group / topic combination.
+func (c *CLIRunner) ResetOffsets(
+	ctx context.Context,
+	topic string,
+	groupID string,
+	partitionOffsets map[int]int64,
+) error {
+	c.startSpinner()
+	err := c.groupsClient.ResetOffsets(ctx, topic, groupID, partitionOffsets)
+	c.stopSpinner()
+	if err != nil {
+		return err
 	}
 
+	c.printer("Success")
+
 	return nil
 }
 
-// GetTopics fetches the details of each topic in the cluster and prints out a summary.
-func (c *CLIRunner) GetTopics(ctx context.Context, full bool) error {
-	c.startSpinner()
+// Search scans a topic for messages that match the argument search config and prints out
+// the matches.
+func (c *CLIRunner) Search(
+	ctx context.Context,
+	searchConfig messages.SearchConfig,
+	outputConfig messages.TailOutputConfig,
+) error {
+	topicInfo, err := c.adminClient.GetTopic(ctx, searchConfig.Topic, false)
+	if err != nil {
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
 
-	topics, err := c.adminClient.GetTopics(ctx, nil, false)
+	partitions, err := messages.SearchPartitions(searchConfig, topicInfo.PartitionIDs())
 	if err != nil {
-		c.stopSpinner()
 		return err
 	}
-	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+
+	if outputConfig.Interactive() {
+		c.printer(
+			"Searching partition(s) %+v in topic %s",
+			partitions,
+			searchConfig.Topic,
+		)
+	}
+
+	c.startSpinner()
+	result, err := messages.SearchMessages(
+		ctx,
+		c.adminClient.GetBootstrapAddrs()[0],
+		searchConfig,
+		partitions,
+		func(progress messages.SearchProgress) {
+			if c.spinnerObj == nil {
+				return
+			}
+			c.spinnerObj.Suffix = fmt.Sprintf(
+				" partition %d: scanned %d/%d messages, %d matches",
+				progress.Partition,
+				progress.ScannedInRange,
+				progress.TotalInRange,
+				progress.Matches,
+			)
+		},
+	)
 	c.stopSpinner()
 	if err != nil {
 		return err
 	}
 
-	c.printer("Topics:\n%s", admin.FormatTopics(topics, brokers, full))
+	for _, message := range result.Matches {
+		message, err := messages.DecodeMessage(ctx, outputConfig.Codec, message)
+		if err != nil {
+			log.Warnf("%+v; showing raw value", err)
+		}
+		if err := messages.PrintMessage(message, outputConfig); err != nil {
+			return err
+		}
+	}
+
+	if outputConfig.Interactive() {
+		c.printer(
+			"Found %d matching message(s) after scanning %d message(s)",
+			len(result.Matches),
+			result.ScannedMessages,
+		)
+	}
+	if result.Truncated {
+		log.Warnf(
+			"Search stopped early because the scan or match limit was reached; there may be more matches",
+		)
+	}
 
 	return nil
 }
 
-// ResetOffsets resets the offsets for a single consumer group / topic combination.
-func (c *CLIRunner) ResetOffsets(
+// Tail prints out a stream of the latest messages in one or more topics. If topicRegex is
+// set, all of the topics whose names match it are tailed in addition to the argument ones.
+// Partitions can only be set when tailing a single topic. If groupID is set, the tail joins
+// the associated consumer group and commits offsets as it goes.
+func (c *CLIRunner) Tail(
 	ctx context.Context,
-	topic string,
+	topics []string,
+	topicRegex string,
 	groupID string,
-	partitionOffsets map[int]int64,
+	offset int64,
+	partitions []int,
+	maxMessages int,
+	filterRegexp string,
+	outputConfig messages.TailOutputConfig,
 ) error {
-	c.startSpinner()
-	err := c.groupsClient.ResetOffsets(ctx, topic, groupID, partitionOffsets)
-	c.stopSpinner()
+	tailer, err := c.newTailer(ctx, topics, topicRegex, groupID, offset, partitions)
 	if err != nil {
 		return err
 	}
 
-	c.printer("Success")
+	stats, err := tailer.LogMessages(ctx, maxMessages, filterRegexp, outputConfig)
+	filtered := filterRegexp != ""
 
-	return nil
+	if outputConfig.Interactive() {
+		c.printer("Tail stats:\n%s", messages.FormatTailStats(stats, filtered))
+	}
+
+	return err
 }
 
-// Tail prints out a stream of the latest messages in a topic.
-func (c *CLIRunner) Tail(
+// SummarizeHeaders tails the argument topics and prints out the number of messages seen for
+// each value of the argument header. The sample ends after the window has passed,
+// maxMessages messages have been counted, or the context is cancelled. The topics,
+// partitions, and offsets are handled in the same way as in Tail.
+func (c *CLIRunner) SummarizeHeaders(
 	ctx context.Context,
-	topic string,
+	topics []string,
+	topicRegex string,
+	groupID string,
 	offset int64,
 	partitions []int,
+	headerKey string,
+	window time.Duration,
 	maxMessages int,
 	filterRegexp string,
-	raw bool,
+	codec messages.Codec,
 ) error {
+	tailer, err := c.newTailer(ctx, topics, topicRegex, groupID, offset, partitions)
+	if err != nil {
+		return err
+	}
+
+	if window > 0 {
+		c.printer(
+			"Counting messages by value of header %s for %s (press Ctrl-C to stop early)",
+			headerKey,
+			window,
+		)
+	} else {
+		c.printer(
+			"Counting messages by value of header %s (press Ctrl-C to stop)",
+			headerKey,
+		)
+	}
+
+	summary, err := tailer.SummarizeHeaders(
+		ctx,
+		headerKey,
+		window,
+		maxMessages,
+		filterRegexp,
+		codec,
+	)
+	if err != nil {
+		return err
+	}
+
+	if summary.TotalMessages == 0 {
+		c.printer("No messages were received during the sample")
+		return nil
+	}
+
+	c.printer(
+		"Header summary (%d message(s) over %s):\n%s",
+		summary.TotalMessages,
+		summary.EndTime.Sub(summary.StartTime).Round(time.Second),
+		messages.FormatHeaderSummary(summary),
+	)
+	return nil
+}
+
+// newTailer creates a tailer for the argument topics, validating the partitions and
+// offsets. If partitions is empty, all partitions in the topics are tailed.
+func (c *CLIRunner) newTailer(
+	ctx context.Context,
+	topics []string,
+	topicRegex string,
+	groupID string,
+	offset int64,
+	partitions []int,
+) (*messages.TopicTailer, error) {
 	var err error
-	if len(partitions) == 0 {
-		topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
+
+	if topicRegex != "" {
+		topics, err = c.matchingTopics(ctx, topics, topicRegex)
 		if err != nil {
-			return err
+			return nil, err
+		}
+	}
+	if len(topics) == 0 {
+		return nil, errors.New("No topics to tail")
+	}
+	if len(partitions) > 0 && len(topics) > 1 {
+		return nil, errors.New("Partitions can only be set when tailing a single topic")
+	}
+	if groupID != "" {
+		if len(partitions) > 0 {
+			return nil, errors.New("Partitions cannot be set when tailing with a consumer group")
+		}
+		if offset != kafka.FirstOffset && offset != kafka.LastOffset {
+			return nil, errors.New(
+				"Offset must be first (-2) or last (-1) when tailing with a consumer group",
+			)
+		}
+	}
+
+	topicPartitions := map[string][]int{}
+
+	if len(partitions) > 0 {
+		topicPartitions[topics[0]] = partitions
+	} else {
+		topicInfos, err := c.adminClient.GetTopics(ctx, topics, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(topicInfos) < len(topics) {
+			return nil, fmt.Errorf("Could not find all topics in %+v", topics)
+		}
+		for _, topicInfo := range topicInfos {
+			topicPartitions[topicInfo.Name] = topicInfo.PartitionIDs()
 		}
-		partitions = topicInfo.PartitionIDs()
 	}
 
-	log.Debugf("Tailing partitions %+v", partitions)
+	log.Debugf("Tailing topic partitions %+v", topicPartitions)
 
-	tailer := messages.NewTopicTailer(
+	return messages.NewMultiTopicTailer(
 		c.adminClient.GetBootstrapAddrs()[0],
-		topic,
-		partitions,
+		topicPartitions,
+		groupID,
 		offset,
 		10e3,
 		10e6,
+	), nil
+}
+
+// Truncate deletes the records at the start of one or more partitions in a topic. If
+// beforeTime is set, the records before the first message at or after this time are
+// deleted; otherwise, the records before beforeOffset are deleted. If partitions is empty,
+// all partitions in the topic are truncated.
+func (c *CLIRunner) Truncate(
+	ctx context.Context,
+	topic string,
+	partitions []int,
+	beforeOffset int64,
+	beforeTime time.Time,
+	dryRun bool,
+	skipConfirm bool,
+) error {
+	c.startSpinner()
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, false)
+	if err != nil {
+		c.stopSpinner()
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+
+	partitionIDsMap := map[int]struct{}{}
+	for _, partitionID := range topicInfo.PartitionIDs() {
+		partitionIDsMap[partitionID] = struct{}{}
+	}
+
+	if len(partitions) == 0 {
+		partitions = topicInfo.PartitionIDs()
+	} else {
+		for _, partition := range partitions {
+			if _, ok := partitionIDsMap[partition]; !ok {
+				c.stopSpinner()
+				return fmt.Errorf("Partition %d not found in topic %s", partition, topic)
+			}
+		}
+	}
+	sort.Ints(partitions)
+
+	truncations, err := messages.GetPartitionTruncations(
+		ctx,
+		c.adminClient.GetBootstrapAddrs()[0],
+		topic,
+		partitions,
+		beforeOffset,
+		beforeTime,
 	)
-	stats, err := tailer.LogMessages(ctx, maxMessages, filterRegexp, raw)
-	filtered := filterRegexp != ""
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
 
-	if !raw {
-		c.printer("Tail stats:\n%s", messages.FormatTailStats(stats, filtered))
+	c.printer(
+		"Truncation of topic %s:\n%s",
+		topic,
+		messages.FormatPartitionTruncations(truncations),
+	)
+
+	partitionOffsets := map[int]int64{}
+	for _, truncation := range truncations {
+		if truncation.RemovedOffsets() > 0 {
+			partitionOffsets[truncation.Partition] = truncation.BeforeOffset
+		}
 	}
 
-	return err
+	if len(partitionOffsets) == 0 {
+		c.printer("No records to delete")
+		return nil
+	}
+	if dryRun {
+		log.Infof("Skipping update because dryRun is set to true")
+		return nil
+	}
+
+	log.Warn("Deleted records cannot be recovered.")
+	ok, _ := apply.Confirm("OK to delete these records?", skipConfirm)
+	if !ok {
+		return errors.New("Stopping because of user response")
+	}
+
+	c.startSpinner()
+	lowWatermarks, err := c.adminClient.DeleteRecords(ctx, topic, partitionOffsets)
+	c.stopSpinner()
+	if err != nil {
+		return err
+	}
+
+	c.printer(
+		"Success; new first offsets by partition:\n%s",
+		groups.FormatPartitionOffsets(lowWatermarks),
+	)
+
+	return nil
+}
+
+// matchingTopics returns the argument topics plus the names of all of the topics in the
+// cluster that match the argument regexp.
+func (c *CLIRunner) matchingTopics(
+	ctx context.Context,
+	topics []string,
+	topicRegex string,
+) ([]string, error) {
+	topicRegexObj, err := regexp.Compile(topicRegex)
+	if err != nil {
+		return nil, err
+	}
+
+	topicNames, err := c.adminClient.GetTopicNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(topicNames)
+
+	matches := []string{}
+	seen := map[string]struct{}{}
+
+	for _, topic := range topics {
+		matches = append(matches, topic)
+		seen[topic] = struct{}{}
+	}
+	for _, topicName := range topicNames {
+		if _, ok := seen[topicName]; ok {
+			continue
+		}
+		if topicRegexObj.MatchString(topicName) {
+			matches = append(matches, topicName)
+		}
+	}
+
+	return matches, nil
 }
 
 func (c *CLIRunner) startSpinner() {
@@ -546,3 +2050,20 @@ func stringsToInts(strs []string) ([]int, error) {
 
 	return ints, nil
 }
+
+// printJSON writes the argument object to stdout as indented JSON.
+func printJSON(obj interface{}) error {
+	jsonBytes, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagination(t *testing.T) {
+	type testCase struct {
+		description    string
+		pagination     Pagination
+		total          int
+		expStart       int
+		expEnd         int
+		expDescription string
+	}
+
+	testCases := []testCase{
+		{
+			description:    "no limit",
+			pagination:     Pagination{Page: 3},
+			total:          25,
+			expStart:       0,
+			expEnd:         25,
+			expDescription: "",
+		},
+		{
+			description:    "first page",
+			pagination:     Pagination{Limit: 10, Page: 1},
+			total:          25,
+			expStart:       0,
+			expEnd:         10,
+			expDescription: " (page 1, 1-10 out of 25 total)",
+		},
+		{
+			description:    "unset page",
+			pagination:     Pagination{Limit: 10},
+			total:          25,
+			expStart:       0,
+			expEnd:         10,
+			expDescription: " (page 1, 1-10 out of 25 total)",
+		},
+		{
+			description:    "last partial page",
+			pagination:     Pagination{Limit: 10, Page: 3},
+			total:          25,
+			expStart:       20,
+			expEnd:         25,
+			expDescription: " (page 3, 21-25 out of 25 total)",
+		},
+		{
+			description:    "page past the end",
+			pagination:     Pagination{Limit: 10, Page: 4},
+			total:          25,
+			expStart:       25,
+			expEnd:         25,
+			expDescription: " (page 4, no items out of 25 total)",
+		},
+		{
+			description:    "empty list",
+			pagination:     Pagination{Limit: 10, Page: 1},
+			total:          0,
+			expStart:       0,
+			expEnd:         0,
+			expDescription: " (page 1, no items out of 0 total)",
+		},
+	}
+
+	for _, testCase := range testCases {
+		start, end := testCase.pagination.bounds(testCase.total)
+		assert.Equal(t, testCase.expStart, start, testCase.description)
+		assert.Equal(t, testCase.expEnd, end, testCase.description)
+		assert.Equal(
+			t,
+			testCase.expDescription,
+			testCase.pagination.description(start, end, testCase.total),
+			testCase.description,
+		)
+	}
+}
@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/groups"
+	"github.com/segmentio/topicctl/pkg/messages"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// topicDescription is the JSON representation of a topic used by GetTopicDescription.
+type topicDescription struct {
+	Name              string                 `json:"name"`
+	TopicID           string                 `json:"topicID,omitempty"`
+	CreatedAt         time.Time              `json:"createdAt"`
+	Partitions        int                    `json:"partitions"`
+	ReplicationFactor int                    `json:"replicationFactor"`
+	RetentionMinutes  int                    `json:"retentionMinutes,omitempty"`
+	Lifecycle         string                 `json:"lifecycle,omitempty"`
+	Ownership         *config.TopicOwnership `json:"ownership,omitempty"`
+	Config            map[string]string      `json:"config"`
+	PartitionDetails  []admin.PartitionInfo  `json:"partitionDetails"`
+	Health            topicHealth            `json:"health"`
+
+	// SizeBytes and LeaderSizeBytes are omitted if the cluster doesn't support describing
+	// log dirs.
+	SizeBytes       *int64 `json:"sizeBytes,omitempty"`
+	LeaderSizeBytes *int64 `json:"leaderSizeBytes,omitempty"`
+
+	Offsets []partitionOffsets `json:"offsets"`
+
+	// Throughput and Groups are only filled in for full descriptions.
+	Throughput *topicThroughput    `json:"throughput,omitempty"`
+	Groups     []groups.TopicGroup `json:"groups,omitempty"`
+}
+
+type topicHealth struct {
+	MinISR                int      `json:"minISR"`
+	OutOfSyncPartitions   []int    `json:"outOfSyncPartitions"`
+	WrongLeaderPartitions []int    `json:"wrongLeaderPartitions"`
+	BlockedPartitions     []int    `json:"blockedPartitions"`
+	Problems              []string `json:"problems"`
+}
+
+type partitionOffsets struct {
+	Partition   int       `json:"partition"`
+	FirstOffset int64     `json:"firstOffset"`
+	FirstTime   time.Time `json:"firstTime"`
+	LastOffset  int64     `json:"lastOffset"`
+	LastTime    time.Time `json:"lastTime"`
+}
+
+type topicThroughput struct {
+	WindowSeconds  float64  `json:"windowSeconds"`
+	Messages       int64    `json:"messages"`
+	MessagesPerSec float64  `json:"messagesPerSec"`
+	Bytes          *int64   `json:"bytes,omitempty"`
+	BytesPerSec    *float64 `json:"bytesPerSec,omitempty"`
+}
+
+// GetTopicDescription fetches the config, partitions, health, size, offsets, and owners of a
+// single topic, and prints them out together. If full is true, then the message and byte
+// rates of the topic are also sampled over the argument window, and the consumer groups
+// that are consuming from it are included. The owners index can be nil.
+func (c *CLIRunner) GetTopicDescription(
+	ctx context.Context,
+	topic string,
+	full bool,
+	window time.Duration,
+	owners *config.OwnershipIndex,
+	jsonOutput bool,
+) error {
+	c.startSpinner()
+
+	topicInfo, err := c.adminClient.GetTopic(ctx, topic, true)
+	if err != nil {
+		c.stopSpinner()
+		return fmt.Errorf("Error fetching topic info: %+v", err)
+	}
+	brokers, err := c.adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	clusterDefaults, err := c.adminClient.GetClusterDefaultConfig(ctx)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	minISRStatus, err := admin.GetMinISRStatus(topicInfo, clusterDefaults)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+
+	description := topicDescription{
+		Name:              topicInfo.Name,
+		TopicID:           topicInfo.TopicID,
+		CreatedAt:         topicInfo.CreatedAt,
+		Partitions:        len(topicInfo.Partitions),
+		ReplicationFactor: topicInfo.MaxReplication(),
+		RetentionMinutes:  int(topicInfo.Retention().Minutes()),
+		Config:            topicInfo.Config,
+		PartitionDetails:  topicInfo.Partitions,
+		Health: topicHealth{
+			MinISR:                minISRStatus.MinISR,
+			OutOfSyncPartitions:   admin.PartitionIDs(topicInfo.OutOfSyncPartitions(nil)),
+			WrongLeaderPartitions: admin.PartitionIDs(topicInfo.WrongLeaderPartitions(nil)),
+			BlockedPartitions:     admin.PartitionIDs(minISRStatus.BlockedPartitions),
+			Problems:              minISRStatus.Problems,
+		},
+		Offsets: []partitionOffsets{},
+	}
+
+	if owners != nil {
+		ownership := owners.Lookup(topic)
+		description.Ownership = &ownership
+		if topicConfig, ok := owners.TopicConfig(topic); ok {
+			description.Lifecycle = string(topicConfig.Meta.LifecycleState())
+		}
+	}
+
+	logDirs, err := c.adminClient.GetLogDirs(ctx, brokers)
+	if err != nil {
+		log.Debugf("Could not get log dirs, so sizes won't be shown: %+v", err)
+	} else {
+		size := admin.TopicReplicaSize(logDirs, topic)
+		description.SizeBytes = &size
+
+		var leaderSize int64
+		for _, partitionSize := range admin.LeaderReplicaSizes(logDirs, topicInfo) {
+			leaderSize += partitionSize
+		}
+		description.LeaderSizeBytes = &leaderSize
+	}
+
+	bounds, err := messages.GetAllPartitionBounds(
+		ctx,
+		c.adminClient.GetBootstrapAddrs()[0],
+		topic,
+		nil,
+	)
+	if err != nil {
+		c.stopSpinner()
+		return err
+	}
+	newestOffsets := map[int]int64{}
+	for _, bound := range bounds {
+		description.Offsets = append(
+			description.Offsets,
+			partitionOffsets{
+				Partition:   bound.Partition,
+				FirstOffset: bound.FirstOffset,
+				FirstTime:   bound.FirstTime,
+				LastOffset:  bound.LastOffset,
+				LastTime:    bound.LastTime,
+			},
+		)
+		newestOffsets[bound.Partition] = bound.LastOffset
+	}
+
+	var throughput messages.TopicThroughput
+
+	if full {
+		description.Groups, err = c.groupsClient.GetTopicGroups(ctx, topic, newestOffsets)
+		c.stopSpinner()
+		if err != nil {
+			return err
+		}
+
+		throughput, err = c.sampleThroughput(ctx, topic, window)
+		if err != nil {
+			return err
+		}
+
+		description.Throughput = &topicThroughput{
+			WindowSeconds:  throughput.Window().Seconds(),
+			Messages:       throughput.TotalMessages(),
+			MessagesPerSec: float64(throughput.TotalMessages()) / throughput.Window().Seconds(),
+		}
+		if totalBytes, ok := throughput.TotalBytes(); ok {
+			bytesPerSec := float64(totalBytes) / throughput.Window().Seconds()
+			description.Throughput.Bytes = &totalBytes
+			description.Throughput.BytesPerSec = &bytesPerSec
+		}
+	} else {
+		c.stopSpinner()
+	}
+
+	if jsonOutput {
+		return printJSON(description)
+	}
+
+	c.printer(
+		"Topic %s:\n%s",
+		topic,
+		admin.FormatTopics([]admin.TopicInfo{topicInfo}, brokers, false, nil),
+	)
+	if topicInfo.TopicID != "" || !topicInfo.CreatedAt.IsZero() {
+		var createdStr string
+		if !topicInfo.CreatedAt.IsZero() {
+			createdStr = topicInfo.CreatedAt.Format(time.RFC3339)
+		}
+		c.printer("Topic ID: %s, created: %s", topicInfo.TopicID, createdStr)
+	}
+	if description.Ownership != nil {
+		c.printer(
+			"Owners:\n%s",
+			config.FormatTopicOwnerships([]config.TopicOwnership{*description.Ownership}),
+		)
+	}
+
+	if len(topicInfo.Config) > 0 {
+		c.printer("Config overrides:\n%s", admin.FormatConfig(topicInfo.Config))
+	} else {
+		c.printer("No config overrides; all settings are inherited from the cluster defaults")
+	}
+
+	c.printer(
+		"Partitions:\n%s",
+		admin.FormatTopicPartitions(topicInfo.Partitions, brokers),
+	)
+
+	health := description.Health
+	if len(health.OutOfSyncPartitions) == 0 && len(health.WrongLeaderPartitions) == 0 &&
+		minISRStatus.OK() {
+		c.printer(
+			"Health: all %d partition(s) are in sync and led by their preferred leaders",
+			len(topicInfo.Partitions),
+		)
+	} else {
+		if len(health.OutOfSyncPartitions) > 0 {
+			log.Warnf(
+				"Health: %d partition(s) have out-of-sync replicas: %+v",
+				len(health.OutOfSyncPartitions),
+				health.OutOfSyncPartitions,
+			)
+		}
+		if len(health.WrongLeaderPartitions) > 0 {
+			log.Warnf(
+				"Health: %d partition(s) aren't led by their preferred leaders: %+v",
+				len(health.WrongLeaderPartitions),
+				health.WrongLeaderPartitions,
+			)
+		}
+		if !minISRStatus.OK() {
+			log.Warnf(
+				"Health: min.insync.replicas problems found:\n%s",
+				admin.FormatMinISRStatuses([]admin.MinISRStatus{minISRStatus}),
+			)
+		}
+	}
+
+	if description.SizeBytes != nil {
+		c.printer(
+			"Size: %s across all replicas, %s in leader replicas",
+			util.PrettyBytes(*description.SizeBytes),
+			util.PrettyBytes(*description.LeaderSizeBytes),
+		)
+	}
+
+	c.printer("Partition bounds:\n%s", messages.FormatBounds(bounds))
+	if formattedTotals := messages.FormatBoundTotals(bounds); formattedTotals != "" {
+		c.printer("Total bounds across all partitions:\n%s", formattedTotals)
+	}
+
+	if !full {
+		return nil
+	}
+
+	c.printer(
+		"Throughput over %s:\n%s",
+		throughput.Window().Round(time.Millisecond),
+		messages.FormatThroughput(throughput),
+	)
+
+	if len(description.Groups) == 0 {
+		c.printer("No consumer groups have members consuming from topic %s", topic)
+	} else {
+		c.printer(
+			"Consumer groups (%d):\n%s",
+			len(description.Groups),
+			groups.FormatTopicGroups(description.Groups),
+		)
+	}
+
+	return nil
+}
@@ -7,23 +7,34 @@ import (
 	"os"
 	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/olekukonko/tablewriter"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/topicctl/pkg/admin"
 	"github.com/segmentio/topicctl/pkg/groups"
+	"github.com/segmentio/topicctl/pkg/messages"
 	log "github.com/sirupsen/logrus"
 )
 
+// defaultProgressInterval is how often the output of the progress command is refreshed if no
+// interval is given.
+const defaultProgressInterval = 5 * time.Second
+
 var (
 	commandSuggestions = []prompt.Suggest{
 		{
 			Text:        "get",
 			Description: "Get information about one or more resources in the cluster",
 		},
+		{
+			Text:        "progress",
+			Description: "Watch the progress of the in-progress partition reassignment",
+		},
 		{
 			Text:        "tail",
 			Description: "Tail all messages in a topic",
@@ -47,18 +58,42 @@ var (
 			Text:        "brokers",
 			Description: "Get all brokers",
 		},
+		{
+			Text:        "cluster",
+			Description: "Get a summary of the cluster",
+		},
+		{
+			Text:        "config-diff",
+			Description: "Get config for a topic alongside the cluster and Kafka defaults",
+		},
+		{
+			Text:        "freezes",
+			Description: "Get topics that are frozen in the cluster",
+		},
 		{
 			Text:        "groups",
 			Description: "Get all consumer groups",
 		},
+		{
+			Text:        "health",
+			Description: "Get topics with min.insync.replicas problems",
+		},
 		{
 			Text:        "lags",
 			Description: "Get partition lags for all members of a consumer group",
 		},
+		{
+			Text:        "log-dirs",
+			Description: "Get the log dirs on all brokers or a single one",
+		},
 		{
 			Text:        "members",
 			Description: "Get members in a consumer group",
 		},
+		{
+			Text:        "messages-at-offset",
+			Description: "Get the messages at an offset or offset range in a topic partition",
+		},
 		{
 			Text:        "partitions",
 			Description: "Get all partitions for a topic",
@@ -67,6 +102,10 @@ var (
 			Text:        "offsets",
 			Description: "Get the offset ranges for all partitions in a topic",
 		},
+		{
+			Text:        "rack-violations",
+			Description: "Get partitions that aren't spread across as many racks as possible",
+		},
 		{
 			Text:        "topics",
 			Description: "Get all topics",
@@ -239,6 +278,15 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "cluster":
+			if err := checkArgs(words, 2); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if err := r.cliRunner.GetClusterInfo(ctx); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "config":
 			if err := checkArgs(words, 3); err != nil {
 				log.Errorf("Error: %+v", err)
@@ -248,6 +296,24 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "config-diff":
+			if err := checkArgs(words, 3); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if err := r.cliRunner.GetConfigDiff(ctx, words[2]); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+		case "freezes":
+			if err := checkArgs(words, 2); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if err := r.cliRunner.GetTopicFreezes(ctx); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "groups":
 			if err := checkArgs(words, 2); err != nil {
 				log.Errorf("Error: %+v", err)
@@ -257,6 +323,15 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "health":
+			if err := checkArgs(words, 2); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if err := r.cliRunner.GetHealth(ctx, nil); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "lags":
 			if err := checkArgs(words, 4); err != nil {
 				log.Errorf("Error: %+v", err)
@@ -266,6 +341,25 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "log-dirs":
+			if err := checkArgsMax(words, 3); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			var brokerIDs []int
+			if len(words) == 3 {
+				brokerID, err := strconv.Atoi(words[2])
+				if err != nil {
+					log.Errorf("Error: %+v", err)
+					return
+				}
+				brokerIDs = []int{brokerID}
+			}
+
+			if err := r.cliRunner.GetLogDirs(ctx, brokerIDs, false); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "members":
 			if err := checkArgs(words, 3); err != nil {
 				log.Errorf("Error: %+v", err)
@@ -275,12 +369,44 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "messages-at-offset":
+			if err := checkArgs(words, 5); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			partition, err := strconv.Atoi(words[3])
+			if err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			startOffset, endOffset, err := messages.ParseOffsetRange(words[4])
+			if err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if err := r.cliRunner.GetMessagesAtOffset(
+				ctx,
+				words[2],
+				partition,
+				startOffset,
+				endOffset,
+				messages.TailFormatDefault,
+			); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "partitions":
 			if err := checkArgs(words, 3); err != nil {
 				log.Errorf("Error: %+v", err)
 				return
 			}
-			if err := r.cliRunner.GetPartitions(ctx, words[2]); err != nil {
+			if err := r.cliRunner.GetPartitions(
+				ctx,
+				words[2],
+				admin.PartitionFilter{},
+				false,
+				Pagination{},
+			); err != nil {
 				log.Errorf("Error: %+v", err)
 				return
 			}
@@ -293,12 +419,32 @@ func (r *Repl) executor(in string) {
 				log.Errorf("Error: %+v", err)
 				return
 			}
+		case "rack-violations":
+			if err := checkArgsMax(words, 3); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			var topicName string
+			if len(words) == 3 {
+				topicName = words[2]
+			}
+
+			if err := r.cliRunner.GetRackViolations(ctx, topicName, 0); err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
 		case "topics":
 			if err := checkArgs(words, 2); err != nil {
 				log.Errorf("Error: %+v", err)
 				return
 			}
-			if err := r.cliRunner.GetTopics(ctx, false); err != nil {
+			if err := r.cliRunner.GetTopics(
+				ctx,
+				false,
+				nil,
+				Pagination{},
+				admin.DefaultRecreatedWindow,
+			); err != nil {
 				log.Errorf("Error: %+v", err)
 				return
 			}
@@ -308,6 +454,29 @@ func (r *Repl) executor(in string) {
 	case "help":
 		fmt.Printf("> Commands:\n%s\n", helpTableStr)
 		return
+	case "progress":
+		if err := checkArgsMax(words, 2); err != nil {
+			log.Errorf("Error: %+v", err)
+			return
+		}
+		refreshInterval := defaultProgressInterval
+		if len(words) == 2 {
+			var err error
+			refreshInterval, err = time.ParseDuration(words[1])
+			if err != nil {
+				log.Errorf("Error: %+v", err)
+				return
+			}
+			if refreshInterval <= 0 {
+				log.Error("Error: Refresh interval must be positive")
+				return
+			}
+		}
+
+		if err := r.cliRunner.WatchReassignment(ctx, refreshInterval); err != nil {
+			log.Errorf("Error: %+v", err)
+			return
+		}
 	case "tail":
 		if err := checkArgsMin(words, 2); err != nil {
 			log.Errorf("Error: %+v", err)
@@ -325,12 +494,14 @@ func (r *Repl) executor(in string) {
 
 		err := r.cliRunner.Tail(
 			ctx,
-			words[1],
+			[]string{words[1]},
+			"",
+			"",
 			kafka.LastOffset,
 			nil,
 			-1,
 			filterRegexp,
-			false,
+			messages.TailOutputConfig{Format: messages.TailFormatDefault},
 		)
 		if err != nil {
 			log.Errorf("Error: %+v", err)
@@ -354,9 +525,12 @@ func (r *Repl) completer(doc prompt.Document) []prompt.Suggest {
 			suggestions = getSuggestions
 		} else if len(words) == 3 && words[0] == "get" &&
 			(words[1] == "balance" ||
+				words[1] == "config-diff" ||
 				words[1] == "lags" ||
+				words[1] == "messages-at-offset" ||
 				words[1] == "partitions" ||
-				words[1] == "offsets") {
+				words[1] == "offsets" ||
+				words[1] == "rack-violations") {
 			suggestions = r.topicSuggestions
 		} else if len(words) == 4 && words[0] == "get" && words[1] == "lags" {
 			suggestions = r.groupSuggestions
@@ -428,22 +602,46 @@ func helpTable() string {
 				"  get brokers",
 				"Get all brokers",
 			},
+			{
+				"  get cluster",
+				"Get a summary of the cluster",
+			},
 			{
 				"  get config [broker or topic]",
 				"Get config for a broker or topic",
 			},
+			{
+				"  get config-diff [topic]",
+				"Get config for a topic alongside the cluster and Kafka defaults",
+			},
+			{
+				"  get freezes",
+				"Get topics that are frozen in the cluster",
+			},
 			{
 				"  get groups",
 				"Get all consumer groups",
 			},
+			{
+				"  get health",
+				"Get topics with min.insync.replicas problems",
+			},
 			{
 				"  get lags [topic] [group]",
 				"Get consumer group lags for all partitions in a topic",
 			},
+			{
+				"  get log-dirs [optional broker]",
+				"Get the log dirs on all brokers or a single one",
+			},
 			{
 				"  get members [group]",
 				"Get the members of a consumer group",
 			},
+			{
+				"  get messages-at-offset [topic] [partition] [offset(s)]",
+				"Get the messages at an offset or offset range in a topic partition",
+			},
 			{
 				"  get partitions [topic]",
 				"Get all partitions for a topic",
@@ -452,10 +650,18 @@ func helpTable() string {
 				"  get offsets [topic]",
 				"Get the offset ranges for all partitions in a topic",
 			},
+			{
+				"  get rack-violations [optional topic]",
+				"Get partitions that aren't spread across as many racks as possible",
+			},
 			{
 				"  get topics",
 				"Get all topics",
 			},
+			{
+				"  progress [optional refresh interval]",
+				"Watch the progress of the in-progress partition reassignment (Ctrl-C to stop)",
+			},
 			{
 				"  tail [topic] [optional filter regexp]",
 				"Tail all messages in a topic",
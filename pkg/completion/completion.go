@@ -0,0 +1,129 @@
+// Package completion contains helpers for the dynamic shell completions of topic and group
+// names. Looking these up requires connecting to the cluster, so the results are cached on
+// disk for a short time to keep completions responsive.
+package completion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTTL is the default amount of time that cached metadata is used for before
+// it's fetched again.
+const DefaultCacheTTL = 5 * time.Minute
+
+// Metadata contains the cluster resource names that are used for completions.
+type Metadata struct {
+	Topics    []string  `json:"topics"`
+	Groups    []string  `json:"groups"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Fetcher is a function that fetches the current metadata from a cluster.
+type Fetcher func(ctx context.Context) (Metadata, error)
+
+// Cache stores the metadata for each cluster in a separate file in a directory.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCache returns a new Cache instance that stores its files in the argument directory.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{
+		dir: dir,
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// DefaultCacheDir returns the directory used for the completion cache if one isn't
+// specified. It's under the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "topicctl", "completion"), nil
+}
+
+// Get returns the metadata for the cluster identified by the argument key. If the cached
+// metadata is missing or older than the cache TTL, it's refreshed using the argument fetcher.
+// Stale metadata is returned if the fetch fails, since completions from slightly out-of-date
+// metadata are better than none.
+func (c *Cache) Get(ctx context.Context, key string, fetch Fetcher) (Metadata, error) {
+	path := filepath.Join(c.dir, cacheFileName(key))
+
+	cached, cacheErr := c.read(path)
+	if cacheErr == nil && c.now().Sub(cached.UpdatedAt) < c.ttl {
+		return cached, nil
+	}
+
+	metadata, err := fetch(ctx)
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return metadata, err
+	}
+
+	metadata.UpdatedAt = c.now()
+	if err := c.write(path, metadata); err != nil {
+		return metadata, err
+	}
+	return metadata, nil
+}
+
+func (c *Cache) read(path string) (Metadata, error) {
+	metadata := Metadata{}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal(contents, &metadata)
+	return metadata, err
+}
+
+func (c *Cache) write(path string, metadata Metadata) error {
+	contents, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, contents, 0644)
+}
+
+// FilterPrefix returns the values that start with the argument prefix, excluding any in
+// the argument exclusions, e.g. arguments that have already been provided.
+func FilterPrefix(values []string, prefix string, exclusions ...string) []string {
+	excluded := map[string]struct{}{}
+	for _, exclusion := range exclusions {
+		excluded[exclusion] = struct{}{}
+	}
+
+	filtered := []string{}
+	for _, value := range values {
+		if _, ok := excluded[value]; ok {
+			continue
+		}
+		if strings.HasPrefix(value, prefix) {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
+
+func cacheFileName(key string) string {
+	return fmt.Sprintf("%x.json", sha256.Sum256([]byte(key)))
+}
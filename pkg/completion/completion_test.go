@@ -0,0 +1,79 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheGet(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "completion")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(dir, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	fetches := 0
+	fetchErr := error(nil)
+	fetch := func(ctx context.Context) (Metadata, error) {
+		fetches++
+		return Metadata{
+			Topics: []string{"topic1", "topic2"},
+			Groups: []string{"group1"},
+		}, fetchErr
+	}
+
+	metadata, err := cache.Get(ctx, "cluster1", fetch)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"topic1", "topic2"}, metadata.Topics)
+	assert.Equal(t, []string{"group1"}, metadata.Groups)
+	assert.Equal(t, 1, fetches)
+
+	// Fresh metadata is read from the cache
+	now = now.Add(30 * time.Second)
+	_, err = cache.Get(ctx, "cluster1", fetch)
+	require.Nil(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// Each cluster has its own entry
+	_, err = cache.Get(ctx, "cluster2", fetch)
+	require.Nil(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// Stale metadata is refreshed
+	now = now.Add(time.Minute)
+	_, err = cache.Get(ctx, "cluster1", fetch)
+	require.Nil(t, err)
+	assert.Equal(t, 3, fetches)
+
+	// If the refresh fails, the stale metadata is used
+	now = now.Add(time.Minute)
+	fetchErr = errors.New("fetch failed")
+	metadata, err = cache.Get(ctx, "cluster1", fetch)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"topic1", "topic2"}, metadata.Topics)
+	assert.Equal(t, 4, fetches)
+
+	// With nothing cached, fetch errors are returned
+	_, err = cache.Get(ctx, "cluster3", fetch)
+	assert.NotNil(t, err)
+}
+
+func TestFilterPrefix(t *testing.T) {
+	values := []string{"orders", "orders-dlq", "payments"}
+
+	assert.Equal(t, values, FilterPrefix(values, ""))
+	assert.Equal(t, []string{"orders", "orders-dlq"}, FilterPrefix(values, "ord"))
+	assert.Equal(t, []string{"orders-dlq"}, FilterPrefix(values, "ord", "orders"))
+	assert.Equal(t, []string{}, FilterPrefix(values, "x"))
+}
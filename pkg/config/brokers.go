@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/segmentio/kafka-go"
+)
+
+// dynamicBrokerKeys contains the broker config keys that can be updated dynamically, i.e.
+// without a broker restart. See https://kafka.apache.org/documentation/#dynamicbrokerconfigs
+// for details.
+var dynamicBrokerKeys = map[string]struct{}{
+	"background.threads":                      {},
+	"compression.type":                        {},
+	"follower.replication.throttled.rate":     {},
+	"leader.replication.throttled.rate":       {},
+	"log.cleaner.backoff.ms":                  {},
+	"log.cleaner.dedupe.buffer.size":          {},
+	"log.cleaner.delete.retention.ms":         {},
+	"log.cleaner.io.buffer.load.factor":       {},
+	"log.cleaner.io.buffer.size":              {},
+	"log.cleaner.io.max.bytes.per.second":     {},
+	"log.cleaner.max.compaction.lag.ms":       {},
+	"log.cleaner.min.cleanable.ratio":         {},
+	"log.cleaner.min.compaction.lag.ms":       {},
+	"log.cleaner.threads":                     {},
+	"log.cleanup.policy":                      {},
+	"log.flush.interval.messages":             {},
+	"log.flush.interval.ms":                   {},
+	"log.index.interval.bytes":                {},
+	"log.index.size.max.bytes":                {},
+	"log.message.downconversion.enable":       {},
+	"log.message.timestamp.difference.max.ms": {},
+	"log.message.timestamp.type":              {},
+	"log.preallocate":                         {},
+	"log.retention.bytes":                     {},
+	"log.retention.ms":                        {},
+	"log.roll.jitter.ms":                      {},
+	"log.roll.ms":                             {},
+	"log.segment.bytes":                       {},
+	"log.segment.delete.delay.ms":             {},
+	"max.connections":                         {},
+	"max.connections.per.ip":                  {},
+	"max.connections.per.ip.overrides":        {},
+	"message.max.bytes":                       {},
+	"metric.reporters":                        {},
+	"min.insync.replicas":                     {},
+	"num.io.threads":                          {},
+	"num.network.threads":                     {},
+	"num.recovery.threads.per.data.dir":       {},
+	"num.replica.fetchers":                    {},
+	"unclean.leader.election.enable":          {},
+}
+
+// perBrokerOnlyKeys contains the dynamic keys that can only be set on individual brokers,
+// not as cluster-wide defaults.
+var perBrokerOnlyKeys = map[string]struct{}{
+	"follower.replication.throttled.rate": {},
+	"leader.replication.throttled.rate":   {},
+}
+
+// BrokersConfig represents the desired dynamic configuration of the brokers in a cluster.
+type BrokersConfig struct {
+	Meta BrokersMeta `json:"meta"`
+	Spec BrokersSpec `json:"spec"`
+}
+
+// BrokersMeta stores the metadata associated with a brokers config. These are used to match
+// the config against a cluster config.
+type BrokersMeta struct {
+	Cluster     string `json:"cluster"`
+	Region      string `json:"region"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+}
+
+// BrokersSpec stores the desired dynamic broker settings.
+type BrokersSpec struct {
+	// DefaultSettings are applied as cluster-wide defaults for all brokers.
+	DefaultSettings BrokerSettings `json:"defaultSettings,omitempty"`
+
+	// BrokerSettings are applied to individual brokers, keyed by broker ID.
+	BrokerSettings map[string]BrokerSettings `json:"brokerSettings,omitempty"`
+}
+
+// BrokerSettings is a map of key/value pairs that correspond to dynamic Kafka broker
+// config settings.
+type BrokerSettings map[string]interface{}
+
+// Validate evaluates whether the brokers config is valid.
+func (b BrokersConfig) Validate() error {
+	var err error
+
+	if b.Meta.Cluster == "" {
+		err = multierror.Append(err, errors.New("Cluster must be set"))
+	}
+	if b.Meta.Region == "" {
+		err = multierror.Append(err, errors.New("Region must be set"))
+	}
+	if b.Meta.Environment == "" {
+		err = multierror.Append(err, errors.New("Environment must be set"))
+	}
+
+	if settingsErr := b.Spec.DefaultSettings.Validate(); settingsErr != nil {
+		err = multierror.Append(err, settingsErr)
+	}
+	for key := range b.Spec.DefaultSettings {
+		if _, ok := perBrokerOnlyKeys[key]; ok {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Key %s can only be set for individual brokers", key),
+			)
+		}
+	}
+
+	for brokerIDStr, settings := range b.Spec.BrokerSettings {
+		if _, parseErr := strconv.Atoi(brokerIDStr); parseErr != nil {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Broker ID %s is not an integer", brokerIDStr),
+			)
+		}
+		if settingsErr := settings.Validate(); settingsErr != nil {
+			err = multierror.Append(err, settingsErr)
+		}
+	}
+
+	return err
+}
+
+// BrokerIDs returns the IDs of the brokers with individual settings, in sorted order.
+// It assumes that the config has already been validated.
+func (b BrokersConfig) BrokerIDs() []int {
+	brokerIDs := []int{}
+
+	for brokerIDStr := range b.Spec.BrokerSettings {
+		brokerID, err := strconv.Atoi(brokerIDStr)
+		if err != nil {
+			continue
+		}
+		brokerIDs = append(brokerIDs, brokerID)
+	}
+
+	sort.Ints(brokerIDs)
+	return brokerIDs
+}
+
+// SettingsForBroker returns the individual settings for the argument broker, or nil if
+// there aren't any.
+func (b BrokersConfig) SettingsForBroker(brokerID int) BrokerSettings {
+	return b.Spec.BrokerSettings[fmt.Sprintf("%d", brokerID)]
+}
+
+// Validate determines whether the given broker settings are valid.
+func (b BrokerSettings) Validate() error {
+	var validateErr error
+
+	for key, value := range b {
+		if _, ok := dynamicBrokerKeys[key]; !ok {
+			validateErr = multierror.Append(
+				validateErr,
+				fmt.Errorf("Key %s is not a recognized dynamic broker config setting", key),
+			)
+			continue
+		}
+
+		if _, err := interfaceToString(value); err != nil {
+			validateErr = multierror.Append(
+				validateErr,
+				fmt.Errorf(
+					"Could not convert value for key %s to string: %+v",
+					key,
+					err,
+				),
+			)
+		}
+	}
+
+	return validateErr
+}
+
+// ConfigMapDiffs compares these broker settings to a string map fetched from
+// the cluster. It has the same semantics as TopicSettings.ConfigMapDiffs.
+func (b BrokerSettings) ConfigMapDiffs(
+	configMap map[string]string,
+) ([]string, []string, error) {
+	return b.ToTopicSettings().ConfigMapDiffs(configMap)
+}
+
+// ToConfigEntries converts the argument keys in the current settings into a slice of
+// kafka-go config entries. If keys is nil, then all fields are converted.
+func (b BrokerSettings) ToConfigEntries(keys []string) ([]kafka.ConfigEntry, error) {
+	return b.ToTopicSettings().ToConfigEntries(keys)
+}
+
+// ToTopicSettings converts these broker settings to a TopicSettings instance so that they
+// can be formatted with the same helpers.
+func (b BrokerSettings) ToTopicSettings() TopicSettings {
+	return TopicSettings(b)
+}
+
+// CheckBrokersConsistency verifies that the argument brokers config is consistent with the
+// argument cluster config.
+func CheckBrokersConsistency(brokersConfig BrokersConfig, clusterConfig ClusterConfig) error {
+	var err error
+
+	if brokersConfig.Meta.Cluster != clusterConfig.Meta.Name {
+		err = multierror.Append(
+			err,
+			errors.New("Brokers config cluster name does not match name in cluster config"),
+		)
+	}
+	if brokersConfig.Meta.Environment != clusterConfig.Meta.Environment {
+		err = multierror.Append(
+			err,
+			errors.New("Brokers config environment does not match cluster environment"),
+		)
+	}
+	if brokersConfig.Meta.Region != clusterConfig.Meta.Region {
+		err = multierror.Append(
+			err,
+			errors.New("Brokers config region does not match cluster region"),
+		)
+	}
+
+	return err
+}
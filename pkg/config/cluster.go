@@ -2,11 +2,21 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/hashicorp/go-multierror"
+	szk "github.com/samuel/go-zookeeper/zk"
 	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/locks"
+	"github.com/segmentio/topicctl/pkg/zk"
 )
 
 // KafkaVersionMajor is a string type for storing Kafka versions.
@@ -20,6 +30,43 @@ const (
 	KafkaVersionMajor2 KafkaVersionMajor = "v2"
 )
 
+// RebalanceGoal is a string type that stores the name of a goal that rebalances optimize
+// for.
+type RebalanceGoal string
+
+const (
+	// RebalanceGoalRackDistribution spreads the replicas for each partition across as many
+	// racks as possible.
+	RebalanceGoalRackDistribution RebalanceGoal = "rack-distribution"
+
+	// RebalanceGoalReplicaBalance evens out the number of replicas on each broker.
+	RebalanceGoalReplicaBalance RebalanceGoal = "replica-balance"
+
+	// RebalanceGoalLeaderBalance evens out the number of partition leaders on each broker.
+	RebalanceGoalLeaderBalance RebalanceGoal = "leader-balance"
+
+	// RebalanceGoalDiskBalance evens out the (estimated) amount of data stored on each broker.
+	RebalanceGoalDiskBalance RebalanceGoal = "disk-balance"
+)
+
+var allRebalanceGoals = []RebalanceGoal{
+	RebalanceGoalRackDistribution,
+	RebalanceGoalReplicaBalance,
+	RebalanceGoalLeaderBalance,
+	RebalanceGoalDiskBalance,
+}
+
+// LockBackend is a string type that stores the name of the backend used for apply locks.
+type LockBackend string
+
+const (
+	// LockBackendZK stores locks in zookeeper. This is the default.
+	LockBackendZK LockBackend = "zk"
+
+	// LockBackendDynamoDB stores locks in a DynamoDB table.
+	LockBackendDynamoDB LockBackend = "dynamodb"
+)
+
 // ClusterConfig stores information about a cluster that's referred to by one
 // or more topic configs. These configs should reflect the reality of what's been
 // set up externally; there's no way to "apply" these at the moment.
@@ -51,10 +98,55 @@ type ClusterSpec struct {
 	// these are assumed to be under the zk root.
 	ZKPrefix string `json:"zkPrefix"`
 
+	// ZKObserverAddrs is a list of zookeeper observer addresses. If the addresses in
+	// ZKAddrs can't be reached, these are used instead, with the tool in read-only mode.
+	ZKObserverAddrs []string `json:"zkObserverAddrs,omitempty"`
+
+	// ZKSessionTimeoutSeconds and ZKConnectTimeoutSeconds are the zookeeper session timeout
+	// and the maximum amount of time to wait for a session when connecting. If unset,
+	// reasonable defaults are used instead.
+	ZKSessionTimeoutSeconds int `json:"zkSessionTimeoutSeconds,omitempty"`
+	ZKConnectTimeoutSeconds int `json:"zkConnectTimeoutSeconds,omitempty"`
+
+	// ZKMaxRetries is the number of times that zookeeper operations are retried, with
+	// exponential backoff, after recoverable errors. If unset, a reasonable default is
+	// used; set it to -1 to disable retries.
+	ZKMaxRetries int `json:"zkMaxRetries,omitempty"`
+
+	// BrokerMaxRetries is the number of times that broker API requests are retried, with
+	// exponential backoff, after transient errors like a controller move. If unset, a
+	// reasonable default is used; set it to -1 to disable retries.
+	BrokerMaxRetries int `json:"brokerMaxRetries,omitempty"`
+
+	// AdminQPS is the maximum rate of zookeeper and broker API requests sent by topicctl
+	// for this cluster. If unset, a default of 500 is used; set it to -1 to disable rate
+	// limiting.
+	AdminQPS float64 `json:"adminQPS,omitempty"`
+
+	// ZKAuth, if set, contains the credentials used to authenticate with zookeeper.
+	ZKAuth *ZKAuthConfig `json:"zkAuth,omitempty"`
+
+	// ZKSetACL indicates whether the zookeeper nodes created by topicctl should be restricted
+	// so that only the authenticated user can modify them. As with the zookeeper.set.acl
+	// broker setting, the nodes are still world-readable. Requires ZKAuth to be set.
+	ZKSetACL bool `json:"zkSetACL,omitempty"`
+
+	// ZKTLS, if set, configures TLS for the connections to the zookeeper secure client port.
+	ZKTLS *ZKTLSConfig `json:"zkTLS,omitempty"`
+
 	// ZKLockPath indicates where locks are stored in zookeeper. If blank, then
-	// no locking will be used on apply operations.
+	// no locking will be used on apply operations. With the dynamodb lock backend, this is
+	// used as the prefix for the lock keys instead.
 	ZKLockPath string `json:"zkLockPath"`
 
+	// LockBackend is the backend used for apply locks. If unset, locks are stored
+	// in zookeeper.
+	LockBackend LockBackend `json:"lockBackend,omitempty"`
+
+	// DynamoDBLockTable is the name of the DynamoDB table that stores locks. It's required
+	// if LockBackend is dynamodb.
+	DynamoDBLockTable string `json:"dynamoDBLockTable,omitempty"`
+
 	// ClusterID is the value of the [prefix]/cluster/id node in zookeeper. If set, it's used
 	// to validate that the cluster we're communicating with is the right one. If blank,
 	// this check isn't done.
@@ -68,6 +160,190 @@ type ClusterSpec struct {
 	// DefaultThrottleMB is the default broker throttle used for migrations in this
 	// cluster. If unset, then a reasonable default is used instead.
 	DefaultThrottleMB int64 `json:"defaultThrottleMB"`
+
+	// SchemaRegistryURL is the base URL of the schema registry associated with this cluster.
+	// It's required if any topics in the cluster declare schema subjects.
+	SchemaRegistryURL string `json:"schemaRegistryURL,omitempty"`
+
+	// ConnectURL is the base URL of the Kafka Connect REST API for connectors that
+	// run against this cluster. It's required for applying or checking connector configs.
+	ConnectURL string `json:"connectURL,omitempty"`
+
+	// RebalanceGoals are the goals that topic rebalances in this cluster optimize for, in
+	// priority order. A lower-priority goal is never improved at the expense of a
+	// higher-priority one. If unset, then the default, count-based rebalancer is used.
+	RebalanceGoals []RebalanceGoal `json:"rebalanceGoals,omitempty"`
+
+	// TopicDefaults, if set, contains topic settings that are inherited by all of the topic
+	// configs in this cluster unless they override them.
+	TopicDefaults *TopicDefaults `json:"topicDefaults,omitempty"`
+
+	// RetentionTiers are named retention periods, e.g. "standard: 7d", that topic configs
+	// can reference via retentionTier. If any are set, topics can't use other retention
+	// values.
+	RetentionTiers map[string]string `json:"retentionTiers,omitempty"`
+
+	// Hooks are run before and after each topic in this cluster is created or migrated.
+	Hooks []HookConfig `json:"hooks,omitempty"`
+
+	// MaintenanceWindows, if set, are the only times at which apply is allowed to move data
+	// between brokers without the --ignore-window flag.
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Owners assigns owners to topics by name, like a CODEOWNERS file. The last matching
+	// rule wins, and the ownership fields in topic configs take precedence over all rules.
+	Owners []OwnershipRule `json:"owners,omitempty"`
+
+	// PasswordPolicy, if set, contains the requirements for the passwords of the SCRAM users
+	// that are created or updated with topicctl. If unset, DefaultPasswordPolicy is used.
+	PasswordPolicy *PasswordPolicy `json:"passwordPolicy,omitempty"`
+}
+
+// TopicDefaults contains the cluster-wide defaults for topic configs. Each value is only
+// used in topic configs that don't set it explicitly; settings are merged key-by-key.
+type TopicDefaults struct {
+	ReplicationFactor int                    `json:"replicationFactor,omitempty"`
+	RetentionMinutes  int                    `json:"retentionMinutes,omitempty"`
+	Settings          TopicSettings          `json:"settings,omitempty"`
+	PlacementConfig   TopicPlacementDefaults `json:"placement,omitempty"`
+}
+
+// TopicPlacementDefaults contains the default placement strategy and picker for topics.
+// The other placement options are topic-specific, so they can't be set here.
+type TopicPlacementDefaults struct {
+	Strategy PlacementStrategy `json:"strategy,omitempty"`
+	Picker   PickerMethod      `json:"picker,omitempty"`
+}
+
+// Validate evaluates whether the topic defaults are valid.
+func (d TopicDefaults) Validate() error {
+	var err error
+
+	if d.ReplicationFactor < 0 {
+		err = multierror.Append(err, errors.New("Default ReplicationFactor must be >= 0"))
+	}
+	if d.RetentionMinutes < 0 {
+		err = multierror.Append(err, errors.New("Default RetentionMinutes must be >= 0"))
+	}
+	if d.RetentionMinutes > 0 && d.Settings["retention.ms"] != nil {
+		err = multierror.Append(
+			err,
+			errors.New("Cannot set both RetentionMinutes and retention.ms in default settings"),
+		)
+	}
+	if settingsErr := d.Settings.Validate(); settingsErr != nil {
+		err = multierror.Append(err, settingsErr)
+	}
+
+	// The static and topic set strategies need per-topic options, so they can't be defaults
+	defaultStrategies := []string{
+		string(PlacementStrategyAny),
+		string(PlacementStrategyBalancedLeaders),
+		string(PlacementStrategyInRack),
+	}
+	if d.PlacementConfig.Strategy != "" &&
+		!inValues(string(d.PlacementConfig.Strategy), defaultStrategies...) {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Default placement strategy must be in %+v", defaultStrategies),
+		)
+	}
+	if d.PlacementConfig.Picker != "" {
+		pickerMethodFound := false
+		for _, pickerMethod := range allPickerMethods {
+			if d.PlacementConfig.Picker == pickerMethod {
+				pickerMethodFound = true
+				break
+			}
+		}
+		if !pickerMethodFound {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Default picker method must be in %+v", allPickerMethods),
+			)
+		}
+	}
+
+	return err
+}
+
+// ZKAuthMechanism is the mechanism used to authenticate with zookeeper.
+type ZKAuthMechanism string
+
+const (
+	// ZKAuthMechanismDigest uses the zookeeper digest scheme with a username and password.
+	ZKAuthMechanismDigest ZKAuthMechanism = "digest"
+
+	// ZKAuthMechanismSASL uses SASL (e.g., Kerberos). It's recognized so that configs shared
+	// with other tools can be validated, but isn't supported by the zookeeper client that
+	// topicctl uses.
+	ZKAuthMechanismSASL ZKAuthMechanism = "sasl"
+)
+
+// ZKPasswordEnvVar is the environment variable that the zookeeper password is read from
+// if it isn't set in the cluster config.
+const ZKPasswordEnvVar = "TOPICCTL_ZK_PASSWORD"
+
+// ZKAuthConfig contains the credentials used to authenticate with zookeeper.
+type ZKAuthConfig struct {
+	// Mechanism is the authentication mechanism. If unset, digest is used.
+	Mechanism ZKAuthMechanism `json:"mechanism,omitempty"`
+
+	// Username and Password are the digest credentials. To keep the password out of the
+	// config, it can be omitted here and set in the TOPICCTL_ZK_PASSWORD environment
+	// variable instead.
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+}
+
+// ZKTLSConfig contains the TLS settings used for zookeeper connections.
+type ZKTLSConfig struct {
+	// CACertPath is the path to a PEM file with the CA certificates used to verify the
+	// zookeeper servers. If unset, the system roots are used.
+	CACertPath string `json:"caCertPath,omitempty"`
+
+	// CertPath and KeyPath are the paths to the PEM-encoded client certificate and key. These
+	// are only needed if the servers require client authentication.
+	CertPath string `json:"certPath,omitempty"`
+	KeyPath  string `json:"keyPath,omitempty"`
+
+	// ServerName overrides the host name used to verify the server certificates.
+	ServerName string `json:"serverName,omitempty"`
+
+	// SkipVerify disables the verification of the server certificates. It should only be
+	// used for testing.
+	SkipVerify bool `json:"skipVerify,omitempty"`
+}
+
+// TLSConfig loads the certificates referenced in the current ZKTLSConfig and returns the
+// resulting TLS config.
+func (t ZKTLSConfig) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.SkipVerify,
+	}
+
+	if t.CACertPath != "" {
+		caCerts, err := ioutil.ReadFile(t.CACertPath)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCerts) {
+			return nil, fmt.Errorf("No valid certificates found in %s", t.CACertPath)
+		}
+	}
+
+	if t.CertPath != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertPath, t.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
 }
 
 // Validate evaluates whether the cluster config is valid.
@@ -93,11 +369,127 @@ func (c ClusterConfig) Validate() error {
 	if len(c.Spec.ZKAddrs) == 0 {
 		err = multierror.Append(err, errors.New("At least one zookeeper address must be set"))
 	}
+	if c.Spec.ZKSessionTimeoutSeconds < 0 || c.Spec.ZKConnectTimeoutSeconds < 0 {
+		err = multierror.Append(err, errors.New("Zookeeper timeouts cannot be negative"))
+	}
+	if c.Spec.ZKAuth != nil {
+		switch c.Spec.ZKAuth.Mechanism {
+		case "", ZKAuthMechanismDigest:
+			if c.Spec.ZKAuth.Username == "" {
+				err = multierror.Append(
+					err,
+					errors.New("ZKAuth username must be set with the digest mechanism"),
+				)
+			}
+		case ZKAuthMechanismSASL:
+			err = multierror.Append(
+				err,
+				errors.New("SASL zookeeper auth is not supported; use the digest mechanism instead"),
+			)
+		default:
+			err = multierror.Append(
+				err,
+				fmt.Errorf(
+					"ZKAuth mechanism must be in %+v",
+					[]ZKAuthMechanism{ZKAuthMechanismDigest, ZKAuthMechanismSASL},
+				),
+			)
+		}
+	} else if c.Spec.ZKSetACL {
+		err = multierror.Append(err, errors.New("ZKAuth must be set if ZKSetACL is true"))
+	}
+	if c.Spec.ZKTLS != nil && (c.Spec.ZKTLS.CertPath == "") != (c.Spec.ZKTLS.KeyPath == "") {
+		err = multierror.Append(
+			err,
+			errors.New("ZKTLS certPath and keyPath must be set together"),
+		)
+	}
 	if c.Spec.VersionMajor != KafkaVersionMajor010 &&
 		c.Spec.VersionMajor != KafkaVersionMajor2 {
 		multierror.Append(err, errors.New("MajorVersion must be v0.10 or v2"))
 	}
 
+	switch c.Spec.LockBackend {
+	case "", LockBackendZK:
+		if c.Spec.DynamoDBLockTable != "" {
+			err = multierror.Append(
+				err,
+				errors.New("DynamoDBLockTable can only be set with the dynamodb lock backend"),
+			)
+		}
+	case LockBackendDynamoDB:
+		if c.Spec.DynamoDBLockTable == "" {
+			err = multierror.Append(
+				err,
+				errors.New("DynamoDBLockTable must be set with the dynamodb lock backend"),
+			)
+		}
+	default:
+		err = multierror.Append(
+			err,
+			fmt.Errorf(
+				"LockBackend must be in %+v",
+				[]LockBackend{LockBackendZK, LockBackendDynamoDB},
+			),
+		)
+	}
+
+	seenGoals := map[RebalanceGoal]struct{}{}
+	for _, goal := range c.Spec.RebalanceGoals {
+		if _, ok := seenGoals[goal]; ok {
+			err = multierror.Append(err, fmt.Errorf("Rebalance goal %s is repeated", goal))
+		}
+		seenGoals[goal] = struct{}{}
+
+		goalIndex := -1
+		for g, validGoal := range allRebalanceGoals {
+			if validGoal == goal {
+				goalIndex = g
+				break
+			}
+		}
+		if goalIndex == -1 {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("RebalanceGoals must be in %+v", allRebalanceGoals),
+			)
+		}
+	}
+
+	for _, hook := range c.Spec.Hooks {
+		if hookErr := hook.Validate(); hookErr != nil {
+			err = multierror.Append(err, hookErr)
+		}
+	}
+
+	for _, window := range c.Spec.MaintenanceWindows {
+		if windowErr := window.Validate(); windowErr != nil {
+			err = multierror.Append(err, windowErr)
+		}
+	}
+
+	if tiersErr := validateRetentionTiers(c.Spec.RetentionTiers); tiersErr != nil {
+		err = multierror.Append(err, tiersErr)
+	}
+
+	for _, rule := range c.Spec.Owners {
+		if ruleErr := rule.Validate(); ruleErr != nil {
+			err = multierror.Append(err, ruleErr)
+		}
+	}
+
+	if c.Spec.PasswordPolicy != nil {
+		if policyErr := c.Spec.PasswordPolicy.Validate(); policyErr != nil {
+			err = multierror.Append(err, policyErr)
+		}
+	}
+
+	if c.Spec.TopicDefaults != nil {
+		if defaultsErr := c.Spec.TopicDefaults.Validate(); defaultsErr != nil {
+			err = multierror.Append(err, defaultsErr)
+		}
+	}
+
 	return err
 }
 
@@ -107,6 +499,11 @@ func (c ClusterConfig) NewAdminClient(
 	sess *session.Session,
 	readOnly bool,
 ) (*admin.Client, error) {
+	zkOptions, err := c.ZKOptions()
+	if err != nil {
+		return nil, err
+	}
+
 	return admin.NewClient(
 		ctx,
 		admin.ClientConfig{
@@ -116,6 +513,78 @@ func (c ClusterConfig) NewAdminClient(
 			ExpectedClusterID: c.Spec.ClusterID,
 			Sess:              sess,
 			ReadOnly:          readOnly,
+			ZKOptions:         zkOptions,
+			QPS:               c.Spec.AdminQPS,
+			BrokerRetries: admin.RetryConfig{
+				MaxRetries: c.Spec.BrokerMaxRetries,
+			},
 		},
 	)
 }
+
+// ZKOptions returns the zookeeper connection, retry, and security settings for this cluster.
+func (c ClusterConfig) ZKOptions() (zk.ClientOptions, error) {
+	options := zk.ClientOptions{
+		SessionTimeout: time.Duration(c.Spec.ZKSessionTimeoutSeconds) * time.Second,
+		ConnectTimeout: time.Duration(c.Spec.ZKConnectTimeoutSeconds) * time.Second,
+		MaxRetries:     c.Spec.ZKMaxRetries,
+		ObserverAddrs:  c.Spec.ZKObserverAddrs,
+	}
+
+	if c.Spec.ZKAuth != nil {
+		password := c.Spec.ZKAuth.Password
+		if password == "" {
+			password = os.Getenv(ZKPasswordEnvVar)
+		}
+
+		options.AuthScheme = string(ZKAuthMechanismDigest)
+		options.AuthCredentials = []byte(
+			fmt.Sprintf("%s:%s", c.Spec.ZKAuth.Username, password),
+		)
+
+		if c.Spec.ZKSetACL {
+			// The "auth" scheme expands to the identities of the creating connection
+			options.ACL = append(
+				szk.AuthACL(szk.PermAll),
+				szk.WorldACL(szk.PermRead)...,
+			)
+		}
+	}
+
+	if c.Spec.ZKTLS != nil {
+		var err error
+		options.TLSConfig, err = c.Spec.ZKTLS.TLSConfig()
+		if err != nil {
+			return options, fmt.Errorf("Error loading zookeeper TLS config: %+v", err)
+		}
+	}
+
+	return options, nil
+}
+
+// NewLocker returns the locker used for apply locks in this cluster. The admin client is
+// used for the zk backend; for the dynamodb one, a new AWS session is created if the
+// argument one is nil.
+func (c ClusterConfig) NewLocker(
+	adminClient *admin.Client,
+	sess *session.Session,
+) (locks.Locker, error) {
+	switch c.Spec.LockBackend {
+	case "", LockBackendZK:
+		return adminClient, nil
+	case LockBackendDynamoDB:
+		if sess == nil {
+			var err error
+			sess, err = session.NewSession()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return locks.NewDynamoDBLocker(
+			dynamodb.New(sess),
+			c.Spec.DynamoDBLockTable,
+		), nil
+	default:
+		return nil, fmt.Errorf("Unrecognized lock backend: %s", c.Spec.LockBackend)
+	}
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"os"
 	"testing"
+	"time"
 
+	szk "github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClusterValidate(t *testing.T) {
@@ -62,6 +66,24 @@ func TestClusterValidate(t *testing.T) {
 			},
 			expError: true,
 		},
+		{
+			description: "negative zk timeout",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs:          []string{"broker-addr"},
+					ZKAddrs:                 []string{"zk-addr"},
+					ZKSessionTimeoutSeconds: -1,
+					VersionMajor:            "v2",
+				},
+			},
+			expError: true,
+		},
 		{
 			description: "missing zk addresses",
 			clusterConfig: ClusterConfig{
@@ -78,6 +100,312 @@ func TestClusterValidate(t *testing.T) {
 			},
 			expError: true,
 		},
+		{
+			description: "valid rebalance goals",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					RebalanceGoals: []RebalanceGoal{
+						RebalanceGoalRackDistribution,
+						RebalanceGoalLeaderBalance,
+					},
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "invalid rebalance goals",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					RebalanceGoals: []RebalanceGoal{"cpu-balance"},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "repeated rebalance goals",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					RebalanceGoals: []RebalanceGoal{
+						RebalanceGoalLeaderBalance,
+						RebalanceGoalLeaderBalance,
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "valid dynamodb lock backend",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs:    []string{"broker-addr"},
+					ZKAddrs:           []string{"zk-addr"},
+					VersionMajor:      "v2",
+					LockBackend:       LockBackendDynamoDB,
+					DynamoDBLockTable: "topicctl-locks",
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "dynamodb lock backend without table",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					LockBackend:    LockBackendDynamoDB,
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "lock table without dynamodb lock backend",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs:    []string{"broker-addr"},
+					ZKAddrs:           []string{"zk-addr"},
+					VersionMajor:      "v2",
+					DynamoDBLockTable: "topicctl-locks",
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "invalid lock backend",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					LockBackend:    "etcd",
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "valid zk auth",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					ZKAuth: &ZKAuthConfig{
+						Username: "topicctl",
+						Password: "secret",
+					},
+					ZKSetACL: true,
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "zk auth without username",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					ZKAuth: &ZKAuthConfig{
+						Mechanism: ZKAuthMechanismDigest,
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "sasl zk auth",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					ZKAuth: &ZKAuthConfig{
+						Mechanism: ZKAuthMechanismSASL,
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "zk acls without auth",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					ZKSetACL:       true,
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "zk tls cert without key",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					ZKTLS: &ZKTLSConfig{
+						CertPath: "client.pem",
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "valid topic defaults",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					TopicDefaults: &TopicDefaults{
+						ReplicationFactor: 3,
+						RetentionMinutes:  1440,
+						Settings: TopicSettings{
+							"cleanup.policy": "delete",
+						},
+						PlacementConfig: TopicPlacementDefaults{
+							Strategy: PlacementStrategyBalancedLeaders,
+							Picker:   PickerMethodLowestIndex,
+						},
+					},
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "topic defaults with static placement",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					TopicDefaults: &TopicDefaults{
+						PlacementConfig: TopicPlacementDefaults{
+							Strategy: PlacementStrategyStatic,
+						},
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "topic defaults with retention set twice",
+			clusterConfig: ClusterConfig{
+				Meta: ClusterMeta{
+					Name:        "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "test-description",
+				},
+				Spec: ClusterSpec{
+					BootstrapAddrs: []string{"broker-addr"},
+					ZKAddrs:        []string{"zk-addr"},
+					VersionMajor:   "v2",
+					TopicDefaults: &TopicDefaults{
+						RetentionMinutes: 1440,
+						Settings: TopicSettings{
+							"retention.ms": 3600000,
+						},
+					},
+				},
+			},
+			expError: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -89,3 +417,61 @@ func TestClusterValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestClusterZKOptions(t *testing.T) {
+	clusterConfig := ClusterConfig{
+		Spec: ClusterSpec{
+			ZKAddrs:                 []string{"zk-addr"},
+			ZKSessionTimeoutSeconds: 30,
+			ZKMaxRetries:            5,
+		},
+	}
+
+	options, err := clusterConfig.ZKOptions()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, options.SessionTimeout)
+	assert.Equal(t, 5, options.MaxRetries)
+	assert.Equal(t, "", options.AuthScheme)
+	assert.Nil(t, options.ACL)
+
+	clusterConfig.Spec.ZKAuth = &ZKAuthConfig{
+		Username: "topicctl",
+		Password: "secret",
+	}
+	clusterConfig.Spec.ZKSetACL = true
+
+	options, err = clusterConfig.ZKOptions()
+	require.NoError(t, err)
+	assert.Equal(t, "digest", options.AuthScheme)
+	assert.Equal(t, []byte("topicctl:secret"), options.AuthCredentials)
+	assert.Equal(
+		t,
+		[]szk.ACL{
+			{Perms: szk.PermAll, Scheme: "auth", ID: ""},
+			{Perms: szk.PermRead, Scheme: "world", ID: "anyone"},
+		},
+		options.ACL,
+	)
+
+	os.Setenv(ZKPasswordEnvVar, "env-secret")
+	defer os.Unsetenv(ZKPasswordEnvVar)
+	clusterConfig.Spec.ZKAuth.Password = ""
+
+	options, err = clusterConfig.ZKOptions()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("topicctl:env-secret"), options.AuthCredentials)
+	assert.Nil(t, options.TLSConfig)
+
+	clusterConfig.Spec.ZKTLS = &ZKTLSConfig{
+		ServerName: "zk.example.com",
+	}
+	options, err = clusterConfig.ZKOptions()
+	require.NoError(t, err)
+	require.NotNil(t, options.TLSConfig)
+	assert.Equal(t, "zk.example.com", options.TLSConfig.ServerName)
+	assert.Nil(t, options.TLSConfig.RootCAs)
+
+	clusterConfig.Spec.ZKTLS.CACertPath = "non-existent-ca.pem"
+	_, err = clusterConfig.ZKOptions()
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// ConnectorKind is the value of the kind field in connector configs. Topic configs don't
+// set a kind.
+const ConnectorKind = "connector"
+
+// ConnectorConfig represents the desired configuration of a Kafka Connect connector.
+type ConnectorConfig struct {
+	Kind string        `json:"kind"`
+	Meta ConnectorMeta `json:"meta"`
+	Spec ConnectorSpec `json:"spec"`
+}
+
+// ConnectorMeta stores the (mostly immutable) metadata associated with a connector.
+type ConnectorMeta struct {
+	Name        string `json:"name"`
+	Cluster     string `json:"cluster"`
+	Region      string `json:"region"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+}
+
+// ConnectorSpec stores the (mutable) specification for a connector.
+type ConnectorSpec struct {
+	// Config is the full connector config as passed to the Connect REST API. Values are
+	// converted to strings before being sent.
+	Config map[string]interface{} `json:"config"`
+}
+
+// Validate evaluates whether the connector config is valid.
+func (c ConnectorConfig) Validate() error {
+	var err error
+
+	if c.Kind != ConnectorKind {
+		err = multierror.Append(err, fmt.Errorf("Kind must be %s", ConnectorKind))
+	}
+	if c.Meta.Name == "" {
+		err = multierror.Append(err, errors.New("Name must be set"))
+	}
+	if c.Meta.Cluster == "" {
+		err = multierror.Append(err, errors.New("Cluster must be set"))
+	}
+	if c.Meta.Region == "" {
+		err = multierror.Append(err, errors.New("Region must be set"))
+	}
+	if c.Meta.Environment == "" {
+		err = multierror.Append(err, errors.New("Environment must be set"))
+	}
+	if _, ok := c.Spec.Config["connector.class"]; !ok {
+		err = multierror.Append(err, errors.New("connector.class must be set in config"))
+	}
+
+	configMap, configErr := c.ToConnectConfig()
+	if configErr != nil {
+		err = multierror.Append(err, configErr)
+	} else if name, ok := c.Spec.Config["name"]; ok && configMap["name"] != c.Meta.Name {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Name in config (%+v) does not match name in meta", name),
+		)
+	}
+
+	return err
+}
+
+// ToConnectConfig converts the config in the spec to a string map that can be sent to the
+// Connect REST API.
+func (c ConnectorConfig) ToConnectConfig() (map[string]string, error) {
+	configMap := map[string]string{}
+
+	for key, value := range c.Spec.Config {
+		strValue, err := interfaceToString(value)
+		if err != nil {
+			return nil, fmt.Errorf("Error converting value for key %s: %+v", key, err)
+		}
+		configMap[key] = strValue
+	}
+
+	return configMap, nil
+}
+
+// CheckConnectorConsistency verifies that the argument connector config is consistent
+// with the argument cluster config.
+func CheckConnectorConsistency(
+	connectorConfig ConnectorConfig,
+	clusterConfig ClusterConfig,
+) error {
+	var err error
+
+	if connectorConfig.Meta.Cluster != clusterConfig.Meta.Name {
+		err = multierror.Append(
+			err,
+			errors.New("Connector cluster name does not match name in cluster config"),
+		)
+	}
+	if connectorConfig.Meta.Environment != clusterConfig.Meta.Environment {
+		err = multierror.Append(
+			err,
+			errors.New("Connector environment does not match cluster environment"),
+		)
+	}
+	if connectorConfig.Meta.Region != clusterConfig.Meta.Region {
+		err = multierror.Append(
+			err,
+			errors.New("Connector region does not match cluster region"),
+		)
+	}
+	if clusterConfig.Spec.ConnectURL == "" {
+		err = multierror.Append(
+			err,
+			errors.New("Cluster config does not have a connect URL"),
+		)
+	}
+
+	return err
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/segmentio/topicctl/pkg/admin"
+)
+
+const (
+	// KafkaTopicAPIVersion is the API version of the Strimzi KafkaTopic custom resources
+	// generated from topic configs.
+	KafkaTopicAPIVersion = "kafka.strimzi.io/v1beta2"
+
+	// KafkaTopicKind is the kind of the Strimzi KafkaTopic custom resource.
+	KafkaTopicKind = "KafkaTopic"
+
+	// KafkaTopicClusterLabel is the label that the Strimzi operator uses to find the
+	// cluster that a topic belongs to.
+	KafkaTopicClusterLabel = "strimzi.io/cluster"
+
+	// KafkaTopicConfigAnnotation is the annotation that stores the full topic config in
+	// each custom resource. This preserves the topicctl-specific fields, e.g. the
+	// placement settings, so that resources can be converted back into configs
+	// without losing information.
+	KafkaTopicConfigAnnotation = "topicctl.segment.com/config"
+
+	kafkaTopicAPIGroup = "kafka.strimzi.io/"
+)
+
+var invalidResourceNameRegexp = regexp.MustCompile("[^a-z0-9.-]+")
+
+// KafkaTopicResource is a Strimzi KafkaTopic custom resource.
+type KafkaTopicResource struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   KafkaTopicResourceMeta `json:"metadata"`
+	Spec       KafkaTopicResourceSpec `json:"spec"`
+}
+
+// KafkaTopicResourceMeta is the subset of the Kubernetes object metadata that's used in
+// KafkaTopic resources.
+type KafkaTopicResourceMeta struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// KafkaTopicResourceSpec is the spec of a KafkaTopic resource. TopicName is only set if
+// the topic name isn't a valid Kubernetes resource name.
+type KafkaTopicResourceSpec struct {
+	TopicName  string                 `json:"topicName,omitempty"`
+	Partitions int                    `json:"partitions"`
+	Replicas   int                    `json:"replicas"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+}
+
+// ToKafkaTopicResource converts the current TopicConfig to a KafkaTopic custom resource in
+// the argument namespace. If strimziCluster is empty, the cluster name in the config is used
+// for the cluster label.
+func (t TopicConfig) ToKafkaTopicResource(
+	namespace string,
+	strimziCluster string,
+) (KafkaTopicResource, error) {
+	if strimziCluster == "" {
+		strimziCluster = t.Meta.Cluster
+	}
+
+	newTopicConfig, err := t.ToNewTopicConfig()
+	if err != nil {
+		return KafkaTopicResource{}, err
+	}
+
+	configAnnotation, err := json.Marshal(t)
+	if err != nil {
+		return KafkaTopicResource{}, err
+	}
+
+	resource := KafkaTopicResource{
+		APIVersion: KafkaTopicAPIVersion,
+		Kind:       KafkaTopicKind,
+		Metadata: KafkaTopicResourceMeta{
+			Name:      resourceName(t.Meta.Name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				KafkaTopicClusterLabel: strimziCluster,
+			},
+			Annotations: map[string]string{
+				KafkaTopicConfigAnnotation: string(configAnnotation),
+			},
+		},
+		Spec: KafkaTopicResourceSpec{
+			Partitions: t.Spec.Partitions,
+			Replicas:   t.Spec.ReplicationFactor,
+		},
+	}
+
+	if resource.Metadata.Name != t.Meta.Name {
+		resource.Spec.TopicName = t.Meta.Name
+	}
+
+	if len(newTopicConfig.ConfigEntries) > 0 {
+		resource.Spec.Config = map[string]interface{}{}
+		for _, entry := range newTopicConfig.ConfigEntries {
+			resource.Spec.Config[entry.ConfigName] = entry.ConfigValue
+		}
+	}
+
+	return resource, nil
+}
+
+// ToYAML converts the current KafkaTopicResource to a YAML string.
+func (r KafkaTopicResource) ToYAML() (string, error) {
+	outBytes, err := yaml.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return string(outBytes), nil
+}
+
+// TopicName returns the name of the Kafka topic for this resource.
+func (r KafkaTopicResource) TopicName() string {
+	if r.Spec.TopicName != "" {
+		return r.Spec.TopicName
+	}
+	return r.Metadata.Name
+}
+
+// TopicConfigFromKafkaTopicResource converts a KafkaTopic custom resource back into a
+// TopicConfig. The config stored in the resource annotation, if any, is used as the
+// base, and the partitions, replicas, and settings are then updated from the resource spec
+// in case these were changed outside of topicctl.
+//
+// If the resource doesn't have an annotation, e.g. because it wasn't generated by topicctl,
+// the cluster, region, and environment are taken from the argument cluster config and the
+// "any" placement strategy is used.
+func TopicConfigFromKafkaTopicResource(
+	resource KafkaTopicResource,
+	clusterConfig ClusterConfig,
+) (TopicConfig, error) {
+	if resource.Kind != KafkaTopicKind ||
+		!strings.HasPrefix(resource.APIVersion, kafkaTopicAPIGroup) {
+		return TopicConfig{}, fmt.Errorf(
+			"Resource %s is not a KafkaTopic (apiVersion: %s, kind: %s)",
+			resource.Metadata.Name,
+			resource.APIVersion,
+			resource.Kind,
+		)
+	}
+
+	var topicConfig TopicConfig
+
+	if configAnnotation, ok := resource.Metadata.Annotations[KafkaTopicConfigAnnotation]; ok {
+		if err := json.Unmarshal([]byte(configAnnotation), &topicConfig); err != nil {
+			return TopicConfig{}, fmt.Errorf(
+				"Error parsing %s annotation in resource %s: %+v",
+				KafkaTopicConfigAnnotation,
+				resource.Metadata.Name,
+				err,
+			)
+		}
+	} else {
+		topicConfig = TopicConfig{
+			Meta: TopicMeta{
+				Cluster:     clusterConfig.Meta.Name,
+				Region:      clusterConfig.Meta.Region,
+				Environment: clusterConfig.Meta.Environment,
+				Description: "Imported from KafkaTopic resource via topicctl",
+			},
+			Spec: TopicSpec{
+				PlacementConfig: TopicPlacementConfig{
+					Strategy: PlacementStrategyAny,
+				},
+			},
+		}
+	}
+
+	topicConfig.Meta.Name = resource.TopicName()
+	topicConfig.Spec.Partitions = resource.Spec.Partitions
+	if resource.Spec.Replicas > 0 {
+		topicConfig.Spec.ReplicationFactor = resource.Spec.Replicas
+	}
+
+	settings, retentionMinutes, err := settingsFromResourceConfig(
+		resource.Spec.Config,
+		topicConfig,
+	)
+	if err != nil {
+		return TopicConfig{}, err
+	}
+	topicConfig.Spec.Settings = settings
+	topicConfig.Spec.RetentionMinutes = retentionMinutes
+
+	return topicConfig, nil
+}
+
+// LoadKafkaTopicResourcesFile loads the KafkaTopic resources in a YAML file.
+func LoadKafkaTopicResourcesFile(path string) ([]KafkaTopicResource, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return LoadKafkaTopicResourcesBytes(contents)
+}
+
+// LoadKafkaTopicResourcesBytes loads the KafkaTopic resources in a (possibly multi-document)
+// YAML file. Documents with other kinds are skipped.
+func LoadKafkaTopicResourcesBytes(contents []byte) ([]KafkaTopicResource, error) {
+	resources := []KafkaTopicResource{}
+
+	for _, document := range splitYAMLDocuments(contents) {
+		kindObj := struct {
+			Kind string `json:"kind"`
+		}{}
+		if err := yaml.Unmarshal(document, &kindObj); err != nil {
+			return nil, err
+		}
+		if kindObj.Kind != KafkaTopicKind {
+			continue
+		}
+
+		resource := KafkaTopicResource{}
+		if err := yaml.Unmarshal(document, &resource); err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// settingsFromResourceConfig converts the config in a KafkaTopic resource into topicctl
+// settings. Values that match the ones in the base topic config keep their original
+// types, and retention is expressed in minutes if the base config does so.
+func settingsFromResourceConfig(
+	resourceConfig map[string]interface{},
+	baseConfig TopicConfig,
+) (TopicSettings, int, error) {
+	settings := TopicSettings{}
+	retentionMinutes := 0
+
+	for key, value := range resourceConfig {
+		strValue, err := interfaceToString(value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error converting value for key %s: %+v", key, err)
+		}
+
+		if key == admin.RetentionKey && baseConfig.Spec.RetentionMinutes > 0 &&
+			strValue == fmt.Sprintf("%d", baseConfig.Spec.RetentionMinutes*60*1000) {
+			retentionMinutes = baseConfig.Spec.RetentionMinutes
+			continue
+		}
+
+		if baseValue, ok := baseConfig.Spec.Settings[key]; ok {
+			baseStrValue, err := interfaceToString(baseValue)
+			if err == nil && baseStrValue == strValue {
+				settings[key] = baseValue
+				continue
+			}
+		}
+
+		settings[key] = strValue
+	}
+
+	if len(settings) == 0 {
+		return nil, retentionMinutes, nil
+	}
+
+	return settings, retentionMinutes, nil
+}
+
+// resourceName converts a topic name into a valid Kubernetes resource name.
+func resourceName(topicName string) string {
+	name := invalidResourceNameRegexp.ReplaceAllString(strings.ToLower(topicName), "-")
+	name = strings.Trim(name, ".-")
+	if len(name) > 253 {
+		name = strings.Trim(name[:253], ".-")
+	}
+	return name
+}
+
+func splitYAMLDocuments(contents []byte) [][]byte {
+	documents := [][]byte{}
+
+	for _, document := range bytes.Split(contents, []byte("\n---")) {
+		// Handle the case where the first line is a separator
+		document = bytes.TrimPrefix(bytes.TrimSpace(document), []byte("---"))
+		if len(bytes.TrimSpace(document)) > 0 {
+			documents = append(documents, document)
+		}
+	}
+
+	return documents
+}
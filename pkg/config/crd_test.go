@@ -0,0 +1,145 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaTopicResourceRoundTrip(t *testing.T) {
+	topicConfig, err := LoadTopicFile("testdata/test-cluster/topics/topic-test.yaml")
+	require.Nil(t, err)
+
+	resource, err := topicConfig.ToKafkaTopicResource("kafka", "")
+	require.Nil(t, err)
+
+	assert.Equal(t, KafkaTopicAPIVersion, resource.APIVersion)
+	assert.Equal(t, KafkaTopicKind, resource.Kind)
+	assert.Equal(t, "topic-test", resource.Metadata.Name)
+	assert.Equal(t, "kafka", resource.Metadata.Namespace)
+	assert.Equal(
+		t,
+		map[string]string{KafkaTopicClusterLabel: "test-cluster"},
+		resource.Metadata.Labels,
+	)
+	assert.Equal(t, "", resource.Spec.TopicName)
+	assert.Equal(t, 9, resource.Spec.Partitions)
+	assert.Equal(t, 2, resource.Spec.Replicas)
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"cleanup.policy": "compact",
+			"follower.replication.throttled.replicas": "1:3,4:5",
+			"max.compaction.lag.ms":                   "12345",
+			"retention.ms":                            "6000000",
+		},
+		resource.Spec.Config,
+	)
+
+	yamlStr, err := resource.ToYAML()
+	require.Nil(t, err)
+	resources, err := LoadKafkaTopicResourcesBytes([]byte("---\n" + yamlStr))
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+
+	importedConfig, err := TopicConfigFromKafkaTopicResource(resources[0], ClusterConfig{})
+	require.Nil(t, err)
+	assert.Equal(t, topicConfig.Meta, importedConfig.Meta)
+	assert.Equal(t, topicConfig.Spec.PlacementConfig, importedConfig.Spec.PlacementConfig)
+	assert.Equal(t, 100, importedConfig.Spec.RetentionMinutes)
+	assert.Equal(t, 9, importedConfig.Spec.Partitions)
+
+	importedYAML, err := importedConfig.ToYAML()
+	require.Nil(t, err)
+	originalYAML, err := topicConfig.ToYAML()
+	require.Nil(t, err)
+	assert.Equal(t, originalYAML, importedYAML)
+
+	// Changes made to the resource spec take precedence over the annotation
+	resource.Spec.Partitions = 12
+	resource.Spec.Config["retention.ms"] = 3600000
+	resource.Spec.Config["cleanup.policy"] = "delete"
+	delete(resource.Spec.Config, "max.compaction.lag.ms")
+
+	importedConfig, err = TopicConfigFromKafkaTopicResource(resource, ClusterConfig{})
+	require.Nil(t, err)
+	assert.Equal(t, 12, importedConfig.Spec.Partitions)
+	assert.Equal(t, 0, importedConfig.Spec.RetentionMinutes)
+	assert.Equal(
+		t,
+		TopicSettings{
+			"cleanup.policy": "delete",
+			"follower.replication.throttled.replicas": []interface{}{"1:3", "4:5"},
+			"retention.ms": "3600000",
+		},
+		importedConfig.Spec.Settings,
+	)
+}
+
+func TestKafkaTopicResourceImport(t *testing.T) {
+	clusterConfig, err := LoadClusterFile("testdata/test-cluster/cluster.yaml")
+	require.Nil(t, err)
+
+	resources, err := LoadKafkaTopicResourcesBytes(
+		[]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+---
+apiVersion: kafka.strimzi.io/v1beta2
+kind: KafkaTopic
+metadata:
+  name: orders-v1
+  labels:
+    strimzi.io/cluster: my-cluster
+spec:
+  topicName: Orders_V1
+  partitions: 6
+  replicas: 3
+  config:
+    cleanup.policy: compact
+    segment.bytes: 1073741824
+`),
+	)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(resources))
+
+	topicConfig, err := TopicConfigFromKafkaTopicResource(resources[0], clusterConfig)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		TopicMeta{
+			Name:        "Orders_V1",
+			Cluster:     clusterConfig.Meta.Name,
+			Region:      clusterConfig.Meta.Region,
+			Environment: clusterConfig.Meta.Environment,
+			Description: "Imported from KafkaTopic resource via topicctl",
+		},
+		topicConfig.Meta,
+	)
+	assert.Equal(t, 6, topicConfig.Spec.Partitions)
+	assert.Equal(t, 3, topicConfig.Spec.ReplicationFactor)
+	assert.Equal(t, PlacementStrategyAny, topicConfig.Spec.PlacementConfig.Strategy)
+	assert.Equal(
+		t,
+		TopicSettings{
+			"cleanup.policy": "compact",
+			"segment.bytes":  "1073741824",
+		},
+		topicConfig.Spec.Settings,
+	)
+
+	_, err = TopicConfigFromKafkaTopicResource(
+		KafkaTopicResource{APIVersion: "v1", Kind: "ConfigMap"},
+		clusterConfig,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestResourceName(t *testing.T) {
+	assert.Equal(t, "topic-test", resourceName("topic-test"))
+	assert.Equal(t, "orders-v1", resourceName("Orders_V1"))
+	assert.Equal(t, "my.topic", resourceName("_my.topic"))
+}
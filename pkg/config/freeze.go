@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ParseFreezeExpiry parses a freeze expiry, which can be either an RFC3339 time or a date.
+// Dates are interpreted as midnight UTC at the start of the day.
+func ParseFreezeExpiry(value string) (time.Time, error) {
+	expiry, err := parseTimeOrDate(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid freeze expiry %s: %+v", value, err)
+	}
+	return expiry, nil
+}
+
+// FrozenAt returns whether the topic metadata freezes the topic at the argument time.
+func (m TopicMeta) FrozenAt(now time.Time) (bool, error) {
+	if !m.Frozen {
+		return false, nil
+	}
+	if m.FreezeExpiry == "" {
+		return true, nil
+	}
+
+	expiry, err := ParseFreezeExpiry(m.FreezeExpiry)
+	if err != nil {
+		return false, err
+	}
+	return now.Before(expiry), nil
+}
+
+// parseTimeOrDate parses either an RFC3339 time or a YYYY-MM-DD date. Dates are interpreted
+// as midnight UTC at the start of the day.
+func parseTimeOrDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, nil
+	}
+	return time.Time{}, errors.New("must be an RFC3339 time or a YYYY-MM-DD date")
+}
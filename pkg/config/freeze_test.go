@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicMetaFrozenAt(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		description string
+		meta        TopicMeta
+		expected    bool
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "not frozen",
+			meta: TopicMeta{
+				FreezeExpiry: "2021-03-02",
+			},
+			expected: false,
+		},
+		{
+			description: "frozen without expiry",
+			meta: TopicMeta{
+				Frozen: true,
+			},
+			expected: true,
+		},
+		{
+			description: "frozen until date",
+			meta: TopicMeta{
+				Frozen:       true,
+				FreezeExpiry: "2021-03-02",
+			},
+			expected: true,
+		},
+		{
+			description: "frozen until past time",
+			meta: TopicMeta{
+				Frozen:       true,
+				FreezeExpiry: "2021-03-01T04:00:00-08:00",
+			},
+			expected: false,
+		},
+		{
+			description: "frozen until future time",
+			meta: TopicMeta{
+				Frozen:       true,
+				FreezeExpiry: "2021-03-01T05:00:00-08:00",
+			},
+			expected: true,
+		},
+		{
+			description: "invalid expiry",
+			meta: TopicMeta{
+				Frozen:       true,
+				FreezeExpiry: "next tuesday",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		frozen, err := testCase.meta.FrozenAt(now)
+		if testCase.expectedErr {
+			assert.Error(t, err, testCase.description)
+			continue
+		}
+		require.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.expected, frozen, testCase.description)
+	}
+}
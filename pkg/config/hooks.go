@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// HookPhase is a string type that indicates whether a hook runs before or after a change.
+type HookPhase string
+
+const (
+	// HookPhasePre hooks run before the change is made. If one fails, the apply stops.
+	HookPhasePre HookPhase = "pre"
+
+	// HookPhasePost hooks run after the change is made.
+	HookPhasePost HookPhase = "post"
+)
+
+var allHookPhases = []HookPhase{HookPhasePre, HookPhasePost}
+
+// HookEvent is a string type that indicates the kind of change that triggers a hook.
+type HookEvent string
+
+const (
+	// HookEventCreate is triggered when a topic is created.
+	HookEventCreate HookEvent = "create"
+
+	// HookEventMigrate is triggered when the partitions of an existing topic are added to or
+	// moved between brokers.
+	HookEventMigrate HookEvent = "migrate"
+
+	// HookEventDelete is triggered when a retiring topic is deleted.
+	HookEventDelete HookEvent = "delete"
+)
+
+var allHookEvents = []HookEvent{HookEventCreate, HookEventMigrate, HookEventDelete}
+
+// HookConfig describes a command or webhook that's run before or after a topic is changed
+// by apply, e.g. to notify the team that owns it or to pause a consumer deployment.
+type HookConfig struct {
+	// Name is used to identify the hook in logs. If unset, the command or URL is used.
+	Name string `json:"name,omitempty"`
+
+	Phase HookPhase `json:"phase"`
+
+	// Events are the changes that trigger the hook. If empty, it's triggered by all of them.
+	Events []HookEvent `json:"events,omitempty"`
+
+	// Exactly one of Command and URL must be set. Commands are run with the details of the
+	// change in TOPICCTL_HOOK_* environment variables; webhooks are sent the same details as
+	// a JSON POST body.
+	Command []string `json:"command,omitempty"`
+	URL     string   `json:"url,omitempty"`
+
+	// TimeoutSeconds is the maximum amount of time that the hook can run for. If unset, a
+	// default of 60 seconds is used.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// ContinueOnError indicates that the apply should proceed even if the hook fails.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+}
+
+// DisplayName returns the name of the hook for logging.
+func (h HookConfig) DisplayName() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	if h.URL != "" {
+		return h.URL
+	}
+	if len(h.Command) > 0 {
+		return h.Command[0]
+	}
+	return "unnamed hook"
+}
+
+// Matches returns whether the hook should be run for the argument phase and event.
+func (h HookConfig) Matches(phase HookPhase, event HookEvent) bool {
+	if h.Phase != phase {
+		return false
+	}
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, hookEvent := range h.Events {
+		if hookEvent == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate evaluates whether the hook config is valid.
+func (h HookConfig) Validate() error {
+	var err error
+
+	if h.Phase != HookPhasePre && h.Phase != HookPhasePost {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Hook %s phase must be in %+v", h.DisplayName(), allHookPhases),
+		)
+	}
+	for _, event := range h.Events {
+		if event != HookEventCreate && event != HookEventMigrate && event != HookEventDelete {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Hook %s events must be in %+v", h.DisplayName(), allHookEvents),
+			)
+		}
+	}
+
+	if (len(h.Command) == 0) == (h.URL == "") {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Exactly one of command or url must be set for hook %s", h.DisplayName()),
+		)
+	}
+	if h.URL != "" {
+		parsedURL, parseErr := url.Parse(h.URL)
+		if parseErr != nil ||
+			(parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Hook %s url must be an http or https URL", h.DisplayName()),
+			)
+		}
+	}
+	if h.TimeoutSeconds < 0 {
+		err = multierror.Append(err, errors.New("Hook timeoutSeconds cannot be negative"))
+	}
+
+	return err
+}
+
+// MatchingHooks returns the hooks in the argument cluster and topic configs that should be
+// run for the argument phase and event. Cluster hooks are returned first.
+func MatchingHooks(
+	clusterConfig ClusterConfig,
+	topicConfig TopicConfig,
+	phase HookPhase,
+	event HookEvent,
+) []HookConfig {
+	hooks := []HookConfig{}
+
+	for _, hook := range append(
+		append([]HookConfig{}, clusterConfig.Spec.Hooks...),
+		topicConfig.Spec.Hooks...,
+	) {
+		if hook.Matches(phase, event) {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookValidate(t *testing.T) {
+	type hookTestCase struct {
+		description string
+		hook        HookConfig
+		expectedOK  bool
+	}
+
+	testCases := []hookTestCase{
+		{
+			description: "command hook",
+			hook: HookConfig{
+				Phase:   HookPhasePre,
+				Events:  []HookEvent{HookEventMigrate},
+				Command: []string{"./notify.sh"},
+			},
+			expectedOK: true,
+		},
+		{
+			description: "webhook",
+			hook: HookConfig{
+				Phase: HookPhasePost,
+				URL:   "https://hooks.example.com/topics",
+			},
+			expectedOK: true,
+		},
+		{
+			description: "bad phase",
+			hook: HookConfig{
+				Phase:   "during",
+				Command: []string{"./notify.sh"},
+			},
+			expectedOK: false,
+		},
+		{
+			description: "bad event",
+			hook: HookConfig{
+				Phase:   HookPhasePre,
+				Events:  []HookEvent{"truncate"},
+				Command: []string{"./notify.sh"},
+			},
+			expectedOK: false,
+		},
+		{
+			description: "command and url",
+			hook: HookConfig{
+				Phase:   HookPhasePre,
+				Command: []string{"./notify.sh"},
+				URL:     "https://hooks.example.com/topics",
+			},
+			expectedOK: false,
+		},
+		{
+			description: "neither command nor url",
+			hook: HookConfig{
+				Phase: HookPhasePre,
+			},
+			expectedOK: false,
+		},
+		{
+			description: "bad url",
+			hook: HookConfig{
+				Phase: HookPhasePre,
+				URL:   "hooks.example.com",
+			},
+			expectedOK: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.hook.Validate()
+		assert.Equal(
+			t,
+			testCase.expectedOK,
+			err == nil,
+			"Unexpected result for %s: %+v",
+			testCase.description,
+			err,
+		)
+	}
+}
+
+func TestMatchingHooks(t *testing.T) {
+	clusterConfig := ClusterConfig{
+		Spec: ClusterSpec{
+			Hooks: []HookConfig{
+				{
+					Name:  "cluster-all",
+					Phase: HookPhasePre,
+				},
+				{
+					Name:   "cluster-create",
+					Phase:  HookPhasePost,
+					Events: []HookEvent{HookEventCreate},
+				},
+			},
+		},
+	}
+	topicConfig := TopicConfig{
+		Spec: TopicSpec{
+			Hooks: []HookConfig{
+				{
+					Name:   "topic-migrate",
+					Phase:  HookPhasePre,
+					Events: []HookEvent{HookEventMigrate},
+				},
+			},
+		},
+	}
+
+	hookNames := func(hooks []HookConfig) []string {
+		names := []string{}
+		for _, hook := range hooks {
+			names = append(names, hook.Name)
+		}
+		return names
+	}
+
+	assert.Equal(
+		t,
+		[]string{"cluster-all", "topic-migrate"},
+		hookNames(MatchingHooks(clusterConfig, topicConfig, HookPhasePre, HookEventMigrate)),
+	)
+	assert.Equal(
+		t,
+		[]string{"cluster-all"},
+		hookNames(MatchingHooks(clusterConfig, topicConfig, HookPhasePre, HookEventCreate)),
+	)
+	assert.Equal(
+		t,
+		[]string{"cluster-create"},
+		hookNames(MatchingHooks(clusterConfig, topicConfig, HookPhasePost, HookEventCreate)),
+	)
+	assert.Equal(
+		t,
+		[]string{},
+		hookNames(MatchingHooks(clusterConfig, topicConfig, HookPhasePost, HookEventMigrate)),
+	)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// TopicLifecycle is a string type that stores the lifecycle state of a topic.
+type TopicLifecycle string
+
+const (
+	// TopicLifecycleActive is the default state, for topics that are in normal use.
+	TopicLifecycleActive TopicLifecycle = "active"
+
+	// TopicLifecycleDeprecated is for topics that clients should stop using. Commands that
+	// refer to these topics show warnings, and produce requests can optionally be blocked.
+	TopicLifecycleDeprecated TopicLifecycle = "deprecated"
+
+	// TopicLifecycleRetiring is for deprecated topics that are scheduled to be deleted on
+	// their deletion date.
+	TopicLifecycleRetiring TopicLifecycle = "retiring"
+)
+
+var allTopicLifecycles = []TopicLifecycle{
+	TopicLifecycleActive,
+	TopicLifecycleDeprecated,
+	TopicLifecycleRetiring,
+}
+
+// LifecycleState returns the lifecycle state of the topic, which is active if it isn't set.
+func (m TopicMeta) LifecycleState() TopicLifecycle {
+	if m.Lifecycle == "" {
+		return TopicLifecycleActive
+	}
+	return m.Lifecycle
+}
+
+// ProduceBlocked returns whether produce requests to the topic should be blocked. This is
+// only possible for topics that are deprecated or retiring.
+func (m TopicMeta) ProduceBlocked() bool {
+	return m.BlockProduce && m.LifecycleState() != TopicLifecycleActive
+}
+
+// DeletionDue returns whether the topic is retiring and its deletion date has passed at the
+// argument time.
+func (m TopicMeta) DeletionDue(now time.Time) (bool, error) {
+	if m.LifecycleState() != TopicLifecycleRetiring {
+		return false, nil
+	}
+
+	deletionDate, err := parseTimeOrDate(m.DeletionDate)
+	if err != nil {
+		return false, fmt.Errorf("Invalid deletion date %s: %+v", m.DeletionDate, err)
+	}
+	return !now.Before(deletionDate), nil
+}
+
+// LifecycleWarning returns a warning for users of the topic if it's deprecated or retiring,
+// or an empty string if it's active.
+func (m TopicMeta) LifecycleWarning() string {
+	var warning string
+
+	switch m.LifecycleState() {
+	case TopicLifecycleDeprecated:
+		warning = fmt.Sprintf("Topic %s is deprecated", m.Name)
+	case TopicLifecycleRetiring:
+		warning = fmt.Sprintf(
+			"Topic %s is deprecated and will be deleted on %s",
+			m.Name,
+			m.DeletionDate,
+		)
+	default:
+		return ""
+	}
+
+	if m.BlockProduce {
+		warning += "; producing to it is blocked"
+	}
+	if m.Replacement != "" {
+		warning += fmt.Sprintf("; use %s instead", m.Replacement)
+	}
+	return warning
+}
+
+func (m TopicMeta) validateLifecycle() error {
+	var err error
+
+	lifecycle := m.LifecycleState()
+	lifecycleFound := false
+	for _, l := range allTopicLifecycles {
+		if l == lifecycle {
+			lifecycleFound = true
+			break
+		}
+	}
+	if !lifecycleFound {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Lifecycle must be one of %+v", allTopicLifecycles),
+		)
+	}
+
+	if lifecycle == TopicLifecycleRetiring {
+		if m.DeletionDate == "" {
+			err = multierror.Append(err, errors.New("DeletionDate must be set for retiring topics"))
+		} else if _, dateErr := parseTimeOrDate(m.DeletionDate); dateErr != nil {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Invalid deletion date %s: %+v", m.DeletionDate, dateErr),
+			)
+		}
+	} else if m.DeletionDate != "" {
+		err = multierror.Append(err, errors.New("DeletionDate can only be set for retiring topics"))
+	}
+
+	if m.BlockProduce && lifecycle == TopicLifecycleActive {
+		err = multierror.Append(
+			err,
+			errors.New("BlockProduce can only be set for deprecated or retiring topics"),
+		)
+	}
+
+	return err
+}
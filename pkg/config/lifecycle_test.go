@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicLifecycle(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	active := TopicMeta{Name: "test-topic"}
+	assert.Equal(t, TopicLifecycleActive, active.LifecycleState())
+	assert.Equal(t, "", active.LifecycleWarning())
+	assert.NoError(t, active.validateLifecycle())
+
+	deprecated := TopicMeta{
+		Name:         "test-topic",
+		Lifecycle:    TopicLifecycleDeprecated,
+		Replacement:  "test-topic-v2",
+		BlockProduce: true,
+	}
+	assert.True(t, deprecated.ProduceBlocked())
+	assert.Equal(
+		t,
+		"Topic test-topic is deprecated; producing to it is blocked; use test-topic-v2 instead",
+		deprecated.LifecycleWarning(),
+	)
+	assert.NoError(t, deprecated.validateLifecycle())
+	due, err := deprecated.DeletionDue(now)
+	require.NoError(t, err)
+	assert.False(t, due)
+
+	retiring := TopicMeta{
+		Name:         "test-topic",
+		Lifecycle:    TopicLifecycleRetiring,
+		DeletionDate: "2021-03-01",
+	}
+	assert.False(t, retiring.ProduceBlocked())
+	assert.Equal(
+		t,
+		"Topic test-topic is deprecated and will be deleted on 2021-03-01",
+		retiring.LifecycleWarning(),
+	)
+	assert.NoError(t, retiring.validateLifecycle())
+	due, err = retiring.DeletionDue(now)
+	require.NoError(t, err)
+	assert.True(t, due)
+	due, err = retiring.DeletionDue(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.False(t, due)
+
+	invalid := []TopicMeta{
+		{
+			Lifecycle: "archived",
+		},
+		{
+			Lifecycle: TopicLifecycleRetiring,
+		},
+		{
+			Lifecycle:    TopicLifecycleRetiring,
+			DeletionDate: "soon",
+		},
+		{
+			Lifecycle:    TopicLifecycleDeprecated,
+			DeletionDate: "2021-03-01",
+		},
+		{
+			BlockProduce: true,
+		},
+	}
+	for _, meta := range invalid {
+		assert.Error(t, meta.validateLifecycle(), "%+v", meta)
+	}
+}
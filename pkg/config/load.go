@@ -2,17 +2,21 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"sort"
 
 	"github.com/ghodss/yaml"
 	"github.com/hashicorp/go-multierror"
+	"github.com/segmentio/topicctl/pkg/exitcode"
 )
 
 // LoadClusterFile loads a ClusterConfig from a path to a YAML file.
 func LoadClusterFile(path string) (ClusterConfig, error) {
 	contents, err := ioutil.ReadFile(path)
 	if err != nil {
-		return ClusterConfig{}, err
+		return ClusterConfig{}, exitcode.Wrap(exitcode.KindConfig, err)
 	}
 	return LoadClusterBytes(contents)
 }
@@ -21,14 +25,14 @@ func LoadClusterFile(path string) (ClusterConfig, error) {
 func LoadClusterBytes(contents []byte) (ClusterConfig, error) {
 	config := ClusterConfig{}
 	err := yaml.Unmarshal(contents, &config)
-	return config, err
+	return config, exitcode.Wrap(exitcode.KindConfig, err)
 }
 
 // LoadTopicFile loads a TopicConfig from a path to a YAML file.
 func LoadTopicFile(path string) (TopicConfig, error) {
 	contents, err := ioutil.ReadFile(path)
 	if err != nil {
-		return TopicConfig{}, err
+		return TopicConfig{}, exitcode.Wrap(exitcode.KindConfig, err)
 	}
 	return LoadTopicBytes(contents)
 }
@@ -37,7 +41,54 @@ func LoadTopicFile(path string) (TopicConfig, error) {
 func LoadTopicBytes(contents []byte) (TopicConfig, error) {
 	config := TopicConfig{}
 	err := yaml.Unmarshal(contents, &config)
-	return config, err
+	return config, exitcode.Wrap(exitcode.KindConfig, err)
+}
+
+// LoadConnectorFile loads a ConnectorConfig from a path to a YAML file.
+func LoadConnectorFile(path string) (ConnectorConfig, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ConnectorConfig{}, exitcode.Wrap(exitcode.KindConfig, err)
+	}
+	return LoadConnectorBytes(contents)
+}
+
+// LoadConnectorBytes loads a ConnectorConfig from YAML bytes.
+func LoadConnectorBytes(contents []byte) (ConnectorConfig, error) {
+	config := ConnectorConfig{}
+	err := yaml.Unmarshal(contents, &config)
+	return config, exitcode.Wrap(exitcode.KindConfig, err)
+}
+
+// LoadBrokersFile loads a BrokersConfig from a path to a YAML file.
+func LoadBrokersFile(path string) (BrokersConfig, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return BrokersConfig{}, exitcode.Wrap(exitcode.KindConfig, err)
+	}
+	return LoadBrokersBytes(contents)
+}
+
+// LoadBrokersBytes loads a BrokersConfig from YAML bytes.
+func LoadBrokersBytes(contents []byte) (BrokersConfig, error) {
+	config := BrokersConfig{}
+	err := yaml.Unmarshal(contents, &config)
+	return config, exitcode.Wrap(exitcode.KindConfig, err)
+}
+
+// LoadKindFile returns the value of the kind field in the YAML file at the argument path.
+// This is blank for topic configs.
+func LoadKindFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", exitcode.Wrap(exitcode.KindConfig, err)
+	}
+
+	kindHolder := struct {
+		Kind string `json:"kind"`
+	}{}
+	err = yaml.Unmarshal(contents, &kindHolder)
+	return kindHolder.Kind, exitcode.Wrap(exitcode.KindConfig, err)
 }
 
 // CheckConsistency verifies that the argument topic config is consistent with the argument
@@ -63,6 +114,130 @@ func CheckConsistency(topicConfig TopicConfig, clusterConfig ClusterConfig) erro
 			errors.New("Topic region does not match cluster region"),
 		)
 	}
+	if topicConfig.Spec.SchemasConfig != nil && clusterConfig.Spec.SchemaRegistryURL == "" {
+		err = multierror.Append(
+			err,
+			errors.New("Topic declares schemas but cluster does not have a schema registry URL"),
+		)
+	}
+	if tiersErr := checkRetentionTiers(topicConfig, clusterConfig); tiersErr != nil {
+		err = multierror.Append(err, tiersErr)
+	}
+
+	return err
+}
+
+// LoadTopicConfigs loads all of the topic configs in the argument directory. Files that
+// aren't topic configs are ignored.
+func LoadTopicConfigs(dir string) ([]TopicConfig, error) {
+	topicConfigs := []TopicConfig{}
+
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			kind, err := LoadKindFile(match)
+			if err != nil {
+				return nil, err
+			}
+			if kind != "" {
+				continue
+			}
+
+			topicConfig, err := LoadTopicFile(match)
+			if err != nil {
+				return nil, err
+			}
+			topicConfigs = append(topicConfigs, topicConfig)
+		}
+	}
+
+	return topicConfigs, nil
+}
+
+// LoadTopicSetMembers loads the other topic configs in the argument directory that are in
+// the same cluster and topic set as the argument topic config. The members inherit the
+// argument cluster-level topic defaults, which can be nil. The results are sorted by
+// topic name. Files that aren't topic configs are ignored.
+func LoadTopicSetMembers(
+	dir string,
+	topicConfig TopicConfig,
+	defaults *TopicDefaults,
+) ([]TopicConfig, error) {
+	topicConfigs, err := LoadTopicConfigs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return TopicSetMembers(topicConfig, topicConfigs, defaults), nil
+}
+
+// TopicSetMembers returns the configs among the argument candidates that are in the same
+// cluster and topic set as the argument topic config, after they inherit the argument
+// cluster-level topic defaults. The results are sorted by topic name.
+func TopicSetMembers(
+	topicConfig TopicConfig,
+	candidates []TopicConfig,
+	defaults *TopicDefaults,
+) []TopicConfig {
+	members := []TopicConfig{}
+
+	for _, memberConfig := range candidates {
+		memberConfig.InheritDefaults(defaults)
+
+		if memberConfig.Meta.Name == topicConfig.Meta.Name ||
+			memberConfig.Meta.Cluster != topicConfig.Meta.Cluster ||
+			memberConfig.Spec.PlacementConfig.Strategy != PlacementStrategyBalancedTopicSet ||
+			memberConfig.Spec.PlacementConfig.TopicSet !=
+				topicConfig.Spec.PlacementConfig.TopicSet {
+			continue
+		}
+
+		members = append(members, memberConfig)
+	}
+
+	sort.Slice(members, func(a, b int) bool {
+		return members[a].Meta.Name < members[b].Meta.Name
+	})
+
+	return members
+}
+
+// CheckTopicSetConsistency verifies that the argument topic config is consistent with the
+// other members of its topic set. Co-partitioned topics need to have the same number of
+// partitions and replicas so that matching partitions can be placed on the same brokers.
+func CheckTopicSetConsistency(topicConfig TopicConfig, members []TopicConfig) error {
+	var err error
+
+	for _, member := range members {
+		if member.Spec.Partitions != topicConfig.Spec.Partitions {
+			err = multierror.Append(
+				err,
+				fmt.Errorf(
+					"Topic %s in topic set %s has %d partitions, but this topic has %d",
+					member.Meta.Name,
+					topicConfig.Spec.PlacementConfig.TopicSet,
+					member.Spec.Partitions,
+					topicConfig.Spec.Partitions,
+				),
+			)
+		}
+		if member.Spec.ReplicationFactor != topicConfig.Spec.ReplicationFactor {
+			err = multierror.Append(
+				err,
+				fmt.Errorf(
+					"Topic %s in topic set %s has replication factor %d, but this topic has %d",
+					member.Meta.Name,
+					topicConfig.Spec.PlacementConfig.TopicSet,
+					member.Spec.ReplicationFactor,
+					topicConfig.Spec.ReplicationFactor,
+				),
+			)
+		}
+	}
 
 	return err
 }
@@ -105,3 +105,145 @@ func TestCheckConsistency(t *testing.T) {
 	assert.Nil(t, CheckConsistency(topicConfig, clusterConfig))
 	assert.NotNil(t, CheckConsistency(topicConfigNoMatch, clusterConfig))
 }
+
+func TestLoadConnector(t *testing.T) {
+	kind, err := LoadKindFile("testdata/test-cluster/connectors/connector-test.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, ConnectorKind, kind)
+
+	kind, err = LoadKindFile("testdata/test-cluster/topics/topic-test.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, "", kind)
+
+	connectorConfig, err := LoadConnectorFile(
+		"testdata/test-cluster/connectors/connector-test.yaml",
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		ConnectorConfig{
+			Kind: ConnectorKind,
+			Meta: ConnectorMeta{
+				Name:        "connector-test",
+				Cluster:     "test-cluster",
+				Region:      "test-region",
+				Environment: "test-env",
+				Description: "Test connector\n",
+			},
+			Spec: ConnectorSpec{
+				Config: map[string]interface{}{
+					"connector.class": "io.confluent.connect.s3.S3SinkConnector",
+					"tasks.max":       4.0,
+					"topics":          "topic-test",
+					"flush.size":      1000.0,
+				},
+			},
+		},
+		connectorConfig,
+	)
+	assert.Nil(t, connectorConfig.Validate())
+
+	connectConfig, err := connectorConfig.ToConnectConfig()
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		map[string]string{
+			"connector.class": "io.confluent.connect.s3.S3SinkConnector",
+			"tasks.max":       "4",
+			"topics":          "topic-test",
+			"flush.size":      "1000",
+		},
+		connectConfig,
+	)
+
+	clusterConfig, err := LoadClusterFile("testdata/test-cluster/cluster.yaml")
+	require.Nil(t, err)
+
+	// Cluster config doesn't have a connect URL
+	assert.NotNil(t, CheckConnectorConsistency(connectorConfig, clusterConfig))
+
+	clusterConfig.Spec.ConnectURL = "http://connect:8083"
+	assert.Nil(t, CheckConnectorConsistency(connectorConfig, clusterConfig))
+
+	connectorConfig.Spec.Config["name"] = "other-name"
+	assert.NotNil(t, connectorConfig.Validate())
+}
+
+func TestLoadBrokers(t *testing.T) {
+	brokersConfig, err := LoadBrokersFile("testdata/test-cluster/brokers.yaml")
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		BrokersConfig{
+			Meta: BrokersMeta{
+				Cluster:     "test-cluster",
+				Region:      "test-region",
+				Environment: "test-env",
+				Description: "Test brokers\n",
+			},
+			Spec: BrokersSpec{
+				DefaultSettings: BrokerSettings{
+					"log.cleaner.threads":  2.0,
+					"num.replica.fetchers": 4.0,
+				},
+				BrokerSettings: map[string]BrokerSettings{
+					"2": {
+						"leader.replication.throttled.rate": 50000000.0,
+					},
+				},
+			},
+		},
+		brokersConfig,
+	)
+	assert.Nil(t, brokersConfig.Validate())
+	assert.Equal(t, []int{2}, brokersConfig.BrokerIDs())
+	assert.Nil(t, brokersConfig.SettingsForBroker(1))
+
+	clusterConfig, err := LoadClusterFile("testdata/test-cluster/cluster.yaml")
+	require.Nil(t, err)
+	assert.Nil(t, CheckBrokersConsistency(brokersConfig, clusterConfig))
+
+	// Throttle rates can't be set as cluster-wide defaults
+	brokersConfig.Spec.DefaultSettings["leader.replication.throttled.rate"] = 1000
+	assert.NotNil(t, brokersConfig.Validate())
+	delete(brokersConfig.Spec.DefaultSettings, "leader.replication.throttled.rate")
+
+	// Static broker configs can't be updated via apply
+	brokersConfig.Spec.DefaultSettings["broker.rack"] = "us-west-2a"
+	assert.NotNil(t, brokersConfig.Validate())
+	delete(brokersConfig.Spec.DefaultSettings, "broker.rack")
+
+	brokersConfig.Spec.BrokerSettings["not-an-id"] = BrokerSettings{}
+	assert.NotNil(t, brokersConfig.Validate())
+}
+
+func TestLoadTopicSetMembers(t *testing.T) {
+	topicConfig, err := LoadTopicFile("testdata/test-cluster/topic-sets/orders.yaml")
+	require.NoError(t, err)
+	topicConfig.SetDefaults()
+	assert.Nil(t, topicConfig.Validate(3))
+
+	members, err := LoadTopicSetMembers("testdata/test-cluster/topic-sets", topicConfig, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(members))
+	assert.Equal(t, "payments", members[0].Meta.Name)
+
+	// The members have different partition counts
+	assert.NotNil(t, CheckTopicSetConsistency(topicConfig, members))
+
+	members[0].Spec.Partitions = topicConfig.Spec.Partitions
+	assert.Nil(t, CheckTopicSetConsistency(topicConfig, members))
+}
+
+func TestLoadTopicConfigs(t *testing.T) {
+	topicConfigs, err := LoadTopicConfigs("testdata/test-cluster/topic-sets")
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, topicConfig := range topicConfigs {
+		names = append(names, topicConfig.Meta.Name)
+	}
+
+	// The connector config is skipped
+	assert.Equal(t, []string{"orders", "payments", "refunds"}, names)
+}
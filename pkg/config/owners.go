@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// Ownership contains the details of who owns a topic.
+type Ownership struct {
+	Owner   string `json:"owner,omitempty"`
+	Team    string `json:"team,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+// IsEmpty returns whether none of the ownership fields are set.
+func (o Ownership) IsEmpty() bool {
+	return o.Owner == "" && o.Team == "" && o.Contact == ""
+}
+
+// merge returns the current ownership with any non-empty fields in the argument ownership
+// replacing the existing values.
+func (o Ownership) merge(other Ownership) Ownership {
+	if other.Owner != "" {
+		o.Owner = other.Owner
+	}
+	if other.Team != "" {
+		o.Team = other.Team
+	}
+	if other.Contact != "" {
+		o.Contact = other.Contact
+	}
+	return o
+}
+
+// OwnershipRule assigns owners to all of the topics whose names match a pattern, similar
+// to a line in a CODEOWNERS file.
+type OwnershipRule struct {
+	// Match is a glob, or a regular expression if wrapped in slashes, that's matched
+	// against topic names.
+	Match string `json:"match"`
+
+	Owner   string `json:"owner,omitempty"`
+	Team    string `json:"team,omitempty"`
+	Contact string `json:"contact,omitempty"`
+}
+
+// Ownership returns the ownership assigned by the rule.
+func (r OwnershipRule) Ownership() Ownership {
+	return Ownership{
+		Owner:   r.Owner,
+		Team:    r.Team,
+		Contact: r.Contact,
+	}
+}
+
+// Validate evaluates whether the rule is valid.
+func (r OwnershipRule) Validate() error {
+	var err error
+
+	if r.Match == "" {
+		err = multierror.Append(err, errors.New("Owners rules must set match"))
+	} else if _, matchErr := util.NewTopicMatcher(r.Match); matchErr != nil {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Invalid owners rule match %s: %+v", r.Match, matchErr),
+		)
+	}
+	if r.Ownership().IsEmpty() {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Owners rule for %s must set at least one of owner, team, or contact", r.Match),
+		)
+	}
+
+	return err
+}
+
+// TopicOwnership contains the resolved ownership of a single topic, along with where it
+// came from.
+type TopicOwnership struct {
+	Topic string `json:"topic"`
+	Ownership
+
+	// Source describes the topic config or owners rule that the ownership came from.
+	Source string `json:"source,omitempty"`
+}
+
+// OwnershipIndex resolves the owners of topics from the owners rules in a cluster config
+// and the ownership fields in topic configs.
+type OwnershipIndex struct {
+	rules        []OwnershipRule
+	matchers     []*util.TopicMatcher
+	topicConfigs map[string]TopicConfig
+}
+
+// NewOwnershipIndex returns an OwnershipIndex for the argument cluster and topic configs.
+// Topic configs for other clusters are ignored.
+func NewOwnershipIndex(
+	clusterConfig ClusterConfig,
+	topicConfigs []TopicConfig,
+) (*OwnershipIndex, error) {
+	index := &OwnershipIndex{
+		rules:        clusterConfig.Spec.Owners,
+		topicConfigs: map[string]TopicConfig{},
+	}
+
+	for _, rule := range clusterConfig.Spec.Owners {
+		matcher, err := util.NewTopicMatcher(rule.Match)
+		if err != nil {
+			return nil, err
+		}
+		index.matchers = append(index.matchers, matcher)
+	}
+
+	for _, topicConfig := range topicConfigs {
+		if clusterConfig.Meta.Name != "" && topicConfig.Meta.Cluster != clusterConfig.Meta.Name {
+			continue
+		}
+		index.topicConfigs[topicConfig.Meta.Name] = topicConfig
+	}
+
+	return index, nil
+}
+
+// Lookup returns the ownership of the argument topic. As in a CODEOWNERS file, the last
+// matching owners rule wins. Any ownership fields that are set in the topic's config then
+// replace the values from the rule.
+func (i *OwnershipIndex) Lookup(topicName string) TopicOwnership {
+	result := TopicOwnership{Topic: topicName}
+
+	for r := len(i.rules) - 1; r >= 0; r-- {
+		if i.matchers[r].Matches(topicName) {
+			result.Ownership = i.rules[r].Ownership()
+			result.Source = fmt.Sprintf("owners rule %s", i.rules[r].Match)
+			break
+		}
+	}
+
+	if topicConfig, ok := i.topicConfigs[topicName]; ok {
+		if topicOwnership := topicConfig.Meta.Ownership(); !topicOwnership.IsEmpty() {
+			result.Ownership = result.Ownership.merge(topicOwnership)
+			result.Source = "topic config"
+		}
+	}
+
+	return result
+}
+
+// TopicConfig returns the config for the argument topic, if the index has one.
+func (i *OwnershipIndex) TopicConfig(topicName string) (TopicConfig, bool) {
+	topicConfig, ok := i.topicConfigs[topicName]
+	return topicConfig, ok
+}
+
+// FormatTopicOwnerships generates a pretty table with the owners of the argument topics.
+func FormatTopicOwnerships(ownerships []TopicOwnership) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Topic",
+			"Owner",
+			"Team",
+			"Contact",
+			"Source",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, ownership := range ownerships {
+		source := ownership.Source
+		if source == "" {
+			source = "none"
+		}
+
+		table.Append(
+			[]string{
+				ownership.Topic,
+				ownership.Owner,
+				ownership.Team,
+				ownership.Contact,
+				source,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipRuleValidate(t *testing.T) {
+	assert.Nil(t, OwnershipRule{Match: "payments-*", Team: "payments"}.Validate())
+	assert.Nil(t, OwnershipRule{Match: "/^orders-v[0-9]+$/", Owner: "alice"}.Validate())
+	assert.NotNil(t, OwnershipRule{Team: "payments"}.Validate())
+	assert.NotNil(t, OwnershipRule{Match: "payments-*"}.Validate())
+	assert.NotNil(t, OwnershipRule{Match: "/[/", Team: "payments"}.Validate())
+}
+
+func TestOwnershipIndexLookup(t *testing.T) {
+	clusterConfig := ClusterConfig{
+		Meta: ClusterMeta{
+			Name: "test-cluster",
+		},
+		Spec: ClusterSpec{
+			Owners: []OwnershipRule{
+				{
+					Match:   "*",
+					Team:    "platform",
+					Contact: "#platform-oncall",
+				},
+				{
+					Match: "payments-*",
+					Team:  "payments",
+				},
+			},
+		},
+	}
+	topicConfigs := []TopicConfig{
+		{
+			Meta: TopicMeta{
+				Name:    "payments-refunds",
+				Cluster: "test-cluster",
+				Owner:   "alice",
+			},
+		},
+		{
+			Meta: TopicMeta{
+				Name:    "orders",
+				Cluster: "test-cluster",
+			},
+		},
+		{
+			Meta: TopicMeta{
+				Name:    "payments-other-cluster",
+				Cluster: "other-cluster",
+				Owner:   "bob",
+			},
+		},
+	}
+
+	index, err := NewOwnershipIndex(clusterConfig, topicConfigs)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		TopicOwnership{
+			Topic: "orders",
+			Ownership: Ownership{
+				Team:    "platform",
+				Contact: "#platform-oncall",
+			},
+			Source: "owners rule *",
+		},
+		index.Lookup("orders"),
+	)
+
+	// The last matching rule wins
+	assert.Equal(
+		t,
+		TopicOwnership{
+			Topic: "payments-charges",
+			Ownership: Ownership{
+				Team: "payments",
+			},
+			Source: "owners rule payments-*",
+		},
+		index.Lookup("payments-charges"),
+	)
+
+	// Topic config fields override the rule
+	assert.Equal(
+		t,
+		TopicOwnership{
+			Topic: "payments-refunds",
+			Ownership: Ownership{
+				Owner: "alice",
+				Team:  "payments",
+			},
+			Source: "topic config",
+		},
+		index.Lookup("payments-refunds"),
+	)
+
+	// Configs for other clusters are ignored
+	assert.Equal(
+		t,
+		Ownership{Team: "payments"},
+		index.Lookup("payments-other-cluster").Ownership,
+	)
+
+	emptyIndex, err := NewOwnershipIndex(ClusterConfig{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, TopicOwnership{Topic: "orders"}, emptyIndex.Lookup("orders"))
+}
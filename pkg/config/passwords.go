@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// DefaultPasswordPolicy is used for the passwords of users in clusters that don't set a
+// password policy.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength: 8,
+}
+
+// PasswordPolicy contains the requirements for the passwords of the SCRAM users that are
+// created or updated with topicctl.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters in each password.
+	MinLength int `json:"minLength"`
+
+	// RequireUppercase, RequireLowercase, RequireDigit, and RequireSymbol indicate whether
+	// passwords must contain at least one character of the corresponding class.
+	RequireUppercase bool `json:"requireUppercase,omitempty"`
+	RequireLowercase bool `json:"requireLowercase,omitempty"`
+	RequireDigit     bool `json:"requireDigit,omitempty"`
+	RequireSymbol    bool `json:"requireSymbol,omitempty"`
+
+	// AllowUsername indicates whether passwords can contain the name of their user.
+	AllowUsername bool `json:"allowUsername,omitempty"`
+}
+
+// Validate evaluates whether the password policy is valid.
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 1 {
+		return errors.New("Password policy minLength must be >= 1")
+	}
+	return nil
+}
+
+// Check evaluates whether the argument password for the argument user meets the
+// requirements of the policy. The returned error lists all of the unmet requirements.
+func (p PasswordPolicy) Check(user string, password string) error {
+	var err error
+
+	if len([]rune(password)) < p.MinLength {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Password must be at least %d characters long", p.MinLength),
+		)
+	}
+
+	var hasUppercase, hasLowercase, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUppercase = true
+		case unicode.IsLower(char):
+			hasLowercase = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUppercase {
+		err = multierror.Append(err, errors.New("Password must contain an uppercase letter"))
+	}
+	if p.RequireLowercase && !hasLowercase {
+		err = multierror.Append(err, errors.New("Password must contain a lowercase letter"))
+	}
+	if p.RequireDigit && !hasDigit {
+		err = multierror.Append(err, errors.New("Password must contain a digit"))
+	}
+	if p.RequireSymbol && !hasSymbol {
+		err = multierror.Append(err, errors.New("Password must contain a symbol"))
+	}
+	if !p.AllowUsername && user != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(user)) {
+		err = multierror.Append(err, errors.New("Password cannot contain the user name"))
+	}
+
+	return err
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+	assert.NoError(t, policy.Validate())
+
+	type testCase struct {
+		description string
+		user        string
+		password    string
+		expectedErr []string
+	}
+
+	testCases := []testCase{
+		{
+			description: "valid",
+			user:        "service",
+			password:    "Correct-Horse-7",
+		},
+		{
+			description: "too short and missing classes",
+			user:        "service",
+			password:    "horse",
+			expectedErr: []string{
+				"at least 10 characters",
+				"uppercase",
+				"digit",
+				"symbol",
+			},
+		},
+		{
+			description: "contains user name",
+			user:        "service",
+			password:    "My-Service-Pw-1",
+			expectedErr: []string{
+				"user name",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := policy.Check(testCase.user, testCase.password)
+		if len(testCase.expectedErr) == 0 {
+			assert.NoError(t, err, testCase.description)
+			continue
+		}
+
+		if assert.Error(t, err, testCase.description) {
+			for _, expected := range testCase.expectedErr {
+				assert.Contains(t, err.Error(), expected, testCase.description)
+			}
+		}
+	}
+
+	assert.NoError(t, DefaultPasswordPolicy.Check("service", "long-enough"))
+	assert.Error(t, DefaultPasswordPolicy.Check("service", "short"))
+	assert.Error(t, PasswordPolicy{}.Validate())
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// parseRetentionTier converts a retention tier duration, e.g. "6h" or "7d", into minutes.
+func parseRetentionTier(value string) (int, error) {
+	duration, err := util.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < time.Minute || duration%time.Minute != 0 {
+		return 0, fmt.Errorf("Retention %s must be a positive, whole number of minutes", value)
+	}
+	return int(duration / time.Minute), nil
+}
+
+// validateRetentionTiers evaluates whether the argument retention tiers are valid.
+func validateRetentionTiers(tiers map[string]string) error {
+	var err error
+
+	for _, name := range sortedTierNames(tiers) {
+		if _, tierErr := parseRetentionTier(tiers[name]); tierErr != nil {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Invalid retention tier %s: %+v", name, tierErr),
+			)
+		}
+	}
+
+	return err
+}
+
+// ResolveRetentionTier replaces the retention tier in the topic config, if any, with the
+// equivalent RetentionMinutes value from the argument tiers. It returns an error if the
+// tier isn't defined or the topic also sets its retention directly.
+func (t *TopicConfig) ResolveRetentionTier(tiers map[string]string) error {
+	if t.Spec.RetentionTier == "" {
+		return nil
+	}
+	if t.Spec.RetentionMinutes > 0 || t.Spec.Settings.HasKey("retention.ms") {
+		return errors.New("Cannot set both retentionTier and retentionMinutes or retention.ms")
+	}
+
+	value, ok := tiers[t.Spec.RetentionTier]
+	if !ok {
+		return fmt.Errorf(
+			"Retention tier %s is not defined in the cluster config; must be in %+v",
+			t.Spec.RetentionTier,
+			sortedTierNames(tiers),
+		)
+	}
+
+	minutes, err := parseRetentionTier(value)
+	if err != nil {
+		return err
+	}
+
+	t.Spec.RetentionMinutes = minutes
+	t.Spec.RetentionTier = ""
+	return nil
+}
+
+// checkRetentionTiers verifies that, if the argument cluster defines retention tiers, the
+// retention of the argument topic is one of them. Topics that don't set a retention use the
+// broker default and are allowed.
+func checkRetentionTiers(topicConfig TopicConfig, clusterConfig ClusterConfig) error {
+	tiers := clusterConfig.Spec.RetentionTiers
+	if len(tiers) == 0 {
+		return nil
+	}
+	if topicConfig.Spec.RetentionTier != "" {
+		// Not resolved yet; ResolveRetentionTier checks that the tier exists
+		return nil
+	}
+
+	var minutes int
+	if topicConfig.Spec.RetentionMinutes > 0 {
+		minutes = topicConfig.Spec.RetentionMinutes
+	} else if topicConfig.Spec.Settings.HasKey("retention.ms") {
+		valueStr, err := topicConfig.Spec.Settings.GetValueStr("retention.ms")
+		if err != nil {
+			return err
+		}
+		retentionMs, err := strconv.ParseInt(valueStr, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Could not parse retention.ms value %s", valueStr)
+		}
+		if retentionMs%60000 != 0 {
+			return fmt.Errorf(
+				"Retention of %dms does not match any of the cluster's retention tiers",
+				retentionMs,
+			)
+		}
+		minutes = int(retentionMs / 60000)
+	} else {
+		return nil
+	}
+
+	for _, name := range sortedTierNames(tiers) {
+		tierMinutes, err := parseRetentionTier(tiers[name])
+		if err == nil && tierMinutes == minutes {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(
+		"Retention of %d minutes does not match any of the cluster's retention tiers %+v; set retentionTier instead",
+		minutes,
+		sortedTierNames(tiers),
+	)
+}
+
+func sortedTierNames(tiers map[string]string) []string {
+	names := []string{}
+	for name := range tiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRetentionTiers(t *testing.T) {
+	assert.NoError(
+		t,
+		validateRetentionTiers(
+			map[string]string{
+				"short":    "6h",
+				"standard": "7d",
+				"archive":  "90d",
+			},
+		),
+	)
+	assert.Error(t, validateRetentionTiers(map[string]string{"bad": "forever"}))
+	assert.Error(t, validateRetentionTiers(map[string]string{"tiny": "30s"}))
+	assert.Error(t, validateRetentionTiers(map[string]string{"negative": "-1h"}))
+}
+
+func TestResolveRetentionTier(t *testing.T) {
+	tiers := map[string]string{
+		"short":    "6h",
+		"standard": "7d",
+	}
+
+	topicConfig := TopicConfig{
+		Spec: TopicSpec{
+			RetentionTier: "standard",
+		},
+	}
+	require.NoError(t, topicConfig.ResolveRetentionTier(tiers))
+	assert.Equal(t, 7*24*60, topicConfig.Spec.RetentionMinutes)
+	assert.Equal(t, "", topicConfig.Spec.RetentionTier)
+
+	topicConfig = TopicConfig{
+		Spec: TopicSpec{
+			RetentionTier: "archive",
+		},
+	}
+	assert.Error(t, topicConfig.ResolveRetentionTier(tiers))
+
+	topicConfig = TopicConfig{
+		Spec: TopicSpec{
+			RetentionTier:    "short",
+			RetentionMinutes: 360,
+		},
+	}
+	assert.Error(t, topicConfig.ResolveRetentionTier(tiers))
+
+	topicConfig = TopicConfig{
+		Spec: TopicSpec{
+			RetentionMinutes: 100,
+		},
+	}
+	require.NoError(t, topicConfig.ResolveRetentionTier(tiers))
+	assert.Equal(t, 100, topicConfig.Spec.RetentionMinutes)
+}
+
+func TestCheckRetentionTiers(t *testing.T) {
+	clusterConfig := ClusterConfig{
+		Spec: ClusterSpec{
+			RetentionTiers: map[string]string{
+				"short":    "6h",
+				"standard": "7d",
+			},
+		},
+	}
+
+	type retentionTestCase struct {
+		description string
+		spec        TopicSpec
+		expectedOK  bool
+	}
+
+	testCases := []retentionTestCase{
+		{
+			description: "no retention",
+			spec:        TopicSpec{},
+			expectedOK:  true,
+		},
+		{
+			description: "minutes matching tier",
+			spec: TopicSpec{
+				RetentionMinutes: 360,
+			},
+			expectedOK: true,
+		},
+		{
+			description: "minutes not matching tier",
+			spec: TopicSpec{
+				RetentionMinutes: 100,
+			},
+			expectedOK: false,
+		},
+		{
+			description: "retention.ms matching tier",
+			spec: TopicSpec{
+				Settings: TopicSettings{
+					"retention.ms": 604800000,
+				},
+			},
+			expectedOK: true,
+		},
+		{
+			description: "retention.ms not matching tier",
+			spec: TopicSpec{
+				Settings: TopicSettings{
+					"retention.ms": 1000,
+				},
+			},
+			expectedOK: false,
+		},
+		{
+			description: "unresolved tier",
+			spec: TopicSpec{
+				RetentionTier: "short",
+			},
+			expectedOK: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := checkRetentionTiers(TopicConfig{Spec: testCase.spec}, clusterConfig)
+		assert.Equal(
+			t,
+			testCase.expectedOK,
+			err == nil,
+			"Unexpected result for %s: %+v",
+			testCase.description,
+			err,
+		)
+	}
+
+	assert.NoError(
+		t,
+		checkRetentionTiers(
+			TopicConfig{Spec: TopicSpec{RetentionMinutes: 100}},
+			ClusterConfig{},
+		),
+	)
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const jsonSchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema is a JSON Schema document (or sub-document).
+type JSONSchema map[string]interface{}
+
+// SchemaKinds contains the names of the config formats that JSON schemas can be generated
+// for.
+var SchemaKinds = []string{"brokers", "cluster", "connector", "topic"}
+
+// GenerateJSONSchema generates a JSON Schema document for the config format with the
+// argument name. The schema is derived from the config structs via reflection so that it
+// stays in sync with the loaders.
+func GenerateJSONSchema(kind string) (JSONSchema, error) {
+	var obj interface{}
+	var title string
+
+	switch kind {
+	case "brokers":
+		obj = BrokersConfig{}
+		title = "topicctl brokers config"
+	case "cluster":
+		obj = ClusterConfig{}
+		title = "topicctl cluster config"
+	case "connector":
+		obj = ConnectorConfig{}
+		title = "topicctl connector config"
+	case "topic":
+		obj = TopicConfig{}
+		title = "topicctl topic config"
+	default:
+		return nil, fmt.Errorf("Unrecognized schema kind %s; must be in %+v", kind, SchemaKinds)
+	}
+
+	schema := typeSchema(reflect.TypeOf(obj))
+	schema["$schema"] = jsonSchemaVersion
+	schema["title"] = title
+
+	return schema, nil
+}
+
+// typeSchema returns the schema for the argument type. Types with a fixed set of values
+// or keys are special-cased so that editors can offer completions for them.
+func typeSchema(t reflect.Type) JSONSchema {
+	switch t {
+	case reflect.TypeOf(PlacementStrategy("")):
+		return enumSchema(allPlacementStrategies)
+	case reflect.TypeOf(PickerMethod("")):
+		return enumSchema(allPickerMethods)
+	case reflect.TypeOf(RebalanceGoal("")):
+		return enumSchema(allRebalanceGoals)
+	case reflect.TypeOf(HookPhase("")):
+		return enumSchema(allHookPhases)
+	case reflect.TypeOf(HookEvent("")):
+		return enumSchema(allHookEvents)
+	case reflect.TypeOf(KafkaVersionMajor("")):
+		return enumSchema([]KafkaVersionMajor{KafkaVersionMajor010, KafkaVersionMajor2})
+	case reflect.TypeOf(TopicSettings{}):
+		return settingsSchema(keyValidators)
+	case reflect.TypeOf(BrokerSettings{}):
+		return settingsSchema(dynamicBrokerKeys)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return JSONSchema{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return JSONSchema{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	default:
+		// Interfaces and anything else can hold any value
+		return JSONSchema{}
+	}
+}
+
+func structSchema(t reflect.Type) JSONSchema {
+	properties := JSONSchema{}
+
+	for f := 0; f < t.NumField(); f++ {
+		field := t.Field(f)
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		properties[name] = typeSchema(field.Type)
+	}
+
+	return JSONSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func enumSchema(values interface{}) JSONSchema {
+	enumValues := []string{}
+
+	valuesSlice := reflect.ValueOf(values)
+	for v := 0; v < valuesSlice.Len(); v++ {
+		enumValues = append(enumValues, valuesSlice.Index(v).String())
+	}
+
+	return JSONSchema{
+		"type": "string",
+		"enum": enumValues,
+	}
+}
+
+// settingsSchema returns the schema for a map of Kafka config settings with the keys in
+// the argument map. Values can be strings, numbers, booleans, or lists of these since they're
+// all converted to strings before being sent to Kafka.
+func settingsSchema(keysMap interface{}) JSONSchema {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(keysMap).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	properties := JSONSchema{}
+	for _, key := range keys {
+		properties[key] = JSONSchema{
+			"type": []string{"string", "number", "boolean", "array"},
+		}
+	}
+
+	return JSONSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
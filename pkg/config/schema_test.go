@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema, err := GenerateJSONSchema("topic")
+	require.Nil(t, err)
+	assert.Equal(t, jsonSchemaVersion, schema["$schema"])
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	spec := schema["properties"].(JSONSchema)["spec"].(JSONSchema)
+	specProperties := spec["properties"].(JSONSchema)
+
+	assert.Equal(t, JSONSchema{"type": "integer"}, specProperties["partitions"])
+	assert.Equal(t, JSONSchema{"type": "boolean"}, specProperties["keyed"])
+
+	// Pointer fields are dereferenced
+	migration := specProperties["migration"].(JSONSchema)
+	assert.Equal(
+		t,
+		JSONSchema{"type": "integer"},
+		migration["properties"].(JSONSchema)["throttleMB"],
+	)
+
+	placement := specProperties["placement"].(JSONSchema)["properties"].(JSONSchema)
+	assert.Equal(
+		t,
+		JSONSchema{
+			"type": "string",
+			"enum": []string{
+				"any",
+				"balanced-leaders",
+				"balanced-topic-set",
+				"in-rack",
+				"static",
+				"static-in-rack",
+			},
+		},
+		placement["strategy"],
+	)
+	assert.Equal(
+		t,
+		JSONSchema{
+			"type": "array",
+			"items": JSONSchema{
+				"type":  "array",
+				"items": JSONSchema{"type": "integer"},
+			},
+		},
+		placement["staticAssignments"],
+	)
+
+	settings := specProperties["settings"].(JSONSchema)
+	assert.Equal(t, false, settings["additionalProperties"])
+	assert.Contains(t, settings["properties"], "cleanup.policy")
+	assert.Equal(t, len(keyValidators), len(settings["properties"].(JSONSchema)))
+
+	for _, kind := range SchemaKinds {
+		_, err := GenerateJSONSchema(kind)
+		assert.Nil(t, err, kind)
+	}
+
+	_, err = GenerateJSONSchema("bad-kind")
+	assert.NotNil(t, err)
+}
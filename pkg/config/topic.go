@@ -35,11 +35,17 @@ const (
 	// are chosen from the rack in a static list, but the specific replicas within each partition
 	// aren't specified.
 	PlacementStrategyStaticInRack PlacementStrategy = "static-in-rack"
+
+	// PlacementStrategyBalancedTopicSet is a strategy in which all of the topics that share
+	// a topic set label have the same replicas for each partition number. The first topic in
+	// the set is placed using the balanced-leaders strategy, and the others follow it.
+	PlacementStrategyBalancedTopicSet PlacementStrategy = "balanced-topic-set"
 )
 
 var allPlacementStrategies = []PlacementStrategy{
 	PlacementStrategyAny,
 	PlacementStrategyBalancedLeaders,
+	PlacementStrategyBalancedTopicSet,
 	PlacementStrategyInRack,
 	PlacementStrategyStatic,
 	PlacementStrategyStaticInRack,
@@ -70,6 +76,16 @@ var allPickerMethods = []PickerMethod{
 	PickerMethodRandomized,
 }
 
+var allSchemaCompatibilities = []string{
+	"BACKWARD",
+	"BACKWARD_TRANSITIVE",
+	"FORWARD",
+	"FORWARD_TRANSITIVE",
+	"FULL",
+	"FULL_TRANSITIVE",
+	"NONE",
+}
+
 // TopicConfig represents the desired configuration of a topic.
 type TopicConfig struct {
 	Meta TopicMeta `json:"meta"`
@@ -88,6 +104,36 @@ type TopicMeta struct {
 	// Consumers is a list of consumers who are expected to consume from this
 	// topic.
 	Consumers []string `json:"consumers,omitempty"`
+
+	// Owner, Team, and Contact identify who's responsible for the topic, e.g. for paging
+	// when it misbehaves. They override any owners rules in the cluster config.
+	Owner   string `json:"owner,omitempty"`
+	Team    string `json:"team,omitempty"`
+	Contact string `json:"contact,omitempty"`
+
+	// Frozen, if true, causes apply to skip the topic until FreezeExpiry (an RFC3339 time or
+	// date), if set. FreezeReason is included in the apply output.
+	Frozen       bool   `json:"frozen,omitempty"`
+	FreezeReason string `json:"freezeReason,omitempty"`
+	FreezeExpiry string `json:"freezeExpiry,omitempty"`
+
+	// Lifecycle is the lifecycle state of the topic; it defaults to active. Replacement is
+	// the topic that clients should use instead of a deprecated one. BlockProduce, which is
+	// only allowed for deprecated and retiring topics, denies produce requests via an ACL.
+	// DeletionDate (an RFC3339 time or date) is required for retiring topics.
+	Lifecycle    TopicLifecycle `json:"lifecycle,omitempty"`
+	Replacement  string         `json:"replacement,omitempty"`
+	BlockProduce bool           `json:"blockProduce,omitempty"`
+	DeletionDate string         `json:"deletionDate,omitempty"`
+}
+
+// Ownership returns the ownership fields set in the topic metadata.
+func (m TopicMeta) Ownership() Ownership {
+	return Ownership{
+		Owner:   m.Owner,
+		Team:    m.Team,
+		Contact: m.Contact,
+	}
 }
 
 // TopicSpec stores the (mutable) specification for a topic.
@@ -97,8 +143,21 @@ type TopicSpec struct {
 	RetentionMinutes  int           `json:"retentionMinutes,omitempty"`
 	Settings          TopicSettings `json:"settings,omitempty"`
 
+	// RetentionTier is the name of one of the retention tiers in the cluster config. It's
+	// an alternative to setting RetentionMinutes or retention.ms directly.
+	RetentionTier string `json:"retentionTier,omitempty"`
+
+	// Keyed indicates that producers partition messages by key. Adding partitions to these
+	// topics changes the key-to-partition mapping, so it requires extra confirmation.
+	Keyed bool `json:"keyed,omitempty"`
+
 	PlacementConfig TopicPlacementConfig  `json:"placement"`
 	MigrationConfig *TopicMigrationConfig `json:"migration,omitempty"`
+	SchemasConfig   *TopicSchemasConfig   `json:"schemas,omitempty"`
+
+	// Hooks are run before and after the topic is created or migrated, after any hooks
+	// in the cluster config.
+	Hooks []HookConfig `json:"hooks,omitempty"`
 }
 
 // TopicPlacementConfig describes how the partition replicas in a topic
@@ -114,6 +173,36 @@ type TopicPlacementConfig struct {
 	// StaticRackAssignments is a list of list of desired replica assignments. It's used
 	// for the "static-in-rack" strategy only.
 	StaticRackAssignments []string `json:"staticRackAssignments,omitempty"`
+
+	// ExcludeBrokers is a list of brokers that shouldn't have any replicas for this topic,
+	// e.g. because they're about to be taken down for maintenance.
+	ExcludeBrokers []int `json:"excludeBrokers,omitempty"`
+
+	// PreferredLeaderBrokers is a list of brokers that should be the leader of each partition
+	// whenever at least one of them is a replica. Leaders can be drained away from a broker by
+	// listing all of the other ones here.
+	PreferredLeaderBrokers []int `json:"preferredLeaderBrokers,omitempty"`
+
+	// RacksPerPartition is the number of distinct racks that the replicas of each partition
+	// should be spread across. Partitions that don't match are reported as rack violations.
+	// It can't be used with the "static" strategy, and the in-rack strategies always
+	// expect exactly one rack.
+	RacksPerPartition int `json:"racksPerPartition,omitempty"`
+
+	// Sticky determines whether placement updates and rebalances keep as many of the
+	// current replicas as possible, only moving the ones needed to satisfy the strategy and
+	// reach the same balance.
+	Sticky bool `json:"sticky,omitempty"`
+
+	// TopicSet is a label shared by a group of co-partitioned topics, e.g. ones that are
+	// joined by key in stream processing jobs. It's used for the "balanced-topic-set"
+	// strategy only.
+	TopicSet string `json:"topicSet,omitempty"`
+
+	// TopicSetAssignments are the replica assignments of the existing topic that the
+	// other members of the topic set follow. These aren't part of the config; they're
+	// filled in at apply time, and are nil if no other topics in the set exist yet.
+	TopicSetAssignments [][]int `json:"-"`
 }
 
 // TopicMigrationConfig configures the throttles and batch sizes used when
@@ -122,6 +211,33 @@ type TopicPlacementConfig struct {
 type TopicMigrationConfig struct {
 	ThrottleMB         int64 `json:"throttleMB"`
 	PartitionBatchSize int   `json:"partitionBatchSize"`
+
+	// Priority determines the order in which topics are migrated when many configs are
+	// applied at once, e.g. in a large rebalance. Topics with higher priorities are applied
+	// first; the default is 0, so negative values can be used to move topics last.
+	Priority int `json:"priority,omitempty"`
+}
+
+// TopicSchemasConfig declares the schema registry subjects associated with the keys and
+// values in a topic, along with the expected compatibility level for these.
+type TopicSchemasConfig struct {
+	KeySubject    string `json:"keySubject,omitempty"`
+	ValueSubject  string `json:"valueSubject,omitempty"`
+	Compatibility string `json:"compatibility,omitempty"`
+}
+
+// Subjects returns the non-empty subjects in this schemas config.
+func (s TopicSchemasConfig) Subjects() []string {
+	subjects := []string{}
+
+	if s.KeySubject != "" {
+		subjects = append(subjects, s.KeySubject)
+	}
+	if s.ValueSubject != "" {
+		subjects = append(subjects, s.ValueSubject)
+	}
+
+	return subjects
 }
 
 // ToNewTopicConfig converts a TopicConfig to a kafka.TopicConfig that can be
@@ -154,6 +270,68 @@ func (t TopicConfig) ToNewTopicConfig() (kafka.TopicConfig, error) {
 	return config, nil
 }
 
+// ResolveClusterDefaults fills in the parts of the topic config that come from the argument
+// cluster config, i.e. its topic defaults and retention tiers, and then sets the remaining
+// defaults. It should be called on each topic config before it's applied or checked.
+func (t *TopicConfig) ResolveClusterDefaults(clusterConfig ClusterConfig) error {
+	t.InheritDefaults(clusterConfig.Spec.TopicDefaults)
+	if err := t.ResolveRetentionTier(clusterConfig.Spec.RetentionTiers); err != nil {
+		return err
+	}
+	t.SetDefaults()
+	return nil
+}
+
+// InheritDefaults fills in the replication factor, retention, placement strategy, picker,
+// and settings of the topic config from the argument cluster-level defaults if the
+// topic config doesn't set them itself. It should be called before SetDefaults.
+func (t *TopicConfig) InheritDefaults(defaults *TopicDefaults) {
+	if defaults == nil {
+		return
+	}
+
+	if t.Spec.ReplicationFactor == 0 {
+		t.Spec.ReplicationFactor = defaults.ReplicationFactor
+	}
+
+	// Retention can be set either via RetentionMinutes or retention.ms; the defaults only
+	// apply if the topic doesn't set it either way.
+	topicSetsRetention := t.Spec.RetentionMinutes > 0 ||
+		t.Spec.Settings.HasKey("retention.ms") ||
+		t.Spec.RetentionTier != ""
+	if !topicSetsRetention {
+		t.Spec.RetentionMinutes = defaults.RetentionMinutes
+	}
+
+	for key, value := range defaults.Settings {
+		if key == "retention.ms" && topicSetsRetention {
+			continue
+		}
+		if t.Spec.Settings.HasKey(key) {
+			continue
+		}
+		if t.Spec.Settings == nil {
+			t.Spec.Settings = TopicSettings{}
+		}
+		t.Spec.Settings[key] = value
+	}
+
+	if t.Spec.PlacementConfig.Strategy == "" {
+		t.Spec.PlacementConfig.Strategy = defaults.PlacementConfig.Strategy
+	}
+	if t.Spec.PlacementConfig.Picker == "" {
+		t.Spec.PlacementConfig.Picker = defaults.PlacementConfig.Picker
+	}
+}
+
+// MigrationPriority returns the migration priority of the topic, or 0 if it isn't set.
+func (t TopicConfig) MigrationPriority() int {
+	if t.Spec.MigrationConfig == nil {
+		return 0
+	}
+	return t.Spec.MigrationConfig.Priority
+}
+
 // SetDefaults sets the default migration and placement settings in a topic config
 // if these aren't set.
 func (t *TopicConfig) SetDefaults() {
@@ -187,6 +365,14 @@ func (t TopicConfig) Validate(numRacks int) error {
 	if t.Meta.Environment == "" {
 		err = multierror.Append(err, errors.New("Environment must be set"))
 	}
+	if t.Meta.FreezeExpiry != "" {
+		if _, expiryErr := ParseFreezeExpiry(t.Meta.FreezeExpiry); expiryErr != nil {
+			err = multierror.Append(err, expiryErr)
+		}
+	}
+	if lifecycleErr := t.Meta.validateLifecycle(); lifecycleErr != nil {
+		err = multierror.Append(err, lifecycleErr)
+	}
 	if t.Spec.Partitions <= 0 {
 		err = multierror.Append(err, errors.New("Partitions must be a positive number"))
 	}
@@ -207,6 +393,39 @@ func (t TopicConfig) Validate(numRacks int) error {
 			errors.New("Cannot set both RetentionMinutes and retention.ms in settings"),
 		)
 	}
+	if t.Spec.RetentionTier != "" &&
+		(t.Spec.RetentionMinutes > 0 || t.Spec.Settings["retention.ms"] != nil) {
+		err = multierror.Append(
+			err,
+			errors.New("Cannot set both RetentionTier and RetentionMinutes or retention.ms"),
+		)
+	}
+
+	if t.Spec.SchemasConfig != nil {
+		if len(t.Spec.SchemasConfig.Subjects()) == 0 {
+			err = multierror.Append(
+				err,
+				errors.New("At least one of keySubject or valueSubject must be set in schemas"),
+			)
+		}
+
+		if t.Spec.SchemasConfig.Compatibility != "" &&
+			!inValues(t.Spec.SchemasConfig.Compatibility, allSchemaCompatibilities...) {
+			err = multierror.Append(
+				err,
+				fmt.Errorf(
+					"Schema compatibility must be in %+v",
+					allSchemaCompatibilities,
+				),
+			)
+		}
+	}
+
+	for _, hook := range t.Spec.Hooks {
+		if hookErr := hook.Validate(); hookErr != nil {
+			err = multierror.Append(err, hookErr)
+		}
+	}
 
 	placement := t.Spec.PlacementConfig
 
@@ -246,8 +465,55 @@ func (t TopicConfig) Validate(numRacks int) error {
 		)
 	}
 
+	for _, brokerID := range placement.ExcludeBrokers {
+		for _, preferredID := range placement.PreferredLeaderBrokers {
+			if brokerID == preferredID {
+				err = multierror.Append(
+					err,
+					fmt.Errorf(
+						"Broker %d cannot be both excluded and a preferred leader",
+						brokerID,
+					),
+				)
+			}
+		}
+	}
+
+	if placement.RacksPerPartition < 0 ||
+		placement.RacksPerPartition > t.Spec.ReplicationFactor {
+		err = multierror.Append(
+			err,
+			fmt.Errorf(
+				"RacksPerPartition must be between 0 and the replication factor (%d)",
+				t.Spec.ReplicationFactor,
+			),
+		)
+	}
+	if numRacks > 0 && placement.RacksPerPartition > numRacks {
+		err = multierror.Append(
+			err,
+			fmt.Errorf(
+				"RacksPerPartition (%d) is greater than the number of racks (%d)",
+				placement.RacksPerPartition,
+				numRacks,
+			),
+		)
+	}
+
+	if (placement.Strategy == PlacementStrategyInRack ||
+		placement.Strategy == PlacementStrategyStaticInRack) &&
+		placement.RacksPerPartition > 1 {
+		err = multierror.Append(
+			err,
+			fmt.Errorf(
+				"RacksPerPartition cannot be greater than 1 for the %s strategy",
+				placement.Strategy,
+			),
+		)
+	}
+
 	switch placement.Strategy {
-	case PlacementStrategyBalancedLeaders:
+	case PlacementStrategyBalancedLeaders, PlacementStrategyBalancedTopicSet:
 		if numRacks > 0 && t.Spec.Partitions%numRacks != 0 {
 			// The balanced-leaders strategy requires that the
 			// partitions be a multiple of the number of racks, otherwise it's impossible
@@ -261,15 +527,27 @@ func (t TopicConfig) Validate(numRacks int) error {
 				),
 			)
 		}
+		if placement.Strategy == PlacementStrategyBalancedTopicSet && placement.TopicSet == "" {
+			err = multierror.Append(
+				err,
+				errors.New("TopicSet must be set for the balanced-topic-set strategy"),
+			)
+		}
 	case PlacementStrategyInRack:
 	case PlacementStrategyStatic:
+		if placement.RacksPerPartition > 0 {
+			err = multierror.Append(
+				err,
+				errors.New("RacksPerPartition cannot be set for the static strategy"),
+			)
+		}
 		if len(placement.StaticAssignments) != t.Spec.Partitions {
 			err = multierror.Append(
 				err,
 				errors.New("Static assignments must be same length as partitions"),
 			)
 		} else {
-			for _, replicas := range placement.StaticAssignments {
+			for p, replicas := range placement.StaticAssignments {
 				if len(replicas) != t.Spec.ReplicationFactor {
 					err = multierror.Append(
 						err,
@@ -277,6 +555,32 @@ func (t TopicConfig) Validate(numRacks int) error {
 					)
 					break
 				}
+
+				if !staticReplicasSatisfyConstraints(replicas, placement) {
+					err = multierror.Append(
+						err,
+						fmt.Errorf(
+							"Static assignment for partition %d is inconsistent with the excluded or preferred leader brokers",
+							p,
+						),
+					)
+				}
+
+				replicasMap := map[int]struct{}{}
+				for _, replica := range replicas {
+					if _, ok := replicasMap[replica]; ok {
+						err = multierror.Append(
+							err,
+							fmt.Errorf(
+								"Static assignment for partition %d contains broker %d more than once",
+								p,
+								replica,
+							),
+						)
+						break
+					}
+					replicasMap[replica] = struct{}{}
+				}
 			}
 		}
 	case PlacementStrategyStaticInRack:
@@ -291,6 +595,7 @@ func (t TopicConfig) Validate(numRacks int) error {
 	// Warn about the partition count in the non-balanced-leaders case
 	if numRacks > 0 &&
 		placement.Strategy != PlacementStrategyBalancedLeaders &&
+		placement.Strategy != PlacementStrategyBalancedTopicSet &&
 		t.Spec.Partitions%numRacks != 0 {
 		log.Warnf("Number of partitions (%d) is not a multiple of the number of racks (%d)",
 			t.Spec.Partitions,
@@ -301,6 +606,103 @@ func (t TopicConfig) Validate(numRacks int) error {
 	return err
 }
 
+// ExpectedRacks returns the number of distinct racks that the replicas of each partition in
+// the topic are expected to be spread across, or 0 if the topic doesn't have any rack
+// expectations.
+func (t TopicConfig) ExpectedRacks() int {
+	switch t.Spec.PlacementConfig.Strategy {
+	case PlacementStrategyInRack, PlacementStrategyStaticInRack:
+		return 1
+	case PlacementStrategyStatic:
+		return 0
+	default:
+		return t.Spec.PlacementConfig.RacksPerPartition
+	}
+}
+
+// staticReplicasSatisfyConstraints returns whether the argument static replicas for a
+// partition are consistent with the broker constraints in the placement config.
+func staticReplicasSatisfyConstraints(replicas []int, placement TopicPlacementConfig) bool {
+	hasPreferred := false
+	for _, replica := range replicas {
+		if inSlice(replica, placement.ExcludeBrokers) {
+			return false
+		}
+		if inSlice(replica, placement.PreferredLeaderBrokers) {
+			hasPreferred = true
+		}
+	}
+
+	return len(replicas) == 0 || !hasPreferred ||
+		inSlice(replicas[0], placement.PreferredLeaderBrokers)
+}
+
+func inSlice(value int, values []int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateBrokers evaluates whether the brokers and racks referenced in the topic's placement
+// config exist in the argument cluster. Unlike Validate, this requires information about the
+// live brokers, so it can only be done when connected to a cluster.
+func (t TopicConfig) ValidateBrokers(brokers []admin.BrokerInfo) error {
+	var err error
+
+	brokerRacks := admin.BrokerRacks(brokers)
+	placement := t.Spec.PlacementConfig
+
+	for _, brokerID := range placement.PreferredLeaderBrokers {
+		if _, ok := brokerRacks[brokerID]; !ok {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Preferred leader broker %d is not in the cluster", brokerID),
+			)
+		}
+	}
+
+	switch placement.Strategy {
+	case PlacementStrategyStatic:
+		for p, replicas := range placement.StaticAssignments {
+			for _, replica := range replicas {
+				if _, ok := brokerRacks[replica]; !ok {
+					err = multierror.Append(
+						err,
+						fmt.Errorf(
+							"Static assignment for partition %d references broker %d, which is not in the cluster",
+							p,
+							replica,
+						),
+					)
+				}
+			}
+		}
+	case PlacementStrategyStaticInRack:
+		racksMap := map[string]struct{}{}
+		for _, rack := range brokerRacks {
+			racksMap[rack] = struct{}{}
+		}
+
+		for p, rack := range placement.StaticRackAssignments {
+			if _, ok := racksMap[rack]; !ok {
+				err = multierror.Append(
+					err,
+					fmt.Errorf(
+						"Static rack assignment for partition %d references rack %s, which has no brokers",
+						p,
+						rack,
+					),
+				)
+			}
+		}
+	}
+
+	return err
+}
+
 // ToYAML converts the current TopicConfig to a YAML string.
 func (t TopicConfig) ToYAML() (string, error) {
 	outBytes, err := yaml.Marshal(t)
@@ -112,6 +112,77 @@ func TestTopicValidate(t *testing.T) {
 			},
 			expError: false,
 		},
+		{
+			description: "all good schemas",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyAny,
+					},
+					SchemasConfig: &TopicSchemasConfig{
+						ValueSubject:  "test-topic-value",
+						Compatibility: "BACKWARD",
+					},
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "schemas without subjects",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyAny,
+					},
+					SchemasConfig: &TopicSchemasConfig{
+						Compatibility: "BACKWARD",
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "schemas invalid compatibility",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyAny,
+					},
+					SchemasConfig: &TopicSchemasConfig{
+						KeySubject:    "test-topic-key",
+						Compatibility: "sideways",
+					},
+				},
+			},
+			expError: true,
+		},
 		{
 			description: "missing meta fields",
 			topicConfig: TopicConfig{
@@ -249,6 +320,190 @@ func TestTopicValidate(t *testing.T) {
 			},
 			expError: true,
 		},
+		{
+			description: "broker excluded and preferred",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy:               PlacementStrategyAny,
+						ExcludeBrokers:         []int{1, 2},
+						PreferredLeaderBrokers: []int{2, 3},
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "static placement with excluded broker",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyStatic,
+						StaticAssignments: [][]int{
+							{1, 2, 3},
+							{4, 5, 6},
+						},
+						ExcludeBrokers: []int{5},
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "static placement with preferred leaders",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyStatic,
+						StaticAssignments: [][]int{
+							{1, 2, 3},
+							{4, 5, 6},
+						},
+						PreferredLeaderBrokers: []int{1, 5},
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "static placement duplicate brokers",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        2,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy: PlacementStrategyStatic,
+						StaticAssignments: [][]int{
+							{1, 2, 3},
+							{4, 5, 4},
+						},
+					},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "racks per partition",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        6,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy:          PlacementStrategyBalancedLeaders,
+						RacksPerPartition: 2,
+					},
+				},
+			},
+			numRacks: 3,
+			expError: false,
+		},
+		{
+			description: "racks per partition greater than replication factor",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        6,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy:          PlacementStrategyAny,
+						RacksPerPartition: 4,
+					},
+				},
+			},
+			numRacks: 6,
+			expError: true,
+		},
+		{
+			description: "racks per partition greater than rack count",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        6,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy:          PlacementStrategyAny,
+						RacksPerPartition: 3,
+					},
+				},
+			},
+			numRacks: 2,
+			expError: true,
+		},
+		{
+			description: "racks per partition with in-rack placement",
+			topicConfig: TopicConfig{
+				Meta: TopicMeta{
+					Name:        "test-topic",
+					Cluster:     "test-cluster",
+					Region:      "test-region",
+					Environment: "test-environment",
+					Description: "Bootstrapped via topicctl bootstrap",
+				},
+				Spec: TopicSpec{
+					Partitions:        6,
+					ReplicationFactor: 3,
+					PlacementConfig: TopicPlacementConfig{
+						Strategy:          PlacementStrategyInRack,
+						RacksPerPartition: 2,
+					},
+				},
+			},
+			numRacks: 3,
+			expError: true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -262,6 +517,179 @@ func TestTopicValidate(t *testing.T) {
 	}
 }
 
+func TestTopicInheritDefaults(t *testing.T) {
+	defaults := &TopicDefaults{
+		ReplicationFactor: 3,
+		RetentionMinutes:  1440,
+		Settings: TopicSettings{
+			"cleanup.policy":      "delete",
+			"min.insync.replicas": 2,
+		},
+		PlacementConfig: TopicPlacementDefaults{
+			Strategy: PlacementStrategyInRack,
+			Picker:   PickerMethodClusterUse,
+		},
+	}
+
+	topicConfig := TopicConfig{
+		Spec: TopicSpec{
+			Partitions: 10,
+		},
+	}
+	topicConfig.InheritDefaults(defaults)
+	assert.Equal(
+		t,
+		TopicSpec{
+			Partitions:        10,
+			ReplicationFactor: 3,
+			RetentionMinutes:  1440,
+			Settings: TopicSettings{
+				"cleanup.policy":      "delete",
+				"min.insync.replicas": 2,
+			},
+			PlacementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyInRack,
+				Picker:   PickerMethodClusterUse,
+			},
+		},
+		topicConfig.Spec,
+	)
+
+	// Values set in the topic config take precedence
+	topicConfig = TopicConfig{
+		Spec: TopicSpec{
+			Partitions:        10,
+			ReplicationFactor: 2,
+			Settings: TopicSettings{
+				"min.insync.replicas": 1,
+				"retention.ms":        3600000,
+			},
+			PlacementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyAny,
+			},
+		},
+	}
+	topicConfig.InheritDefaults(defaults)
+	assert.Equal(
+		t,
+		TopicSpec{
+			Partitions:        10,
+			ReplicationFactor: 2,
+			Settings: TopicSettings{
+				"cleanup.policy":      "delete",
+				"min.insync.replicas": 1,
+				"retention.ms":        3600000,
+			},
+			PlacementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyAny,
+				Picker:   PickerMethodClusterUse,
+			},
+		},
+		topicConfig.Spec,
+	)
+
+	// Nil defaults are a no-op
+	topicConfig = TopicConfig{}
+	topicConfig.InheritDefaults(nil)
+	assert.Equal(t, TopicConfig{}, topicConfig)
+}
+
+func TestTopicExpectedRacks(t *testing.T) {
+	topicConfig := TopicConfig{
+		Spec: TopicSpec{
+			PlacementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyBalancedLeaders,
+			},
+		},
+	}
+	assert.Equal(t, 0, topicConfig.ExpectedRacks())
+
+	topicConfig.Spec.PlacementConfig.RacksPerPartition = 2
+	assert.Equal(t, 2, topicConfig.ExpectedRacks())
+
+	topicConfig.Spec.PlacementConfig.Strategy = PlacementStrategyInRack
+	assert.Equal(t, 1, topicConfig.ExpectedRacks())
+
+	topicConfig.Spec.PlacementConfig.Strategy = PlacementStrategyStatic
+	assert.Equal(t, 0, topicConfig.ExpectedRacks())
+}
+
+func TestTopicValidateBrokers(t *testing.T) {
+	brokers := []admin.BrokerInfo{
+		{ID: 1, Rack: "zone1"},
+		{ID: 2, Rack: "zone2"},
+		{ID: 3, Rack: "zone3"},
+	}
+
+	type testCase struct {
+		description     string
+		placementConfig TopicPlacementConfig
+		expError        bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "any placement",
+			placementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyAny,
+			},
+			expError: false,
+		},
+		{
+			description: "static placement with live brokers",
+			placementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyStatic,
+				StaticAssignments: [][]int{
+					{1, 2},
+					{3, 1},
+				},
+			},
+			expError: false,
+		},
+		{
+			description: "static placement with missing broker",
+			placementConfig: TopicPlacementConfig{
+				Strategy: PlacementStrategyStatic,
+				StaticAssignments: [][]int{
+					{1, 2},
+					{3, 4},
+				},
+			},
+			expError: true,
+		},
+		{
+			description: "static-in-rack placement with live racks",
+			placementConfig: TopicPlacementConfig{
+				Strategy:              PlacementStrategyStaticInRack,
+				StaticRackAssignments: []string{"zone1", "zone3"},
+			},
+			expError: false,
+		},
+		{
+			description: "static-in-rack placement with missing rack",
+			placementConfig: TopicPlacementConfig{
+				Strategy:              PlacementStrategyStaticInRack,
+				StaticRackAssignments: []string{"zone1", "zone4"},
+			},
+			expError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		topicConfig := TopicConfig{
+			Spec: TopicSpec{
+				PlacementConfig: testCase.placementConfig,
+			},
+		}
+		err := topicConfig.ValidateBrokers(brokers)
+		if testCase.expError {
+			assert.Error(t, err, testCase.description)
+		} else {
+			assert.NoError(t, err, testCase.description)
+		}
+	}
+}
+
 func TestTopicConfigFromTopicInfo(t *testing.T) {
 	type testCase struct {
 		description    string
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// maxWindowSearch is how far ahead NextMaintenanceWindow looks for the start of a window.
+const maxWindowSearch = 366 * 24 * time.Hour
+
+// MaintenanceWindow is a recurring period during which apply is allowed to move data between
+// brokers.
+type MaintenanceWindow struct {
+	// Schedule is a five-field cron expression, e.g. "0 2 * * 1-5", for the times at which
+	// the window opens.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open each time, e.g. "4h".
+	Duration string `json:"duration"`
+
+	// Timezone is the IANA name of the timezone that the schedule is evaluated in, e.g.
+	// "America/Los_Angeles". If unset, UTC is used.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Validate evaluates whether the window is valid.
+func (w MaintenanceWindow) Validate() error {
+	_, _, _, err := w.parse()
+	return err
+}
+
+// Contains returns whether the argument time falls inside an occurrence of the window.
+func (w MaintenanceWindow) Contains(now time.Time) (bool, error) {
+	schedule, duration, location, err := w.parse()
+	if err != nil {
+		return false, err
+	}
+
+	// Walk back, one minute at a time, over the starts of the windows that could still be
+	// open
+	start := now.In(location).Truncate(time.Minute)
+	for elapsed := time.Duration(0); elapsed < duration; elapsed += time.Minute {
+		candidate := start.Add(-elapsed)
+		if schedule.Matches(candidate) && now.Sub(candidate) < duration {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NextStart returns the next time after the argument one at which the window opens. It
+// returns the zero time if the window doesn't open in the next year.
+func (w MaintenanceWindow) NextStart(now time.Time) (time.Time, error) {
+	schedule, _, location, err := w.parse()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start := now.In(location).Truncate(time.Minute).Add(time.Minute)
+	for elapsed := time.Duration(0); elapsed < maxWindowSearch; elapsed += time.Minute {
+		candidate := start.Add(elapsed)
+		if schedule.Matches(candidate) {
+			return candidate, nil
+		}
+	}
+
+	return time.Time{}, nil
+}
+
+func (w MaintenanceWindow) parse() (util.CronSchedule, time.Duration, *time.Location, error) {
+	var err error
+
+	schedule, scheduleErr := util.ParseCronSchedule(w.Schedule)
+	if scheduleErr != nil {
+		err = multierror.Append(err, scheduleErr)
+	}
+
+	duration, durationErr := util.ParseDuration(w.Duration)
+	if durationErr != nil {
+		err = multierror.Append(
+			err,
+			fmt.Errorf("Invalid maintenance window duration %s: %+v", w.Duration, durationErr),
+		)
+	} else if duration < time.Minute {
+		err = multierror.Append(
+			err,
+			errors.New("Maintenance window duration must be at least 1m"),
+		)
+	}
+
+	location := time.UTC
+	if w.Timezone != "" {
+		var locationErr error
+		location, locationErr = time.LoadLocation(w.Timezone)
+		if locationErr != nil {
+			err = multierror.Append(
+				err,
+				fmt.Errorf("Invalid maintenance window timezone %s: %+v", w.Timezone, locationErr),
+			)
+		}
+	}
+
+	return schedule, duration, location, err
+}
+
+// InMaintenanceWindow returns whether the argument time is inside one of the cluster's
+// maintenance windows. Clusters without any windows are always considered to be in one.
+func (c ClusterConfig) InMaintenanceWindow(now time.Time) (bool, error) {
+	if len(c.Spec.MaintenanceWindows) == 0 {
+		return true, nil
+	}
+
+	for _, window := range c.Spec.MaintenanceWindows {
+		ok, err := window.Contains(now)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NextMaintenanceWindow returns the earliest time after the argument one at which one of the
+// cluster's maintenance windows opens, or the zero time if there isn't one in the next year.
+func (c ClusterConfig) NextMaintenanceWindow(now time.Time) (time.Time, error) {
+	var next time.Time
+
+	for _, window := range c.Spec.MaintenanceWindows {
+		start, err := window.NextStart(now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	return next, nil
+}
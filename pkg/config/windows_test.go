@@ -0,0 +1,91 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindows(t *testing.T) {
+	clusterConfig := ClusterConfig{
+		Spec: ClusterSpec{
+			MaintenanceWindows: []MaintenanceWindow{
+				{
+					// 2am to 6am on weekdays in New York
+					Schedule: "0 2 * * 1-5",
+					Duration: "4h",
+					Timezone: "America/New_York",
+				},
+			},
+		},
+	}
+	require.NoError(t, clusterConfig.Spec.MaintenanceWindows[0].Validate())
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	type testCase struct {
+		description  string
+		time         time.Time
+		expected     bool
+		expectedNext time.Time
+	}
+
+	// 2021-03-01 is a Monday
+	testCases := []testCase{
+		{
+			description:  "inside window",
+			time:         time.Date(2021, 3, 1, 3, 30, 0, 0, newYork),
+			expected:     true,
+			expectedNext: time.Date(2021, 3, 2, 2, 0, 0, 0, newYork),
+		},
+		{
+			description:  "inside window in UTC",
+			time:         time.Date(2021, 3, 1, 7, 0, 0, 0, time.UTC),
+			expected:     true,
+			expectedNext: time.Date(2021, 3, 2, 2, 0, 0, 0, newYork),
+		},
+		{
+			description:  "at end of window",
+			time:         time.Date(2021, 3, 1, 6, 0, 0, 0, newYork),
+			expected:     false,
+			expectedNext: time.Date(2021, 3, 2, 2, 0, 0, 0, newYork),
+		},
+		{
+			description:  "weekend",
+			time:         time.Date(2021, 3, 6, 3, 0, 0, 0, newYork),
+			expected:     false,
+			expectedNext: time.Date(2021, 3, 8, 2, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, testCase := range testCases {
+		ok, err := clusterConfig.InMaintenanceWindow(testCase.time)
+		require.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.expected, ok, testCase.description)
+
+		next, err := clusterConfig.NextMaintenanceWindow(testCase.time)
+		require.NoError(t, err, testCase.description)
+		assert.True(
+			t,
+			testCase.expectedNext.Equal(next),
+			"%s: expected %s, got %s",
+			testCase.description,
+			testCase.expectedNext,
+			next,
+		)
+	}
+
+	ok, err := ClusterConfig{}.InMaintenanceWindow(time.Now())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	invalid := MaintenanceWindow{
+		Schedule: "0 2 * *",
+		Duration: "30s",
+		Timezone: "Mars/Olympus_Mons",
+	}
+	assert.Error(t, invalid.Validate())
+}
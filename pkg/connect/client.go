@@ -0,0 +1,228 @@
+package connect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrConnectorNotFound is returned when a connector doesn't exist in the Connect cluster.
+var ErrConnectorNotFound = errors.New("Connector not found")
+
+// Client is a minimal client for the Kafka Connect REST API.
+type Client struct {
+	connectURL string
+	httpClient *http.Client
+}
+
+// NewClient returns a new Client instance for the Connect cluster at the argument URL.
+func NewClient(connectURL string) *Client {
+	return &Client{
+		connectURL: strings.TrimRight(connectURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// ConnectorStatus stores the state of a connector and its tasks.
+type ConnectorStatus struct {
+	Name      string      `json:"name"`
+	Connector WorkerState `json:"connector"`
+	Tasks     []TaskState `json:"tasks"`
+	Type      string      `json:"type"`
+}
+
+// WorkerState stores the state of a connector on a specific worker.
+type WorkerState struct {
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+// TaskState stores the state of a single connector task.
+type TaskState struct {
+	ID       int    `json:"id"`
+	State    string `json:"state"`
+	WorkerID string `json:"worker_id"`
+	Trace    string `json:"trace,omitempty"`
+}
+
+// Running returns whether the connector and all of its tasks are in the RUNNING state.
+func (c ConnectorStatus) Running() bool {
+	if c.Connector.State != "RUNNING" {
+		return false
+	}
+	for _, task := range c.Tasks {
+		if task.State != "RUNNING" {
+			return false
+		}
+	}
+	return true
+}
+
+// NumTasksRunning returns the number of tasks in the RUNNING state.
+func (c ConnectorStatus) NumTasksRunning() int {
+	count := 0
+	for _, task := range c.Tasks {
+		if task.State == "RUNNING" {
+			count++
+		}
+	}
+	return count
+}
+
+// GetConnectorNames returns the names of all connectors in the Connect cluster, in
+// sorted order.
+func (c *Client) GetConnectorNames(ctx context.Context) ([]string, error) {
+	names := []string{}
+	if err := c.do(ctx, http.MethodGet, "/connectors", nil, &names); err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetConnectorConfig returns the current config for the argument connector. If the
+// connector doesn't exist, then ErrConnectorNotFound is returned.
+func (c *Client) GetConnectorConfig(
+	ctx context.Context,
+	name string,
+) (map[string]string, error) {
+	config := map[string]string{}
+	err := c.do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/connectors/%s/config", url.PathEscape(name)),
+		nil,
+		&config,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// GetConnectorStatus returns the status of the argument connector and its tasks.
+func (c *Client) GetConnectorStatus(
+	ctx context.Context,
+	name string,
+) (ConnectorStatus, error) {
+	status := ConnectorStatus{}
+	err := c.do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/connectors/%s/status", url.PathEscape(name)),
+		nil,
+		&status,
+	)
+	return status, err
+}
+
+// PutConnectorConfig creates the argument connector if it doesn't exist, or replaces its
+// config if it does.
+func (c *Client) PutConnectorConfig(
+	ctx context.Context,
+	name string,
+	config map[string]string,
+) error {
+	return c.do(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("/connectors/%s/config", url.PathEscape(name)),
+		config,
+		nil,
+	)
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	body interface{},
+	result interface{},
+) error {
+	reqBody := &bytes.Buffer{}
+
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, c.connectURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrConnectorNotFound
+	} else if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf(
+			"Connect request %s %s failed with status %d: %s",
+			method,
+			path,
+			resp.StatusCode,
+			string(respBytes),
+		)
+	}
+
+	if result != nil {
+		return json.Unmarshal(respBytes, result)
+	}
+	return nil
+}
+
+// ConfigDiffs compares a desired connector config to the current one from the Connect
+// cluster, returning the keys whose values differ (including keys that are only in one
+// of the two), in sorted order. The "name" key is ignored since it's set by the
+// Connect cluster.
+func ConfigDiffs(desired map[string]string, current map[string]string) []string {
+	diffKeys := []string{}
+
+	for key, value := range desired {
+		if key == "name" {
+			continue
+		}
+		if currValue, ok := current[key]; !ok || currValue != value {
+			diffKeys = append(diffKeys, key)
+		}
+	}
+	for key := range current {
+		if key == "name" {
+			continue
+		}
+		if _, ok := desired[key]; !ok {
+			diffKeys = append(diffKeys, key)
+		}
+	}
+
+	sort.Strings(diffKeys)
+	return diffKeys
+}
@@ -0,0 +1,103 @@
+package connect
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	configs := map[string]map[string]string{
+		"connector-a": {
+			"name":            "connector-a",
+			"connector.class": "FileStreamSink",
+		},
+	}
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/connectors":
+				json.NewEncoder(w).Encode([]string{"connector-b", "connector-a"})
+			case r.Method == http.MethodGet && r.URL.Path == "/connectors/connector-a/config":
+				json.NewEncoder(w).Encode(configs["connector-a"])
+			case r.Method == http.MethodGet && r.URL.Path == "/connectors/connector-a/status":
+				w.Write(
+					[]byte(`{"name":"connector-a","connector":{"state":"RUNNING","worker_id":"w1"},"tasks":[{"id":0,"state":"RUNNING","worker_id":"w1"},{"id":1,"state":"FAILED","worker_id":"w2"}],"type":"sink"}`),
+				)
+			case r.Method == http.MethodPut && r.URL.Path == "/connectors/connector-c/config":
+				body, _ := ioutil.ReadAll(r.Body)
+				config := map[string]string{}
+				json.Unmarshal(body, &config)
+				configs["connector-c"] = config
+				w.WriteHeader(http.StatusCreated)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error_code":404,"message":"Connector not found"}`))
+			}
+		}),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL)
+
+	names, err := client.GetConnectorNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"connector-a", "connector-b"}, names)
+
+	config, err := client.GetConnectorConfig(ctx, "connector-a")
+	require.NoError(t, err)
+	assert.Equal(t, configs["connector-a"], config)
+
+	_, err = client.GetConnectorConfig(ctx, "connector-c")
+	assert.Equal(t, ErrConnectorNotFound, err)
+
+	status, err := client.GetConnectorStatus(ctx, "connector-a")
+	require.NoError(t, err)
+	assert.Equal(t, "sink", status.Type)
+	assert.Equal(t, 1, status.NumTasksRunning())
+	assert.False(t, status.Running())
+
+	err = client.PutConnectorConfig(
+		ctx,
+		"connector-c",
+		map[string]string{"connector.class": "FileStreamSource"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"connector.class": "FileStreamSource"}, configs["connector-c"])
+}
+
+func TestConfigDiffs(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"file", "tasks.max", "topics"},
+		ConfigDiffs(
+			map[string]string{
+				"connector.class": "FileStreamSink",
+				"tasks.max":       "2",
+				"topics":          "topic-a",
+			},
+			map[string]string{
+				"name":            "connector-a",
+				"connector.class": "FileStreamSink",
+				"tasks.max":       "1",
+				"file":            "/tmp/out.txt",
+			},
+		),
+	)
+	assert.Equal(
+		t,
+		[]string{},
+		ConfigDiffs(
+			map[string]string{"connector.class": "FileStreamSink"},
+			map[string]string{"name": "a", "connector.class": "FileStreamSink"},
+		),
+	)
+}
@@ -0,0 +1,106 @@
+package connect
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// FormatConnectorStatuses generates a pretty table that summarizes the states of the
+// argument connectors.
+func FormatConnectorStatuses(statuses []ConnectorStatus) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Name",
+			"Type",
+			"State",
+			"Worker",
+			"Tasks\nRunning",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, status := range statuses {
+		table.Append(
+			[]string{
+				status.Name,
+				status.Type,
+				status.Connector.State,
+				status.Connector.WorkerID,
+				fmt.Sprintf("%d/%d", status.NumTasksRunning(), len(status.Tasks)),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatConfigDiff generates a table that summarizes the differences between the
+// config of a connector in the Connect cluster and the config in a connector config file.
+func FormatConfigDiff(
+	desired map[string]string,
+	current map[string]string,
+	diffKeys []string,
+) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Key",
+			"Cluster Value (Curr)",
+			"Config Value (New)",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, diffKey := range diffKeys {
+		table.Append(
+			[]string{
+				diffKey,
+				current[diffKey],
+				desired[diffKey],
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
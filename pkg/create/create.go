@@ -0,0 +1,291 @@
+// Package create contains the logic behind the interactive topic config wizard. It asks
+// a few high-level questions about a new topic and converts the answers into a topic config
+// that follows the defaults of the target cluster.
+package create
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+const (
+	// PartitionThroughputMBs is the peak throughput, in MB/sec, that each partition is sized
+	// for.
+	PartitionThroughputMBs = 5.0
+
+	// Keyed topics are given extra partitions up front because adding partitions later
+	// changes the key-to-partition mapping.
+	keyedPartitionMultiplier = 2
+
+	minPartitions            = 3
+	defaultReplicationFactor = 3
+	defaultRetentionHours    = 24
+)
+
+// Answers contains the user's responses to the wizard questions.
+type Answers struct {
+	Name           string
+	Description    string
+	ThroughputMBs  float64
+	RetentionHours int
+	Keyed          bool
+}
+
+// Prompter asks questions on a writer and reads the responses, one per line, from a reader.
+type Prompter struct {
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+// NewPrompter returns a new Prompter instance.
+func NewPrompter(reader io.Reader, writer io.Writer) *Prompter {
+	return &Prompter{
+		reader: bufio.NewReader(reader),
+		writer: writer,
+	}
+}
+
+// Ask asks the argument question and returns the trimmed response. If the response is
+// empty, the default value is returned instead.
+func (p *Prompter) Ask(question string, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.writer, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.writer, "%s: ", question)
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	response := strings.TrimSpace(line)
+	if response == "" {
+		return defaultValue, nil
+	}
+	return response, nil
+}
+
+// AskValid asks the argument question until the response passes the argument validation
+// function.
+func (p *Prompter) AskValid(
+	question string,
+	defaultValue string,
+	validate func(string) error,
+) (string, error) {
+	for {
+		response, err := p.Ask(question, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(response); err != nil {
+			fmt.Fprintf(p.writer, "Invalid response: %+v\n", err)
+			continue
+		}
+		return response, nil
+	}
+}
+
+// AskInt asks the argument question until the response is a non-negative integer.
+func (p *Prompter) AskInt(question string, defaultValue int) (int, error) {
+	response, err := p.AskValid(
+		question,
+		fmt.Sprintf("%d", defaultValue),
+		func(response string) error {
+			value, err := strconv.Atoi(response)
+			if err != nil || value < 0 {
+				return errors.New("Must be a non-negative integer")
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(response)
+}
+
+// AskFloat asks the argument question until the response is a non-negative number.
+func (p *Prompter) AskFloat(question string, defaultValue float64) (float64, error) {
+	response, err := p.AskValid(
+		question,
+		strconv.FormatFloat(defaultValue, 'f', -1, 64),
+		func(response string) error {
+			value, err := strconv.ParseFloat(response, 64)
+			if err != nil || value < 0 {
+				return errors.New("Must be a non-negative number")
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(response, 64)
+}
+
+// AskBool asks the argument yes/no question until the response is one of these.
+func (p *Prompter) AskBool(question string, defaultValue bool) (bool, error) {
+	defaultStr := "no"
+	if defaultValue {
+		defaultStr = "yes"
+	}
+
+	response, err := p.AskValid(
+		fmt.Sprintf("%s (yes/no)", question),
+		defaultStr,
+		func(response string) error {
+			if _, ok := parseBool(response); !ok {
+				return errors.New("Must be yes or no")
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	value, _ := parseBool(response)
+	return value, nil
+}
+
+// AskTopicAnswers runs through the wizard questions for a new topic. The argument cluster
+// config is used to pick the default retention, and the name validation function, if
+// non-nil, is used to reject names that are invalid or already taken.
+func AskTopicAnswers(
+	prompter *Prompter,
+	clusterConfig config.ClusterConfig,
+	validateName func(string) error,
+) (Answers, error) {
+	answers := Answers{}
+	var err error
+
+	answers.Name, err = prompter.AskValid(
+		"Topic name",
+		"",
+		func(name string) error {
+			if name == "" {
+				return errors.New("Name must be set")
+			}
+			if validateName != nil {
+				return validateName(name)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return answers, err
+	}
+
+	answers.Description, err = prompter.Ask("Description (optional)", "")
+	if err != nil {
+		return answers, err
+	}
+
+	answers.ThroughputMBs, err = prompter.AskFloat("Expected peak throughput in MB/sec", 1)
+	if err != nil {
+		return answers, err
+	}
+
+	retentionHours := defaultRetentionHours
+	if defaults := clusterConfig.Spec.TopicDefaults; defaults != nil &&
+		defaults.RetentionMinutes > 0 {
+		retentionHours = int(math.Ceil(float64(defaults.RetentionMinutes) / 60.0))
+	}
+
+	for {
+		answers.RetentionHours, err = prompter.AskInt("Retention in hours", retentionHours)
+		if err != nil {
+			return answers, err
+		}
+		if answers.RetentionHours > 0 {
+			break
+		}
+		fmt.Fprintln(prompter.writer, "Invalid response: Retention must be positive")
+	}
+
+	answers.Keyed, err = prompter.AskBool(
+		"Do producers partition messages by key?",
+		false,
+	)
+	if err != nil {
+		return answers, err
+	}
+
+	return answers, nil
+}
+
+// GenerateTopicConfig converts the argument answers into a topic config for the argument
+// cluster. The number of partitions is based on the throughput estimate and rounded up to a
+// multiple of the number of racks, if this is positive. The replication factor and
+// placement strategy come from the cluster's topic defaults if these are set.
+func GenerateTopicConfig(
+	clusterConfig config.ClusterConfig,
+	answers Answers,
+	numRacks int,
+) config.TopicConfig {
+	replicationFactor := defaultReplicationFactor
+	strategy := config.PlacementStrategyBalancedLeaders
+
+	if defaults := clusterConfig.Spec.TopicDefaults; defaults != nil {
+		if defaults.ReplicationFactor > 0 {
+			replicationFactor = defaults.ReplicationFactor
+		}
+		if defaults.PlacementConfig.Strategy != "" {
+			strategy = defaults.PlacementConfig.Strategy
+		}
+	}
+
+	return config.TopicConfig{
+		Meta: config.TopicMeta{
+			Name:        answers.Name,
+			Cluster:     clusterConfig.Meta.Name,
+			Region:      clusterConfig.Meta.Region,
+			Environment: clusterConfig.Meta.Environment,
+			Description: answers.Description,
+		},
+		Spec: config.TopicSpec{
+			Partitions:        NumPartitions(answers.ThroughputMBs, answers.Keyed, numRacks),
+			ReplicationFactor: replicationFactor,
+			RetentionMinutes:  answers.RetentionHours * 60,
+			Keyed:             answers.Keyed,
+			PlacementConfig: config.TopicPlacementConfig{
+				Strategy: strategy,
+			},
+		},
+	}
+}
+
+// NumPartitions returns the suggested number of partitions for a topic with the argument
+// peak throughput.
+func NumPartitions(throughputMBs float64, keyed bool, numRacks int) int {
+	partitions := int(math.Ceil(throughputMBs / PartitionThroughputMBs))
+	if keyed {
+		partitions *= keyedPartitionMultiplier
+	}
+	if partitions < minPartitions {
+		partitions = minPartitions
+	}
+	if numRacks > 0 && partitions%numRacks != 0 {
+		partitions += numRacks - partitions%numRacks
+	}
+	return partitions
+}
+
+func parseBool(response string) (bool, bool) {
+	switch strings.ToLower(response) {
+	case "y", "yes":
+		return true, true
+	case "n", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,140 @@
+package create
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAskTopicAnswers(t *testing.T) {
+	clusterConfig := config.ClusterConfig{
+		Meta: config.ClusterMeta{
+			Name:        "test-cluster",
+			Region:      "test-region",
+			Environment: "test-env",
+		},
+		Spec: config.ClusterSpec{
+			TopicDefaults: &config.TopicDefaults{
+				RetentionMinutes: 2880,
+			},
+		},
+	}
+
+	input := strings.Join(
+		[]string{
+			"",
+			"existing-topic",
+			"new-topic",
+			"My new topic",
+			"lots",
+			"12.5",
+			"",
+			"maybe",
+			"yes",
+		},
+		"\n",
+	)
+	output := &bytes.Buffer{}
+
+	answers, err := AskTopicAnswers(
+		NewPrompter(strings.NewReader(input), output),
+		clusterConfig,
+		func(name string) error {
+			if name == "existing-topic" {
+				return errors.New("Topic already exists")
+			}
+			return nil
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		Answers{
+			Name:           "new-topic",
+			Description:    "My new topic",
+			ThroughputMBs:  12.5,
+			RetentionHours: 48,
+			Keyed:          true,
+		},
+		answers,
+	)
+	assert.Contains(t, output.String(), "Retention in hours [48]")
+	assert.Contains(t, output.String(), "Invalid response: Topic already exists")
+
+	// Running out of input is an error
+	_, err = AskTopicAnswers(
+		NewPrompter(strings.NewReader("new-topic\n"), output),
+		clusterConfig,
+		nil,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestGenerateTopicConfig(t *testing.T) {
+	clusterConfig := config.ClusterConfig{
+		Meta: config.ClusterMeta{
+			Name:        "test-cluster",
+			Region:      "test-region",
+			Environment: "test-env",
+		},
+	}
+	answers := Answers{
+		Name:           "new-topic",
+		ThroughputMBs:  12.5,
+		RetentionHours: 48,
+		Keyed:          true,
+	}
+
+	topicConfig := GenerateTopicConfig(clusterConfig, answers, 3)
+	assert.Equal(
+		t,
+		config.TopicConfig{
+			Meta: config.TopicMeta{
+				Name:        "new-topic",
+				Cluster:     "test-cluster",
+				Region:      "test-region",
+				Environment: "test-env",
+			},
+			Spec: config.TopicSpec{
+				Partitions:        6,
+				ReplicationFactor: 3,
+				RetentionMinutes:  2880,
+				Keyed:             true,
+				PlacementConfig: config.TopicPlacementConfig{
+					Strategy: config.PlacementStrategyBalancedLeaders,
+				},
+			},
+		},
+		topicConfig,
+	)
+	topicConfig.SetDefaults()
+	assert.Nil(t, topicConfig.Validate(3))
+
+	clusterConfig.Spec.TopicDefaults = &config.TopicDefaults{
+		ReplicationFactor: 2,
+		PlacementConfig: config.TopicPlacementDefaults{
+			Strategy: config.PlacementStrategyInRack,
+		},
+	}
+	topicConfig = GenerateTopicConfig(clusterConfig, answers, 3)
+	assert.Equal(t, 2, topicConfig.Spec.ReplicationFactor)
+	assert.Equal(
+		t,
+		config.PlacementStrategyInRack,
+		topicConfig.Spec.PlacementConfig.Strategy,
+	)
+}
+
+func TestNumPartitions(t *testing.T) {
+	assert.Equal(t, 3, NumPartitions(0, false, 0))
+	assert.Equal(t, 3, NumPartitions(1, true, 0))
+	assert.Equal(t, 4, NumPartitions(1, false, 4))
+	assert.Equal(t, 5, NumPartitions(21, false, 0))
+	assert.Equal(t, 6, NumPartitions(21, false, 3))
+	assert.Equal(t, 10, NumPartitions(21, true, 0))
+}
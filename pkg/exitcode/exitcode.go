@@ -0,0 +1,104 @@
+// Package exitcode classifies the errors returned by topicctl commands so that automation
+// can branch on the type of failure via the process exit code instead of parsing log output.
+package exitcode
+
+import (
+	"errors"
+)
+
+// Kind is a string type that stores the category of an error.
+type Kind string
+
+const (
+	// KindUnknown is used for errors that haven't been classified.
+	KindUnknown Kind = "unknown"
+
+	// KindConfig is used for configs or command-line arguments that can't be loaded or
+	// parsed.
+	KindConfig Kind = "config"
+
+	// KindValidation is used for configs that were loaded but are invalid, e.g. because
+	// required fields are missing or they aren't consistent with the cluster config.
+	KindValidation Kind = "validation"
+
+	// KindDrift is used when the state of a cluster doesn't match its configs.
+	KindDrift Kind = "drift"
+
+	// KindUnreachable is used when the cluster can't be reached.
+	KindUnreachable Kind = "unreachable"
+
+	// KindPartialApply is used when an apply of multiple configs failed after some of them
+	// were already applied.
+	KindPartialApply Kind = "partial-apply"
+)
+
+var kindCodes = map[Kind]int{
+	KindUnknown:      1,
+	KindConfig:       2,
+	KindValidation:   3,
+	KindDrift:        4,
+	KindUnreachable:  5,
+	KindPartialApply: 6,
+}
+
+// Error is an error with an associated kind.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns an error of the argument kind that wraps the argument error. It returns nil
+// if the error is nil.
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{
+		Kind: kind,
+		Err:  err,
+	}
+}
+
+// KindOf returns the kind of the argument error. If it's wrapped more than once, the
+// outermost kind is used.
+func KindOf(err error) Kind {
+	var kindErr *Error
+	if errors.As(err, &kindErr) {
+		return kindErr.Kind
+	}
+	return KindUnknown
+}
+
+// Code returns the process exit code for the argument error, or 0 if it's nil.
+func Code(err error) int {
+	if err == nil {
+		return 0
+	}
+	return kindCodes[KindOf(err)]
+}
+
+// JSONError is the structured representation of an error that's output in JSON errors mode.
+type JSONError struct {
+	Error    string `json:"error"`
+	Kind     Kind   `json:"kind"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// ToJSONError converts the argument (non-nil) error to a JSONError.
+func ToJSONError(err error) JSONError {
+	return JSONError{
+		Error:    err.Error(),
+		Kind:     KindOf(err),
+		ExitCode: Code(err),
+	}
+}
@@ -0,0 +1,38 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, 0, Code(nil))
+	assert.Nil(t, Wrap(KindConfig, nil))
+
+	err := errors.New("test error")
+	assert.Equal(t, 1, Code(err))
+	assert.Equal(t, KindUnknown, KindOf(err))
+
+	configErr := Wrap(KindConfig, err)
+	assert.Equal(t, "test error", configErr.Error())
+	assert.Equal(t, 2, Code(configErr))
+	assert.True(t, errors.Is(configErr, err))
+
+	// Kinds are found through other wrappers, and the outermost kind wins
+	wrappedErr := fmt.Errorf("Wrapped: %w", Wrap(KindDrift, configErr))
+	assert.Equal(t, KindDrift, KindOf(wrappedErr))
+	assert.Equal(t, 4, Code(wrappedErr))
+
+	assert.Equal(
+		t,
+		JSONError{
+			Error:    "Wrapped: test error",
+			Kind:     KindDrift,
+			ExitCode: 4,
+		},
+		ToJSONError(wrappedErr),
+	)
+}
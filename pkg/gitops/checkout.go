@@ -0,0 +1,79 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Checkout clones the argument git repo into dir and checks out the commit that the argument
+// ref points to. The ref can be a branch, tag, or commit SHA. It returns the full SHA of the
+// checked-out commit.
+//
+// The clone is done by shelling out to the git binary so that the user's existing
+// credentials and ssh configuration are used.
+func Checkout(ctx context.Context, repoURL string, ref string, dir string) (string, error) {
+	log.Infof("Cloning %s into %s", repoURL, dir)
+	if _, err := runGit(ctx, "", "clone", "--quiet", "--no-checkout", repoURL, dir); err != nil {
+		return "", err
+	}
+
+	sha, err := resolveRef(ctx, dir, ref)
+	if err != nil {
+		return "", err
+	}
+
+	log.Infof("Checking out %s (%s)", ref, sha)
+	if _, err := runGit(ctx, dir, "checkout", "--quiet", "--detach", sha); err != nil {
+		return "", err
+	}
+
+	return sha, nil
+}
+
+// resolveRef returns the SHA of the commit that the argument ref points to. Branches only
+// exist as remote-tracking refs in a fresh clone, so these are tried if the ref can't be
+// resolved directly.
+func resolveRef(ctx context.Context, dir string, ref string) (string, error) {
+	for _, candidate := range []string{ref, fmt.Sprintf("origin/%s", ref)} {
+		sha, err := runGit(
+			ctx,
+			dir,
+			"rev-parse",
+			"--verify",
+			"--quiet",
+			fmt.Sprintf("%s^{commit}", candidate),
+		)
+		if err == nil && sha != "" {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("Could not resolve git ref %s", ref)
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	log.Debugf("Running git %s", strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(
+			"Error running git %s: %+v (%s)",
+			args[0],
+			err,
+			strings.TrimSpace(stderr.String()),
+		)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
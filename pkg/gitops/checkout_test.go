@@ -0,0 +1,103 @@
+package gitops
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckout(t *testing.T) {
+	ctx := context.Background()
+
+	repoDir, err := ioutil.TempDir("", "topicctl-gitops-repo")
+	require.Nil(t, err)
+	defer os.RemoveAll(repoDir)
+
+	commit := func(contents string) string {
+		err := ioutil.WriteFile(
+			filepath.Join(repoDir, "topic.yaml"),
+			[]byte(contents),
+			0644,
+		)
+		require.Nil(t, err)
+
+		_, err = runGit(ctx, repoDir, "add", "topic.yaml")
+		require.Nil(t, err)
+		_, err = runGit(
+			ctx,
+			repoDir,
+			"-c", "user.name=test",
+			"-c", "user.email=test@example.com",
+			"commit",
+			"--quiet",
+			"-m", contents,
+		)
+		require.Nil(t, err)
+
+		sha, err := runGit(ctx, repoDir, "rev-parse", "HEAD")
+		require.Nil(t, err)
+		return sha
+	}
+
+	_, err = runGit(ctx, repoDir, "init", "--quiet")
+	require.Nil(t, err)
+	_, err = runGit(ctx, repoDir, "checkout", "--quiet", "-b", "release")
+	require.Nil(t, err)
+
+	firstSHA := commit("first")
+	_, err = runGit(ctx, repoDir, "tag", "v1")
+	require.Nil(t, err)
+	secondSHA := commit("second")
+
+	type testCase struct {
+		ref         string
+		expectedSHA string
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			ref:         "release",
+			expectedSHA: secondSHA,
+		},
+		{
+			ref:         "v1",
+			expectedSHA: firstSHA,
+		},
+		{
+			ref:         firstSHA,
+			expectedSHA: firstSHA,
+		},
+		{
+			ref:         "non-existent-branch",
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		checkoutDir, err := ioutil.TempDir("", "topicctl-gitops-checkout")
+		require.Nil(t, err)
+		defer os.RemoveAll(checkoutDir)
+
+		sha, err := Checkout(ctx, repoDir, testCase.ref, filepath.Join(checkoutDir, "repo"))
+		if testCase.expectedErr {
+			assert.NotNil(t, err, testCase.ref)
+			continue
+		}
+		require.Nil(t, err, testCase.ref)
+		assert.Equal(t, testCase.expectedSHA, sha, testCase.ref)
+
+		contents, err := ioutil.ReadFile(filepath.Join(checkoutDir, "repo", "topic.yaml"))
+		require.Nil(t, err)
+		if sha == firstSHA {
+			assert.Equal(t, "first", string(contents))
+		} else {
+			assert.Equal(t, "second", string(contents))
+		}
+	}
+}
@@ -156,6 +156,66 @@ func (c *Client) GetMemberLags(
 	return partitionLags, nil
 }
 
+// GetTopicGroups returns the consumer groups that have members consuming from the argument
+// topic, along with their total offset lag in it. The lag is computed against the argument
+// newest offsets, keyed by partition; partitions without committed offsets are skipped.
+func (c *Client) GetTopicGroups(
+	ctx context.Context,
+	topic string,
+	newestOffsets map[int]int64,
+) ([]TopicGroup, error) {
+	groupCoordinators, err := c.GetGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topicGroups := []TopicGroup{}
+
+	for _, groupCoordinator := range groupCoordinators {
+		groupDetails, err := c.GetGroupDetails(ctx, groupCoordinator.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := groupDetails.TopicsMap()[topic]; !ok {
+			continue
+		}
+
+		topicGroup := TopicGroup{
+			GroupID: groupDetails.GroupID,
+			State:   groupDetails.State,
+		}
+		for _, member := range groupDetails.Members {
+			if _, ok := member.TopicPartitions[topic]; ok {
+				topicGroup.Members++
+				topicGroup.Partitions += len(member.TopicPartitions[topic])
+			}
+		}
+
+		offsets, err := c.client.ConsumerOffsets(
+			ctx, kafka.TopicAndGroup{
+				Topic:   topic,
+				GroupId: groupDetails.GroupID,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		for partition, offset := range offsets {
+			newestOffset, ok := newestOffsets[partition]
+			if !ok || offset < 0 {
+				continue
+			}
+			if newestOffset > offset {
+				topicGroup.OffsetLag += newestOffset - offset
+			}
+		}
+
+		topicGroups = append(topicGroups, topicGroup)
+	}
+
+	return topicGroups, nil
+}
+
 // ResetOffsets updates the offsets for a given topic / group combination.
 func (c *Client) ResetOffsets(
 	ctx context.Context,
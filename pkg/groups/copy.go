@@ -0,0 +1,132 @@
+package groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/messages"
+	log "github.com/sirupsen/logrus"
+)
+
+// PartitionOffsetCopy stores the source and new offsets for a single partition when copying
+// offsets between groups.
+type PartitionOffsetCopy struct {
+	Partition    int
+	SourceOffset int64
+	NewOffset    int64
+}
+
+// GetOffsetCopies returns the offsets that a destination group should have in order to copy
+// the committed offsets of the argument source group in a topic. The offsets are optionally
+// shifted by a number of messages and/or a duration; see messages.ShiftOffset for details.
+//
+// Partitions in which the source group doesn't have any committed offsets are skipped.
+func (c *Client) GetOffsetCopies(
+	ctx context.Context,
+	topic string,
+	sourceGroupID string,
+	offsetShift int64,
+	timeShift time.Duration,
+) ([]PartitionOffsetCopy, error) {
+	offsets, err := c.client.ConsumerOffsets(
+		ctx,
+		kafka.TopicAndGroup{
+			Topic:   topic,
+			GroupId: sourceGroupID,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := []int{}
+	for partition := range offsets {
+		partitions = append(partitions, partition)
+	}
+	sort.Ints(partitions)
+
+	offsetCopies := []PartitionOffsetCopy{}
+
+	for _, partition := range partitions {
+		sourceOffset := offsets[partition]
+
+		// kafka-go returns kafka.FirstOffset for partitions without committed offsets
+		if sourceOffset < 0 {
+			log.Warnf(
+				"Group %s has no committed offset for partition %d; skipping it",
+				sourceGroupID,
+				partition,
+			)
+			continue
+		}
+
+		newOffset, err := messages.ShiftOffset(
+			ctx,
+			c.brokerAddr,
+			topic,
+			partition,
+			sourceOffset,
+			offsetShift,
+			timeShift,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		offsetCopies = append(
+			offsetCopies,
+			PartitionOffsetCopy{
+				Partition:    partition,
+				SourceOffset: sourceOffset,
+				NewOffset:    newOffset,
+			},
+		)
+	}
+
+	if len(offsetCopies) == 0 {
+		return nil, fmt.Errorf(
+			"Group %s has no committed offsets in topic %s",
+			sourceGroupID,
+			topic,
+		)
+	}
+
+	return offsetCopies, nil
+}
+
+// CopyOffsets sets the offsets of the argument destination group to the new offsets in the
+// argument copies. None of the destination group's active members can be consuming the
+// topic; otherwise, the new offsets could be overwritten.
+func (c *Client) CopyOffsets(
+	ctx context.Context,
+	topic string,
+	destGroupID string,
+	offsetCopies []PartitionOffsetCopy,
+) error {
+	if len(offsetCopies) == 0 {
+		return errors.New("No offsets to copy")
+	}
+
+	groupDetails, err := c.GetGroupDetails(ctx, destGroupID)
+	if err != nil {
+		return err
+	}
+	if _, ok := groupDetails.TopicsMap()[topic]; ok {
+		return fmt.Errorf(
+			"Group %s has active members consuming topic %s; stop them first",
+			destGroupID,
+			topic,
+		)
+	}
+
+	partitionOffsets := map[int]int64{}
+	for _, offsetCopy := range offsetCopies {
+		partitionOffsets[offsetCopy.Partition] = offsetCopy.NewOffset
+	}
+
+	return c.ResetOffsets(ctx, topic, destGroupID, partitionOffsets)
+}
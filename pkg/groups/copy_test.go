@@ -0,0 +1,61 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyOffsets(t *testing.T) {
+	ctx := context.Background()
+	topicName := createTestTopic(ctx, t)
+	sourceGroupID := fmt.Sprintf("test-group-%s", topicName)
+	destGroupID := fmt.Sprintf("test-group-dest-%s", topicName)
+
+	reader := readTestMessages(ctx, t, topicName, sourceGroupID)
+	require.Nil(t, reader.Close())
+
+	client := NewClient(util.TestKafkaAddr())
+
+	sourceOffsets, err := client.client.ConsumerOffsets(
+		ctx,
+		kafka.TopicAndGroup{
+			Topic:   topicName,
+			GroupId: sourceGroupID,
+		},
+	)
+	require.Nil(t, err)
+
+	offsetCopies, err := client.GetOffsetCopies(ctx, topicName, sourceGroupID, -1, 0)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(offsetCopies))
+
+	for _, offsetCopy := range offsetCopies {
+		assert.Equal(t, sourceOffsets[offsetCopy.Partition], offsetCopy.SourceOffset)
+		assert.Equal(t, offsetCopy.SourceOffset-1, offsetCopy.NewOffset)
+	}
+
+	err = client.CopyOffsets(ctx, topicName, destGroupID, offsetCopies)
+	require.Nil(t, err)
+
+	destOffsets, err := client.client.ConsumerOffsets(
+		ctx,
+		kafka.TopicAndGroup{
+			Topic:   topicName,
+			GroupId: destGroupID,
+		},
+	)
+	require.Nil(t, err)
+	for _, offsetCopy := range offsetCopies {
+		assert.Equal(t, offsetCopy.NewOffset, destOffsets[offsetCopy.Partition])
+	}
+
+	// Source group has no offsets
+	_, err = client.GetOffsetCopies(ctx, topicName, "non-existent-group", 0, 0)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,239 @@
+package groups
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+// The version of kafka-go used by this repo doesn't expose the APIs below, so the requests
+// are made via the protocol package instead.
+const (
+	findCoordinatorAPIKey     int16 = 10
+	findCoordinatorAPIVersion int16 = 0
+	deleteGroupsAPIKey        int16 = 42
+	deleteGroupsAPIVersion    int16 = 0
+	offsetDeleteAPIKey        int16 = 47
+	offsetDeleteAPIVersion    int16 = 0
+)
+
+// DeleteGroup deletes a consumer group, including all of its committed offsets. The group
+// must be empty, i.e. it can't have any active members. Requires Kafka 1.1 or newer.
+func (c *Client) DeleteGroup(ctx context.Context, groupID string) error {
+	groupDetails, err := c.GetGroupDetails(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if groupDetails.State == "Dead" {
+		return fmt.Errorf("Group %s does not exist", groupID)
+	}
+	if len(groupDetails.Members) > 0 || groupDetails.State != "Empty" {
+		return fmt.Errorf(
+			"Group %s is not empty (state: %s, members: %d); stop all of its consumers first",
+			groupID,
+			groupDetails.State,
+			len(groupDetails.Members),
+		)
+	}
+
+	coordinatorAddr, err := findCoordinator(ctx, c.brokerAddr, groupID)
+	if err != nil {
+		return err
+	}
+
+	body := &protocol.Encoder{}
+	body.WriteInt32(1)
+	body.WriteString(groupID)
+
+	log.Debugf("Deleting group %s via coordinator %s", groupID, coordinatorAddr)
+	response, err := protocol.RoundTrip(
+		ctx,
+		coordinatorAddr,
+		deleteGroupsAPIKey,
+		deleteGroupsAPIVersion,
+		body,
+	)
+	if err != nil {
+		return err
+	}
+
+	return decodeDeleteGroupsResponse(response, groupID)
+}
+
+// DeleteGroupOffsets deletes the committed offsets for a consumer group in all partitions of
+// a single topic. None of the group's active members can be consuming the topic. Requires
+// Kafka 2.4 or newer.
+func (c *Client) DeleteGroupOffsets(
+	ctx context.Context,
+	groupID string,
+	topic string,
+) error {
+	groupDetails, err := c.GetGroupDetails(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if groupDetails.State == "Dead" {
+		return fmt.Errorf("Group %s does not exist", groupID)
+	}
+	if _, ok := groupDetails.TopicsMap()[topic]; ok {
+		return fmt.Errorf(
+			"Group %s has active members consuming topic %s; stop them first",
+			groupID,
+			topic,
+		)
+	}
+
+	conn, err := kafka.DefaultDialer.DialContext(ctx, "tcp", c.brokerAddr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return err
+	}
+
+	coordinatorAddr, err := findCoordinator(ctx, c.brokerAddr, groupID)
+	if err != nil {
+		return err
+	}
+
+	body := &protocol.Encoder{}
+	body.WriteString(groupID)
+	body.WriteInt32(1)
+	body.WriteString(topic)
+	body.WriteInt32(int32(len(partitions)))
+	for _, partition := range partitions {
+		body.WriteInt32(int32(partition.ID))
+	}
+
+	log.Debugf(
+		"Deleting offsets for group %s in topic %s via coordinator %s",
+		groupID,
+		topic,
+		coordinatorAddr,
+	)
+	response, err := protocol.RoundTrip(
+		ctx,
+		coordinatorAddr,
+		offsetDeleteAPIKey,
+		offsetDeleteAPIVersion,
+		body,
+	)
+	if err != nil {
+		return err
+	}
+
+	return decodeOffsetDeleteResponse(response)
+}
+
+// findCoordinator returns the address of the coordinator broker for the argument group.
+func findCoordinator(ctx context.Context, brokerAddr string, groupID string) (string, error) {
+	body := &protocol.Encoder{}
+	body.WriteString(groupID)
+
+	response, err := protocol.RoundTrip(
+		ctx,
+		brokerAddr,
+		findCoordinatorAPIKey,
+		findCoordinatorAPIVersion,
+		body,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	errorCode := response.ReadInt16()
+	// Node ID
+	response.ReadInt32()
+	host := response.ReadString()
+	port := response.ReadInt32()
+
+	if err := response.Err(); err != nil {
+		return "", err
+	}
+	if errorCode != 0 {
+		return "", fmt.Errorf(
+			"Error finding coordinator for group %s: %+v",
+			groupID,
+			kafka.Error(errorCode),
+		)
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+func decodeDeleteGroupsResponse(response *protocol.Decoder, groupID string) error {
+	// Throttle time
+	response.ReadInt32()
+
+	found := false
+
+	numResults := response.ReadInt32()
+	for i := 0; i < int(numResults) && response.Err() == nil; i++ {
+		resultGroupID := response.ReadString()
+		errorCode := response.ReadInt16()
+
+		if resultGroupID != groupID {
+			continue
+		}
+		found = true
+
+		if errorCode != 0 {
+			return fmt.Errorf(
+				"Error deleting group %s: %+v",
+				groupID,
+				kafka.Error(errorCode),
+			)
+		}
+	}
+
+	if err := response.Err(); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("Response is missing group %s", groupID)
+	}
+	return nil
+}
+
+func decodeOffsetDeleteResponse(response *protocol.Decoder) error {
+	errorCode := response.ReadInt16()
+	// Throttle time
+	response.ReadInt32()
+
+	if err := response.Err(); err != nil {
+		return err
+	}
+	if errorCode != 0 {
+		return fmt.Errorf("Error deleting offsets: %+v", kafka.Error(errorCode))
+	}
+
+	numTopics := response.ReadInt32()
+	for i := 0; i < int(numTopics) && response.Err() == nil; i++ {
+		topic := response.ReadString()
+		numPartitions := response.ReadInt32()
+
+		for j := 0; j < int(numPartitions) && response.Err() == nil; j++ {
+			partition := response.ReadInt32()
+			partitionErrorCode := response.ReadInt16()
+
+			if partitionErrorCode != 0 {
+				return fmt.Errorf(
+					"Error deleting offsets for partition %d in topic %s: %+v",
+					partition,
+					topic,
+					kafka.Error(partitionErrorCode),
+				)
+			}
+		}
+	}
+
+	return response.Err()
+}
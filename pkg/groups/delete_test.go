@@ -0,0 +1,167 @@
+package groups
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteGroup(t *testing.T) {
+	ctx := context.Background()
+	util.SkipIfTestAPIUnsupported(ctx, t, deleteGroupsAPIKey)
+
+	topicName := createTestTopic(ctx, t)
+	groupID := fmt.Sprintf("test-group-%s", topicName)
+	reader := readTestMessages(ctx, t, topicName, groupID)
+
+	client := NewClient(util.TestKafkaAddr())
+
+	// Group has an active member
+	err := client.DeleteGroup(ctx, groupID)
+	assert.NotNil(t, err)
+
+	require.Nil(t, reader.Close())
+
+	err = client.DeleteGroup(ctx, groupID)
+	require.Nil(t, err)
+
+	groupDetails, err := client.GetGroupDetails(ctx, groupID)
+	require.Nil(t, err)
+	assert.Equal(t, "Dead", groupDetails.State)
+
+	// Group no longer exists
+	err = client.DeleteGroup(ctx, groupID)
+	assert.NotNil(t, err)
+}
+
+func TestDeleteGroupOffsets(t *testing.T) {
+	ctx := context.Background()
+	util.SkipIfTestAPIUnsupported(ctx, t, offsetDeleteAPIKey)
+
+	topicName := createTestTopic(ctx, t)
+	groupID := fmt.Sprintf("test-group-%s", topicName)
+	reader := readTestMessages(ctx, t, topicName, groupID)
+
+	client := NewClient(util.TestKafkaAddr())
+
+	// Group has an active member consuming the topic
+	err := client.DeleteGroupOffsets(ctx, groupID, topicName)
+	assert.NotNil(t, err)
+
+	require.Nil(t, reader.Close())
+
+	err = client.DeleteGroupOffsets(ctx, groupID, topicName)
+	require.Nil(t, err)
+
+	offsets, err := client.client.ConsumerOffsets(
+		ctx,
+		kafka.TopicAndGroup{
+			Topic:   topicName,
+			GroupId: groupID,
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		map[int]int64{
+			0: kafka.FirstOffset,
+			1: kafka.FirstOffset,
+		},
+		offsets,
+	)
+}
+
+func TestDecodeDeleteGroupsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt32(2)
+	response.WriteString("other-group")
+	response.WriteInt16(0)
+	response.WriteString("test-group")
+	response.WriteInt16(68)
+
+	err := decodeDeleteGroupsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"other-group",
+	)
+	assert.Nil(t, err)
+
+	err = decodeDeleteGroupsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"test-group",
+	)
+	assert.NotNil(t, err)
+
+	err = decodeDeleteGroupsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"missing-group",
+	)
+	assert.NotNil(t, err)
+}
+
+func TestDecodeOffsetDeleteResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt16(0)
+	response.WriteInt32(0)
+	response.WriteInt32(1)
+	response.WriteString("test-topic")
+	response.WriteInt32(2)
+	response.WriteInt32(0)
+	response.WriteInt16(0)
+	response.WriteInt32(1)
+	response.WriteInt16(0)
+
+	err := decodeOffsetDeleteResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+	)
+	assert.Nil(t, err)
+
+	response = &protocol.Encoder{}
+	response.WriteInt16(0)
+	response.WriteInt32(0)
+	response.WriteInt32(1)
+	response.WriteString("test-topic")
+	response.WriteInt32(1)
+	response.WriteInt32(0)
+	response.WriteInt16(86)
+
+	err = decodeOffsetDeleteResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+	)
+	assert.NotNil(t, err)
+}
+
+func readTestMessages(
+	ctx context.Context,
+	t *testing.T,
+	topicName string,
+	groupID string,
+) *kafka.Reader {
+	reader := kafka.NewReader(
+		kafka.ReaderConfig{
+			Brokers:  []string{util.TestKafkaAddr()},
+			GroupID:  groupID,
+			Topic:    topicName,
+			MinBytes: 50,
+			MaxBytes: 10000,
+		},
+	)
+
+	readerCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	for i := 0; i < 8; i++ {
+		_, err := reader.ReadMessage(readerCtx)
+		require.Nil(t, err)
+	}
+
+	return reader
+}
@@ -312,3 +312,156 @@ func FormatPartitionOffsets(partitionOffsets map[int]int64) string {
 	table.Render()
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
+
+// FormatOffsetCopies generates a pretty table that shows the source and new offsets for each
+// partition when copying offsets between groups.
+func FormatOffsetCopies(offsetCopies []PartitionOffsetCopy) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Partition",
+			"Source Offset",
+			"New Offset",
+			"Shift",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, offsetCopy := range offsetCopies {
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", offsetCopy.Partition),
+				fmt.Sprintf("%d", offsetCopy.SourceOffset),
+				fmt.Sprintf("%d", offsetCopy.NewOffset),
+				fmt.Sprintf("%+d", offsetCopy.NewOffset-offsetCopy.SourceOffset),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatAssignmentPreviews generates a pretty table from the results of
+// PreviewAssignments.
+func FormatAssignmentPreviews(previews []MemberAssignmentPreview, full bool) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Member ID",
+			"Num\nPartitions",
+			"Partitions",
+			"Added",
+			"Removed",
+		},
+	)
+	table.SetAutoWrapText(true)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, preview := range previews {
+		var memberID string
+		if full {
+			memberID = preview.MemberID
+		} else {
+			memberID, _ = util.TruncateStringMiddle(preview.MemberID, 40, 5)
+		}
+
+		table.Append(
+			[]string{
+				memberID,
+				fmt.Sprintf("%d", len(preview.Partitions)),
+				fmt.Sprintf("%+v", preview.Partitions),
+				fmt.Sprintf("%+v", preview.Added()),
+				fmt.Sprintf("%+v", preview.Removed()),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatTopicGroups generates a pretty table from the results of a call to GetTopicGroups.
+func FormatTopicGroups(topicGroups []TopicGroup) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Group",
+			"State",
+			"Members",
+			"Partitions",
+			"Offset Lag",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, topicGroup := range topicGroups {
+		table.Append(
+			[]string{
+				topicGroup.GroupID,
+				topicGroup.State,
+				fmt.Sprintf("%d", topicGroup.Members),
+				fmt.Sprintf("%d", topicGroup.Partitions),
+				fmt.Sprintf("%d", topicGroup.OffsetLag),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
@@ -0,0 +1,274 @@
+package groups
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// AssignmentStrategy is a string type that stores the name of a consumer group
+// partition assignment strategy.
+type AssignmentStrategy string
+
+const (
+	// AssignmentStrategyRange assigns contiguous ranges of partitions to each member.
+	AssignmentStrategyRange AssignmentStrategy = "range"
+
+	// AssignmentStrategyRoundRobin assigns partitions to members one at a time, in order.
+	AssignmentStrategyRoundRobin AssignmentStrategy = "roundrobin"
+
+	// AssignmentStrategySticky balances partitions across members while keeping as many
+	// of the current assignments in place as possible.
+	AssignmentStrategySticky AssignmentStrategy = "sticky"
+)
+
+// AllAssignmentStrategies contains all of the supported assignment strategies.
+var AllAssignmentStrategies = []AssignmentStrategy{
+	AssignmentStrategyRange,
+	AssignmentStrategyRoundRobin,
+	AssignmentStrategySticky,
+}
+
+// MemberAssignmentPreview stores the previewed partitions for a single (possibly
+// hypothetical) group member, along with the partitions that it currently owns.
+type MemberAssignmentPreview struct {
+	MemberID          string
+	Partitions        []int
+	CurrentPartitions []int
+}
+
+// Added returns the partitions that the member would own in the preview, but doesn't
+// currently own.
+func (m MemberAssignmentPreview) Added() []int {
+	return subtractInts(m.Partitions, m.CurrentPartitions)
+}
+
+// Removed returns the partitions that the member currently owns, but wouldn't own in
+// the preview.
+func (m MemberAssignmentPreview) Removed() []int {
+	return subtractInts(m.CurrentPartitions, m.Partitions)
+}
+
+// PreviewMemberIDs generates the member IDs to use in an assignment preview. The
+// existing members in the group are used first (in sorted order) so that sticky
+// assignments can be evaluated, and then synthetic IDs are generated for any additional
+// members. If numMembers is <= 0, then just the existing members are returned.
+func PreviewMemberIDs(currMembers []string, numMembers int) []string {
+	sortedMembers := append([]string{}, currMembers...)
+	sort.Strings(sortedMembers)
+
+	if numMembers <= 0 {
+		return sortedMembers
+	}
+
+	memberIDs := []string{}
+	for m := 0; m < numMembers; m++ {
+		if m < len(sortedMembers) {
+			memberIDs = append(memberIDs, sortedMembers[m])
+		} else {
+			memberIDs = append(memberIDs, fmt.Sprintf("new-member-%d", m-len(sortedMembers)+1))
+		}
+	}
+
+	return memberIDs
+}
+
+// PreviewAssignments computes which partitions in the argument topic each member would
+// own under the argument assignment strategy. The range and roundrobin strategies use the
+// corresponding balancers in kafka-go. The current map, which can be nil, contains
+// the partitions currently owned by each member; this is used for the sticky strategy and
+// for showing what would change.
+func PreviewAssignments(
+	strategy AssignmentStrategy,
+	topic string,
+	partitions []int,
+	memberIDs []string,
+	current map[string][]int,
+) ([]MemberAssignmentPreview, error) {
+	if len(memberIDs) == 0 {
+		return nil, fmt.Errorf("Must have at least one member to preview assignments")
+	}
+
+	var assignments map[string][]int
+
+	switch strategy {
+	case AssignmentStrategyRange:
+		assignments = balancerAssignments(
+			kafka.RangeGroupBalancer{},
+			topic,
+			partitions,
+			memberIDs,
+		)
+	case AssignmentStrategyRoundRobin:
+		assignments = balancerAssignments(
+			kafka.RoundRobinGroupBalancer{},
+			topic,
+			partitions,
+			memberIDs,
+		)
+	case AssignmentStrategySticky:
+		assignments = stickyAssignments(partitions, memberIDs, current)
+	default:
+		return nil, fmt.Errorf(
+			"Unrecognized assignment strategy %s; must be in %+v",
+			strategy,
+			AllAssignmentStrategies,
+		)
+	}
+
+	previews := []MemberAssignmentPreview{}
+
+	for _, memberID := range memberIDs {
+		memberPartitions := append([]int{}, assignments[memberID]...)
+		sort.Ints(memberPartitions)
+
+		currPartitions := append([]int{}, current[memberID]...)
+		sort.Ints(currPartitions)
+
+		previews = append(
+			previews,
+			MemberAssignmentPreview{
+				MemberID:          memberID,
+				Partitions:        memberPartitions,
+				CurrentPartitions: currPartitions,
+			},
+		)
+	}
+
+	return previews, nil
+}
+
+func balancerAssignments(
+	balancer kafka.GroupBalancer,
+	topic string,
+	partitions []int,
+	memberIDs []string,
+) map[string][]int {
+	members := []kafka.GroupMember{}
+	for _, memberID := range memberIDs {
+		members = append(
+			members,
+			kafka.GroupMember{
+				ID:     memberID,
+				Topics: []string{topic},
+			},
+		)
+	}
+
+	kafkaPartitions := []kafka.Partition{}
+	for _, partition := range partitions {
+		kafkaPartitions = append(
+			kafkaPartitions,
+			kafka.Partition{
+				Topic: topic,
+				ID:    partition,
+			},
+		)
+	}
+
+	assignments := map[string][]int{}
+	for memberID, topicPartitions := range balancer.AssignGroups(members, kafkaPartitions) {
+		assignments[memberID] = topicPartitions[topic]
+	}
+
+	return assignments
+}
+
+// stickyAssignments balances the partitions across the members so that each member gets
+// either floor(partitions / members) or ceil(partitions / members) partitions, moving as few
+// of the current assignments as possible.
+func stickyAssignments(
+	partitions []int,
+	memberIDs []string,
+	current map[string][]int,
+) map[string][]int {
+	sortedPartitions := append([]int{}, partitions...)
+	sort.Ints(sortedPartitions)
+
+	validPartitions := map[int]struct{}{}
+	for _, partition := range sortedPartitions {
+		validPartitions[partition] = struct{}{}
+	}
+
+	minCount := len(sortedPartitions) / len(memberIDs)
+	numWithExtra := len(sortedPartitions) % len(memberIDs)
+
+	assignments := map[string][]int{}
+	owned := map[int]struct{}{}
+
+	// First, keep the current assignments that are still valid
+	for _, memberID := range memberIDs {
+		assignments[memberID] = []int{}
+
+		currPartitions := append([]int{}, current[memberID]...)
+		sort.Ints(currPartitions)
+
+		for _, partition := range currPartitions {
+			if _, ok := validPartitions[partition]; !ok {
+				continue
+			}
+			if _, ok := owned[partition]; ok {
+				continue
+			}
+			assignments[memberID] = append(assignments[memberID], partition)
+			owned[partition] = struct{}{}
+		}
+	}
+
+	// Then, trim members that have too many. Members with the most partitions get to keep
+	// the extra ones.
+	sortedMembers := append([]string{}, memberIDs...)
+	sort.SliceStable(sortedMembers, func(a, b int) bool {
+		return len(assignments[sortedMembers[a]]) > len(assignments[sortedMembers[b]])
+	})
+
+	for m, memberID := range sortedMembers {
+		maxCount := minCount
+		if m < numWithExtra {
+			maxCount++
+		}
+
+		if len(assignments[memberID]) > maxCount {
+			for _, partition := range assignments[memberID][maxCount:] {
+				delete(owned, partition)
+			}
+			assignments[memberID] = assignments[memberID][:maxCount]
+		}
+	}
+
+	// Finally, give the unowned partitions to the members with the fewest partitions
+	for _, partition := range sortedPartitions {
+		if _, ok := owned[partition]; ok {
+			continue
+		}
+
+		var target string
+		for _, memberID := range memberIDs {
+			if target == "" || len(assignments[memberID]) < len(assignments[target]) {
+				target = memberID
+			}
+		}
+
+		assignments[target] = append(assignments[target], partition)
+		owned[partition] = struct{}{}
+	}
+
+	return assignments
+}
+
+func subtractInts(values []int, toRemove []int) []int {
+	removeMap := map[int]struct{}{}
+	for _, value := range toRemove {
+		removeMap[value] = struct{}{}
+	}
+
+	results := []int{}
+	for _, value := range values {
+		if _, ok := removeMap[value]; !ok {
+			results = append(results, value)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,147 @@
+package groups
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewMemberIDs(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"a", "b"},
+		PreviewMemberIDs([]string{"b", "a"}, 0),
+	)
+	assert.Equal(
+		t,
+		[]string{"a", "b", "new-member-1", "new-member-2"},
+		PreviewMemberIDs([]string{"b", "a"}, 4),
+	)
+	assert.Equal(
+		t,
+		[]string{"a"},
+		PreviewMemberIDs([]string{"b", "a"}, 1),
+	)
+}
+
+func TestPreviewAssignments(t *testing.T) {
+	type testCase struct {
+		description string
+		strategy    AssignmentStrategy
+		partitions  []int
+		memberIDs   []string
+		current     map[string][]int
+		expected    map[string][]int
+		expErr      bool
+	}
+
+	testCases := []testCase{
+		{
+			description: "range",
+			strategy:    AssignmentStrategyRange,
+			partitions:  []int{0, 1, 2, 3, 4, 5, 6},
+			memberIDs:   []string{"a", "b", "c"},
+			expected: map[string][]int{
+				"a": {0, 1},
+				"b": {2, 3},
+				"c": {4, 5, 6},
+			},
+		},
+		{
+			description: "round robin",
+			strategy:    AssignmentStrategyRoundRobin,
+			partitions:  []int{0, 1, 2, 3, 4, 5, 6},
+			memberIDs:   []string{"a", "b", "c"},
+			expected: map[string][]int{
+				"a": {0, 3, 6},
+				"b": {1, 4},
+				"c": {2, 5},
+			},
+		},
+		{
+			description: "sticky with no current state",
+			strategy:    AssignmentStrategySticky,
+			partitions:  []int{0, 1, 2, 3},
+			memberIDs:   []string{"a", "b"},
+			expected: map[string][]int{
+				"a": {0, 2},
+				"b": {1, 3},
+			},
+		},
+		{
+			description: "sticky scale up",
+			strategy:    AssignmentStrategySticky,
+			partitions:  []int{0, 1, 2, 3, 4, 5},
+			memberIDs:   []string{"a", "b", "new-member-1"},
+			current: map[string][]int{
+				"a": {0, 1, 2},
+				"b": {3, 4, 5},
+			},
+			expected: map[string][]int{
+				"a":            {0, 1},
+				"b":            {3, 4},
+				"new-member-1": {2, 5},
+			},
+		},
+		{
+			description: "sticky scale down",
+			strategy:    AssignmentStrategySticky,
+			partitions:  []int{0, 1, 2, 3, 4, 5},
+			memberIDs:   []string{"a", "b"},
+			current: map[string][]int{
+				"a": {0, 1},
+				"b": {2, 3},
+				"c": {4, 5},
+			},
+			expected: map[string][]int{
+				"a": {0, 1, 4},
+				"b": {2, 3, 5},
+			},
+		},
+		{
+			description: "bad strategy",
+			strategy:    AssignmentStrategy("not-a-strategy"),
+			partitions:  []int{0, 1},
+			memberIDs:   []string{"a"},
+			expErr:      true,
+		},
+		{
+			description: "no members",
+			strategy:    AssignmentStrategyRange,
+			partitions:  []int{0, 1},
+			expErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		previews, err := PreviewAssignments(
+			testCase.strategy,
+			"test-topic",
+			testCase.partitions,
+			testCase.memberIDs,
+			testCase.current,
+		)
+		if testCase.expErr {
+			assert.NotNil(t, err, testCase.description)
+			continue
+		}
+		require.Nil(t, err, testCase.description)
+
+		results := map[string][]int{}
+		for _, preview := range previews {
+			results[preview.MemberID] = preview.Partitions
+		}
+		assert.Equal(t, testCase.expected, results, testCase.description)
+	}
+}
+
+func TestMemberAssignmentPreviewDiffs(t *testing.T) {
+	preview := MemberAssignmentPreview{
+		MemberID:          "a",
+		Partitions:        []int{1, 2, 3},
+		CurrentPartitions: []int{0, 1},
+	}
+	assert.Equal(t, []int{2, 3}, preview.Added())
+	assert.Equal(t, []int{0}, preview.Removed())
+}
@@ -76,6 +76,20 @@ func (m MemberInfo) Topics() []string {
 	return topics
 }
 
+// TopicGroup summarizes a consumer group that's consuming from a single topic.
+type TopicGroup struct {
+	GroupID string `json:"groupID"`
+	State   string `json:"state"`
+
+	// Members is the number of group members that are subscribed to the topic, and Partitions
+	// is the number of the topic's partitions that are assigned to them.
+	Members    int `json:"members"`
+	Partitions int `json:"partitions"`
+
+	// OffsetLag is the total offset lag of the group across the topic's partitions.
+	OffsetLag int64 `json:"offsetLag"`
+}
+
 // MemberPartitionLag information about the lag for a single topic / partition / group member
 // combination.
 type MemberPartitionLag struct {
@@ -0,0 +1,144 @@
+package healthcheck
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/bench"
+)
+
+// FormatResults generates a pretty table with the health check results for each broker.
+func FormatResults(results Results) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Partitions\nLed",
+			"Produce\nLatency",
+			"Consume\nLatency",
+			"Status",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, broker := range results.Brokers {
+		var status string
+		var produceLatency string
+		var consumeLatency string
+
+		if broker.Partitions == 0 {
+			status = "No canary partitions led"
+		} else if len(broker.Errors) > 0 {
+			status = strings.Join(broker.Errors, "\n")
+		} else {
+			status = "OK"
+			produceLatency = broker.ProduceLatency.Round(time.Microsecond).String()
+			consumeLatency = broker.ConsumeLatency.Round(time.Microsecond).String()
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", broker.Broker),
+				fmt.Sprintf("%d", broker.Partitions),
+				produceLatency,
+				consumeLatency,
+				status,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatProbeResults generates a pretty table with the probe latencies for each broker. Each
+// latency column shows the median and max values.
+func FormatProbeResults(results []BrokerProbeResults) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Broker",
+			"Address",
+			"Connect\n(P50 / Max)",
+			"TLS Handshake\n(P50 / Max)",
+			"Metadata\n(P50 / Max)",
+			"Produce\n(P50 / Max)",
+			"Failures",
+			"Last Error",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, result := range results {
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", result.Broker),
+				result.Addr,
+				latencyStatsStr(result.Connect),
+				latencyStatsStr(result.TLSHandshake),
+				latencyStatsStr(result.Metadata),
+				latencyStatsStr(result.Produce),
+				fmt.Sprintf("%d/%d", result.Failures, result.Attempts),
+				result.LastError,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func latencyStatsStr(stats bench.LatencyStats) string {
+	if stats.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf(
+		"%s / %s",
+		stats.P50.Round(time.Microsecond),
+		stats.Max.Round(time.Microsecond),
+	)
+}
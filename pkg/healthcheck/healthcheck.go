@@ -0,0 +1,370 @@
+// Package healthcheck contains an end-to-end smoke test for a cluster that produces canary
+// messages to every partition of a topic and then consumes them back.
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTopic is the canary topic used if one isn't set in the config.
+	DefaultTopic = "topicctl-canary"
+
+	// The maximum number of bytes to read per canary fetch
+	maxReadBytes = 1e6
+)
+
+// Config contains the configuration for a health check.
+type Config struct {
+	Topic string
+
+	// CreateTopic determines whether the canary topic is created if it doesn't exist. The
+	// topic is created with one partition per broker so that each broker is likely to lead
+	// one of them.
+	CreateTopic       bool
+	ReplicationFactor int
+
+	// Timeout is the maximum amount of time to wait for each canary message to be produced
+	// and consumed.
+	Timeout time.Duration
+}
+
+// Validate evaluates whether the health check config is valid.
+func (c Config) Validate() error {
+	if c.Topic == "" {
+		return errors.New("Topic must be set")
+	}
+	if c.CreateTopic && c.ReplicationFactor <= 0 {
+		return errors.New("Replication factor must be positive")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("Timeout must be positive")
+	}
+	return nil
+}
+
+// PartitionResult contains the outcome of the check for a single canary partition.
+type PartitionResult struct {
+	Partition      int
+	Leader         int
+	ProduceLatency time.Duration
+	ConsumeLatency time.Duration
+	Err            error
+}
+
+// BrokerResult summarizes the checks for the canary partitions led by a single broker.
+// The latencies are the maximums across these partitions.
+type BrokerResult struct {
+	Broker         int
+	Partitions     int
+	ProduceLatency time.Duration
+	ConsumeLatency time.Duration
+	Errors         []string
+}
+
+// Healthy returns whether the broker led at least one canary partition and all of the
+// checks for these succeeded.
+func (b BrokerResult) Healthy() bool {
+	return b.Partitions > 0 && len(b.Errors) == 0
+}
+
+// Results contains the outcome of a health check.
+type Results struct {
+	Topic      string
+	Elapsed    time.Duration
+	Partitions []PartitionResult
+	Brokers    []BrokerResult
+}
+
+// Healthy returns whether the checks for all brokers succeeded.
+func (r Results) Healthy() bool {
+	for _, broker := range r.Brokers {
+		if !broker.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// UnhealthyBrokers returns the IDs of the brokers that failed their checks or that don't
+// lead any canary partitions.
+func (r Results) UnhealthyBrokers() []int {
+	brokerIDs := []int{}
+	for _, broker := range r.Brokers {
+		if !broker.Healthy() {
+			brokerIDs = append(brokerIDs, broker.Broker)
+		}
+	}
+	return brokerIDs
+}
+
+// Run runs a health check against the cluster that the argument admin client is connected
+// to.
+func Run(
+	ctx context.Context,
+	adminClient *admin.Client,
+	config Config,
+) (Results, error) {
+	results := Results{
+		Topic: config.Topic,
+	}
+
+	if err := config.Validate(); err != nil {
+		return results, err
+	}
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return results, err
+	}
+
+	topicInfo, err := getOrCreateTopic(ctx, adminClient, config, len(brokers))
+	if err != nil {
+		return results, err
+	}
+
+	start := time.Now()
+	results.Partitions = make([]PartitionResult, len(topicInfo.Partitions))
+	bootstrapAddr := adminClient.GetBootstrapAddrs()[0]
+
+	wg := sync.WaitGroup{}
+
+	for p, partition := range topicInfo.Partitions {
+		wg.Add(1)
+
+		go func(p int, partition admin.PartitionInfo) {
+			defer wg.Done()
+
+			result := PartitionResult{
+				Partition: partition.ID,
+				Leader:    partition.Leader,
+			}
+			result.ProduceLatency, result.ConsumeLatency, result.Err = checkPartition(
+				ctx,
+				bootstrapAddr,
+				config,
+				partition.ID,
+			)
+			if result.Err != nil {
+				log.Debugf(
+					"Canary check failed for partition %d: %+v",
+					partition.ID,
+					result.Err,
+				)
+			}
+			results.Partitions[p] = result
+		}(p, partition)
+	}
+
+	wg.Wait()
+
+	results.Elapsed = time.Since(start)
+	results.Brokers = SummarizeByBroker(results.Partitions, brokers)
+
+	return results, nil
+}
+
+// SummarizeByBroker aggregates the argument partition results by leader. All of the argument
+// brokers are included in the output, even if they don't lead any partitions.
+func SummarizeByBroker(
+	partitionResults []PartitionResult,
+	brokers []admin.BrokerInfo,
+) []BrokerResult {
+	brokerResults := map[int]*BrokerResult{}
+
+	for _, broker := range brokers {
+		brokerResults[broker.ID] = &BrokerResult{
+			Broker: broker.ID,
+			Errors: []string{},
+		}
+	}
+
+	for _, partitionResult := range partitionResults {
+		brokerResult, ok := brokerResults[partitionResult.Leader]
+		if !ok {
+			brokerResult = &BrokerResult{
+				Broker: partitionResult.Leader,
+				Errors: []string{},
+			}
+			brokerResults[partitionResult.Leader] = brokerResult
+		}
+
+		brokerResult.Partitions++
+
+		if partitionResult.Err != nil {
+			brokerResult.Errors = append(
+				brokerResult.Errors,
+				fmt.Sprintf("partition %d: %+v", partitionResult.Partition, partitionResult.Err),
+			)
+			continue
+		}
+		if partitionResult.ProduceLatency > brokerResult.ProduceLatency {
+			brokerResult.ProduceLatency = partitionResult.ProduceLatency
+		}
+		if partitionResult.ConsumeLatency > brokerResult.ConsumeLatency {
+			brokerResult.ConsumeLatency = partitionResult.ConsumeLatency
+		}
+	}
+
+	results := []BrokerResult{}
+	for _, brokerResult := range brokerResults {
+		results = append(results, *brokerResult)
+	}
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Broker < results[b].Broker
+	})
+
+	return results
+}
+
+func getOrCreateTopic(
+	ctx context.Context,
+	adminClient *admin.Client,
+	config Config,
+	numBrokers int,
+) (admin.TopicInfo, error) {
+	topicInfo, err := adminClient.GetTopic(ctx, config.Topic, true)
+	if err == nil {
+		return topicInfo, nil
+	} else if err != admin.ErrTopicDoesNotExist {
+		return topicInfo, err
+	}
+
+	if !config.CreateTopic {
+		return topicInfo, fmt.Errorf("Canary topic %s does not exist", config.Topic)
+	}
+
+	replicationFactor := config.ReplicationFactor
+	if replicationFactor > numBrokers {
+		replicationFactor = numBrokers
+	}
+
+	log.Infof(
+		"Creating canary topic %s with %d partitions and replication factor %d",
+		config.Topic,
+		numBrokers,
+		replicationFactor,
+	)
+
+	err = adminClient.CreateTopic(
+		ctx,
+		kafka.TopicConfig{
+			Topic:             config.Topic,
+			NumPartitions:     numBrokers,
+			ReplicationFactor: replicationFactor,
+		},
+	)
+	if err != nil {
+		return topicInfo, err
+	}
+
+	// Wait for the topic and its leaders to be visible
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(config.Timeout)
+
+	for {
+		select {
+		case <-ticker.C:
+			topicInfo, err = adminClient.GetTopic(ctx, config.Topic, true)
+			if err == nil && len(topicInfo.Partitions) == numBrokers &&
+				allLeadersElected(topicInfo) {
+				return topicInfo, nil
+			}
+		case <-timeout:
+			return topicInfo, fmt.Errorf(
+				"Timed out waiting for canary topic %s to be created",
+				config.Topic,
+			)
+		case <-ctx.Done():
+			return topicInfo, ctx.Err()
+		}
+	}
+}
+
+func allLeadersElected(topicInfo admin.TopicInfo) bool {
+	for _, partition := range topicInfo.Partitions {
+		if partition.Leader < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPartition produces a single canary message to the argument partition and then reads
+// it back from the partition leader. It returns the produce and consume latencies; the
+// latter is measured from the time that the produce completes.
+func checkPartition(
+	ctx context.Context,
+	bootstrapAddr string,
+	config Config,
+	partition int,
+) (time.Duration, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	conn, err := kafka.DefaultDialer.DialLeader(
+		ctx,
+		"tcp",
+		bootstrapAddr,
+		config.Topic,
+		partition,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	offset, err := conn.ReadLastOffset()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value := canaryValue(partition)
+
+	produceStart := time.Now()
+	if _, err := conn.WriteMessages(kafka.Message{Value: []byte(value)}); err != nil {
+		return 0, 0, err
+	}
+	consumeStart := time.Now()
+	produceLatency := consumeStart.Sub(produceStart)
+
+	if _, err := conn.Seek(offset, kafka.SeekAbsolute); err != nil {
+		return produceLatency, 0, err
+	}
+
+	for {
+		message, err := conn.ReadMessage(maxReadBytes)
+		if err != nil {
+			return produceLatency, 0, err
+		}
+		if string(message.Value) == value {
+			return produceLatency, time.Since(consumeStart), nil
+		}
+	}
+}
+
+func canaryValue(partition int) string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf(
+		"topicctl healthcheck from %s, partition %d, at %d",
+		hostname,
+		partition,
+		time.Now().UnixNano(),
+	)
+}
@@ -0,0 +1,126 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	adminClient, err := admin.NewClient(
+		ctx,
+		admin.ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       "",
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       false,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	topicName := util.RandomString("topic-healthcheck-", 6)
+
+	_, err = Run(
+		ctx,
+		adminClient,
+		Config{
+			Topic:   topicName,
+			Timeout: 10 * time.Second,
+		},
+	)
+	assert.NotNil(t, err)
+
+	results, err := Run(
+		ctx,
+		adminClient,
+		Config{
+			Topic:             topicName,
+			CreateTopic:       true,
+			ReplicationFactor: 2,
+			Timeout:           10 * time.Second,
+		},
+	)
+	require.Nil(t, err)
+
+	brokerIDs, err := adminClient.GetBrokerIDs(ctx)
+	require.Nil(t, err)
+	assert.Equal(t, len(brokerIDs), len(results.Partitions))
+	assert.Equal(t, len(brokerIDs), len(results.Brokers))
+
+	for _, partitionResult := range results.Partitions {
+		assert.Nil(t, partitionResult.Err)
+		assert.Greater(t, int64(partitionResult.ProduceLatency), int64(0))
+		assert.Greater(t, int64(partitionResult.ConsumeLatency), int64(0))
+	}
+}
+
+func TestSummarizeByBroker(t *testing.T) {
+	brokers := []admin.BrokerInfo{
+		{ID: 1},
+		{ID: 2},
+		{ID: 3},
+	}
+
+	results := Results{
+		Brokers: SummarizeByBroker(
+			[]PartitionResult{
+				{
+					Partition:      0,
+					Leader:         1,
+					ProduceLatency: 10 * time.Millisecond,
+					ConsumeLatency: 20 * time.Millisecond,
+				},
+				{
+					Partition:      1,
+					Leader:         1,
+					ProduceLatency: 30 * time.Millisecond,
+					ConsumeLatency: 5 * time.Millisecond,
+				},
+				{
+					Partition: 2,
+					Leader:    2,
+					Err:       errors.New("timed out"),
+				},
+			},
+			brokers,
+		),
+	}
+
+	assert.Equal(
+		t,
+		[]BrokerResult{
+			{
+				Broker:         1,
+				Partitions:     2,
+				ProduceLatency: 30 * time.Millisecond,
+				ConsumeLatency: 20 * time.Millisecond,
+				Errors:         []string{},
+			},
+			{
+				Broker:     2,
+				Partitions: 1,
+				Errors:     []string{"partition 2: timed out"},
+			},
+			{
+				Broker:     3,
+				Partitions: 0,
+				Errors:     []string{},
+			},
+		},
+		results.Brokers,
+	)
+	assert.False(t, results.Healthy())
+	assert.Equal(t, []int{2, 3}, results.UnhealthyBrokers())
+
+	results.Brokers = results.Brokers[:1]
+	assert.True(t, results.Healthy())
+}
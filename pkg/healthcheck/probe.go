@@ -0,0 +1,300 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/bench"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbeConfig contains the configuration for probing the latencies of each broker.
+type ProbeConfig struct {
+	Rounds   int
+	Interval time.Duration
+	Timeout  time.Duration
+
+	// Topic is used for the produce probes. Each broker gets a message produced to one of the
+	// partitions that it leads. If the topic isn't set or doesn't exist, produce probes are
+	// skipped.
+	Topic string
+
+	// TLSSkipVerify skips verification of broker certificates in the TLS handshake probes,
+	// which are run for brokers that have an SSL listener.
+	TLSSkipVerify bool
+}
+
+// Validate evaluates whether the probe config is valid.
+func (c ProbeConfig) Validate() error {
+	if c.Rounds <= 0 {
+		return errors.New("Rounds must be positive")
+	}
+	if c.Interval < 0 {
+		return errors.New("Interval cannot be negative")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("Timeout must be positive")
+	}
+	return nil
+}
+
+// BrokerProbeResults summarizes the latencies observed when probing a single broker. Stats
+// are only computed over successful probes.
+type BrokerProbeResults struct {
+	Broker  int
+	Addr    string
+	TLSAddr string
+
+	// ProducePartition is the partition used for produce probes, or -1 if these are skipped.
+	ProducePartition int
+
+	Connect      bench.LatencyStats
+	TLSHandshake bench.LatencyStats
+	Metadata     bench.LatencyStats
+	Produce      bench.LatencyStats
+
+	Attempts  int
+	Failures  int
+	LastError string
+}
+
+type probeLatencies struct {
+	connect      time.Duration
+	tlsHandshake time.Duration
+	metadata     time.Duration
+	produce      time.Duration
+}
+
+type brokerProbe struct {
+	results   *BrokerProbeResults
+	latencies []probeLatencies
+}
+
+// ProbeBrokers connects to each broker individually and measures the latencies of opening
+// a connection, doing a TLS handshake (if the broker has an SSL listener), fetching metadata,
+// and producing to a partition that the broker leads. Each broker is probed config.Rounds
+// times; the results are sorted by broker ID.
+func ProbeBrokers(
+	ctx context.Context,
+	adminClient *admin.Client,
+	config ProbeConfig,
+) ([]BrokerProbeResults, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	brokers, err := adminClient.GetBrokers(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	producePartitions := map[int]int{}
+
+	if config.Topic != "" {
+		topicInfo, err := adminClient.GetTopic(ctx, config.Topic, true)
+		if err == nil {
+			for _, partition := range topicInfo.Partitions {
+				if _, ok := producePartitions[partition.Leader]; !ok {
+					producePartitions[partition.Leader] = partition.ID
+				}
+			}
+		} else if err == admin.ErrTopicDoesNotExist {
+			log.Infof("Topic %s does not exist, skipping produce probes", config.Topic)
+		} else {
+			return nil, err
+		}
+	}
+
+	probes := []*brokerProbe{}
+
+	for _, broker := range brokers {
+		producePartition, ok := producePartitions[broker.ID]
+		if !ok {
+			producePartition = -1
+		}
+
+		probes = append(
+			probes,
+			&brokerProbe{
+				results: &BrokerProbeResults{
+					Broker:           broker.ID,
+					Addr:             broker.Addr(),
+					TLSAddr:          TLSEndpointAddr(broker.Endpoints),
+					ProducePartition: producePartition,
+				},
+			},
+		)
+	}
+
+	for round := 0; round < config.Rounds; round++ {
+		if round > 0 {
+			select {
+			case <-time.After(config.Interval):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		wg := sync.WaitGroup{}
+
+		for _, probe := range probes {
+			wg.Add(1)
+
+			go func(probe *brokerProbe) {
+				defer wg.Done()
+
+				latencies, err := probeBroker(ctx, *probe.results, config)
+				probe.results.Attempts++
+
+				if err != nil {
+					log.Debugf("Probe of broker %d failed: %+v", probe.results.Broker, err)
+					probe.results.Failures++
+					probe.results.LastError = err.Error()
+					return
+				}
+				probe.latencies = append(probe.latencies, latencies)
+			}(probe)
+		}
+
+		wg.Wait()
+	}
+
+	results := []BrokerProbeResults{}
+
+	for _, probe := range probes {
+		var connect, tlsHandshake, metadata, produce []time.Duration
+
+		for _, latencies := range probe.latencies {
+			connect = append(connect, latencies.connect)
+			metadata = append(metadata, latencies.metadata)
+
+			if probe.results.TLSAddr != "" {
+				tlsHandshake = append(tlsHandshake, latencies.tlsHandshake)
+			}
+			if probe.results.ProducePartition >= 0 {
+				produce = append(produce, latencies.produce)
+			}
+		}
+
+		probe.results.Connect = bench.ComputeLatencyStats(connect)
+		probe.results.TLSHandshake = bench.ComputeLatencyStats(tlsHandshake)
+		probe.results.Metadata = bench.ComputeLatencyStats(metadata)
+		probe.results.Produce = bench.ComputeLatencyStats(produce)
+
+		results = append(results, *probe.results)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Broker < results[b].Broker
+	})
+
+	return results, nil
+}
+
+// TLSEndpointAddr returns the address of the first SSL or SASL_SSL listener in the argument
+// broker endpoints, or an empty string if there isn't one.
+func TLSEndpointAddr(endpoints []string) string {
+	for _, endpoint := range endpoints {
+		elements := strings.SplitN(endpoint, "://", 2)
+		if len(elements) != 2 {
+			continue
+		}
+
+		protocol := strings.ToUpper(elements[0])
+		if protocol == "SSL" || protocol == "SASL_SSL" {
+			return elements[1]
+		}
+	}
+
+	return ""
+}
+
+func probeBroker(
+	ctx context.Context,
+	broker BrokerProbeResults,
+	config ProbeConfig,
+) (probeLatencies, error) {
+	latencies := probeLatencies{}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	dialer := &net.Dialer{}
+
+	start := time.Now()
+	netConn, err := dialer.DialContext(ctx, "tcp", broker.Addr)
+	if err != nil {
+		return latencies, err
+	}
+	latencies.connect = time.Since(start)
+
+	partition := broker.ProducePartition
+	if partition < 0 {
+		partition = 0
+	}
+
+	conn := kafka.NewConnWith(
+		netConn,
+		kafka.ConnConfig{
+			ClientID:  "topicctl",
+			Topic:     config.Topic,
+			Partition: partition,
+		},
+	)
+	defer conn.Close()
+	conn.SetDeadline(deadline)
+
+	start = time.Now()
+	if _, err := conn.Brokers(); err != nil {
+		return latencies, err
+	}
+	latencies.metadata = time.Since(start)
+
+	if broker.ProducePartition >= 0 {
+		start = time.Now()
+		_, err := conn.WriteMessages(
+			kafka.Message{
+				Value: []byte("topicctl latency probe"),
+			},
+		)
+		if err != nil {
+			return latencies, err
+		}
+		latencies.produce = time.Since(start)
+	}
+
+	if broker.TLSAddr != "" {
+		tlsConn, err := dialer.DialContext(ctx, "tcp", broker.TLSAddr)
+		if err != nil {
+			return latencies, err
+		}
+		defer tlsConn.Close()
+		tlsConn.SetDeadline(deadline)
+
+		host, _, _ := net.SplitHostPort(broker.TLSAddr)
+		client := tls.Client(
+			tlsConn,
+			&tls.Config{
+				ServerName:         host,
+				InsecureSkipVerify: config.TLSSkipVerify,
+			},
+		)
+
+		start = time.Now()
+		if err := client.Handshake(); err != nil {
+			return latencies, err
+		}
+		latencies.tlsHandshake = time.Since(start)
+	}
+
+	return latencies, nil
+}
@@ -0,0 +1,76 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeBrokers(t *testing.T) {
+	ctx := context.Background()
+
+	adminClient, err := admin.NewClient(
+		ctx,
+		admin.ClientConfig{
+			ZKAddrs:        []string{util.TestZKAddr()},
+			ZKPrefix:       "",
+			BootstrapAddrs: []string{util.TestKafkaAddr()},
+			ReadOnly:       true,
+		},
+	)
+	require.Nil(t, err)
+	defer adminClient.Close()
+
+	results, err := ProbeBrokers(
+		ctx,
+		adminClient,
+		ProbeConfig{
+			Rounds:   2,
+			Interval: 10 * time.Millisecond,
+			Timeout:  5 * time.Second,
+			Topic:    util.RandomString("topic-probe-", 6),
+		},
+	)
+	require.Nil(t, err)
+
+	brokerIDs, err := adminClient.GetBrokerIDs(ctx)
+	require.Nil(t, err)
+	require.Equal(t, len(brokerIDs), len(results))
+
+	for _, result := range results {
+		assert.Equal(t, 2, result.Attempts)
+		assert.Equal(t, 0, result.Failures)
+		assert.Equal(t, 2, result.Connect.Count)
+		assert.Equal(t, 2, result.Metadata.Count)
+		assert.Equal(t, -1, result.ProducePartition)
+		assert.Equal(t, 0, result.Produce.Count)
+	}
+}
+
+func TestTLSEndpointAddr(t *testing.T) {
+	assert.Equal(
+		t,
+		"broker1:9093",
+		TLSEndpointAddr(
+			[]string{
+				"PLAINTEXT://broker1:9092",
+				"SSL://broker1:9093",
+			},
+		),
+	)
+	assert.Equal(
+		t,
+		"broker1:9094",
+		TLSEndpointAddr([]string{"sasl_ssl://broker1:9094"}),
+	)
+	assert.Equal(
+		t,
+		"",
+		TLSEndpointAddr([]string{"PLAINTEXT://broker1:9092", "invalid"}),
+	)
+}
@@ -0,0 +1,243 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+)
+
+// Severity is a string type that stores the severity of a lint result.
+type Severity string
+
+const (
+	// SeverityError is used for problems that will cause the config to be rejected by
+	// apply or check.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for problems that are probably mistakes, but won't cause
+	// the config to be rejected.
+	SeverityWarning Severity = "warning"
+)
+
+const (
+	// DefaultNamePattern is the default regular expression that topic names are expected to
+	// match.
+	DefaultNamePattern = `^[a-z0-9]+([._-][a-z0-9]+)*$`
+
+	// Retention times below this are considered "short" for compacted topics.
+	minCompactedRetentionMs = 24 * 60 * 60 * 1000
+)
+
+// Options contains the settings used for linting topic configs.
+type Options struct {
+	// NamePattern is a regular expression that topic names must match. If nil, names
+	// aren't checked against a pattern.
+	NamePattern *regexp.Regexp
+}
+
+// Result is a single problem found in a topic config.
+type Result struct {
+	Path     string
+	Severity Severity
+	Message  string
+}
+
+// String returns a human-readable representation of the result.
+func (r Result) String() string {
+	return fmt.Sprintf("%s: [%s] %s", r.Path, r.Severity, r.Message)
+}
+
+// LintTopicBytes lints the topic config with the argument YAML contents. The path is used
+// for naming checks and in the results; the file itself isn't read.
+func LintTopicBytes(path string, contents []byte, options Options) []Result {
+	results := []Result{}
+
+	addResult := func(severity Severity, format string, args ...interface{}) {
+		results = append(
+			results,
+			Result{
+				Path:     path,
+				Severity: severity,
+				Message:  fmt.Sprintf(format, args...),
+			},
+		)
+	}
+
+	// First, check the structure of the YAML, including any unknown fields
+	jsonContents, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		addResult(SeverityError, "Could not parse YAML: %+v", err)
+		return results
+	}
+
+	topicConfig := config.TopicConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonContents))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&topicConfig); err != nil {
+		addResult(SeverityError, "Invalid config structure: %+v", err)
+
+		// Try again without the strict check so that the other checks can still be run
+		topicConfig = config.TopicConfig{}
+		if err := json.Unmarshal(jsonContents, &topicConfig); err != nil {
+			return results
+		}
+	}
+
+	topicConfig.SetDefaults()
+
+	// Then, run the same validation that's done in apply and check; racks aren't known
+	// without a cluster, so rack-based checks are skipped.
+	if err := topicConfig.Validate(0); err != nil {
+		if multiErr, ok := err.(*multierror.Error); ok {
+			for _, subErr := range multiErr.Errors {
+				addResult(SeverityError, "%s", subErr.Error())
+			}
+		} else {
+			addResult(SeverityError, "%s", err.Error())
+		}
+	}
+
+	// Finally, look for things that are valid but probably mistakes
+	for _, warning := range topicWarnings(path, topicConfig, options) {
+		addResult(SeverityWarning, "%s", warning)
+	}
+
+	return results
+}
+
+// HasErrors returns whether any of the argument results are errors.
+func HasErrors(results []Result) bool {
+	for _, result := range results {
+		if result.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func topicWarnings(path string, topicConfig config.TopicConfig, options Options) []string {
+	warnings := []string{}
+	name := topicConfig.Meta.Name
+
+	if name != "" {
+		if options.NamePattern != nil && !options.NamePattern.MatchString(name) {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"Topic name %s does not match naming convention %s",
+					name,
+					options.NamePattern.String(),
+				),
+			)
+		}
+		if strings.Contains(name, ".") && strings.Contains(name, "_") {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"Topic name %s contains both '.' and '_', which can collide in Kafka metric names",
+					name,
+				),
+			)
+		}
+
+		fileName := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if fileName != name {
+			warnings = append(
+				warnings,
+				fmt.Sprintf("File name %s does not match topic name %s", fileName, name),
+			)
+		}
+	}
+
+	if topicConfig.Spec.ReplicationFactor == 1 {
+		warnings = append(
+			warnings,
+			"Replication factor of 1 means that data will be unavailable if its broker goes down",
+		)
+	}
+
+	settings := topicConfig.Spec.Settings
+
+	if minISRStr, err := settings.GetValueStr("min.insync.replicas"); err == nil &&
+		minISRStr != "" {
+		minISR, err := strconv.Atoi(minISRStr)
+		if err == nil && topicConfig.Spec.ReplicationFactor > 0 &&
+			minISR >= topicConfig.Spec.ReplicationFactor {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"min.insync.replicas (%d) is not less than the replication factor (%d); producers using acks=all will fail if any replica is down",
+					minISR,
+					topicConfig.Spec.ReplicationFactor,
+				),
+			)
+		}
+	}
+
+	retentionMs := int64(-1)
+	if topicConfig.Spec.RetentionMinutes > 0 {
+		retentionMs = int64(topicConfig.Spec.RetentionMinutes) * 60000
+	} else if retentionStr, err := settings.GetValueStr(admin.RetentionKey); err == nil &&
+		retentionStr != "" {
+		if value, err := strconv.ParseInt(retentionStr, 10, 64); err == nil {
+			retentionMs = value
+		}
+	}
+
+	cleanupPolicy, _ := settings.GetValueStr("cleanup.policy")
+	policies := strings.Split(cleanupPolicy, ",")
+	compacted := inSlice("compact", policies)
+	deleted := inSlice("delete", policies)
+
+	if compacted && retentionMs > 0 {
+		if !deleted {
+			warnings = append(
+				warnings,
+				"Retention is set but has no effect because cleanup.policy is compact",
+			)
+		} else if retentionMs < minCompactedRetentionMs {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"Compacted topic has short retention (%dms); the latest values for keys may be deleted",
+					retentionMs,
+				),
+			)
+		}
+	}
+
+	if segmentStr, err := settings.GetValueStr("segment.ms"); err == nil && segmentStr != "" {
+		segmentMs, err := strconv.ParseInt(segmentStr, 10, 64)
+		if err == nil && retentionMs > 0 && segmentMs > retentionMs {
+			warnings = append(
+				warnings,
+				fmt.Sprintf(
+					"segment.ms (%d) is greater than the retention (%dms); data may be retained longer than expected",
+					segmentMs,
+					retentionMs,
+				),
+			)
+		}
+	}
+
+	return warnings
+}
+
+func inSlice(value string, values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+	return false
+}
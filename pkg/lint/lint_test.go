@@ -0,0 +1,146 @@
+package lint
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintTopicBytes(t *testing.T) {
+	type testCase struct {
+		description string
+		path        string
+		contents    string
+		expErrors   int
+		expWarnings []string
+	}
+
+	options := Options{
+		NamePattern: regexp.MustCompile(DefaultNamePattern),
+	}
+
+	testCases := []testCase{
+		{
+			description: "clean config",
+			path:        "topics/topic-test.yaml",
+			contents: `
+meta:
+  name: topic-test
+  cluster: test-cluster
+  environment: test-env
+  region: test-region
+spec:
+  partitions: 9
+  replicationFactor: 3
+  retentionMinutes: 100
+  placement:
+    strategy: in-rack
+  settings:
+    cleanup.policy: delete
+    min.insync.replicas: 2
+`,
+		},
+		{
+			description: "unknown fields and settings",
+			path:        "topics/topic-test.yaml",
+			contents: `
+meta:
+  name: topic-test
+  cluster: test-cluster
+  environment: test-env
+  region: test-region
+spec:
+  partitions: 9
+  replicas: 3
+  placement:
+    strategy: in-rack
+  settings:
+    cleanup.polcy: delete
+`,
+			// Unknown field, missing replication factor, unknown setting
+			expErrors: 3,
+		},
+		{
+			description: "suspicious combinations",
+			path:        "topics/topic_Test.yaml",
+			contents: `
+meta:
+  name: topic_Test.v1
+  cluster: test-cluster
+  environment: test-env
+  region: test-region
+spec:
+  partitions: 9
+  replicationFactor: 1
+  retentionMinutes: 60
+  placement:
+    strategy: any
+  settings:
+    cleanup.policy: compact,delete
+    min.insync.replicas: 1
+    segment.ms: 86400000
+`,
+			expWarnings: []string{
+				"Topic name topic_Test.v1 does not match naming convention ^[a-z0-9]+([._-][a-z0-9]+)*$",
+				"Topic name topic_Test.v1 contains both '.' and '_', which can collide in Kafka metric names",
+				"File name topic_Test does not match topic name topic_Test.v1",
+				"Replication factor of 1 means that data will be unavailable if its broker goes down",
+				"min.insync.replicas (1) is not less than the replication factor (1); producers using acks=all will fail if any replica is down",
+				"Compacted topic has short retention (3600000ms); the latest values for keys may be deleted",
+				"segment.ms (86400000) is greater than the retention (3600000ms); data may be retained longer than expected",
+			},
+		},
+		{
+			description: "compact-only with retention",
+			path:        "topic-test.yaml",
+			contents: `
+meta:
+  name: topic-test
+  cluster: test-cluster
+  environment: test-env
+  region: test-region
+spec:
+  partitions: 9
+  replicationFactor: 3
+  retentionMinutes: 60
+  placement:
+    strategy: any
+  settings:
+    cleanup.policy: compact
+`,
+			expWarnings: []string{
+				"Retention is set but has no effect because cleanup.policy is compact",
+			},
+		},
+		{
+			description: "bad yaml",
+			path:        "topic-test.yaml",
+			contents:    "meta: [",
+			expErrors:   1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		results := LintTopicBytes(testCase.path, []byte(testCase.contents), options)
+
+		numErrors := 0
+		warnings := []string{}
+
+		for _, result := range results {
+			assert.Equal(t, testCase.path, result.Path, testCase.description)
+			if result.Severity == SeverityError {
+				numErrors++
+			} else {
+				warnings = append(warnings, result.Message)
+			}
+		}
+
+		assert.Equal(t, testCase.expErrors, numErrors, testCase.description)
+		assert.Equal(t, testCase.expErrors > 0, HasErrors(results), testCase.description)
+		if testCase.expWarnings == nil {
+			testCase.expWarnings = []string{}
+		}
+		assert.Equal(t, testCase.expWarnings, warnings, testCase.description)
+	}
+}
@@ -0,0 +1,314 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/segmentio/topicctl/pkg/zk"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DynamoDBLockKey is the name of the partition key in the DynamoDB lock table. The table
+	// needs to be created with a string hash key with this name.
+	DynamoDBLockKey = "LockPath"
+
+	defaultLeaseDuration = time.Minute
+	defaultRetryInterval = 2 * time.Second
+)
+
+// DynamoDBLocker is a Locker that stores locks as items in a DynamoDB table. Each item
+// has a lease that's renewed in the background while the lock is held, so locks held by
+// processes that crash are released automatically once their lease expires.
+type DynamoDBLocker struct {
+	client        dynamodbiface.DynamoDBAPI
+	table         string
+	leaseDuration time.Duration
+	retryInterval time.Duration
+	now           func() time.Time
+}
+
+var _ Locker = (*DynamoDBLocker)(nil)
+var _ LosableLock = (*dynamoDBLock)(nil)
+
+// NewDynamoDBLocker creates and returns a new DynamoDBLocker instance that stores its locks
+// in the argument table.
+func NewDynamoDBLocker(
+	client dynamodbiface.DynamoDBAPI,
+	table string,
+) *DynamoDBLocker {
+	return &DynamoDBLocker{
+		client:        client,
+		table:         table,
+		leaseDuration: defaultLeaseDuration,
+		retryInterval: defaultRetryInterval,
+		now:           time.Now,
+	}
+}
+
+// AcquireLock tries to acquire a lock for the argument path, retrying until the lock is
+// free or the context is done.
+func (d *DynamoDBLocker) AcquireLock(ctx context.Context, path string) (zk.Lock, error) {
+	metadata := zk.CurrentLockMetadata()
+	owner := util.RandomString(
+		fmt.Sprintf("%s-%d-", metadata.Host, metadata.PID),
+		8,
+	)
+
+	for {
+		err := d.putLock(ctx, path, owner, metadata)
+		if err == nil {
+			break
+		}
+		if !isConditionalCheckFailure(err) {
+			return nil, err
+		}
+
+		log.Debugf("Lock %s is held, retrying in %s", path, d.retryInterval.String())
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(d.retryInterval):
+		}
+	}
+
+	lock := &dynamoDBLock{
+		locker:   d,
+		path:     path,
+		owner:    owner,
+		metadata: metadata,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+		lostChan: make(chan struct{}),
+	}
+	go lock.renew()
+
+	return lock, nil
+}
+
+// LockHeld returns whether the lock for the argument path is held and its lease hasn't
+// expired.
+func (d *DynamoDBLocker) LockHeld(ctx context.Context, path string) (bool, error) {
+	_, held, err := d.LockHolder(ctx, path)
+	return held, err
+}
+
+// LockHolder returns the metadata stored in the lock item for the argument path, along with
+// whether the lock is held and its lease hasn't expired.
+func (d *DynamoDBLocker) LockHolder(
+	ctx context.Context,
+	path string,
+) (*zk.LockMetadata, bool, error) {
+	output, err := d.client.GetItemWithContext(
+		ctx,
+		&dynamodb.GetItemInput{
+			TableName:      aws.String(d.table),
+			Key:            lockKey(path),
+			ConsistentRead: aws.Bool(true),
+		},
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if output.Item == nil {
+		return nil, false, nil
+	}
+
+	expiresAttr, ok := output.Item["ExpiresAt"]
+	if ok && expiresAttr.N != nil {
+		expiresAt, err := strconv.ParseInt(*expiresAttr.N, 10, 64)
+		if err != nil {
+			return nil, false, err
+		}
+		if expiresAt < d.now().Unix() {
+			return nil, false, nil
+		}
+	}
+
+	return itemLockMetadata(output.Item), true, nil
+}
+
+// putLock writes the lock item for the argument owner. This succeeds if the lock is free,
+// its lease has expired, or it's already held by the same owner (i.e., the lease is being
+// renewed).
+func (d *DynamoDBLocker) putLock(
+	ctx context.Context,
+	path string,
+	owner string,
+	metadata zk.LockMetadata,
+) error {
+	now := d.now()
+
+	item := lockKey(path)
+	item["Owner"] = &dynamodb.AttributeValue{S: aws.String(owner)}
+	item["User"] = &dynamodb.AttributeValue{S: aws.String(metadata.User)}
+	item["Host"] = &dynamodb.AttributeValue{S: aws.String(metadata.Host)}
+	item["PID"] = &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(metadata.PID))}
+	item["StartTime"] = &dynamodb.AttributeValue{
+		S: aws.String(metadata.StartTime.Format(time.RFC3339)),
+	}
+	item["ExpiresAt"] = &dynamodb.AttributeValue{
+		N: aws.String(strconv.FormatInt(now.Add(d.leaseDuration).Unix(), 10)),
+	}
+
+	_, err := d.client.PutItemWithContext(
+		ctx,
+		&dynamodb.PutItemInput{
+			TableName: aws.String(d.table),
+			Item:      item,
+			ConditionExpression: aws.String(
+				"attribute_not_exists(#key) OR #owner = :owner OR #expires < :now",
+			),
+			ExpressionAttributeNames: map[string]*string{
+				"#key":     aws.String(DynamoDBLockKey),
+				"#owner":   aws.String("Owner"),
+				"#expires": aws.String("ExpiresAt"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":owner": {S: aws.String(owner)},
+				":now":   {N: aws.String(strconv.FormatInt(now.Unix(), 10))},
+			},
+		},
+	)
+	return err
+}
+
+// deleteLock deletes the lock item if it's still held by the argument owner.
+func (d *DynamoDBLocker) deleteLock(ctx context.Context, path string, owner string) error {
+	_, err := d.client.DeleteItemWithContext(
+		ctx,
+		&dynamodb.DeleteItemInput{
+			TableName:           aws.String(d.table),
+			Key:                 lockKey(path),
+			ConditionExpression: aws.String("#owner = :owner"),
+			ExpressionAttributeNames: map[string]*string{
+				"#owner": aws.String("Owner"),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":owner": {S: aws.String(owner)},
+			},
+		},
+	)
+	if isConditionalCheckFailure(err) {
+		return fmt.Errorf("Lock %s is no longer held by this process", path)
+	}
+	return err
+}
+
+type dynamoDBLock struct {
+	locker   *DynamoDBLocker
+	path     string
+	owner    string
+	metadata zk.LockMetadata
+	stopChan chan struct{}
+	doneChan chan struct{}
+	lostChan chan struct{}
+
+	mutex    sync.Mutex
+	unlocked bool
+}
+
+// Unlock stops renewing the lease and deletes the lock item.
+func (l *dynamoDBLock) Unlock() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.unlocked {
+		return errors.New("Lock is already unlocked")
+	}
+	l.unlocked = true
+
+	close(l.stopChan)
+	<-l.doneChan
+
+	return l.locker.deleteLock(context.Background(), l.path, l.owner)
+}
+
+// Lost returns a channel that's closed if the lease on the lock can't be renewed before it
+// expires or if another process takes the lock over.
+func (l *dynamoDBLock) Lost() <-chan struct{} {
+	return l.lostChan
+}
+
+// renew periodically extends the lease on the lock until Unlock is called. Failed renewals
+// are retried on the next tick; if the lease expires in the meantime, or if the lock is
+// held by someone else, the lock is marked as lost and renewal stops.
+func (l *dynamoDBLock) renew() {
+	defer close(l.doneChan)
+
+	ticker := time.NewTicker(l.locker.leaseDuration / 3)
+	defer ticker.Stop()
+
+	leaseExpiry := l.locker.now().Add(l.locker.leaseDuration)
+
+	for {
+		select {
+		case <-l.stopChan:
+			return
+		case <-ticker.C:
+			renewStart := l.locker.now()
+			err := l.locker.putLock(context.Background(), l.path, l.owner, l.metadata)
+			if err == nil {
+				leaseExpiry = renewStart.Add(l.locker.leaseDuration)
+				continue
+			}
+
+			if isConditionalCheckFailure(err) {
+				log.Errorf("Lock %s has been taken over by another process", l.path)
+			} else if !l.locker.now().Before(leaseExpiry) {
+				log.Errorf(
+					"Could not renew lease for lock %s before it expired: %+v",
+					l.path,
+					err,
+				)
+			} else {
+				log.Warnf("Could not renew lease for lock %s, retrying: %+v", l.path, err)
+				continue
+			}
+
+			close(l.lostChan)
+			return
+		}
+	}
+}
+
+// itemLockMetadata returns the holder metadata stored in the argument lock item. Fields that
+// are missing or can't be parsed are left empty.
+func itemLockMetadata(item map[string]*dynamodb.AttributeValue) *zk.LockMetadata {
+	metadata := &zk.LockMetadata{}
+
+	if attr, ok := item["User"]; ok && attr.S != nil {
+		metadata.User = *attr.S
+	}
+	if attr, ok := item["Host"]; ok && attr.S != nil {
+		metadata.Host = *attr.S
+	}
+	if attr, ok := item["PID"]; ok && attr.N != nil {
+		metadata.PID, _ = strconv.Atoi(*attr.N)
+	}
+	if attr, ok := item["StartTime"]; ok && attr.S != nil {
+		metadata.StartTime, _ = time.Parse(time.RFC3339, *attr.S)
+	}
+
+	return metadata
+}
+
+func lockKey(path string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		DynamoDBLockKey: {S: aws.String(path)},
+	}
+}
+
+func isConditionalCheckFailure(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
@@ -0,0 +1,205 @@
+package locks
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamoDBLocker(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDBClient{
+		items: map[string]map[string]*dynamodb.AttributeValue{},
+	}
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	locker1 := NewDynamoDBLocker(client, "test-table")
+	locker1.now = func() time.Time { return now }
+	locker2 := NewDynamoDBLocker(client, "test-table")
+	locker2.now = func() time.Time { return now }
+	locker2.retryInterval = 10 * time.Millisecond
+
+	held, err := locker1.LockHeld(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	lock1, err := locker1.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+
+	held, err = locker2.LockHeld(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	metadata, held, err := locker2.LockHolder(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	assert.True(t, held)
+	require.NotNil(t, metadata)
+	assert.Equal(t, os.Getpid(), metadata.PID)
+
+	// Other paths are independent
+	otherLock, err := locker2.AcquireLock(ctx, "/locks/other-lock")
+	require.NoError(t, err)
+	require.NoError(t, otherLock.Unlock())
+
+	// Second locker can't get the lock while it's held
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = locker2.AcquireLock(timeoutCtx, "/locks/test-lock")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	require.NoError(t, lock1.Unlock())
+	assert.Error(t, lock1.Unlock())
+
+	held, err = locker1.LockHeld(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	lock2, err := locker2.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	require.NoError(t, lock2.Unlock())
+}
+
+func TestDynamoDBLockerExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDBClient{
+		items: map[string]map[string]*dynamodb.AttributeValue{},
+	}
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	locker1 := NewDynamoDBLocker(client, "test-table")
+	locker1.now = func() time.Time { return now }
+	// Make sure that the lease isn't renewed during the test
+	locker1.leaseDuration = time.Hour
+
+	lock1, err := locker1.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+
+	// Simulate a crashed holder by moving the clock past the end of the lease
+	locker2 := NewDynamoDBLocker(client, "test-table")
+	locker2.now = func() time.Time { return now.Add(2 * time.Hour) }
+
+	held, err := locker2.LockHeld(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	lock2, err := locker2.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+
+	// The original holder can't delete the lock after it's been taken over
+	assert.Error(t, lock1.Unlock())
+	require.NoError(t, lock2.Unlock())
+}
+
+// fakeDynamoDBClient is a minimal, in-memory implementation of the DynamoDB calls
+// made by DynamoDBLocker. It only supports the condition expressions used there.
+type fakeDynamoDBClient struct {
+	dynamodbiface.DynamoDBAPI
+
+	sync.Mutex
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func (f *fakeDynamoDBClient) GetItemWithContext(
+	ctx aws.Context,
+	input *dynamodb.GetItemInput,
+	opts ...request.Option,
+) (*dynamodb.GetItemOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	return &dynamodb.GetItemOutput{
+		Item: f.items[*input.Key[DynamoDBLockKey].S],
+	}, nil
+}
+
+func (f *fakeDynamoDBClient) PutItemWithContext(
+	ctx aws.Context,
+	input *dynamodb.PutItemInput,
+	opts ...request.Option,
+) (*dynamodb.PutItemOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	key := *input.Item[DynamoDBLockKey].S
+	if curr, ok := f.items[key]; ok {
+		owner := *input.ExpressionAttributeValues[":owner"].S
+		now, _ := strconv.ParseInt(*input.ExpressionAttributeValues[":now"].N, 10, 64)
+		expiresAt, _ := strconv.ParseInt(*curr["ExpiresAt"].N, 10, 64)
+
+		if *curr["Owner"].S != owner && expiresAt >= now {
+			return nil, conditionalCheckFailure()
+		}
+	}
+
+	f.items[key] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDBClient) DeleteItemWithContext(
+	ctx aws.Context,
+	input *dynamodb.DeleteItemInput,
+	opts ...request.Option,
+) (*dynamodb.DeleteItemOutput, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	key := *input.Key[DynamoDBLockKey].S
+	curr, ok := f.items[key]
+	if !ok || *curr["Owner"].S != *input.ExpressionAttributeValues[":owner"].S {
+		return nil, conditionalCheckFailure()
+	}
+
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func conditionalCheckFailure() error {
+	return awserr.New(
+		dynamodb.ErrCodeConditionalCheckFailedException,
+		"The conditional request failed",
+		nil,
+	)
+}
+
+func TestDynamoDBLockerLostLease(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDBClient{
+		items: map[string]map[string]*dynamodb.AttributeValue{},
+	}
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	locker1 := NewDynamoDBLocker(client, "test-table")
+	locker1.now = func() time.Time { return now }
+	locker1.leaseDuration = 30 * time.Millisecond
+
+	lock1, err := locker1.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+	losableLock, ok := lock1.(LosableLock)
+	require.True(t, ok)
+
+	// Simulate another process taking over the lock after the lease expired
+	locker2 := NewDynamoDBLocker(client, "test-table")
+	locker2.now = func() time.Time { return now.Add(2 * time.Hour) }
+	lock2, err := locker2.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+
+	select {
+	case <-losableLock.Lost():
+	case <-time.After(time.Second):
+		assert.Fail(t, "Lock wasn't marked as lost")
+	}
+
+	assert.Error(t, lock1.Unlock())
+	require.NoError(t, lock2.Unlock())
+}
@@ -0,0 +1,39 @@
+package locks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/topicctl/pkg/zk"
+)
+
+// ErrLockLost is returned by operations that stop because a lock they were holding was lost,
+// e.g. because its lease couldn't be renewed and another process took it over.
+var ErrLockLost = errors.New("Lock was lost while it was held")
+
+// Locker is an interface for backends that can be used to serialize apply runs. The default
+// backend is zookeeper, via the admin client, but alternatives like DynamoDB can be used
+// when zookeeper access is restricted or when applies run from many CI workers.
+type Locker interface {
+	// AcquireLock acquires a lock for the argument path, blocking until the lock is
+	// available or the context is done.
+	AcquireLock(ctx context.Context, path string) (zk.Lock, error)
+
+	// LockHeld returns whether the lock for the argument path is currently held by anyone.
+	LockHeld(ctx context.Context, path string) (bool, error)
+
+	// LockHolder returns the metadata of the process that holds the lock for the argument
+	// path, along with whether the lock is held at all. The metadata is nil if the lock is
+	// free or if its holder didn't record any.
+	LockHolder(ctx context.Context, path string) (*zk.LockMetadata, bool, error)
+}
+
+// LosableLock is implemented by locks that can be lost while they're held, e.g. because
+// they're based on leases that have to be renewed. Holders should check for this and stop
+// making changes once the lock is lost.
+type LosableLock interface {
+	zk.Lock
+
+	// Lost returns a channel that's closed if the lock is lost before it's unlocked.
+	Lost() <-chan struct{}
+}
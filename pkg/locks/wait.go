@@ -0,0 +1,90 @@
+package locks
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWaitBackoff    = 2 * time.Second
+	defaultWaitMaxBackoff = 30 * time.Second
+)
+
+// WaitForLock waits for at most maxWait until the lock for the argument path is free,
+// logging who holds it in the meantime. The lock is polled with exponential backoff plus
+// some jitter so that many processes queued behind the same lock, e.g. concurrent CI jobs,
+// don't all check it at once.
+//
+// The lock isn't acquired, so another process can still take it first; callers should treat
+// a nil return as a hint that acquiring it is likely to succeed soon.
+func WaitForLock(
+	ctx context.Context,
+	locker Locker,
+	path string,
+	maxWait time.Duration,
+) error {
+	return waitForLock(ctx, locker, path, maxWait, defaultWaitBackoff, defaultWaitMaxBackoff)
+}
+
+func waitForLock(
+	ctx context.Context,
+	locker Locker,
+	path string,
+	maxWait time.Duration,
+	backoff time.Duration,
+	maxBackoff time.Duration,
+) error {
+	deadline := time.Now().Add(maxWait)
+	var lastHolder string
+
+	for {
+		metadata, held, err := locker.LockHolder(ctx, path)
+		if err != nil {
+			return err
+		}
+		if !held {
+			return nil
+		}
+
+		holder := "an unknown process"
+		if metadata != nil {
+			holder = metadata.String()
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf(
+				"Timed out after %s waiting for lock %s, which is held by %s",
+				maxWait.String(),
+				path,
+				holder,
+			)
+		}
+
+		if holder != lastHolder {
+			log.Infof("Lock %s is held by %s; waiting for it to be released", path, holder)
+			lastHolder = holder
+		}
+
+		sleepTime := backoff + time.Duration(rand.Int63n(int64(backoff)/5+1))
+		if sleepTime > remaining {
+			sleepTime = remaining
+		}
+		log.Debugf("Checking lock %s again in %s", path, sleepTime.String())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sleepTime):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
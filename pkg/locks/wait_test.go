@@ -0,0 +1,52 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForLock(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeDynamoDBClient{
+		items: map[string]map[string]*dynamodb.AttributeValue{},
+	}
+	locker := NewDynamoDBLocker(client, "test-table")
+
+	// Free locks don't need to be waited for
+	err := waitForLock(ctx, locker, "/locks/test-lock", time.Second, time.Millisecond, time.Millisecond)
+	require.NoError(t, err)
+
+	lock, err := locker.AcquireLock(ctx, "/locks/test-lock")
+	require.NoError(t, err)
+
+	err = waitForLock(
+		ctx,
+		locker,
+		"/locks/test-lock",
+		50*time.Millisecond,
+		5*time.Millisecond,
+		10*time.Millisecond,
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Timed out after 50ms waiting for lock /locks/test-lock")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Unlock()
+	}()
+
+	err = waitForLock(
+		ctx,
+		locker,
+		"/locks/test-lock",
+		5*time.Second,
+		5*time.Millisecond,
+		10*time.Millisecond,
+	)
+	require.NoError(t, err)
+}
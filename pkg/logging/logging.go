@@ -0,0 +1,109 @@
+// Package logging contains helpers for configuring the topicctl logs, including the JSON
+// output format and the correlation IDs that group the log lines for a single operation.
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+	prefixed "github.com/x-cray/logrus-prefixed-formatter"
+)
+
+// Format is a string type that stores a log output format.
+type Format string
+
+const (
+	// FormatText is the default, human-readable log format.
+	FormatText Format = "text"
+
+	// FormatJSON outputs each log entry as a JSON object, which is easier to parse in
+	// centralized logging systems.
+	FormatJSON Format = "json"
+)
+
+// AllFormats contains all of the supported log formats.
+var AllFormats = []Format{FormatText, FormatJSON}
+
+// CorrelationIDField is the log field that stores the correlation ID of the current
+// operation.
+const CorrelationIDField = "correlation_id"
+
+// colorsDisabled is set by DisableColors so that the text formatter stays uncolored if the
+// format is set again afterwards.
+var colorsDisabled bool
+
+// SetFormat sets the output format of the standard logger.
+func SetFormat(format Format) error {
+	switch format {
+	case FormatText:
+		log.SetFormatter(&prefixed.TextFormatter{
+			TimestampFormat: "2006-01-02 15:04:05",
+			FullTimestamp:   true,
+			DisableColors:   colorsDisabled,
+		})
+	case FormatJSON:
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("Log format must be in %+v", AllFormats)
+	}
+	return nil
+}
+
+// DisableColors turns off colors in both the log output and the tables and diffs that
+// topicctl prints. It should be called when the output is going to a file or CI log, where
+// the escape codes would just add noise.
+func DisableColors() {
+	colorsDisabled = true
+	color.NoColor = true
+
+	if formatter, ok := log.StandardLogger().Formatter.(*prefixed.TextFormatter); ok {
+		formatter.DisableColors = true
+	}
+}
+
+// NewCorrelationID returns a new, random correlation ID.
+func NewCorrelationID() string {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		// This should never happen, but the ID isn't critical enough to fail on
+		return "unknown"
+	}
+	return hex.EncodeToString(idBytes)
+}
+
+// NewCorrelationHook returns a logrus hook that adds the argument correlation ID to all
+// log entries. It should be added before any hooks that write entries elsewhere so that
+// these include the ID too.
+func NewCorrelationHook(id string) log.Hook {
+	return &correlationHook{id: id}
+}
+
+// WithCorrelationID adds the argument correlation ID to all entries from the standard
+// logger until the returned function is called.
+func WithCorrelationID(id string) func() {
+	origHooks := log.LevelHooks{}
+	for level, hooks := range log.StandardLogger().Hooks {
+		origHooks[level] = append([]log.Hook{}, hooks...)
+	}
+	log.AddHook(NewCorrelationHook(id))
+
+	return func() {
+		log.StandardLogger().ReplaceHooks(origHooks)
+	}
+}
+
+type correlationHook struct {
+	id string
+}
+
+func (h *correlationHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *correlationHook) Fire(entry *log.Entry) error {
+	entry.Data[CorrelationIDField] = h.id
+	return nil
+}
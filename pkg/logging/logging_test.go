@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCorrelationID(t *testing.T) {
+	logger := log.StandardLogger()
+	origOut := logger.Out
+	origFormatter := logger.Formatter
+	defer func() {
+		logger.SetOutput(origOut)
+		logger.SetFormatter(origFormatter)
+	}()
+
+	buf := &bytes.Buffer{}
+	logger.SetOutput(buf)
+	require.Nil(t, SetFormat(FormatJSON))
+
+	id := NewCorrelationID()
+	assert.Equal(t, 16, len(id))
+	assert.NotEqual(t, id, NewCorrelationID())
+
+	restore := WithCorrelationID(id)
+	log.Info("first message")
+	restore()
+	log.Info("second message")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Equal(t, 2, len(lines))
+
+	first := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, "first message", first["msg"])
+	assert.Equal(t, id, first[CorrelationIDField])
+
+	second := map[string]interface{}{}
+	require.Nil(t, json.Unmarshal(lines[1], &second))
+	assert.Equal(t, "second message", second["msg"])
+	_, ok := second[CorrelationIDField]
+	assert.False(t, ok)
+
+	assert.NotNil(t, SetFormat("xml"))
+}
@@ -259,7 +259,11 @@ func dialLeaderRetries(
 		}
 		log.Debugf("Error dialing partition %d: %+v; retrying", partition, err)
 
-		time.Sleep(sleepDuration)
+		select {
+		case <-time.After(sleepDuration):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 		sleepDuration *= 2
 	}
 
@@ -0,0 +1,280 @@
+package messages
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CodecPrefixExec is the prefix for codec specs that run a subprocess.
+	CodecPrefixExec = "exec:"
+
+	// CodecPrefixPlugin is the prefix for codec specs that load a Go plugin.
+	CodecPrefixPlugin = "plugin:"
+)
+
+// Codec converts message values between their serialized form in Kafka and a readable one,
+// e.g. for proprietary serialization formats. Decode is used when printing messages and
+// Encode when writing them.
+type Codec interface {
+	Decode(ctx context.Context, topic string, data []byte) ([]byte, error)
+	Encode(ctx context.Context, topic string, data []byte) ([]byte, error)
+	Close() error
+}
+
+// NewCodec creates a codec from the argument spec, which is either "exec:" followed by a
+// command to run as a subprocess (see SubprocessCodec) or "plugin:" followed by the path
+// to a Go plugin (see PluginCodec).
+func NewCodec(spec string) (Codec, error) {
+	switch {
+	case strings.HasPrefix(spec, CodecPrefixExec):
+		return NewSubprocessCodec(strings.TrimPrefix(spec, CodecPrefixExec))
+	case strings.HasPrefix(spec, CodecPrefixPlugin):
+		return NewPluginCodec(strings.TrimPrefix(spec, CodecPrefixPlugin))
+	default:
+		return nil, fmt.Errorf(
+			"Codec must start with %s or %s: %s",
+			CodecPrefixExec,
+			CodecPrefixPlugin,
+			spec,
+		)
+	}
+}
+
+// DecodeMessage returns a copy of the argument message with its value decoded by the
+// argument codec. If the codec is nil, the message is returned as-is.
+func DecodeMessage(
+	ctx context.Context,
+	codec Codec,
+	message kafka.Message,
+) (kafka.Message, error) {
+	if codec == nil {
+		return message, nil
+	}
+
+	value, err := codec.Decode(ctx, message.Topic, message.Value)
+	if err != nil {
+		return message, fmt.Errorf(
+			"Error decoding message at offset %d in topic %s, partition %d: %+v",
+			message.Offset,
+			message.Topic,
+			message.Partition,
+			err,
+		)
+	}
+	message.Value = value
+	return message, nil
+}
+
+// codecRequest is a single request to a subprocess codec. Data is base64-encoded.
+type codecRequest struct {
+	Op    string `json:"op"`
+	Topic string `json:"topic"`
+	Data  []byte `json:"data"`
+}
+
+// codecResponse is the response to a codecRequest.
+type codecResponse struct {
+	Data  []byte `json:"data"`
+	Error string `json:"error,omitempty"`
+}
+
+// SubprocessCodec is a codec that runs an external command and exchanges messages with it
+// over its stdin and stdout. Each request is written as a single line of JSON with "op" set
+// to "decode" or "encode", along with the "topic" and the base64-encoded "data". The command
+// should reply with one line of JSON per request, containing either the base64-encoded "data"
+// of the result or an "error" string. The command's stderr is passed through.
+type SubprocessCodec struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	mutex  sync.Mutex
+}
+
+// NewSubprocessCodec starts the argument command, which is run via the shell, and returns
+// a codec that sends requests to it.
+func NewSubprocessCodec(command string) (*SubprocessCodec, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, errors.New("Codec command cannot be empty")
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Starting codec command: %s", command)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Error starting codec command: %+v", err)
+	}
+
+	return &SubprocessCodec{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}, nil
+}
+
+// Decode decodes the argument data via the subprocess.
+func (s *SubprocessCodec) Decode(
+	ctx context.Context,
+	topic string,
+	data []byte,
+) ([]byte, error) {
+	return s.roundTrip(ctx, "decode", topic, data)
+}
+
+// Encode encodes the argument data via the subprocess.
+func (s *SubprocessCodec) Encode(
+	ctx context.Context,
+	topic string,
+	data []byte,
+) ([]byte, error) {
+	return s.roundTrip(ctx, "encode", topic, data)
+}
+
+// Close closes the subprocess's stdin and waits for it to exit.
+func (s *SubprocessCodec) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.stdin.Close(); err != nil {
+		return err
+	}
+	return s.cmd.Wait()
+}
+
+func (s *SubprocessCodec) roundTrip(
+	ctx context.Context,
+	op string,
+	topic string,
+	data []byte,
+) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Requests are sent one at a time so that the responses can be matched up with them
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	contents, err := json.Marshal(codecRequest{Op: op, Topic: topic, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.stdin.Write(append(contents, '\n')); err != nil {
+		return nil, fmt.Errorf("Error writing to codec command: %+v", err)
+	}
+
+	line, err := s.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("Error reading from codec command: %+v", err)
+	}
+
+	response := codecResponse{}
+	if err := json.Unmarshal(line, &response); err != nil {
+		return nil, fmt.Errorf("Could not parse codec command response: %+v", err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Data, nil
+}
+
+// PluginCodec is a codec that calls functions in a Go plugin, built with
+// "go build -buildmode=plugin". The plugin must export a Decode function and can optionally
+// export an Encode one, both with the signature:
+//
+//	func(topic string, data []byte) ([]byte, error)
+//
+// Plugins are only supported on Linux and macOS, and must be built with the same version of
+// Go as topicctl.
+type PluginCodec struct {
+	decode func(topic string, data []byte) ([]byte, error)
+	encode func(topic string, data []byte) ([]byte, error)
+}
+
+// NewPluginCodec loads the plugin at the argument path and returns a codec that calls its
+// functions.
+func NewPluginCodec(path string) (*PluginCodec, error) {
+	plug, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading codec plugin %s: %+v", path, err)
+	}
+
+	codec := &PluginCodec{}
+
+	decodeSym, err := plug.Lookup("Decode")
+	if err != nil {
+		return nil, fmt.Errorf("Codec plugin %s doesn't export a Decode function", path)
+	}
+	codec.decode, err = pluginCodecFunc(decodeSym)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Decode function in codec plugin %s: %+v", path, err)
+	}
+
+	if encodeSym, err := plug.Lookup("Encode"); err == nil {
+		codec.encode, err = pluginCodecFunc(encodeSym)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid Encode function in codec plugin %s: %+v", path, err)
+		}
+	}
+
+	return codec, nil
+}
+
+// Decode decodes the argument data via the plugin.
+func (p *PluginCodec) Decode(
+	ctx context.Context,
+	topic string,
+	data []byte,
+) ([]byte, error) {
+	return p.decode(topic, data)
+}
+
+// Encode encodes the argument data via the plugin, if it supports encoding.
+func (p *PluginCodec) Encode(
+	ctx context.Context,
+	topic string,
+	data []byte,
+) ([]byte, error) {
+	if p.encode == nil {
+		return nil, errors.New("Codec plugin doesn't support encoding")
+	}
+	return p.encode(topic, data)
+}
+
+// Close is a no-op since plugins can't be unloaded.
+func (p *PluginCodec) Close() error {
+	return nil
+}
+
+func pluginCodecFunc(sym plugin.Symbol) (func(string, []byte) ([]byte, error), error) {
+	switch fn := sym.(type) {
+	case func(string, []byte) ([]byte, error):
+		return fn, nil
+	case *func(string, []byte) ([]byte, error):
+		return *fn, nil
+	default:
+		return nil, fmt.Errorf("Unexpected type %T", sym)
+	}
+}
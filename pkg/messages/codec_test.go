@@ -0,0 +1,97 @@
+package messages
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const codecHelperEnvVar = "TOPICCTL_CODEC_HELPER"
+
+// TestCodecHelperProcess isn't a real test; it's run as the subprocess in
+// TestSubprocessCodec. It upper-cases data on decode and lower-cases it on encode.
+func TestCodecHelperProcess(t *testing.T) {
+	if os.Getenv(codecHelperEnvVar) != "1" {
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		request := codecRequest{}
+		response := codecResponse{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			response.Error = err.Error()
+		} else if string(request.Data) == "bad" {
+			response.Error = "bad data"
+		} else if request.Op == "decode" {
+			response.Data = bytes.ToUpper(request.Data)
+		} else {
+			response.Data = bytes.ToLower(request.Data)
+		}
+
+		contents, _ := json.Marshal(response)
+		fmt.Println(string(contents))
+	}
+	os.Exit(0)
+}
+
+func TestSubprocessCodec(t *testing.T) {
+	os.Setenv(codecHelperEnvVar, "1")
+	defer os.Unsetenv(codecHelperEnvVar)
+
+	ctx := context.Background()
+
+	codec, err := NewCodec(
+		fmt.Sprintf("exec:%s -test.run=TestCodecHelperProcess", os.Args[0]),
+	)
+	require.NoError(t, err)
+
+	decoded, err := codec.Decode(ctx, "topic1", []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("HELLO"), decoded)
+
+	encoded, err := codec.Encode(ctx, "topic1", []byte("HELLO"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), encoded)
+
+	_, err = codec.Decode(ctx, "topic1", []byte("bad"))
+	assert.EqualError(t, err, "bad data")
+
+	message, err := DecodeMessage(
+		ctx,
+		codec,
+		kafka.Message{Topic: "topic1", Value: []byte("value")},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("VALUE"), message.Value)
+
+	_, err = DecodeMessage(ctx, codec, kafka.Message{Topic: "topic1", Value: []byte("bad")})
+	assert.Error(t, err)
+
+	assert.NoError(t, codec.Close())
+}
+
+func TestNewCodecErrors(t *testing.T) {
+	_, err := NewCodec("unknown:codec")
+	assert.Error(t, err)
+
+	_, err = NewCodec("exec:")
+	assert.Error(t, err)
+
+	_, err = NewCodec("plugin:/non-existent/codec.so")
+	assert.Error(t, err)
+
+	message := kafka.Message{Value: []byte("value")}
+	decoded, err := DecodeMessage(context.Background(), nil, message)
+	require.NoError(t, err)
+	assert.Equal(t, message, decoded)
+}
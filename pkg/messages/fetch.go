@@ -0,0 +1,157 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxFetchMessages is the maximum number of messages that can be fetched in a single
+	// GetMessagesInRange call.
+	MaxFetchMessages = 100
+
+	maxFetchBatchBytes = 10e6
+)
+
+// GetMessagesInRange fetches the messages with offsets between startOffset and endOffset
+// (inclusive) in a single topic partition. The returned slice can have fewer messages than
+// the size of the range if the topic is compacted or if the range includes control records.
+func GetMessagesInRange(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partition int,
+	startOffset int64,
+	endOffset int64,
+) ([]kafka.Message, error) {
+	if startOffset < 0 {
+		return nil, fmt.Errorf("Start offset (%d) cannot be negative", startOffset)
+	}
+	if endOffset < startOffset {
+		return nil, fmt.Errorf(
+			"End offset (%d) must be greater than or equal to start offset (%d)",
+			endOffset,
+			startOffset,
+		)
+	}
+	if endOffset-startOffset+1 > MaxFetchMessages {
+		return nil, fmt.Errorf("Can fetch at most %d messages at a time", MaxFetchMessages)
+	}
+
+	conn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	firstOffset, lastOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error getting offsets for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	// The last offset is the offset of the next message that will be written
+	if startOffset < firstOffset || endOffset >= lastOffset {
+		return nil, fmt.Errorf(
+			"Offsets %d->%d are not in the range of available offsets for partition %d (%d->%d)",
+			startOffset,
+			endOffset,
+			partition,
+			firstOffset,
+			lastOffset-1,
+		)
+	}
+
+	_, err = conn.Seek(startOffset, kafka.SeekAbsolute|kafka.SeekDontCheck)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error seeking for partition %d at offset %d: %+v",
+			partition,
+			startOffset,
+			err,
+		)
+	}
+
+	messages := []kafka.Message{}
+
+	for {
+		batch := conn.ReadBatch(1, maxFetchBatchBytes)
+
+		done := false
+		numRead := 0
+
+		for {
+			message, err := batch.ReadMessage()
+			if err != nil {
+				break
+			}
+			numRead++
+
+			// Batches can start before the requested offset
+			if message.Offset < startOffset {
+				continue
+			}
+			if message.Offset > endOffset {
+				done = true
+				break
+			}
+
+			message.Topic = topic
+			message.Partition = partition
+			messages = append(messages, message)
+
+			if message.Offset == endOffset {
+				done = true
+				break
+			}
+		}
+
+		if err := batch.Close(); err != nil {
+			return nil, fmt.Errorf(
+				"Error reading messages for partition %d: %+v",
+				partition,
+				err,
+			)
+		}
+		if done || numRead == 0 {
+			break
+		}
+
+		log.Debugf(
+			"Fetched %d messages in partition %d so far, reading another batch",
+			len(messages),
+			partition,
+		)
+	}
+
+	return messages, nil
+}
+
+// ParseOffsetRange parses either a single offset (e.g., "1234") or an inclusive range of
+// offsets (e.g., "1234-1240").
+func ParseOffsetRange(offsetStr string) (int64, int64, error) {
+	elements := strings.SplitN(offsetStr, "-", 2)
+
+	startOffset, err := strconv.ParseInt(elements[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Could not parse offset %s: %+v", offsetStr, err)
+	}
+	if len(elements) == 1 {
+		return startOffset, startOffset, nil
+	}
+
+	endOffset, err := strconv.ParseInt(elements[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Could not parse offset range %s: %+v", offsetStr, err)
+	}
+
+	return startOffset, endOffset, nil
+}
@@ -0,0 +1,85 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMessagesInRange(t *testing.T) {
+	ctx := context.Background()
+	controllerConn := util.TestKafkaContollerConn(ctx, t)
+
+	topicName := util.RandomString("topic-fetch-", 6)
+
+	err := controllerConn.CreateTopics(
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     1,
+			ReplicationFactor: 1,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:   []string{util.TestKafkaAddr()},
+			Topic:     topicName,
+			BatchSize: 5,
+		},
+	)
+	defer writer.Close()
+
+	messages := []kafka.Message{}
+	for i := 0; i < 20; i++ {
+		messages = append(
+			messages,
+			kafka.Message{
+				Key:   []byte(fmt.Sprintf("key%d", i)),
+				Value: []byte(fmt.Sprintf("value%d", i)),
+			},
+		)
+	}
+	require.Nil(t, writer.WriteMessages(ctx, messages...))
+
+	fetched, err := GetMessagesInRange(ctx, util.TestKafkaAddr(), topicName, 0, 7, 7)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(fetched))
+	assert.Equal(t, int64(7), fetched[0].Offset)
+	assert.Equal(t, "key7", string(fetched[0].Key))
+	assert.Equal(t, topicName, fetched[0].Topic)
+
+	fetched, err = GetMessagesInRange(ctx, util.TestKafkaAddr(), topicName, 0, 3, 12)
+	require.Nil(t, err)
+	require.Equal(t, 10, len(fetched))
+	for i, message := range fetched {
+		assert.Equal(t, int64(i+3), message.Offset)
+	}
+
+	_, err = GetMessagesInRange(ctx, util.TestKafkaAddr(), topicName, 0, 15, 25)
+	assert.NotNil(t, err)
+}
+
+func TestParseOffsetRange(t *testing.T) {
+	start, end, err := ParseOffsetRange("1234")
+	require.Nil(t, err)
+	assert.Equal(t, int64(1234), start)
+	assert.Equal(t, int64(1234), end)
+
+	start, end, err = ParseOffsetRange("1234-1240")
+	require.Nil(t, err)
+	assert.Equal(t, int64(1234), start)
+	assert.Equal(t, int64(1240), end)
+
+	_, _, err = ParseOffsetRange("abc")
+	assert.NotNil(t, err)
+	_, _, err = ParseOffsetRange("10-abc")
+	assert.NotNil(t, err)
+}
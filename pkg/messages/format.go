@@ -4,22 +4,30 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/segmentio/topicctl/pkg/util"
 )
 
-// FormatTailStats generates a pretty table from a TailStats instance.
+// FormatTailStats generates a pretty table from a TailStats instance. The topic is only
+// shown if more than one topic was tailed.
 func FormatTailStats(stats TailStats, filtered bool) string {
 	buf := &bytes.Buffer{}
 
 	table := tablewriter.NewWriter(buf)
 
-	var headerNames []string
+	multiTopic := len(stats.PartitionStats) > 1
+	headerNames := []string{}
+
+	if multiTopic {
+		headerNames = append(headerNames, "Topic")
+	}
 
 	if filtered {
-		headerNames = []string{
+		headerNames = append(
+			headerNames,
 			"Partition",
 			"Messages Tailed\n(Total)",
 			"Messages Tailed\n(Filtered)",
@@ -27,31 +35,28 @@ func FormatTailStats(stats TailStats, filtered bool) string {
 			"First Time",
 			"Last Offset",
 			"Last Time",
-		}
+		)
 	} else {
-		headerNames = []string{
+		headerNames = append(
+			headerNames,
 			"Partition",
 			"Messages Tailed",
 			"First Offset",
 			"First Time",
 			"Last Offset",
 			"Last Time",
-		}
+		)
 	}
 
 	table.SetHeader(headerNames)
 
 	table.SetAutoWrapText(false)
-	table.SetColumnAlignment(
-		[]int{
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-			tablewriter.ALIGN_LEFT,
-		},
-	)
+
+	alignments := []int{}
+	for i := 0; i < len(headerNames); i++ {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
 	table.SetBorders(
 		tablewriter.Border{
 			Left:   false,
@@ -61,49 +66,162 @@ func FormatTailStats(stats TailStats, filtered bool) string {
 		},
 	)
 
-	partitions := []int{}
+	topics := []string{}
+	for topic := range stats.PartitionStats {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
 
-	for partition, partitionStats := range stats.PartitionStats {
-		if partitionStats.TotalMessages == 0 {
-			continue
+	for _, topic := range topics {
+		partitions := []int{}
+
+		for partition, partitionStats := range stats.PartitionStats[topic] {
+			if partitionStats.TotalMessages == 0 {
+				continue
+			}
+			partitions = append(partitions, partition)
 		}
-		partitions = append(partitions, partition)
-	}
 
-	sort.Slice(partitions, func(a, b int) bool {
-		return partitions[a] < partitions[b]
-	})
+		sort.Slice(partitions, func(a, b int) bool {
+			return partitions[a] < partitions[b]
+		})
 
-	for _, partition := range partitions {
-		partitionStats := stats.PartitionStats[partition]
+		for _, partition := range partitions {
+			partitionStats := stats.PartitionStats[topic][partition]
 
-		columnValues := []string{
-			fmt.Sprintf("%d", partition),
-			fmt.Sprintf("%d", partitionStats.TotalMessages),
-		}
+			columnValues := []string{}
+
+			if multiTopic {
+				columnValues = append(columnValues, topic)
+			}
 
-		if filtered {
 			columnValues = append(
 				columnValues,
-				fmt.Sprintf("%d", partitionStats.TotalMessagesFiltered),
+				fmt.Sprintf("%d", partition),
+				fmt.Sprintf("%d", partitionStats.TotalMessages),
 			)
+
+			if filtered {
+				columnValues = append(
+					columnValues,
+					fmt.Sprintf("%d", partitionStats.TotalMessagesFiltered),
+				)
+			}
+
+			columnValues = append(
+				columnValues,
+				fmt.Sprintf("%d", partitionStats.FirstOffset),
+				partitionStats.FirstTime.Format(time.RFC3339),
+				fmt.Sprintf("%d", partitionStats.LastOffset),
+				partitionStats.LastTime.Format(time.RFC3339),
+			)
+
+			table.Append(columnValues)
 		}
+	}
 
-		columnValues = append(
-			columnValues,
-			fmt.Sprintf("%d", partitionStats.FirstOffset),
-			partitionStats.FirstTime.Format(time.RFC3339),
-			fmt.Sprintf("%d", partitionStats.LastOffset),
-			partitionStats.LastTime.Format(time.RFC3339),
-		)
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatHeaderSummary generates a pretty table from a HeaderSummary instance.
+func FormatHeaderSummary(summary HeaderSummary) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	headerNames := []string{
+		fmt.Sprintf("Value of\n%s", summary.HeaderKey),
+		"Messages",
+		"Percent",
+		"Rate",
+		"Bytes",
+		"Partitions",
+		"First Time",
+		"Last Time",
+	}
+	table.SetHeader(headerNames)
+
+	table.SetAutoWrapText(false)
+
+	alignments := []int{}
+	for i := 0; i < len(headerNames); i++ {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	duration := summary.EndTime.Sub(summary.StartTime)
 
-		table.Append(columnValues)
+	for _, valueCount := range summary.Values {
+		value := valueCount.Value
+		if valueCount.Missing {
+			value = "(missing)"
+		} else if value == "" {
+			value = "(empty)"
+		}
+
+		percent := 0.0
+		if summary.TotalMessages > 0 {
+			percent = 100.0 * float64(valueCount.Messages) / float64(summary.TotalMessages)
+		}
+
+		table.Append(
+			[]string{
+				value,
+				fmt.Sprintf("%d", valueCount.Messages),
+				fmt.Sprintf("%.1f%%", percent),
+				util.PrettyRate(int64(valueCount.Messages), duration),
+				util.PrettyBytes(valueCount.Bytes),
+				formatTopicPartitions(valueCount.Partitions),
+				valueCount.FirstTime.Format(time.RFC3339),
+				valueCount.LastTime.Format(time.RFC3339),
+			},
+		)
 	}
 
 	table.Render()
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
 
+// formatTopicPartitions formats a list of partitions for display. The topic is only shown if
+// the partitions are from more than one topic, and long lists are abbreviated.
+func formatTopicPartitions(topicPartitions []TopicPartition) string {
+	const maxShown = 8
+
+	multiTopic := false
+	for _, topicPartition := range topicPartitions {
+		if topicPartition.Topic != topicPartitions[0].Topic {
+			multiTopic = true
+			break
+		}
+	}
+
+	strs := []string{}
+	for i, topicPartition := range topicPartitions {
+		if i == maxShown {
+			strs = append(strs, fmt.Sprintf("... (%d total)", len(topicPartitions)))
+			break
+		}
+		if multiTopic {
+			strs = append(
+				strs,
+				fmt.Sprintf("%s/%d", topicPartition.Topic, topicPartition.Partition),
+			)
+		} else {
+			strs = append(strs, fmt.Sprintf("%d", topicPartition.Partition))
+		}
+	}
+
+	return strings.Join(strs, ",")
+}
+
 // FormatBounds makes a pretty table from the results of a GetAllPartitionBounds
 // call.
 func FormatBounds(boundsSlice []Bounds) string {
@@ -258,3 +376,604 @@ func FormatBoundTotals(boundsSlice []Bounds) string {
 	table.Render()
 	return string(bytes.TrimRight(buf.Bytes(), "\n"))
 }
+
+// FormatRetentionLosses makes a pretty table from the results of an EstimateRetentionLoss
+// call. Partitions that wouldn't lose any messages are omitted.
+func FormatRetentionLosses(losses []RetentionLoss) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Partition",
+			"Retained Messages",
+			"Est. Messages Deleted",
+			"Est. Percent Deleted",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	var totalMessages int64
+	var totalLost int64
+
+	for _, loss := range losses {
+		totalMessages += loss.TotalMessages
+		totalLost += loss.LostMessages
+
+		if loss.LostMessages == 0 {
+			continue
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", loss.Partition),
+				fmt.Sprintf("%d", loss.TotalMessages),
+				fmt.Sprintf("%d", loss.LostMessages),
+				fmt.Sprintf("%.1f%%", loss.LostFraction()*100.0),
+			},
+		)
+	}
+
+	var totalPercent float64
+	if totalMessages > 0 {
+		totalPercent = float64(totalLost) / float64(totalMessages) * 100.0
+	}
+
+	table.SetFooter(
+		[]string{
+			"Total",
+			fmt.Sprintf("%d", totalMessages),
+			fmt.Sprintf("%d", totalLost),
+			fmt.Sprintf("%.1f%%", totalPercent),
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatRetentionBytesLosses makes a pretty table from the results of an
+// EstimateRetentionBytesLoss call. Partitions that wouldn't lose any data are omitted.
+func FormatRetentionBytesLosses(losses []RetentionBytesLoss) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Partition",
+			"Leader Size",
+			"Est. Bytes Deleted",
+			"Est. Percent Deleted",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	var totalSize int64
+	var totalLost int64
+
+	for _, loss := range losses {
+		totalSize += loss.Size
+		totalLost += loss.LostBytes
+
+		if loss.LostBytes == 0 {
+			continue
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", loss.Partition),
+				util.PrettyBytes(loss.Size),
+				util.PrettyBytes(loss.LostBytes),
+				fmt.Sprintf("%.1f%%", loss.LostFraction()*100.0),
+			},
+		)
+	}
+
+	var totalPercent float64
+	if totalSize > 0 {
+		totalPercent = float64(totalLost) / float64(totalSize) * 100.0
+	}
+
+	table.SetFooter(
+		[]string{
+			"Total",
+			util.PrettyBytes(totalSize),
+			util.PrettyBytes(totalLost),
+			fmt.Sprintf("%.1f%%", totalPercent),
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatPartitionTruncations generates a pretty table that shows how many offsets would be
+// removed from each partition by a truncation.
+func FormatPartitionTruncations(truncations []PartitionTruncation) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Partition",
+			"Current First Offset",
+			"New First Offset",
+			"End Offset",
+			"Offsets Removed",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	var totalRemoved int64
+
+	for _, truncation := range truncations {
+		totalRemoved += truncation.RemovedOffsets()
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", truncation.Partition),
+				fmt.Sprintf("%d", truncation.FirstOffset),
+				fmt.Sprintf("%d", truncation.BeforeOffset),
+				fmt.Sprintf("%d", truncation.EndOffset),
+				fmt.Sprintf("%d", truncation.RemovedOffsets()),
+			},
+		)
+	}
+
+	table.SetFooter(
+		[]string{
+			"Total",
+			"",
+			"",
+			"",
+			fmt.Sprintf("%d", totalRemoved),
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatThroughput generates a pretty table from a TopicThroughput instance, with a row for
+// each partition and a final row with the totals.
+func FormatThroughput(throughput TopicThroughput) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	headerNames := []string{
+		"Partition",
+		"Start Offset",
+		"End Offset",
+		"Messages",
+		"Messages/Sec",
+		"Bytes",
+		"Bytes/Sec",
+	}
+	table.SetHeader(headerNames)
+
+	table.SetAutoWrapText(false)
+
+	alignments := []int{}
+	for i := 0; i < len(headerNames); i++ {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	window := throughput.Window()
+
+	for _, partition := range throughput.Partitions {
+		partitionBytes, bytesOK := partition.Bytes()
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", partition.Partition),
+				fmt.Sprintf("%d", partition.StartOffset),
+				fmt.Sprintf("%d", partition.EndOffset),
+				fmt.Sprintf("%d", partition.Messages()),
+				formatPerSec(float64(partition.Messages()), window),
+				formatThroughputBytes(partitionBytes, bytesOK),
+				formatThroughputBytesRate(partitionBytes, bytesOK, window),
+			},
+		)
+	}
+
+	totalBytes, totalBytesOK := throughput.TotalBytes()
+	table.Append(
+		[]string{
+			"Total",
+			"",
+			"",
+			fmt.Sprintf("%d", throughput.TotalMessages()),
+			formatPerSec(float64(throughput.TotalMessages()), window),
+			formatThroughputBytes(totalBytes, totalBytesOK),
+			formatThroughputBytesRate(totalBytes, totalBytesOK, window),
+		},
+	)
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func formatPerSec(count float64, window time.Duration) string {
+	if window <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", count/window.Seconds())
+}
+
+func formatThroughputBytes(numBytes int64, ok bool) string {
+	if !ok {
+		return "n/a"
+	}
+	return util.PrettyBytes(numBytes)
+}
+
+func formatThroughputBytesRate(numBytes int64, ok bool, window time.Duration) string {
+	if !ok || window <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf(
+		"%s/sec",
+		util.PrettyBytes(int64(float64(numBytes)/window.Seconds())),
+	)
+}
+
+// FormatPartitionSkew generates a pretty table of the skew statistics in a PartitionSkew
+// instance.
+func FormatPartitionSkew(skew PartitionSkew) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Statistic", "Messages", "Bytes"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	names := []string{
+		"Total",
+		"Mean per Partition",
+		"Std Dev",
+		"Coefficient of Variation",
+		"Min",
+		"Max",
+		"Max/Min Ratio",
+		"Max/Mean Ratio",
+	}
+	messageValues := formatSkewStats(skew.Messages, false)
+	byteValues := formatSkewStats(skew.Bytes, true)
+
+	for i, name := range names {
+		bytesValue := "n/a"
+		if skew.BytesKnown {
+			bytesValue = byteValues[i]
+		}
+		table.Append([]string{name, messageValues[i], bytesValue})
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// formatSkewStats formats the values in a SkewStats instance, in the order of the rows in
+// FormatPartitionSkew.
+func formatSkewStats(stats SkewStats, isBytes bool) []string {
+	formatCount := func(value int64) string {
+		if isBytes {
+			return util.PrettyBytes(value)
+		}
+		return fmt.Sprintf("%d", value)
+	}
+	formatAverage := func(value float64) string {
+		if isBytes {
+			return util.PrettyBytes(int64(value))
+		}
+		return fmt.Sprintf("%.1f", value)
+	}
+
+	maxToMin := "inf"
+	if ratio, ok := stats.MaxToMin(); ok {
+		maxToMin = fmt.Sprintf("%.2f", ratio)
+	}
+
+	return []string{
+		formatCount(stats.Total),
+		formatAverage(stats.Mean),
+		formatAverage(stats.StdDev),
+		fmt.Sprintf("%.2f", stats.CoefficientOfVariation()),
+		formatCount(stats.Min),
+		formatCount(stats.Max),
+		maxToMin,
+		fmt.Sprintf("%.2f", stats.MaxToMean()),
+	}
+}
+
+// FormatHottestPartitions generates a pretty table of the partitions in a PartitionSkew
+// instance with the most messages, along with their shares of the topic's total.
+func FormatHottestPartitions(skew PartitionSkew, maxPartitions int) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	headerNames := []string{
+		"Partition",
+		"Messages",
+		"Share of\nMessages",
+		"Bytes",
+		"Share of\nBytes",
+		"Vs. Mean",
+	}
+	table.SetHeader(headerNames)
+
+	table.SetAutoWrapText(false)
+
+	alignments := []int{}
+	for i := 0; i < len(headerNames); i++ {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	formatShare := func(value int64, total int64) string {
+		if total == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.1f%%", 100.0*float64(value)/float64(total))
+	}
+
+	for _, partition := range skew.HottestPartitions(maxPartitions) {
+		partitionBytes, _ := partition.Bytes()
+		bytesStr := "n/a"
+		bytesShare := "n/a"
+		if skew.BytesKnown {
+			bytesStr = util.PrettyBytes(partitionBytes)
+			bytesShare = formatShare(partitionBytes, skew.Bytes.Total)
+		}
+
+		vsMean := ""
+		if skew.Messages.Mean > 0 {
+			vsMean = fmt.Sprintf("%.2fx", float64(partition.Messages())/skew.Messages.Mean)
+		}
+
+		table.Append(
+			[]string{
+				fmt.Sprintf("%d", partition.Partition),
+				fmt.Sprintf("%d", partition.Messages()),
+				formatShare(partition.Messages(), skew.Messages.Total),
+				bytesStr,
+				bytesShare,
+				vsMean,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatKeyDistributions generates a pretty table of the key distributions in a KeyAnalysis
+// instance, with a row for each partition count.
+func FormatKeyDistributions(analysis KeyAnalysis) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	headerNames := []string{
+		"Partitions",
+		"Mean Records\nper Partition",
+		"Mean Keys\nper Partition",
+		"Empty\nPartitions",
+		"Max/Mean\nRatio",
+		"Coefficient\nof Variation",
+		"Even",
+	}
+	table.SetHeader(headerNames)
+
+	table.SetAutoWrapText(false)
+
+	alignments := []int{}
+	for i := 0; i < len(headerNames); i++ {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, distribution := range analysis.Distributions {
+		partitionsStr := fmt.Sprintf("%d", distribution.NumPartitions)
+		if distribution.NumPartitions == analysis.CurrentPartitions {
+			partitionsStr += " (current)"
+		}
+
+		evenStr := "no"
+		if distribution.Even() {
+			evenStr = "yes"
+		}
+
+		table.Append(
+			[]string{
+				partitionsStr,
+				fmt.Sprintf("%.1f", distribution.Stats.Mean),
+				fmt.Sprintf(
+					"%.1f",
+					float64(analysis.DistinctKeys)/float64(distribution.NumPartitions),
+				),
+				fmt.Sprintf("%d", distribution.EmptyPartitions()),
+				fmt.Sprintf("%.2f", distribution.Stats.MaxToMean()),
+				fmt.Sprintf("%.2f", distribution.Stats.CoefficientOfVariation()),
+				evenStr,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatHotKeys generates a pretty table of the most common keys in a KeyAnalysis instance.
+func FormatHotKeys(analysis KeyAnalysis) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader(
+		[]string{
+			"Key",
+			"Records",
+			"Share of\nKeyed Records",
+			"Current\nPartition",
+		},
+	)
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	keyedRecords := analysis.KeyedRecords()
+
+	for _, keyCount := range analysis.HotKeys {
+		share := ""
+		if keyedRecords > 0 {
+			share = fmt.Sprintf("%.1f%%", 100.0*float64(keyCount.Records)/float64(keyedRecords))
+		}
+
+		table.Append(
+			[]string{
+				bytesToStr(keyCount.Key),
+				fmt.Sprintf("%d", keyCount.Records),
+				share,
+				fmt.Sprintf("%d", keyCount.Partition),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatKeyPartitions generates a pretty table of keys and the partitions that they map to.
+func FormatKeyPartitions(keyPartitions []KeyPartition) string {
+	buf := &bytes.Buffer{}
+
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Key", "Partition"})
+	table.SetAutoWrapText(false)
+	table.SetColumnAlignment(
+		[]int{
+			tablewriter.ALIGN_LEFT,
+			tablewriter.ALIGN_LEFT,
+		},
+	)
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	for _, keyPartition := range keyPartitions {
+		table.Append(
+			[]string{
+				keyPartition.Key,
+				fmt.Sprintf("%d", keyPartition.Partition),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
@@ -0,0 +1,210 @@
+package messages
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// HeaderSummary stores the number of messages seen for each value of a header over a
+// sample of messages.
+type HeaderSummary struct {
+	HeaderKey     string
+	TotalMessages int
+	StartTime     time.Time
+	EndTime       time.Time
+
+	// Values are the counts for each header value, sorted by descending message count.
+	Values []HeaderValueCount
+}
+
+// HeaderValueCount stores the stats for a single header value in a HeaderSummary.
+type HeaderValueCount struct {
+	Value string
+
+	// Missing is set for the messages that don't have the header at all.
+	Missing bool
+
+	Messages   int
+	Bytes      int64
+	Partitions []TopicPartition
+	FirstTime  time.Time
+	LastTime   time.Time
+}
+
+// TopicPartition identifies a partition in a topic.
+type TopicPartition struct {
+	Topic     string
+	Partition int
+}
+
+// HeaderValue returns the value of the first header in the message with the argument key
+// and whether the message has this header.
+func HeaderValue(message kafka.Message, key string) (string, bool) {
+	for _, header := range message.Headers {
+		if header.Key == key {
+			return bytesToStr(header.Value), true
+		}
+	}
+	return "", false
+}
+
+// headerCounter accumulates the counts for a HeaderSummary.
+type headerCounter struct {
+	headerKey     string
+	totalMessages int
+	startTime     time.Time
+	counts        map[headerCountKey]*HeaderValueCount
+	partitions    map[headerCountKey]map[TopicPartition]struct{}
+}
+
+type headerCountKey struct {
+	value   string
+	missing bool
+}
+
+func newHeaderCounter(headerKey string, startTime time.Time) *headerCounter {
+	return &headerCounter{
+		headerKey:  headerKey,
+		startTime:  startTime,
+		counts:     map[headerCountKey]*HeaderValueCount{},
+		partitions: map[headerCountKey]map[TopicPartition]struct{}{},
+	}
+}
+
+func (h *headerCounter) add(message kafka.Message) {
+	value, ok := HeaderValue(message, h.headerKey)
+	key := headerCountKey{value: value, missing: !ok}
+
+	count, ok := h.counts[key]
+	if !ok {
+		count = &HeaderValueCount{
+			Value:     value,
+			Missing:   key.missing,
+			FirstTime: message.Time,
+		}
+		h.counts[key] = count
+		h.partitions[key] = map[TopicPartition]struct{}{}
+	}
+
+	h.totalMessages++
+	count.Messages++
+	count.Bytes += int64(len(message.Key) + len(message.Value))
+	if message.Time.Before(count.FirstTime) {
+		count.FirstTime = message.Time
+	}
+	if message.Time.After(count.LastTime) {
+		count.LastTime = message.Time
+	}
+	h.partitions[key][TopicPartition{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+	}] = struct{}{}
+}
+
+func (h *headerCounter) summary(endTime time.Time) HeaderSummary {
+	summary := HeaderSummary{
+		HeaderKey:     h.headerKey,
+		TotalMessages: h.totalMessages,
+		StartTime:     h.startTime,
+		EndTime:       endTime,
+		Values:        []HeaderValueCount{},
+	}
+
+	for key, count := range h.counts {
+		valueCount := *count
+		valueCount.Partitions = []TopicPartition{}
+		for topicPartition := range h.partitions[key] {
+			valueCount.Partitions = append(valueCount.Partitions, topicPartition)
+		}
+		sort.Slice(valueCount.Partitions, func(a, b int) bool {
+			partitionA := valueCount.Partitions[a]
+			partitionB := valueCount.Partitions[b]
+			return partitionA.Topic < partitionB.Topic ||
+				(partitionA.Topic == partitionB.Topic &&
+					partitionA.Partition < partitionB.Partition)
+		})
+		summary.Values = append(summary.Values, valueCount)
+	}
+
+	sort.Slice(summary.Values, func(a, b int) bool {
+		valueA := summary.Values[a]
+		valueB := summary.Values[b]
+
+		if valueA.Messages != valueB.Messages {
+			return valueA.Messages > valueB.Messages
+		}
+		if valueA.Missing != valueB.Missing {
+			// Put messages without the header last among ties
+			return !valueA.Missing
+		}
+		return valueA.Value < valueB.Value
+	})
+
+	return summary
+}
+
+// SummarizeHeaders counts the messages from the tailer for each value of the argument
+// header. It stops after the sample window has passed, maxMessages messages have been
+// counted, or the context is cancelled, whichever comes first, and then returns the
+// summary. A window or maxMessages <= 0 means no limit.
+func (t *TopicTailer) SummarizeHeaders(
+	ctx context.Context,
+	headerKey string,
+	window time.Duration,
+	maxMessages int,
+	filterRegexp string,
+	codec Codec,
+) (HeaderSummary, error) {
+	filterRegexpObj, err := compileFilter(filterRegexp)
+	if err != nil {
+		return HeaderSummary{}, err
+	}
+
+	// Use a separate context for the readers so that they're stopped when the sample is
+	// done, not just when the parent is cancelled
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var windowChan <-chan time.Time
+	if window > 0 {
+		timer := time.NewTimer(window)
+		defer timer.Stop()
+		windowChan = timer.C
+	}
+
+	messagesChan := make(chan TailMessage, 100)
+	t.GetMessages(readCtx, messagesChan)
+
+	counter := newHeaderCounter(headerKey, time.Now())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return counter.summary(time.Now()), nil
+		case <-windowChan:
+			return counter.summary(time.Now()), nil
+		case tailMessage := <-messagesChan:
+			if tailMessage.Err != nil {
+				log.Warnf("Got error: %+v", tailMessage.Err)
+				continue
+			}
+
+			message, err := DecodeMessage(ctx, codec, tailMessage.Message)
+			if err != nil {
+				log.Warnf("%+v; using raw value", err)
+			}
+			if filterRegexpObj != nil && !filterRegexpObj.Match(message.Value) {
+				continue
+			}
+
+			counter.add(message)
+			if maxMessages > 0 && counter.totalMessages >= maxMessages {
+				return counter.summary(time.Now()), nil
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaderValue(t *testing.T) {
+	message := kafka.Message{
+		Headers: []kafka.Header{
+			{Key: "tenant", Value: []byte("tenant1")},
+			{Key: "region", Value: []byte("us-west-2")},
+			{Key: "tenant", Value: []byte("tenant2")},
+		},
+	}
+
+	value, ok := HeaderValue(message, "tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "tenant1", value)
+
+	value, ok = HeaderValue(message, "region")
+	assert.True(t, ok)
+	assert.Equal(t, "us-west-2", value)
+
+	_, ok = HeaderValue(message, "non-existent")
+	assert.False(t, ok)
+}
+
+func TestHeaderCounter(t *testing.T) {
+	startTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newMessage := func(partition int, offset int64, tenant string) kafka.Message {
+		message := kafka.Message{
+			Topic:     "topic1",
+			Partition: partition,
+			Offset:    offset,
+			Key:       []byte("key"),
+			Value:     []byte("value"),
+			Time:      startTime.Add(time.Duration(offset) * time.Second),
+		}
+		if tenant != "" {
+			message.Headers = []kafka.Header{{Key: "tenant", Value: []byte(tenant)}}
+		}
+		return message
+	}
+
+	counter := newHeaderCounter("tenant", startTime)
+	counter.add(newMessage(1, 1, "tenant1"))
+	counter.add(newMessage(0, 2, "tenant2"))
+	counter.add(newMessage(0, 3, "tenant1"))
+	counter.add(newMessage(1, 4, ""))
+	counter.add(newMessage(2, 5, "tenant1"))
+	counter.add(newMessage(2, 6, "tenant2"))
+
+	summary := counter.summary(startTime.Add(time.Minute))
+	assert.Equal(
+		t,
+		HeaderSummary{
+			HeaderKey:     "tenant",
+			TotalMessages: 6,
+			StartTime:     startTime,
+			EndTime:       startTime.Add(time.Minute),
+			Values: []HeaderValueCount{
+				{
+					Value:    "tenant1",
+					Messages: 3,
+					Bytes:    24,
+					Partitions: []TopicPartition{
+						{Topic: "topic1", Partition: 0},
+						{Topic: "topic1", Partition: 1},
+						{Topic: "topic1", Partition: 2},
+					},
+					FirstTime: startTime.Add(time.Second),
+					LastTime:  startTime.Add(5 * time.Second),
+				},
+				{
+					Value:    "tenant2",
+					Messages: 2,
+					Bytes:    16,
+					Partitions: []TopicPartition{
+						{Topic: "topic1", Partition: 0},
+						{Topic: "topic1", Partition: 2},
+					},
+					FirstTime: startTime.Add(2 * time.Second),
+					LastTime:  startTime.Add(6 * time.Second),
+				},
+				{
+					Missing:  true,
+					Messages: 1,
+					Bytes:    8,
+					Partitions: []TopicPartition{
+						{Topic: "topic1", Partition: 1},
+					},
+					FirstTime: startTime.Add(4 * time.Second),
+					LastTime:  startTime.Add(4 * time.Second),
+				},
+			},
+		},
+		summary,
+	)
+}
+
+func TestFormatTopicPartitions(t *testing.T) {
+	assert.Equal(
+		t,
+		"0,2",
+		formatTopicPartitions(
+			[]TopicPartition{
+				{Topic: "topic1", Partition: 0},
+				{Topic: "topic1", Partition: 2},
+			},
+		),
+	)
+	assert.Equal(
+		t,
+		"topic1/0,topic2/0",
+		formatTopicPartitions(
+			[]TopicPartition{
+				{Topic: "topic1", Partition: 0},
+				{Topic: "topic2", Partition: 0},
+			},
+		),
+	)
+
+	manyPartitions := []TopicPartition{}
+	for i := 0; i < 10; i++ {
+		manyPartitions = append(manyPartitions, TopicPartition{Topic: "topic1", Partition: i})
+	}
+	assert.Equal(t, "0,1,2,3,4,5,6,7,... (10 total)", formatTopicPartitions(manyPartitions))
+}
@@ -0,0 +1,316 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// EvenMaxToMeanRatio is the maximum ratio of the records in the busiest partition to the mean
+// for which a key distribution is considered to be even.
+const EvenMaxToMeanRatio = 1.5
+
+// SampledKey is the key of a sampled message along with the partition that it was read from.
+// Key is nil if the message doesn't have a key.
+type SampledKey struct {
+	Partition int
+	Key       []byte
+}
+
+// SampleKeys reads the keys of up to sampleSize of the most recent messages in a topic,
+// spread evenly across the argument partitions.
+func SampleKeys(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partitions []int,
+	sampleSize int,
+) ([]SampledKey, error) {
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("Topic %s does not have any partitions", topic)
+	}
+	perPartition := (sampleSize + len(partitions) - 1) / len(partitions)
+
+	sampled := []SampledKey{}
+
+	for _, partition := range partitions {
+		keys, err := sampleRecentKeys(ctx, brokerAddr, topic, partition, perPartition)
+		if err != nil {
+			return nil, err
+		}
+		sampled = append(sampled, keys...)
+	}
+
+	return sampled, nil
+}
+
+func sampleRecentKeys(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partition int,
+	count int,
+) ([]SampledKey, error) {
+	conn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	firstOffset, endOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error getting offsets for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	startOffset := endOffset - int64(count)
+	if startOffset < firstOffset {
+		startOffset = firstOffset
+	}
+
+	keys := []SampledKey{}
+	if startOffset >= endOffset {
+		return keys, nil
+	}
+
+	_, err = conn.Seek(startOffset, kafka.SeekAbsolute|kafka.SeekDontCheck)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error seeking for partition %d at offset %d: %+v",
+			partition,
+			startOffset,
+			err,
+		)
+	}
+
+	for {
+		batch := conn.ReadBatch(1, maxFetchBatchBytes)
+
+		done := false
+		numRead := 0
+
+		for {
+			message, err := batch.ReadMessage()
+			if err != nil {
+				break
+			}
+			numRead++
+
+			// Batches can start before the requested offset
+			if message.Offset < startOffset {
+				continue
+			}
+
+			keys = append(keys, SampledKey{Partition: partition, Key: message.Key})
+
+			if message.Offset >= endOffset-1 || len(keys) >= count {
+				done = true
+				break
+			}
+		}
+
+		if err := batch.Close(); err != nil {
+			return nil, fmt.Errorf(
+				"Error reading messages for partition %d: %+v",
+				partition,
+				err,
+			)
+		}
+		if done || numRead == 0 {
+			break
+		}
+
+		log.Debugf(
+			"Sampled %d keys in partition %d so far, reading another batch",
+			len(keys),
+			partition,
+		)
+	}
+
+	return keys, nil
+}
+
+// KeyAnalysis stores the results of analyzing how a sample of message keys would be spread
+// across partitions by a partitioner.
+type KeyAnalysis struct {
+	Topic             string
+	Partitioner       util.Partitioner
+	CurrentPartitions int
+
+	SampledRecords int
+	KeylessRecords int
+	DistinctKeys   int
+
+	// MatchingRecords is the number of keyed records that are in the partition that the
+	// partitioner maps their key to under the current partition count. If this is much lower
+	// than the number of keyed records, the producers probably use a different partitioner.
+	MatchingRecords int
+
+	// HotKeys are the most common keys in the sample, in descending order of records.
+	HotKeys []KeyCount
+
+	// Distributions are the distributions of the keyed records over each partition count.
+	Distributions []KeyDistribution
+}
+
+// KeyedRecords returns the number of sampled records that have keys.
+func (k KeyAnalysis) KeyedRecords() int {
+	return k.SampledRecords - k.KeylessRecords
+}
+
+// KeyCount stores the number of sampled records with a single key.
+type KeyCount struct {
+	Key     []byte
+	Records int
+
+	// Partition is the partition that the key maps to under the current partition count.
+	Partition int
+}
+
+// KeyDistribution stores how the sampled keyed records would be spread across a given
+// number of partitions.
+type KeyDistribution struct {
+	NumPartitions int
+
+	// PartitionRecords and PartitionKeys are the number of records and distinct keys that
+	// map to each partition, indexed by partition ID.
+	PartitionRecords []int64
+	PartitionKeys    []int64
+
+	// Stats are the skew stats of the records per partition.
+	Stats SkewStats
+}
+
+// EmptyPartitions returns the number of partitions that none of the sampled keys map to.
+func (k KeyDistribution) EmptyPartitions() int {
+	empty := 0
+	for _, records := range k.PartitionRecords {
+		if records == 0 {
+			empty++
+		}
+	}
+	return empty
+}
+
+// Even returns whether the busiest partition gets at most EvenMaxToMeanRatio times the mean
+// number of records.
+func (k KeyDistribution) Even() bool {
+	return k.Stats.Total > 0 && k.Stats.MaxToMean() <= EvenMaxToMeanRatio
+}
+
+// AnalyzeKeys computes the distributions of the argument sampled keys under the current
+// partition count and each of the argument proposed ones. The current partition count is
+// always included first; the proposed ones follow in ascending order.
+func AnalyzeKeys(
+	topic string,
+	sampled []SampledKey,
+	partitioner util.Partitioner,
+	currentPartitions int,
+	proposedPartitions []int,
+	maxHotKeys int,
+) (KeyAnalysis, error) {
+	if err := partitioner.Validate(); err != nil {
+		return KeyAnalysis{}, err
+	}
+	if currentPartitions <= 0 {
+		return KeyAnalysis{}, fmt.Errorf("Topic %s does not have any partitions", topic)
+	}
+
+	partitionCounts := []int{currentPartitions}
+	proposed := append([]int{}, proposedPartitions...)
+	sort.Ints(proposed)
+	for _, count := range proposed {
+		if count <= 0 {
+			return KeyAnalysis{}, fmt.Errorf("Partition counts must be positive: %d", count)
+		}
+		if count != partitionCounts[len(partitionCounts)-1] && count != currentPartitions {
+			partitionCounts = append(partitionCounts, count)
+		}
+	}
+
+	analysis := KeyAnalysis{
+		Topic:             topic,
+		Partitioner:       partitioner,
+		CurrentPartitions: currentPartitions,
+		SampledRecords:    len(sampled),
+		HotKeys:           []KeyCount{},
+		Distributions:     []KeyDistribution{},
+	}
+
+	keyRecords := map[string]int{}
+
+	for _, sampledKey := range sampled {
+		if sampledKey.Key == nil {
+			analysis.KeylessRecords++
+			continue
+		}
+		keyRecords[string(sampledKey.Key)]++
+
+		partition, err := util.PartitionForKey(partitioner, sampledKey.Key, currentPartitions)
+		if err != nil {
+			return KeyAnalysis{}, err
+		}
+		if partition == sampledKey.Partition {
+			analysis.MatchingRecords++
+		}
+	}
+	analysis.DistinctKeys = len(keyRecords)
+
+	for key, records := range keyRecords {
+		partition, err := util.PartitionForKey(partitioner, []byte(key), currentPartitions)
+		if err != nil {
+			return KeyAnalysis{}, err
+		}
+		analysis.HotKeys = append(
+			analysis.HotKeys,
+			KeyCount{
+				Key:       []byte(key),
+				Records:   records,
+				Partition: partition,
+			},
+		)
+	}
+	sort.Slice(analysis.HotKeys, func(a, b int) bool {
+		if analysis.HotKeys[a].Records != analysis.HotKeys[b].Records {
+			return analysis.HotKeys[a].Records > analysis.HotKeys[b].Records
+		}
+		return string(analysis.HotKeys[a].Key) < string(analysis.HotKeys[b].Key)
+	})
+	if len(analysis.HotKeys) > maxHotKeys {
+		analysis.HotKeys = analysis.HotKeys[:maxHotKeys]
+	}
+
+	for _, count := range partitionCounts {
+		distribution := KeyDistribution{
+			NumPartitions:    count,
+			PartitionRecords: make([]int64, count),
+			PartitionKeys:    make([]int64, count),
+		}
+		for key, records := range keyRecords {
+			partition, err := util.PartitionForKey(partitioner, []byte(key), count)
+			if err != nil {
+				return KeyAnalysis{}, err
+			}
+			distribution.PartitionRecords[partition] += int64(records)
+			distribution.PartitionKeys[partition]++
+		}
+		distribution.Stats = NewSkewStats(distribution.PartitionRecords)
+
+		analysis.Distributions = append(analysis.Distributions, distribution)
+	}
+
+	return analysis, nil
+}
+
+// KeyPartition is a key along with the partition that it maps to.
+type KeyPartition struct {
+	Key       string
+	Partition int
+}
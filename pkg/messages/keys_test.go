@@ -0,0 +1,117 @@
+package messages
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeKeys(t *testing.T) {
+	sampled := []SampledKey{}
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprintf("key%d", i%100))
+		sampled = append(
+			sampled,
+			SampledKey{
+				Key:       key,
+				Partition: partitionForKey(t, key),
+			},
+		)
+	}
+
+	// Add a hot key and some keyless records
+	for i := 0; i < 200; i++ {
+		key := []byte("hot-key")
+		sampled = append(
+			sampled,
+			SampledKey{
+				Key:       key,
+				Partition: partitionForKey(t, key),
+			},
+		)
+	}
+	for i := 0; i < 50; i++ {
+		sampled = append(sampled, SampledKey{Partition: i % 4})
+	}
+
+	analysis, err := AnalyzeKeys(
+		"topic1",
+		sampled,
+		util.PartitionerMurmur2,
+		4,
+		[]int{16, 8, 4, 8},
+		3,
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1250, analysis.SampledRecords)
+	assert.Equal(t, 50, analysis.KeylessRecords)
+	assert.Equal(t, 1200, analysis.KeyedRecords())
+	assert.Equal(t, 101, analysis.DistinctKeys)
+	assert.Equal(t, 1200, analysis.MatchingRecords)
+
+	assert.Equal(t, 3, len(analysis.HotKeys))
+	assert.Equal(t, []byte("hot-key"), analysis.HotKeys[0].Key)
+	assert.Equal(t, 200, analysis.HotKeys[0].Records)
+	assert.Equal(t, []byte("key0"), analysis.HotKeys[1].Key)
+	assert.Equal(t, 10, analysis.HotKeys[1].Records)
+
+	partitionCounts := []int{}
+	for _, distribution := range analysis.Distributions {
+		partitionCounts = append(partitionCounts, distribution.NumPartitions)
+		assert.Equal(t, int64(1200), distribution.Stats.Total)
+		assert.Equal(t, distribution.NumPartitions, len(distribution.PartitionRecords))
+
+		var totalKeys int64
+		for _, keys := range distribution.PartitionKeys {
+			totalKeys += keys
+		}
+		assert.Equal(t, int64(101), totalKeys)
+	}
+	assert.Equal(t, []int{4, 8, 16}, partitionCounts)
+
+	// The hot key gets at least 200 of the 1200 records, which is more than 1.5x the mean of
+	// 75 with 16 partitions
+	assert.False(t, analysis.Distributions[2].Even())
+
+	// Using a different partitioner than the producers should show up in the matches
+	otherAnalysis, err := AnalyzeKeys("topic1", sampled, util.PartitionerCRC32, 4, nil, 3)
+	require.NoError(t, err)
+	assert.Less(t, otherAnalysis.MatchingRecords, 1200)
+	assert.Equal(t, 1, len(otherAnalysis.Distributions))
+
+	_, err = AnalyzeKeys("topic1", sampled, util.Partitioner("unknown"), 4, nil, 3)
+	assert.Error(t, err)
+
+	_, err = AnalyzeKeys("topic1", sampled, util.PartitionerMurmur2, 4, []int{0}, 3)
+	assert.Error(t, err)
+}
+
+func TestKeyDistributionEven(t *testing.T) {
+	even := KeyDistribution{
+		NumPartitions:    4,
+		PartitionRecords: []int64{100, 110, 90, 100},
+		Stats:            NewSkewStats([]int64{100, 110, 90, 100}),
+	}
+	assert.True(t, even.Even())
+	assert.Equal(t, 0, even.EmptyPartitions())
+
+	uneven := KeyDistribution{
+		NumPartitions:    4,
+		PartitionRecords: []int64{300, 100, 0, 0},
+		Stats:            NewSkewStats([]int64{300, 100, 0, 0}),
+	}
+	assert.False(t, uneven.Even())
+	assert.Equal(t, 2, uneven.EmptyPartitions())
+
+	assert.False(t, KeyDistribution{}.Even())
+}
+
+func partitionForKey(t *testing.T, key []byte) int {
+	partition, err := util.PartitionForKey(util.PartitionerMurmur2, key, 4)
+	require.NoError(t, err)
+	return partition
+}
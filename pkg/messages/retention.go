@@ -0,0 +1,104 @@
+package messages
+
+import (
+	"sort"
+	"time"
+)
+
+// RetentionLoss stores the estimated number of messages in a partition that would become
+// eligible for deletion if the retention time were reduced.
+type RetentionLoss struct {
+	Partition     int
+	TotalMessages int64
+	LostMessages  int64
+	Cutoff        time.Time
+}
+
+// LostFraction returns the fraction of the currently-retained messages that would be
+// eligible for deletion.
+func (r RetentionLoss) LostFraction() float64 {
+	if r.TotalMessages == 0 {
+		return 0.0
+	}
+	return float64(r.LostMessages) / float64(r.TotalMessages)
+}
+
+// EstimateRetentionLoss estimates how many of the messages in each partition would be
+// eligible for deletion under the argument retention time. This assumes that messages
+// were produced at a constant rate between the first and last message in each partition,
+// so the results should be treated as approximate.
+func EstimateRetentionLoss(
+	boundsSlice []Bounds,
+	retention time.Duration,
+	now time.Time,
+) []RetentionLoss {
+	cutoff := now.Add(-retention)
+	losses := []RetentionLoss{}
+
+	for _, bounds := range boundsSlice {
+		totalMessages := bounds.LastOffset - bounds.FirstOffset
+		loss := RetentionLoss{
+			Partition:     bounds.Partition,
+			TotalMessages: totalMessages,
+			Cutoff:        cutoff,
+		}
+
+		if totalMessages <= 0 || !bounds.FirstTime.Before(cutoff) {
+			// Nothing in the partition is older than the cutoff
+		} else if bounds.LastTime.Before(cutoff) {
+			loss.LostMessages = totalMessages
+		} else {
+			span := bounds.LastTime.Sub(bounds.FirstTime)
+			expired := cutoff.Sub(bounds.FirstTime)
+			loss.LostMessages = int64(float64(totalMessages) * float64(expired) / float64(span))
+		}
+
+		losses = append(losses, loss)
+	}
+
+	return losses
+}
+
+// RetentionBytesLoss is the estimated amount of data in a partition that would be eligible
+// for deletion under a new retention.bytes limit.
+type RetentionBytesLoss struct {
+	Partition int
+	Size      int64
+	LostBytes int64
+}
+
+// LostFraction returns the fraction of the current partition size that would be eligible
+// for deletion.
+func (r RetentionBytesLoss) LostFraction() float64 {
+	if r.Size == 0 {
+		return 0.0
+	}
+	return float64(r.LostBytes) / float64(r.Size)
+}
+
+// EstimateRetentionBytesLoss estimates how much of the data in each partition would be
+// eligible for deletion under the argument retention.bytes limit, given the current
+// partition sizes keyed by partition ID. Kafka deletes whole segments, so slightly less
+// data may actually be removed. The results are sorted by partition.
+func EstimateRetentionBytesLoss(
+	sizes map[int]int64,
+	retentionBytes int64,
+) []RetentionBytesLoss {
+	losses := []RetentionBytesLoss{}
+
+	for partition, size := range sizes {
+		loss := RetentionBytesLoss{
+			Partition: partition,
+			Size:      size,
+		}
+		if retentionBytes >= 0 && size > retentionBytes {
+			loss.LostBytes = size - retentionBytes
+		}
+		losses = append(losses, loss)
+	}
+
+	sort.Slice(losses, func(a, b int) bool {
+		return losses[a].Partition < losses[b].Partition
+	})
+	return losses
+}
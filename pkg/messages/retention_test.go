@@ -0,0 +1,118 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateRetentionLoss(t *testing.T) {
+	now := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	boundsSlice := []Bounds{
+		{
+			// Empty partition
+			Partition:   0,
+			FirstOffset: 10,
+			LastOffset:  10,
+		},
+		{
+			// All messages are newer than the cutoff
+			Partition:   1,
+			FirstOffset: 0,
+			FirstTime:   now.Add(-30 * time.Minute),
+			LastOffset:  100,
+			LastTime:    now,
+		},
+		{
+			// All messages are older than the cutoff
+			Partition:   2,
+			FirstOffset: 50,
+			FirstTime:   now.Add(-4 * time.Hour),
+			LastOffset:  150,
+			LastTime:    now.Add(-2 * time.Hour),
+		},
+		{
+			// Half of the messages are older than the cutoff
+			Partition:   3,
+			FirstOffset: 0,
+			FirstTime:   now.Add(-2 * time.Hour),
+			LastOffset:  200,
+			LastTime:    now,
+		},
+	}
+
+	losses := EstimateRetentionLoss(boundsSlice, time.Hour, now)
+	cutoff := now.Add(-time.Hour)
+
+	assert.Equal(
+		t,
+		[]RetentionLoss{
+			{
+				Partition:     0,
+				TotalMessages: 0,
+				LostMessages:  0,
+				Cutoff:        cutoff,
+			},
+			{
+				Partition:     1,
+				TotalMessages: 100,
+				LostMessages:  0,
+				Cutoff:        cutoff,
+			},
+			{
+				Partition:     2,
+				TotalMessages: 100,
+				LostMessages:  100,
+				Cutoff:        cutoff,
+			},
+			{
+				Partition:     3,
+				TotalMessages: 200,
+				LostMessages:  100,
+				Cutoff:        cutoff,
+			},
+		},
+		losses,
+	)
+	assert.Equal(t, 0.5, losses[3].LostFraction())
+	assert.Equal(t, 0.0, losses[0].LostFraction())
+}
+
+func TestEstimateRetentionBytesLoss(t *testing.T) {
+	losses := EstimateRetentionBytesLoss(
+		map[int]int64{
+			2: 500,
+			0: 2000,
+			1: 1000,
+		},
+		1000,
+	)
+	assert.Equal(
+		t,
+		[]RetentionBytesLoss{
+			{
+				Partition: 0,
+				Size:      2000,
+				LostBytes: 1000,
+			},
+			{
+				Partition: 1,
+				Size:      1000,
+				LostBytes: 0,
+			},
+			{
+				Partition: 2,
+				Size:      500,
+				LostBytes: 0,
+			},
+		},
+		losses,
+	)
+	assert.Equal(t, 0.5, losses[0].LostFraction())
+
+	// Unlimited retention doesn't delete anything
+	losses = EstimateRetentionBytesLoss(map[int]int64{0: 2000}, -1)
+	assert.Equal(t, int64(0), losses[0].LostBytes)
+}
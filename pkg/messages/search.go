@@ -0,0 +1,347 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Partitioner is a string type that stores the name of the partitioner that producers use
+// to assign keyed messages to partitions.
+type Partitioner string
+
+const (
+	// PartitionerNone means that the partitioner is unknown, so all partitions are searched.
+	PartitionerNone Partitioner = "none"
+
+	// PartitionerHash is the FNV-1a hash partitioner used by default in kafka-go and Sarama.
+	PartitionerHash Partitioner = "hash"
+
+	// PartitionerMurmur2 is the murmur2 partitioner used by default in the Java client.
+	PartitionerMurmur2 Partitioner = "murmur2"
+
+	// PartitionerCRC32 is the CRC32 partitioner used by default in librdkafka.
+	PartitionerCRC32 Partitioner = "crc32"
+)
+
+// AllPartitioners contains all of the valid partitioners.
+var AllPartitioners = []Partitioner{
+	PartitionerNone,
+	PartitionerHash,
+	PartitionerMurmur2,
+	PartitionerCRC32,
+}
+
+// SearchConfig contains the parameters for a search.
+type SearchConfig struct {
+	Topic string
+
+	// Key is the message key to look for. If nil, messages with any key match.
+	Key []byte
+
+	// Partitioner is used to narrow down the partitions searched when Key is set.
+	Partitioner Partitioner
+
+	// Since and Until bound the times of the matching messages. They're ignored if zero.
+	Since time.Time
+	Until time.Time
+
+	// MaxScanMessages is the maximum number of messages that are scanned, in total, across
+	// all partitions.
+	MaxScanMessages int64
+
+	// MaxMatches is the number of matches after which the search stops. If zero, all
+	// matches are returned.
+	MaxMatches int
+}
+
+// SearchProgress describes the progress of a running search.
+type SearchProgress struct {
+	Partition      int
+	ScannedInRange int64
+	TotalInRange   int64
+	Matches        int
+}
+
+// SearchResult is the result of a search.
+type SearchResult struct {
+	Matches         []kafka.Message
+	ScannedMessages int64
+
+	// Truncated is set if the search stopped before scanning all messages in the
+	// argument time range because of the MaxScanMessages or MaxMatches limits.
+	Truncated bool
+}
+
+// SearchPartitions returns the partitions that can contain messages matching the argument
+// search config.
+func SearchPartitions(searchConfig SearchConfig, partitions []int) ([]int, error) {
+	sorted := append([]int{}, partitions...)
+	sort.Ints(sorted)
+
+	if searchConfig.Key == nil || searchConfig.Partitioner == PartitionerNone ||
+		searchConfig.Partitioner == "" {
+		return sorted, nil
+	}
+
+	var balancer kafka.Balancer
+
+	switch searchConfig.Partitioner {
+	case PartitionerHash:
+		balancer = &kafka.Hash{}
+	case PartitionerMurmur2:
+		balancer = kafka.Murmur2Balancer{Consistent: true}
+	case PartitionerCRC32:
+		balancer = kafka.CRC32Balancer{Consistent: true}
+	default:
+		return nil, fmt.Errorf("Partitioner must be in %+v", AllPartitioners)
+	}
+
+	return []int{
+		balancer.Balance(kafka.Message{Key: searchConfig.Key}, sorted...),
+	}, nil
+}
+
+// SearchMessages scans the argument partitions of a topic for messages that match the
+// argument search config. The progress callback, if non-nil, is called periodically while
+// the scan runs.
+func SearchMessages(
+	ctx context.Context,
+	brokerAddr string,
+	searchConfig SearchConfig,
+	partitions []int,
+	progress func(SearchProgress),
+) (SearchResult, error) {
+	result := SearchResult{
+		Matches: []kafka.Message{},
+	}
+
+	for _, partition := range partitions {
+		done, err := searchPartition(
+			ctx,
+			brokerAddr,
+			searchConfig,
+			partition,
+			progress,
+			&result,
+		)
+		if err != nil {
+			return result, err
+		}
+		if done {
+			result.Truncated = true
+			break
+		}
+	}
+
+	sort.Slice(result.Matches, func(a, b int) bool {
+		if result.Matches[a].Time.Equal(result.Matches[b].Time) {
+			return result.Matches[a].Partition < result.Matches[b].Partition
+		}
+		return result.Matches[a].Time.Before(result.Matches[b].Time)
+	})
+
+	return result, nil
+}
+
+// searchPartition scans a single partition and adds the matches to the argument result. It
+// returns true if one of the search limits was hit.
+func searchPartition(
+	ctx context.Context,
+	brokerAddr string,
+	searchConfig SearchConfig,
+	partition int,
+	progress func(SearchProgress),
+	result *SearchResult,
+) (bool, error) {
+	conn, err := dialLeaderRetries(ctx, brokerAddr, searchConfig.Topic, partition)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	startOffset, endOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return false, fmt.Errorf(
+			"Error getting offsets for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	// Use the time index in the broker to skip over messages outside of the time range
+	if !searchConfig.Since.IsZero() {
+		sinceOffset, err := conn.ReadOffset(searchConfig.Since)
+		if err != nil {
+			return false, fmt.Errorf(
+				"Error getting offset for time %s in partition %d: %+v",
+				searchConfig.Since.Format(time.RFC3339),
+				partition,
+				err,
+			)
+		}
+		if sinceOffset < 0 {
+			// There are no messages at or after the argument time
+			startOffset = endOffset
+		} else if sinceOffset > startOffset {
+			startOffset = sinceOffset
+		}
+	}
+	if !searchConfig.Until.IsZero() {
+		untilOffset, err := conn.ReadOffset(searchConfig.Until)
+		if err != nil {
+			return false, fmt.Errorf(
+				"Error getting offset for time %s in partition %d: %+v",
+				searchConfig.Until.Format(time.RFC3339),
+				partition,
+				err,
+			)
+		}
+		if untilOffset >= startOffset && untilOffset < endOffset {
+			endOffset = untilOffset
+		}
+	}
+
+	log.Debugf(
+		"Searching partition %d between offsets %d and %d",
+		partition,
+		startOffset,
+		endOffset,
+	)
+
+	if startOffset >= endOffset {
+		return false, nil
+	}
+
+	_, err = conn.Seek(startOffset, kafka.SeekAbsolute|kafka.SeekDontCheck)
+	if err != nil {
+		return false, fmt.Errorf(
+			"Error seeking for partition %d at offset %d: %+v",
+			partition,
+			startOffset,
+			err,
+		)
+	}
+
+	partitionProgress := SearchProgress{
+		Partition:    partition,
+		TotalInRange: endOffset - startOffset,
+	}
+
+	for {
+		// Extend the deadline for each batch since scans can take a while
+		conn.SetDeadline(time.Now().Add(connTimeout))
+		batch := conn.ReadBatch(1, maxFetchBatchBytes)
+
+		numRead := 0
+		partitionDone := false
+		limitHit := false
+
+		for {
+			message, err := batch.ReadMessage()
+			if err != nil {
+				break
+			}
+			numRead++
+
+			if message.Offset < startOffset {
+				continue
+			}
+			if message.Offset >= endOffset {
+				partitionDone = true
+				break
+			}
+
+			result.ScannedMessages++
+			partitionProgress.ScannedInRange = message.Offset - startOffset + 1
+
+			if searchMatch(searchConfig, message) {
+				message.Topic = searchConfig.Topic
+				message.Partition = partition
+				result.Matches = append(result.Matches, message)
+				partitionProgress.Matches++
+
+				if searchConfig.MaxMatches > 0 &&
+					len(result.Matches) >= searchConfig.MaxMatches {
+					limitHit = true
+					break
+				}
+			}
+
+			if searchConfig.MaxScanMessages > 0 &&
+				result.ScannedMessages >= searchConfig.MaxScanMessages {
+				limitHit = true
+				break
+			}
+		}
+
+		if err := batch.Close(); err != nil {
+			return false, fmt.Errorf(
+				"Error reading messages for partition %d: %+v",
+				partition,
+				err,
+			)
+		}
+
+		if progress != nil {
+			progress(partitionProgress)
+		}
+
+		if limitHit {
+			return true, nil
+		}
+		if partitionDone || numRead == 0 {
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		default:
+		}
+	}
+}
+
+func searchMatch(searchConfig SearchConfig, message kafka.Message) bool {
+	if searchConfig.Key != nil && !bytes.Equal(searchConfig.Key, message.Key) {
+		return false
+	}
+	if !searchConfig.Since.IsZero() && message.Time.Before(searchConfig.Since) {
+		return false
+	}
+	if !searchConfig.Until.IsZero() && !message.Time.Before(searchConfig.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseSearchTime parses a time bound for a search. This can be either an RFC3339
+// timestamp (e.g., "2020-06-01T12:00:00Z") or a duration relative to the argument current
+// time (e.g., "2h" for two hours ago).
+func ParseSearchTime(timeStr string, now time.Time) (time.Time, error) {
+	if timeStr == "" {
+		return time.Time{}, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, timeStr); err == nil {
+		return parsed, nil
+	}
+
+	duration, err := time.ParseDuration(timeStr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"Could not parse time %s; must be an RFC3339 timestamp or a duration",
+			timeStr,
+		)
+	}
+	if duration < 0 {
+		return time.Time{}, fmt.Errorf("Duration %s cannot be negative", timeStr)
+	}
+
+	return now.Add(-duration), nil
+}
@@ -0,0 +1,193 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchMessages(t *testing.T) {
+	ctx := context.Background()
+	controllerConn := util.TestKafkaContollerConn(ctx, t)
+
+	topicName := util.RandomString("topic-search-", 6)
+
+	err := controllerConn.CreateTopics(
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     3,
+			ReplicationFactor: 1,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:  []string{util.TestKafkaAddr()},
+			Topic:    topicName,
+			Balancer: kafka.Murmur2Balancer{Consistent: true},
+		},
+	)
+	defer writer.Close()
+
+	messages := []kafka.Message{}
+	for i := 0; i < 30; i++ {
+		messages = append(
+			messages,
+			kafka.Message{
+				Key:   []byte(fmt.Sprintf("key%d", i%5)),
+				Value: []byte(fmt.Sprintf("value%d", i)),
+			},
+		)
+	}
+	require.Nil(t, writer.WriteMessages(ctx, messages...))
+
+	searchConfig := SearchConfig{
+		Topic:           topicName,
+		Key:             []byte("key2"),
+		Partitioner:     PartitionerMurmur2,
+		MaxScanMessages: 1000,
+	}
+	partitions, err := SearchPartitions(searchConfig, []int{0, 1, 2})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(partitions))
+
+	progressCalls := 0
+	result, err := SearchMessages(
+		ctx,
+		util.TestKafkaAddr(),
+		searchConfig,
+		partitions,
+		func(progress SearchProgress) {
+			progressCalls++
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 6, len(result.Matches))
+	assert.False(t, result.Truncated)
+	assert.Greater(t, progressCalls, 0)
+	for _, match := range result.Matches {
+		assert.Equal(t, "key2", string(match.Key))
+	}
+
+	searchConfig.MaxMatches = 2
+	result, err = SearchMessages(ctx, util.TestKafkaAddr(), searchConfig, partitions, nil)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(result.Matches))
+	assert.True(t, result.Truncated)
+
+	// Nothing should match in the future
+	result, err = SearchMessages(
+		ctx,
+		util.TestKafkaAddr(),
+		SearchConfig{
+			Topic:           topicName,
+			Since:           time.Now().Add(time.Hour),
+			MaxScanMessages: 1000,
+		},
+		[]int{0, 1, 2},
+		nil,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(result.Matches))
+	assert.Equal(t, int64(0), result.ScannedMessages)
+}
+
+func TestSearchPartitions(t *testing.T) {
+	partitions := []int{2, 0, 1, 3}
+
+	result, err := SearchPartitions(SearchConfig{}, partitions)
+	require.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, result)
+
+	result, err = SearchPartitions(
+		SearchConfig{Key: []byte("key1"), Partitioner: PartitionerNone},
+		partitions,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, result)
+
+	balancers := map[Partitioner]kafka.Balancer{
+		PartitionerHash:    &kafka.Hash{},
+		PartitionerMurmur2: kafka.Murmur2Balancer{Consistent: true},
+		PartitionerCRC32:   kafka.CRC32Balancer{Consistent: true},
+	}
+
+	for partitioner, balancer := range balancers {
+		for i := 0; i < 10; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			result, err = SearchPartitions(
+				SearchConfig{Key: key, Partitioner: partitioner},
+				partitions,
+			)
+			require.Nil(t, err)
+			assert.Equal(
+				t,
+				[]int{balancer.Balance(kafka.Message{Key: key}, 0, 1, 2, 3)},
+				result,
+				string(partitioner),
+			)
+		}
+	}
+
+	_, err = SearchPartitions(
+		SearchConfig{Key: []byte("key1"), Partitioner: "bad-partitioner"},
+		partitions,
+	)
+	assert.NotNil(t, err)
+}
+
+func TestSearchMatch(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	message := kafka.Message{
+		Key:  []byte("key1"),
+		Time: now,
+	}
+
+	assert.True(t, searchMatch(SearchConfig{}, message))
+	assert.True(t, searchMatch(SearchConfig{Key: []byte("key1")}, message))
+	assert.False(t, searchMatch(SearchConfig{Key: []byte("key2")}, message))
+	assert.False(t, searchMatch(SearchConfig{Key: []byte{}}, message))
+	assert.True(t, searchMatch(SearchConfig{Since: now}, message))
+	assert.False(t, searchMatch(SearchConfig{Since: now.Add(time.Second)}, message))
+	assert.False(t, searchMatch(SearchConfig{Until: now}, message))
+	assert.True(
+		t,
+		searchMatch(
+			SearchConfig{
+				Key:   []byte("key1"),
+				Since: now.Add(-time.Hour),
+				Until: now.Add(time.Hour),
+			},
+			message,
+		),
+	)
+}
+
+func TestParseSearchTime(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	parsed, err := ParseSearchTime("", now)
+	require.Nil(t, err)
+	assert.True(t, parsed.IsZero())
+
+	parsed, err = ParseSearchTime("2020-05-31T10:30:00Z", now)
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 5, 31, 10, 30, 0, 0, time.UTC), parsed)
+
+	parsed, err = ParseSearchTime("2h", now)
+	require.Nil(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), parsed)
+
+	_, err = ParseSearchTime("-2h", now)
+	assert.NotNil(t, err)
+	_, err = ParseSearchTime("yesterday", now)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,105 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ShiftOffset shifts an offset in a topic partition. If timeShift is non-zero, the offset is
+// first moved to the first message at or after the time of the message at the argument offset
+// plus timeShift; if the argument offset is at the end of the partition, the current time is
+// used instead of the message time. The offset is then moved by offsetShift messages.
+//
+// The result is clamped so that it's within the current bounds of the partition.
+func ShiftOffset(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partition int,
+	offset int64,
+	offsetShift int64,
+	timeShift time.Duration,
+) (int64, error) {
+	conn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	firstOffset, endOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, fmt.Errorf(
+			"Error getting offsets for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	newOffset := offset
+
+	if timeShift != 0 {
+		refTime := time.Now()
+
+		if offset >= firstOffset && offset < endOffset {
+			_, err = conn.Seek(offset, kafka.SeekAbsolute|kafka.SeekDontCheck)
+			if err != nil {
+				return 0, fmt.Errorf(
+					"Error seeking for partition %d at offset %d: %+v",
+					partition,
+					offset,
+					err,
+				)
+			}
+
+			message, err := conn.ReadMessage(maxMessageSizeBytes)
+			if err != nil {
+				return 0, fmt.Errorf(
+					"Error reading message for partition %d (offset %d): %+v",
+					partition,
+					offset,
+					err,
+				)
+			}
+			refTime = message.Time
+		}
+
+		// Use a separate connection for the offset lookup, as in GetPartitionBounds
+		timeConn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+		if err != nil {
+			return 0, err
+		}
+		defer timeConn.Close()
+
+		shiftedTime := refTime.Add(timeShift)
+		timeOffset, err := timeConn.ReadOffset(shiftedTime)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"Error getting offset for time %s in partition %d: %+v",
+				shiftedTime.Format(time.RFC3339),
+				partition,
+				err,
+			)
+		}
+
+		if timeOffset < 0 {
+			// There are no messages at or after the shifted time
+			newOffset = endOffset
+		} else {
+			newOffset = timeOffset
+		}
+	}
+
+	newOffset += offsetShift
+
+	if newOffset < firstOffset {
+		newOffset = firstOffset
+	}
+	if newOffset > endOffset {
+		newOffset = endOffset
+	}
+
+	return newOffset, nil
+}
@@ -0,0 +1,104 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShiftOffset(t *testing.T) {
+	ctx := context.Background()
+	controllerConn := util.TestKafkaContollerConn(ctx, t)
+
+	topicName := util.RandomString("topic-shift-", 6)
+
+	err := controllerConn.CreateTopics(
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     1,
+			ReplicationFactor: 1,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:   []string{util.TestKafkaAddr()},
+			Topic:     topicName,
+			BatchSize: 5,
+		},
+	)
+	defer writer.Close()
+
+	messages := []kafka.Message{}
+	for i := 0; i < 10; i++ {
+		messages = append(
+			messages,
+			kafka.Message{
+				Key:   []byte(fmt.Sprintf("key%d", i)),
+				Value: []byte(fmt.Sprintf("value%d", i)),
+			},
+		)
+	}
+	require.Nil(t, writer.WriteMessages(ctx, messages...))
+
+	type testCase struct {
+		offset      int64
+		offsetShift int64
+		timeShift   time.Duration
+		expected    int64
+	}
+
+	testCases := []testCase{
+		{
+			offset:   5,
+			expected: 5,
+		},
+		{
+			offset:      5,
+			offsetShift: -2,
+			expected:    3,
+		},
+		{
+			offset:      5,
+			offsetShift: 20,
+			expected:    10,
+		},
+		{
+			offset:      2,
+			offsetShift: -5,
+			expected:    0,
+		},
+		{
+			offset:    5,
+			timeShift: -time.Hour,
+			expected:  0,
+		},
+		{
+			offset:    5,
+			timeShift: time.Hour,
+			expected:  10,
+		},
+	}
+
+	for _, testCase := range testCases {
+		shifted, err := ShiftOffset(
+			ctx,
+			util.TestKafkaAddr(),
+			topicName,
+			0,
+			testCase.offset,
+			testCase.offsetShift,
+			testCase.timeShift,
+		)
+		require.Nil(t, err)
+		assert.Equal(t, testCase.expected, shifted, "Test case %+v", testCase)
+	}
+}
@@ -0,0 +1,133 @@
+package messages
+
+import (
+	"math"
+	"sort"
+)
+
+// SkewStats stores statistics on how a quantity (e.g., the number of messages produced
+// during a sample) is spread across the partitions in a topic.
+type SkewStats struct {
+	Total  int64
+	Mean   float64
+	StdDev float64
+	Min    int64
+	Max    int64
+}
+
+// NewSkewStats computes the skew stats for the argument per-partition values.
+func NewSkewStats(values []int64) SkewStats {
+	stats := SkewStats{}
+	if len(values) == 0 {
+		return stats
+	}
+
+	stats.Min = values[0]
+	stats.Max = values[0]
+
+	for _, value := range values {
+		stats.Total += value
+		if value < stats.Min {
+			stats.Min = value
+		}
+		if value > stats.Max {
+			stats.Max = value
+		}
+	}
+	stats.Mean = float64(stats.Total) / float64(len(values))
+
+	var sumSquares float64
+	for _, value := range values {
+		diff := float64(value) - stats.Mean
+		sumSquares += diff * diff
+	}
+	stats.StdDev = math.Sqrt(sumSquares / float64(len(values)))
+
+	return stats
+}
+
+// MaxToMin returns the ratio of the maximum value to the minimum one and whether it's
+// defined, i.e. whether the minimum is non-zero.
+func (s SkewStats) MaxToMin() (float64, bool) {
+	if s.Min == 0 {
+		return 0, false
+	}
+	return float64(s.Max) / float64(s.Min), true
+}
+
+// MaxToMean returns the ratio of the maximum value to the mean, or 0 if the mean is 0.
+func (s SkewStats) MaxToMean() float64 {
+	if s.Mean == 0 {
+		return 0
+	}
+	return float64(s.Max) / s.Mean
+}
+
+// CoefficientOfVariation returns the standard deviation divided by the mean, or 0 if the
+// mean is 0. Unlike the standard deviation, this is comparable across topics with different
+// rates.
+func (s SkewStats) CoefficientOfVariation() float64 {
+	if s.Mean == 0 {
+		return 0
+	}
+	return s.StdDev / s.Mean
+}
+
+// PartitionSkew stores the skew of the messages and bytes produced to the partitions in a
+// topic over a sample window.
+type PartitionSkew struct {
+	Throughput TopicThroughput
+	Messages   SkewStats
+
+	// Bytes is only set if BytesKnown is true, i.e. the byte counts are known for all
+	// partitions.
+	Bytes      SkewStats
+	BytesKnown bool
+}
+
+// NewPartitionSkew computes the partition skew from a throughput sample.
+func NewPartitionSkew(throughput TopicThroughput) PartitionSkew {
+	messageCounts := []int64{}
+	byteCounts := []int64{}
+	bytesKnown := len(throughput.Partitions) > 0
+
+	for _, partition := range throughput.Partitions {
+		messageCounts = append(messageCounts, partition.Messages())
+
+		partitionBytes, ok := partition.Bytes()
+		if !ok {
+			bytesKnown = false
+		}
+		byteCounts = append(byteCounts, partitionBytes)
+	}
+
+	skew := PartitionSkew{
+		Throughput: throughput,
+		Messages:   NewSkewStats(messageCounts),
+		BytesKnown: bytesKnown,
+	}
+	if bytesKnown {
+		skew.Bytes = NewSkewStats(byteCounts)
+	}
+
+	return skew
+}
+
+// HottestPartitions returns up to maxPartitions partitions with the most messages during
+// the sample, in descending order.
+func (p PartitionSkew) HottestPartitions(maxPartitions int) []PartitionThroughput {
+	partitions := make([]PartitionThroughput, len(p.Throughput.Partitions))
+	copy(partitions, p.Throughput.Partitions)
+
+	sort.Slice(partitions, func(a, b int) bool {
+		if partitions[a].Messages() != partitions[b].Messages() {
+			return partitions[a].Messages() > partitions[b].Messages()
+		}
+		return partitions[a].Partition < partitions[b].Partition
+	})
+
+	if len(partitions) > maxPartitions {
+		partitions = partitions[:maxPartitions]
+	}
+	return partitions
+}
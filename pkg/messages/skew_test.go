@@ -0,0 +1,69 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSkewStats(t *testing.T) {
+	stats := NewSkewStats([]int64{2, 4, 4, 4, 5, 5, 7, 9})
+	assert.Equal(t, int64(40), stats.Total)
+	assert.Equal(t, 5.0, stats.Mean)
+	assert.Equal(t, 2.0, stats.StdDev)
+	assert.Equal(t, int64(2), stats.Min)
+	assert.Equal(t, int64(9), stats.Max)
+	assert.Equal(t, 0.4, stats.CoefficientOfVariation())
+	assert.Equal(t, 1.8, stats.MaxToMean())
+
+	ratio, ok := stats.MaxToMin()
+	assert.True(t, ok)
+	assert.Equal(t, 4.5, ratio)
+
+	stats = NewSkewStats([]int64{0, 10})
+	_, ok = stats.MaxToMin()
+	assert.False(t, ok)
+
+	assert.Equal(t, SkewStats{}, NewSkewStats(nil))
+	assert.Equal(t, 0.0, SkewStats{}.CoefficientOfVariation())
+	assert.Equal(t, 0.0, SkewStats{}.MaxToMean())
+}
+
+func TestPartitionSkew(t *testing.T) {
+	startTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	throughput := NewTopicThroughput(
+		"topic1",
+		startTime,
+		map[int]int64{0: 0, 1: 0, 2: 0, 3: 0},
+		map[int]int64{0: 0, 1: 0, 2: 0, 3: 0},
+		startTime.Add(time.Minute),
+		map[int]int64{0: 10, 1: 70, 2: 10, 3: 10},
+		map[int]int64{0: 100, 1: 700, 2: 100, 3: 100},
+	)
+
+	skew := NewPartitionSkew(throughput)
+	assert.True(t, skew.BytesKnown)
+	assert.Equal(t, int64(100), skew.Messages.Total)
+	assert.Equal(t, int64(1000), skew.Bytes.Total)
+	assert.Equal(t, 2.8, skew.Messages.MaxToMean())
+
+	hottest := skew.HottestPartitions(2)
+	assert.Equal(t, 2, len(hottest))
+	assert.Equal(t, 1, hottest[0].Partition)
+	assert.Equal(t, 0, hottest[1].Partition)
+
+	noSizes := NewTopicThroughput(
+		"topic1",
+		startTime,
+		map[int]int64{0: 0},
+		nil,
+		startTime.Add(time.Minute),
+		map[int]int64{0: 10},
+		nil,
+	)
+	skew = NewPartitionSkew(noSizes)
+	assert.False(t, skew.BytesKnown)
+	assert.Equal(t, SkewStats{}, skew.Bytes)
+}
@@ -2,6 +2,9 @@ package messages
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -20,14 +23,89 @@ import (
 	_ "github.com/segmentio/kafka-go/zstd"
 )
 
+// TailFormat is a string type that stores the name of the format used for outputting
+// tailed messages.
+type TailFormat string
+
+const (
+	// TailFormatDefault prints out each message in a human-readable, multi-line block.
+	TailFormatDefault TailFormat = "default"
+
+	// TailFormatJSONL prints out each message as a single-line JSON object.
+	TailFormatJSONL TailFormat = "jsonl"
+
+	// TailFormatRaw prints out the value of each message as-is.
+	TailFormatRaw TailFormat = "raw"
+
+	// TailFormatHex prints out the value of each message as a hex string.
+	TailFormatHex TailFormat = "hex"
+
+	// TailFormatKeyOnly prints out the key of each message as-is.
+	TailFormatKeyOnly TailFormat = "key-only"
+)
+
+// AllTailFormats contains all of the valid tail formats.
+var AllTailFormats = []TailFormat{
+	TailFormatDefault,
+	TailFormatJSONL,
+	TailFormatRaw,
+	TailFormatHex,
+	TailFormatKeyOnly,
+}
+
+// TailOutputConfig configures how tailed messages are printed out. The include flags
+// only apply to the script-oriented formats; the default format always includes the
+// partition, offset, timestamp, and headers. The topic is always included when tailing
+// multiple topics.
+type TailOutputConfig struct {
+	Format           TailFormat
+	IncludeTopic     bool
+	IncludePartition bool
+	IncludeOffset    bool
+	IncludeTimestamp bool
+	IncludeHeaders   bool
+
+	// Codec, if set, decodes message values before they're filtered and printed.
+	Codec Codec
+}
+
+// Validate determines whether the output config is valid.
+func (c TailOutputConfig) Validate() error {
+	for _, format := range AllTailFormats {
+		if c.Format == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("Format must be in %+v", AllTailFormats)
+}
+
+// Interactive returns whether the output is meant to be read by humans (as opposed to
+// scripts). Log messages and tail stats are only shown in interactive mode.
+func (c TailOutputConfig) Interactive() bool {
+	return c.Format == "" || c.Format == TailFormatDefault
+}
+
+// tailJSONMessage is the representation of a message in the jsonl format.
+type tailJSONMessage struct {
+	Topic         string            `json:"topic,omitempty"`
+	Partition     *int              `json:"partition,omitempty"`
+	Offset        *int64            `json:"offset,omitempty"`
+	Timestamp     *time.Time        `json:"timestamp,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Key           string            `json:"key"`
+	KeyEncoding   string            `json:"keyEncoding,omitempty"`
+	Value         string            `json:"value"`
+	ValueEncoding string            `json:"valueEncoding,omitempty"`
+}
+
 // TopicTailer fetches a stream of messages from a topic.
 type TopicTailer struct {
-	brokerAddr string
-	topic      string
-	partitions []int
-	offset     int64
-	minBytes   int
-	maxBytes   int
+	brokerAddr      string
+	topicPartitions map[string][]int
+	groupID         string
+	offset          int64
+	minBytes        int
+	maxBytes        int
 }
 
 // NewTopicTailer returns a new TopicTailer instance.
@@ -38,27 +116,54 @@ func NewTopicTailer(
 	offset int64,
 	minBytes int,
 	maxBytes int,
+) *TopicTailer {
+	return NewMultiTopicTailer(
+		brokerAddr,
+		map[string][]int{topic: partitions},
+		"",
+		offset,
+		minBytes,
+		maxBytes,
+	)
+}
+
+// NewMultiTopicTailer returns a new TopicTailer instance that tails the argument partitions
+// in multiple topics. The messages from all of the topics are interleaved in the output.
+//
+// If groupID is set, the tailer joins the associated consumer group and commits its offsets
+// instead of reading the argument partitions directly. Partitions are then assigned by the
+// group, and the offset (which must be kafka.FirstOffset or kafka.LastOffset) is only used
+// for partitions that don't have a committed offset yet.
+func NewMultiTopicTailer(
+	brokerAddr string,
+	topicPartitions map[string][]int,
+	groupID string,
+	offset int64,
+	minBytes int,
+	maxBytes int,
 ) *TopicTailer {
 	return &TopicTailer{
-		brokerAddr: brokerAddr,
-		topic:      topic,
-		partitions: partitions,
-		offset:     offset,
-		minBytes:   minBytes,
-		maxBytes:   maxBytes,
+		brokerAddr:      brokerAddr,
+		topicPartitions: topicPartitions,
+		groupID:         groupID,
+		offset:          offset,
+		minBytes:        minBytes,
+		maxBytes:        maxBytes,
 	}
 }
 
 // TailMessage represents a single message retrieved from a kafka reader.
 type TailMessage struct {
 	Message   kafka.Message
+	Topic     string
 	Partition int
 	Err       error
 }
 
-// TailStats stores stats on all partitions that are tailed.
+// TailStats stores stats on all partitions that are tailed, keyed by topic and then
+// partition.
 type TailStats struct {
-	PartitionStats map[int]*TailPartitionStats
+	PartitionStats map[string]map[int]*TailPartitionStats
 }
 
 // TailPartitionStats stores stats on the fetches from a single topic
@@ -83,28 +188,52 @@ func (t *TopicTailer) GetMessages(
 ) {
 	readers := []*kafka.Reader{}
 
-	for _, partition := range t.partitions {
-		reader := kafka.NewReader(
-			kafka.ReaderConfig{
-				Brokers:        []string{t.brokerAddr},
-				Topic:          t.topic,
-				Partition:      partition,
-				MinBytes:       t.minBytes,
-				MaxBytes:       t.maxBytes,
-				ReadBackoffMin: 200 * time.Millisecond,
-				ReadBackoffMax: 3 * time.Second,
-				MaxAttempts:    5,
-			},
-		)
+	for topic, partitions := range t.topicPartitions {
+		if t.groupID != "" {
+			readers = append(
+				readers,
+				kafka.NewReader(
+					kafka.ReaderConfig{
+						Brokers:        []string{t.brokerAddr},
+						GroupID:        t.groupID,
+						Topic:          topic,
+						StartOffset:    t.offset,
+						CommitInterval: time.Second,
+						MinBytes:       t.minBytes,
+						MaxBytes:       t.maxBytes,
+						ReadBackoffMin: 200 * time.Millisecond,
+						ReadBackoffMax: 3 * time.Second,
+						MaxAttempts:    5,
+					},
+				),
+			)
+			continue
+		}
 
-		reader.SetOffset(t.offset)
-		readers = append(readers, reader)
+		for _, partition := range partitions {
+			reader := kafka.NewReader(
+				kafka.ReaderConfig{
+					Brokers:        []string{t.brokerAddr},
+					Topic:          topic,
+					Partition:      partition,
+					MinBytes:       t.minBytes,
+					MaxBytes:       t.maxBytes,
+					ReadBackoffMin: 200 * time.Millisecond,
+					ReadBackoffMax: 3 * time.Second,
+					MaxAttempts:    5,
+				},
+			)
+
+			reader.SetOffset(t.offset)
+			readers = append(readers, reader)
+		}
 	}
 
 	for _, reader := range readers {
 		go func(r *kafka.Reader) {
 			log.Debugf(
-				"Starting read loop for partition %d",
+				"Starting read loop for topic %s, partition %d",
+				r.Config().Topic,
 				r.Config().Partition,
 			)
 			defer r.Close()
@@ -113,44 +242,79 @@ func (t *TopicTailer) GetMessages(
 				message, err := r.ReadMessage(ctx)
 
 				if err != nil {
+					topic := r.Config().Topic
 					partition := r.Config().Partition
 
-					if strings.Contains(err.Error(), "connection reset") ||
+					if ctx.Err() != nil {
+						// The tail was stopped; nothing is reading the channel anymore
+						return
+					} else if strings.Contains(err.Error(), "connection reset") ||
 						strings.Contains(err.Error(), "broken pipe") ||
 						strings.Contains(err.Error(), "i/o timeout") {
 						// These errors are recoverable, just try again
 						log.Warnf(
-							"Got connection error reading from partition %d, retrying: %+v",
+							"Got connection error reading from topic %s, partition %d, retrying: %+v",
+							topic,
 							partition,
 							err,
 						)
 						continue
 					} else {
 						// Any other error will cause the reader to stop
-						messagesChan <- TailMessage{
-							Partition: r.Config().Partition,
-							Err:       err,
-						}
+						sendTailMessage(
+							ctx,
+							messagesChan,
+							TailMessage{
+								Topic:     topic,
+								Partition: partition,
+								Err:       err,
+							},
+						)
 						return
 					}
 				}
 
-				if message.Topic != t.topic {
+				if message.Topic != r.Config().Topic {
 					// At the start, we sometimes get a junk message with an
 					// empty topic
 					continue
 				}
 
-				messagesChan <- TailMessage{
-					Message:   message,
-					Partition: r.Config().Partition,
-					Err:       nil,
+				// Use the partition from the message since readers in a consumer group
+				// can be assigned more than one
+				if !sendTailMessage(
+					ctx,
+					messagesChan,
+					TailMessage{
+						Message:   message,
+						Topic:     r.Config().Topic,
+						Partition: message.Partition,
+						Err:       nil,
+					},
+				) {
+					return
 				}
 			}
 		}(reader)
 	}
 }
 
+// sendTailMessage sends the argument message to the channel unless the context is done
+// first, so that readers don't block forever once the consumer of the channel has stopped.
+// It returns whether the message was sent.
+func sendTailMessage(
+	ctx context.Context,
+	messagesChan chan TailMessage,
+	message TailMessage,
+) bool {
+	select {
+	case messagesChan <- message:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // LogMessages logs out the message stream from the tailer. It returns stats
 // from the tail run that can be displayed by the caller after the context is cancelled or
 // maxMessages messages have been tailed.
@@ -158,26 +322,30 @@ func (t *TopicTailer) LogMessages(
 	ctx context.Context,
 	maxMessages int,
 	filterRegexp string,
-	raw bool,
+	outputConfig TailOutputConfig,
 ) (TailStats, error) {
-	var filterRegexpObj *regexp.Regexp
-	var err error
-	if filterRegexp != "" {
-		filterRegexpObj, err = regexp.Compile(filterRegexp)
-		if err != nil {
-			return TailStats{}, err
-		}
+	filterRegexpObj, err := compileFilter(filterRegexp)
+	if err != nil {
+		return TailStats{}, err
 	}
 
 	messagesChan := make(chan TailMessage, 100)
 	t.GetMessages(ctx, messagesChan)
 
 	stats := TailStats{
-		PartitionStats: map[int]*TailPartitionStats{},
+		PartitionStats: map[string]map[int]*TailPartitionStats{},
 	}
 
-	for _, partition := range t.partitions {
-		stats.PartitionStats[partition] = &TailPartitionStats{}
+	for topic, partitions := range t.topicPartitions {
+		stats.PartitionStats[topic] = map[int]*TailPartitionStats{}
+		for _, partition := range partitions {
+			stats.PartitionStats[topic][partition] = &TailPartitionStats{}
+		}
+	}
+
+	// Always show the topic if there's more than one of them
+	if len(t.topicPartitions) > 1 {
+		outputConfig.IncludeTopic = true
 	}
 
 	for {
@@ -185,8 +353,12 @@ func (t *TopicTailer) LogMessages(
 		case <-ctx.Done():
 			return stats, ctx.Err()
 		case tailMessage := <-messagesChan:
-			partition := tailMessage.Partition
-			partitionStats := stats.PartitionStats[partition]
+			partitionStats, ok := stats.PartitionStats[tailMessage.Topic][tailMessage.Partition]
+			if !ok {
+				// Consumer groups can pick up partitions that were added after the tail started
+				partitionStats = &TailPartitionStats{}
+				stats.PartitionStats[tailMessage.Topic][tailMessage.Partition] = partitionStats
+			}
 
 			if tailMessage.Err != nil {
 				log.Warnf("Got error: %+v", tailMessage.Err)
@@ -205,6 +377,12 @@ func (t *TopicTailer) LogMessages(
 			partitionStats.LastOffset = tailMessage.Message.Offset
 			partitionStats.LastTime = tailMessage.Message.Time
 
+			decoded, err := DecodeMessage(ctx, outputConfig.Codec, tailMessage.Message)
+			if err != nil {
+				log.Warnf("%+v; showing raw value", err)
+			}
+			tailMessage.Message = decoded
+
 			if filterRegexpObj != nil && !filterRegexpObj.Match(tailMessage.Message.Value) {
 				continue
 			}
@@ -213,62 +391,196 @@ func (t *TopicTailer) LogMessages(
 			partitionStats.TotalMessageBytesFiltered += int64(len(tailMessage.Message.Key))
 			partitionStats.TotalMessageBytesFiltered += int64(len(tailMessage.Message.Value))
 
-			if raw {
-				fmt.Printf("%s\n", string(tailMessage.Message.Value))
-				continue
+			if err := PrintMessage(tailMessage.Message, outputConfig); err != nil {
+				return stats, err
 			}
 
-			var dividerPrinter func(f string, a ...interface{}) string
-			var keyPrinter func(f string, a ...interface{}) string
-			var valuePrinter func(f string, a ...interface{}) string
-			var messagePrinter func(f string, a ...interface{}) string
-
-			if !util.InTerminal() {
-				dividerPrinter = fmt.Sprintf
-				keyPrinter = fmt.Sprintf
-				valuePrinter = fmt.Sprintf
-				messagePrinter = fmt.Sprintf
-			} else {
-				dividerPrinter = color.New(color.FgGreen, color.Faint).SprintfFunc()
-				keyPrinter = color.New(color.FgBlue, color.Bold).SprintfFunc()
-				valuePrinter = color.New(color.FgYellow).SprintfFunc()
-				messagePrinter = fmt.Sprintf
+			if maxMessages > 0 && partitionStats.TotalMessages >= maxMessages {
+				return stats, nil
 			}
+		}
+	}
+}
 
-			fmt.Println(
-				dividerPrinter("======================================================="),
-			)
-			fmt.Printf(
-				"%s %s\n",
-				keyPrinter("Partition:"),
-				valuePrinter("%d", tailMessage.Message.Partition),
-			)
-			fmt.Printf(
-				"%s %s\n",
-				keyPrinter("Offset:   "),
-				valuePrinter("%d", tailMessage.Message.Offset),
-			)
-			fmt.Printf(
-				"%s %s\n",
-				keyPrinter("Time:     "),
-				valuePrinter(tailMessage.Message.Time.Format(time.RFC3339)),
-			)
-			fmt.Printf(
-				"%s %s\n",
-				keyPrinter("Key:      "),
-				valuePrinter(bytesToStr(tailMessage.Message.Key)),
-			)
+// compileFilter compiles the argument filter regexp, returning nil if it's empty.
+func compileFilter(filterRegexp string) (*regexp.Regexp, error) {
+	if filterRegexp == "" {
+		return nil, nil
+	}
+	return regexp.Compile(filterRegexp)
+}
+
+// PrintMessage prints out a single message to stdout using the argument output config.
+// In the default format, the message headers are always shown since they often carry
+// routing information (e.g., tenant IDs); in the others, they're only included if
+// IncludeHeaders is set.
+func PrintMessage(message kafka.Message, outputConfig TailOutputConfig) error {
+	if !outputConfig.Interactive() {
+		formatted, err := FormatTailMessage(message, outputConfig)
+		if err != nil {
+			return err
+		}
+		fmt.Println(formatted)
+		return nil
+	}
+
+	var dividerPrinter func(f string, a ...interface{}) string
+	var keyPrinter func(f string, a ...interface{}) string
+	var valuePrinter func(f string, a ...interface{}) string
+	var headerKeyPrinter func(f string, a ...interface{}) string
+	var messagePrinter func(f string, a ...interface{}) string
+
+	if !util.InTerminal() {
+		dividerPrinter = fmt.Sprintf
+		keyPrinter = fmt.Sprintf
+		valuePrinter = fmt.Sprintf
+		headerKeyPrinter = fmt.Sprintf
+		messagePrinter = fmt.Sprintf
+	} else {
+		dividerPrinter = color.New(color.FgGreen, color.Faint).SprintfFunc()
+		keyPrinter = color.New(color.FgBlue, color.Bold).SprintfFunc()
+		valuePrinter = color.New(color.FgYellow).SprintfFunc()
+		headerKeyPrinter = color.New(color.FgCyan).SprintfFunc()
+		messagePrinter = fmt.Sprintf
+	}
+
+	fmt.Println(
+		dividerPrinter("======================================================="),
+	)
+	if outputConfig.IncludeTopic {
+		fmt.Printf(
+			"%s %s\n",
+			keyPrinter("Topic:    "),
+			valuePrinter(message.Topic),
+		)
+	}
+	fmt.Printf(
+		"%s %s\n",
+		keyPrinter("Partition:"),
+		valuePrinter("%d", message.Partition),
+	)
+	fmt.Printf(
+		"%s %s\n",
+		keyPrinter("Offset:   "),
+		valuePrinter("%d", message.Offset),
+	)
+	fmt.Printf(
+		"%s %s\n",
+		keyPrinter("Time:     "),
+		valuePrinter(message.Time.Format(time.RFC3339)),
+	)
+	fmt.Printf(
+		"%s %s\n",
+		keyPrinter("Key:      "),
+		valuePrinter(bytesToStr(message.Key)),
+	)
+	if len(message.Headers) > 0 {
+		fmt.Printf("%s\n", keyPrinter("Headers:  "))
+
+		maxKeyLen := 0
+		for _, header := range message.Headers {
+			if len(header.Key) > maxKeyLen {
+				maxKeyLen = len(header.Key)
+			}
+		}
+		for _, header := range message.Headers {
 			fmt.Printf(
-				"%s %s\n",
-				keyPrinter("Value:    "),
-				messagePrinter(bytesToStr(tailMessage.Message.Value)),
+				"  %s %s\n",
+				headerKeyPrinter("%-*s", maxKeyLen+1, header.Key+":"),
+				valuePrinter(bytesToStr(header.Value)),
 			)
+		}
+	}
+	fmt.Printf(
+		"%s %s\n",
+		keyPrinter("Value:    "),
+		messagePrinter(bytesToStr(message.Value)),
+	)
 
-			if maxMessages > 0 && partitionStats.TotalMessages >= maxMessages {
-				return stats, nil
+	return nil
+}
+
+// FormatTailMessage formats a single message for one of the script-oriented output
+// formats. In the jsonl format, the included fields are set in the JSON object; in the
+// others, they're prepended to the output and separated by tabs.
+func FormatTailMessage(message kafka.Message, outputConfig TailOutputConfig) (string, error) {
+	if outputConfig.Format == TailFormatJSONL {
+		jsonMessage := tailJSONMessage{}
+
+		if outputConfig.IncludeTopic {
+			jsonMessage.Topic = message.Topic
+		}
+		if outputConfig.IncludePartition {
+			jsonMessage.Partition = &message.Partition
+		}
+		if outputConfig.IncludeOffset {
+			jsonMessage.Offset = &message.Offset
+		}
+		if outputConfig.IncludeTimestamp {
+			jsonMessage.Timestamp = &message.Time
+		}
+		if outputConfig.IncludeHeaders && len(message.Headers) > 0 {
+			jsonMessage.Headers = map[string]string{}
+			for _, header := range message.Headers {
+				jsonMessage.Headers[header.Key] = string(header.Value)
 			}
 		}
+		jsonMessage.Key, jsonMessage.KeyEncoding = encodeBytes(message.Key)
+		jsonMessage.Value, jsonMessage.ValueEncoding = encodeBytes(message.Value)
+
+		contents, err := json.Marshal(jsonMessage)
+		if err != nil {
+			return "", err
+		}
+		return string(contents), nil
+	}
+
+	fields := []string{}
+
+	if outputConfig.IncludeTopic {
+		fields = append(fields, message.Topic)
+	}
+	if outputConfig.IncludePartition {
+		fields = append(fields, fmt.Sprintf("%d", message.Partition))
+	}
+	if outputConfig.IncludeOffset {
+		fields = append(fields, fmt.Sprintf("%d", message.Offset))
+	}
+	if outputConfig.IncludeTimestamp {
+		fields = append(fields, message.Time.Format(time.RFC3339Nano))
+	}
+	if outputConfig.IncludeHeaders {
+		headerStrs := []string{}
+		for _, header := range message.Headers {
+			headerStrs = append(
+				headerStrs,
+				fmt.Sprintf("%s=%s", header.Key, string(header.Value)),
+			)
+		}
+		fields = append(fields, strings.Join(headerStrs, ","))
+	}
+
+	switch outputConfig.Format {
+	case TailFormatRaw:
+		fields = append(fields, string(message.Value))
+	case TailFormatHex:
+		fields = append(fields, hex.EncodeToString(message.Value))
+	case TailFormatKeyOnly:
+		fields = append(fields, string(message.Key))
+	default:
+		return "", fmt.Errorf("Unsupported tail format: %s", outputConfig.Format)
+	}
+
+	return strings.Join(fields, "\t"), nil
+}
+
+// encodeBytes returns a JSON-friendly version of a byte sequence along with the encoding
+// used. Valid UTF-8 strings are returned as-is, and everything else is base64-encoded.
+func encodeBytes(input []byte) (string, string) {
+	if utf8.Valid(input) {
+		return string(input), ""
 	}
+	return base64.StdEncoding.EncodeToString(input), "base64"
 }
 
 // bytesToStr makes a screen-printable version of a byte sequence.
@@ -88,3 +88,208 @@ outerLoop:
 
 	assert.Equal(t, 10, len(seenKeys))
 }
+
+func TestTailerGetMessagesGroup(t *testing.T) {
+	ctx := context.Background()
+	controllerConn := util.TestKafkaContollerConn(ctx, t)
+
+	topicName := util.RandomString("topic-tail-group-", 6)
+	groupID := util.RandomString("group-tail-", 6)
+
+	err := controllerConn.CreateTopics(
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     2,
+			ReplicationFactor: 1,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:  []string{util.TestKafkaAddr()},
+			Topic:    topicName,
+			Balancer: &kafka.RoundRobin{},
+		},
+	)
+	defer writer.Close()
+
+	writeMessages := func(start int, count int) {
+		messages := []kafka.Message{}
+		for i := start; i < start+count; i++ {
+			messages = append(
+				messages,
+				kafka.Message{
+					Key:   []byte(fmt.Sprintf("key%d", i)),
+					Value: []byte(fmt.Sprintf("value%d", i)),
+				},
+			)
+		}
+		require.Nil(t, writer.WriteMessages(ctx, messages...))
+	}
+
+	readKeys := func(count int) map[string]struct{} {
+		readCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		tailer := NewMultiTopicTailer(
+			util.TestKafkaAddr(),
+			map[string][]int{topicName: {0, 1}},
+			groupID,
+			kafka.FirstOffset,
+			1,
+			1000,
+		)
+		messagesChan := make(chan TailMessage, 100)
+		tailer.GetMessages(readCtx, messagesChan)
+
+		timer := time.NewTimer(20 * time.Second)
+		seenKeys := map[string]struct{}{}
+
+		for len(seenKeys) < count {
+			select {
+			case message := <-messagesChan:
+				require.Nil(t, message.Err)
+				seenKeys[string(message.Message.Key)] = struct{}{}
+			case <-timer.C:
+				return seenKeys
+			}
+		}
+
+		// Give the reader time to commit its offsets
+		time.Sleep(3 * time.Second)
+		return seenKeys
+	}
+
+	writeMessages(0, 10)
+	assert.Equal(t, 10, len(readKeys(10)))
+
+	// The second tail should resume from the committed offsets
+	writeMessages(10, 5)
+	seenKeys := readKeys(5)
+	assert.Equal(t, 5, len(seenKeys))
+	_, ok := seenKeys["key10"]
+	assert.True(t, ok)
+	_, ok = seenKeys["key0"]
+	assert.False(t, ok)
+}
+
+func TestFormatTailMessage(t *testing.T) {
+	message := kafka.Message{
+		Topic:     "topic1",
+		Partition: 3,
+		Offset:    1234,
+		Key:       []byte("key1"),
+		Value:     []byte("value1"),
+		Headers: []kafka.Header{
+			{Key: "header1", Value: []byte("hvalue1")},
+			{Key: "header2", Value: []byte("hvalue2")},
+		},
+		Time: time.Date(2020, 6, 1, 12, 30, 0, 0, time.UTC),
+	}
+	allFields := TailOutputConfig{
+		IncludePartition: true,
+		IncludeOffset:    true,
+		IncludeTimestamp: true,
+		IncludeHeaders:   true,
+	}
+
+	type formatTestCase struct {
+		description  string
+		outputConfig TailOutputConfig
+		message      kafka.Message
+		expected     string
+	}
+
+	testCases := []formatTestCase{
+		{
+			description:  "raw",
+			outputConfig: TailOutputConfig{Format: TailFormatRaw},
+			message:      message,
+			expected:     "value1",
+		},
+		{
+			description:  "hex",
+			outputConfig: TailOutputConfig{Format: TailFormatHex},
+			message:      message,
+			expected:     "76616c756531",
+		},
+		{
+			description:  "key-only",
+			outputConfig: TailOutputConfig{Format: TailFormatKeyOnly},
+			message:      message,
+			expected:     "key1",
+		},
+		{
+			description:  "jsonl",
+			outputConfig: TailOutputConfig{Format: TailFormatJSONL},
+			message:      message,
+			expected:     `{"key":"key1","value":"value1"}`,
+		},
+		{
+			description:  "jsonl binary",
+			outputConfig: TailOutputConfig{Format: TailFormatJSONL},
+			message: kafka.Message{
+				Key:   []byte("key1"),
+				Value: []byte{0xff, 0x00},
+			},
+			expected: `{"key":"key1","value":"/wA=","valueEncoding":"base64"}`,
+		},
+	}
+
+	testCases = append(
+		testCases,
+		formatTestCase{
+			description: "key-only with topic",
+			outputConfig: TailOutputConfig{
+				Format:       TailFormatKeyOnly,
+				IncludeTopic: true,
+			},
+			message:  message,
+			expected: "topic1\tkey1",
+		},
+		formatTestCase{
+			description: "jsonl with topic",
+			outputConfig: TailOutputConfig{
+				Format:       TailFormatJSONL,
+				IncludeTopic: true,
+			},
+			message:  message,
+			expected: `{"topic":"topic1","key":"key1","value":"value1"}`,
+		},
+	)
+
+	allFields.Format = TailFormatRaw
+	testCases = append(
+		testCases,
+		formatTestCase{
+			description:  "raw all fields",
+			outputConfig: allFields,
+			message:      message,
+			expected:     "3\t1234\t2020-06-01T12:30:00Z\theader1=hvalue1,header2=hvalue2\tvalue1",
+		},
+	)
+
+	allFields.Format = TailFormatJSONL
+	testCases = append(
+		testCases,
+		formatTestCase{
+			description:  "jsonl all fields",
+			outputConfig: allFields,
+			message:      message,
+			expected:     `{"partition":3,"offset":1234,"timestamp":"2020-06-01T12:30:00Z","headers":{"header1":"hvalue1","header2":"hvalue2"},"key":"key1","value":"value1"}`,
+		},
+	)
+
+	for _, testCase := range testCases {
+		formatted, err := FormatTailMessage(testCase.message, testCase.outputConfig)
+		require.NoError(t, err, testCase.description)
+		assert.Equal(t, testCase.expected, formatted, testCase.description)
+	}
+
+	_, err := FormatTailMessage(message, TailOutputConfig{Format: TailFormatDefault})
+	assert.Error(t, err)
+	assert.Error(t, TailOutputConfig{Format: "bad-format"}.Validate())
+	assert.NoError(t, TailOutputConfig{Format: TailFormatHex}.Validate())
+}
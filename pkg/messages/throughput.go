@@ -0,0 +1,225 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PartitionThroughput stores the offsets and sizes of a single partition at the start and
+// end of a sample window.
+type PartitionThroughput struct {
+	Partition   int
+	StartOffset int64
+	EndOffset   int64
+
+	// StartSize and EndSize are the sizes of the partition's leader replica in bytes, or -1 if
+	// these aren't known.
+	StartSize int64
+	EndSize   int64
+}
+
+// Messages returns the number of offsets that were added to the partition during the window.
+// This can be slightly larger than the number of messages if the topic has transaction
+// control records.
+func (p PartitionThroughput) Messages() int64 {
+	if p.EndOffset < p.StartOffset {
+		return 0
+	}
+	return p.EndOffset - p.StartOffset
+}
+
+// Bytes returns the number of bytes that were added to the partition during the window and
+// whether this is known. The latter is false if the sizes couldn't be fetched or if the
+// partition shrank during the window (e.g., because old segments were deleted).
+func (p PartitionThroughput) Bytes() (int64, bool) {
+	if p.StartSize < 0 || p.EndSize < 0 || p.EndSize < p.StartSize {
+		return 0, false
+	}
+	return p.EndSize - p.StartSize, true
+}
+
+// TopicThroughput stores the throughput of all of the partitions in a topic over a sample
+// window.
+type TopicThroughput struct {
+	Topic      string
+	StartTime  time.Time
+	EndTime    time.Time
+	Partitions []PartitionThroughput
+}
+
+// Window returns the length of the sample window.
+func (t TopicThroughput) Window() time.Duration {
+	return t.EndTime.Sub(t.StartTime)
+}
+
+// TotalMessages returns the number of messages added across all partitions.
+func (t TopicThroughput) TotalMessages() int64 {
+	var total int64
+	for _, partition := range t.Partitions {
+		total += partition.Messages()
+	}
+	return total
+}
+
+// TotalBytes returns the number of bytes added across all partitions, along with whether
+// this is known for all of them.
+func (t TopicThroughput) TotalBytes() (int64, bool) {
+	var total int64
+	for _, partition := range t.Partitions {
+		bytes, ok := partition.Bytes()
+		if !ok {
+			return 0, false
+		}
+		total += bytes
+	}
+	return total, len(t.Partitions) > 0
+}
+
+// NewTopicThroughput combines the offsets and sizes sampled at the start and end of a window
+// into a TopicThroughput. The size maps can be nil if the sizes aren't known.
+func NewTopicThroughput(
+	topic string,
+	startTime time.Time,
+	startOffsets map[int]int64,
+	startSizes map[int]int64,
+	endTime time.Time,
+	endOffsets map[int]int64,
+	endSizes map[int]int64,
+) TopicThroughput {
+	throughput := TopicThroughput{
+		Topic:      topic,
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Partitions: []PartitionThroughput{},
+	}
+
+	for partition, startOffset := range startOffsets {
+		endOffset, ok := endOffsets[partition]
+		if !ok {
+			continue
+		}
+
+		partitionThroughput := PartitionThroughput{
+			Partition:   partition,
+			StartOffset: startOffset,
+			EndOffset:   endOffset,
+			StartSize:   -1,
+			EndSize:     -1,
+		}
+		if size, ok := startSizes[partition]; ok {
+			partitionThroughput.StartSize = size
+		}
+		if size, ok := endSizes[partition]; ok {
+			partitionThroughput.EndSize = size
+		}
+
+		throughput.Partitions = append(throughput.Partitions, partitionThroughput)
+	}
+
+	sort.Slice(throughput.Partitions, func(a, b int) bool {
+		return throughput.Partitions[a].Partition < throughput.Partitions[b].Partition
+	})
+
+	return throughput
+}
+
+// GetEndOffsets gets the end offset (i.e., the offset of the next message that will be
+// written) of each of the argument partitions in a topic, keyed by partition.
+func GetEndOffsets(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partitions []int,
+) (map[int]int64, error) {
+	return getOffsets(ctx, brokerAddr, topic, partitions, false)
+}
+
+// GetStartOffsets gets the start offset (i.e., the offset of the first message that hasn't
+// been deleted) of each of the argument partitions in a topic, keyed by partition.
+func GetStartOffsets(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partitions []int,
+) (map[int]int64, error) {
+	return getOffsets(ctx, brokerAddr, topic, partitions, true)
+}
+
+func getOffsets(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partitions []int,
+	first bool,
+) (map[int]int64, error) {
+	offsets := map[int]int64{}
+	errs := []error{}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	partitionsChan := make(chan int, len(partitions))
+	for _, partition := range partitions {
+		partitionsChan <- partition
+	}
+	close(partitionsChan)
+
+	for i := 0; i < numWorkers && i < len(partitions); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for partition := range partitionsChan {
+				offset, err := getOffset(ctx, brokerAddr, topic, partition, first)
+
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					offsets[partition] = offset
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return offsets, nil
+}
+
+func getOffset(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partition int,
+	first bool,
+) (int64, error) {
+	conn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var offset int64
+	if first {
+		offset, err = conn.ReadFirstOffset()
+	} else {
+		offset, err = conn.ReadLastOffset()
+	}
+	if err != nil {
+		return 0, fmt.Errorf(
+			"Error getting offset for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+	return offset, nil
+}
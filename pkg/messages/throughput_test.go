@@ -0,0 +1,64 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTopicThroughput(t *testing.T) {
+	startTime := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(10 * time.Second)
+
+	throughput := NewTopicThroughput(
+		"topic1",
+		startTime,
+		map[int]int64{0: 100, 1: 200, 2: 300},
+		map[int]int64{0: 1000, 1: 5000, 2: 3000},
+		endTime,
+		map[int]int64{0: 150, 1: 200, 2: 320},
+		map[int]int64{0: 1500, 1: 5000, 2: 100},
+	)
+
+	assert.Equal(t, 10*time.Second, throughput.Window())
+	assert.Equal(
+		t,
+		[]PartitionThroughput{
+			{Partition: 0, StartOffset: 100, EndOffset: 150, StartSize: 1000, EndSize: 1500},
+			{Partition: 1, StartOffset: 200, EndOffset: 200, StartSize: 5000, EndSize: 5000},
+			{Partition: 2, StartOffset: 300, EndOffset: 320, StartSize: 3000, EndSize: 100},
+		},
+		throughput.Partitions,
+	)
+	assert.Equal(t, int64(70), throughput.TotalMessages())
+
+	bytes, ok := throughput.Partitions[0].Bytes()
+	assert.True(t, ok)
+	assert.Equal(t, int64(500), bytes)
+
+	// Partition 2 shrank, so its byte count isn't known
+	_, ok = throughput.Partitions[2].Bytes()
+	assert.False(t, ok)
+	_, ok = throughput.TotalBytes()
+	assert.False(t, ok)
+
+	noSizes := NewTopicThroughput(
+		"topic1",
+		startTime,
+		map[int]int64{0: 100, 1: 200},
+		nil,
+		endTime,
+		map[int]int64{0: 110},
+		nil,
+	)
+	assert.Equal(
+		t,
+		[]PartitionThroughput{
+			{Partition: 0, StartOffset: 100, EndOffset: 110, StartSize: -1, EndSize: -1},
+		},
+		noSizes.Partitions,
+	)
+	_, ok = noSizes.TotalBytes()
+	assert.False(t, ok)
+}
@@ -0,0 +1,252 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// The version of kafka-go used by this repo doesn't support reading offsets with the
+	// read_committed isolation level, so the requests are made via the protocol package
+	// instead. v2 is the first version with an isolation level.
+	listOffsetsAPIKey     int16 = 2
+	listOffsetsAPIVersion int16 = 2
+
+	readCommittedIsolationLevel int8  = 1
+	latestOffsetTimestamp       int64 = -1
+)
+
+// PartitionTruncation describes the effect of truncating (i.e., deleting the records at
+// the start of) a single topic partition.
+type PartitionTruncation struct {
+	Partition int
+
+	// FirstOffset is the current first offset in the partition.
+	FirstOffset int64
+
+	// EndOffset is the offset of the next message that will be written to the partition.
+	EndOffset int64
+
+	// LastStableOffset is the offset of the first record in the partition that's part of a
+	// transaction that's still open. It's the same as EndOffset if there are no open
+	// transactions.
+	LastStableOffset int64
+
+	// BeforeOffset is the offset that all records will be deleted before; it becomes the
+	// new first offset in the partition.
+	BeforeOffset int64
+}
+
+// RemovedOffsets returns the number of offsets that are removed by the truncation. This
+// can be larger than the number of messages removed if the topic is compacted or has
+// transaction control records.
+func (p PartitionTruncation) RemovedOffsets() int64 {
+	return p.BeforeOffset - p.FirstOffset
+}
+
+// GetPartitionTruncations figures out the offsets that each of the argument partitions
+// would be truncated to. If beforeTime is set, all of the records before the first
+// message at or after this time are removed; otherwise, all of the records before
+// beforeOffset are removed. The offsets are clamped so that they're within the current
+// bounds of each partition and so that records in open transactions are never removed.
+func GetPartitionTruncations(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partitions []int,
+	beforeOffset int64,
+	beforeTime time.Time,
+) ([]PartitionTruncation, error) {
+	truncations := []PartitionTruncation{}
+
+	for _, partition := range partitions {
+		truncation, err := getPartitionTruncation(
+			ctx,
+			brokerAddr,
+			topic,
+			partition,
+			beforeOffset,
+			beforeTime,
+		)
+		if err != nil {
+			return nil, err
+		}
+		truncations = append(truncations, truncation)
+	}
+
+	return truncations, nil
+}
+
+func getPartitionTruncation(
+	ctx context.Context,
+	brokerAddr string,
+	topic string,
+	partition int,
+	beforeOffset int64,
+	beforeTime time.Time,
+) (PartitionTruncation, error) {
+	conn, err := dialLeaderRetries(ctx, brokerAddr, topic, partition)
+	if err != nil {
+		return PartitionTruncation{}, err
+	}
+	defer conn.Close()
+
+	firstOffset, endOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return PartitionTruncation{}, fmt.Errorf(
+			"Error getting offsets for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	if !beforeTime.IsZero() {
+		timeOffset, err := conn.ReadOffset(beforeTime)
+		if err != nil {
+			return PartitionTruncation{}, fmt.Errorf(
+				"Error getting offset for time %s in partition %d: %+v",
+				beforeTime.Format(time.RFC3339),
+				partition,
+				err,
+			)
+		}
+
+		if timeOffset < 0 {
+			// There are no messages at or after the argument time, so everything is removed
+			beforeOffset = endOffset
+		} else {
+			beforeOffset = timeOffset
+		}
+	}
+
+	stableOffset, err := getLastStableOffset(ctx, conn.RemoteAddr().String(), topic, partition)
+	if err != nil {
+		return PartitionTruncation{}, fmt.Errorf(
+			"Error getting last stable offset for partition %d: %+v",
+			partition,
+			err,
+		)
+	}
+
+	clampedOffset := clampTruncationOffset(beforeOffset, firstOffset, endOffset, stableOffset)
+	if clampedOffset < beforeOffset && clampedOffset == stableOffset && stableOffset < endOffset {
+		log.Infof(
+			"Partition %d has open transactions starting at offset %d, so it's only truncated up to there",
+			partition,
+			stableOffset,
+		)
+	}
+
+	return PartitionTruncation{
+		Partition:        partition,
+		FirstOffset:      firstOffset,
+		EndOffset:        endOffset,
+		LastStableOffset: stableOffset,
+		BeforeOffset:     clampedOffset,
+	}, nil
+}
+
+// clampTruncationOffset clamps the argument offset to the current bounds of a partition. The
+// upper bound is the last stable offset rather than the end offset so that records in open
+// transactions, which could still be aborted or committed, are never deleted.
+func clampTruncationOffset(
+	beforeOffset int64,
+	firstOffset int64,
+	endOffset int64,
+	stableOffset int64,
+) int64 {
+	upperBound := endOffset
+	if stableOffset < upperBound {
+		upperBound = stableOffset
+	}
+
+	if beforeOffset > upperBound {
+		beforeOffset = upperBound
+	}
+	if beforeOffset < firstOffset {
+		beforeOffset = firstOffset
+	}
+	return beforeOffset
+}
+
+// getLastStableOffset gets the last stable offset of a partition from its leader via a
+// ListOffsets request with the read_committed isolation level.
+func getLastStableOffset(
+	ctx context.Context,
+	leaderAddr string,
+	topic string,
+	partition int,
+) (int64, error) {
+	response, err := protocol.RoundTrip(
+		ctx,
+		leaderAddr,
+		listOffsetsAPIKey,
+		listOffsetsAPIVersion,
+		encodeListOffsetsRequest(topic, partition),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return decodeListOffsetsResponse(response, topic, partition)
+}
+
+// encodeListOffsetsRequest encodes the body of a v2 ListOffsets request for the latest
+// read_committed offset of a single partition.
+func encodeListOffsetsRequest(topic string, partition int) *protocol.Encoder {
+	body := &protocol.Encoder{}
+
+	// Replica ID; -1 for regular clients
+	body.WriteInt32(-1)
+	body.WriteInt8(readCommittedIsolationLevel)
+
+	body.WriteInt32(1)
+	body.WriteString(topic)
+	body.WriteInt32(1)
+	body.WriteInt32(int32(partition))
+	body.WriteInt64(latestOffsetTimestamp)
+
+	return body
+}
+
+// decodeListOffsetsResponse decodes the body of a v2 ListOffsets response and returns the
+// offset for the argument partition.
+func decodeListOffsetsResponse(
+	response *protocol.Decoder,
+	topic string,
+	partition int,
+) (int64, error) {
+	// Throttle time
+	response.ReadInt32()
+
+	numTopics := response.ReadInt32()
+	for i := 0; i < int(numTopics) && response.Err() == nil; i++ {
+		name := response.ReadString()
+
+		numPartitions := response.ReadInt32()
+		for j := 0; j < int(numPartitions) && response.Err() == nil; j++ {
+			partitionID := response.ReadInt32()
+			errorCode := response.ReadInt16()
+			// Timestamp
+			response.ReadInt64()
+			offset := response.ReadInt64()
+
+			if response.Err() != nil || name != topic || int(partitionID) != partition {
+				continue
+			}
+			if errorCode != 0 {
+				return 0, kafka.Error(errorCode)
+			}
+			return offset, nil
+		}
+	}
+
+	if err := response.Err(); err != nil {
+		return 0, fmt.Errorf("Error decoding ListOffsets response: %+v", err)
+	}
+	return 0, fmt.Errorf("Partition %d of topic %s not in ListOffsets response", partition, topic)
+}
@@ -0,0 +1,208 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/topicctl/pkg/protocol"
+	"github.com/segmentio/topicctl/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPartitionTruncations(t *testing.T) {
+	ctx := context.Background()
+	controllerConn := util.TestKafkaContollerConn(ctx, t)
+
+	topicName := util.RandomString("topic-truncate-", 6)
+
+	err := controllerConn.CreateTopics(
+		kafka.TopicConfig{
+			Topic:             topicName,
+			NumPartitions:     1,
+			ReplicationFactor: 1,
+		},
+	)
+	require.Nil(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	writer := kafka.NewWriter(
+		kafka.WriterConfig{
+			Brokers:   []string{util.TestKafkaAddr()},
+			Topic:     topicName,
+			BatchSize: 5,
+		},
+	)
+	defer writer.Close()
+
+	messages := []kafka.Message{}
+	for i := 0; i < 10; i++ {
+		messages = append(
+			messages,
+			kafka.Message{
+				Key:   []byte(fmt.Sprintf("key%d", i)),
+				Value: []byte(fmt.Sprintf("value%d", i)),
+			},
+		)
+	}
+	require.Nil(t, writer.WriteMessages(ctx, messages...))
+
+	truncations, err := GetPartitionTruncations(
+		ctx,
+		util.TestKafkaAddr(),
+		topicName,
+		[]int{0},
+		4,
+		time.Time{},
+	)
+	require.Nil(t, err)
+	assert.Equal(
+		t,
+		[]PartitionTruncation{
+			{
+				Partition:        0,
+				FirstOffset:      0,
+				EndOffset:        10,
+				LastStableOffset: 10,
+				BeforeOffset:     4,
+			},
+		},
+		truncations,
+	)
+	assert.Equal(t, int64(4), truncations[0].RemovedOffsets())
+
+	// Offsets past the end are clamped
+	truncations, err = GetPartitionTruncations(
+		ctx,
+		util.TestKafkaAddr(),
+		topicName,
+		[]int{0},
+		25,
+		time.Time{},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), truncations[0].BeforeOffset)
+
+	// Everything is before a time in the future
+	truncations, err = GetPartitionTruncations(
+		ctx,
+		util.TestKafkaAddr(),
+		topicName,
+		[]int{0},
+		0,
+		time.Now().Add(time.Hour),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), truncations[0].RemovedOffsets())
+
+	// Nothing is before a time in the past
+	truncations, err = GetPartitionTruncations(
+		ctx,
+		util.TestKafkaAddr(),
+		topicName,
+		[]int{0},
+		0,
+		time.Now().Add(-time.Hour),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), truncations[0].RemovedOffsets())
+}
+
+func TestClampTruncationOffset(t *testing.T) {
+	type testCase struct {
+		description  string
+		beforeOffset int64
+		stableOffset int64
+		expected     int64
+	}
+
+	// The partition has offsets 10-100
+	testCases := []testCase{
+		{
+			description:  "within bounds",
+			beforeOffset: 50,
+			stableOffset: 100,
+			expected:     50,
+		},
+		{
+			description:  "before first offset",
+			beforeOffset: 5,
+			stableOffset: 100,
+			expected:     10,
+		},
+		{
+			description:  "past end offset",
+			beforeOffset: 150,
+			stableOffset: 100,
+			expected:     100,
+		},
+		{
+			description:  "past last stable offset",
+			beforeOffset: 80,
+			stableOffset: 60,
+			expected:     60,
+		},
+		{
+			description:  "open transaction at first offset",
+			beforeOffset: 80,
+			stableOffset: 10,
+			expected:     10,
+		},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(
+			t,
+			testCase.expected,
+			clampTruncationOffset(testCase.beforeOffset, 10, 100, testCase.stableOffset),
+			testCase.description,
+		)
+	}
+}
+
+func TestDecodeListOffsetsResponse(t *testing.T) {
+	response := &protocol.Encoder{}
+	response.WriteInt32(0)
+	response.WriteInt32(1)
+	response.WriteString("test-topic")
+	response.WriteInt32(2)
+	for _, partition := range []struct {
+		id        int32
+		errorCode int16
+		offset    int64
+	}{
+		{id: 0, errorCode: 0, offset: 42},
+		{id: 1, errorCode: 6, offset: -1},
+	} {
+		response.WriteInt32(partition.id)
+		response.WriteInt16(partition.errorCode)
+		response.WriteInt64(-1)
+		response.WriteInt64(partition.offset)
+	}
+
+	offset, err := decodeListOffsetsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"test-topic",
+		0,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), offset)
+
+	_, err = decodeListOffsetsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"test-topic",
+		1,
+	)
+	assert.Error(t, err)
+
+	_, err = decodeListOffsetsResponse(
+		protocol.NewDecoder(bytes.NewReader(response.Bytes())),
+		"other-topic",
+		0,
+	)
+	assert.Error(t, err)
+}
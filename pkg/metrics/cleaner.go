@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PartitionDirtyRatio stores the log cleaner dirty ratio for a single topic partition, i.e.
+// the fraction of the partition's log that hasn't been compacted yet.
+type PartitionDirtyRatio struct {
+	Topic      string  `json:"topic"`
+	Partition  int     `json:"partition"`
+	DirtyRatio float64 `json:"dirtyRatio"`
+}
+
+// DirtyRatioFetcher is an interface for structs that can get the log cleaner dirty ratio of
+// each of the partitions in a compacted topic.
+type DirtyRatioFetcher interface {
+	// FetchDirtyRatios returns a map from partition ID to dirty ratio for the argument
+	// topic. Partitions without data can be omitted.
+	FetchDirtyRatios(ctx context.Context, topic string) (map[int]float64, error)
+}
+
+// DirtyRatios is a DirtyRatioFetcher backed by a map from topic name to partition ID to
+// dirty ratio.
+type DirtyRatios map[string]map[int]float64
+
+var _ DirtyRatioFetcher = (DirtyRatios)(nil)
+
+// FetchDirtyRatios returns the dirty ratios for the argument topic.
+func (d DirtyRatios) FetchDirtyRatios(
+	ctx context.Context,
+	topic string,
+) (map[int]float64, error) {
+	return d[topic], nil
+}
+
+// LoadDirtyRatios reads partition dirty ratios from a JSON or CSV file. The format is
+// determined from the file extension and matches the one used by NewFileFetcher, with
+// dirtyRatio in place of the bytes-in rate.
+func LoadDirtyRatios(path string) (DirtyRatios, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ratios := []PartitionDirtyRatio{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(file).Decode(&ratios); err != nil {
+			return nil, err
+		}
+	case ".csv":
+		csvReader := csv.NewReader(file)
+		csvReader.FieldsPerRecord = 3
+		csvReader.TrimLeadingSpace = true
+
+		records, err := csvReader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for r, record := range records {
+			if r == 0 {
+				continue
+			}
+
+			partition, err := strconv.Atoi(record[1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid partition in row %d: %+v", r+1, err)
+			}
+			dirtyRatio, err := strconv.ParseFloat(record[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid dirty ratio in row %d: %+v", r+1, err)
+			}
+
+			ratios = append(
+				ratios,
+				PartitionDirtyRatio{
+					Topic:      record[0],
+					Partition:  partition,
+					DirtyRatio: dirtyRatio,
+				},
+			)
+		}
+	default:
+		return nil, fmt.Errorf(
+			"Unrecognized metrics file extension for %s; must be .json or .csv",
+			path,
+		)
+	}
+
+	dirtyRatios := DirtyRatios{}
+	for _, ratio := range ratios {
+		if ratio.DirtyRatio < 0 || ratio.DirtyRatio > 1 {
+			return nil, fmt.Errorf(
+				"Dirty ratio for %s/%d must be between 0 and 1",
+				ratio.Topic,
+				ratio.Partition,
+			)
+		}
+		if _, ok := dirtyRatios[ratio.Topic]; !ok {
+			dirtyRatios[ratio.Topic] = map[int]float64{}
+		}
+		dirtyRatios[ratio.Topic][ratio.Partition] = ratio.DirtyRatio
+	}
+
+	return dirtyRatios, nil
+}
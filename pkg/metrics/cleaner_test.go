@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDirtyRatios(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonPath := filepath.Join(dir, "ratios.json")
+	require.Nil(
+		t,
+		ioutil.WriteFile(
+			jsonPath,
+			[]byte(`[
+				{"topic": "topic-a", "partition": 0, "dirtyRatio": 0.25},
+				{"topic": "topic-a", "partition": 1, "dirtyRatio": 0.9}
+			]`),
+			0644,
+		),
+	)
+	csvPath := filepath.Join(dir, "ratios.csv")
+	require.Nil(
+		t,
+		ioutil.WriteFile(
+			csvPath,
+			[]byte("topic,partition,dirty_ratio\ntopic-a,0,0.25\ntopic-a, 1, 0.9\n"),
+			0644,
+		),
+	)
+
+	expected := DirtyRatios{
+		"topic-a": {0: 0.25, 1: 0.9},
+	}
+
+	for _, path := range []string{jsonPath, csvPath} {
+		ratios, err := LoadDirtyRatios(path)
+		require.Nil(t, err)
+		assert.Equal(t, expected, ratios)
+
+		topicRatios, err := ratios.FetchDirtyRatios(context.Background(), "topic-a")
+		require.Nil(t, err)
+		assert.Equal(t, map[int]float64{0: 0.25, 1: 0.9}, topicRatios)
+	}
+
+	badPath := filepath.Join(dir, "bad.csv")
+	require.Nil(
+		t,
+		ioutil.WriteFile(badPath, []byte("topic,partition,dirty_ratio\ntopic-a,0,1.5\n"), 0644),
+	)
+	_, err = LoadDirtyRatios(badPath)
+	assert.NotNil(t, err)
+}
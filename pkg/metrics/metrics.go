@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PartitionRate stores the observed throughput for a single topic partition.
+type PartitionRate struct {
+	Topic         string  `json:"topic"`
+	Partition     int     `json:"partition"`
+	BytesInPerSec float64 `json:"bytesInPerSec"`
+}
+
+// Fetcher is an interface for structs that can get the recent throughput for each of the
+// partitions in a topic, e.g. from a file or a monitoring system.
+type Fetcher interface {
+	// FetchPartitionRates returns a map from partition ID to bytes-in rate for the argument
+	// topic. Partitions without data can be omitted.
+	FetchPartitionRates(ctx context.Context, topic string) (map[int]float64, error)
+}
+
+// FileFetcher is a Fetcher that reads partition rates from a JSON or CSV file.
+//
+// JSON files should contain a list of objects with topic, partition, and bytesInPerSec
+// keys. CSV files should have a header row followed by rows with the topic, partition,
+// and bytes-in rate, in that order.
+type FileFetcher struct {
+	rates []PartitionRate
+}
+
+var _ Fetcher = (*FileFetcher)(nil)
+
+// NewFileFetcher creates a new FileFetcher from the file at the argument path. The format is
+// determined from the file extension.
+func NewFileFetcher(path string) (*FileFetcher, error) {
+	var parser func(io.Reader) ([]PartitionRate, error)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		parser = ParseJSONRates
+	case ".csv":
+		parser = ParseCSVRates
+	default:
+		return nil, fmt.Errorf(
+			"Unrecognized metrics file extension for %s; must be .json or .csv",
+			path,
+		)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rates, err := parser(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileFetcher{rates: rates}, nil
+}
+
+// FetchPartitionRates returns the rates in the file for the argument topic.
+func (f *FileFetcher) FetchPartitionRates(
+	ctx context.Context,
+	topic string,
+) (map[int]float64, error) {
+	topicRates := map[int]float64{}
+
+	for _, rate := range f.rates {
+		if rate.Topic == topic {
+			topicRates[rate.Partition] = rate.BytesInPerSec
+		}
+	}
+
+	return topicRates, nil
+}
+
+// ParseJSONRates parses partition rates from a JSON list.
+func ParseJSONRates(reader io.Reader) ([]PartitionRate, error) {
+	rates := []PartitionRate{}
+	if err := json.NewDecoder(reader).Decode(&rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ParseCSVRates parses partition rates from CSV rows. The first row is assumed to be a
+// header and is skipped.
+func ParseCSVRates(reader io.Reader) ([]PartitionRate, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rates := []PartitionRate{}
+
+	for r, record := range records {
+		if r == 0 {
+			continue
+		}
+
+		partition, err := strconv.Atoi(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid partition in row %d: %+v", r+1, err)
+		}
+		bytesInPerSec, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bytes-in rate in row %d: %+v", r+1, err)
+		}
+
+		rates = append(
+			rates,
+			PartitionRate{
+				Topic:         record[0],
+				Partition:     partition,
+				BytesInPerSec: bytesInPerSec,
+			},
+		)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRates(t *testing.T) {
+	expected := []PartitionRate{
+		{
+			Topic:         "topic-a",
+			Partition:     0,
+			BytesInPerSec: 1000,
+		},
+		{
+			Topic:         "topic-a",
+			Partition:     1,
+			BytesInPerSec: 2500.5,
+		},
+		{
+			Topic:         "topic-b",
+			Partition:     0,
+			BytesInPerSec: 10,
+		},
+	}
+
+	jsonRates, err := ParseJSONRates(
+		strings.NewReader(`[
+			{"topic": "topic-a", "partition": 0, "bytesInPerSec": 1000},
+			{"topic": "topic-a", "partition": 1, "bytesInPerSec": 2500.5},
+			{"topic": "topic-b", "partition": 0, "bytesInPerSec": 10}
+		]`),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, expected, jsonRates)
+
+	csvRates, err := ParseCSVRates(
+		strings.NewReader(
+			"topic,partition,bytes_in_per_sec\ntopic-a,0,1000\ntopic-a, 1, 2500.5\ntopic-b,0,10\n",
+		),
+	)
+	require.Nil(t, err)
+	assert.Equal(t, expected, csvRates)
+
+	_, err = ParseCSVRates(strings.NewReader("topic,partition,rate\ntopic-a,zero,10\n"))
+	assert.NotNil(t, err)
+}
+
+func TestFileFetcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rates.csv")
+	require.Nil(
+		t,
+		ioutil.WriteFile(path, []byte("topic,partition,rate\ntopic-a,0,100\ntopic-b,1,200\n"), 0644),
+	)
+
+	fetcher, err := NewFileFetcher(path)
+	require.Nil(t, err)
+
+	rates, err := fetcher.FetchPartitionRates(context.Background(), "topic-b")
+	require.Nil(t, err)
+	assert.Equal(t, map[int]float64{1: 200}, rates)
+
+	_, err = NewFileFetcher(filepath.Join(dir, "rates.txt"))
+	assert.NotNil(t, err)
+}
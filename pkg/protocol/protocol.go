@@ -0,0 +1,359 @@
+// Package protocol contains helpers for making raw requests to Kafka brokers. It's used for
+// the APIs that aren't supported by the version of kafka-go used in this repo.
+package protocol
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	clientID = "topicctl"
+
+	// Deadline for each round trip, unless the context has an earlier one
+	requestTimeout = 45 * time.Second
+)
+
+var correlationIDs int32
+
+// Encoder builds the body of a request.
+type Encoder struct {
+	buf bytes.Buffer
+}
+
+// WriteBool writes a boolean as a single byte.
+func (e *Encoder) WriteBool(value bool) {
+	if value {
+		e.buf.WriteByte(1)
+	} else {
+		e.buf.WriteByte(0)
+	}
+}
+
+// WriteInt8 writes an 8-bit integer.
+func (e *Encoder) WriteInt8(value int8) {
+	e.buf.WriteByte(byte(value))
+}
+
+// WriteInt16 writes a 16-bit integer.
+func (e *Encoder) WriteInt16(value int16) {
+	binary.Write(&e.buf, binary.BigEndian, value)
+}
+
+// WriteInt32 writes a 32-bit integer. This is also used for array lengths.
+func (e *Encoder) WriteInt32(value int32) {
+	binary.Write(&e.buf, binary.BigEndian, value)
+}
+
+// WriteInt64 writes a 64-bit integer.
+func (e *Encoder) WriteInt64(value int64) {
+	binary.Write(&e.buf, binary.BigEndian, value)
+}
+
+// WriteString writes a (non-nullable) string.
+func (e *Encoder) WriteString(value string) {
+	e.WriteInt16(int16(len(value)))
+	e.buf.WriteString(value)
+}
+
+// WriteNullString writes a null value for a nullable string.
+func (e *Encoder) WriteNullString() {
+	e.WriteInt16(-1)
+}
+
+// WriteUvarint writes an unsigned varint. This is used for the lengths of compact strings
+// and arrays in flexible request versions.
+func (e *Encoder) WriteUvarint(value uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, value)
+	e.buf.Write(buf[:n])
+}
+
+// WriteCompactString writes a (non-nullable) compact string.
+func (e *Encoder) WriteCompactString(value string) {
+	e.WriteUvarint(uint64(len(value) + 1))
+	e.buf.WriteString(value)
+}
+
+// WriteCompactBytes writes a (non-nullable) compact byte array.
+func (e *Encoder) WriteCompactBytes(value []byte) {
+	e.WriteUvarint(uint64(len(value) + 1))
+	e.buf.Write(value)
+}
+
+// WriteCompactArrayLength writes the length of a compact array; the elements are written
+// separately.
+func (e *Encoder) WriteCompactArrayLength(length int) {
+	e.WriteUvarint(uint64(length + 1))
+}
+
+// WriteCompactNullArray writes a null value for a nullable compact array.
+func (e *Encoder) WriteCompactNullArray() {
+	e.WriteUvarint(0)
+}
+
+// WriteEmptyTaggedFields writes an empty set of tagged fields, which ends each structure in
+// flexible request versions.
+func (e *Encoder) WriteEmptyTaggedFields() {
+	e.WriteUvarint(0)
+}
+
+// Bytes returns the encoded bytes.
+func (e *Encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Decoder reads the fields of a response. Errors are sticky; after the first one, all reads
+// return zero values and Err returns the error.
+type Decoder struct {
+	reader io.Reader
+	err    error
+}
+
+// NewDecoder returns a Decoder that reads from the argument reader.
+func NewDecoder(reader io.Reader) *Decoder {
+	return &Decoder{reader: reader}
+}
+
+// ReadBool reads a boolean stored as a single byte.
+func (d *Decoder) ReadBool() bool {
+	var value int8
+	d.read(&value)
+	return value != 0
+}
+
+// ReadInt8 reads an 8-bit integer.
+func (d *Decoder) ReadInt8() int8 {
+	var value int8
+	d.read(&value)
+	return value
+}
+
+// ReadInt16 reads a 16-bit integer.
+func (d *Decoder) ReadInt16() int16 {
+	var value int16
+	d.read(&value)
+	return value
+}
+
+// ReadInt32 reads a 32-bit integer. This is also used for array lengths.
+func (d *Decoder) ReadInt32() int32 {
+	var value int32
+	d.read(&value)
+	return value
+}
+
+// ReadInt64 reads a 64-bit integer.
+func (d *Decoder) ReadInt64() int64 {
+	var value int64
+	d.read(&value)
+	return value
+}
+
+// ReadString reads a string. Null strings are returned as empty ones.
+func (d *Decoder) ReadString() string {
+	length := d.ReadInt16()
+	if d.err != nil || length < 0 {
+		return ""
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(d.reader, value); err != nil {
+		d.err = err
+		return ""
+	}
+	return string(value)
+}
+
+// ReadUvarint reads an unsigned varint.
+func (d *Decoder) ReadUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	var value uint64
+	var shift uint
+	for {
+		var b byte
+		d.read(&b)
+		if d.err != nil {
+			return 0
+		}
+		if shift >= 64 {
+			d.err = errors.New("Varint overflows a 64-bit integer")
+			return 0
+		}
+		value |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return value
+		}
+		shift += 7
+	}
+}
+
+// ReadCompactString reads a compact string. Null strings are returned as empty ones.
+func (d *Decoder) ReadCompactString() string {
+	length := int(d.ReadUvarint()) - 1
+	if d.err != nil || length < 0 {
+		return ""
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(d.reader, value); err != nil {
+		d.err = err
+		return ""
+	}
+	return string(value)
+}
+
+// ReadCompactArrayLength reads the length of a compact array. Null arrays have a length
+// of -1.
+func (d *Decoder) ReadCompactArrayLength() int {
+	return int(d.ReadUvarint()) - 1
+}
+
+// SkipTaggedFields reads and discards the tagged fields at the end of a structure in a
+// flexible response version.
+func (d *Decoder) SkipTaggedFields() {
+	numFields := d.ReadUvarint()
+	for i := uint64(0); i < numFields && d.err == nil; i++ {
+		// Tag
+		d.ReadUvarint()
+		size := d.ReadUvarint()
+		if d.err != nil {
+			return
+		}
+		if _, err := io.CopyN(ioutil.Discard, d.reader, int64(size)); err != nil {
+			d.err = err
+		}
+	}
+}
+
+// Err returns the first error hit while decoding, if any.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+func (d *Decoder) read(value interface{}) {
+	if d.err != nil {
+		return
+	}
+	d.err = binary.Read(d.reader, binary.BigEndian, value)
+}
+
+// RoundTrip sends a request with the argument API key, version, and body to the broker at
+// brokerAddr, waits for the response, and returns a Decoder for the response body.
+func RoundTrip(
+	ctx context.Context,
+	brokerAddr string,
+	apiKey int16,
+	apiVersion int16,
+	body *Encoder,
+) (*Decoder, error) {
+	return roundTrip(ctx, brokerAddr, apiKey, apiVersion, body, false)
+}
+
+// FlexibleRoundTrip is like RoundTrip, but for flexible API versions, which have tagged
+// fields in their request and response headers. The body should use compact strings and
+// arrays.
+func FlexibleRoundTrip(
+	ctx context.Context,
+	brokerAddr string,
+	apiKey int16,
+	apiVersion int16,
+	body *Encoder,
+) (*Decoder, error) {
+	return roundTrip(ctx, brokerAddr, apiKey, apiVersion, body, true)
+}
+
+func roundTrip(
+	ctx context.Context,
+	brokerAddr string,
+	apiKey int16,
+	apiVersion int16,
+	body *Encoder,
+	flexible bool,
+) (*Decoder, error) {
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", brokerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing broker %s: %+v", brokerAddr, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(requestTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	correlationID := atomic.AddInt32(&correlationIDs, 1)
+
+	header := &Encoder{}
+	header.WriteInt16(apiKey)
+	header.WriteInt16(apiVersion)
+	header.WriteInt32(correlationID)
+	header.WriteString(clientID)
+	if flexible {
+		header.WriteEmptyTaggedFields()
+	}
+
+	request := &Encoder{}
+	request.WriteInt32(int32(len(header.Bytes()) + len(body.Bytes())))
+	request.buf.Write(header.Bytes())
+	request.buf.Write(body.Bytes())
+
+	if _, err := conn.Write(request.Bytes()); err != nil {
+		return nil, fmt.Errorf("Error sending request to broker %s: %+v", brokerAddr, err)
+	}
+
+	response, err := readResponse(bufio.NewReader(conn), correlationID)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading response from broker %s: %+v", brokerAddr, err)
+	}
+	if flexible {
+		response.SkipTaggedFields()
+		if err := response.Err(); err != nil {
+			return nil, fmt.Errorf("Error reading response from broker %s: %+v", brokerAddr, err)
+		}
+	}
+
+	return response, nil
+}
+
+func readResponse(reader io.Reader, expectedCorrelationID int32) (*Decoder, error) {
+	sizeDecoder := NewDecoder(reader)
+	size := sizeDecoder.ReadInt32()
+	if err := sizeDecoder.Err(); err != nil {
+		return nil, err
+	}
+
+	responseBytes := make([]byte, size)
+	if _, err := io.ReadFull(reader, responseBytes); err != nil {
+		return nil, err
+	}
+
+	response := NewDecoder(bytes.NewReader(responseBytes))
+	correlationID := response.ReadInt32()
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	if correlationID != expectedCorrelationID {
+		return nil, fmt.Errorf(
+			"Got correlation ID %d in response, expected %d",
+			correlationID,
+			expectedCorrelationID,
+		)
+	}
+
+	return response, nil
+}
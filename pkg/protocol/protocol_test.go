@@ -0,0 +1,144 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	encoder := &Encoder{}
+	encoder.WriteInt16(12)
+	encoder.WriteInt32(-3)
+	encoder.WriteInt64(1 << 40)
+	encoder.WriteString("test-string")
+	encoder.WriteString("")
+	encoder.WriteBool(true)
+	encoder.WriteBool(false)
+	encoder.WriteInt8(-2)
+	encoder.WriteNullString()
+
+	decoder := NewDecoder(bytes.NewReader(encoder.Bytes()))
+	assert.Equal(t, int16(12), decoder.ReadInt16())
+	assert.Equal(t, int32(-3), decoder.ReadInt32())
+	assert.Equal(t, int64(1<<40), decoder.ReadInt64())
+	assert.Equal(t, "test-string", decoder.ReadString())
+	assert.Equal(t, "", decoder.ReadString())
+	assert.True(t, decoder.ReadBool())
+	assert.False(t, decoder.ReadBool())
+	assert.Equal(t, int8(-2), decoder.ReadInt8())
+	assert.Equal(t, "", decoder.ReadString())
+	require.Nil(t, decoder.Err())
+
+	// Errors are sticky
+	assert.Equal(t, int32(0), decoder.ReadInt32())
+	assert.Equal(t, "", decoder.ReadString())
+	assert.NotNil(t, decoder.Err())
+}
+
+func TestEncodeDecodeCompact(t *testing.T) {
+	encoder := &Encoder{}
+	encoder.WriteUvarint(300)
+	encoder.WriteCompactString("test-string")
+	encoder.WriteCompactString("")
+	encoder.WriteCompactBytes([]byte{1, 2, 3})
+	encoder.WriteCompactArrayLength(2)
+	encoder.WriteCompactNullArray()
+	// Tagged fields with a single 3-byte field
+	encoder.WriteUvarint(1)
+	encoder.WriteUvarint(0)
+	encoder.WriteUvarint(3)
+	encoder.WriteInt8(1)
+	encoder.WriteInt16(2)
+	encoder.WriteEmptyTaggedFields()
+	encoder.WriteInt32(7)
+
+	decoder := NewDecoder(bytes.NewReader(encoder.Bytes()))
+	assert.Equal(t, uint64(300), decoder.ReadUvarint())
+	assert.Equal(t, "test-string", decoder.ReadCompactString())
+	assert.Equal(t, "", decoder.ReadCompactString())
+	assert.Equal(t, "\x01\x02\x03", decoder.ReadCompactString())
+	assert.Equal(t, 2, decoder.ReadCompactArrayLength())
+	assert.Equal(t, -1, decoder.ReadCompactArrayLength())
+	decoder.SkipTaggedFields()
+	decoder.SkipTaggedFields()
+	assert.Equal(t, int32(7), decoder.ReadInt32())
+	require.Nil(t, decoder.Err())
+}
+
+func TestRoundTrip(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+
+	type receivedRequest struct {
+		apiKey     int16
+		apiVersion int16
+		clientID   string
+		body       string
+		err        error
+	}
+	requestsChan := make(chan receivedRequest, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			requestsChan <- receivedRequest{err: err}
+			return
+		}
+		defer conn.Close()
+
+		decoder := NewDecoder(conn)
+		size := decoder.ReadInt32()
+		requestBytes := make([]byte, size)
+		if _, err := io.ReadFull(conn, requestBytes); err != nil {
+			requestsChan <- receivedRequest{err: err}
+			return
+		}
+
+		requestDecoder := NewDecoder(bytes.NewReader(requestBytes))
+		request := receivedRequest{
+			apiKey:     requestDecoder.ReadInt16(),
+			apiVersion: requestDecoder.ReadInt16(),
+		}
+		correlationID := requestDecoder.ReadInt32()
+		request.clientID = requestDecoder.ReadString()
+		request.body = requestDecoder.ReadString()
+		request.err = requestDecoder.Err()
+		requestsChan <- request
+
+		responseBody := &Encoder{}
+		responseBody.WriteInt32(correlationID)
+		responseBody.WriteString("response")
+
+		response := &Encoder{}
+		response.WriteInt32(int32(len(responseBody.Bytes())))
+		conn.Write(append(response.Bytes(), responseBody.Bytes()...))
+	}()
+
+	body := &Encoder{}
+	body.WriteString("request")
+
+	response, err := RoundTrip(
+		context.Background(),
+		listener.Addr().String(),
+		42,
+		1,
+		body,
+	)
+	require.Nil(t, err)
+	assert.Equal(t, "response", response.ReadString())
+	require.Nil(t, response.Err())
+
+	request := <-requestsChan
+	require.Nil(t, request.err)
+	assert.Equal(t, int16(42), request.apiKey)
+	assert.Equal(t, int16(1), request.apiVersion)
+	assert.Equal(t, "topicctl", request.clientID)
+	assert.Equal(t, "request", request.body)
+}
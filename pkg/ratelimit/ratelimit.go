@@ -0,0 +1,93 @@
+// Package ratelimit limits the rate of requests that topicctl sends to zookeeper and the
+// brokers so that bulk operations don't overwhelm the cluster.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket rate limiter. A nil Limiter doesn't limit anything.
+type Limiter struct {
+	mutex  sync.Mutex
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewLimiter returns a limiter that allows qps requests per second on average, with bursts
+// of up to burst requests. If burst is less than 1, it's set based on qps. If qps is zero or
+// negative, nil is returned so that requests aren't limited.
+func NewLimiter(qps float64, burst int) *Limiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Max(1.0, math.Ceil(qps)))
+	}
+
+	return &Limiter{
+		qps:    qps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// QPS returns the average number of requests per second allowed by the limiter, or 0 if it's
+// unlimited.
+func (l *Limiter) QPS() float64 {
+	if l == nil {
+		return 0
+	}
+	return l.qps
+}
+
+// Wait blocks until the next request is allowed or the argument context is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		// Give the token back since the request won't be made
+		l.mutex.Lock()
+		l.tokens = math.Min(l.burst, l.tokens+1.0)
+		l.mutex.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller needs to wait
+// before using it.
+func (l *Limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.qps)
+	}
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.qps * float64(time.Second))
+}
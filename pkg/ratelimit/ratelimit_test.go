@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(10.0, 2)
+	limiter.now = func() time.Time { return now }
+
+	// Burst is available immediately
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+
+	// Then each request needs to wait for a new token
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve())
+	assert.Equal(t, 200*time.Millisecond, limiter.reserve())
+
+	// Tokens refill over time, up to the burst
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 100*time.Millisecond, limiter.reserve())
+}
+
+func TestLimiterDefaultBurst(t *testing.T) {
+	assert.Equal(t, 5.0, NewLimiter(4.5, 0).burst)
+	assert.Equal(t, 1.0, NewLimiter(0.5, 0).burst)
+	assert.Equal(t, 3.0, NewLimiter(100.0, 3).burst)
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	var limiter *Limiter
+	assert.Nil(t, NewLimiter(0, 10))
+	assert.Nil(t, NewLimiter(-1, 10))
+	assert.Equal(t, 0.0, limiter.QPS())
+	require.NoError(t, limiter.Wait(context.Background()))
+}
+
+func TestLimiterWait(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(50.0, 1)
+	assert.Equal(t, 50.0, limiter.QPS())
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(ctx))
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(35*time.Millisecond))
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	limiter = NewLimiter(0.01, 1)
+	require.NoError(t, limiter.Wait(cancelCtx))
+	assert.Equal(t, context.Canceled, limiter.Wait(cancelCtx))
+}
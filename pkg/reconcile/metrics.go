@@ -0,0 +1,181 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CycleResult is the outcome of a single reconciliation cycle.
+type CycleResult string
+
+const (
+	// CycleSucceeded is used for cycles in which all topics were reconciled.
+	CycleSucceeded CycleResult = "success"
+
+	// CycleFailed is used for cycles in which one or more topics couldn't be reconciled.
+	CycleFailed CycleResult = "failure"
+
+	// CycleLimited is used for cycles that were skipped because they would have made more
+	// changes than allowed.
+	CycleLimited CycleResult = "limited"
+)
+
+// Metrics stores stats about reconciliation cycles. It implements http.Handler so that the
+// stats can be scraped by Prometheus.
+type Metrics struct {
+	sync.Mutex
+
+	cycles            map[CycleResult]int64
+	appliedChanges    int64
+	topicErrors       int64
+	pendingChanges    int64
+	managedTopics     int64
+	lastCycleDuration time.Duration
+	lastCycleTime     time.Time
+	lastSuccessTime   time.Time
+}
+
+var _ http.Handler = (*Metrics)(nil)
+
+// NewMetrics returns a new, empty Metrics instance.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		cycles: map[CycleResult]int64{},
+	}
+}
+
+// RecordCycle updates the metrics with the results of a reconciliation cycle.
+func (m *Metrics) RecordCycle(
+	result CycleResult,
+	summary CycleSummary,
+	endTime time.Time,
+	duration time.Duration,
+) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.cycles[result]++
+	m.appliedChanges += int64(summary.AppliedChanges)
+	m.topicErrors += int64(len(summary.FailedTopics))
+	m.pendingChanges = int64(summary.PlannedChanges - summary.AppliedChanges)
+	m.managedTopics = int64(summary.Topics)
+	m.lastCycleDuration = duration
+	m.lastCycleTime = endTime
+
+	if result == CycleSucceeded {
+		m.lastSuccessTime = endTime
+	}
+}
+
+// ServeHTTP writes out the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.Write(w)
+}
+
+// Write writes out the metrics in the Prometheus text format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.Lock()
+	defer m.Unlock()
+
+	results := []string{}
+	for result := range m.cycles {
+		results = append(results, string(result))
+	}
+	sort.Strings(results)
+
+	cycleValues := []string{}
+	for _, result := range results {
+		cycleValues = append(
+			cycleValues,
+			fmt.Sprintf("{result=%q} %d", result, m.cycles[CycleResult(result)]),
+		)
+	}
+
+	metrics := []struct {
+		name       string
+		metricType string
+		help       string
+		values     []string
+	}{
+		{
+			name:       "topicctl_reconcile_cycles_total",
+			metricType: "counter",
+			help:       "Number of reconciliation cycles, by result.",
+			values:     cycleValues,
+		},
+		{
+			name:       "topicctl_reconcile_applied_changes_total",
+			metricType: "counter",
+			help:       "Number of topic changes applied.",
+			values:     []string{fmt.Sprintf(" %d", m.appliedChanges)},
+		},
+		{
+			name:       "topicctl_reconcile_topic_errors_total",
+			metricType: "counter",
+			help:       "Number of errors reconciling individual topics.",
+			values:     []string{fmt.Sprintf(" %d", m.topicErrors)},
+		},
+		{
+			name:       "topicctl_reconcile_pending_changes",
+			metricType: "gauge",
+			help:       "Number of changes found but not applied in the last cycle.",
+			values:     []string{fmt.Sprintf(" %d", m.pendingChanges)},
+		},
+		{
+			name:       "topicctl_reconcile_managed_topics",
+			metricType: "gauge",
+			help:       "Number of topic configs processed in the last cycle.",
+			values:     []string{fmt.Sprintf(" %d", m.managedTopics)},
+		},
+		{
+			name:       "topicctl_reconcile_last_cycle_duration_seconds",
+			metricType: "gauge",
+			help:       "Duration of the last cycle.",
+			values:     []string{fmt.Sprintf(" %g", m.lastCycleDuration.Seconds())},
+		},
+		{
+			name:       "topicctl_reconcile_last_cycle_timestamp_seconds",
+			metricType: "gauge",
+			help:       "Unix time at which the last cycle finished.",
+			values:     []string{fmt.Sprintf(" %d", unixOrZero(m.lastCycleTime))},
+		},
+		{
+			name:       "topicctl_reconcile_last_success_timestamp_seconds",
+			metricType: "gauge",
+			help:       "Unix time at which the last successful cycle finished.",
+			values:     []string{fmt.Sprintf(" %d", unixOrZero(m.lastSuccessTime))},
+		},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(
+			w,
+			"# HELP %s %s\n# TYPE %s %s\n",
+			metric.name,
+			metric.help,
+			metric.name,
+			metric.metricType,
+		); err != nil {
+			return err
+		}
+		for _, value := range metric.values {
+			if _, err := fmt.Fprintf(w, "%s%s\n", metric.name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
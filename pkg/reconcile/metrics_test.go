@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+
+	endTime := time.Unix(1600000000, 0)
+
+	metrics.RecordCycle(
+		CycleSucceeded,
+		CycleSummary{
+			Topics:         3,
+			PlannedChanges: 2,
+			AppliedChanges: 2,
+		},
+		endTime,
+		1500*time.Millisecond,
+	)
+	metrics.RecordCycle(
+		CycleLimited,
+		CycleSummary{
+			Topics:         3,
+			PlannedChanges: 20,
+		},
+		endTime.Add(time.Minute),
+		time.Second,
+	)
+	metrics.RecordCycle(
+		CycleFailed,
+		CycleSummary{
+			Topics:         3,
+			PlannedChanges: 1,
+			FailedTopics:   []string{"topic-a"},
+		},
+		endTime.Add(2*time.Minute),
+		2*time.Second,
+	)
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, metrics.Write(buf))
+
+	lines := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			`topicctl_reconcile_cycles_total{result="failure"} 1`,
+			`topicctl_reconcile_cycles_total{result="limited"} 1`,
+			`topicctl_reconcile_cycles_total{result="success"} 1`,
+			"topicctl_reconcile_applied_changes_total 2",
+			"topicctl_reconcile_topic_errors_total 1",
+			"topicctl_reconcile_pending_changes 1",
+			"topicctl_reconcile_managed_topics 3",
+			"topicctl_reconcile_last_cycle_duration_seconds 2",
+			"topicctl_reconcile_last_cycle_timestamp_seconds 1600000120",
+			"topicctl_reconcile_last_success_timestamp_seconds 1600000000",
+		},
+		lines,
+	)
+	assert.Contains(
+		t,
+		buf.String(),
+		"# TYPE topicctl_reconcile_cycles_total counter\n",
+	)
+}
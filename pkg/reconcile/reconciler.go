@@ -0,0 +1,422 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/apply"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/gitops"
+	"github.com/segmentio/topicctl/pkg/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// ReconcilerConfig contains the configuration for a Reconciler.
+type ReconcilerConfig struct {
+	// ConfigPaths are paths or globs for the topic configs to reconcile. If GitRepo is set,
+	// these are relative to GitPath in the repo.
+	ConfigPaths []string
+
+	// ClusterConfigPath is the path to the cluster config. If not set, the cluster.yaml in
+	// the parent directory of each topic config is used.
+	ClusterConfigPath string
+
+	// BackupDir, if set, is the directory that the state of each topic is backed up to
+	// before it's changed.
+	BackupDir string
+
+	// DeleteRetired, if set, allows retiring topics that are past their deletion dates to be
+	// deleted. Deletions count towards MaxChangesPerCycle like any other change.
+	DeleteRetired bool
+
+	// GitRepo, GitRef, and GitPath, if set, configure the git repo that configs are
+	// read from. The repo is checked out again at the start of each cycle.
+	GitRepo string
+	GitRef  string
+	GitPath string
+
+	// Interval is the base amount of time between cycles in watch mode.
+	Interval time.Duration
+
+	// Jitter is the maximum fraction of the interval that each wait is randomly shifted
+	// by, e.g. 0.1 for waits between 0.9 and 1.1 times the interval.
+	Jitter float64
+
+	// MaxChangesPerCycle is the maximum number of changes that a cycle can make; cycles
+	// that would make more changes are skipped entirely. Zero means no limit.
+	MaxChangesPerCycle int
+
+	// SleepLoopTime is the amount of time to wait between partition checks.
+	SleepLoopTime time.Duration
+}
+
+// CycleSummary summarizes a single reconciliation cycle.
+type CycleSummary struct {
+	SHA            string
+	Topics         int
+	PlannedChanges int
+	AppliedChanges int
+	FailedTopics   []string
+}
+
+// Reconciler periodically converges the topics in a cluster to match their configs, without
+// any user interaction.
+type Reconciler struct {
+	config  ReconcilerConfig
+	metrics *Metrics
+	random  *rand.Rand
+}
+
+// reconcileTarget stores the configs needed to reconcile a single topic.
+type reconcileTarget struct {
+	topicConfigPath   string
+	clusterConfigPath string
+	topicConfig       config.TopicConfig
+	clusterConfig     config.ClusterConfig
+	topicSetMembers   []string
+}
+
+// NewReconciler returns a new Reconciler instance.
+func NewReconciler(reconcilerConfig ReconcilerConfig) *Reconciler {
+	return &Reconciler{
+		config:  reconcilerConfig,
+		metrics: NewMetrics(),
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Metrics returns the metrics for this reconciler.
+func (r *Reconciler) Metrics() *Metrics {
+	return r.metrics
+}
+
+// Watch runs reconciliation cycles, separated by jittered intervals, until the argument
+// context is cancelled. Errors in individual cycles are logged but don't stop the loop.
+func (r *Reconciler) Watch(ctx context.Context) error {
+	for {
+		if _, err := r.Reconcile(ctx); err != nil {
+			log.Warnf("Reconciliation cycle failed: %+v", err)
+		}
+
+		wait := jitteredInterval(r.config.Interval, r.config.Jitter, r.random)
+		log.Infof("Waiting %s until the next reconciliation cycle", wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Reconcile runs a single reconciliation cycle. The changes needed for each topic are found
+// with a dry-run apply first; if the total is within the configured limit, then the topics
+// with changes are applied. Failures for individual topics don't stop the others from being
+// reconciled, but are included in the returned error.
+func (r *Reconciler) Reconcile(ctx context.Context) (CycleSummary, error) {
+	startTime := time.Now()
+
+	// Group the logs for each cycle, including the ones from its applies
+	restoreLogs := logging.WithCorrelationID(logging.NewCorrelationID())
+	defer restoreLogs()
+
+	summary, err := r.reconcile(ctx)
+
+	var result CycleResult
+	switch {
+	case err == nil:
+		result = CycleSucceeded
+	case summary.limited():
+		result = CycleLimited
+	default:
+		result = CycleFailed
+	}
+
+	endTime := time.Now()
+	r.metrics.RecordCycle(result, summary.CycleSummary, endTime, endTime.Sub(startTime))
+
+	log.Infof(
+		"Reconciliation cycle finished with result %s (topics: %d, planned changes: %d, applied changes: %d, failed topics: %+v)",
+		result,
+		summary.Topics,
+		summary.PlannedChanges,
+		summary.AppliedChanges,
+		summary.FailedTopics,
+	)
+
+	return summary.CycleSummary, err
+}
+
+type cycleState struct {
+	CycleSummary
+	maxChanges int
+}
+
+func (s cycleState) limited() bool {
+	return s.maxChanges > 0 && s.PlannedChanges > s.maxChanges
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) (cycleState, error) {
+	state := cycleState{
+		CycleSummary: CycleSummary{
+			FailedTopics: []string{},
+		},
+		maxChanges: r.config.MaxChangesPerCycle,
+	}
+
+	baseDir := ""
+
+	if r.config.GitRepo != "" {
+		tempDir, err := ioutil.TempDir("", "topicctl-reconcile")
+		if err != nil {
+			return state, err
+		}
+		defer os.RemoveAll(tempDir)
+
+		repoDir := filepath.Join(tempDir, "repo")
+		state.SHA, err = gitops.Checkout(ctx, r.config.GitRepo, r.config.GitRef, repoDir)
+		if err != nil {
+			return state, err
+		}
+		baseDir = filepath.Join(repoDir, r.config.GitPath)
+	}
+
+	targets, err := r.loadTargets(baseDir)
+	if err != nil {
+		return state, err
+	}
+	state.Topics = len(targets)
+
+	// Keep a cache of the admin clients with the cluster config path as the key
+	adminClients := map[string]*admin.Client{}
+	defer func() {
+		for _, adminClient := range adminClients {
+			adminClient.Close()
+		}
+	}()
+
+	log.Infof("Planning changes for %d topic(s)", len(targets))
+	changedTargets := []reconcileTarget{}
+
+	for _, target := range targets {
+		changes, err := r.applyTarget(ctx, target, adminClients, true)
+		if err != nil {
+			log.Warnf("Error planning changes for topic %s: %+v", target.topicConfig.Meta.Name, err)
+			state.FailedTopics = append(state.FailedTopics, target.topicConfig.Meta.Name)
+			continue
+		}
+		if changes.NumChanges() > 0 {
+			state.PlannedChanges += changes.NumChanges()
+			changedTargets = append(changedTargets, target)
+		}
+	}
+
+	if state.limited() {
+		return state, fmt.Errorf(
+			"Cycle would make %d changes, which exceeds the limit of %d; not applying any of them",
+			state.PlannedChanges,
+			state.maxChanges,
+		)
+	}
+
+	for _, target := range changedTargets {
+		log.Infof("Applying changes to topic %s", target.topicConfig.Meta.Name)
+		changes, err := r.applyTarget(ctx, target, adminClients, false)
+		state.AppliedChanges += changes.NumChanges()
+
+		if err != nil {
+			log.Warnf("Error applying topic %s: %+v", target.topicConfig.Meta.Name, err)
+			state.FailedTopics = append(state.FailedTopics, target.topicConfig.Meta.Name)
+		}
+	}
+
+	if len(state.FailedTopics) > 0 {
+		return state, fmt.Errorf("Could not reconcile topics %+v", state.FailedTopics)
+	}
+
+	if r.config.GitRepo != "" {
+		for _, adminClient := range adminClients {
+			appliedRef := admin.NewAppliedRef(
+				r.config.GitRepo,
+				r.config.GitRef,
+				state.SHA,
+				r.config.GitPath,
+			)
+			if err := adminClient.SetAppliedRef(ctx, appliedRef); err != nil {
+				return state, err
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// loadTargets loads all of the topic configs that match the config paths, along with their
+// cluster configs. Non-topic configs are skipped.
+func (r *Reconciler) loadTargets(baseDir string) ([]reconcileTarget, error) {
+	targets := []reconcileTarget{}
+
+	for _, configPath := range r.config.ConfigPaths {
+		if baseDir != "" {
+			configPath = filepath.Join(baseDir, configPath)
+		}
+
+		matches, err := filepath.Glob(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			kind, err := config.LoadKindFile(match)
+			if err != nil {
+				return nil, err
+			}
+			if kind == config.ConnectorKind {
+				log.Infof("Skipping connector config %s", match)
+				continue
+			}
+
+			target, err := r.loadTarget(match)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, target)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("No topic configs match the provided paths (%+v)", r.config.ConfigPaths)
+	}
+
+	return targets, nil
+}
+
+func (r *Reconciler) loadTarget(topicConfigPath string) (reconcileTarget, error) {
+	target := reconcileTarget{
+		topicConfigPath:   topicConfigPath,
+		clusterConfigPath: r.config.ClusterConfigPath,
+	}
+
+	if target.clusterConfigPath == "" {
+		var err error
+		target.clusterConfigPath, err = filepath.Abs(
+			filepath.Join(filepath.Dir(topicConfigPath), "..", "cluster.yaml"),
+		)
+		if err != nil {
+			return target, err
+		}
+	}
+
+	var err error
+	target.clusterConfig, err = config.LoadClusterFile(target.clusterConfigPath)
+	if err != nil {
+		return target, err
+	}
+
+	target.topicConfig, err = config.LoadTopicFile(topicConfigPath)
+	if err != nil {
+		return target, err
+	}
+	target.topicConfig.InheritDefaults(target.clusterConfig.Spec.TopicDefaults)
+	err = target.topicConfig.ResolveRetentionTier(target.clusterConfig.Spec.RetentionTiers)
+	if err != nil {
+		return target, err
+	}
+	target.topicConfig.SetDefaults()
+
+	if target.topicConfig.Spec.PlacementConfig.Strategy ==
+		config.PlacementStrategyBalancedTopicSet {
+		members, err := config.LoadTopicSetMembers(
+			filepath.Dir(topicConfigPath),
+			target.topicConfig,
+			target.clusterConfig.Spec.TopicDefaults,
+		)
+		if err != nil {
+			return target, err
+		}
+		if err := config.CheckTopicSetConsistency(target.topicConfig, members); err != nil {
+			return target, err
+		}
+
+		for _, member := range members {
+			target.topicSetMembers = append(target.topicSetMembers, member.Meta.Name)
+		}
+	}
+
+	return target, nil
+}
+
+// applyTarget runs an apply for the argument target and returns the changes that it made
+// or, in dry-run mode, would have made.
+func (r *Reconciler) applyTarget(
+	ctx context.Context,
+	target reconcileTarget,
+	adminClients map[string]*admin.Client,
+	dryRun bool,
+) (*apply.TopicChanges, error) {
+	report := &apply.ChangeReport{
+		DryRun: dryRun,
+		Topics: []*apply.TopicChanges{},
+	}
+	changes := func() *apply.TopicChanges {
+		if len(report.Topics) == 0 {
+			return &apply.TopicChanges{}
+		}
+		return report.Topics[0]
+	}
+
+	adminClient, ok := adminClients[target.clusterConfigPath]
+	if !ok {
+		var err error
+		adminClient, err = target.clusterConfig.NewAdminClient(ctx, nil, false)
+		if err != nil {
+			return changes(), err
+		}
+		adminClients[target.clusterConfigPath] = adminClient
+	}
+
+	locker, err := target.clusterConfig.NewLocker(adminClient, nil)
+	if err != nil {
+		return changes(), err
+	}
+
+	applier, err := apply.NewTopicApplier(
+		ctx,
+		adminClient,
+		apply.TopicApplierConfig{
+			BackupDir:       r.config.BackupDir,
+			ChangeReport:    report,
+			ClusterConfig:   target.clusterConfig,
+			DeleteRetired:   r.config.DeleteRetired,
+			DryRun:          dryRun,
+			Locker:          locker,
+			SkipConfirm:     true,
+			SleepLoopTime:   r.config.SleepLoopTime,
+			TopicConfig:     target.topicConfig,
+			TopicSetMembers: target.topicSetMembers,
+		},
+	)
+	if err != nil {
+		return changes(), err
+	}
+
+	err = applier.Apply(ctx)
+	return changes(), err
+}
+
+// jitteredInterval returns the argument interval shifted by a random amount of up to
+// jitter times the interval in either direction.
+func jitteredInterval(interval time.Duration, jitter float64, random *rand.Rand) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+
+	shift := (random.Float64()*2 - 1) * jitter * float64(interval)
+	return interval + time.Duration(shift)
+}
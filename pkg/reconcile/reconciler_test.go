@@ -0,0 +1,59 @@
+package reconcile
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTargets(t *testing.T) {
+	reconciler := NewReconciler(
+		ReconcilerConfig{
+			ConfigPaths: []string{"topics/topic-default.yaml", "topics/topic-static.yaml"},
+		},
+	)
+
+	targets, err := reconciler.loadTargets("../../examples/local-cluster")
+	require.Nil(t, err)
+	require.Equal(t, 2, len(targets))
+	assert.Equal(t, "topic-default", targets[0].topicConfig.Meta.Name)
+	assert.Equal(t, "topic-static", targets[1].topicConfig.Meta.Name)
+	assert.Equal(t, "local-cluster", targets[0].clusterConfig.Meta.Name)
+
+	reconciler = NewReconciler(
+		ReconcilerConfig{
+			ConfigPaths: []string{"topics/non-existent*.yaml"},
+		},
+	)
+	_, err = reconciler.loadTargets("../../examples/local-cluster")
+	assert.NotNil(t, err)
+}
+
+func TestJitteredInterval(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	assert.Equal(t, time.Minute, jitteredInterval(time.Minute, 0, random))
+
+	for i := 0; i < 100; i++ {
+		interval := jitteredInterval(time.Minute, 0.1, random)
+		assert.True(t, interval >= 54*time.Second, interval)
+		assert.True(t, interval <= 66*time.Second, interval)
+	}
+}
+
+func TestCycleLimited(t *testing.T) {
+	state := cycleState{
+		CycleSummary: CycleSummary{PlannedChanges: 5},
+		maxChanges:   5,
+	}
+	assert.False(t, state.limited())
+
+	state.PlannedChanges = 6
+	assert.True(t, state.limited())
+
+	state.maxChanges = 0
+	assert.False(t, state.limited())
+}
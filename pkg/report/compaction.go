@@ -0,0 +1,283 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/metrics"
+)
+
+const (
+	// Kafka's defaults for the topic settings that affect compaction. Brokers can override
+	// these, but that isn't visible from the topic configs in zookeeper.
+	defaultMinCleanableDirtyRatio = 0.5
+	defaultDeleteRetentionMs      = 86400000
+	defaultSegmentMs              = 604800000
+)
+
+// CompactionOptions contains the thresholds that compacted topics are checked against.
+type CompactionOptions struct {
+	// MaxDirtyRatio is the dirty ratio above which a partition is considered to be lagging
+	// behind the log cleaner. Topics with a higher min.cleanable.dirty.ratio are checked
+	// against that instead.
+	MaxDirtyRatio float64
+
+	// MinDeleteRetention is the smallest delete.retention.ms that's considered safe for
+	// consumers that fall behind or are restarted.
+	MinDeleteRetention time.Duration
+
+	// MaxSegmentAge is the largest segment.ms that's considered safe. Records in the active
+	// segment aren't compacted, so low-throughput topics with long segments can go for a
+	// long time without being compacted.
+	MaxSegmentAge time.Duration
+}
+
+// CompactionHealth contains the compaction settings and state of a single compacted topic,
+// along with any problems found in them.
+type CompactionHealth struct {
+	Topic         string `json:"topic"`
+	CleanupPolicy string `json:"cleanupPolicy"`
+	Partitions    int    `json:"partitions"`
+
+	// SizeBytes is the total size of the topic across all of its replicas.
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// MaxDirtyRatio is the highest dirty ratio across the topic's partitions, or -1 if no
+	// dirty ratios are available for the topic.
+	MaxDirtyRatio          float64 `json:"maxDirtyRatio"`
+	MinCleanableDirtyRatio float64 `json:"minCleanableDirtyRatio"`
+	DeleteRetentionMs      int64   `json:"deleteRetentionMs"`
+	SegmentMs              int64   `json:"segmentMs"`
+
+	Problems []string `json:"problems"`
+}
+
+// Risky returns whether any problems were found for the topic.
+func (c CompactionHealth) Risky() bool {
+	return len(c.Problems) > 0
+}
+
+// CompactionHealths checks each of the compacted topics among the argument topics for
+// compaction problems. Dirty ratios are taken from the argument fetcher, which can be nil,
+// and sizes from the argument log dirs. The results are sorted with the risky topics first,
+// and then by topic name.
+func CompactionHealths(
+	ctx context.Context,
+	topics []admin.TopicInfo,
+	logDirs []admin.LogDirInfo,
+	fetcher metrics.DirtyRatioFetcher,
+	options CompactionOptions,
+) ([]CompactionHealth, error) {
+	sizes := map[string]int64{}
+	for _, logDir := range logDirs {
+		for _, replica := range logDir.Replicas {
+			if !replica.IsFuture {
+				sizes[replica.Topic] += replica.Size
+			}
+		}
+	}
+
+	healths := []CompactionHealth{}
+
+	for _, topic := range topics {
+		cleanupPolicy := topic.Config["cleanup.policy"]
+		if !strings.Contains(cleanupPolicy, "compact") {
+			continue
+		}
+
+		health := CompactionHealth{
+			Topic:         topic.Name,
+			CleanupPolicy: cleanupPolicy,
+			Partitions:    len(topic.Partitions),
+			SizeBytes:     sizes[topic.Name],
+			MaxDirtyRatio: -1,
+			Problems:      []string{},
+		}
+
+		var err error
+		health.MinCleanableDirtyRatio, err = floatSetting(
+			topic,
+			"min.cleanable.dirty.ratio",
+			defaultMinCleanableDirtyRatio,
+		)
+		if err != nil {
+			return nil, err
+		}
+		health.DeleteRetentionMs, err = intSetting(
+			topic,
+			"delete.retention.ms",
+			defaultDeleteRetentionMs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		health.SegmentMs, err = intSetting(topic, "segment.ms", defaultSegmentMs)
+		if err != nil {
+			return nil, err
+		}
+
+		if fetcher != nil {
+			ratios, err := fetcher.FetchDirtyRatios(ctx, topic.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			maxPartition := -1
+			for partition, ratio := range ratios {
+				if ratio > health.MaxDirtyRatio ||
+					(ratio == health.MaxDirtyRatio && partition < maxPartition) {
+					health.MaxDirtyRatio = ratio
+					maxPartition = partition
+				}
+			}
+
+			threshold := options.MaxDirtyRatio
+			if health.MinCleanableDirtyRatio > threshold {
+				threshold = health.MinCleanableDirtyRatio
+			}
+			if maxPartition >= 0 && health.MaxDirtyRatio > threshold {
+				health.Problems = append(
+					health.Problems,
+					fmt.Sprintf(
+						"cleaner lag: dirty ratio of partition %d is %.2f, above %.2f",
+						maxPartition,
+						health.MaxDirtyRatio,
+						threshold,
+					),
+				)
+			}
+		}
+
+		minDeleteRetentionMs := options.MinDeleteRetention.Milliseconds()
+		if health.DeleteRetentionMs < minDeleteRetentionMs {
+			health.Problems = append(
+				health.Problems,
+				fmt.Sprintf(
+					"delete.retention.ms of %s is below %s; consumers that fall behind can miss tombstones",
+					time.Duration(health.DeleteRetentionMs)*time.Millisecond,
+					options.MinDeleteRetention,
+				),
+			)
+		}
+		if retentionMsStr, ok := topic.Config["retention.ms"]; ok &&
+			strings.Contains(cleanupPolicy, "delete") {
+			retentionMs, err := strconv.ParseInt(retentionMsStr, 10, 64)
+			if err == nil && retentionMs >= 0 && retentionMs < health.DeleteRetentionMs {
+				health.Problems = append(
+					health.Problems,
+					fmt.Sprintf(
+						"retention.ms of %s is below delete.retention.ms, so tombstones are deleted early",
+						time.Duration(retentionMs)*time.Millisecond,
+					),
+				)
+			}
+		}
+
+		maxSegmentMs := options.MaxSegmentAge.Milliseconds()
+		if maxSegmentMs > 0 && health.SegmentMs > maxSegmentMs {
+			health.Problems = append(
+				health.Problems,
+				fmt.Sprintf(
+					"segment.ms of %s is above %s; records in the active segment aren't compacted",
+					time.Duration(health.SegmentMs)*time.Millisecond,
+					options.MaxSegmentAge,
+				),
+			)
+		}
+
+		healths = append(healths, health)
+	}
+
+	sort.Slice(healths, func(a, b int) bool {
+		if healths[a].Risky() != healths[b].Risky() {
+			return healths[a].Risky()
+		}
+		return healths[a].Topic < healths[b].Topic
+	})
+
+	return healths, nil
+}
+
+// EstimateDirtyRatios estimates the dirty ratio of each partition from two samples of the
+// cluster's log dirs, taken some time apart, for cases where the log cleaner metrics aren't
+// available. The estimate is the fraction of each partition's final size that was added
+// between the samples; if the cleaner ran in the meantime, the partition shrinks and the
+// estimate is zero.
+func EstimateDirtyRatios(before []admin.LogDirInfo, after []admin.LogDirInfo) metrics.DirtyRatios {
+	beforeSizes := partitionSizes(before)
+	afterSizes := partitionSizes(after)
+
+	ratios := metrics.DirtyRatios{}
+
+	for topic, partitions := range afterSizes {
+		for partition, afterSize := range partitions {
+			beforeSize, ok := beforeSizes[topic][partition]
+			if !ok || afterSize <= 0 {
+				continue
+			}
+
+			ratio := 0.0
+			if afterSize > beforeSize {
+				ratio = float64(afterSize-beforeSize) / float64(afterSize)
+			}
+
+			if _, ok := ratios[topic]; !ok {
+				ratios[topic] = map[int]float64{}
+			}
+			ratios[topic][partition] = ratio
+		}
+	}
+
+	return ratios
+}
+
+// partitionSizes returns the size of the largest replica of each partition in the argument
+// log dirs.
+func partitionSizes(logDirs []admin.LogDirInfo) map[string]map[int]int64 {
+	sizes := map[string]map[int]int64{}
+
+	for _, logDir := range logDirs {
+		for _, replica := range logDir.Replicas {
+			if replica.IsFuture {
+				continue
+			}
+			if _, ok := sizes[replica.Topic]; !ok {
+				sizes[replica.Topic] = map[int]int64{}
+			}
+			if replica.Size > sizes[replica.Topic][replica.Partition] {
+				sizes[replica.Topic][replica.Partition] = replica.Size
+			}
+		}
+	}
+
+	return sizes
+}
+
+func floatSetting(topic admin.TopicInfo, key string, defaultValue float64) (float64, error) {
+	valueStr, ok := topic.Config[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s value for topic %s: %s", key, topic.Name, valueStr)
+	}
+	return value, nil
+}
+
+func intSetting(topic admin.TopicInfo, key string, defaultValue int64) (int64, error) {
+	valueStr, ok := topic.Config[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %s value for topic %s: %s", key, topic.Name, valueStr)
+	}
+	return value, nil
+}
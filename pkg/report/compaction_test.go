@@ -0,0 +1,140 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactionHealths(t *testing.T) {
+	ctx := context.Background()
+
+	topics := []admin.TopicInfo{
+		{
+			Name: "healthy",
+			Config: map[string]string{
+				"cleanup.policy": "compact",
+			},
+			Partitions: []admin.PartitionInfo{{ID: 0}, {ID: 1}},
+		},
+		{
+			Name: "lagging",
+			Config: map[string]string{
+				"cleanup.policy": "compact",
+			},
+			Partitions: []admin.PartitionInfo{{ID: 0}, {ID: 1}},
+		},
+		{
+			Name: "misconfigured",
+			Config: map[string]string{
+				"cleanup.policy":      "compact,delete",
+				"delete.retention.ms": "60000",
+				"retention.ms":        "30000",
+				"segment.ms":          "2592000000",
+			},
+			Partitions: []admin.PartitionInfo{{ID: 0}},
+		},
+		{
+			Name: "not-compacted",
+			Config: map[string]string{
+				"delete.retention.ms": "0",
+			},
+			Partitions: []admin.PartitionInfo{{ID: 0}},
+		},
+	}
+	logDirs := []admin.LogDirInfo{
+		{
+			Broker: 1,
+			Replicas: []admin.LogDirReplica{
+				{Topic: "healthy", Partition: 0, Size: 100},
+				{Topic: "healthy", Partition: 1, Size: 100},
+				{Topic: "lagging", Partition: 0, Size: 300},
+			},
+		},
+	}
+	dirtyRatios := metrics.DirtyRatios{
+		"healthy": {0: 0.1, 1: 0.4},
+		"lagging": {0: 0.3, 1: 0.95},
+	}
+
+	healths, err := CompactionHealths(
+		ctx,
+		topics,
+		logDirs,
+		dirtyRatios,
+		CompactionOptions{
+			MaxDirtyRatio:      0.8,
+			MinDeleteRetention: time.Hour,
+			MaxSegmentAge:      7 * 24 * time.Hour,
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(healths))
+
+	assert.Equal(t, "lagging", healths[0].Topic)
+	assert.Equal(t, 0.95, healths[0].MaxDirtyRatio)
+	assert.Equal(t, 1, len(healths[0].Problems))
+	assert.Contains(t, healths[0].Problems[0], "partition 1")
+
+	assert.Equal(t, "misconfigured", healths[1].Topic)
+	assert.Equal(t, -1.0, healths[1].MaxDirtyRatio)
+	assert.Equal(t, int64(60000), healths[1].DeleteRetentionMs)
+	assert.Equal(t, 3, len(healths[1].Problems))
+
+	assert.Equal(t, "healthy", healths[2].Topic)
+	assert.Equal(t, int64(200), healths[2].SizeBytes)
+	assert.Equal(t, int64(defaultDeleteRetentionMs), healths[2].DeleteRetentionMs)
+	assert.False(t, healths[2].Risky())
+
+	_, err = CompactionHealths(
+		ctx,
+		[]admin.TopicInfo{
+			{
+				Name: "bad",
+				Config: map[string]string{
+					"cleanup.policy": "compact",
+					"segment.ms":     "a while",
+				},
+			},
+		},
+		nil,
+		nil,
+		CompactionOptions{},
+	)
+	assert.Error(t, err)
+}
+
+func TestEstimateDirtyRatios(t *testing.T) {
+	before := []admin.LogDirInfo{
+		{
+			Broker: 1,
+			Replicas: []admin.LogDirReplica{
+				{Topic: "topic-a", Partition: 0, Size: 100},
+				{Topic: "topic-a", Partition: 1, Size: 400},
+			},
+		},
+	}
+	after := []admin.LogDirInfo{
+		{
+			Broker: 1,
+			Replicas: []admin.LogDirReplica{
+				{Topic: "topic-a", Partition: 0, Size: 400},
+				{Topic: "topic-a", Partition: 1, Size: 200},
+				{Topic: "topic-b", Partition: 0, Size: 200},
+			},
+		},
+	}
+
+	assert.Equal(
+		t,
+		metrics.DirtyRatios{
+			"topic-a": {0: 0.75, 1: 0.0},
+		},
+		EstimateDirtyRatios(before, after),
+	)
+}
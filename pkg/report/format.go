@@ -0,0 +1,222 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/segmentio/topicctl/pkg/util"
+)
+
+// FormatTeamUsages generates a pretty table that summarizes the argument team usages.
+func FormatTeamUsages(teamUsages []TeamUsage) string {
+	buf := &bytes.Buffer{}
+
+	table := newTable(
+		buf,
+		[]string{
+			"Team",
+			"Topics",
+			"Partitions",
+			"Replicas",
+			"Size",
+			"Storage\nShare",
+			"Bytes In",
+		},
+	)
+
+	for _, teamUsage := range teamUsages {
+		table.Append(
+			[]string{
+				teamUsage.Team,
+				fmt.Sprintf("%d", teamUsage.Topics),
+				fmt.Sprintf("%d", teamUsage.Partitions),
+				fmt.Sprintf("%d", teamUsage.Replicas),
+				util.PrettyBytes(teamUsage.SizeBytes),
+				fmt.Sprintf("%.1f%%", teamUsage.StorageShare*100.0),
+				prettyRate(teamUsage.BytesInPerSec),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// FormatTopicUsages generates a pretty table that summarizes the argument topic usages.
+func FormatTopicUsages(topicUsages []TopicUsage) string {
+	buf := &bytes.Buffer{}
+
+	table := newTable(
+		buf,
+		[]string{
+			"Topic",
+			"Team",
+			"Owner",
+			"Partitions",
+			"Replicas",
+			"Size",
+			"Bytes In",
+		},
+	)
+
+	for _, topicUsage := range topicUsages {
+		table.Append(
+			[]string{
+				topicUsage.Topic,
+				topicUsage.Team,
+				topicUsage.Owner,
+				fmt.Sprintf("%d", topicUsage.Partitions),
+				fmt.Sprintf("%d", topicUsage.Replicas),
+				util.PrettyBytes(topicUsage.SizeBytes),
+				prettyRate(topicUsage.BytesInPerSec),
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// WriteTeamUsagesCSV writes the argument team usages as CSV rows, with a header row first.
+// Sizes and rates are in raw bytes so that the output can be processed by other tools.
+func WriteTeamUsagesCSV(writer io.Writer, teamUsages []TeamUsage) error {
+	rows := [][]string{
+		{
+			"team",
+			"topics",
+			"partitions",
+			"replicas",
+			"size_bytes",
+			"storage_share",
+			"bytes_in_per_sec",
+		},
+	}
+
+	for _, teamUsage := range teamUsages {
+		rows = append(
+			rows,
+			[]string{
+				teamUsage.Team,
+				strconv.Itoa(teamUsage.Topics),
+				strconv.Itoa(teamUsage.Partitions),
+				strconv.Itoa(teamUsage.Replicas),
+				strconv.FormatInt(teamUsage.SizeBytes, 10),
+				strconv.FormatFloat(teamUsage.StorageShare, 'f', 4, 64),
+				strconv.FormatFloat(teamUsage.BytesInPerSec, 'f', 2, 64),
+			},
+		)
+	}
+
+	return csv.NewWriter(writer).WriteAll(rows)
+}
+
+// WriteTopicUsagesCSV writes the argument topic usages as CSV rows, with a header row first.
+func WriteTopicUsagesCSV(writer io.Writer, topicUsages []TopicUsage) error {
+	rows := [][]string{
+		{
+			"topic",
+			"team",
+			"owner",
+			"partitions",
+			"replicas",
+			"size_bytes",
+			"bytes_in_per_sec",
+		},
+	}
+
+	for _, topicUsage := range topicUsages {
+		rows = append(
+			rows,
+			[]string{
+				topicUsage.Topic,
+				topicUsage.Team,
+				topicUsage.Owner,
+				strconv.Itoa(topicUsage.Partitions),
+				strconv.Itoa(topicUsage.Replicas),
+				strconv.FormatInt(topicUsage.SizeBytes, 10),
+				strconv.FormatFloat(topicUsage.BytesInPerSec, 'f', 2, 64),
+			},
+		)
+	}
+
+	return csv.NewWriter(writer).WriteAll(rows)
+}
+
+// FormatCompactionHealths generates a pretty table that summarizes the argument compacted
+// topic healths.
+func FormatCompactionHealths(healths []CompactionHealth) string {
+	buf := &bytes.Buffer{}
+
+	table := newTable(
+		buf,
+		[]string{
+			"Topic",
+			"Cleanup\nPolicy",
+			"Size",
+			"Max Dirty\nRatio",
+			"Delete\nRetention",
+			"Segment\nAge",
+			"Problems",
+		},
+	)
+
+	for _, health := range healths {
+		dirtyRatio := "unknown"
+		if health.MaxDirtyRatio >= 0 {
+			dirtyRatio = fmt.Sprintf("%.2f", health.MaxDirtyRatio)
+		}
+
+		problems := "none"
+		if health.Risky() {
+			problems = strings.Join(health.Problems, "\n")
+		}
+
+		table.Append(
+			[]string{
+				health.Topic,
+				health.CleanupPolicy,
+				util.PrettyBytes(health.SizeBytes),
+				dirtyRatio,
+				(time.Duration(health.DeleteRetentionMs) * time.Millisecond).String(),
+				(time.Duration(health.SegmentMs) * time.Millisecond).String(),
+				problems,
+			},
+		)
+	}
+
+	table.Render()
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+func newTable(writer io.Writer, header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(writer)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+
+	alignments := []int{}
+	for range header {
+		alignments = append(alignments, tablewriter.ALIGN_LEFT)
+	}
+	table.SetColumnAlignment(alignments)
+
+	table.SetBorders(
+		tablewriter.Border{
+			Left:   false,
+			Top:    true,
+			Right:  false,
+			Bottom: true,
+		},
+	)
+
+	return table
+}
+
+func prettyRate(bytesPerSec float64) string {
+	return fmt.Sprintf("%s/sec", util.PrettyBytes(int64(bytesPerSec)))
+}
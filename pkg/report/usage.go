@@ -0,0 +1,145 @@
+// Package report generates reports that summarize how a cluster is used, e.g. for capacity
+// planning and chargeback.
+package report
+
+import (
+	"context"
+	"sort"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/segmentio/topicctl/pkg/metrics"
+)
+
+// UnownedTeam is the team that topics without any ownership information are grouped under.
+const UnownedTeam = "(unowned)"
+
+// TopicUsage contains the resources used by a single topic.
+type TopicUsage struct {
+	Topic string `json:"topic"`
+	Team  string `json:"team"`
+	Owner string `json:"owner,omitempty"`
+
+	Partitions int `json:"partitions"`
+	Replicas   int `json:"replicas"`
+
+	// SizeBytes is the total size of the topic across all of its replicas.
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// BytesInPerSec is the total produce rate across all partitions, if throughput samples
+	// were provided.
+	BytesInPerSec float64 `json:"bytesInPerSec"`
+}
+
+// TeamUsage contains the resources used by all of the topics owned by a team.
+type TeamUsage struct {
+	Team          string  `json:"team"`
+	Topics        int     `json:"topics"`
+	Partitions    int     `json:"partitions"`
+	Replicas      int     `json:"replicas"`
+	SizeBytes     int64   `json:"sizeBytes"`
+	BytesInPerSec float64 `json:"bytesInPerSec"`
+
+	// StorageShare is the fraction of the cluster's total storage that's used by the team.
+	StorageShare float64 `json:"storageShare"`
+}
+
+// TopicUsages joins the argument topics with their sizes from the argument log dirs, their
+// throughputs from the argument fetcher, which can be nil, and their owners from the
+// argument index. The results are sorted by topic name.
+func TopicUsages(
+	ctx context.Context,
+	topics []admin.TopicInfo,
+	logDirs []admin.LogDirInfo,
+	fetcher metrics.Fetcher,
+	owners *config.OwnershipIndex,
+) ([]TopicUsage, error) {
+	sizes := map[string]int64{}
+	for _, logDir := range logDirs {
+		for _, replica := range logDir.Replicas {
+			// Future replicas are copies that are still being moved between log dirs
+			if !replica.IsFuture {
+				sizes[replica.Topic] += replica.Size
+			}
+		}
+	}
+
+	usages := []TopicUsage{}
+
+	for _, topic := range topics {
+		usage := TopicUsage{
+			Topic:      topic.Name,
+			Team:       UnownedTeam,
+			Partitions: len(topic.Partitions),
+			SizeBytes:  sizes[topic.Name],
+		}
+		for _, partition := range topic.Partitions {
+			usage.Replicas += len(partition.Replicas)
+		}
+
+		if owners != nil {
+			ownership := owners.Lookup(topic.Name)
+			if ownership.Team != "" {
+				usage.Team = ownership.Team
+			}
+			usage.Owner = ownership.Owner
+		}
+
+		if fetcher != nil {
+			rates, err := fetcher.FetchPartitionRates(ctx, topic.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, rate := range rates {
+				usage.BytesInPerSec += rate
+			}
+		}
+
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(a, b int) bool {
+		return usages[a].Topic < usages[b].Topic
+	})
+
+	return usages, nil
+}
+
+// TeamUsages aggregates the argument topic usages by team. The results are sorted by size,
+// from largest to smallest.
+func TeamUsages(topicUsages []TopicUsage) []TeamUsage {
+	usagesByTeam := map[string]*TeamUsage{}
+	var totalSize int64
+
+	for _, topicUsage := range topicUsages {
+		teamUsage, ok := usagesByTeam[topicUsage.Team]
+		if !ok {
+			teamUsage = &TeamUsage{Team: topicUsage.Team}
+			usagesByTeam[topicUsage.Team] = teamUsage
+		}
+
+		teamUsage.Topics++
+		teamUsage.Partitions += topicUsage.Partitions
+		teamUsage.Replicas += topicUsage.Replicas
+		teamUsage.SizeBytes += topicUsage.SizeBytes
+		teamUsage.BytesInPerSec += topicUsage.BytesInPerSec
+		totalSize += topicUsage.SizeBytes
+	}
+
+	teamUsages := []TeamUsage{}
+	for _, teamUsage := range usagesByTeam {
+		if totalSize > 0 {
+			teamUsage.StorageShare = float64(teamUsage.SizeBytes) / float64(totalSize)
+		}
+		teamUsages = append(teamUsages, *teamUsage)
+	}
+
+	sort.Slice(teamUsages, func(a, b int) bool {
+		if teamUsages[a].SizeBytes != teamUsages[b].SizeBytes {
+			return teamUsages[a].SizeBytes > teamUsages[b].SizeBytes
+		}
+		return teamUsages[a].Team < teamUsages[b].Team
+	})
+
+	return teamUsages
+}
@@ -0,0 +1,152 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/segmentio/topicctl/pkg/admin"
+	"github.com/segmentio/topicctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFetcher struct {
+	rates map[string]map[int]float64
+}
+
+func (f testFetcher) FetchPartitionRates(
+	ctx context.Context,
+	topic string,
+) (map[int]float64, error) {
+	return f.rates[topic], nil
+}
+
+func TestUsages(t *testing.T) {
+	ctx := context.Background()
+
+	topics := []admin.TopicInfo{
+		{
+			Name: "payments",
+			Partitions: []admin.PartitionInfo{
+				{ID: 0, Replicas: []int{1, 2}},
+				{ID: 1, Replicas: []int{2, 3}},
+			},
+		},
+		{
+			Name: "orders",
+			Partitions: []admin.PartitionInfo{
+				{ID: 0, Replicas: []int{1, 2, 3}},
+			},
+		},
+		{
+			Name: "misc",
+			Partitions: []admin.PartitionInfo{
+				{ID: 0, Replicas: []int{1}},
+			},
+		},
+	}
+	logDirs := []admin.LogDirInfo{
+		{
+			Broker: 1,
+			Replicas: []admin.LogDirReplica{
+				{Topic: "payments", Partition: 0, Size: 100},
+				{Topic: "orders", Partition: 0, Size: 200},
+				{Topic: "misc", Partition: 0, Size: 100},
+			},
+		},
+		{
+			Broker: 2,
+			Replicas: []admin.LogDirReplica{
+				{Topic: "payments", Partition: 0, Size: 100},
+				{Topic: "payments", Partition: 1, Size: 50},
+				{Topic: "orders", Partition: 0, Size: 200},
+				// Future replicas aren't counted
+				{Topic: "orders", Partition: 0, Size: 200, IsFuture: true},
+			},
+		},
+	}
+	fetcher := testFetcher{
+		rates: map[string]map[int]float64{
+			"payments": {0: 10.0, 1: 20.0},
+		},
+	}
+	owners, err := config.NewOwnershipIndex(
+		config.ClusterConfig{
+			Spec: config.ClusterSpec{
+				Owners: []config.OwnershipRule{
+					{Match: "payments", Team: "payments", Owner: "alice"},
+					{Match: "orders", Team: "payments"},
+				},
+			},
+		},
+		nil,
+	)
+	require.NoError(t, err)
+
+	topicUsages, err := TopicUsages(ctx, topics, logDirs, fetcher, owners)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]TopicUsage{
+			{
+				Topic:      "misc",
+				Team:       UnownedTeam,
+				Partitions: 1,
+				Replicas:   1,
+				SizeBytes:  100,
+			},
+			{
+				Topic:      "orders",
+				Team:       "payments",
+				Partitions: 1,
+				Replicas:   3,
+				SizeBytes:  400,
+			},
+			{
+				Topic:         "payments",
+				Team:          "payments",
+				Owner:         "alice",
+				Partitions:    2,
+				Replicas:      4,
+				SizeBytes:     250,
+				BytesInPerSec: 30.0,
+			},
+		},
+		topicUsages,
+	)
+
+	assert.Equal(
+		t,
+		[]TeamUsage{
+			{
+				Team:          "payments",
+				Topics:        2,
+				Partitions:    3,
+				Replicas:      7,
+				SizeBytes:     650,
+				BytesInPerSec: 30.0,
+				StorageShare:  650.0 / 750.0,
+			},
+			{
+				Team:         UnownedTeam,
+				Topics:       1,
+				Partitions:   1,
+				Replicas:     1,
+				SizeBytes:    100,
+				StorageShare: 100.0 / 750.0,
+			},
+		},
+		TeamUsages(topicUsages),
+	)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteTeamUsagesCSV(buf, TeamUsages(topicUsages)))
+	assert.Equal(
+		t,
+		"team,topics,partitions,replicas,size_bytes,storage_share,bytes_in_per_sec\n"+
+			"payments,2,3,7,650,0.8667,30.00\n"+
+			"(unowned),1,1,1,100,0.1333,0.00\n",
+		buf.String(),
+	)
+}
@@ -0,0 +1,203 @@
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	contentType = "application/vnd.schemaregistry.v1+json"
+
+	// Error codes returned by the registry API when a subject or its config aren't found.
+	errorCodeSubjectNotFound = 40401
+	errorCodeConfigNotFound  = 40408
+)
+
+// ErrSubjectNotFound is returned when a subject isn't registered in the schema registry.
+var ErrSubjectNotFound = errors.New("Subject not found")
+
+// Client is a minimal client for the Confluent-compatible schema registry REST API.
+type Client struct {
+	registryURL string
+	httpClient  *http.Client
+}
+
+// NewClient returns a new Client instance for the registry at the argument URL.
+func NewClient(registryURL string) *Client {
+	return &Client{
+		registryURL: strings.TrimRight(registryURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+type registryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+type compatibilityResponse struct {
+	CompatibilityLevel string `json:"compatibilityLevel"`
+}
+
+type compatibilityRequest struct {
+	Compatibility string `json:"compatibility"`
+}
+
+// GetSubjectVersions returns the versions registered for the argument subject. If the
+// subject doesn't exist, then ErrSubjectNotFound is returned.
+func (c *Client) GetSubjectVersions(ctx context.Context, subject string) ([]int, error) {
+	versions := []int{}
+
+	err := c.do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject)),
+		nil,
+		&versions,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetCompatibility returns the compatibility level for the argument subject. If the
+// subject doesn't have its own level, then the registry-wide default is returned.
+func (c *Client) GetCompatibility(ctx context.Context, subject string) (string, error) {
+	resp := compatibilityResponse{}
+
+	err := c.do(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("/config/%s", url.PathEscape(subject)),
+		nil,
+		&resp,
+	)
+	if err == ErrSubjectNotFound {
+		err = c.do(ctx, http.MethodGet, "/config", nil, &resp)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return resp.CompatibilityLevel, nil
+}
+
+// SetCompatibility updates the compatibility level for the argument subject.
+func (c *Client) SetCompatibility(
+	ctx context.Context,
+	subject string,
+	compatibility string,
+) error {
+	return c.do(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("/config/%s", url.PathEscape(subject)),
+		compatibilityRequest{Compatibility: compatibility},
+		nil,
+	)
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	body interface{},
+	result interface{},
+) error {
+	var reqBody *bytes.Buffer
+
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewBuffer(bodyBytes)
+	} else {
+		reqBody = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequest(method, c.registryURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		regErr := registryError{}
+		if err := json.Unmarshal(respBytes, &regErr); err == nil {
+			if regErr.ErrorCode == errorCodeSubjectNotFound ||
+				regErr.ErrorCode == errorCodeConfigNotFound {
+				return ErrSubjectNotFound
+			}
+		}
+
+		return fmt.Errorf(
+			"Schema registry request %s %s failed with status %d: %s",
+			method,
+			path,
+			resp.StatusCode,
+			string(respBytes),
+		)
+	}
+
+	if result != nil {
+		return json.Unmarshal(respBytes, result)
+	}
+	return nil
+}
+
+// SubjectStatus summarizes the state of a single subject in the schema registry.
+type SubjectStatus struct {
+	Subject       string
+	Registered    bool
+	NumVersions   int
+	Compatibility string
+}
+
+// GetSubjectStatus fetches the registration and compatibility status of the argument subject.
+func (c *Client) GetSubjectStatus(ctx context.Context, subject string) (SubjectStatus, error) {
+	status := SubjectStatus{
+		Subject: subject,
+	}
+
+	versions, err := c.GetSubjectVersions(ctx, subject)
+	if err != nil && err != ErrSubjectNotFound {
+		return status, err
+	}
+	status.Registered = err == nil && len(versions) > 0
+	status.NumVersions = len(versions)
+
+	status.Compatibility, err = c.GetCompatibility(ctx, subject)
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,86 @@
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientSubjectStatus(t *testing.T) {
+	compatibilities := map[string]string{
+		"test-topic-value": "FULL",
+	}
+
+	server := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/subjects/test-topic-value/versions":
+				w.Write([]byte(`[1,2,3]`))
+			case r.Method == http.MethodGet && r.URL.Path == "/subjects/test-topic-key/versions":
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+			case r.Method == http.MethodGet && r.URL.Path == "/config":
+				w.Write([]byte(`{"compatibilityLevel":"BACKWARD"}`))
+			case r.Method == http.MethodGet:
+				subject := r.URL.Path[len("/config/"):]
+				compatibility, ok := compatibilities[subject]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"error_code":40408,"message":"Subject not found."}`))
+					return
+				}
+				w.Write([]byte(`{"compatibilityLevel":"` + compatibility + `"}`))
+			case r.Method == http.MethodPut:
+				body, _ := ioutil.ReadAll(r.Body)
+				req := compatibilityRequest{}
+				json.Unmarshal(body, &req)
+				compatibilities[r.URL.Path[len("/config/"):]] = req.Compatibility
+				w.Write(body)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}),
+	)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL + "/")
+
+	status, err := client.GetSubjectStatus(ctx, "test-topic-value")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		SubjectStatus{
+			Subject:       "test-topic-value",
+			Registered:    true,
+			NumVersions:   3,
+			Compatibility: "FULL",
+		},
+		status,
+	)
+
+	status, err = client.GetSubjectStatus(ctx, "test-topic-key")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		SubjectStatus{
+			Subject:       "test-topic-key",
+			Registered:    false,
+			Compatibility: "BACKWARD",
+		},
+		status,
+	)
+
+	err = client.SetCompatibility(ctx, "test-topic-key", "NONE")
+	require.NoError(t, err)
+
+	compatibility, err := client.GetCompatibility(ctx, "test-topic-key")
+	require.NoError(t, err)
+	assert.Equal(t, "NONE", compatibility)
+}
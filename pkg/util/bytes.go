@@ -0,0 +1,67 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var byteUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	// Longer suffixes must come first so that they're matched before the shorter ones
+	{suffix: "KIB", multiplier: 1024},
+	{suffix: "MIB", multiplier: 1024 * 1024},
+	{suffix: "GIB", multiplier: 1024 * 1024 * 1024},
+	{suffix: "KB", multiplier: 1000},
+	{suffix: "MB", multiplier: 1000 * 1000},
+	{suffix: "GB", multiplier: 1000 * 1000 * 1000},
+	{suffix: "K", multiplier: 1000},
+	{suffix: "M", multiplier: 1000 * 1000},
+	{suffix: "G", multiplier: 1000 * 1000 * 1000},
+	{suffix: "B", multiplier: 1},
+}
+
+// ParseBytes converts a human-formatted size string (e.g., "1KB", "50MB", "2GiB") into a
+// number of bytes. Unit suffixes are case-insensitive and are optional; values without
+// a suffix are interpreted as bytes. A trailing "/s" is ignored so that rates can be
+// parsed with the same function.
+func ParseBytes(input string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	value = strings.TrimSuffix(value, "/S")
+
+	multiplier := int64(1)
+
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Could not parse byte value %s", input)
+	}
+	if floatValue < 0 {
+		return 0, fmt.Errorf("Byte value %s cannot be negative", input)
+	}
+
+	return int64(floatValue * float64(multiplier)), nil
+}
+
+// PrettyBytes returns a human-formatted string for the argument number of bytes.
+func PrettyBytes(bytes int64) string {
+	switch {
+	case bytes >= 1000*1000*1000:
+		return fmt.Sprintf("%0.2fGB", float64(bytes)/(1000*1000*1000))
+	case bytes >= 1000*1000:
+		return fmt.Sprintf("%0.2fMB", float64(bytes)/(1000*1000))
+	case bytes >= 1000:
+		return fmt.Sprintf("%0.2fKB", float64(bytes)/1000)
+	default:
+		return fmt.Sprintf("%dB", bytes)
+	}
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBytes(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected int64
+		expError bool
+	}
+
+	testCases := []testCase{
+		{
+			input:    "512",
+			expected: 512,
+		},
+		{
+			input:    "1KB",
+			expected: 1000,
+		},
+		{
+			input:    "1kib",
+			expected: 1024,
+		},
+		{
+			input:    "50MB/s",
+			expected: 50000000,
+		},
+		{
+			input:    "1.5 GB",
+			expected: 1500000000,
+		},
+		{
+			input:    "10B",
+			expected: 10,
+		},
+		{
+			input:    "MB",
+			expError: true,
+		},
+		{
+			input:    "-5KB",
+			expError: true,
+		},
+	}
+
+	for _, testCaseObj := range testCases {
+		result, err := ParseBytes(testCaseObj.input)
+		if testCaseObj.expError {
+			assert.Error(t, err, testCaseObj.input)
+		} else {
+			assert.NoError(t, err, testCaseObj.input)
+			assert.Equal(t, testCaseObj.expected, result, testCaseObj.input)
+		}
+	}
+}
+
+func TestPrettyBytes(t *testing.T) {
+	assert.Equal(t, "999B", PrettyBytes(999))
+	assert.Equal(t, "1.50KB", PrettyBytes(1500))
+	assert.Equal(t, "50.00MB", PrettyBytes(50000000))
+	assert.Equal(t, "2.00GB", PrettyBytes(2000000000))
+}
@@ -0,0 +1,161 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed, five-field cron expression (minute, hour, day of month, month,
+// and day of week).
+type CronSchedule struct {
+	minutes     map[int]struct{}
+	hours       map[int]struct{}
+	daysOfMonth map[int]struct{}
+	months      map[int]struct{}
+	daysOfWeek  map[int]struct{}
+
+	// As in standard cron, if both day fields are restricted then a time matches if either
+	// one does.
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// ParseCronSchedule parses a standard, five-field cron expression like "0 2 * * 1-5". Each
+// field can be a "*", a value, a range ("1-5"), or a comma-separated list of these, and values
+// and ranges can have a step ("*/15", "0-30/10"). Days of the week run from 0 (Sunday) to 6,
+// with 7 also accepted for Sunday.
+func ParseCronSchedule(expr string) (CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return CronSchedule{}, fmt.Errorf(
+			"Cron expression %s must have %d fields, found %d",
+			expr,
+			len(cronFields),
+			len(fields),
+		)
+	}
+
+	values := []map[int]struct{}{}
+	for f, field := range fields {
+		fieldValues, err := parseCronField(field, cronFields[f])
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("Invalid cron expression %s: %+v", expr, err)
+		}
+		values = append(values, fieldValues)
+	}
+
+	if _, ok := values[4][7]; ok {
+		values[4][0] = struct{}{}
+	}
+
+	return CronSchedule{
+		minutes:       values[0],
+		hours:         values[1],
+		daysOfMonth:   values[2],
+		months:        values[3],
+		daysOfWeek:    values[4],
+		anyDayOfMonth: strings.HasPrefix(fields[2], "*"),
+		anyDayOfWeek:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// Matches returns whether the schedule fires at the minute containing the argument time,
+// evaluated in the time's location.
+func (c CronSchedule) Matches(t time.Time) bool {
+	if !intInSet(t.Minute(), c.minutes) ||
+		!intInSet(t.Hour(), c.hours) ||
+		!intInSet(int(t.Month()), c.months) {
+		return false
+	}
+
+	dayOfMonthOK := intInSet(t.Day(), c.daysOfMonth)
+	dayOfWeekOK := intInSet(int(t.Weekday()), c.daysOfWeek)
+
+	switch {
+	case c.anyDayOfMonth && c.anyDayOfWeek:
+		return true
+	case c.anyDayOfMonth:
+		return dayOfWeekOK
+	case c.anyDayOfWeek:
+		return dayOfMonthOK
+	default:
+		return dayOfMonthOK || dayOfWeekOK
+	}
+}
+
+func parseCronField(field string, spec cronField) (map[int]struct{}, error) {
+	values := map[int]struct{}{}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if slashIndex := strings.Index(part, "/"); slashIndex >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[slashIndex+1:])
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("Invalid %s step in %s", spec.name, part)
+			}
+			part = part[:slashIndex]
+		}
+
+		start := spec.min
+		end := spec.max
+
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %s value %s", spec.name, bounds[0])
+			}
+			end = start
+
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("Invalid %s value %s", spec.name, bounds[1])
+				}
+			} else if step > 1 {
+				// As in standard cron, "5/10" means every 10 starting at 5
+				end = spec.max
+			}
+		}
+
+		if start < spec.min || end > spec.max || start > end {
+			return nil, fmt.Errorf(
+				"The %s values in %s must be between %d and %d",
+				spec.name,
+				part,
+				spec.min,
+				spec.max,
+			)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = struct{}{}
+		}
+	}
+
+	return values, nil
+}
+
+func intInSet(value int, values map[int]struct{}) bool {
+	_, ok := values[value]
+	return ok
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule(t *testing.T) {
+	type testCase struct {
+		expr     string
+		time     time.Time
+		expected bool
+	}
+
+	// 2021-03-01 is a Monday
+	testCases := []testCase{
+		{
+			expr:     "* * * * *",
+			time:     time.Date(2021, 3, 1, 12, 34, 56, 0, time.UTC),
+			expected: true,
+		},
+		{
+			expr:     "0 2 * * 1-5",
+			time:     time.Date(2021, 3, 1, 2, 0, 30, 0, time.UTC),
+			expected: true,
+		},
+		{
+			expr:     "0 2 * * 1-5",
+			time:     time.Date(2021, 3, 1, 2, 1, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			expr:     "0 2 * * 1-5",
+			time:     time.Date(2021, 3, 6, 2, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			expr:     "*/15 * * * *",
+			time:     time.Date(2021, 3, 1, 2, 45, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			expr:     "5/20 * * * *",
+			time:     time.Date(2021, 3, 1, 2, 25, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			expr:     "0 0 * * 7",
+			time:     time.Date(2021, 3, 7, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			// Either day field can match when both are set
+			expr:     "0 0 15 * 1",
+			time:     time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			expected: true,
+		},
+		{
+			expr:     "0 0 15 * 1",
+			time:     time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			expected: false,
+		},
+		{
+			expr:     "30 9,17 1 3 *",
+			time:     time.Date(2021, 3, 1, 17, 30, 0, 0, time.UTC),
+			expected: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		schedule, err := ParseCronSchedule(testCase.expr)
+		require.NoError(t, err, testCase.expr)
+		assert.Equal(t, testCase.expected, schedule.Matches(testCase.time), testCase.expr)
+	}
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 5-2 * * *",
+		"*/0 * * * *",
+		"* * 0 * *",
+		"a * * * *",
+	} {
+		_, err := ParseCronSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -2,6 +2,8 @@ package util
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -47,3 +49,27 @@ func PrettyRate(count int64, duration time.Duration) string {
 		return fmt.Sprintf("~0")
 	}
 }
+
+// ParseDuration parses a duration string. In addition to the units supported by
+// time.ParseDuration, it accepts a single integer number of days (e.g., "7d") or weeks
+// (e.g., "2w"), which are common for retention periods.
+func ParseDuration(input string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(input)
+
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		if !strings.HasSuffix(trimmed, suffix) {
+			continue
+		}
+
+		count, err := strconv.Atoi(strings.TrimSuffix(trimmed, suffix))
+		if err != nil {
+			return 0, fmt.Errorf("Could not parse duration %s", input)
+		}
+		return time.Duration(count) * unit, nil
+	}
+
+	return time.ParseDuration(trimmed)
+}
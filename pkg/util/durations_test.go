@@ -98,3 +98,48 @@ func TestPrettyRate(t *testing.T) {
 		)
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	type testCase struct {
+		input       string
+		expected    time.Duration
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			input:    "6h",
+			expected: 6 * time.Hour,
+		},
+		{
+			input:    "90m",
+			expected: 90 * time.Minute,
+		},
+		{
+			input:    "7d",
+			expected: 7 * 24 * time.Hour,
+		},
+		{
+			input:    " 2w ",
+			expected: 14 * 24 * time.Hour,
+		},
+		{
+			input:       "1.5d",
+			expectedErr: true,
+		},
+		{
+			input:       "forever",
+			expectedErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		duration, err := ParseDuration(testCase.input)
+		if testCase.expectedErr {
+			assert.Error(t, err, testCase.input)
+		} else {
+			assert.NoError(t, err, testCase.input)
+			assert.Equal(t, testCase.expected, duration, testCase.input)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// TopicMatcher matches topic names against a pattern. Patterns are globs by default,
+// e.g. "orders-*"; patterns wrapped in slashes, e.g. "/^orders-(us|eu)$/", are treated as
+// regular expressions instead.
+type TopicMatcher struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// NewTopicMatcher returns a TopicMatcher for the argument pattern.
+func NewTopicMatcher(pattern string) (*TopicMatcher, error) {
+	if pattern == "" {
+		return nil, errors.New("Topic pattern cannot be empty")
+	}
+
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		regex, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid topic regex %s: %+v", pattern, err)
+		}
+		return &TopicMatcher{
+			pattern: pattern,
+			regex:   regex,
+		}, nil
+	}
+
+	// Check that the glob is valid up-front so that Matches doesn't need to return an error
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("Invalid topic glob %s: %+v", pattern, err)
+	}
+
+	return &TopicMatcher{
+		pattern: pattern,
+	}, nil
+}
+
+// Matches returns whether the argument topic name matches the pattern. A nil matcher matches
+// all topics.
+func (m *TopicMatcher) Matches(topic string) bool {
+	if m == nil {
+		return true
+	}
+	if m.regex != nil {
+		return m.regex.MatchString(topic)
+	}
+
+	matches, _ := path.Match(m.pattern, topic)
+	return matches
+}
+
+// Filter returns the subset of the argument topic names that match the pattern, in the same
+// order.
+func (m *TopicMatcher) Filter(topics []string) []string {
+	filtered := []string{}
+
+	for _, topic := range topics {
+		if m.Matches(topic) {
+			filtered = append(filtered, topic)
+		}
+	}
+
+	return filtered
+}
+
+// String returns the pattern for this matcher.
+func (m *TopicMatcher) String() string {
+	if m == nil {
+		return ""
+	}
+	return m.pattern
+}
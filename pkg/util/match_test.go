@@ -0,0 +1,33 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicMatcher(t *testing.T) {
+	topics := []string{"orders-us", "orders-eu", "orders-dlq", "payments-us"}
+
+	globMatcher, err := NewTopicMatcher("orders-*")
+	require.NoError(t, err)
+	assert.True(t, globMatcher.Matches("orders-us"))
+	assert.False(t, globMatcher.Matches("payments-us"))
+	assert.Equal(t, []string{"orders-us", "orders-eu", "orders-dlq"}, globMatcher.Filter(topics))
+
+	regexMatcher, err := NewTopicMatcher("/^orders-(us|eu)$/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders-us", "orders-eu"}, regexMatcher.Filter(topics))
+
+	var nilMatcher *TopicMatcher
+	assert.True(t, nilMatcher.Matches("payments-us"))
+	assert.Equal(t, topics, nilMatcher.Filter(topics))
+
+	_, err = NewTopicMatcher("")
+	assert.Error(t, err)
+	_, err = NewTopicMatcher("orders-[")
+	assert.Error(t, err)
+	_, err = NewTopicMatcher("/orders-(/")
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"sync"
+)
+
+// RunParallel calls the argument function for each index in [0, n), running at most
+// concurrency calls at a time. If any call fails, the context passed to the other calls is
+// cancelled, no new calls are started, and the first error is returned.
+func RunParallel(
+	ctx context.Context,
+	n int,
+	concurrency int,
+	fn func(ctx context.Context, i int) error,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indices := make(chan int, n)
+	for i := 0; i < n; i++ {
+		indices <- i
+	}
+	close(indices)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	numWorkers := concurrency
+	if n < numWorkers {
+		numWorkers = n
+	}
+
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if firstErr == nil {
+		// Catch the case where the parent context was cancelled before all calls were run
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunParallel(t *testing.T) {
+	ctx := context.Background()
+
+	var mutex sync.Mutex
+	active := 0
+	maxActive := 0
+	results := make([]int, 100)
+
+	err := RunParallel(ctx, len(results), 5, func(ctx context.Context, i int) error {
+		mutex.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+
+		results[i] = i * 2
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, maxActive, 5)
+	for i, result := range results {
+		assert.Equal(t, i*2, result)
+	}
+
+	testErr := errors.New("test error")
+	err = RunParallel(ctx, 100, 5, func(ctx context.Context, i int) error {
+		if i == 10 {
+			return testErr
+		}
+		return nil
+	})
+	assert.Equal(t, testErr, err)
+
+	assert.NoError(
+		t,
+		RunParallel(ctx, 0, 5, func(ctx context.Context, i int) error {
+			return testErr
+		}),
+	)
+}
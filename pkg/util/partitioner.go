@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Partitioner is a string type that stores the name of a partitioner that producers use to
+// map message keys to partitions.
+type Partitioner string
+
+const (
+	// PartitionerMurmur2 is the default partitioner in the Java client; it's also available
+	// as "murmur2" and "murmur2_random" in librdkafka.
+	PartitionerMurmur2 Partitioner = "murmur2"
+
+	// PartitionerCRC32 is the default partitioner in librdkafka ("consistent_random"); it's
+	// also available as "consistent".
+	PartitionerCRC32 Partitioner = "crc32"
+
+	// PartitionerFNV1A is the partitioner used by kafka-go's Hash balancer.
+	PartitionerFNV1A Partitioner = "fnv1a"
+)
+
+// AllPartitioners contains all of the valid partitioners.
+var AllPartitioners = []Partitioner{
+	PartitionerMurmur2,
+	PartitionerCRC32,
+	PartitionerFNV1A,
+}
+
+// Validate determines whether the partitioner is valid.
+func (p Partitioner) Validate() error {
+	for _, partitioner := range AllPartitioners {
+		if p == partitioner {
+			return nil
+		}
+	}
+	return fmt.Errorf("Partitioner must be in %+v", AllPartitioners)
+}
+
+// PartitionForKey returns the partition that the argument key maps to in a topic with
+// numPartitions partitions when using the argument partitioner. The key must be non-nil;
+// producers don't hash messages without keys, but spread them randomly or round-robin
+// instead.
+func PartitionForKey(partitioner Partitioner, key []byte, numPartitions int) (int, error) {
+	if key == nil {
+		return 0, fmt.Errorf("Key must be set")
+	}
+	if numPartitions <= 0 {
+		return 0, fmt.Errorf("Number of partitions must be positive: %d", numPartitions)
+	}
+
+	var balancer kafka.Balancer
+
+	switch partitioner {
+	case PartitionerMurmur2:
+		balancer = kafka.Murmur2Balancer{Consistent: true}
+	case PartitionerCRC32:
+		balancer = kafka.CRC32Balancer{Consistent: true}
+	case PartitionerFNV1A:
+		balancer = &kafka.Hash{}
+	default:
+		return 0, partitioner.Validate()
+	}
+
+	partitions := make([]int, numPartitions)
+	for i := 0; i < numPartitions; i++ {
+		partitions[i] = i
+	}
+	return balancer.Balance(kafka.Message{Key: key}, partitions...), nil
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionForKey(t *testing.T) {
+	// Expected values are based on the hashes in the librdkafka partitioner tests
+	type testCase struct {
+		partitioner   Partitioner
+		key           string
+		numPartitions int
+		expected      int
+	}
+
+	testCases := []testCase{
+		{
+			partitioner:   PartitionerMurmur2,
+			key:           "kafka",
+			numPartitions: 7,
+			expected:      3,
+		},
+		{
+			partitioner:   PartitionerMurmur2,
+			key:           "1234",
+			numPartitions: 7,
+			expected:      0,
+		},
+		{
+			partitioner:   PartitionerMurmur2,
+			key:           "",
+			numPartitions: 7,
+			expected:      2,
+		},
+		{
+			partitioner:   PartitionerCRC32,
+			key:           "23456",
+			numPartitions: 17,
+			expected:      7,
+		},
+	}
+
+	for _, testCase := range testCases {
+		partition, err := PartitionForKey(
+			testCase.partitioner,
+			[]byte(testCase.key),
+			testCase.numPartitions,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, testCase.expected, partition, testCase.key)
+	}
+
+	partition, err := PartitionForKey(PartitionerFNV1A, []byte("key1"), 5)
+	require.NoError(t, err)
+	repeated, err := PartitionForKey(PartitionerFNV1A, []byte("key1"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, partition, repeated)
+
+	_, err = PartitionForKey(Partitioner("unknown"), []byte("key1"), 5)
+	assert.Error(t, err)
+	_, err = PartitionForKey(PartitionerMurmur2, nil, 5)
+	assert.Error(t, err)
+	_, err = PartitionForKey(PartitionerMurmur2, []byte("key1"), 0)
+	assert.Error(t, err)
+}
@@ -12,3 +12,8 @@ import (
 func InTerminal() bool {
 	return terminal.IsTerminal(int(os.Stdout.Fd()))
 }
+
+// StderrInTerminal determines whether stderr, which the logs are written to, is a terminal.
+func StderrInTerminal() bool {
+	return terminal.IsTerminal(int(os.Stderr.Fd()))
+}
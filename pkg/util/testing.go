@@ -65,6 +65,24 @@ func TestKafkaContollerConn(ctx context.Context, t *testing.T) *kafka.Conn {
 	return controllerConn
 }
 
+// SkipIfTestAPIUnsupported skips the current test if the brokers in the test cluster don't
+// support the API with the argument key (e.g., because the Kafka version is too old).
+func SkipIfTestAPIUnsupported(ctx context.Context, t *testing.T, apiKey int16) {
+	conn := TestKafkaConn(ctx, t)
+	defer conn.Close()
+
+	apiVersions, err := conn.ApiVersions()
+	require.Nil(t, err)
+
+	for _, apiVersion := range apiVersions {
+		if apiVersion.ApiKey == apiKey {
+			return
+		}
+	}
+
+	t.Skipf("Test cluster does not support API %d", apiKey)
+}
+
 var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
 // RandomString returns a random string with the argument length.
@@ -0,0 +1,208 @@
+package zk
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	szk "github.com/samuel/go-zookeeper/zk"
+	log "github.com/sirupsen/logrus"
+)
+
+var _ Client = (*CachedClient)(nil)
+
+// CachedClient is a Client implementation that wraps another one and caches the results of
+// reads for a fixed amount of time. Any write through the client clears the whole cache, but
+// changes made by other clients aren't seen until the cached entries expire, so it should
+// only be used in places where slightly stale data is acceptable, e.g. interactive sessions.
+type CachedClient struct {
+	Client
+
+	ttl     time.Duration
+	nowFunc func() time.Time
+
+	mutex   sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	path   string
+	method string
+}
+
+type cacheEntry struct {
+	content  []byte
+	exists   bool
+	children []string
+	stats    *szk.Stat
+	expires  time.Time
+}
+
+// NewCachedClient returns a new CachedClient that wraps the argument client. Read results are
+// cached for the argument ttl.
+func NewCachedClient(client Client, ttl time.Duration) *CachedClient {
+	return &CachedClient{
+		Client:  client,
+		ttl:     ttl,
+		nowFunc: time.Now,
+		entries: map[cacheKey]cacheEntry{},
+	}
+}
+
+// Get returns the value at the argument zk path.
+func (c *CachedClient) Get(
+	ctx context.Context,
+	path string,
+) ([]byte, *szk.Stat, error) {
+	entry, err := c.cached(path, "get", func(entry *cacheEntry) error {
+		var err error
+		entry.content, entry.stats, err = c.Client.Get(ctx, path)
+		return err
+	})
+	return entry.content, entry.stats, err
+}
+
+// GetJSON unmarshals the JSON content at the argument zk path into an object.
+func (c *CachedClient) GetJSON(
+	ctx context.Context,
+	path string,
+	obj interface{},
+) (*szk.Stat, error) {
+	data, stats, err := c.Get(ctx, path)
+	if err != nil {
+		return stats, err
+	}
+
+	err = json.Unmarshal(data, obj)
+	return stats, err
+}
+
+// Children gets all children of the node at the argument zk path.
+func (c *CachedClient) Children(
+	ctx context.Context,
+	path string,
+) ([]string, *szk.Stat, error) {
+	entry, err := c.cached(path, "children", func(entry *cacheEntry) error {
+		var err error
+		entry.children, entry.stats, err = c.Client.Children(ctx, path)
+		return err
+	})
+	return entry.children, entry.stats, err
+}
+
+// Exists returns whether a node exists at the argument zk path.
+func (c *CachedClient) Exists(
+	ctx context.Context,
+	path string,
+) (bool, *szk.Stat, error) {
+	entry, err := c.cached(path, "exists", func(entry *cacheEntry) error {
+		var err error
+		entry.exists, entry.stats, err = c.Client.Exists(ctx, path)
+		return err
+	})
+	return entry.exists, entry.stats, err
+}
+
+// Create adds a new node at the argument zk path.
+func (c *CachedClient) Create(
+	ctx context.Context,
+	path string,
+	data []byte,
+	sequential bool,
+) error {
+	defer c.Clear()
+	return c.Client.Create(ctx, path, data, sequential)
+}
+
+// CreateJSON creates a new node at the argument zk path using the JSON-marshalled contents of
+// the argument object.
+func (c *CachedClient) CreateJSON(
+	ctx context.Context,
+	path string,
+	obj interface{},
+	sequential bool,
+) error {
+	defer c.Clear()
+	return c.Client.CreateJSON(ctx, path, obj, sequential)
+}
+
+// Set updates the contents of the node at the argument zk path.
+func (c *CachedClient) Set(
+	ctx context.Context,
+	path string,
+	data []byte,
+	version int32,
+) (*szk.Stat, error) {
+	defer c.Clear()
+	return c.Client.Set(ctx, path, data, version)
+}
+
+// SetJSON updates the contents of the node at the argument zk path using the JSON marshalling
+// of the argument object.
+func (c *CachedClient) SetJSON(
+	ctx context.Context,
+	path string,
+	obj interface{},
+	version int32,
+) (*szk.Stat, error) {
+	defer c.Clear()
+	return c.Client.SetJSON(ctx, path, obj, version)
+}
+
+// Delete removes the node at the argument zk path.
+func (c *CachedClient) Delete(
+	ctx context.Context,
+	path string,
+	version int32,
+) error {
+	defer c.Clear()
+	return c.Client.Delete(ctx, path, version)
+}
+
+// AcquireLock tries to acquire a lock using the argument zk path.
+func (c *CachedClient) AcquireLock(ctx context.Context, path string) (Lock, error) {
+	defer c.Clear()
+	return c.Client.AcquireLock(ctx, path)
+}
+
+// Clear removes all entries from the cache.
+func (c *CachedClient) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = map[cacheKey]cacheEntry{}
+}
+
+// cached returns the unexpired cache entry for the argument path and method, if any.
+// Otherwise, it runs the argument fetch function to fill in a new entry. Errors aren't
+// cached.
+func (c *CachedClient) cached(
+	path string,
+	method string,
+	fetch func(entry *cacheEntry) error,
+) (cacheEntry, error) {
+	key := cacheKey{path: path, method: method}
+	now := c.nowFunc()
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		log.Debugf("Using cached %s result for path %s", method, path)
+		return entry, nil
+	}
+
+	entry = cacheEntry{}
+	if err := fetch(&entry); err != nil {
+		return entry, err
+	}
+	entry.expires = now.Add(c.ttl)
+
+	c.mutex.Lock()
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	return entry, nil
+}
@@ -0,0 +1,85 @@
+package zk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	szk "github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient is a fake Client that counts reads and stores nodes in memory.
+type countingClient struct {
+	Client
+
+	nodes map[string][]byte
+	reads int
+}
+
+func (c *countingClient) Get(ctx context.Context, path string) ([]byte, *szk.Stat, error) {
+	c.reads++
+	data, ok := c.nodes[path]
+	if !ok {
+		return nil, nil, szk.ErrNoNode
+	}
+	return data, &szk.Stat{}, nil
+}
+
+func (c *countingClient) Set(
+	ctx context.Context,
+	path string,
+	data []byte,
+	version int32,
+) (*szk.Stat, error) {
+	c.nodes[path] = data
+	return &szk.Stat{}, nil
+}
+
+func TestCachedClient(t *testing.T) {
+	ctx := context.Background()
+	countingClient := &countingClient{
+		nodes: map[string][]byte{
+			"/node1": []byte(`{"key": "value1"}`),
+		},
+	}
+
+	now := time.Now()
+	cachedClient := NewCachedClient(countingClient, time.Minute)
+	cachedClient.nowFunc = func() time.Time {
+		return now
+	}
+
+	obj := map[string]string{}
+	_, err := cachedClient.GetJSON(ctx, "/node1", &obj)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value1"}, obj)
+	assert.Equal(t, 1, countingClient.reads)
+
+	// Cached
+	_, err = cachedClient.GetJSON(ctx, "/node1", &obj)
+	require.NoError(t, err)
+	assert.Equal(t, 1, countingClient.reads)
+
+	// Errors aren't cached
+	_, _, err = cachedClient.Get(ctx, "/node2")
+	assert.Equal(t, szk.ErrNoNode, err)
+	_, _, err = cachedClient.Get(ctx, "/node2")
+	assert.Equal(t, szk.ErrNoNode, err)
+	assert.Equal(t, 3, countingClient.reads)
+
+	// Writes clear the cache
+	_, err = cachedClient.Set(ctx, "/node1", []byte(`{"key": "value2"}`), -1)
+	require.NoError(t, err)
+	_, err = cachedClient.GetJSON(ctx, "/node1", &obj)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value2"}, obj)
+	assert.Equal(t, 4, countingClient.reads)
+
+	// Entries expire after the ttl
+	now = now.Add(2 * time.Minute)
+	_, _, err = cachedClient.Get(ctx, "/node1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, countingClient.reads)
+}
@@ -2,12 +2,16 @@ package zk
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"path/filepath"
 	"time"
 
 	szk "github.com/samuel/go-zookeeper/zk"
+	"github.com/segmentio/topicctl/pkg/ratelimit"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -38,6 +42,7 @@ type Client interface {
 		obj interface{},
 		version int32,
 	) (*szk.Stat, error)
+	Delete(ctx context.Context, path string, version int32) error
 
 	// Lock operations
 	AcquireLock(ctx context.Context, path string) (Lock, error)
@@ -61,6 +66,73 @@ type pooledResp struct {
 	err      error
 }
 
+// ClientOptions contains the connection and retry settings for a PooledClient. Zero values
+// are replaced with reasonable defaults.
+type ClientOptions struct {
+	// SessionTimeout is the zk session timeout. Ephemeral nodes, e.g. the ones used for
+	// locks, are removed if the client can't reach the ensemble for this long.
+	SessionTimeout time.Duration
+
+	// ConnectTimeout is the maximum amount of time to wait for a session to be established
+	// when the client is created.
+	ConnectTimeout time.Duration
+
+	// MaxRetries is the number of times that an operation is retried after a recoverable
+	// error, e.g. a dropped connection. Set this to a negative value to disable retries.
+	MaxRetries int
+
+	// RetryBackoff is the amount of time to wait before the first retry. It's doubled for
+	// each subsequent retry, up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+
+	// ObserverAddrs are the addresses of zk observers to connect to if the main addresses
+	// can't be reached. Since observers can't be relied on for writes, the client is
+	// read-only in this case.
+	ObserverAddrs []string
+
+	// AuthScheme and AuthCredentials, if set, are used to authenticate each connection
+	// after its session is established, e.g. "digest" and "user:password".
+	AuthScheme      string
+	AuthCredentials []byte
+
+	// ACL is applied to all nodes created by the client, including the ones used for locks.
+	// If unset, created nodes are world-writable.
+	ACL []szk.ACL
+
+	// TLSConfig, if set, is used to connect to the ensemble over TLS. This requires the
+	// zk servers to expose a secure client port, which is supported in zk 3.5 and later.
+	TLSConfig *tls.Config
+
+	// Limiter, if set, limits the rate of zk operations, including retries. It can be shared
+	// with other clients, e.g. the broker API calls made by the admin client.
+	Limiter *ratelimit.Limiter
+}
+
+func (o ClientOptions) withDefaults() ClientOptions {
+	if o.SessionTimeout == 0 {
+		o.SessionTimeout = time.Minute
+	}
+	if o.ConnectTimeout == 0 {
+		o.ConnectTimeout = 10 * time.Second
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	} else if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = 200 * time.Millisecond
+	}
+	if o.MaxRetryBackoff == 0 {
+		o.MaxRetryBackoff = 5 * time.Second
+	}
+	if len(o.ACL) == 0 {
+		o.ACL = szk.WorldACL(szk.PermAll)
+	}
+	return o
+}
+
 // PooledClient is a Client implementation that uses a pool of connections
 // instead of a single one for read-only operations. It can be subtantially faster than the base
 // samuel client, particularly when getting zookeeper nodes from multiple goroutines.
@@ -68,33 +140,38 @@ type PooledClient struct {
 	connections []*szk.Conn
 	requestChan chan pooledRequest
 	readOnly    bool
+	options     ClientOptions
 }
 
-// NewPooledClient returns a new PooledClient instance.
+// NewPooledClient returns a new PooledClient instance. If the argument addresses can't be
+// reached and observer addresses are set in the options, the client falls back to using
+// the observers in read-only mode.
 func NewPooledClient(
 	zkAddrs []string,
-	timeout time.Duration,
+	options ClientOptions,
 	logger szk.Logger,
 	poolSize int,
 	readOnly bool,
 ) (*PooledClient, error) {
-	connections := []*szk.Conn{}
+	options = options.withDefaults()
 	log.Debugf("Creating zk client with addresses %+v", zkAddrs)
 
-	for i := 0; i < poolSize; i++ {
-		conn, _, err := szk.Connect(
-			zkAddrs,
-			time.Minute,
-			szk.WithLogger(logger),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("Error connecting to zkAddr %+v: %+v", zkAddrs, err)
+	connections, err := connectPool(zkAddrs, options, logger, poolSize)
+	if err != nil {
+		if len(options.ObserverAddrs) == 0 {
+			return nil, err
 		}
 
-		connections = append(
-			connections,
-			conn,
+		log.Warnf(
+			"%+v; falling back to zk observers %+v in read-only mode",
+			err,
+			options.ObserverAddrs,
 		)
+		connections, err = connectPool(options.ObserverAddrs, options, logger, poolSize)
+		if err != nil {
+			return nil, err
+		}
+		readOnly = true
 	}
 
 	requestChan := make(chan pooledRequest)
@@ -131,29 +208,24 @@ func NewPooledClient(
 		connections: connections,
 		requestChan: requestChan,
 		readOnly:    readOnly,
+		options:     options,
 	}, nil
 }
 
+// ReadOnly returns whether this client is in read-only mode, either because it was
+// requested or because the client fell back to zk observers.
+func (c *PooledClient) ReadOnly() bool {
+	return c.readOnly
+}
+
 // Get returns the value at the argument zk path.
 func (c *PooledClient) Get(
 	ctx context.Context,
 	path string,
 ) ([]byte, *szk.Stat, error) {
-	respChan := make(chan pooledResp)
 	log.Debugf("Getting path %s", path)
-
-	c.requestChan <- pooledRequest{
-		path:     path,
-		method:   "get",
-		respChan: respChan,
-	}
-
-	select {
-	case resp := <-respChan:
-		return resp.content, resp.stats, resp.err
-	case <-ctx.Done():
-		return nil, nil, ctx.Err()
-	}
+	resp := c.pooledRequest(ctx, path, "get")
+	return resp.content, resp.stats, resp.err
 }
 
 // GetJSON unmarshals the JSON content at the argument zk path into an object.
@@ -176,21 +248,9 @@ func (c *PooledClient) Children(
 	ctx context.Context,
 	path string,
 ) ([]string, *szk.Stat, error) {
-	respChan := make(chan pooledResp)
 	log.Debugf("Getting children at %s", path)
-
-	c.requestChan <- pooledRequest{
-		path:     path,
-		method:   "children",
-		respChan: respChan,
-	}
-
-	select {
-	case resp := <-respChan:
-		return resp.children, resp.stats, resp.err
-	case <-ctx.Done():
-		return nil, nil, ctx.Err()
-	}
+	resp := c.pooledRequest(ctx, path, "children")
+	return resp.children, resp.stats, resp.err
 }
 
 // Exists returns whether a node exists at the argument zk path.
@@ -198,20 +258,42 @@ func (c *PooledClient) Exists(
 	ctx context.Context,
 	path string,
 ) (bool, *szk.Stat, error) {
-	respChan := make(chan pooledResp)
+	resp := c.pooledRequest(ctx, path, "exists")
+	return resp.exists, resp.stats, resp.err
+}
 
-	c.requestChan <- pooledRequest{
-		path:     path,
-		method:   "exists",
-		respChan: respChan,
-	}
+// pooledRequest runs a read-only request on one of the connections in the pool, retrying
+// it if it fails with a recoverable error.
+func (c *PooledClient) pooledRequest(
+	ctx context.Context,
+	path string,
+	method string,
+) pooledResp {
+	var resp pooledResp
+
+	err := c.withRetries(ctx, method, false, func() error {
+		respChan := make(chan pooledResp, 1)
+
+		select {
+		case c.requestChan <- pooledRequest{
+			path:     path,
+			method:   method,
+			respChan: respChan,
+		}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 
-	select {
-	case resp := <-respChan:
-		return resp.exists, resp.stats, resp.err
-	case <-ctx.Done():
-		return false, nil, ctx.Err()
-	}
+		select {
+		case resp = <-respChan:
+			return resp.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	resp.err = err
+
+	return resp
 }
 
 // Create adds a new node at the argument zk path.
@@ -225,36 +307,31 @@ func (c *PooledClient) Create(
 		return errors.New("Cannot write in read-only mode")
 	}
 
-	errChan := make(chan error)
+	var flags int32
+	if sequential {
+		flags = szk.FlagSequence
+	}
 
-	go func() {
-		var err error
+	return c.withRetries(ctx, "create", true, func() error {
+		errChan := make(chan error, 1)
 
-		if sequential {
-			_, err = c.connections[0].Create(
+		go func() {
+			_, err := c.connections[0].Create(
 				path,
 				data,
-				szk.FlagSequence,
-				szk.WorldACL(szk.PermAll),
-			)
-		} else {
-			_, err = c.connections[0].Create(
-				path,
-				data,
-				0,
-				szk.WorldACL(szk.PermAll),
+				flags,
+				c.options.ACL,
 			)
+			errChan <- err
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errChan:
+			return err
 		}
-
-		errChan <- err
-	}()
-
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
-		return err
-	}
+	})
 }
 
 // CreateJSON creates a new node at the argument zk path using the JSON-marshalled contents of
@@ -289,19 +366,26 @@ func (c *PooledClient) Set(
 		return nil, errors.New("Cannot write in read-only mode")
 	}
 
-	resultsChan := make(chan setResp)
+	var stats *szk.Stat
 
-	go func() {
-		stats, err := c.connections[0].Set(path, data, version)
-		resultsChan <- setResp{stats, err}
-	}()
+	err := c.withRetries(ctx, "set", true, func() error {
+		resultsChan := make(chan setResp, 1)
 
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case result := <-resultsChan:
-		return result.stats, result.err
-	}
+		go func() {
+			setStats, err := c.connections[0].Set(path, data, version)
+			resultsChan <- setResp{setStats, err}
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-resultsChan:
+			stats = result.stats
+			return result.err
+		}
+	})
+
+	return stats, err
 }
 
 // SetJSON updates the contents of the node at the argument zk path using the JSON marshalling
@@ -320,13 +404,39 @@ func (c *PooledClient) SetJSON(
 	return c.Set(ctx, path, data, version)
 }
 
+// Delete removes the node at the argument zk path. The node must not have any children.
+func (c *PooledClient) Delete(
+	ctx context.Context,
+	path string,
+	version int32,
+) error {
+	if c.readOnly {
+		return errors.New("Cannot delete in read-only mode")
+	}
+
+	return c.withRetries(ctx, "delete", true, func() error {
+		errChan := make(chan error, 1)
+
+		go func() {
+			errChan <- c.connections[0].Delete(path, version)
+		}()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errChan:
+			return err
+		}
+	})
+}
+
 // AcquireLock tries to acquire a lock using the argument zk path.
 func (c *PooledClient) AcquireLock(ctx context.Context, path string) (Lock, error) {
 	if c.readOnly {
 		return nil, errors.New("Cannot create lock in read-only mode")
 	}
 
-	lock := szk.NewLock(c.connections[0], path, szk.WorldACL(szk.PermAll))
+	lock := szk.NewLock(c.connections[0], path, c.options.ACL)
 	errChan := make(chan error)
 
 	go func() {
@@ -337,10 +447,32 @@ func (c *PooledClient) AcquireLock(ctx context.Context, path string) (Lock, erro
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case err := <-errChan:
+		if err == nil {
+			// The metadata is only informational, so don't fail if it can't be set
+			if metadataErr := c.setLockMetadata(ctx, path); metadataErr != nil {
+				log.Warnf("Could not set metadata for lock %s: %+v", path, metadataErr)
+			}
+		}
 		return lock, err
 	}
 }
 
+// setLockMetadata stores the metadata for the current process in the node that holds the
+// lock at the argument path.
+func (c *PooledClient) setLockMetadata(ctx context.Context, path string) error {
+	children, _, err := c.connections[0].Children(path)
+	if err != nil {
+		return err
+	}
+	holder, err := HolderNode(children)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.SetJSON(ctx, filepath.Join(path, holder), CurrentLockMetadata(), -1)
+	return err
+}
+
 // Close closes the current client and frees the associated resources.
 func (c *PooledClient) Close() error {
 	close(c.requestChan)
@@ -362,3 +494,152 @@ func (c *PooledClient) Close() error {
 
 	return nil
 }
+
+// withRetries runs the argument operation, retrying it with exponential backoff if it fails
+// with a recoverable error. Each attempt waits for the client's rate limiter, if any.
+func (c *PooledClient) withRetries(
+	ctx context.Context,
+	name string,
+	write bool,
+	operation func() error,
+) error {
+	backoff := c.options.RetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		if err := c.options.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		err := operation()
+		if err == nil || attempt > c.options.MaxRetries || !isRetryable(err, write) {
+			return err
+		}
+
+		log.Warnf(
+			"Got recoverable error for zk %s (attempt %d/%d), retrying in %s: %+v",
+			name,
+			attempt,
+			c.options.MaxRetries+1,
+			backoff,
+			err,
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > c.options.MaxRetryBackoff {
+			backoff = c.options.MaxRetryBackoff
+		}
+	}
+}
+
+// isRetryable returns whether an operation that failed with the argument error can be
+// safely retried. Reads can be retried after any connection error, but writes are only
+// retried if they definitely weren't sent to the server; otherwise, a retry could fail
+// or, worse, be applied twice.
+func isRetryable(err error, write bool) bool {
+	switch err {
+	case szk.ErrNoServer:
+		return true
+	case szk.ErrConnectionClosed, szk.ErrSessionExpired:
+		return !write
+	default:
+		return false
+	}
+}
+
+// connectPool creates poolSize connections to the argument zk addresses, waiting for each
+// one to establish a session.
+func connectPool(
+	zkAddrs []string,
+	options ClientOptions,
+	logger szk.Logger,
+	poolSize int,
+) ([]*szk.Conn, error) {
+	connections := []*szk.Conn{}
+
+	closeAll := func() {
+		for _, conn := range connections {
+			conn.Close()
+		}
+	}
+
+	for i := 0; i < poolSize; i++ {
+		conn, events, err := szk.Connect(
+			zkAddrs,
+			options.SessionTimeout,
+			szk.WithLogger(logger),
+			szk.WithDialer(dialer(options.TLSConfig)),
+		)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("Error connecting to zkAddr %+v: %+v", zkAddrs, err)
+		}
+		connections = append(connections, conn)
+
+		if err := waitForSession(events, options.ConnectTimeout); err != nil {
+			closeAll()
+			return nil, fmt.Errorf("Error connecting to zkAddr %+v: %+v", zkAddrs, err)
+		}
+
+		if options.AuthScheme != "" {
+			// The credentials are kept by the connection and re-sent after reconnects
+			if err := conn.AddAuth(options.AuthScheme, options.AuthCredentials); err != nil {
+				closeAll()
+				return nil, fmt.Errorf(
+					"Error authenticating to zkAddr %+v with scheme %s: %+v",
+					zkAddrs,
+					options.AuthScheme,
+					err,
+				)
+			}
+		}
+	}
+
+	return connections, nil
+}
+
+// dialer returns the zk dialer for the argument TLS config. If the config is nil, plain
+// TCP connections are used; otherwise, each connection is wrapped in TLS.
+func dialer(tlsConfig *tls.Config) szk.Dialer {
+	if tlsConfig == nil {
+		return net.DialTimeout
+	}
+
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return tls.DialWithDialer(
+			&net.Dialer{Timeout: timeout},
+			network,
+			address,
+			tlsConfig,
+		)
+	}
+}
+
+// waitForSession waits until the connection that emits the argument events has a session.
+func waitForSession(events <-chan szk.Event, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return errors.New("Connection closed before session was established")
+			}
+
+			switch event.State {
+			case szk.StateHasSession:
+				return nil
+			case szk.StateAuthFailed:
+				return szk.ErrAuthFailed
+			}
+		case <-timer.C:
+			return fmt.Errorf("Timed out after %s waiting for session", timeout)
+		}
+	}
+}
@@ -88,7 +88,7 @@ func TestPooledClientRead(t *testing.T) {
 
 	pooledClient, err := NewPooledClient(
 		[]string{testZkAddress},
-		5*time.Second,
+		ClientOptions{SessionTimeout: 5 * time.Second},
 		&ZKDebugLogger{},
 		2,
 		true,
@@ -179,7 +179,7 @@ func TestPooledClientWrites(t *testing.T) {
 
 	pooledClient, err := NewPooledClient(
 		[]string{testZkAddress},
-		5*time.Second,
+		ClientOptions{SessionTimeout: 5 * time.Second},
 		&ZKDebugLogger{},
 		2,
 		false,
@@ -249,7 +249,7 @@ func TestPooledClientSequentialWrites(t *testing.T) {
 
 	pooledClient, err := NewPooledClient(
 		[]string{testZkAddress},
-		5*time.Second,
+		ClientOptions{SessionTimeout: 5 * time.Second},
 		&ZKDebugLogger{},
 		2,
 		false,
@@ -296,7 +296,7 @@ func TestPooledClientLocks(t *testing.T) {
 
 	pooledClient, err := NewPooledClient(
 		[]string{testZkAddress},
-		5*time.Second,
+		ClientOptions{SessionTimeout: 5 * time.Second},
 		&ZKDebugLogger{},
 		2,
 		false,
@@ -316,6 +316,16 @@ func TestPooledClientLocks(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(children))
 
+	metadata := LockMetadata{}
+	_, err = pooledClient.GetJSON(
+		ctx,
+		fmt.Sprintf("%s/%s", lockPath, children[0]),
+		&metadata,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, os.Getpid(), metadata.PID)
+	assert.NotEqual(t, "", metadata.Host)
+
 	require.Nil(t, lock.Unlock())
 
 	children, _, err = pooledClient.Children(ctx, lockPath)
@@ -323,6 +333,196 @@ func TestPooledClientLocks(t *testing.T) {
 	assert.Equal(t, 0, len(children))
 }
 
+func TestPooledClientObserverFallback(t *testing.T) {
+	pooledClient, err := NewPooledClient(
+		[]string{"127.0.0.1:1"},
+		ClientOptions{
+			SessionTimeout: 5 * time.Second,
+			ConnectTimeout: 500 * time.Millisecond,
+			ObserverAddrs:  []string{testZkAddress},
+		},
+		&ZKDebugLogger{},
+		2,
+		false,
+	)
+	require.Nil(t, err)
+	defer pooledClient.Close()
+
+	assert.True(t, pooledClient.ReadOnly())
+
+	ctx := context.Background()
+	exists, _, err := pooledClient.Exists(ctx, "/")
+	require.Nil(t, err)
+	assert.True(t, exists)
+
+	err = pooledClient.Create(ctx, fmt.Sprintf("/%s", testPrefix("observer")), nil, false)
+	assert.NotNil(t, err)
+}
+
+func TestPooledClientAuthACL(t *testing.T) {
+	zkConn, _, err := szk.Connect(
+		[]string{testZkAddress},
+		5*time.Second,
+	)
+	require.Nil(t, err)
+	defer zkConn.Close()
+
+	prefix := testPrefix("pooled-client-acl")
+
+	CreateNodes(
+		t,
+		zkConn,
+		[]PathTuple{
+			{
+				Path: fmt.Sprintf("/%s", prefix),
+				Obj:  nil,
+			},
+		},
+	)
+
+	pooledClient, err := NewPooledClient(
+		[]string{testZkAddress},
+		ClientOptions{
+			SessionTimeout:  5 * time.Second,
+			AuthScheme:      "digest",
+			AuthCredentials: []byte("topicctl:secret"),
+			ACL: append(
+				szk.AuthACL(szk.PermAll),
+				szk.WorldACL(szk.PermRead)...,
+			),
+		},
+		&ZKDebugLogger{},
+		2,
+		false,
+	)
+	require.Nil(t, err)
+	defer pooledClient.Close()
+
+	ctx := context.Background()
+	testPath := fmt.Sprintf("/%s/test1", prefix)
+
+	err = pooledClient.Create(ctx, testPath, []byte(`"hello"`), false)
+	require.Nil(t, err)
+
+	_, err = pooledClient.Set(ctx, testPath, []byte(`"updated"`), -1)
+	require.Nil(t, err)
+
+	// Unauthenticated connections can read the node, but not update it
+	contents, _, err := zkConn.Get(testPath)
+	require.Nil(t, err)
+	assert.Equal(t, `"updated"`, string(contents))
+
+	_, err = zkConn.Set(testPath, []byte(`"other"`), -1)
+	assert.Equal(t, szk.ErrNoAuth, err)
+}
+
+func TestPooledClientConnectTimeout(t *testing.T) {
+	startTime := time.Now()
+
+	_, err := NewPooledClient(
+		[]string{"127.0.0.1:1"},
+		ClientOptions{
+			ConnectTimeout: 500 * time.Millisecond,
+		},
+		&ZKDebugLogger{},
+		2,
+		false,
+	)
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(startTime) < 5*time.Second)
+}
+
+func TestPooledClientRetries(t *testing.T) {
+	pooledClient := &PooledClient{
+		options: ClientOptions{
+			MaxRetries:      2,
+			RetryBackoff:    time.Millisecond,
+			MaxRetryBackoff: 2 * time.Millisecond,
+		},
+	}
+	ctx := context.Background()
+
+	type testCase struct {
+		description   string
+		write         bool
+		errs          []error
+		expectedCalls int
+		expectedErr   error
+	}
+
+	testCases := []testCase{
+		{
+			description:   "success",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			description:   "read recovers after connection errors",
+			errs:          []error{szk.ErrConnectionClosed, szk.ErrNoServer, nil},
+			expectedCalls: 3,
+		},
+		{
+			description:   "read runs out of retries",
+			errs:          []error{szk.ErrNoServer, szk.ErrNoServer, szk.ErrNoServer, nil},
+			expectedCalls: 3,
+			expectedErr:   szk.ErrNoServer,
+		},
+		{
+			description:   "non-recoverable error",
+			errs:          []error{szk.ErrNoNode, nil},
+			expectedCalls: 1,
+			expectedErr:   szk.ErrNoNode,
+		},
+		{
+			description:   "write retried if not sent",
+			write:         true,
+			errs:          []error{szk.ErrNoServer, nil},
+			expectedCalls: 2,
+		},
+		{
+			description:   "write not retried if possibly sent",
+			write:         true,
+			errs:          []error{szk.ErrConnectionClosed, nil},
+			expectedCalls: 1,
+			expectedErr:   szk.ErrConnectionClosed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		calls := 0
+		err := pooledClient.withRetries(
+			ctx,
+			"test",
+			testCase.write,
+			func() error {
+				err := testCase.errs[calls]
+				calls++
+				return err
+			},
+		)
+		assert.Equal(t, testCase.expectedErr, err, testCase.description)
+		assert.Equal(t, testCase.expectedCalls, calls, testCase.description)
+	}
+}
+
+func TestHolderNode(t *testing.T) {
+	_, err := HolderNode([]string{})
+	assert.NotNil(t, err)
+
+	holder, err := HolderNode(
+		[]string{
+			"_c_3a5e1b-lock-0000000012",
+			"_c_9f12ab-lock-0000000010",
+			"_c_77cd01-lock-0000000011",
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "_c_9f12ab-lock-0000000010", holder)
+
+	_, err = HolderNode([]string{"_c_3a5e1b-lock-bad"})
+	assert.NotNil(t, err)
+}
+
 func testPrefix(name string) string {
 	return util.RandomString(fmt.Sprintf("zk-test-%s-", name), 6)
 }
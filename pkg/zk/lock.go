@@ -1,6 +1,14 @@
 package zk
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
 	szk "github.com/samuel/go-zookeeper/zk"
 )
 
@@ -10,3 +18,86 @@ type Lock interface {
 }
 
 var _ Lock = (*szk.Lock)(nil)
+
+// LockMetadata contains information about the process holding a lock. It's stored in the
+// lock's zk node so that locks orphaned by crashed or stuck processes can be traced back to
+// their holders.
+type LockMetadata struct {
+	User      string    `json:"user"`
+	Host      string    `json:"host"`
+	PID       int       `json:"pid"`
+	StartTime time.Time `json:"startTime"`
+}
+
+// String returns a short description of the lock holder for logs and error messages.
+func (m LockMetadata) String() string {
+	return fmt.Sprintf(
+		"%s@%s (pid %d, started %s)",
+		m.User,
+		m.Host,
+		m.PID,
+		m.StartTime.UTC().Format(time.RFC3339),
+	)
+}
+
+// LockInfo describes the current state of a single lock.
+type LockInfo struct {
+	// Path is the path of the lock, i.e. the parent of the nodes created by each
+	// lock holder or waiter.
+	Path string
+
+	// HolderNode is the full path of the node for the current holder.
+	HolderNode string
+
+	// Metadata is the metadata for the current holder. It's nil if the lock was acquired
+	// by a version of topicctl that didn't record metadata.
+	Metadata *LockMetadata
+
+	// Waiters is the number of processes waiting to acquire the lock.
+	Waiters int
+}
+
+// HolderNode returns the name of the child node that holds a lock given all of the children
+// of the lock path. Each lock node is suffixed with a sequence number, and the lowest one
+// holds the lock.
+func HolderNode(children []string) (string, error) {
+	if len(children) == 0 {
+		return "", errors.New("Lock has no children")
+	}
+
+	var holder string
+	lowestSeq := -1
+
+	for _, child := range children {
+		parts := strings.Split(child, "-")
+		seq, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			return "", err
+		}
+		if lowestSeq == -1 || seq < lowestSeq {
+			lowestSeq = seq
+			holder = child
+		}
+	}
+
+	return holder, nil
+}
+
+// CurrentLockMetadata returns the lock metadata for the current process.
+func CurrentLockMetadata() LockMetadata {
+	metadata := LockMetadata{
+		User:      "unknown",
+		Host:      "unknown",
+		PID:       os.Getpid(),
+		StartTime: time.Now().UTC(),
+	}
+
+	if currUser, err := user.Current(); err == nil {
+		metadata.User = currUser.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		metadata.Host = host
+	}
+
+	return metadata
+}